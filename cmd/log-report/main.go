@@ -9,6 +9,7 @@ import (
 	"io"
 	"mime/quotedprintable"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -30,6 +31,10 @@ func main() {
 		changes             [][]string
 		authn               [][]string
 		errors              []map[string]interface{}
+		failedLogins        int
+		failedByHour        = make(map[string]int)
+		failedByIP          = make(map[string]int)
+		alert               bool
 		out                 bytes.Buffer
 		qpw                 *quotedprintable.Writer
 		err                 error
@@ -110,6 +115,11 @@ func main() {
 				}
 			}
 		}
+		if ip, ok := entry["failedLogin"].(string); ok {
+			failedLogins++
+			failedByHour[entry["time"].(string)[11:13]]++
+			failedByIP[ip]++
+		}
 		if _, ok := entry["error"].(string); ok {
 			errors = append(errors, entry)
 		} else if _, ok := entry["errors"].([]interface{}); ok {
@@ -118,13 +128,24 @@ func main() {
 	}
 	reorder(changes)
 	reorder(authn)
-	fmt.Fprintf(&out, "From: SunnyvaleSERV.org <admin@sunnyvaleserv.org>\r\nTo: admin@sunnyvaleserv.org\r\nSubject: SunnyvaleSERV.org Usage Report for %s\r\nContent-Type: text/html; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", date)
+	alert = failedLogins >= failedLoginDailyAlert
+	for _, count := range failedByHour {
+		if count >= failedLoginHourlyAlert {
+			alert = true
+		}
+	}
+	fmt.Fprint(&out, "From: SunnyvaleSERV.org <admin@sunnyvaleserv.org>\r\nTo: admin@sunnyvaleserv.org\r\nSubject: ")
+	if alert {
+		fmt.Fprint(&out, "ALERT: Failed Login Spike: ")
+	}
+	fmt.Fprintf(&out, "SunnyvaleSERV.org Usage Report for %s\r\nContent-Type: text/html; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", date)
 	qpw = quotedprintable.NewWriter(&out)
 	if requestElapsedCount != 0 {
 		requestElapsedAvg = requestElapsedSum / time.Duration(requestElapsedCount)
 	}
 	fmt.Fprintf(qpw, `<!DOCTYPE html><html><body><div>%d requests, average %dms, max %dms.</div>`,
 		requestCount, requestElapsedAvg/time.Millisecond, requestElapsedMax/time.Millisecond)
+	showFailedLogins(qpw, failedLogins, failedByHour, failedByIP, alert)
 	if len(errors) != 0 {
 		fmt.Fprintf(qpw, `<div style="margin-top:1em;font-weight:bold">Errors</div>`)
 		for _, e := range errors {
@@ -155,6 +176,59 @@ func main() {
 	}
 }
 
+// A spike of failed logins, which may be an attack on the site, is flagged in
+// the report when there are at least this many in a day, or in any one hour.
+const (
+	failedLoginDailyAlert  = 100
+	failedLoginHourlyAlert = 20
+)
+
+// failedLoginTopSources is the number of IP addresses listed in the failed
+// logins section of the report.
+const failedLoginTopSources = 10
+
+// showFailedLogins shows the count of failed logins, broken down by hour and
+// IP address.  When there has been a spike, it says so prominently.
+func showFailedLogins(w io.Writer, total int, byHour, byIP map[string]int, alert bool) {
+	var (
+		hours []string
+		ips   []string
+	)
+	if total == 0 {
+		return
+	}
+	if alert {
+		fmt.Fprint(w, `<div style="margin-top:1em;font-weight:bold;color:red">Failed Logins: Unusual Spike</div>`)
+	} else {
+		fmt.Fprint(w, `<div style="margin-top:1em;font-weight:bold">Failed Logins</div>`)
+	}
+	fmt.Fprintf(w, `<div>%d failed logins from %d IP addresses.</div>`, total, len(byIP))
+	for hour, count := range byHour {
+		if count >= failedLoginHourlyAlert {
+			hours = append(hours, hour)
+		}
+	}
+	sort.Strings(hours)
+	for _, hour := range hours {
+		fmt.Fprintf(w, `<div style="margin-left:2em"><span style="font-variant:tabular-nums">%s:00</span> %d failed logins</div>`, hour, byHour[hour])
+	}
+	for ip := range byIP {
+		ips = append(ips, ip)
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		if byIP[ips[i]] != byIP[ips[j]] {
+			return byIP[ips[i]] > byIP[ips[j]]
+		}
+		return ips[i] < ips[j]
+	})
+	if len(ips) > failedLoginTopSources {
+		ips = ips[:failedLoginTopSources]
+	}
+	for _, ip := range ips {
+		fmt.Fprintf(w, `<div style="margin-left:2em;font-family:monospace">%s: %d</div>`, html.EscapeString(ip), byIP[ip])
+	}
+}
+
 // reorder rearranges the list so that all items with common prefixes are
 // adjacent.  It retains the original order (i.e., chronological) otherwise.
 func reorder(list [][]string) {
@@ -0,0 +1,41 @@
+// This program runs a mock OpenID provider, for trying out single sign-on
+// against a development server.  It signs in every request as the user given
+// on the command line, without asking.  Configure the server with
+// "ssoIssuer" set to the URL it prints, and "ssoClientID" and
+// "ssoClientSecret" matching its flags.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"sunnyvaleserv.org/portal/util/oidc/oidctest"
+)
+
+func main() {
+	var (
+		addr     = flag.String("addr", "localhost:8001", "listen address")
+		clientID = flag.String("client-id", "portal", "client ID")
+		secret   = flag.String("client-secret", "", "client secret (empty for a public client)")
+		subject  = flag.String("subject", "mock-user", "subject identifier of the signed-in user")
+		email    = flag.String("email", "", "email address of the signed-in user")
+		verified = flag.Bool("email-verified", true, "whether the email address is verified")
+		name     = flag.String("name", "", "name of the signed-in user")
+	)
+	flag.Parse()
+	if *email == "" {
+		fmt.Fprintf(os.Stderr, "usage: mock-oidc -email address [flags]\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+	provider := oidctest.New(*clientID, *secret)
+	provider.Issuer = "http://" + *addr
+	provider.User = oidctest.User{Subject: *subject, Email: *email, EmailVerified: *verified, Name: *name}
+	fmt.Printf("mock OpenID provider: ssoIssuer=%s ssoClientID=%s\n", provider.Issuer, *clientID)
+	if err := http.ListenAndServe(*addr, provider); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"sunnyvaleserv.org/portal/server"
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/ui"
 )
 
@@ -28,6 +29,10 @@ func main() {
 		}
 		return
 	}
+	if err = auth.CheckConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+		os.Exit(1)
+	}
 	if err = fcgi.Serve(nil, server.Server); err != nil {
 		fmt.Fprintf(os.Stderr, "ERROR: fcgi.Serve: %s\n", err)
 		os.Exit(1)
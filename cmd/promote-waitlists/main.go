@@ -0,0 +1,37 @@
+// promote-waitlists cancels the holds on class seats offered to students on
+// waiting lists who did not confirm them in time, and offers those seats to
+// the next students on the waiting lists.  It is normally invoked hourly as a
+// cron job.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"sunnyvaleserv.org/portal/pages/classes"
+	"sunnyvaleserv.org/portal/store"
+	"sunnyvaleserv.org/portal/util/log"
+)
+
+func main() {
+	var entry *log.Entry
+
+	switch os.Getenv("HOME") {
+	case "/home/snyserv":
+		if err := os.Chdir("/home/snyserv/sunnyvaleserv.org/data"); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+			os.Exit(1)
+		}
+	case "/Users/stever":
+		if err := os.Chdir("/Users/stever/src/serv-portal/data"); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	entry = log.New("", "promote-waitlists")
+	defer entry.Log()
+	store.Connect(context.Background(), entry, func(st *store.Store) {
+		classes.PromoteExpiredHolds(context.Background(), st)
+	})
+}
@@ -0,0 +1,160 @@
+// send-surveys sends invitations to respond to the feedback survey for each
+// class that ended recently to the students registered for it.  Each
+// invitation has its own link, which allows one response without logging in.
+// It is normally invoked daily as a cron job.
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"time"
+
+	"sunnyvaleserv.org/portal/server/l10n"
+	"sunnyvaleserv.org/portal/store"
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/classreg"
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/personverify"
+	"sunnyvaleserv.org/portal/store/survey"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/log"
+	"sunnyvaleserv.org/portal/util/sendmail"
+)
+
+// maxAge is the number of days after the end of a class during which survey
+// invitations will be sent for it.  It keeps us from surveying classes that
+// ended long before the survey questions were written.
+const maxAge = 30
+
+// invitation is a survey invitation to be sent.
+type invitation struct {
+	c     *class.Class
+	ended time.Time
+	name  string
+	email string
+	token string
+}
+
+func main() {
+	var (
+		entry   *log.Entry
+		invites []*invitation
+	)
+	switch os.Getenv("HOME") {
+	case "/home/snyserv":
+		if err := os.Chdir("/home/snyserv/sunnyvaleserv.org/data"); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+			os.Exit(1)
+		}
+	case "/Users/stever":
+		if err := os.Chdir("/Users/stever/src/serv-portal/data"); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	entry = log.New("", "send-surveys")
+	defer entry.Log()
+	store.Connect(context.Background(), entry, func(st *store.Store) {
+		var mailer *sendmail.Mailer
+		var err error
+
+		st.Transaction(func() {
+			invites = createInvites(st)
+		})
+		if len(invites) == 0 {
+			return
+		}
+		if mailer, err = sendmail.OpenMailer(); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: can't open mailer: %s\n", err)
+			os.Exit(1)
+		}
+		for _, inv := range invites {
+			sendInvite(mailer, inv)
+		}
+	})
+}
+
+// createInvites creates survey invitations for the students of each class that
+// ended recently, whose class type has survey questions, and for which
+// invitations have not already been sent.  It returns the list of invitations
+// to be sent.
+func createInvites(st *store.Store) (invites []*invitation) {
+	var (
+		classes []*class.Class
+		today   = time.Now().Format("2006-01-02")
+		cutoff  = time.Now().AddDate(0, 0, -maxAge).Format("2006-01-02")
+	)
+	class.All(st, class.FID|class.FType|class.FStart|class.FRegURL, func(c *class.Class) {
+		if c.Start() < today && c.RegURL() == "" {
+			classes = append(classes, c.Clone())
+		}
+	})
+	for _, c := range classes {
+		// The class ends on the date of its last session, or on its
+		// start date if it has no sessions.
+		var last = c.Start()
+		c.Sessions(st, event.FStart, func(e *event.Event) {
+			last = max(last, e.Start()[:10])
+		})
+		if last >= today || last < cutoff {
+			continue
+		}
+		if !survey.HasQuestions(st, c.Type()) || survey.Invited(st, c.ID()) {
+			continue
+		}
+		ended, _ := time.ParseInLocation("2006-01-02", last, time.Local)
+		classreg.AllForClass(st, c.ID(), classreg.FRegisteredBy|classreg.FPerson|classreg.FFirstName|classreg.FLastName|classreg.FEmail|classreg.FWaitlist, func(cr *classreg.ClassReg) {
+			// Students on the waiting list didn't take the class.  If
+			// the registration was made by someone whose email address
+			// isn't verified, we don't send email on their behalf.
+			if cr.Waitlist() || cr.Email() == "" {
+				return
+			}
+			if cr.RegisteredBy() != cr.Person() && personverify.Pending(st, cr.RegisteredBy()) {
+				return
+			}
+			invites = append(invites, &invitation{
+				c:     c,
+				ended: ended,
+				name:  cr.FirstName() + " " + cr.LastName(),
+				email: cr.Email(),
+				token: util.RandomToken(),
+			})
+		})
+	}
+	for _, inv := range invites {
+		survey.CreateInvite(st, inv.c.ID(), inv.token)
+	}
+	return invites
+}
+
+// sendInvite sends a survey invitation, in both English and Spanish.
+func sendInvite(mailer *sendmail.Mailer, inv *invitation) {
+	var (
+		body  bytes.Buffer
+		ctype = inv.c.Type().String()
+	)
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\n", config.Get("fromEmail"), (&mail.Address{Name: inv.name, Address: inv.email}).String())
+	fmt.Fprintf(&body, "Subject: %s: %s / %s: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n",
+		ctype, "Class Survey", l10n.Localize(ctype, "es"), l10n.Localize("Class Survey", "es"))
+	for i, lang := range []string{"en", "es"} {
+		if i != 0 {
+			fmt.Fprint(&body, "\r\n\r\n----------------------------------------\r\n\r\n")
+		}
+		fmt.Fprintf(&body, l10n.Localize("Greetings, %s,", lang), inv.name)
+		fmt.Fprint(&body, "\r\n\r\n")
+		fmt.Fprintf(&body, l10n.Localize("Thank you for taking the %s class that ended on %s.  We would appreciate your feedback, which will help us improve the class.  Please answer a few short questions at:", lang),
+			l10n.Localize(ctype, lang), l10n.LocalizeDate(inv.ended, lang))
+		fmt.Fprintf(&body, "\r\n    %s/survey/%s\r\n\r\n", config.Get("siteURL"), inv.token)
+		fmt.Fprint(&body, l10n.Localize("Your responses are anonymous unless you choose to include your name.", lang))
+		fmt.Fprint(&body, "\r\n\r\nSunnyvale SERV\r\nserv@sunnyvale.ca.gov")
+	}
+	fmt.Fprint(&body, "\r\n")
+	if err := mailer.SendMessage(context.Background(), config.Get("fromAddr"), []string{inv.email}, body.Bytes()); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: can't send survey invitation to %s: %s\n", inv.email, err)
+	}
+}
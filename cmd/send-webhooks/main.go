@@ -0,0 +1,43 @@
+// send-webhooks retries webhook deliveries that failed or were never
+// attempted, once their retry time has come, and prunes old completed
+// deliveries from the delivery log.  It is normally invoked every few minutes
+// as a cron job.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"sunnyvaleserv.org/portal/store"
+	"sunnyvaleserv.org/portal/store/webhook"
+	"sunnyvaleserv.org/portal/util/log"
+)
+
+// keepDays is the number of days that completed deliveries are kept in the
+// delivery log.
+const keepDays = 30
+
+func main() {
+	var entry *log.Entry
+
+	switch os.Getenv("HOME") {
+	case "/home/snyserv":
+		if err := os.Chdir("/home/snyserv/sunnyvaleserv.org/data"); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+			os.Exit(1)
+		}
+	case "/Users/stever":
+		if err := os.Chdir("/Users/stever/src/serv-portal/data"); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	entry = log.New("", "send-webhooks")
+	defer entry.Log()
+	store.Connect(context.Background(), entry, func(st *store.Store) {
+		webhook.DeliverDue(st)
+		webhook.PruneDeliveries(st, time.Now().AddDate(0, 0, -keepDays))
+	})
+}
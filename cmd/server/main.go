@@ -7,6 +7,7 @@ import (
 	"syscall"
 
 	"sunnyvaleserv.org/portal/server"
+	"sunnyvaleserv.org/portal/server/auth"
 )
 
 func main() {
@@ -16,6 +17,10 @@ func main() {
 		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
 		os.Exit(1)
 	}
+	if err = auth.CheckConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+		os.Exit(1)
+	}
 	ensureSingleton()
 	if err = http.ListenAndServe(":8000", server.Server); err != nil {
 		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
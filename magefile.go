@@ -37,7 +37,12 @@ func Build() {
 }
 
 // Install builds the server (as an FCGI executable) and all associated commands
-// and installs them.
+// and installs them.  Some of the commands must be run periodically from cron:
+//
+//	promote-waitlists  hourly, to offer seats whose holds expired
+//	send-surveys       daily, to invite students to class feedback surveys
+//	send-webhooks      every few minutes, to deliver and retry webhook events
+//	volunteer-hours    at various times, for its various tasks
 func Install() error {
 	mg.Deps(Assets)
 	if err := sh.Run(mg.GoCmd(), "install", "./cmd/gen-ical"); err != nil {
@@ -46,6 +51,9 @@ func Install() error {
 	if err := sh.Run(mg.GoCmd(), "install", "./cmd/log-report"); err != nil {
 		return err
 	}
+	if err := sh.Run(mg.GoCmd(), "install", "./cmd/promote-waitlists"); err != nil {
+		return err
+	}
 	if err := sh.Run(mg.GoCmd(), "install", "./cmd/rebuild-search-index"); err != nil {
 		return err
 	}
@@ -70,6 +78,12 @@ func Install() error {
 		os.Remove("received-text-hook")
 		return err
 	}
+	if err := sh.Run(mg.GoCmd(), "install", "./cmd/send-surveys"); err != nil {
+		return err
+	}
+	if err := sh.Run(mg.GoCmd(), "install", "./cmd/send-webhooks"); err != nil {
+		return err
+	}
 	if err := sh.Run(mg.GoCmd(), "build", "-o", "text-status-hook", "./cmd/text-status-hook"); err != nil {
 		return err
 	}
@@ -118,25 +132,36 @@ var stylesheets = []string{
 	"ui/page.css",
 	"ui/dialog.css",
 	// Individual pages.
-	"pages/admin/classedit/classedit.css",
+	"pages/admin/archive/archive.css",
 	"pages/admin/classlist/classlist.css",
+	"pages/admin/duplicates/duplicates.css",
 	"pages/admin/listedit/listedit.css",
 	"pages/admin/listlist/listlist.css",
 	"pages/admin/listpeople/listpeople.css",
+	"pages/admin/lockouts/lockouts.css",
 	"pages/admin/redirlist/redirlist.css",
+	"pages/admin/referrallist/referrallist.css",
 	"pages/admin/roleedit/roleedit.css",
 	"pages/admin/rolelist/rolelist.css",
 	"pages/admin/venuelist/venuelist.css",
+	"pages/admin/webhooklist/webhooklist.css",
+	"pages/admin/webhooklog/webhooklog.css",
 	"pages/classes/all.css",
 	"pages/classes/cert.css",
+	"pages/classes/certificates.css",
 	"pages/classes/common.css",
 	"pages/classes/moulage.css",
 	"pages/classes/myn.css",
 	"pages/classes/pep.css",
 	"pages/classes/register.css",
 	"pages/classes/reglist.css",
+	"pages/classes/roster.css",
+	"pages/classes/sessions.css",
+	"pages/classes/survey.css",
+	"pages/classes/waitlist.css",
 	"pages/classes/classlists/classlists.css",
 	"pages/errpage/errpage.css",
+	"pages/events/emsheet/emsheet.css",
 	"pages/events/eventattend/attendance.css",
 	"pages/events/eventcopy/eventcopy.css",
 	"pages/events/eventedit/details.css",
@@ -152,26 +177,38 @@ var stylesheets = []string{
 	"pages/events/signups/signups.css",
 	"pages/events/tasklists/tasklists.css",
 	"pages/files/files.css",
+	"pages/history/history.css",
 	"pages/homepage/homepage.css",
 	"pages/login/login.css",
 	"pages/login/newpwd.css",
 	"pages/people/activity/activity.css",
+	"pages/people/peopleavail/peopleavail.css",
 	"pages/people/peoplelist/peoplelist.css",
 	"pages/people/peoplemap/peoplemap.css",
+	"pages/people/personedit/apitokens.css",
 	"pages/people/personedit/contact.css",
+	"pages/people/personedit/passkeys.css",
+	"pages/people/personedit/photo.css",
 	"pages/people/personedit/roles.css",
 	"pages/people/personedit/status.css",
 	"pages/people/personedit/subscriptions.css",
 	"pages/people/personedit/vregister.css",
+	"pages/people/personview/availability.css",
+	"pages/people/personview/certificates.css",
 	"pages/people/personview/contact.css",
+	"pages/people/personview/data.css",
+	"pages/people/personview/history.css",
 	"pages/people/personview/names.css",
 	"pages/people/personview/notes.css",
 	"pages/people/personview/password.css",
 	"pages/people/personview/personview.css",
 	"pages/people/personview/roles.css",
+	"pages/people/personview/sessions.css",
 	"pages/people/personview/status.css",
 	"pages/people/personview/subscriptions.css",
+	"pages/reports/activations/activations.css",
 	"pages/reports/attendance/attendance.css",
+	"pages/reports/classes/classes.css",
 	"pages/reports/clearance/clearance.css",
 	"pages/search/search.css",
 	"pages/static/static.css",
@@ -249,6 +286,7 @@ var scripts = []string{
 	"pages/admin/roleedit/roleedit.js",
 	"pages/classes/all.js",
 	"pages/classes/register.js",
+	"pages/events/emsheet/emsheet.js",
 	"pages/events/eventattend/attendance.js",
 	"pages/events/eventedit/details.js",
 	"pages/events/eventscal/eventscal.js",
@@ -257,15 +295,18 @@ var scripts = []string{
 	"pages/events/proxysignup/proxy.js",
 	"pages/events/signups/shared.js",
 	"pages/files/files.js",
+	"pages/login/login.js",
 	"pages/people/activity/activity.js",
 	"pages/people/peoplelist/peoplelist.js",
 	"pages/people/peoplemap/peoplemap.js",
 	"pages/people/personedit/contact.js",
+	"pages/people/personedit/passkeys.js",
 	"pages/people/personedit/password.js",
 	"pages/people/personedit/roles.js",
 	"pages/people/personedit/status.js",
 	"pages/people/personedit/subscriptions.js",
 	"pages/reports/attendance/attendance.js",
+	"pages/reports/classes/classes.js",
 	"pages/reports/clearance/clearance.js",
 	"pages/texts/textnew/textnew.js",
 	"pages/texts/textview/textview.js",
@@ -0,0 +1,126 @@
+// Package archive handles the /admin/archive pages, through which the webmaster
+// reviews inactive people for archival, and deletes archived people.
+package archive
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personarchive"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// defaultInactivityMonths is the number of months of inactivity after which a
+// person is proposed for archival, if config.json doesn't say otherwise.
+const defaultInactivityMonths = 36
+
+// inactivityMonths returns the number of months of inactivity after which a
+// person is proposed for archival.
+func inactivityMonths() int {
+	if months, err := strconv.Atoi(config.Get("archiveAfterMonths")); err == nil && months > 0 {
+		return months
+	}
+	return defaultInactivityMonths
+}
+
+// Get handles GET /admin/archive requests.
+func Get(r *request.Request) {
+	var (
+		user *person.Person
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	Render(r, user)
+}
+
+// Render renders the archive review queue.
+func Render(r *request.Request, user *person.Person) {
+	var opts = ui.PageOpts{
+		Title:    "Archive People",
+		MenuItem: "admin",
+		Tabs: []ui.PageTab{
+			{Name: "Roles", URL: "/admin/roles", Target: "main"},
+			{Name: "Lists", URL: "/admin/lists", Target: "main"},
+			{Name: "Venues", URL: "/admin/venues", Target: "main"},
+			{Name: "Classes", URL: "/admin/classes", Target: "main"},
+			{Name: "Referrals", URL: "/admin/referrals", Target: "main"},
+			{Name: "Redirects", URL: "/admin/redirects", Target: "main"},
+			{Name: "Duplicates", URL: "/admin/duplicates", Target: "main"},
+			{Name: "Archive", URL: "/admin/archive", Target: "main", Active: true},
+			{Name: "Lockouts", URL: "/admin/lockouts", Target: "main"},
+			{Name: "Webhooks", URL: "/admin/webhooks", Target: "main"},
+		},
+	}
+	r.HTMLNoCache()
+	ui.Page(r, user, opts, func(main *htmlb.Element) {
+		var (
+			months = inactivityMonths()
+			today  = time.Now()
+			cutoff = today.AddDate(0, -months, 0)
+			grid   *htmlb.Element
+		)
+		main.E("div class=archiveIntro").TF("People who hold no roles and have had no recorded activity in the last %d months are listed here for review.  Archiving a person removes their contact information, addresses, birthdate, and emergency contacts, but keeps their name and history for reports.", months)
+		personarchive.Candidates(r, cutoff, today, func(p *person.Person, lastActive string) {
+			if grid == nil {
+				grid = main.E("div class=archiveGrid")
+				row := grid.E("div class=archiveHeading")
+				row.E("div>Person")
+				row.E("div>Last Activity")
+				row.E("div")
+			}
+			row := grid.E("div class=archiveRow")
+			showPerson(row.E("div"), p)
+			if lastActive != "" {
+				row.E("div").T(lastActive)
+			} else {
+				row.E("div class=archiveNone>none recorded")
+			}
+			row.E("div").E("a href=/admin/archive/%d up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'>Review", p.ID())
+		})
+		if grid == nil {
+			main.E("div class=archiveNone>No people are awaiting review.")
+		}
+		main.E("h2 class=archiveHeader>Archived People")
+		grid = nil
+		personarchive.Archived(r, func(p *person.Person) {
+			if grid == nil {
+				grid = main.E("div class=archiveGrid")
+			}
+			row := grid.E("div class=archiveRow")
+			showPerson(row.E("div"), p)
+			if reasons := personarchive.DeleteBlockers(r, p); len(reasons) != 0 {
+				row.E("div class=archiveNone").T(strings.Join(reasons, ", "))
+				row.E("div")
+			} else {
+				row.E("div")
+				row.E("div").E("a href=/admin/archive/%d up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-danger'>Delete", p.ID())
+			}
+		})
+		if grid == nil {
+			main.E("div class=archiveNone>No people have been archived.")
+		}
+	})
+}
+
+func showPerson(cell *htmlb.Element, p *person.Person) {
+	cell.E("a href=/people/%d up-target=main>%s", p.ID(), p.SortName())
+	cell.E("span class=archiveID> [%d]", p.ID())
+	if p.Email() != "" {
+		cell.E("div class=archiveDetail").T(p.Email())
+	}
+	if p.CellPhone() != "" {
+		cell.E("div class=archiveDetail").T(p.CellPhone())
+	}
+}
@@ -0,0 +1,93 @@
+package archive
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personarchive"
+	"sunnyvaleserv.org/portal/ui/form"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// HandleReview handles /admin/archive/$id requests.
+func HandleReview(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		p    *person.Person
+		f    form.Form
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), personarchive.ScrubFields); p == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if p.ID() == person.AdminID {
+		errpage.Forbidden(r, user)
+		return
+	}
+	f.Attrs = "method=POST up-target=main"
+	f.Dialog = true
+	if p.Flags()&person.Archived != 0 {
+		reasons := personarchive.DeleteBlockers(r, p)
+		f.Title = "Delete Person"
+		if len(reasons) != 0 {
+			f.Rows = []form.Row{&form.MessageRow{
+				HTML: fmt.Sprintf("%s [%d] cannot be deleted: %s.", html.EscapeString(p.InformalName()), p.ID(), html.EscapeString(strings.Join(reasons, ", "))),
+			}}
+			f.Handle(r)
+			return
+		}
+		f.Buttons = []*form.Button{{
+			Label: "Delete", Style: "danger",
+			OnClick: func() bool {
+				r.Transaction(func() {
+					personarchive.Delete(r, p)
+				})
+				Render(r, user)
+				return true
+			},
+		}}
+		f.Rows = []form.Row{&form.MessageRow{
+			HTML: fmt.Sprintf("%s [%d] will be deleted entirely.  Their shift signups, received text messages, and text message replies will be deleted, and their class registrations will be detached from them.  This cannot be undone.", html.EscapeString(p.InformalName()), p.ID()),
+		}}
+		f.Handle(r)
+		return
+	}
+	months := inactivityMonths()
+	f.Title = "Archive Person"
+	f.Buttons = []*form.Button{{
+		Label: "Archive",
+		OnClick: func() bool {
+			r.Transaction(func() {
+				personarchive.Scrub(r, p)
+			})
+			Render(r, user)
+			return true
+		},
+	}, {
+		Name: "keep", Label: "Keep", Style: "secondary",
+		OnClick: func() bool {
+			r.Transaction(func() {
+				personarchive.Retain(r, p, time.Now().AddDate(0, months, 0))
+			})
+			Render(r, user)
+			return true
+		},
+	}}
+	f.Rows = []form.Row{&form.MessageRow{
+		HTML: fmt.Sprintf("Archiving %s [%d] will remove their email addresses, phone numbers, addresses, birthdate, emergency contacts, password, list subscriptions, and availability.  Their name, hours, attendance, and class registrations will be kept for reports.  This cannot be undone.  Alternatively, choose “Keep” to leave them alone for another %d months.", html.EscapeString(p.InformalName()), p.ID(), months),
+	}}
+	f.Handle(r)
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"sunnyvaleserv.org/portal/pages/admin/classlist"
+	"sunnyvaleserv.org/portal/pages/classes"
 	"sunnyvaleserv.org/portal/pages/errpage"
 	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/class"
@@ -15,7 +16,6 @@ import (
 	"sunnyvaleserv.org/portal/store/role"
 	"sunnyvaleserv.org/portal/ui/form"
 	"sunnyvaleserv.org/portal/util"
-	"sunnyvaleserv.org/portal/util/htmlb"
 	"sunnyvaleserv.org/portal/util/request"
 )
 
@@ -28,7 +28,9 @@ func Handle(r *request.Request, idstr string) {
 		f            form.Form
 		canDelete    bool
 		roleID       role.ID
+		gradRoleID   role.ID
 		studentRoles = []role.ID{0}
+		gradRoles    = []role.ID{0}
 		roleMap      = map[role.ID]string{0: "(none)"}
 	)
 	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
@@ -41,17 +43,25 @@ func Handle(r *request.Request, idstr string) {
 			errpage.NotFound(r, user)
 			return
 		}
-		uc = c.Updater(r, nil)
+		uc = c.Updater(r, nil, nil)
 		canDelete = !classreg.ClassHasSignups(r, c.ID())
 		roleID = c.Role()
+		gradRoleID = c.GradRole()
 	}
 	role.All(r, role.FID|role.FName|role.FPrivLevel|role.FFlags, func(rl *role.Role) {
-		if rl.PrivLevel() == enum.PrivStudent && rl.Flags()&(role.Archived|role.ImplicitOnly) == 0 {
+		if rl.Flags()&(role.Archived|role.ImplicitOnly) != 0 {
+			return
+		}
+		if rl.PrivLevel() == enum.PrivStudent {
 			studentRoles = append(studentRoles, rl.ID())
 			roleMap[rl.ID()] = rl.Name()
+		} else if rl.PrivLevel() == enum.PrivMember {
+			gradRoles = append(gradRoles, rl.ID())
+			roleMap[rl.ID()] = rl.Name()
 		}
 	})
 	slices.SortFunc(studentRoles, func(a, b role.ID) int { return cmp.Compare(roleMap[a], roleMap[b]) })
+	slices.SortFunc(gradRoles, func(a, b role.ID) int { return cmp.Compare(roleMap[a], roleMap[b]) })
 	f.Attrs = "method=POST up-target=main"
 	f.Dialog = true
 	if c == nil {
@@ -128,12 +138,24 @@ func Handle(r *request.Request, idstr string) {
 			ValueFunc: func(id role.ID) string { return strconv.Itoa(int(id)) },
 			LabelFunc: func(r *request.Request, v role.ID) string { return roleMap[v] },
 		},
-		&referralsRow{form.LabeledRow{Label: "Referrals"}, uc},
+		&form.SelectRow[role.ID]{
+			LabeledRow: form.LabeledRow{
+				RowID: "classeditGradRole",
+				Label: "Graduate Role",
+				Help:  "Role given to students who are credited for every session",
+			},
+			Name:      "gradRole",
+			ValueP:    &gradRoleID,
+			Options:   gradRoles,
+			ValueFunc: func(id role.ID) string { return strconv.Itoa(int(id)) },
+			LabelFunc: func(r *request.Request, v role.ID) string { return roleMap[v] },
+		},
 	}
 	f.Buttons = []*form.Button{{
 		Label: "Save",
 		OnClick: func() bool {
 			uc.Role = role.WithID(r, roleID, role.FID|role.FName)
+			uc.GradRole = role.WithID(r, gradRoleID, role.FID|role.FName)
 			return saveClass(r, user, c, uc)
 		},
 	}}
@@ -149,6 +171,7 @@ func Handle(r *request.Request, idstr string) {
 			OnClick: func() bool {
 				uc.ID = 0
 				uc.Role = role.WithID(r, roleID, role.FID|role.FName)
+				uc.GradRole = role.WithID(r, gradRoleID, role.FID|role.FName)
 				return saveClass(r, user, nil, uc)
 			},
 		})
@@ -206,35 +229,20 @@ func (rdr *reqDescRow) Read(r *request.Request) bool {
 	return true
 }
 
-type referralsRow struct {
-	form.LabeledRow
-	uc *class.Updater
-}
-
-func (rr *referralsRow) ShouldEmit(_ request.ValidationList) bool {
-	return len(rr.uc.Referrals) != 0
-}
-
-func (rr *referralsRow) Emit(r *request.Request, parent *htmlb.Element, focus bool) {
-	row := rr.EmitPrefix(r, parent, "")
-	grid := row.E("div class='classeditReferrals formInput'")
-	for _, ref := range class.AllReferrals {
-		grid.E("div>%d", rr.uc.Referrals[ref])
-		grid.E("div>%s", ref.String())
-	}
-	rr.EmitSuffix(r, row)
-}
-
-func (rr *referralsRow) Read(r *request.Request) bool { return true }
-
 func saveClass(r *request.Request, user *person.Person, c *class.Class, ur *class.Updater) bool {
+	var raised bool
+
 	r.Transaction(func() {
 		if c == nil {
 			c = class.Create(r, ur)
 		} else {
+			raised = c.Limit() != 0 && (ur.Limit == 0 || ur.Limit > c.Limit())
 			c.Update(r, ur)
 		}
 	})
+	if raised {
+		classes.PromoteWaitlist(r, c.ID())
+	}
 	classlist.Render(r, user)
 	return true
 }
@@ -34,7 +34,12 @@ func Render(r *request.Request, user *person.Person) {
 			{Name: "Lists", URL: "/admin/lists", Target: "main"},
 			{Name: "Venues", URL: "/admin/venues", Target: "main"},
 			{Name: "Classes", URL: "/admin/classes", Target: "main", Active: true},
+			{Name: "Referrals", URL: "/admin/referrals", Target: "main"},
 			{Name: "Redirects", URL: "/admin/redirects", Target: "main"},
+			{Name: "Duplicates", URL: "/admin/duplicates", Target: "main"},
+			{Name: "Archive", URL: "/admin/archive", Target: "main"},
+			{Name: "Lockouts", URL: "/admin/lockouts", Target: "main"},
+			{Name: "Webhooks", URL: "/admin/webhooks", Target: "main"},
 		},
 	}
 	r.HTMLNoCache()
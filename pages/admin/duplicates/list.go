@@ -0,0 +1,82 @@
+// Package duplicates handles the /admin/duplicates pages, which find and merge
+// duplicate person records.
+package duplicates
+
+import (
+	"strings"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personmerge"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// Get handles GET /admin/duplicates requests.
+func Get(r *request.Request) {
+	var (
+		user *person.Person
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	Render(r, user)
+}
+
+// Render renders the list of probable duplicate person records.
+func Render(r *request.Request, user *person.Person) {
+	var opts = ui.PageOpts{
+		Title:    "Duplicate People",
+		MenuItem: "admin",
+		Tabs: []ui.PageTab{
+			{Name: "Roles", URL: "/admin/roles", Target: "main"},
+			{Name: "Lists", URL: "/admin/lists", Target: "main"},
+			{Name: "Venues", URL: "/admin/venues", Target: "main"},
+			{Name: "Classes", URL: "/admin/classes", Target: "main"},
+			{Name: "Referrals", URL: "/admin/referrals", Target: "main"},
+			{Name: "Redirects", URL: "/admin/redirects", Target: "main"},
+			{Name: "Duplicates", URL: "/admin/duplicates", Target: "main", Active: true},
+			{Name: "Archive", URL: "/admin/archive", Target: "main"},
+			{Name: "Lockouts", URL: "/admin/lockouts", Target: "main"},
+			{Name: "Webhooks", URL: "/admin/webhooks", Target: "main"},
+		},
+	}
+	r.HTMLNoCache()
+	ui.Page(r, user, opts, func(main *htmlb.Element) {
+		dups := personmerge.FindDuplicates(r)
+		if len(dups) == 0 {
+			main.E("div>No probable duplicates found.")
+			return
+		}
+		grid := main.E("div class=duplicatesGrid")
+		row := grid.E("div class=duplicatesHeading")
+		row.E("div>Person")
+		row.E("div>Person")
+		row.E("div>Reason")
+		row.E("div")
+		for _, dup := range dups {
+			row = grid.E("div class=duplicatesRow")
+			showPerson(row.E("div"), dup.A)
+			showPerson(row.E("div"), dup.B)
+			row.E("div").T(strings.Join(dup.Reasons, ", "))
+			row.E("div").E("a href=/admin/duplicates/%d/%d up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'>Merge", dup.A.ID(), dup.B.ID())
+		}
+	})
+}
+
+func showPerson(cell *htmlb.Element, p *person.Person) {
+	cell.E("a href=/people/%d up-target=main>%s", p.ID(), p.SortName())
+	cell.E("span class=duplicatesID> [%d]", p.ID())
+	if p.Email() != "" {
+		cell.E("div class=duplicatesDetail").T(p.Email())
+	}
+	if p.CellPhone() != "" {
+		cell.E("div class=duplicatesDetail").T(p.CellPhone())
+	}
+}
@@ -0,0 +1,128 @@
+package duplicates
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personmerge"
+	"sunnyvaleserv.org/portal/ui/form"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+var conflictLabels = map[person.Fields]string{
+	person.FInformalName: "Informal Name",
+	person.FFormalName:   "Formal Name",
+	person.FSortName:     "Sort Name",
+	person.FCallSign:     "Call Sign",
+	person.FPronouns:     "Pronouns",
+	person.FEmail:        "Email",
+	person.FEmail2:       "Alt. Email",
+	person.FCellPhone:    "Cell Phone",
+	person.FHomePhone:    "Home Phone",
+	person.FWorkPhone:    "Work Phone",
+	person.FVolgisticsID: "Volgistics ID",
+	person.FBirthdate:    "Birthdate",
+	person.FPassword:     "Password",
+	person.FAddresses:    "Addresses",
+}
+
+// HandleMerge handles /admin/duplicates/$keep/$drop requests.
+func HandleMerge(r *request.Request, keepstr, dropstr string) {
+	var (
+		user *person.Person
+		keep *person.Person
+		drop *person.Person
+		m    *personmerge.Merge
+		f    form.Form
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if keep = person.WithID(r, person.ID(util.ParseID(keepstr)), personmerge.PersonFields); keep == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if drop = person.WithID(r, person.ID(util.ParseID(dropstr)), personmerge.PersonFields); drop == nil || drop.ID() == keep.ID() {
+		errpage.NotFound(r, user)
+		return
+	}
+	if keep.ID() == person.AdminID || drop.ID() == person.AdminID {
+		errpage.Forbidden(r, user)
+		return
+	}
+	m = personmerge.Prepare(keep, drop)
+	f.Attrs = "method=POST up-target=main"
+	f.Dialog = true
+	f.Title = "Merge People"
+	f.Buttons = []*form.Button{{
+		Label: "Merge",
+		OnClick: func() bool {
+			r.Transaction(func() {
+				m.Apply(r)
+			})
+			Render(r, user)
+			return true
+		},
+	}}
+	f.Rows = []form.Row{&form.MessageRow{
+		HTML: fmt.Sprintf(`%s [%d] will be merged into %s [%d].  All roles, event attendance, shift signups, class registrations, certificates, list subscriptions, text messages, notes, emergency contacts, background checks, DSW registrations, availability, passkeys, API tokens, and sign-on settings of the former will be moved to the latter, and the former will be deleted.  This cannot be undone.  <a href=/admin/duplicates/%d/%d up-target=.form>Merge the other way</a>.`,
+			html.EscapeString(drop.InformalName()), drop.ID(), html.EscapeString(keep.InformalName()), keep.ID(), drop.ID(), keep.ID()),
+	}}
+	if losses := m.Losses(r); len(losses) != 0 {
+		f.Rows = append(f.Rows, &form.MessageRow{
+			HTML: fmt.Sprintf(`Both people have a %s, so that of %s [%d] will be discarded.`,
+				html.EscapeString(strings.Join(losses, ", ")), html.EscapeString(drop.InformalName()), drop.ID()),
+		})
+	}
+	for _, field := range personmerge.ConflictFields {
+		if m.Conflicts&field == 0 {
+			continue
+		}
+		kv, dv := m.Values(field)
+		choice := "keep"
+		f.Rows = append(f.Rows, &conflictRow{form.RadioGroupRow[string]{
+			LabeledRow: form.LabeledRow{
+				RowID: fmt.Sprintf("duplicatesConflict%d", field),
+				Label: conflictLabels[field],
+			},
+			Name:    fmt.Sprintf("conflict%d", field),
+			ValueP:  &choice,
+			Options: []string{"keep", "drop"},
+			LabelFunc: func(_ *request.Request, v string) string {
+				if v == "keep" {
+					return fmt.Sprintf("%s [%d]", kv, keep.ID())
+				}
+				return fmt.Sprintf("%s [%d]", dv, drop.ID())
+			},
+			Validate: form.NoValidate,
+		}, m, field})
+	}
+	f.Handle(r)
+}
+
+type conflictRow struct {
+	form.RadioGroupRow[string]
+	m     *personmerge.Merge
+	field person.Fields
+}
+
+func (cr *conflictRow) Read(r *request.Request) bool {
+	if !cr.RadioGroupRow.Read(r) {
+		return false
+	}
+	if *cr.ValueP == "" {
+		cr.Error = fmt.Sprintf("Please choose the %s to keep.", strings.ToLower(cr.Label))
+		return false
+	}
+	cr.m.Resolve(cr.field, *cr.ValueP == "drop")
+	return true
+}
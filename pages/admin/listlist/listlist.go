@@ -35,7 +35,12 @@ func Render(r *request.Request, user *person.Person) {
 			{Name: "Lists", URL: "/admin/lists", Target: "main", Active: true},
 			{Name: "Venues", URL: "/admin/venues", Target: "main"},
 			{Name: "Classes", URL: "/admin/classes", Target: "main"},
+			{Name: "Referrals", URL: "/admin/referrals", Target: "main"},
 			{Name: "Redirects", URL: "/admin/redirects", Target: "main"},
+			{Name: "Duplicates", URL: "/admin/duplicates", Target: "main"},
+			{Name: "Archive", URL: "/admin/archive", Target: "main"},
+			{Name: "Lockouts", URL: "/admin/lockouts", Target: "main"},
+			{Name: "Webhooks", URL: "/admin/webhooks", Target: "main"},
 		},
 	}
 	r.HTMLNoCache()
@@ -46,6 +51,7 @@ func Render(r *request.Request, user *person.Person) {
 		row.E("div>Sub")
 		row.E("div>Unsub")
 		row.E("div>Send")
+		row.E("div")
 		list.All(r, func(l *list.List) {
 			var name string
 			var senderCount, subCount, unsubCount int
@@ -83,6 +89,7 @@ func Render(r *request.Request, user *person.Person) {
 			} else {
 				row.E("div>0")
 			}
+			row.E("a href=/admin/lists/%d/history class=listlistHistory up-layer=new up-size=grow up-dismissable=key up-history=false>history", l.ID)
 		})
 		main.E("div class=listlistButtons").
 			E("a href=/admin/lists/NEW up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-primary'>Add List")
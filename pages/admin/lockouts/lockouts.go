@@ -0,0 +1,130 @@
+// Package lockouts handles the /admin/lockouts page, through which the
+// webmaster sees the sources of recent failed login attempts and the accounts
+// that are locked out, and can unlock them.
+package lockouts
+
+import (
+	"net/http"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/pages/login"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const lockoutPersonFields = person.FID | person.FInformalName | person.FSortName | person.FEmail | person.FBadLoginCount | person.FBadLoginTime
+
+// Handle handles /admin/lockouts requests.
+func Handle(r *request.Request) {
+	var (
+		user *person.Person
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if !auth.CheckCSRF(r, user) {
+		return
+	}
+	if r.Method == http.MethodPost {
+		if source := r.FormValue("source"); source != "" {
+			auth.ClearLoginThrottle(r, source)
+		}
+		if p := person.WithID(r, person.ID(util.ParseID(r.FormValue("person"))), lockoutPersonFields); p != nil {
+			login.Unlock(r, p)
+		}
+	}
+	Render(r, user)
+}
+
+// Render renders the lockouts page.
+func Render(r *request.Request, user *person.Person) {
+	var opts = ui.PageOpts{
+		Title:    "Lockouts",
+		MenuItem: "admin",
+		Tabs: []ui.PageTab{
+			{Name: "Roles", URL: "/admin/roles", Target: "main"},
+			{Name: "Lists", URL: "/admin/lists", Target: "main"},
+			{Name: "Venues", URL: "/admin/venues", Target: "main"},
+			{Name: "Classes", URL: "/admin/classes", Target: "main"},
+			{Name: "Referrals", URL: "/admin/referrals", Target: "main"},
+			{Name: "Redirects", URL: "/admin/redirects", Target: "main"},
+			{Name: "Duplicates", URL: "/admin/duplicates", Target: "main"},
+			{Name: "Archive", URL: "/admin/archive", Target: "main"},
+			{Name: "Lockouts", URL: "/admin/lockouts", Target: "main", Active: true},
+			{Name: "Webhooks", URL: "/admin/webhooks", Target: "main"},
+		},
+	}
+	r.HTMLNoCache()
+	ui.Page(r, user, opts, func(main *htmlb.Element) {
+		var grid *htmlb.Element
+
+		main.E("h2 class=lockoutsHeader>Failed Logins by Source")
+		for _, src := range auth.LoginThrottleSources(r) {
+			if grid == nil {
+				grid = main.E("div class=lockoutsGrid")
+				row := grid.E("div class=lockoutsHeading")
+				row.E("div>Source")
+				row.E("div>Failures")
+				row.E("div>Latest")
+				row.E("div>Throttled Until")
+				row.E("div")
+			}
+			row := grid.E("div class=lockoutsRow")
+			row.E("div").T(src.Source)
+			row.E("div").TF("%d", src.Failures)
+			row.E("div").T(src.Last.Format("15:04:05"))
+			if !src.Until.IsZero() {
+				row.E("div").T(src.Until.Format("15:04:05"))
+			} else {
+				row.E("div class=lockoutsNone>not throttled")
+			}
+			unlockButton(r, row.E("div"), "source", src.Source)
+		}
+		if grid == nil {
+			main.E("div class=lockoutsNone>There have been no failed logins in the last hour.")
+		}
+		main.E("h2 class=lockoutsHeader>Locked Accounts")
+		grid = nil
+		person.All(r, lockoutPersonFields, func(p *person.Person) {
+			if !login.LockedOut(p) {
+				return
+			}
+			if grid == nil {
+				grid = main.E("div class=lockoutsGrid")
+				row := grid.E("div class=lockoutsHeading")
+				row.E("div>Person")
+				row.E("div>Failures")
+				row.E("div>Latest")
+				row.E("div")
+				row.E("div")
+			}
+			row := grid.E("div class=lockoutsRow")
+			cell := row.E("div")
+			cell.E("a href=/people/%d up-target=main>%s", p.ID(), p.SortName())
+			if p.Email() != "" {
+				cell.E("div class=lockoutsNone").T(p.Email())
+			}
+			row.E("div").TF("%d", p.BadLoginCount())
+			row.E("div").T(p.BadLoginTime().Format("2006-01-02 15:04:05"))
+			row.E("div")
+			unlockButton(r, row.E("div"), "person", p.ID())
+		})
+		if grid == nil {
+			main.E("div class=lockoutsNone>No accounts are locked out.")
+		}
+	})
+}
+
+func unlockButton(r *request.Request, cell *htmlb.Element, name string, value any) {
+	form := cell.E("form method=POST action=/admin/lockouts up-target=main")
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	form.E("button type=submit name=%s value=%v class='sbtn sbtn-small sbtn-primary'>Unlock", name, value)
+}
@@ -0,0 +1,149 @@
+package referraledit
+
+import (
+	"strings"
+
+	"sunnyvaleserv.org/portal/pages/admin/referrallist"
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/referral"
+	"sunnyvaleserv.org/portal/ui/form"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// Handle handles /admin/referrals/$id requests, where $id may be "NEW".
+func Handle(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		s    *referral.Source
+		us   *referral.Source
+		f    form.Form
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	f.Attrs = "method=POST up-target=main"
+	f.Dialog = true
+	f.Buttons = []*form.Button{{
+		Label: "Save",
+		OnClick: func() bool {
+			r.Transaction(func() {
+				if s == nil {
+					referral.Create(r, us)
+				} else {
+					s.Update(r, us)
+				}
+			})
+			referrallist.Render(r, user)
+			return true
+		},
+	}}
+	if idstr == "NEW" {
+		us = new(referral.Source)
+		f.Title = "New Referral Source"
+	} else {
+		if s = referral.WithID(r, referral.ID(util.ParseID(idstr))); s == nil {
+			errpage.NotFound(r, user)
+			return
+		}
+		us = s.Clone()
+		f.Title = "Edit Referral Source"
+		if !s.InUse(r) {
+			f.Buttons = append(f.Buttons, &form.Button{
+				Name: "delete", Label: "Delete", Style: "danger",
+				OnClick: func() bool {
+					r.Transaction(func() {
+						s.Delete(r)
+					})
+					referrallist.Render(r, user)
+					return true
+				},
+			})
+		}
+	}
+	f.Rows = []form.Row{
+		&nameRow{form.TextInputRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "referraleditEnName",
+				Label: "Name",
+			},
+			Name:   "enName",
+			ValueP: &us.EnName,
+		}, "The name is required."},
+		&nameRow{form.TextInputRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "referraleditEsName",
+				Label: "Spanish",
+			},
+			Name:   "esName",
+			ValueP: &us.EsName,
+		}, "The Spanish name is required."},
+		&codeRow{form.TextInputRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "referraleditCode",
+				Label: "Campaign Code",
+				Help:  "Optional.  Registrants from class page URLs with ?src= followed by this code have this source selected automatically.",
+			},
+			Name:   "code",
+			ValueP: &us.Code,
+		}, us},
+		&form.CheckboxesRow{
+			LabeledRow: form.LabeledRow{Label: "Flags"},
+			Validate:   form.NoValidate,
+			Boxes: []*form.Checkbox{{
+				Name:     "other",
+				Label:    "Ask registrants to specify",
+				CheckedP: &us.Other,
+			}, {
+				Name:     "archived",
+				Label:    "No longer offered",
+				CheckedP: &us.Archived,
+			}},
+		},
+	}
+	f.Handle(r)
+}
+
+type nameRow struct {
+	form.TextInputRow
+	message string
+}
+
+func (nr *nameRow) Read(r *request.Request) bool {
+	if !nr.TextInputRow.Read(r) {
+		return false
+	}
+	if *nr.ValueP == "" {
+		nr.Error = nr.message
+		return false
+	}
+	return true
+}
+
+type codeRow struct {
+	form.TextInputRow
+	us *referral.Source
+}
+
+func (cr *codeRow) Read(r *request.Request) bool {
+	if !cr.TextInputRow.Read(r) {
+		return false
+	}
+	cr.us.Code = strings.ToLower(cr.us.Code)
+	if cr.us.Code == "" {
+		return true
+	} else if !referral.ValidCode(cr.us.Code) {
+		cr.Error = "The campaign code must contain only letters, digits, hyphens, and underscores."
+		return false
+	} else if cr.us.DuplicateCode(r) {
+		cr.Error = "Another source has this campaign code."
+		return false
+	}
+	return true
+}
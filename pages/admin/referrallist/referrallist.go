@@ -0,0 +1,95 @@
+package referrallist
+
+import (
+	"net/http"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/referral"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// Handle handles /admin/referrals requests.  A GET shows the list of referral
+// sources.  A POST with a moveup parameter moves the specified source one place
+// earlier in the list.
+func Handle(r *request.Request) {
+	var user *person.Person
+
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if r.Method == http.MethodPost {
+		if s := referral.WithID(r, referral.ID(util.ParseID(r.FormValue("moveup")))); s != nil {
+			r.Transaction(func() {
+				s.MoveUp(r)
+			})
+		}
+	}
+	Render(r, user)
+}
+
+// Render renders the list of referral sources.
+func Render(r *request.Request, user *person.Person) {
+	var opts = ui.PageOpts{
+		Title:    "Referral Sources",
+		MenuItem: "admin",
+		Tabs: []ui.PageTab{
+			{Name: "Roles", URL: "/admin/roles", Target: "main"},
+			{Name: "Lists", URL: "/admin/lists", Target: "main"},
+			{Name: "Venues", URL: "/admin/venues", Target: "main"},
+			{Name: "Classes", URL: "/admin/classes", Target: "main"},
+			{Name: "Referrals", URL: "/admin/referrals", Target: "main", Active: true},
+			{Name: "Redirects", URL: "/admin/redirects", Target: "main"},
+			{Name: "Duplicates", URL: "/admin/duplicates", Target: "main"},
+			{Name: "Archive", URL: "/admin/archive", Target: "main"},
+			{Name: "Lockouts", URL: "/admin/lockouts", Target: "main"},
+			{Name: "Webhooks", URL: "/admin/webhooks", Target: "main"},
+		},
+	}
+	r.HTMLNoCache()
+	ui.Page(r, user, opts, func(main *htmlb.Element) {
+		var first = true
+
+		main.E("div class=referrallistNote>Class registrants are asked how they found out about the class, and choose from this list.  A campaign code selects its source automatically when it is given in the URL of a class page, as in %s/pep?src=code.", config.Get("siteURL"))
+		grid := main.E("div class=referrallistGrid")
+		row := grid.E("div class=referrallistHeading")
+		row.E("div>Name")
+		row.E("div>Spanish")
+		row.E("div>Campaign Code")
+		row.E("div>Flags")
+		row.E("div")
+		referral.All(r, func(s *referral.Source) {
+			row = grid.E("div class=referrallistRow")
+			row.E("div").E("a href=/admin/referrals/%d up-layer=new up-size=grow up-dismissable=key up-history=false", s.ID).T(s.EnName)
+			row.E("div").T(s.EsName)
+			row.E("div").T(s.Code)
+			switch {
+			case s.Archived:
+				row.E("div>archived")
+			case s.Other:
+				row.E("div>asks for details")
+			default:
+				row.E("div")
+			}
+			if !first {
+				form := row.E("form method=POST up-target=main")
+				form.E("input type=hidden name=csrf value=%s", r.CSRF)
+				form.E("button type=submit name=moveup value=%d class='sbtn sbtn-xsmall sbtn-secondary' title='Move up'>↑", s.ID)
+			} else {
+				row.E("div")
+			}
+			first = false
+		})
+		main.E("div class=referrallistButtons").
+			E("a href=/admin/referrals/NEW up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-primary'>Add Source")
+	})
+}
@@ -1,6 +1,7 @@
 package roleedit
 
 import (
+	"slices"
 	"strconv"
 
 	"sunnyvaleserv.org/portal/pages/admin/rolelist"
@@ -140,6 +141,14 @@ func Handle(r *request.Request, idstr string) {
 			_, ok := impliers[rl.ID()]
 			return !ok && rl.ID() != ur.ID
 		}, "Implies", "implies", &ur.Implies, false),
+		&permissionsRow{CheckboxesRow: form.CheckboxesRow{
+			LabeledRow: form.LabeledRow{
+				Label: "Permissions",
+				Help:  "Permissions granted to people holding this role, in addition to those granted by its privilege level",
+			},
+			Validate: form.NoValidate,
+			Name:     "permissions",
+		}, ur: ur},
 		&listsRow{form.LabeledRow{Label: "Lists"}, ur},
 	}
 	f.Handle(r)
@@ -196,6 +205,46 @@ func (or *orgRow) Read(r *request.Request) bool {
 	return true
 }
 
+type permissionsRow struct {
+	form.CheckboxesRow
+	ur       *role.Updater
+	checkeds []bool
+}
+
+func (pr *permissionsRow) init() {
+	pr.Boxes = make([]*form.Checkbox, len(enum.AllPermissions))
+	pr.checkeds = make([]bool, len(enum.AllPermissions))
+	for i, perm := range enum.AllPermissions {
+		pr.Boxes[i] = &form.Checkbox{
+			Value:    string(perm),
+			Label:    perm.Label(),
+			CheckedP: &pr.checkeds[i],
+		}
+	}
+}
+
+func (pr *permissionsRow) Emit(r *request.Request, parent *htmlb.Element, focus bool) {
+	if pr.Boxes == nil {
+		pr.init()
+		for i, perm := range enum.AllPermissions {
+			pr.checkeds[i] = slices.Contains(pr.ur.Permissions, perm)
+		}
+	}
+	pr.CheckboxesRow.Emit(r, parent, focus)
+}
+
+func (pr *permissionsRow) Read(r *request.Request) bool {
+	pr.init()
+	pr.CheckboxesRow.Read(r)
+	pr.ur.Permissions = nil
+	for i, perm := range enum.AllPermissions {
+		if pr.checkeds[i] {
+			pr.ur.Permissions = append(pr.ur.Permissions, perm)
+		}
+	}
+	return true
+}
+
 type listsRow struct {
 	form.LabeledRow
 	ur *role.Updater
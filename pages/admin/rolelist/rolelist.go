@@ -36,7 +36,12 @@ func Render(r *request.Request, user *person.Person) {
 			{Name: "Lists", URL: "/admin/lists", Target: "main"},
 			{Name: "Venues", URL: "/admin/venues", Target: "main"},
 			{Name: "Classes", URL: "/admin/classes", Target: "main"},
+			{Name: "Referrals", URL: "/admin/referrals", Target: "main"},
 			{Name: "Redirects", URL: "/admin/redirects", Target: "main"},
+			{Name: "Duplicates", URL: "/admin/duplicates", Target: "main"},
+			{Name: "Archive", URL: "/admin/archive", Target: "main"},
+			{Name: "Lockouts", URL: "/admin/lockouts", Target: "main"},
+			{Name: "Webhooks", URL: "/admin/webhooks", Target: "main"},
 		},
 	}
 	r.HTMLNoCache()
@@ -55,6 +60,7 @@ func Render(r *request.Request, user *person.Person) {
 			ndiv := row.E("div")
 			ndiv.E("a href=/admin/roles/%d up-layer=new up-size=grow up-dismissable=key up-history=false>%s", rl.ID(), rl.Name())
 			ndiv.E("a href=/people?role=%d class=rolelistCount up-target=.pageCanvas>%d", rl.ID(), personrole.PeopleCountForRole(r, rl.ID()))
+			ndiv.E("a href=/admin/roles/%d/history class=rolelistHistory up-layer=new up-size=grow up-dismissable=key up-history=false>history", rl.ID())
 		})
 		main.E("div class=rolelistButtons").
 			E("a href=/admin/roles/NEW up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-primary'>Add Role")
@@ -0,0 +1,184 @@
+package webhookedit
+
+import (
+	"html"
+	"net/url"
+	"slices"
+
+	"sunnyvaleserv.org/portal/pages/admin/webhooklist"
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/webhook"
+	"sunnyvaleserv.org/portal/ui/form"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// Handle handles /admin/webhooks/$id requests, where $id may be "NEW".
+func Handle(r *request.Request, idstr string) {
+	var (
+		user      *person.Person
+		w         *webhook.Webhook
+		uw        *webhook.Updater
+		f         form.Form
+		newSecret bool
+		secret    string
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	f.Attrs = "method=POST up-target=main"
+	f.Dialog = true
+	f.Buttons = []*form.Button{{
+		Label: "Save",
+		OnClick: func() bool {
+			if w == nil || newSecret {
+				uw.Secret = util.RandomToken()
+			}
+			r.Transaction(func() {
+				if w == nil {
+					webhook.Create(r, uw)
+				} else {
+					w.Update(r, uw)
+				}
+			})
+			webhooklist.Render(r, user)
+			return true
+		},
+	}}
+	if idstr == "NEW" {
+		uw = new(webhook.Updater)
+		f.Title = "New Webhook"
+		secret = "A secret will be generated when the webhook is saved."
+	} else {
+		if w = webhook.WithID(r, webhook.ID(util.ParseID(idstr))); w == nil {
+			errpage.NotFound(r, user)
+			return
+		}
+		uw = w.Updater()
+		f.Title = "Edit Webhook"
+		secret = "<code>" + html.EscapeString(w.Secret) + "</code>"
+		f.Buttons = append(f.Buttons, &form.Button{
+			Name: "delete", Label: "Delete", Style: "danger",
+			OnClick: func() bool {
+				r.Transaction(func() {
+					w.Delete(r)
+				})
+				webhooklist.Render(r, user)
+				return true
+			},
+		})
+	}
+	f.Rows = []form.Row{
+		&urlRow{form.TextInputRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "webhookeditURL",
+				Label: "URL",
+				Help:  "Notifications are POSTed to this URL.",
+			},
+			Name:   "url",
+			ValueP: &uw.URL,
+		}, uw},
+		newEventsRow(uw),
+		&form.MessageRow{
+			LabeledRow: form.LabeledRow{
+				Label: "Secret",
+				Help:  "Each notification is signed with this secret.  See the X-SERV-Signature header.",
+			},
+			HTML: secret,
+		},
+	}
+	if w != nil {
+		f.Rows = append(f.Rows, &form.CheckboxesRow{
+			Validate: form.NoValidate,
+			Boxes: []*form.Checkbox{{
+				Name:     "newsecret",
+				Label:    "Generate a new secret",
+				CheckedP: &newSecret,
+			}},
+		})
+	}
+	f.Rows = append(f.Rows, &form.FlagsRow[webhook.Flag]{
+		CheckboxesRow: form.CheckboxesRow{
+			LabeledRow: form.LabeledRow{Label: "Flags"},
+			Validate:   form.NoValidate,
+			Name:       "flags",
+		},
+		ValueP: &uw.Flags,
+		Flags:  []webhook.Flag{webhook.Disabled},
+		LabelFunc: func(_ *request.Request, v webhook.Flag) string {
+			return map[webhook.Flag]string{
+				webhook.Disabled: "Disabled (notifications are queued but not sent)",
+			}[v]
+		},
+	})
+	f.Handle(r)
+}
+
+type urlRow struct {
+	form.TextInputRow
+	uw *webhook.Updater
+}
+
+func (ur *urlRow) Read(r *request.Request) bool {
+	if !ur.TextInputRow.Read(r) {
+		return false
+	}
+	if ur.uw.URL == "" {
+		ur.Error = "The URL is required."
+		return false
+	} else if u, err := url.Parse(ur.uw.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		ur.Error = "The URL must be a valid http or https URL."
+		return false
+	}
+	return true
+}
+
+type eventsRow struct {
+	form.CheckboxesRow
+	uw       *webhook.Updater
+	checkeds []bool
+}
+
+func newEventsRow(uw *webhook.Updater) (er *eventsRow) {
+	er = &eventsRow{
+		CheckboxesRow: form.CheckboxesRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "webhookeditEvents",
+				Label: "Events",
+			},
+			Name: "event",
+		},
+		uw:       uw,
+		checkeds: make([]bool, len(webhook.AllEvents)),
+	}
+	for i, e := range webhook.AllEvents {
+		er.checkeds[i] = slices.Contains(uw.Events, e)
+		er.Boxes = append(er.Boxes, &form.Checkbox{
+			Value:    e,
+			Label:    webhook.EventLabel(e),
+			CheckedP: &er.checkeds[i],
+		})
+	}
+	return er
+}
+
+func (er *eventsRow) Read(r *request.Request) bool {
+	er.CheckboxesRow.Read(r)
+	er.uw.Events = nil
+	for i, e := range webhook.AllEvents {
+		if er.checkeds[i] {
+			er.uw.Events = append(er.uw.Events, e)
+		}
+	}
+	if len(er.uw.Events) == 0 {
+		er.Error = "At least one event must be selected."
+		return false
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package webhooklist
+
+import (
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/webhook"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// Get handles GET /admin/webhooks requests.
+func Get(r *request.Request) {
+	var (
+		user *person.Person
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	Render(r, user)
+}
+
+func Render(r *request.Request, user *person.Person) {
+	var opts = ui.PageOpts{
+		Title:    "Webhooks",
+		MenuItem: "admin",
+		Tabs: []ui.PageTab{
+			{Name: "Roles", URL: "/admin/roles", Target: "main"},
+			{Name: "Lists", URL: "/admin/lists", Target: "main"},
+			{Name: "Venues", URL: "/admin/venues", Target: "main"},
+			{Name: "Classes", URL: "/admin/classes", Target: "main"},
+			{Name: "Referrals", URL: "/admin/referrals", Target: "main"},
+			{Name: "Redirects", URL: "/admin/redirects", Target: "main"},
+			{Name: "Duplicates", URL: "/admin/duplicates", Target: "main"},
+			{Name: "Archive", URL: "/admin/archive", Target: "main"},
+			{Name: "Lockouts", URL: "/admin/lockouts", Target: "main"},
+			{Name: "Webhooks", URL: "/admin/webhooks", Target: "main", Active: true},
+		},
+	}
+	r.HTMLNoCache()
+	ui.Page(r, user, opts, func(main *htmlb.Element) {
+		var count int
+
+		grid := main.E("div class=webhooklistGrid")
+		row := grid.E("div class=webhooklistHeading")
+		row.E("div>URL")
+		row.E("div>Events")
+		row.E("div>Pending")
+		row.E("div>Failed")
+		row.E("div")
+		webhook.All(r, func(w *webhook.Webhook) {
+			count++
+			row = grid.E("div class=webhooklistRow")
+			cell := row.E("div")
+			cell.E("a href=/admin/webhooks/%d up-layer=new up-size=grow up-dismissable=key up-history=false", w.ID).T(w.URL)
+			if w.Flags&webhook.Disabled != 0 {
+				cell.E("div class=webhooklistDisabled>disabled")
+			}
+			cell = row.E("div")
+			for _, e := range w.Events {
+				cell.E("div").T(e)
+			}
+			pending, failed := w.Counts(r)
+			row.E("div").TF("%d", pending)
+			row.E("div", failed != 0, "class=webhooklistFailed").TF("%d", failed)
+			row.E("div").E("a href=/admin/webhooks/%d/log up-target=main class='sbtn sbtn-small sbtn-secondary'>Delivery Log", w.ID)
+		})
+		if count == 0 {
+			main.E("div class=webhooklistNone>No webhooks are defined.")
+		}
+		main.E("div class=webhooklistButtons").
+			E("a href=/admin/webhooks/NEW up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-primary'>Add Webhook")
+	})
+}
@@ -0,0 +1,123 @@
+// Package webhooklog handles the /admin/webhooks/$id/log page, through which
+// the webmaster sees the recent deliveries to a webhook and can retry those
+// that have not succeeded.
+package webhooklog
+
+import (
+	"net/http"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/webhook"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// logLimit is the number of deliveries shown in the log.
+const logLimit = 100
+
+// Handle handles /admin/webhooks/$id/log requests.
+func Handle(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		w    *webhook.Webhook
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if !auth.CheckCSRF(r, user) {
+		return
+	}
+	if w = webhook.WithID(r, webhook.ID(util.ParseID(idstr))); w == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if r.Method == http.MethodPost {
+		if d := webhook.DeliveryWithID(r, webhook.DeliveryID(util.ParseID(r.FormValue("retry")))); d != nil && d.Webhook == w.ID {
+			d.Retry(r)
+		}
+	}
+	Render(r, user, w)
+}
+
+// Render renders the delivery log page for the specified webhook.
+func Render(r *request.Request, user *person.Person, w *webhook.Webhook) {
+	var opts = ui.PageOpts{
+		Title:    "Webhook Delivery Log",
+		MenuItem: "admin",
+		Tabs: []ui.PageTab{
+			{Name: "Roles", URL: "/admin/roles", Target: "main"},
+			{Name: "Lists", URL: "/admin/lists", Target: "main"},
+			{Name: "Venues", URL: "/admin/venues", Target: "main"},
+			{Name: "Classes", URL: "/admin/classes", Target: "main"},
+			{Name: "Referrals", URL: "/admin/referrals", Target: "main"},
+			{Name: "Redirects", URL: "/admin/redirects", Target: "main"},
+			{Name: "Duplicates", URL: "/admin/duplicates", Target: "main"},
+			{Name: "Archive", URL: "/admin/archive", Target: "main"},
+			{Name: "Lockouts", URL: "/admin/lockouts", Target: "main"},
+			{Name: "Webhooks", URL: "/admin/webhooks", Target: "main", Active: true},
+		},
+	}
+	r.HTMLNoCache()
+	ui.Page(r, user, opts, func(main *htmlb.Element) {
+		var grid *htmlb.Element
+
+		head := main.E("div class=webhooklogHeader")
+		head.E("a href=/admin/webhooks up-target=main class='sbtn sbtn-small sbtn-secondary'>Back")
+		head.E("span class=webhooklogURL").T(w.URL)
+		if w.Flags&webhook.Disabled != 0 {
+			head.E("span class=webhooklogNone>(disabled)")
+		}
+		w.Deliveries(r, logLimit, func(d *webhook.Delivery) {
+			if grid == nil {
+				grid = main.E("div class=webhooklogGrid")
+				row := grid.E("div class=webhooklogHeading")
+				row.E("div>Created")
+				row.E("div>Event")
+				row.E("div>Attempts")
+				row.E("div>Last Response")
+				row.E("div>Result")
+				row.E("div")
+			}
+			row := grid.E("div class=webhooklogRow")
+			row.E("div").T(d.Created.Format("2006-01-02 15:04:05"))
+			cell := row.E("div")
+			details := cell.E("details")
+			details.E("summary").T(d.Event)
+			details.E("pre class=webhooklogPayload").T(d.Payload)
+			row.E("div").TF("%d", d.Attempts)
+			switch {
+			case d.Error != "":
+				row.E("div class=webhooklogError").T(d.Error)
+			case d.Status != 0:
+				row.E("div").TF("HTTP %d", d.Status)
+			default:
+				row.E("div class=webhooklogNone>none")
+			}
+			switch {
+			case !d.Delivered.IsZero():
+				row.E("div").T("delivered " + d.Delivered.Format("2006-01-02 15:04:05"))
+			case !d.NextAttempt.IsZero():
+				row.E("div").T("next attempt " + d.NextAttempt.Format("2006-01-02 15:04:05"))
+			default:
+				row.E("div class=webhooklogError>failed")
+			}
+			cell = row.E("div")
+			if d.Delivered.IsZero() {
+				form := cell.E("form method=POST action=/admin/webhooks/%d/log up-target=main", w.ID)
+				form.E("input type=hidden name=csrf value=%s", r.CSRF)
+				form.E("button type=submit name=retry value=%d class='sbtn sbtn-small sbtn-primary'>Retry", d.ID)
+			}
+		})
+		if grid == nil {
+			main.E("div class=webhooklogNone>No notifications have been sent to this webhook.")
+		}
+	})
+}
@@ -0,0 +1,116 @@
+// Package api implements version 1 of the JSON API, under /api/v1.  It is
+// meant for people who want to script against the portal.  Requests are
+// authenticated with personal API tokens (see the apitoken package), passed
+// as "Authorization: Bearer <token>", and the token's owner is held to the
+// same privilege rules as in the web interface.  The API is described by the
+// OpenAPI document at /api/v1/openapi.json.
+//
+// Like the rest of the site, the API uses only GET and POST methods.  POST
+// requests take a JSON object as their body.  Errors are reported with a JSON
+// object having a single "error" key.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/apitoken"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// maxBodySize is the largest request body we accept.
+const maxBodySize = 64 * 1024
+
+// Route handles /api/v1/* requests.  c is the list of path components after
+// /api/v1, padded with empty strings.
+func Route(r *request.Request, c []string) {
+	switch {
+	case c[0] == "openapi.json" && c[1] == "":
+		getOpenAPI(r)
+	case c[0] == "events" && c[1] == "":
+		getEvents(r)
+	case c[0] == "events" && c[1] != "" && c[2] == "":
+		getEvent(r, c[1])
+	case c[0] == "shifts" && c[1] != "" && c[2] == "signups" && c[3] == "":
+		handleSignups(r, c[1])
+	case c[0] == "people" && c[1] == "":
+		getPeople(r)
+	case c[0] == "people" && c[1] != "" && c[2] == "":
+		getPerson(r, c[1])
+	case c[0] == "roles" && c[1] == "":
+		getRoles(r)
+	case c[0] == "roles" && c[1] != "" && c[2] == "people" && c[3] == "":
+		getRolePeople(r, c[1])
+	case c[0] == "lists" && c[1] == "":
+		getLists(r)
+	case c[0] == "lists" && c[1] != "" && c[2] == "people" && c[3] == "":
+		getListPeople(r, c[1])
+	case c[0] == "hours" && c[1] == "":
+		handleHours(r)
+	default:
+		writeError(r, http.StatusNotFound, "no such API endpoint")
+	}
+}
+
+// apiUser authenticates the request and returns the token owner, with the
+// specified fields.  If authentication fails, or the token lacks the scope,
+// it sends the error response and returns nil.
+func apiUser(r *request.Request, fields person.Fields, scope apitoken.Scope) (user *person.Person) {
+	user, err := auth.APIUser(r, fields, scope)
+	switch {
+	case err == nil:
+		return user
+	case errors.Is(err, auth.ErrAPIThrottled):
+		writeError(r, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, auth.ErrAPIScope):
+		r.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+string(scope)+`"`)
+		writeError(r, http.StatusForbidden, err.Error())
+	default:
+		r.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		writeError(r, http.StatusUnauthorized, err.Error())
+	}
+	return nil
+}
+
+// checkMethod returns whether the request method is one of those allowed.  If
+// not, it sends an error response.
+func checkMethod(r *request.Request, allowed ...string) bool {
+	for _, m := range allowed {
+		if r.Method == m {
+			return true
+		}
+	}
+	writeError(r, http.StatusMethodNotAllowed, "method not allowed")
+	return false
+}
+
+// readBody decodes the JSON body of a POST request into v.  If it can't, it
+// sends an error response and returns false.
+func readBody(r *request.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(r, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// writeJSON sends a successful response with v as its JSON body.
+func writeJSON(r *request.Request, v any) {
+	r.Header().Set("Content-Type", "application/json; charset=utf-8")
+	r.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(r)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// writeError sends an error response.
+func writeError(r *request.Request, status int, message string) {
+	r.Header().Set("Content-Type", "application/json; charset=utf-8")
+	r.WriteHeader(status)
+	json.NewEncoder(r).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/apitoken"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/shift"
+	"sunnyvaleserv.org/portal/store/shiftperson"
+	"sunnyvaleserv.org/portal/store/task"
+	"sunnyvaleserv.org/portal/store/venue"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// maxEventRange is the longest date range that can be requested in a single
+// event listing.
+const maxEventRange = 366 * 24 * time.Hour
+
+type venueData struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+type eventData struct {
+	ID         int         `json:"id"`
+	Name       string      `json:"name"`
+	Start      string      `json:"start"`
+	End        string      `json:"end"`
+	Venue      *venueData  `json:"venue"`
+	Activation string      `json:"activation,omitempty"`
+	Details    string      `json:"details,omitempty"`
+	Tasks      []*taskData `json:"tasks,omitempty"`
+}
+type taskData struct {
+	ID          int          `json:"id"`
+	Name        string       `json:"name"`
+	Org         string       `json:"org"`
+	Details     string       `json:"details,omitempty"`
+	RecordHours bool         `json:"recordHours"`
+	SignupsOpen bool         `json:"signupsOpen"`
+	Shifts      []*shiftData `json:"shifts"`
+}
+type shiftData struct {
+	ID       int        `json:"id"`
+	Start    string     `json:"start"`
+	End      string     `json:"end"`
+	Venue    *venueData `json:"venue"`
+	Min      uint       `json:"min"`
+	Max      uint       `json:"max"`
+	Count    int        `json:"count"`
+	SignedUp bool       `json:"signedUp"`
+}
+type signupPersonData struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+type signupsData struct {
+	Shift  int                 `json:"shift"`
+	People []*signupPersonData `json:"people"`
+}
+
+const apiEventFields = event.FID | event.FName | event.FStart | event.FEnd | event.FVenueURL | event.FActivation | event.FFlags
+const apiVenueFields = venue.FID | venue.FName | venue.FURL
+
+// makeVenueData returns the JSON data for a venue, or nil if there is none.
+// urlOverride is the event's venue URL, if any.
+func makeVenueData(v *venue.Venue, urlOverride string) *venueData {
+	if v == nil {
+		return nil
+	}
+	vd := &venueData{ID: int(v.ID()), Name: v.Name(), URL: v.URL()}
+	if urlOverride != "" {
+		vd.URL = urlOverride
+	}
+	return vd
+}
+
+// getEvents handles GET /api/v1/events requests.  It lists the events
+// starting within the date range given by the "start" and "end" parameters
+// (inclusive, in YYYY-MM-DD format), which default to the next month.
+func getEvents(r *request.Request) {
+	var events = []*eventData{}
+
+	if !checkMethod(r, http.MethodGet) || apiUser(r, 0, apitoken.ScopeEventsRead) == nil {
+		return
+	}
+	start, end, ok := dateRange(r)
+	if !ok {
+		writeError(r, http.StatusBadRequest, "invalid start or end date")
+		return
+	}
+	event.AllBetween(r, start.Format("2006-01-02"), end.AddDate(0, 0, 1).Format("2006-01-02"), apiEventFields, apiVenueFields, func(e *event.Event, v *venue.Venue) {
+		if e.Flags()&event.OtherHours != 0 {
+			return
+		}
+		events = append(events, &eventData{
+			ID: int(e.ID()), Name: e.Name(), Start: e.Start(), End: e.End(),
+			Venue: makeVenueData(v, e.VenueURL()), Activation: e.Activation(),
+		})
+	})
+	writeJSON(r, events)
+}
+
+// dateRange parses the "start" and "end" parameters of an event listing.
+func dateRange(r *request.Request) (start, end time.Time, ok bool) {
+	var err error
+
+	if s := r.FormValue("start"); s != "" {
+		if start, err = time.ParseInLocation("2006-01-02", s, time.Local); err != nil {
+			return start, end, false
+		}
+	} else {
+		now := time.Now()
+		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	}
+	if e := r.FormValue("end"); e != "" {
+		if end, err = time.ParseInLocation("2006-01-02", e, time.Local); err != nil {
+			return start, end, false
+		}
+	} else {
+		end = start.AddDate(0, 1, 0)
+	}
+	if end.Before(start) || end.Sub(start) > maxEventRange {
+		return start, end, false
+	}
+	return start, end, true
+}
+
+// getEvent handles GET /api/v1/events/$id requests.  It returns the event
+// with its tasks and shifts.
+func getEvent(r *request.Request, idstr string) {
+	const taskFields = task.FID | task.FName | task.FOrg | task.FDetails | task.FFlags
+	const shiftFields = shift.FID | shift.FStart | shift.FEnd | shift.FMin | shift.FMax
+	var (
+		user *person.Person
+		e    *event.Event
+		ed   *eventData
+	)
+	if !checkMethod(r, http.MethodGet) {
+		return
+	}
+	if user = apiUser(r, 0, apitoken.ScopeEventsRead); user == nil {
+		return
+	}
+	if e = event.WithID(r, event.ID(util.ParseID(idstr)), apiEventFields|event.FVenue|event.FDetails); e == nil || e.Flags()&event.OtherHours != 0 {
+		writeError(r, http.StatusNotFound, "no such event")
+		return
+	}
+	ed = &eventData{
+		ID: int(e.ID()), Name: e.Name(), Start: e.Start(), End: e.End(),
+		Activation: e.Activation(), Details: e.Details(), Tasks: []*taskData{},
+	}
+	if e.Venue() != 0 {
+		ed.Venue = makeVenueData(venue.WithID(r, e.Venue(), apiVenueFields), e.VenueURL())
+	}
+	task.AllForEvent(r, e.ID(), taskFields, func(t *task.Task) {
+		td := &taskData{
+			ID: int(t.ID()), Name: t.Name(), Org: t.Org().String(), Details: t.Details(),
+			RecordHours: t.Flags()&task.RecordHours != 0, SignupsOpen: t.Flags()&task.SignupsOpen != 0,
+			Shifts: []*shiftData{},
+		}
+		ed.Tasks = append(ed.Tasks, td)
+	})
+	for _, td := range ed.Tasks {
+		shift.AllForTask(r, task.ID(td.ID), shiftFields, apiVenueFields, func(s *shift.Shift, v *venue.Venue) {
+			sd := &shiftData{
+				ID: int(s.ID()), Start: s.Start(), End: s.End(), Venue: makeVenueData(v, ""),
+				Min: s.Min(), Max: s.Max(), SignedUp: shiftperson.Get(r, s.ID(), user.ID()) > 0,
+			}
+			td.Shifts = append(td.Shifts, sd)
+		})
+		for _, sd := range td.Shifts {
+			shiftperson.PeopleForShift(r, shift.ID(sd.ID), person.FID, func(*person.Person) { sd.Count++ })
+		}
+	}
+	writeJSON(r, ed)
+}
+
+// handleSignups handles GET and POST /api/v1/shifts/$id/signups requests.
+// GET lists the people signed up for the shift.  POST signs a person up for
+// the shift, or cancels their signup, as the web interface would.  The body
+// is {"signedUp": bool, "person": id}; the person defaults to the token owner,
+// and only people who can edit the event can sign up someone else.
+func handleSignups(r *request.Request, idstr string) {
+	const taskFields = task.FEvent | task.FOrg | shiftperson.EligibilityCheckerTaskFields | shiftperson.SignUpTaskFields
+	const shiftFields = shift.FTask | shift.FStart | shiftperson.EligibilityCheckerShiftFields | shiftperson.SignUpShiftFields
+	const personFields = shiftperson.EligibilityCheckerPersonFields | shiftperson.SignUpPersonFields
+	var (
+		user   *person.Person
+		s      *shift.Shift
+		scope  = apitoken.ScopeEventsRead
+		people = []*signupPersonData{}
+	)
+	if !checkMethod(r, http.MethodGet, http.MethodPost) {
+		return
+	}
+	if r.Method == http.MethodPost {
+		scope = apitoken.ScopeSignupsWrite
+	}
+	if user = apiUser(r, personFields, scope); user == nil {
+		return
+	}
+	if s = shift.WithID(r, shift.ID(util.ParseID(idstr)), shiftFields); s == nil {
+		writeError(r, http.StatusNotFound, "no such shift")
+		return
+	}
+	if r.Method == http.MethodPost {
+		var body struct {
+			SignedUp *bool `json:"signedUp"`
+			Person   int   `json:"person"`
+		}
+		if !readBody(r, &body) {
+			return
+		}
+		if body.SignedUp == nil {
+			writeError(r, http.StatusBadRequest, "signedUp is required")
+			return
+		}
+		t := task.WithID(r, s.Task(), taskFields)
+		e := event.WithID(r, t.Event(), shiftperson.SignUpEventFields)
+		editable := auth.Can(user, enum.PermEventEdit, t.Org())
+		p := user
+		if body.Person != 0 && person.ID(body.Person) != user.ID() {
+			if !editable {
+				writeError(r, http.StatusForbidden, "not allowed to sign up other people")
+				return
+			}
+			if p = person.WithID(r, person.ID(body.Person), personFields); p == nil {
+				writeError(r, http.StatusNotFound, "no such person")
+				return
+			}
+		}
+		want, have := *body.SignedUp, shiftperson.Get(r, s.ID(), p.ID()) > 0
+		if want != have {
+			var reason shiftperson.IneligibleReason
+			ec := shiftperson.NewEligibilityChecker(r, t, p, editable)
+			if want {
+				reason = ec.CanSignUp(s)
+			} else {
+				reason = ec.CanCancel(s)
+			}
+			if reason != "" {
+				writeError(r, http.StatusConflict, string(reason))
+				return
+			}
+			r.Transaction(func() {
+				if want {
+					shiftperson.SignUp(r, e, t, s, p)
+				} else {
+					shiftperson.Decline(r, e, t, s, p)
+				}
+			})
+		}
+	}
+	shiftperson.PeopleForShift(r, s.ID(), person.FID|person.FSortName, func(p *person.Person) {
+		people = append(people, &signupPersonData{ID: int(p.ID()), Name: p.SortName()})
+	})
+	writeJSON(r, &signupsData{Shift: int(s.ID()), People: people})
+}
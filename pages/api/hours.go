@@ -0,0 +1,171 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/people/activity"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/apitoken"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/task"
+	"sunnyvaleserv.org/portal/store/taskperson"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// maxMinutes is the most volunteer time that can be recorded for one person
+// on one task.
+const maxMinutes = 24 * 60
+
+type hoursJSON struct {
+	Person  int               `json:"person"`
+	Period  string            `json:"period"`
+	Total   uint              `json:"total"`
+	Entries []*hoursEntryJSON `json:"entries"`
+}
+type hoursEntryJSON struct {
+	Event    hoursEventJSON `json:"event"`
+	Task     hoursTaskJSON  `json:"task"`
+	Minutes  uint           `json:"minutes"`
+	Attended bool           `json:"attended"`
+	Credited bool           `json:"credited"`
+}
+type hoursEventJSON struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Start string `json:"start"`
+}
+type hoursTaskJSON struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Org  string `json:"org"`
+}
+
+// handleHours handles GET and POST /api/v1/hours requests.  GET returns the
+// volunteer hours of a person (the "person" parameter, defaulting to the
+// token owner) for a period (the "period" parameter, YYYY or YYYY-MM,
+// defaulting to the current month).  POST records the hours of a person on a
+// task; its body is {"person": id, "task": id, "minutes": n}.  The same rules
+// apply as on the web activity page:  people can see and record their own
+// hours, leaders can see anyone's and record them for tasks in their
+// organizations, and hours can be recorded only for tasks that record hours,
+// on events that have started, in the current reporting period.
+func handleHours(r *request.Request) {
+	var (
+		user  *person.Person
+		scope = apitoken.ScopeHoursRead
+	)
+	if !checkMethod(r, http.MethodGet, http.MethodPost) {
+		return
+	}
+	if r.Method == http.MethodPost {
+		scope = apitoken.ScopeHoursWrite
+	}
+	if user = apiUser(r, 0, scope); user == nil {
+		return
+	}
+	if r.Method == http.MethodPost {
+		postHours(r, user)
+	} else {
+		getHours(r, user)
+	}
+}
+
+func getHours(r *request.Request, user *person.Person) {
+	const eventFields = event.FID | event.FName | event.FStart
+	const taskFields = task.FID | task.FName | task.FOrg
+	var (
+		p      = user
+		start  string
+		end    string
+		result = hoursJSON{Entries: []*hoursEntryJSON{}}
+	)
+	if pidstr := r.FormValue("person"); pidstr != "" {
+		pid, _ := strconv.Atoi(pidstr)
+		if p = person.WithID(r, person.ID(pid), person.FID); p == nil || (p.ID() != user.ID() && !auth.Can(user, enum.PermEventAttendance, 0)) {
+			writeError(r, http.StatusNotFound, "no such person")
+			return
+		}
+	}
+	result.Person, result.Period = int(p.ID()), r.FormValue("period")
+	if result.Period == "" {
+		result.Period = time.Now().Format("2006-01")
+	}
+	if y, err := time.ParseInLocation("2006", result.Period, time.Local); err == nil {
+		start, end = y.Format("2006-01-02"), y.AddDate(1, 0, 0).Format("2006-01-02")
+	} else if m, err := time.ParseInLocation("2006-01", result.Period, time.Local); err == nil {
+		start, end = m.Format("2006-01-02"), m.AddDate(0, 1, 0).Format("2006-01-02")
+	} else {
+		writeError(r, http.StatusBadRequest, "invalid period")
+		return
+	}
+	taskperson.AllBetween(r, start, end, p.ID(), eventFields, taskFields, func(e *event.Event, t *task.Task, minutes uint, flags taskperson.Flag) {
+		result.Total += minutes
+		result.Entries = append(result.Entries, &hoursEntryJSON{
+			Event:    hoursEventJSON{ID: int(e.ID()), Name: e.Name(), Start: e.Start()},
+			Task:     hoursTaskJSON{ID: int(t.ID()), Name: t.Name(), Org: t.Org().String()},
+			Minutes:  minutes,
+			Attended: flags&taskperson.Attended != 0,
+			Credited: flags&taskperson.Credited != 0,
+		})
+	})
+	writeJSON(r, &result)
+}
+
+func postHours(r *request.Request, user *person.Person) {
+	const eventFields = taskperson.SetEventFields | event.FFlags
+	const taskFields = taskperson.SetTaskFields | task.FFlags | task.FOrg
+	var (
+		p    = user
+		e    *event.Event
+		t    *task.Task
+		body struct {
+			Person  int   `json:"person"`
+			Task    int   `json:"task"`
+			Minutes *uint `json:"minutes"`
+		}
+	)
+	if !readBody(r, &body) {
+		return
+	}
+	if body.Minutes == nil || *body.Minutes > maxMinutes || *body.Minutes%30 != 0 {
+		writeError(r, http.StatusBadRequest, "minutes must be a multiple of 30, no more than 1440")
+		return
+	}
+	if body.Person != 0 && person.ID(body.Person) != user.ID() {
+		if p = person.WithID(r, person.ID(body.Person), taskperson.SetPersonFields); p == nil {
+			writeError(r, http.StatusNotFound, "no such person")
+			return
+		}
+	}
+	if t = task.WithID(r, task.ID(body.Task), taskFields); t == nil {
+		writeError(r, http.StatusNotFound, "no such task")
+		return
+	}
+	e = event.WithID(r, t.Event(), eventFields)
+	if p.ID() != user.ID() && !auth.Can(user, enum.PermEventAttendance, t.Org()) {
+		writeError(r, http.StatusForbidden, "not allowed to record hours for that person on that task")
+		return
+	}
+	if t.Flags()&task.RecordHours == 0 {
+		writeError(r, http.StatusConflict, "that task does not record volunteer hours")
+		return
+	}
+	if e.Start() >= time.Now().AddDate(0, 0, 1).Format("2006-01-02T00:00") && e.Flags()&event.OtherHours == 0 {
+		writeError(r, http.StatusConflict, "that event has not started yet")
+		return
+	}
+	if cy, cm := activity.CurrentPeriod(); e.Start()[:7] < fmt.Sprintf("%d-%02d", cy, cm) && !auth.Can(user, enum.PermHoursEditPast, 0) {
+		writeError(r, http.StatusConflict, "hours for that event can no longer be changed")
+		return
+	}
+	_, flags := taskperson.Get(r, t.ID(), p.ID())
+	r.Transaction(func() {
+		taskperson.Set(r, e, t, p, *body.Minutes, flags)
+	})
+	writeJSON(r, map[string]any{"person": p.ID(), "task": t.ID(), "minutes": *body.Minutes})
+}
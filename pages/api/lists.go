@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+
+	"sunnyvaleserv.org/portal/store/apitoken"
+	"sunnyvaleserv.org/portal/store/list"
+	"sunnyvaleserv.org/portal/store/listperson"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+type listJSON struct {
+	ID         int    `json:"id"`
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	Subscribed bool   `json:"subscribed"`
+	Sender     bool   `json:"sender"`
+}
+type listMemberJSON struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Subscribed bool   `json:"subscribed"`
+	Sender     bool   `json:"sender"`
+}
+
+// getLists handles GET /api/v1/lists requests.  Webmasters get all lists;
+// everyone else gets the lists to which they are subscribed or can send.
+func getLists(r *request.Request) {
+	var (
+		user  *person.Person
+		lists = []*listJSON{}
+		byID  = make(map[list.ID]*listJSON)
+	)
+	if !checkMethod(r, http.MethodGet) {
+		return
+	}
+	if user = apiUser(r, 0, apitoken.ScopeListsRead); user == nil {
+		return
+	}
+	add := func(l *list.List) *listJSON {
+		if lj := byID[l.ID]; lj != nil {
+			return lj
+		}
+		lj := &listJSON{ID: int(l.ID), Type: l.Type.String(), Name: l.Name}
+		lists, byID[l.ID] = append(lists, lj), lj
+		return lj
+	}
+	if user.IsWebmaster() {
+		list.All(r, func(l *list.List) { add(l) })
+	}
+	listperson.SubscriptionsByPerson(r, user.ID(), func(l *list.List) { add(l).Subscribed = true })
+	listperson.SendersByPerson(r, user.ID(), func(l *list.List) { add(l).Sender = true })
+	writeJSON(r, lists)
+}
+
+// getListPeople handles GET /api/v1/lists/$id/people requests.  It lists the
+// subscribers and senders of the list, to webmasters and to people who can
+// send to the list.  Only their IDs and names are returned.
+func getListPeople(r *request.Request, idstr string) {
+	var (
+		user   *person.Person
+		l      *list.List
+		people = []*listMemberJSON{}
+	)
+	if !checkMethod(r, http.MethodGet) {
+		return
+	}
+	if user = apiUser(r, person.CanViewViewerFields, apitoken.ScopeListsRead); user == nil {
+		return
+	}
+	if l = list.WithID(r, list.ID(util.ParseID(idstr))); l == nil || (!user.IsWebmaster() && !listperson.CanSend(r, user.ID(), l.ID)) {
+		writeError(r, http.StatusNotFound, "no such list")
+		return
+	}
+	listperson.All(r, l.ID, person.FSortName|person.CanViewTargetFields, func(p *person.Person, sender, sub, unsub bool) {
+		if (sub && !unsub) || sender {
+			if user.CanView(p) != person.ViewNone {
+				people = append(people, &listMemberJSON{ID: int(p.ID()), Name: p.SortName(), Subscribed: sub && !unsub, Sender: sender})
+			}
+		}
+	})
+	writeJSON(r, people)
+}
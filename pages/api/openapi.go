@@ -0,0 +1,22 @@
+package api
+
+import (
+	_ "embed" // for go:embed
+	"net/http"
+
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+// getOpenAPI handles GET /api/v1/openapi.json requests.  It needs no
+// authentication.
+func getOpenAPI(r *request.Request) {
+	if !checkMethod(r, http.MethodGet) {
+		return
+	}
+	r.Header().Set("Content-Type", "application/json; charset=utf-8")
+	r.Header().Set("Cache-Control", "no-cache")
+	r.Write(openAPI)
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"net/http"
+
+	"sunnyvaleserv.org/portal/store/apitoken"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personrole"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+type personJSON struct {
+	ID           int    `json:"id"`
+	InformalName string `json:"informalName"`
+	SortName     string `json:"sortName"`
+	CallSign     string `json:"callSign,omitempty"`
+	Pronouns     string `json:"pronouns,omitempty"`
+	Email        string `json:"email,omitempty"`
+	Email2       string `json:"email2,omitempty"`
+	CellPhone    string `json:"cellPhone,omitempty"`
+	HomePhone    string `json:"homePhone,omitempty"`
+	WorkPhone    string `json:"workPhone,omitempty"`
+}
+type memberJSON struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Explicit bool   `json:"explicit"`
+}
+type roleJSON struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Title string `json:"title,omitempty"`
+	Org   string `json:"org"`
+}
+
+// apiPersonFields are the fields needed by makePersonJSON.
+const apiPersonFields = person.FID | person.FInformalName | person.FSortName | person.FCallSign | person.FPronouns | person.FEmail | person.FEmail2 | person.FCellPhone | person.FHomePhone | person.FWorkPhone | person.CanViewTargetFields
+
+// makePersonJSON returns the JSON data for a person, as seen by the user, or
+// nil if the user can't see them.  The contact information included is the
+// same as is shown in the people list.  The person must have apiPersonFields.
+func makePersonJSON(user, p *person.Person) (pj *personJSON) {
+	view := user.CanView(p)
+	if view == person.ViewNone {
+		return nil
+	}
+	pj = &personJSON{
+		ID: int(p.ID()), InformalName: p.InformalName(), SortName: p.SortName(),
+		CallSign: p.CallSign(), Pronouns: p.Pronouns(),
+	}
+	if view >= person.ViewWorkContact {
+		pj.Email, pj.Email2, pj.WorkPhone = p.Email(), p.Email2(), p.WorkPhone()
+	}
+	if view == person.ViewFull {
+		pj.CellPhone, pj.HomePhone = p.CellPhone(), p.HomePhone()
+	}
+	return pj
+}
+
+// getPeople handles GET /api/v1/people requests.  It lists all of the people
+// the user can see.
+func getPeople(r *request.Request) {
+	var (
+		user   *person.Person
+		people = []*personJSON{}
+	)
+	if !checkMethod(r, http.MethodGet) {
+		return
+	}
+	if user = apiUser(r, person.CanViewViewerFields, apitoken.ScopePeopleRead); user == nil {
+		return
+	}
+	person.All(r, apiPersonFields, func(p *person.Person) {
+		if pj := makePersonJSON(user, p); pj != nil {
+			people = append(people, pj)
+		}
+	})
+	writeJSON(r, people)
+}
+
+// getPerson handles GET /api/v1/people/$id requests.
+func getPerson(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		p    *person.Person
+		pj   *personJSON
+	)
+	if !checkMethod(r, http.MethodGet) {
+		return
+	}
+	if user = apiUser(r, person.CanViewViewerFields, apitoken.ScopePeopleRead); user == nil {
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), apiPersonFields); p != nil {
+		pj = makePersonJSON(user, p)
+	}
+	if pj == nil {
+		writeError(r, http.StatusNotFound, "no such person")
+		return
+	}
+	writeJSON(r, pj)
+}
+
+// canSeeRole returns whether the user can see the role and its members.  As
+// with the role filter in the people list, that requires membership in the
+// role's organization.
+func canSeeRole(user *person.Person, rl *role.Role) bool {
+	return user.IsWebmaster() || user.HasPrivLevel(rl.Org(), enum.PrivMember)
+}
+
+// getRoles handles GET /api/v1/roles requests.  It lists the roles the user
+// can see, other than archived ones.
+func getRoles(r *request.Request) {
+	var (
+		user  *person.Person
+		roles = []*roleJSON{}
+	)
+	if !checkMethod(r, http.MethodGet) {
+		return
+	}
+	if user = apiUser(r, 0, apitoken.ScopeRolesRead); user == nil {
+		return
+	}
+	role.All(r, role.FID|role.FName|role.FTitle|role.FOrg|role.FFlags, func(rl *role.Role) {
+		if rl.Flags()&role.Archived == 0 && canSeeRole(user, rl) {
+			roles = append(roles, &roleJSON{ID: int(rl.ID()), Name: rl.Name(), Title: rl.Title(), Org: rl.Org().String()})
+		}
+	})
+	writeJSON(r, roles)
+}
+
+// getRolePeople handles GET /api/v1/roles/$id/people requests.  It lists the
+// people who hold the role, among those the user can see.  Only their IDs and
+// names are returned; their contact information is available from
+// /api/v1/people with the people:read scope.
+func getRolePeople(r *request.Request, idstr string) {
+	var (
+		user   *person.Person
+		rl     *role.Role
+		people = []*memberJSON{}
+	)
+	if !checkMethod(r, http.MethodGet) {
+		return
+	}
+	if user = apiUser(r, person.CanViewViewerFields, apitoken.ScopeRolesRead); user == nil {
+		return
+	}
+	if rl = role.WithID(r, role.ID(util.ParseID(idstr)), role.FID|role.FOrg); rl == nil || !canSeeRole(user, rl) {
+		writeError(r, http.StatusNotFound, "no such role")
+		return
+	}
+	personrole.PeopleForRole(r, rl.ID(), person.FSortName|person.CanViewTargetFields, func(p *person.Person, explicit bool) {
+		if user.CanView(p) != person.ViewNone {
+			people = append(people, &memberJSON{ID: int(p.ID()), Name: p.SortName(), Explicit: explicit})
+		}
+	})
+	writeJSON(r, people)
+}
@@ -0,0 +1,232 @@
+package classes
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg" // for signature images
+	_ "image/png"  // for signature images
+	"net/http"
+	"os"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/certificate"
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/pdf"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const certificatesClassFields = class.FID | class.FType | class.FStart | class.FRegURL | class.FRole
+
+// HandleCertificates handles /classes/$id/certificates requests.  A GET
+// returns a PDF with all of the certificates issued for the class, for
+// printing.  A POST issues certificates to all students who have completed
+// the class and don't already have one.
+func HandleCertificates(r *request.Request, cidstr string) {
+	var (
+		user  *person.Person
+		c     *class.Class
+		certs []*certificate.Certificate
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if c = class.WithID(r, class.ID(util.ParseID(cidstr)), certificatesClassFields); c == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if !auth.Can(user, enum.PermClassManage, c.Type().Org()) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if r.Method == http.MethodPost {
+		r.Transaction(func() {
+			issueCertificates(r, c)
+		})
+		RenderRegList(r, user, c)
+		return
+	}
+	certificate.AllForClass(r, c.ID(), func(cert *certificate.Certificate) {
+		certs = append(certs, cert.Clone())
+	})
+	if len(certs) == 0 {
+		errpage.NotFound(r, user)
+		return
+	}
+	writeCertificates(r, fmt.Sprintf("%s %s Certificates.pdf", c.Type(), c.Start()), certs)
+}
+
+// issueCertificates issues certificates to all students who have completed
+// the class and don't already have one.
+func issueCertificates(r *request.Request, c *class.Class) {
+	var (
+		sessions  []event.ID
+		completed string
+		issued    = make(map[person.ID]bool)
+		today     = time.Now().Format("2006-01-02")
+	)
+	c.Sessions(r, event.FID|event.FStart, func(e *event.Event) {
+		sessions = append(sessions, e.ID())
+		completed = max(completed, e.Start()[:10])
+	})
+	if len(sessions) == 0 {
+		return
+	}
+	certificate.AllForClass(r, c.ID(), func(cert *certificate.Certificate) {
+		issued[cert.Person] = true
+	})
+	for pid, att := range c.Attendance(r) {
+		if issued[pid] || !class.Completed(sessions, att) {
+			continue
+		}
+		p := person.WithID(r, pid, person.FID|person.FFormalName)
+		certificate.Create(r, &certificate.Updater{
+			Token:     util.RandomToken(),
+			Person:    pid,
+			Class:     c.ID(),
+			Name:      p.FormalName(),
+			Course:    c.Type().String(),
+			Completed: completed,
+			Issued:    today,
+		})
+	}
+}
+
+// GetCertificate handles GET /certificates/$token requests.  This is the
+// public verification page whose URL is printed on each certificate.
+func GetCertificate(r *request.Request, token string) {
+	var (
+		user *person.Person
+		cert *certificate.Certificate
+	)
+	user = auth.SessionUser(r, 0, false)
+	if cert = certificate.WithToken(r, token); cert == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	ui.Page(r, user, ui.PageOpts{Title: r.Loc("Certificate Verification")}, func(main *htmlb.Element) {
+		main.E("div class=certverifyTitle").R(r.Loc("Certificate Verification"))
+		grid := main.E("div class=certverifyGrid")
+		grid.E("div").R(r.Loc("Certificate No."))
+		grid.E("div").T(cert.Number())
+		grid.E("div").R(r.Loc("Issued to"))
+		grid.E("div").T(cert.Name)
+		grid.E("div").R(r.Loc("Course"))
+		grid.E("div").T(r.Loc(cert.Course))
+		grid.E("div").R(r.Loc("Completed"))
+		grid.E("div").T(cert.Completed)
+		grid.E("div").R(r.Loc("Issued"))
+		grid.E("div").T(cert.Issued)
+		main.E("div class=certverifyValid").R(r.Loc("This is a valid certificate issued by Sunnyvale Emergency Response Volunteers (SERV)."))
+		if canSeeCertificate(r, user, cert) {
+			main.E("a href=/certificates/%s/pdf target=_blank class='sbtn sbtn-small sbtn-primary'>%s", cert.Token, r.Loc("Download Certificate"))
+		}
+	})
+}
+
+// GetCertificatePDF handles GET /certificates/$token/pdf requests.
+func GetCertificatePDF(r *request.Request, token string) {
+	var (
+		user *person.Person
+		cert *certificate.Certificate
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if cert = certificate.WithToken(r, token); cert == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if !canSeeCertificate(r, user, cert) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	writeCertificates(r, fmt.Sprintf("Certificate %s.pdf", cert.Number()), []*certificate.Certificate{cert})
+}
+
+// canSeeCertificate returns whether the user can download the certificate:
+// they must be the person to whom it was issued, or a leader.
+func canSeeCertificate(r *request.Request, user *person.Person, cert *certificate.Certificate) bool {
+	if user == nil {
+		return false
+	}
+	if user.ID() == cert.Person || auth.Can(user, enum.PermClassManage, enum.OrgAdmin) {
+		return true
+	}
+	if cert.Class != 0 {
+		if c := class.WithID(r, cert.Class, class.FType); c != nil {
+			return auth.Can(user, enum.PermClassManage, c.Type().Org())
+		}
+	}
+	return false
+}
+
+// writeCertificates writes a PDF containing the specified certificates, one
+// per page.
+func writeCertificates(r *request.Request, filename string, certs []*certificate.Certificate) {
+	var (
+		doc       = pdf.New()
+		signature *pdf.Image
+		signer    = config.Get("certificateSigner")
+	)
+	if fname := config.Get("certificateSignature"); fname != "" {
+		if fh, err := os.Open(fname); err == nil {
+			if img, _, err := image.Decode(fh); err == nil {
+				signature = doc.AddImage(img)
+			} else {
+				r.LogEntry.Problems.AddError(err)
+			}
+			fh.Close()
+		} else {
+			r.LogEntry.Problems.AddError(err)
+		}
+	}
+	for _, cert := range certs {
+		drawCertificate(doc, cert, signature, signer)
+	}
+	r.Header().Set("Content-Type", "application/pdf")
+	r.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	r.Header().Set("Cache-Control", "no-store")
+	doc.WriteTo(r)
+}
+
+// drawCertificate draws a certificate on a new landscape page of the document.
+func drawCertificate(doc *pdf.Document, cert *certificate.Certificate, signature *pdf.Image, signer string) {
+	const (
+		width  = pdf.LetterHeight
+		height = pdf.LetterWidth
+		center = width / 2
+	)
+	var completed = cert.Completed
+	if t, err := time.Parse("2006-01-02", cert.Completed); err == nil {
+		completed = t.Format("January 2, 2006")
+	}
+	page := doc.AddPage(width, height)
+	page.Rect(24, 24, width-48, height-48, 3, 0.2)
+	page.Rect(32, 32, width-64, height-64, 1, 0.2)
+	page.CenterText(center, 480, pdf.HelveticaBold, 36, "Certificate of Completion")
+	page.CenterText(center, 425, pdf.Helvetica, 16, "This certifies that")
+	page.CenterText(center, 375, pdf.HelveticaBold, 32, cert.Name)
+	page.Line(center-216, 365, center+216, 365, 0.5, 0.5)
+	page.CenterText(center, 330, pdf.Helvetica, 16, "has successfully completed")
+	page.CenterText(center, 290, pdf.HelveticaBold, 24, cert.Course)
+	page.CenterText(center, 255, pdf.Helvetica, 16, "on "+completed)
+	page.CenterText(center, 210, pdf.Helvetica, 12, "Sunnyvale Emergency Response Volunteers (SERV)")
+	page.CenterText(center, 195, pdf.Helvetica, 12, "City of Sunnyvale Office of Emergency Services")
+	if signature != nil {
+		page.Image(signature, 96, 110, 240, 50)
+	}
+	page.Line(96, 105, 336, 105, 0.5, 0)
+	page.CenterText(216, 90, pdf.Helvetica, 11, signer)
+	page.RightText(width-96, 120, pdf.Helvetica, 11, "Certificate No. "+cert.Number())
+	page.RightText(width-96, 105, pdf.Helvetica, 9, "Verify this certificate at:")
+	page.RightText(width-96, 92, pdf.Helvetica, 9, config.Get("siteURL")+"/certificates/"+cert.Token)
+}
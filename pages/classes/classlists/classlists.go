@@ -29,7 +29,7 @@ func Handle(r *request.Request, cidstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
-	if !user.HasPrivLevel(c.Type().Org(), enum.PrivLeader) {
+	if !auth.Can(user, enum.PermClassManage, c.Type().Org()) {
 		errpage.Forbidden(r, user)
 		return
 	}
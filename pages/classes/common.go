@@ -1,6 +1,7 @@
 package classes
 
 import (
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/class"
 	"sunnyvaleserv.org/portal/store/classreg"
 	"sunnyvaleserv.org/portal/store/enum"
@@ -12,8 +13,14 @@ import (
 func getClassesCommon(r *request.Request, user *person.Person, main *htmlb.Element, ctype class.Type) {
 	var (
 		classes  *htmlb.Element
+		query    string
 		langFlag = class.FEnDesc
 	)
+	// Carry any campaign code from the URL of this page through to the
+	// registration, so that it is recorded there.
+	if code := campaignCode(r); code != "" {
+		query = "?src=" + code
+	}
 	if r.Language == "es" {
 		langFlag = class.FEsDesc
 	}
@@ -28,14 +35,14 @@ func getClassesCommon(r *request.Request, user *person.Person, main *htmlb.Eleme
 		}
 		if c.RegURL() != "" {
 			classes.E("div").E("a href=%s target=_blank class='sbtn sbtn-primary sbtn-small'", c.RegURL()).R(r.Loc("Sign Up"))
-		} else if user.HasPrivLevel(ctype.Org(), enum.PrivLeader) {
+		} else if auth.Can(user, enum.PermClassManage, ctype.Org()) {
 			classes.E("div").E("a href=/classes/%d/reglist up-target=main class='sbtn sbtn-primary sbtn-small'>Registrations", c.ID())
 		} else if classreg.ClassHasWaitlist(r, c.ID()) || classreg.ClassIsFull(r, c.ID()) {
 			d := classes.E("div")
 			d.E("div class=classesFull").R(r.Loc("This session is full."))
-			d.E("div").E("a href=/classes/%d/register up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-primary sbtn-small'", c.ID()).R(r.Loc("Wait List"))
+			d.E("div").E("a href=/classes/%d/register%s up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-primary sbtn-small'", c.ID(), query).R(r.Loc("Wait List"))
 		} else {
-			classes.E("div").E("a href=/classes/%d/register up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-primary sbtn-small'", c.ID()).R(r.Loc("Sign Up"))
+			classes.E("div").E("a href=/classes/%d/register%s up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-primary sbtn-small'", c.ID(), query).R(r.Loc("Sign Up"))
 		}
 	})
 	if classes == nil {
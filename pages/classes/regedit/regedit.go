@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sunnyvaleserv.org/portal/pages/classes"
@@ -30,7 +31,7 @@ var statusLabels = map[string]string{
 
 // Handle handles /classes/regedit/$id requests.
 func Handle(r *request.Request, ridstr string) {
-	const classFields = class.FID | class.FStart | class.FLimit | class.FReferrals | class.FType | class.FRegURL | class.FRole
+	const classFields = class.FID | class.FStart | class.FLimit | class.FType | class.FRegURL | class.FRole
 	var (
 		user      *person.Person
 		cr        *classreg.ClassReg
@@ -59,7 +60,7 @@ func Handle(r *request.Request, ridstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
-	if !user.HasPrivLevel(c.Type().Org(), enum.PrivLeader) {
+	if !auth.Can(user, enum.PermClassManage, c.Type().Org()) {
 		errpage.Forbidden(r, user)
 		return
 	}
@@ -205,11 +206,14 @@ func saveRegistration(r *request.Request, user *person.Person, c *class.Class, c
 				personrole.RemoveRole(r, ur.Person, rl)
 			}
 		}
-		ur.Waitlist = status == "waitlist"
+		if ur.Waitlist = status == "waitlist"; ur.Waitlist {
+			ur.HoldUntil, ur.HoldToken = time.Time{}, ""
+		}
 		cr.Update(r, ur)
 		ok = true
 	})
 	if ok {
+		classes.PromoteWaitlist(r, c.ID())
 		classes.RenderRegList(r, user, c)
 	}
 	return ok, nameError
@@ -219,6 +223,7 @@ func deleteRegistration(r *request.Request, user *person.Person, c *class.Class,
 	r.Transaction(func() {
 		cr.Delete(r, c)
 	})
+	classes.PromoteWaitlist(r, c.ID())
 	classes.RenderRegList(r, user, c)
 	return true
 }
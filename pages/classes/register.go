@@ -14,6 +14,8 @@ import (
 	"sunnyvaleserv.org/portal/store/class"
 	"sunnyvaleserv.org/portal/store/classreg"
 	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personverify"
+	"sunnyvaleserv.org/portal/store/referral"
 	"sunnyvaleserv.org/portal/util"
 	"sunnyvaleserv.org/portal/util/config"
 	"sunnyvaleserv.org/portal/util/htmlb"
@@ -31,10 +33,14 @@ func HandleRegister(r *request.Request, cidstr string) {
 		regs         []*classreg.ClassReg
 		uregs        []*classreg.Updater
 		errors       []string
-		referral     class.Referral
+		ref          referralAnswer
 		others       uint
 		forceGet     bool
+		pending      bool
+		throttled    bool
 		haveWaitlist bool
+		waitlisted   int
+		positions    = make(map[classreg.ID]int)
 		max          = -1
 	)
 	// Get the user information.
@@ -52,9 +58,17 @@ func HandleRegister(r *request.Request, cidstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
+	// Remove registrations by unverified accounts that have expired,
+	// release any expired holds, and fill any open seats from the waiting
+	// list before counting.
+	expireUnverified(r)
+	PromoteWaitlist(r, c.ID())
+	pending = personverify.Pending(r, user.ID())
 	classreg.AllForClass(r, c.ID(), classreg.UpdaterFields, func(cr *classreg.ClassReg) {
 		if cr.Waitlist() {
 			haveWaitlist = true
+			waitlisted++
+			positions[cr.ID()] = waitlisted
 		}
 		if cr.RegisteredBy() != user.ID() {
 			if !cr.Waitlist() {
@@ -76,9 +90,11 @@ func HandleRegister(r *request.Request, cidstr string) {
 	}
 	// Determine what to display in the form.
 	if r.Method == http.MethodPost && !forceGet {
-		uregs, errors, referral = readForm(r, max)
-		if len(errors) == 0 {
-			applyForm(r, user, c, regs, uregs, referral)
+		uregs, errors, ref = readForm(r, max)
+		ref.campaign = campaignCode(r)
+		if throttled = registrationThrottled(r, user.Email()); !throttled && len(errors) == 0 {
+			recordRegistration(r, user.Email())
+			applyForm(r, user, c, regs, uregs, ref, pending)
 			return
 		}
 	} else {
@@ -96,12 +112,15 @@ func HandleRegister(r *request.Request, cidstr string) {
 				Email:        personEmail(user),
 				CellPhone:    user.CellPhone(),
 			})
-			referral = class.Referral(99) // a nonzero invalid value
+			ref.ask, ref.campaign = true, campaignCode(r)
+			if s := referral.WithCode(r, ref.campaign); s != nil {
+				ref.source = s.ID
+			}
 		}
 		uregs = append(uregs, new(classreg.Updater))
 	}
 	r.HTMLNoCache()
-	if len(errors) != 0 || forceGet {
+	if len(errors) != 0 || forceGet || throttled {
 		r.WriteHeader(http.StatusUnprocessableEntity)
 	}
 	html := htmlb.HTML(r)
@@ -112,6 +131,15 @@ func HandleRegister(r *request.Request, cidstr string) {
 	}
 	form.E("div class='formTitle formTitle-primary'").R(r.Loc("Class Registration"))
 	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	if ref.campaign != "" {
+		form.E("input type=hidden name=src value=%s", ref.campaign)
+	}
+	if throttled {
+		form.E("div class='formRow-3col formError'").R(r.Loc("There have been too many registration attempts.  Please try again later."))
+	}
+	if pending {
+		form.E("div class='formRow-3col classregPending'").R(r.Loc("We have sent you an email asking you to confirm your email address.  Your registrations will be pending until you do."))
+	}
 	if max == 0 {
 		form.E("div class=formRow-3col").R(r.Loc("This class is now full.  You will be placed on a waiting list for the class and will be notified if space becomes available."))
 	}
@@ -120,15 +148,15 @@ func HandleRegister(r *request.Request, cidstr string) {
 		if i < len(errors) {
 			err = errors[i]
 		}
-		emitRow(r, form, uregs[i], err, i)
+		emitRow(r, form, uregs[i], err, i, positions[uregs[i].ID])
 	}
-	if referral != 0 {
-		emitReferral(r, form, referral)
+	if ref.ask {
+		emitReferral(r, form, ref)
 	}
 	emitButtons(r, form)
 }
 
-func emitRow(r *request.Request, form *htmlb.Element, reg *classreg.Updater, err string, idx int) {
+func emitRow(r *request.Request, form *htmlb.Element, reg *classreg.Updater, err string, idx, position int) {
 	div := form.E("div class='formRow-3col classregDivider'", idx == 0, "class=first")
 	div.E("div").TF(r.Loc("Student %d"), idx+1)
 	div.E("button type=button class='sbtn sbtn-xsmall sbtn-danger classregClear' data-row=%d>%s", idx, r.Loc("Clear"))
@@ -146,6 +174,11 @@ func emitRow(r *request.Request, form *htmlb.Element, reg *classreg.Updater, err
 	if err != "" {
 		row.E("div class=formError>%s", err)
 	}
+	if reg.ID != 0 && reg.Waitlist {
+		form.E("div class='formRow-3col classregWaitlist'").TF(r.Loc("This student is number %d on the waiting list."), position)
+	} else if reg.HoldToken != "" {
+		form.E("div class='formRow-3col classregWaitlist'").TF(r.Loc("A place is being held for this student until %s.  Please confirm it using the link in the email we sent."), localizeHold(reg.HoldUntil, r.Language))
+	}
 }
 
 func personFirstName(p *person.Person) string {
@@ -168,16 +201,45 @@ func personEmail(p *person.Person) string {
 	return p.Email2()
 }
 
-func emitReferral(r *request.Request, form *htmlb.Element, referral class.Referral) {
+// referralAnswer is the registrant's answer to "How did you find out about
+// this class?", along with the campaign code, if any, from the URL of the
+// class page they registered from.
+type referralAnswer struct {
+	ask      bool // whether the question is asked in the form
+	source   referral.ID
+	other    string
+	campaign string
+}
+
+// campaignCode returns the campaign code given in the src parameter of the
+// request, or an empty string if there is none or it isn't valid.
+func campaignCode(r *request.Request) string {
+	if code := strings.ToLower(strings.TrimSpace(r.FormValue("src"))); referral.ValidCode(code) {
+		return code
+	}
+	return ""
+}
+
+func emitReferral(r *request.Request, form *htmlb.Element, ref referralAnswer) {
+	var other bool
+
 	row := form.E("div class='formRow-3col classregReferral'")
 	row.E("label for=classregReferral>%s", r.Loc("How did you find out about this class?"))
 	sel := row.E("select id=classregReferral name=referral class=formInput")
-	if !referral.Valid() {
+	if ref.source == 0 {
 		sel.E("option value='' selected>%s", r.Loc("(select one)"))
 	}
-	for _, ref := range class.AllReferrals {
-		sel.E("option value=%d", ref, ref == referral, "selected").T(r.Loc(ref.String()))
-	}
+	referral.All(r, func(s *referral.Source) {
+		if s.Archived {
+			return
+		}
+		sel.E("option value=%d", s.ID, s.ID == ref.source, "selected", s.Other, "data-other").T(s.Name(r.Language))
+		if s.ID == ref.source && s.Other {
+			other = true
+		}
+	})
+	row.E("input id=classregReferralOther name=referralOther class=formInput placeholder=%s value=%s",
+		r.Loc("Please specify"), ref.other, !other, "hidden")
 }
 
 func emitButtons(r *request.Request, form *htmlb.Element) {
@@ -188,7 +250,7 @@ func emitButtons(r *request.Request, form *htmlb.Element) {
 
 var emailRE = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
 
-func readForm(r *request.Request, max int) (uregs []*classreg.Updater, errors []string, referral class.Referral) {
+func readForm(r *request.Request, max int) (uregs []*classreg.Updater, errors []string, ref referralAnswer) {
 	for i := range r.Form["firstName"] {
 		if i >= len(r.Form["lastName"]) || i >= len(r.Form["email"]) || i >= len(r.Form["cellPhone"]) {
 			break
@@ -224,11 +286,18 @@ func readForm(r *request.Request, max int) (uregs []*classreg.Updater, errors []
 		max--
 	}
 	if _, ok := r.Form["referral"]; ok {
-		if referral = class.Referral(util.ParseID(r.FormValue("referral"))); !referral.Valid() {
-			referral = class.Referral(99) // a nonzero invalid value
+		ref.ask = true
+		if s := referral.WithID(r, referral.ID(util.ParseID(r.FormValue("referral")))); s != nil && !s.Archived {
+			ref.source = s.ID
+			if s.Other {
+				ref.other = strings.TrimSpace(r.FormValue("referralOther"))
+				if len(ref.other) > 200 {
+					ref.other = ref.other[:200]
+				}
+			}
 		}
 	}
-	return uregs, errors, referral
+	return uregs, errors, ref
 }
 
 func fmtPhone(p *string) bool {
@@ -254,19 +323,39 @@ func fmtPhone(p *string) bool {
 
 func applyForm(
 	r *request.Request, user *person.Person, c *class.Class, regs []*classreg.ClassReg,
-	uregs []*classreg.Updater, referral class.Referral,
+	uregs []*classreg.Updater, ref referralAnswer, pending bool,
 ) {
+	// If the referral question wasn't asked, because the user already had
+	// registrations for the class, new registrations inherit the answer
+	// given with the earlier ones.
+	if !ref.ask && len(regs) != 0 {
+		ref.source, ref.other = regs[0].Referral(), regs[0].ReferralOther()
+		if ref.campaign == "" {
+			ref.campaign = regs[0].Campaign()
+		}
+	}
 	// Determine adds, cancels, and changes.
 	adds, changesTo, changesFrom, cancels := splitForm(regs, uregs, user, c)
+	for _, add := range adds {
+		add.Referral, add.ReferralOther, add.Campaign = ref.source, ref.other, ref.campaign
+	}
 	// Save the changes.
-	saveRegistrations(r, adds, changesTo, changesFrom, cancels, c, referral)
+	saveRegistrations(r, adds, changesTo, changesFrom, cancels, c)
+	// If any seats were given up, offer them to the waiting list.
+	if len(cancels) != 0 {
+		PromoteWaitlist(r, c.ID())
+	}
 	// Send the confirmation emails.  (We don't send confirmation emails for
-	// changes, only for adds and cancels.)
-	sendUserConfirmation(r, user, c, uregs, cancels)
-	sendAddConfirmations(r, user, c, adds)
-	sendCancelConfirmations(r, user, c, cancels)
+	// changes, only for adds and cancels.  If the user's email address
+	// isn't verified yet, we send email only to the user; the other
+	// students are notified when it is verified.)
+	sendUserConfirmation(r, user, c, uregs, cancels, pending)
+	if !pending {
+		sendAddConfirmations(r, user, c, adds)
+		sendCancelConfirmations(r, user, c, cancels)
+	}
 	// Show the confirmation dialog.
-	showConfirmation(r, user, uregs, cancels)
+	showConfirmation(r, user, uregs, cancels, pending)
 }
 
 // splitForm compares the registrations submitted in the form with those already
@@ -280,6 +369,8 @@ func splitForm(
 			if r != nil && r.FirstName() == ur.FirstName && r.LastName() == ur.LastName {
 				if r.Email() != ur.Email || r.CellPhone() != ur.CellPhone {
 					ur.ID, ur.RegisteredBy, ur.Class = r.ID(), user, c
+					ur.Waitlist, ur.HoldUntil, ur.HoldToken = r.Waitlist(), r.HoldUntil(), r.HoldToken()
+					ur.Referral, ur.ReferralOther, ur.Campaign = r.Referral(), r.ReferralOther(), r.Campaign()
 					changesTo = append(changesTo, ur)
 					changesFrom = append(changesFrom, r)
 				}
@@ -303,7 +394,6 @@ func splitForm(
 
 func saveRegistrations(
 	r *request.Request, adds, changesTo []*classreg.Updater, changesFrom, cancels []*classreg.ClassReg, c *class.Class,
-	referral class.Referral,
 ) {
 	r.Transaction(func() {
 		for i, to := range changesTo {
@@ -315,15 +405,10 @@ func saveRegistrations(
 		for _, add := range adds {
 			classreg.Create(r, add)
 		}
-		if referral.Valid() {
-			uc := c.Updater(r, nil)
-			uc.Referrals[referral]++
-			c.Update(r, uc)
-		}
 	})
 }
 
-func sendUserConfirmation(r *request.Request, user *person.Person, c *class.Class, uregs []*classreg.Updater, cancels []*classreg.ClassReg) {
+func sendUserConfirmation(r *request.Request, user *person.Person, c *class.Class, uregs []*classreg.Updater, cancels []*classreg.ClassReg, pending bool) {
 	var (
 		toaddrs []string
 		recips  []string
@@ -389,6 +474,10 @@ func sendUserConfirmation(r *request.Request, user *person.Person, c *class.Clas
 			}
 		}
 	}
+	if len(uregs) != 0 && pending {
+		fmt.Fprint(&body, "\r\n\r\n")
+		fmt.Fprint(&body, r.Loc("These registrations are pending until you confirm your email address, using the link in the separate email we sent you.  If you do not confirm it in time, they will be canceled."))
+	}
 	if len(uregs) != 0 {
 		fmt.Fprint(&body, "\r\n\r\n")
 		fmt.Fprint(&body, r.Loc("If you need to withdraw from the class or make other changes, please return to SunnyvaleSERV.org.  You may also reply to this email."))
@@ -477,7 +566,7 @@ func sendCancelConfirmations(r *request.Request, user *person.Person, c *class.C
 	}
 }
 
-func showConfirmation(r *request.Request, user *person.Person, uregs []*classreg.Updater, cancels []*classreg.ClassReg) {
+func showConfirmation(r *request.Request, user *person.Person, uregs []*classreg.Updater, cancels []*classreg.ClassReg, pending bool) {
 	var (
 		recips    []string
 		reciplist string
@@ -510,6 +599,9 @@ func showConfirmation(r *request.Request, user *person.Person, uregs []*classreg
 	}
 	if len(uregs) != 0 {
 		row.E("p").TF(r.Loc("A confirmation message has been sent to %s. If you don’t receive it promptly, look for it in your Junk Mail folder. Move it to your inbox so that future messages from us about the class are not marked as Junk Mail."), reciplist)
+		if pending {
+			row.E("p").E("b").T(r.Loc("Your registration is not final until you confirm your email address, using the link in the separate email we sent you."))
+		}
 		row.E("p").T(r.Loc("If you need to withdraw from the class, please return to this website and remove your registration.  You may also send email to serv@sunnyvale.ca.gov."))
 	} else {
 		row.E("p").TF(r.Loc("A confirmation message has been sent to %s."), reciplist)
@@ -36,3 +36,42 @@ up.on('input', '.classregFirstname', (evt, elm) => {
   cellPhone.lastElementChild.value = ''
   insertBefore.parentElement.insertBefore(cellPhone, insertBefore)
 })
+up.on('change', '#classregReferral', (evt, elm) => {
+  const other = document.getElementById('classregReferralOther')
+  other.hidden = !elm.selectedOptions[0].hasAttribute('data-other')
+  if (!other.hidden) other.focus()
+})
+// The account creation form carries a proof-of-work challenge (see verify.go).
+// We start solving it as soon as the form is shown, and hold up submission of
+// the form until it is solved.
+up.compiler('.classregChallenge', (elm) => {
+  const challenge = elm.querySelector('[name=challenge]').value
+  const nonce = elm.querySelector('[name=nonce]')
+  const bits = parseInt(elm.dataset.bits)
+  const encoder = new TextEncoder()
+  const leadingZeroBits = (hash) => {
+    let count = 0
+    for (const b of hash) {
+      if (b) return count + Math.clz32(b) - 24
+      count += 8
+    }
+    return count
+  }
+  const solve = async () => {
+    if (!window.crypto || !crypto.subtle) return
+    for (let n = 0; ; n++) {
+      const hash = new Uint8Array(await crypto.subtle.digest('SHA-256', encoder.encode(challenge + n)))
+      if (leadingZeroBits(hash) >= bits) {
+        nonce.value = n
+        return
+      }
+    }
+  }
+  elm.solving = solve().then(() => { elm.solved = true })
+})
+up.on('up:form:submit', 'form', (evt, form) => {
+  const elm = form.querySelector('.classregChallenge')
+  if (!elm || !elm.solving || elm.solved) return
+  evt.preventDefault()
+  elm.solving.then(() => { up.submit(form) })
+})
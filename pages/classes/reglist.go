@@ -1,14 +1,21 @@
 package classes
 
 import (
+	"sort"
+
 	"sunnyvaleserv.org/portal/pages/errpage"
 	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/certificate"
 	"sunnyvaleserv.org/portal/store/class"
 	"sunnyvaleserv.org/portal/store/classreg"
 	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/event"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/store/personrole"
+	"sunnyvaleserv.org/portal/store/personverify"
+	"sunnyvaleserv.org/portal/store/referral"
 	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/store/taskperson"
 	"sunnyvaleserv.org/portal/ui"
 	"sunnyvaleserv.org/portal/util"
 	"sunnyvaleserv.org/portal/util/htmlb"
@@ -16,7 +23,7 @@ import (
 )
 
 func GetRegList(r *request.Request, cidstr string) {
-	const classFields = class.FStart | class.FLimit | class.FReferrals | class.FType | class.FRegURL | class.FRole
+	const classFields = class.FID | class.FStart | class.FLimit | class.FType | class.FRegURL | class.FRole
 	var (
 		user *person.Person
 		c    *class.Class
@@ -26,32 +33,58 @@ func GetRegList(r *request.Request, cidstr string) {
 	}
 	if c = class.WithID(r, class.ID(util.ParseID(cidstr)), classFields); c == nil || c.RegURL() != "" {
 		errpage.NotFound(r, user)
+		return
 	}
-	if !user.HasPrivLevel(c.Type().Org(), enum.PrivLeader) {
+	if !auth.Can(user, enum.PermClassManage, c.Type().Org()) {
 		errpage.Forbidden(r, user)
+		return
 	}
+	expireUnverified(r)
+	PromoteWaitlist(r, c.ID())
 	RenderRegList(r, user, c)
 }
 
 func RenderRegList(r *request.Request, user *person.Person, c *class.Class) {
-	const classregFields = classreg.FID | classreg.FFirstName | classreg.FLastName | classreg.FEmail | classreg.FCellPhone | classreg.FRegisteredBy | classreg.FPerson | classreg.FWaitlist
+	const classregFields = classreg.FID | classreg.FFirstName | classreg.FLastName | classreg.FEmail | classreg.FCellPhone | classreg.FRegisteredBy | classreg.FPerson | classreg.FWaitlist | classreg.FHoldUntil | classreg.FReferral | classreg.FCampaign
 	var (
-		regs     []*classreg.ClassReg
-		waitlist int
-		opts     ui.PageOpts
+		regs        []*classreg.ClassReg
+		waitlist    int
+		sessions    []event.ID
+		attendance  map[person.ID]map[event.ID]taskperson.Flag
+		certs       = make(map[person.ID]string)
+		pending     = make(map[person.ID]bool)
+		uncertified bool
+		opts        ui.PageOpts
 	)
+	c.Sessions(r, event.FID, func(e *event.Event) {
+		sessions = append(sessions, e.ID())
+	})
+	if len(sessions) != 0 {
+		attendance = c.Attendance(r)
+	}
+	certificate.AllForClass(r, c.ID(), func(cert *certificate.Certificate) {
+		certs[cert.Person] = cert.Token
+	})
 	classreg.AllForClass(r, c.ID(), classregFields, func(cr *classreg.ClassReg) {
 		regs = append(regs, cr.Clone())
 		if cr.Waitlist() {
 			waitlist++
 		}
 	})
+	for _, reg := range regs {
+		if _, ok := pending[reg.RegisteredBy()]; !ok {
+			pending[reg.RegisteredBy()] = personverify.Pending(r, reg.RegisteredBy())
+		}
+	}
 	opts = ui.PageOpts{
 		Title:    "Class Registrations",
 		MenuItem: "classes",
 	}
 	ui.Page(r, user, opts, func(main *htmlb.Element) {
-		var lastRB person.ID
+		var (
+			lastRB   person.ID
+			position int
+		)
 
 		main.E("div class=reglistClass>%s", c.Type().String())
 		main.E("div class=reglistStart>%s", c.Start())
@@ -81,26 +114,85 @@ func RenderRegList(r *request.Request, user *person.Person, c *class.Class) {
 			}
 			grid.E("div class=reglistEmail").E("a href=mailto:%s target=_blank>%s", reg.Email(), reg.Email())
 			grid.E("div class=reglistCellPhone>%s", reg.CellPhone())
-			if reg.Person() != 0 && c.Role() != 0 && hasRole(r, reg.Person(), c.Role()) {
+			if reg.Person() != 0 && class.Completed(sessions, attendance[reg.Person()]) {
+				if token := certs[reg.Person()]; token != "" {
+					grid.E("div>Completed, ").E("a href=/certificates/%s/pdf target=_blank>certificate", token)
+				} else {
+					grid.E("div>Completed")
+					uncertified = true
+				}
+			} else if reg.Person() != 0 && c.Role() != 0 && hasRole(r, reg.Person(), c.Role()) {
 				grid.E("div>In Class")
+			} else if !reg.HoldUntil().IsZero() {
+				grid.E("div title='Promoted from waitlist, awaiting confirmation'>Held until %s", reg.HoldUntil().Format("Jan 2 15:04"))
+			} else if !reg.Waitlist() && pending[reg.RegisteredBy()] {
+				grid.E("div title='Registrant has not yet confirmed their email address'>Unverified")
 			} else if !reg.Waitlist() {
 				grid.E("div>Registered")
 			} else {
-				grid.E("div>Waitlist")
+				position++
+				grid.E("div>Waitlist #%d", position)
 			}
 			grid.E("div").E("a href=/classes/regedit/%d up-layer=new up-size=grow up-history=false class='sbtn sbtn-xsmall sbtn-primary'>Edit", reg.ID())
 		}
+		buttons := main.E("div class=reglistButtons")
+		buttons.E("a href=/classes/%d/sessions up-target=main class='sbtn sbtn-xsmall sbtn-primary'>Sessions", c.ID())
+		buttons.E("a href=/classes/%d/roster up-target=main class='sbtn sbtn-xsmall sbtn-primary'>Roster", c.ID())
+		if uncertified {
+			form := buttons.E("form method=POST action=/classes/%d/certificates up-target=main", c.ID())
+			form.E("input type=hidden name=csrf value=%s", r.CSRF)
+			form.E("input type=submit class='sbtn sbtn-xsmall sbtn-primary' value='Issue Certificates'")
+		}
+		if len(certs) != 0 {
+			buttons.E("a href=/classes/%d/certificates target=_blank class='sbtn sbtn-xsmall sbtn-primary'>Print Certificates", c.ID())
+		}
+		buttons.E("a href=/classes/%d/survey up-target=main class='sbtn sbtn-xsmall sbtn-primary'>Survey", c.ID())
 		if len(regs) != 0 {
-			buttons := main.E("div class=reglistButtons")
 			buttons.E("a href=/classes/%d/lists up-layer=new up-size=grow up-history=false class='sbtn sbtn-xsmall sbtn-primary'>Email Lists", c.ID())
-			main.E("div class=reglistReferralsHeading>Referred by:")
-			grid := main.E("div class=reglistReferrals")
-			for _, ref := range class.AllReferrals {
-				grid.E("div>%d", c.Referrals()[ref])
-				grid.E("div>%s", ref.String())
+			emitReferrals(r, main, regs)
+		}
+	})
+}
+
+// emitReferrals emits a summary of how the registrants learned of the class,
+// and of the campaign codes in the URLs they registered from.
+func emitReferrals(r *request.Request, main *htmlb.Element, regs []*classreg.ClassReg) {
+	var (
+		sources   = make(map[referral.ID]int)
+		campaigns = make(map[string]int)
+		codes     []string
+	)
+	for _, reg := range regs {
+		sources[reg.Referral()]++
+		if reg.Campaign() != "" {
+			if campaigns[reg.Campaign()] == 0 {
+				codes = append(codes, reg.Campaign())
 			}
+			campaigns[reg.Campaign()]++
 		}
+	}
+	main.E("div class=reglistReferralsHeading>Referred by:")
+	grid := main.E("div class=reglistReferrals")
+	referral.All(r, func(s *referral.Source) {
+		if s.Archived && sources[s.ID] == 0 {
+			return
+		}
+		grid.E("div>%d", sources[s.ID])
+		grid.E("div>%s", s.EnName)
 	})
+	if sources[0] != 0 {
+		grid.E("div>%d", sources[0])
+		grid.E("div>(not answered)")
+	}
+	if len(codes) != 0 {
+		main.E("div class=reglistReferralsHeading>Campaigns:")
+		grid = main.E("div class=reglistReferrals")
+		sort.Strings(codes)
+		for _, code := range codes {
+			grid.E("div>%d", campaigns[code])
+			grid.E("div>%s", code)
+		}
+	}
 }
 
 func hasRole(r *request.Request, p person.ID, rl role.ID) bool {
@@ -1,6 +1,7 @@
 package classes
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -34,6 +35,7 @@ type registerLogIn struct {
 	cellPhone string
 	newpwd1   string
 	newpwd2   string
+	challenge string
 	loggedIn  bool
 }
 
@@ -129,6 +131,7 @@ func (li *registerLogIn) createForm() {
 			},
 			login: li,
 		},
+		&challengeRow{login: li},
 	}
 }
 
@@ -226,6 +229,10 @@ func (pr *passwordRow) Read(r *request.Request) bool {
 		}
 		return false
 	}
+	if until := auth.LoginThrottled(r); !until.IsZero() {
+		pr.Error = fmt.Sprintf(r.Loc("There have been too many failed login attempts from your network.  Please try again after %s."), until.Format("3:04pm"))
+		return false
+	}
 	if pr.login.user.ID() != person.AdminID { // admin cannot be disabled or locked out
 		if pr.login.user.BadLoginCount() >= maxBadLogins && time.Now().Before(pr.login.user.BadLoginTime().Add(badLoginThreshold)) {
 			valid = false // locked out
@@ -237,9 +244,14 @@ func (pr *passwordRow) Read(r *request.Request) bool {
 		valid = false // wrong password
 	}
 	if !valid {
+		auth.RecordLoginFailure(r)
 		pr.Error = r.Loc("Login incorrect. Please try again.")
 		return false
 	}
+	if auth.SSORequired(pr.login.user) {
+		pr.Error = fmt.Sprintf(r.Loc("Your account must sign in with %s."), auth.SSOLabel())
+		return false
+	}
 	return true
 }
 
@@ -354,6 +366,53 @@ func (npr *newPasswordRow) ShouldEmit(_ request.ValidationList) bool {
 	return npr.login.haveEmail && npr.login.user == nil
 }
 
+// challengeRow is an invisible row that carries the robot checks for account
+// creation:  a proof-of-work challenge solved by register.js, and a honeypot
+// field that people won't see but robots are likely to fill in.  It also
+// enforces the rate limit on account creation.
+type challengeRow struct {
+	form.BaseRow
+	login *registerLogIn
+	err   string
+}
+
+func (cr *challengeRow) Read(r *request.Request) bool {
+	cr.err = ""
+	if !cr.login.haveEmail || cr.login.user != nil {
+		return true
+	}
+	if _, ok := r.Form["challenge"]; !ok {
+		return false // challenge not yet issued
+	}
+	if registrationThrottled(r, cr.login.email) {
+		cr.err = r.Loc("There have been too many registration attempts.  Please try again later.")
+		return false
+	}
+	if r.FormValue("website") != "" || !checkChallenge(r, r.FormValue("challenge"), r.FormValue("nonce")) {
+		cr.err = r.Loc("We could not confirm that this request came from a person.  Please wait a moment and try again.")
+		return false
+	}
+	cr.login.challenge = r.FormValue("challenge")
+	return true
+}
+
+func (cr *challengeRow) ShouldEmit(_ request.ValidationList) bool {
+	return cr.login.haveEmail && cr.login.user == nil
+}
+
+func (cr *challengeRow) Emit(r *request.Request, parent *htmlb.Element, _ bool) {
+	row := parent.E("div class='formRow-3col classregChallenge' data-bits=%d", challengeBits)
+	row.E("input type=hidden name=challenge value=%s", newChallenge())
+	row.E("input type=hidden name=nonce")
+	honeypot := row.E("div class=classregWebsite aria-hidden=true")
+	honeypot.E("label for=classregWebsite>Website")
+	honeypot.E("input id=classregWebsite name=website tabindex=-1 autocomplete=off")
+	if cr.err != "" {
+		row.E("div class=formError").T(cr.err)
+		r.LogEntry.Problems.Add(cr.err)
+	}
+}
+
 func (login *registerLogIn) submitEmail() bool {
 	return false
 }
@@ -386,7 +445,10 @@ func (login *registerLogIn) createAccount() bool {
 		login.user = person.Create(login.r, up)
 		auth.SetPassword(login.r, login.user, login.newpwd1)
 		auth.CreateSession(login.r, login.user, false)
+		useChallenge(login.r, login.challenge)
 	})
+	startVerification(login.r, login.user)
+	recordRegistration(login.r, login.email)
 	login.r.Form.Set("csrf", login.r.CSRF)
 	login.loggedIn = true
 	return true
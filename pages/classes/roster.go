@@ -0,0 +1,484 @@
+package classes
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/server/l10n"
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/classreg"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personmerge"
+	"sunnyvaleserv.org/portal/store/personrole"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/store/task"
+	"sunnyvaleserv.org/portal/store/taskperson"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/ui/form"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+	"sunnyvaleserv.org/portal/util/sendmail"
+)
+
+const rosterClassFields = class.FID | class.FType | class.FStart | class.FRegURL | class.FRole | class.FGradRole
+
+const rosterRegFields = classreg.UpdaterFields
+
+// rosterClass returns the class named in a /classes/$id/roster URL, after
+// verifying that the user is a leader of its organization.  It returns nil
+// (after rendering an error page) if not.
+func rosterClass(r *request.Request, user *person.Person, cidstr string) (c *class.Class) {
+	if c = class.WithID(r, class.ID(util.ParseID(cidstr)), rosterClassFields); c == nil || c.RegURL() != "" {
+		errpage.NotFound(r, user)
+		return nil
+	}
+	if !auth.Can(user, enum.PermClassManage, c.Type().Org()) {
+		errpage.Forbidden(r, user)
+		return nil
+	}
+	return c
+}
+
+// HandleRoster handles /classes/$id/roster requests.  A GET shows the class
+// roster, with check-in controls for a selected session.  A POST performs one
+// of the roster actions:  checkin=$pid or uncheck=$pid (with session=$eid),
+// create (person records for unlinked registrations with no probable
+// duplicates), or graduate.
+func HandleRoster(r *request.Request, cidstr string) {
+	var (
+		user *person.Person
+		c    *class.Class
+		eid  = event.ID(util.ParseID(r.FormValue("session")))
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if c = rosterClass(r, user, cidstr); c == nil {
+		return
+	}
+	if r.Method == http.MethodPost {
+		switch {
+		case r.FormValue("checkin") != "":
+			checkIn(r, c, eid, person.ID(util.ParseID(r.FormValue("checkin"))), true)
+		case r.FormValue("uncheck") != "":
+			checkIn(r, c, eid, person.ID(util.ParseID(r.FormValue("uncheck"))), false)
+		case r.FormValue("create") != "":
+			createStudents(r, c)
+		case r.FormValue("graduate") != "":
+			graduateStudents(r, c)
+		}
+	}
+	RenderRoster(r, user, c, eid)
+}
+
+// rosterStudent is a student on the class roster.
+type rosterStudent struct {
+	reg        *classreg.ClassReg
+	attendance map[event.ID]taskperson.Flag
+	matches    int
+	graduated  bool
+}
+
+// RenderRoster renders the class roster.  The check-in controls are for the
+// specified session, or if that is zero, for today's session (or the most
+// recent one, or the first one).
+func RenderRoster(r *request.Request, user *person.Person, c *class.Class, eid event.ID) {
+	var (
+		sessions   []*event.Event
+		sids       []event.ID
+		students   []*rosterStudent
+		session    *event.Event
+		sessTask   *task.Task
+		attendance = c.Attendance(r)
+		matcher    *personmerge.Matcher
+		canCreate  bool
+		canGrad    bool
+		today      = time.Now().Format("2006-01-02")
+	)
+	c.Sessions(r, event.FID|event.FName|event.FStart|event.FEnd, func(e *event.Event) {
+		sessions = append(sessions, e.Clone())
+		sids = append(sids, e.ID())
+	})
+	for _, e := range sessions {
+		if e.ID() == eid || (eid == 0 && (session == nil || e.Start()[:10] <= today)) {
+			session = e
+		}
+	}
+	if session != nil {
+		sessTask = c.SessionTask(r, session.ID(), task.FID)
+	}
+	classreg.AllForClass(r, c.ID(), rosterRegFields, func(cr *classreg.ClassReg) {
+		if !cr.Waitlist() {
+			students = append(students, &rosterStudent{reg: cr.Clone()})
+		}
+	})
+	for _, s := range students {
+		if s.reg.Person() == 0 {
+			if matcher == nil {
+				matcher = personmerge.NewMatcher(r)
+			}
+			if s.matches = len(matcher.Find(s.reg.LastName()+", "+s.reg.FirstName(), s.reg.Email(), s.reg.CellPhone())); s.matches == 0 {
+				canCreate = true
+			}
+			continue
+		}
+		s.attendance = attendance[s.reg.Person()]
+		if c.GradRole() != 0 {
+			s.graduated = hasRole(r, s.reg.Person(), c.GradRole())
+			if !s.graduated && class.Completed(sids, s.attendance) {
+				canGrad = true
+			}
+		}
+	}
+	r.HTMLNoCache()
+	ui.Page(r, user, ui.PageOpts{Title: "Class Roster", MenuItem: "classes"}, func(main *htmlb.Element) {
+		main.E("div class=reglistClass>%s", c.Type().String())
+		main.E("div class=reglistStart>%s", c.Start())
+		buttons := main.E("div class=reglistButtons")
+		buttons.E("a href=/classes/%d/reglist up-target=main class='sbtn sbtn-xsmall sbtn-primary'>Registrations", c.ID())
+		buttons.E("a href=/classes/%d/sessions up-target=main class='sbtn sbtn-xsmall sbtn-primary'>Sessions", c.ID())
+		if len(students) == 0 {
+			main.E("div class=rosterNote>No students are registered for this class.")
+			return
+		}
+		if len(sessions) != 0 {
+			tabs := main.E("div class=rosterSessions")
+			tabs.E("span>Check in for session:")
+			for i, e := range sessions {
+				tabs.E("a href=/classes/%d/roster?session=%d up-target=main title=%s class='sbtn sbtn-xsmall'", c.ID(), e.ID(), e.Start()[:10],
+					e == session, "class=sbtn-primary", e != session, "class=sbtn-secondary").T(fmt.Sprint(i + 1))
+			}
+			tabs.E("span").T(fmt.Sprintf("%s %s", session.Start()[:10], session.Name()))
+		}
+		grid := main.E("div class=rosterGrid")
+		grid.E("div").E("b>Student")
+		grid.E("div").E("b>Person Record")
+		grid.E("div").E("b>Check In")
+		grid.E("div").E("b>Sessions")
+		grid.E("div").E("b>Status")
+		for _, s := range students {
+			grid.E("div>%s %s", s.reg.FirstName(), s.reg.LastName())
+			if s.reg.Person() == 0 {
+				cell := grid.E("div")
+				cell.E("a href=/classes/%d/roster/%d up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-xsmall sbtn-secondary'>Link", c.ID(), s.reg.ID())
+				if s.matches != 0 {
+					cell.E("span class=rosterMatches>%d possible %s", s.matches, plural(s.matches, "match", "matches"))
+				}
+				grid.E("div")
+				grid.E("div")
+				grid.E("div")
+				continue
+			}
+			grid.E("div").E("a href=/people/%d up-target=main>%d", s.reg.Person(), s.reg.Person())
+			if sessTask == nil {
+				grid.E("div")
+			} else {
+				form := grid.E("form method=POST up-target=main")
+				form.E("input type=hidden name=csrf value=%s", r.CSRF)
+				form.E("input type=hidden name=session value=%d", session.ID())
+				if s.attendance[session.ID()]&taskperson.Attended != 0 {
+					form.E("span class=rosterCheckedIn>✓")
+					form.E("button type=submit name=uncheck value=%d class='sbtn sbtn-xsmall sbtn-secondary'>Undo", s.reg.Person())
+				} else {
+					form.E("button type=submit name=checkin value=%d class='sbtn sbtn-xsmall sbtn-primary'>Check In", s.reg.Person())
+				}
+			}
+			var credited int
+			for _, sid := range sids {
+				if s.attendance[sid]&taskperson.Credited != 0 {
+					credited++
+				}
+			}
+			grid.E("div class=rosterCount>%d of %d", credited, len(sids))
+			switch {
+			case s.graduated:
+				grid.E("div>Graduated")
+			case class.Completed(sids, s.attendance):
+				grid.E("div>Completed")
+			default:
+				grid.E("div")
+			}
+		}
+		if canCreate || canGrad {
+			form := main.E("form method=POST up-target=main class=reglistButtons")
+			form.E("input type=hidden name=csrf value=%s", r.CSRF)
+			if session != nil {
+				form.E("input type=hidden name=session value=%d", session.ID())
+			}
+			if canCreate {
+				form.E("input type=submit name=create class='sbtn sbtn-xsmall sbtn-primary' value='Create Person Records'")
+			}
+			if canGrad {
+				form.E("input type=submit name=graduate class='sbtn sbtn-xsmall sbtn-primary' value='Graduate Students'")
+			}
+		}
+		if canCreate {
+			main.E("div class=rosterNote>Create Person Records creates records for all students who don’t have one and don’t resemble anyone already in the database.  Use the Link button for the others.")
+		}
+		if c.GradRole() == 0 {
+			main.E("div class=rosterNote>To graduate students, set a Graduate Role for this class in the class settings.")
+		} else if canGrad {
+			main.E("div class=rosterNote>Graduate Students gives the graduate role to every student who has been credited for every session, and sends them an email with instructions for logging in.")
+		}
+	})
+}
+
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+// checkIn records (or with in=false, clears) a student's attendance at a
+// class session.
+func checkIn(r *request.Request, c *class.Class, eid event.ID, pid person.ID, in bool) {
+	var (
+		e *event.Event
+		t *task.Task
+		p *person.Person
+	)
+	if e = event.WithID(r, eid, taskperson.SetEventFields); e == nil {
+		return
+	}
+	if t = c.SessionTask(r, e.ID(), taskperson.SetTaskFields); t == nil {
+		return
+	}
+	if p = person.WithID(r, pid, taskperson.SetPersonFields); p == nil {
+		return
+	}
+	r.Transaction(func() {
+		minutes, flags := taskperson.Get(r, t.ID(), p.ID())
+		if in {
+			flags |= taskperson.Attended | taskperson.Credited
+		} else {
+			flags &^= taskperson.Attended | taskperson.Credited
+		}
+		taskperson.Set(r, e, t, p, minutes, flags)
+	})
+}
+
+// createStudents creates person records for all of the students registered
+// for the class who don't have one and who have no probable duplicates in the
+// database.
+func createStudents(r *request.Request, c *class.Class) {
+	var (
+		regs    []*classreg.ClassReg
+		matcher = personmerge.NewMatcher(r)
+	)
+	classreg.AllForClass(r, c.ID(), rosterRegFields, func(cr *classreg.ClassReg) {
+		if !cr.Waitlist() && cr.Person() == 0 {
+			regs = append(regs, cr.Clone())
+		}
+	})
+	r.Transaction(func() {
+		for _, cr := range regs {
+			if len(matcher.Find(cr.LastName()+", "+cr.FirstName(), cr.Email(), cr.CellPhone())) == 0 {
+				linkStudent(r, c, cr, nil)
+			}
+		}
+	})
+}
+
+// linkStudent links a class registration to the specified person record.  If
+// the person is nil, a new person record is created from the registration
+// data, omitting any email address or phone number that would duplicate an
+// existing person's.  It must be called in a transaction.
+func linkStudent(r *request.Request, c *class.Class, cr *classreg.ClassReg, p *person.Person) {
+	if p == nil {
+		up := &person.Updater{
+			InformalName:     cr.FirstName() + " " + cr.LastName(),
+			FormalName:       cr.FirstName() + " " + cr.LastName(),
+			SortName:         cr.LastName() + ", " + cr.FirstName(),
+			Email:            cr.Email(),
+			CellPhone:        cr.CellPhone(),
+			UnsubscribeToken: util.RandomToken(),
+		}
+		if up.Email != "" && up.DuplicateEmail(r) {
+			up.Email = ""
+		}
+		if up.DuplicateCellPhone(r) {
+			up.CellPhone = ""
+		}
+		p = person.Create(r, up)
+	}
+	ucr := cr.Updater(r, c, p, nil)
+	ucr.Person = p
+	cr.Update(r, ucr)
+	if c.Role() != 0 {
+		if rl := role.WithID(r, c.Role(), role.FID|role.FName); rl != nil {
+			personrole.AddRole(r, p, rl)
+		}
+	}
+}
+
+// graduateStudents gives the class's graduate role to all students who have
+// been credited for every session and don't already hold it, and sends each
+// of them an onboarding email.
+func graduateStudents(r *request.Request, c *class.Class) {
+	const personFields = person.FID | person.FInformalName | person.FEmail
+	var (
+		sids       []event.ID
+		grads      []*person.Person
+		rl         *role.Role
+		attendance = c.Attendance(r)
+	)
+	if rl = role.WithID(r, c.GradRole(), role.FID|role.FName); rl == nil {
+		return
+	}
+	c.Sessions(r, event.FID, func(e *event.Event) {
+		sids = append(sids, e.ID())
+	})
+	classreg.AllForClass(r, c.ID(), classreg.FPerson|classreg.FWaitlist, func(cr *classreg.ClassReg) {
+		if cr.Person() == 0 || cr.Waitlist() || !class.Completed(sids, attendance[cr.Person()]) {
+			return
+		}
+		if p := person.WithID(r, cr.Person(), personFields); p != nil && !hasRole(r, p.ID(), rl.ID()) {
+			grads = append(grads, p)
+		}
+	})
+	r.Transaction(func() {
+		for _, p := range grads {
+			personrole.AddRole(r, p, rl)
+		}
+	})
+	for _, p := range grads {
+		sendOnboarding(r, c, p)
+	}
+}
+
+// sendOnboarding sends a graduate of the class an email welcoming them and
+// explaining how to log in.
+func sendOnboarding(r *request.Request, c *class.Class, p *person.Person) {
+	var (
+		body  bytes.Buffer
+		ctype = c.Type().String()
+	)
+	if p.Email() == "" {
+		return
+	}
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\n", config.Get("fromEmail"), (&mail.Address{Name: p.InformalName(), Address: p.Email()}).String())
+	fmt.Fprintf(&body, "Subject: %s / %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n",
+		"Welcome to Sunnyvale SERV", l10n.Localize("Welcome to Sunnyvale SERV", "es"))
+	for i, lang := range []string{"en", "es"} {
+		if i != 0 {
+			fmt.Fprint(&body, "\r\n\r\n----------------------------------------\r\n\r\n")
+		}
+		fmt.Fprintf(&body, l10n.Localize("Greetings, %s,", lang), p.InformalName())
+		fmt.Fprint(&body, "\r\n\r\n")
+		fmt.Fprintf(&body, l10n.Localize("Congratulations on completing %s!  You are now a member of Sunnyvale SERV, and you have an account on our web site, where you can see upcoming events, sign up for shifts, and keep your contact information up to date.", lang), l10n.Localize(ctype, lang))
+		fmt.Fprint(&body, "\r\n\r\n")
+		fmt.Fprintf(&body, l10n.Localize("To log in, go to %s/login and use your email address, %s.  If you have not yet set a password, or have forgotten it, use the password reset link on that page.", lang), config.Get("siteURL"), p.Email())
+		fmt.Fprint(&body, "\r\n\r\n")
+		fmt.Fprint(&body, l10n.Localize("If you have any questions, just reply to this email.", lang))
+		fmt.Fprint(&body, "\r\n\r\nSunnyvale SERV\r\nserv@sunnyvale.ca.gov")
+	}
+	fmt.Fprint(&body, "\r\n")
+	if err := sendmail.SendMessage(r.Context(), config.Get("fromAddr"), []string{p.Email()}, body.Bytes()); err != nil {
+		r.LogEntry.Problems.AddError(err)
+	}
+}
+
+// HandleRosterLink handles /classes/$id/roster/$regid requests.  It allows a
+// leader to link a class registration to an existing person record, or to
+// create a new one for it.
+func HandleRosterLink(r *request.Request, cidstr, cridstr string) {
+	var (
+		user    *person.Person
+		c       *class.Class
+		cr      *classreg.ClassReg
+		matches []*personmerge.Match
+		options = []person.ID{0}
+		labels  = map[person.ID]string{0: "Create a new person record"}
+		choice  person.ID
+		f       form.Form
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if c = rosterClass(r, user, cidstr); c == nil {
+		return
+	}
+	if cr = classreg.WithID(r, classreg.ID(util.ParseID(cridstr)), rosterRegFields); cr == nil || cr.Class() != c.ID() || cr.Person() != 0 {
+		errpage.NotFound(r, user)
+		return
+	}
+	matches = personmerge.NewMatcher(r).Find(cr.LastName()+", "+cr.FirstName(), cr.Email(), cr.CellPhone())
+	for _, m := range matches {
+		options = append(options, m.Person.ID())
+		labels[m.Person.ID()] = fmt.Sprintf("%s (%s)", m.Person.SortName(), strings.Join(m.Reasons, ", "))
+	}
+	if len(matches) != 0 {
+		choice = matches[0].Person.ID()
+	}
+	f.Attrs = "method=POST up-target=main"
+	f.Dialog = true
+	f.Title = "Link Student"
+	f.Rows = []form.Row{
+		&form.MessageRow{
+			LabeledRow: form.LabeledRow{Label: "Student"},
+			HTML:       html.EscapeString(strings.TrimSpace(fmt.Sprintf("%s %s  %s  %s", cr.FirstName(), cr.LastName(), cr.Email(), cr.CellPhone()))),
+		},
+		&linkChoiceRow{form.RadioGroupRow[person.ID]{
+			LabeledRow: form.LabeledRow{
+				RowID: "rosterLinkChoice",
+				Label: "Person",
+				Help:  "A new person record will not include an email address or phone number already used by someone else.",
+			},
+			Name:      "person",
+			ValueP:    &choice,
+			Options:   options,
+			ValueFunc: func(id person.ID) string { return fmt.Sprint(int(id)) },
+			LabelFunc: func(_ *request.Request, id person.ID) string { return labels[id] },
+			Validate:  form.NoValidate,
+		}, cr},
+	}
+	f.Buttons = []*form.Button{{
+		Label: "Save",
+		OnClick: func() bool {
+			var p *person.Person
+			if choice != 0 {
+				p = person.WithID(r, choice, person.FID|person.FInformalName)
+			}
+			r.Transaction(func() {
+				linkStudent(r, c, cr, p)
+			})
+			RenderRoster(r, user, c, 0)
+			return true
+		},
+	}}
+	f.Handle(r)
+}
+
+// linkChoiceRow is the row for choosing the person record to link to.  It
+// refuses to create a new person record whose name would duplicate an
+// existing one.
+type linkChoiceRow struct {
+	form.RadioGroupRow[person.ID]
+	cr *classreg.ClassReg
+}
+
+func (lcr *linkChoiceRow) Read(r *request.Request) bool {
+	if !lcr.RadioGroupRow.Read(r) {
+		return false
+	}
+	if *lcr.ValueP == 0 {
+		up := &person.Updater{SortName: lcr.cr.LastName() + ", " + lcr.cr.FirstName()}
+		if up.DuplicateSortName(r) {
+			lcr.Error = "Another person already has this name.  Link the student to that person, or change the name on the registration."
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,295 @@
+package classes
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/classreg"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/store/task"
+	"sunnyvaleserv.org/portal/store/taskperson"
+	"sunnyvaleserv.org/portal/store/taskrole"
+	"sunnyvaleserv.org/portal/store/venue"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/ui/form"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const sessionsClassFields = class.FID | class.FType | class.FStart | class.FRegURL | class.FRole
+
+// GetSessions handles GET /classes/$id/sessions requests.
+func GetSessions(r *request.Request, cidstr string) {
+	var (
+		user *person.Person
+		c    *class.Class
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if c = class.WithID(r, class.ID(util.ParseID(cidstr)), sessionsClassFields); c == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if !auth.Can(user, enum.PermClassManage, c.Type().Org()) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	RenderSessions(r, user, c)
+}
+
+type student struct {
+	pid        person.ID
+	name       string
+	attendance map[event.ID]taskperson.Flag
+}
+
+// RenderSessions renders the list of sessions of the class, and the attendance
+// of each student at each session.
+func RenderSessions(r *request.Request, user *person.Person, c *class.Class) {
+	var (
+		sessions   []*event.Event
+		sids       []event.ID
+		students   []*student
+		attendance = c.Attendance(r)
+	)
+	c.Sessions(r, event.FID|event.FName|event.FStart|event.FEnd, func(e *event.Event) {
+		sessions = append(sessions, e.Clone())
+		sids = append(sids, e.ID())
+	})
+	// The students are everyone registered for the class (and not on the
+	// waitlist), plus anyone else with attendance recorded at a session.
+	classreg.AllForClass(r, c.ID(), classreg.FPerson|classreg.FFirstName|classreg.FLastName|classreg.FWaitlist, func(cr *classreg.ClassReg) {
+		if cr.Person() == 0 || cr.Waitlist() || slices.ContainsFunc(students, func(s *student) bool { return s.pid == cr.Person() }) {
+			return
+		}
+		students = append(students, &student{pid: cr.Person(), name: cr.LastName() + ", " + cr.FirstName(), attendance: attendance[cr.Person()]})
+	})
+	for pid, att := range attendance {
+		if !slices.ContainsFunc(students, func(s *student) bool { return s.pid == pid }) {
+			if p := person.WithID(r, pid, person.FSortName); p != nil {
+				students = append(students, &student{pid: pid, name: p.SortName(), attendance: att})
+			}
+		}
+	}
+	slices.SortFunc(students, func(a, b *student) int {
+		return cmp.Or(strings.Compare(strings.ToLower(a.name), strings.ToLower(b.name)), cmp.Compare(a.pid, b.pid))
+	})
+	r.HTMLNoCache()
+	ui.Page(r, user, ui.PageOpts{Title: "Class Sessions", MenuItem: "classes"}, func(main *htmlb.Element) {
+		main.E("div class=reglistClass>%s", c.Type().String())
+		main.E("div class=reglistStart>%s", c.Start())
+		buttons := main.E("div class=reglistButtons")
+		if c.RegURL() == "" {
+			buttons.E("a href=/classes/%d/reglist up-target=main class='sbtn sbtn-xsmall sbtn-primary'>Registrations", c.ID())
+			buttons.E("a href=/classes/%d/roster up-target=main class='sbtn sbtn-xsmall sbtn-primary'>Roster", c.ID())
+		}
+		if c.Role() != 0 {
+			buttons.E("a href=/classes/%d/sessions/add up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-xsmall sbtn-primary'>Add Session", c.ID())
+		} else {
+			main.E("div class=sessionsNote>Sessions can be added only to classes with a student role.")
+		}
+		if len(sessions) == 0 {
+			main.E("div class=sessionsNote>No sessions have been added to this class.")
+			return
+		}
+		list := main.E("div class=sessionsList")
+		for i, e := range sessions {
+			list.E("div>%d.", i+1)
+			list.E("div>%s %s–%s", e.Start()[:10], e.Start()[11:], e.End()[11:])
+			list.E("div").E("a href=/events/%d up-target=main>%s", e.ID(), e.Name())
+		}
+		if len(students) == 0 {
+			main.E("div class=sessionsNote>No students are registered for this class.")
+			return
+		}
+		grid := main.E("div class=sessionsGrid style=grid-template-columns:max-content%s", strings.Repeat(" 2.5rem", len(sessions))+" max-content")
+		grid.E("div").E("b>Student")
+		for i := range sessions {
+			grid.E("div class=sessionsNum").E("b>%d", i+1)
+		}
+		grid.E("div").E("b>Status")
+		for _, s := range students {
+			grid.E("div").E("a href=/people/%d up-target=main>%s", s.pid, s.name)
+			for _, e := range sessions {
+				switch flags := s.attendance[e.ID()]; {
+				case flags&taskperson.Credited != 0:
+					grid.E("div class='sessionsNum sessionsCredited' title=Credited>✓")
+				case flags&taskperson.Attended != 0:
+					grid.E("div class=sessionsNum title='Attended without credit'>A")
+				default:
+					grid.E("div class='sessionsNum sessionsAbsent'>–")
+				}
+			}
+			if class.Completed(sids, s.attendance) {
+				grid.E("div class=sessionsCompleted>Completed")
+			} else {
+				grid.E("div")
+			}
+		}
+		main.E("div class=sessionsNote>✓ = credited, A = attended without credit.  A student has completed the class when they have been credited for every session.")
+	})
+}
+
+// HandleAddSession handles /classes/$id/sessions/add requests.
+func HandleAddSession(r *request.Request, cidstr string) {
+	var (
+		user   *person.Person
+		c      *class.Class
+		rl     *role.Role
+		ue     event.Updater
+		date   string
+		start  string
+		end    string
+		venues []*venue.Venue
+		f      form.Form
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if c = class.WithID(r, class.ID(util.ParseID(cidstr)), sessionsClassFields); c == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if !auth.Can(user, enum.PermClassManage, c.Type().Org()) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if rl = role.WithID(r, c.Role(), role.FID|role.FName); rl == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	venue.All(r, venue.FID|venue.FName, func(v *venue.Venue) {
+		clone := *v
+		venues = append(venues, &clone)
+	})
+	count := 0
+	c.Sessions(r, event.FID|event.FStart, func(e *event.Event) {
+		count++
+		date = e.Start()[:10]
+		start, end = e.Start()[11:], e.End()[11:]
+	})
+	if count == 0 {
+		date = c.Start()
+	} else if d, err := time.Parse("2006-01-02", date); err == nil {
+		// Most classes meet weekly, so suggest the same time a week
+		// after the last session.
+		date = d.AddDate(0, 0, 7).Format("2006-01-02")
+	}
+	ue.Name = fmt.Sprintf("%s Session %d", c.Type(), count+1)
+	f.Attrs = "method=POST up-target=main"
+	f.Dialog = true
+	f.Title = "Add Session"
+	f.Buttons = []*form.Button{{
+		Label: "Add",
+		OnClick: func() bool {
+			ue.Start, ue.End = date+"T"+start, date+"T"+end
+			if ue.Name == "" {
+				f.Rows[0].(*form.InputRow).Error = "The name is required."
+				return false
+			}
+			if ue.DuplicateName(r) {
+				f.Rows[0].(*form.InputRow).Error = "Another event on this date has this name."
+				return false
+			}
+			r.Transaction(func() {
+				e := event.Create(r, &ue)
+				t := task.Create(r, &task.Updater{Event: e, Name: "Class", Org: c.Type().Org()})
+				taskrole.Set(r, e, t, []*role.Role{rl}, []*role.Role{})
+				c.AddSession(r, e)
+			})
+			RenderSessions(r, user, c)
+			return true
+		},
+	}}
+	f.Rows = []form.Row{
+		&form.InputRow{
+			LabeledRow: form.LabeledRow{RowID: "sessionsName", Label: "Name"},
+			Name:       "name",
+			ValueP:     &ue.Name,
+			Validate:   form.NoValidate,
+		},
+		&requiredDateRow{form.DateRow{InputRow: form.InputRow{
+			LabeledRow: form.LabeledRow{RowID: "sessionsDate", Label: "Date"},
+			Name:       "date",
+			ValueP:     &date,
+		}}},
+		&timeRow{form.InputRow{
+			LabeledRow: form.LabeledRow{RowID: "sessionsStart", Label: "Start Time"},
+			Name:       "start",
+			ValueP:     &start,
+		}},
+		&endTimeRow{timeRow{form.InputRow{
+			LabeledRow: form.LabeledRow{RowID: "sessionsEnd", Label: "End Time"},
+			Name:       "end",
+			ValueP:     &end,
+		}}, &start},
+		&form.SelectRow[*venue.Venue]{
+			LabeledRow:  form.LabeledRow{RowID: "sessionsVenue", Label: "Venue"},
+			Name:        "venue",
+			ValueP:      &ue.Venue,
+			Options:     venues,
+			ValueFunc:   func(v *venue.Venue) string { return fmt.Sprint(v.ID()) },
+			LabelFunc:   func(_ *request.Request, v *venue.Venue) string { return v.Name() },
+			Placeholder: "TBD",
+			Validate:    form.NoValidate,
+		},
+	}
+	f.Handle(r)
+}
+
+type requiredDateRow struct{ form.DateRow }
+
+func (rdr *requiredDateRow) Read(r *request.Request) bool {
+	if !rdr.DateRow.Read(r) {
+		return false
+	}
+	if *rdr.ValueP == "" {
+		rdr.Error = "The date is required."
+		return false
+	}
+	return true
+}
+
+// timeRow is a row with a time input.
+type timeRow struct{ form.InputRow }
+
+func (tr *timeRow) Emit(r *request.Request, parent *htmlb.Element, focus bool) {
+	tr.Validate = form.NoValidate
+	tr.EmitSuffix(r, tr.EmitPrefix(r, parent, focus).A("type=time"))
+}
+func (tr *timeRow) Read(r *request.Request) bool {
+	tr.InputRow.Read(r)
+	if t, err := time.Parse("15:04", *tr.ValueP); err != nil || t.Format("15:04") != *tr.ValueP {
+		tr.Error = fmt.Sprintf("Please enter a valid %s.", strings.ToLower(tr.Label))
+		return false
+	}
+	return true
+}
+
+// endTimeRow is a timeRow whose time must be after the start time.
+type endTimeRow struct {
+	timeRow
+	start *string
+}
+
+func (etr *endTimeRow) Read(r *request.Request) bool {
+	if !etr.timeRow.Read(r) {
+		return false
+	}
+	if *etr.ValueP <= *etr.start {
+		etr.Error = "The end time must be after the start time."
+		return false
+	}
+	return true
+}
@@ -0,0 +1,302 @@
+package classes
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/survey"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/ui/form"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const surveyClassFields = class.FID | class.FType | class.FStart | class.FRegURL
+
+// HandleSurvey handles /survey/$token requests.  Students use it to respond to
+// the feedback survey for a class they took.  It does not require a login; the
+// token is the authorization.
+func HandleSurvey(r *request.Request, token string) {
+	var (
+		inv       *survey.Invite
+		c         *class.Class
+		questions []*survey.Question
+		values    []string
+		name      string
+		f         form.Form
+	)
+	if inv = survey.InviteWithToken(r, token); inv != nil {
+		c = class.WithID(r, inv.Class, surveyClassFields)
+	}
+	if inv == nil || c == nil {
+		errpage.NotFound(r, nil)
+		return
+	}
+	if inv.Responded {
+		showSurveyThanks(r, c, r.Loc("We have already received your response to this survey.  Thank you!"))
+		return
+	}
+	survey.Questions(r, c.Type(), func(q *survey.Question) {
+		questions = append(questions, q.Clone())
+	})
+	values = make([]string, len(questions))
+	f.PageWrapper = func(r *request.Request, fn func(*htmlb.Element)) {
+		ui.Page(r, nil, ui.PageOpts{Title: r.Loc("Class Survey"), MenuItem: "classes"}, func(main *htmlb.Element) {
+			main.E("div class=reglistClass").T(r.Loc(c.Type().String()))
+			main.E("div class=reglistStart").T(c.Start())
+			main.E("div class=surveyIntro").R(r.Loc("Thank you for taking this class.  Please help us improve it by answering these questions.  All questions are optional."))
+			fn(main)
+		})
+	}
+	f.Attrs = "method=POST up-target=main"
+	f.Buttons = []*form.Button{{
+		Label: "Submit",
+		OnClick: func() bool {
+			var answers = make(map[survey.QuestionID]string)
+			for i, q := range questions {
+				if values[i] != "" {
+					answers[q.ID] = values[i]
+				}
+			}
+			r.Transaction(func() {
+				inv.Respond(r, name, answers)
+			})
+			showSurveyThanks(r, c, r.Loc("Thank you for your feedback!"))
+			return true
+		},
+	}}
+	for i, q := range questions {
+		f.Rows = append(f.Rows, surveyQuestionRow(q, &values[i], r.Language))
+	}
+	f.Rows = append(f.Rows, &form.InputRow{
+		LabeledRow: form.LabeledRow{
+			RowID: "surveyName",
+			Label: "Your name",
+			Help:  "Optional.  Leave this blank to respond anonymously.",
+		},
+		Name:     "name",
+		ValueP:   &name,
+		Validate: form.NoValidate,
+	})
+	f.Handle(r)
+}
+
+// surveyQuestionRow returns the form row for answering a survey question.
+func surveyQuestionRow(q *survey.Question, valueP *string, lang string) form.Row {
+	var (
+		label = q.Text(lang)
+		name  = fmt.Sprintf("q%d", q.ID)
+		rowid = fmt.Sprintf("surveyQ%d", q.ID)
+	)
+	switch q.Kind {
+	case survey.Rating:
+		var options []string
+		for i := 1; i <= survey.MaxRating; i++ {
+			options = append(options, strconv.Itoa(i))
+		}
+		return &form.RadioGroupRow[string]{
+			LabeledRow: form.LabeledRow{RowID: rowid, Label: label, Help: "1 = poor, 5 = excellent"},
+			Name:       name,
+			ValueP:     valueP,
+			Options:    options,
+			LabelFunc:  func(_ *request.Request, v string) string { return v },
+			Validate:   form.NoValidate,
+		}
+	case survey.Choice:
+		var (
+			options []string
+			choices = q.Choices(lang)
+		)
+		for i := range choices {
+			options = append(options, strconv.Itoa(i))
+		}
+		return &form.RadioGroupRow[string]{
+			LabeledRow: form.LabeledRow{RowID: rowid, Label: label},
+			Name:       name,
+			ValueP:     valueP,
+			Options:    options,
+			LabelFunc:  func(_ *request.Request, v string) string { return choices[util.ParseID(v)] },
+			Validate:   form.NoValidate,
+		}
+	default:
+		return &form.TextAreaRow{
+			LabeledRow: form.LabeledRow{RowID: rowid, Label: label},
+			Name:       name,
+			ValueP:     valueP,
+			Wrap:       "soft",
+			Validate:   form.NoValidate,
+		}
+	}
+}
+
+// showSurveyThanks displays a page with the specified (localized) message
+// after a survey response.
+func showSurveyThanks(r *request.Request, c *class.Class, message string) {
+	ui.Page(r, nil, ui.PageOpts{Title: r.Loc("Class Survey"), MenuItem: "classes"}, func(main *htmlb.Element) {
+		main.E("div class=reglistClass").T(r.Loc(c.Type().String()))
+		main.E("div class=reglistStart").T(c.Start())
+		main.E("div class=surveyIntro").T(message)
+	})
+}
+
+// questionResults holds the aggregated answers to a single survey question.
+type questionResults struct {
+	q       *survey.Question
+	count   int
+	total   int
+	counts  []int
+	answers []string
+}
+
+// GetSurveyResults handles GET /classes/$id/survey requests.  It shows the
+// aggregated results of the feedback survey for the class, or exports the
+// individual responses as CSV if the format=csv parameter is given.
+func GetSurveyResults(r *request.Request, cidstr string) {
+	var (
+		user      *person.Person
+		c         *class.Class
+		results   []*questionResults
+		responses []*survey.Response
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if c = class.WithID(r, class.ID(util.ParseID(cidstr)), surveyClassFields); c == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if !auth.Can(user, enum.PermClassManage, c.Type().Org()) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	survey.Questions(r, c.Type(), func(q *survey.Question) {
+		results = append(results, &questionResults{q: q.Clone(), counts: make([]int, max(survey.MaxRating+1, len(q.EnChoices)))})
+	})
+	survey.Responses(r, c.ID(), func(resp *survey.Response) {
+		responses = append(responses, resp)
+	})
+	if r.FormValue("format") == "csv" {
+		renderSurveyCSV(r, c, results, responses)
+		return
+	}
+	for _, qr := range results {
+		for _, resp := range responses {
+			answer, ok := resp.Answers[qr.q.ID]
+			if !ok {
+				continue
+			}
+			switch qr.q.Kind {
+			case survey.Rating, survey.Choice:
+				if n, err := strconv.Atoi(answer); err == nil && n >= 0 && n < len(qr.counts) {
+					qr.count++
+					qr.total += n
+					qr.counts[n]++
+				}
+			default:
+				if resp.Name != "" {
+					answer = fmt.Sprintf("%s (%s)", answer, resp.Name)
+				}
+				qr.answers = append(qr.answers, answer)
+			}
+		}
+	}
+	sent, responded := survey.InviteCounts(r, c.ID())
+	r.HTMLNoCache()
+	ui.Page(r, user, ui.PageOpts{Title: "Class Survey Results", MenuItem: "classes"}, func(main *htmlb.Element) {
+		main.E("div class=reglistClass>%s", c.Type().String())
+		main.E("div class=reglistStart>%s", c.Start())
+		buttons := main.E("div class=reglistButtons")
+		if c.RegURL() == "" {
+			buttons.E("a href=/classes/%d/reglist up-target=main class='sbtn sbtn-xsmall sbtn-primary'>Registrations", c.ID())
+		}
+		buttons.E("a href=/classes/surveys/%d up-target=main class='sbtn sbtn-xsmall sbtn-primary'>Edit Questions", c.Type().Int())
+		if len(responses) != 0 {
+			buttons.E("a href=/classes/%d/survey?format=csv download class='sbtn sbtn-xsmall sbtn-primary'>Export", c.ID())
+		}
+		if sent == 0 {
+			main.E("div class=surveyNote>No survey invitations have been sent for this class.  They are sent to the registered students after the last session of the class.")
+		} else {
+			main.E("div class=surveyNote>%d of %d invited students have responded.", responded, sent)
+		}
+		if len(results) == 0 {
+			main.E("div class=surveyNote>There are no survey questions for this class type.")
+			return
+		}
+		for _, qr := range results {
+			main.E("div class=surveyResultsQuestion").T(qr.q.EnText)
+			switch qr.q.Kind {
+			case survey.Rating:
+				if qr.count == 0 {
+					main.E("div class=surveyNote>No answers.")
+					break
+				}
+				main.E("div class=surveyNote>Average %.1f from %d answers.", float64(qr.total)/float64(qr.count), qr.count)
+				emitSurveyCounts(main, qr, func(i int) string { return strconv.Itoa(i + 1) }, qr.counts[1:survey.MaxRating+1])
+			case survey.Choice:
+				if qr.count == 0 {
+					main.E("div class=surveyNote>No answers.")
+					break
+				}
+				emitSurveyCounts(main, qr, func(i int) string { return qr.q.EnChoices[i] }, qr.counts[:len(qr.q.EnChoices)])
+			default:
+				if len(qr.answers) == 0 {
+					main.E("div class=surveyNote>No answers.")
+					break
+				}
+				list := main.E("ul class=surveyResultsText")
+				for _, answer := range qr.answers {
+					list.E("li").T(answer)
+				}
+			}
+		}
+	})
+}
+
+// emitSurveyCounts emits a bar chart of the number of answers with each
+// rating or choice.
+func emitSurveyCounts(main *htmlb.Element, qr *questionResults, label func(int) string, counts []int) {
+	grid := main.E("div class=surveyResultsGrid")
+	for i, count := range counts {
+		grid.E("div").T(label(i))
+		grid.E("div class=surveyResultsBar").E("div style=width:%d%%", count*100/qr.count)
+		grid.E("div>%d", count)
+	}
+}
+
+// renderSurveyCSV writes the individual responses to the survey for the class
+// in CSV format.
+func renderSurveyCSV(r *request.Request, c *class.Class, results []*questionResults, responses []*survey.Response) {
+	r.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	r.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s %s Survey.csv"`, c.Type(), c.Start()))
+	out := csv.NewWriter(r)
+	out.UseCRLF = true
+	row := []string{"Respondent"}
+	for _, qr := range results {
+		row = append(row, qr.q.EnText)
+	}
+	out.Write(row)
+	for _, resp := range responses {
+		row = row[:0]
+		if resp.Name != "" {
+			row = append(row, resp.Name)
+		} else {
+			row = append(row, "(anonymous)")
+		}
+		for _, qr := range results {
+			answer := resp.Answers[qr.q.ID]
+			if n, err := strconv.Atoi(answer); err == nil && qr.q.Kind == survey.Choice && n >= 0 && n < len(qr.q.EnChoices) {
+				answer = qr.q.EnChoices[n]
+			}
+			row = append(row, answer)
+		}
+		out.Write(row)
+	}
+	out.Flush()
+}
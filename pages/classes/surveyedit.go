@@ -0,0 +1,250 @@
+package classes
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/survey"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/ui/form"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// surveyType returns the class type named in a /classes/surveys/$ctype URL,
+// after verifying that the user is a leader of its organization.  It returns
+// zero (after rendering an error page) if not.
+func surveyType(r *request.Request, user *person.Person, ctypestr string) class.Type {
+	ctype := class.Type(util.ParseID(ctypestr))
+	if !slices.Contains(class.AllTypes, ctype) {
+		errpage.NotFound(r, user)
+		return 0
+	}
+	if !auth.Can(user, enum.PermClassManage, ctype.Org()) {
+		errpage.Forbidden(r, user)
+		return 0
+	}
+	return ctype
+}
+
+// HandleSurveyQuestions handles /classes/surveys/$ctype requests.  A GET shows
+// the list of survey questions for the class type.  A POST with a moveup
+// parameter moves the specified question one place earlier in the list.
+func HandleSurveyQuestions(r *request.Request, ctypestr string) {
+	var (
+		user  *person.Person
+		ctype class.Type
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if ctype = surveyType(r, user, ctypestr); ctype == 0 {
+		return
+	}
+	if r.Method == http.MethodPost {
+		if q := survey.QuestionWithID(r, survey.QuestionID(util.ParseID(r.FormValue("moveup")))); q != nil && q.Type == ctype {
+			r.Transaction(func() {
+				q.MoveUp(r)
+			})
+		}
+	}
+	RenderSurveyQuestions(r, user, ctype)
+}
+
+// RenderSurveyQuestions renders the list of survey questions for the class
+// type.
+func RenderSurveyQuestions(r *request.Request, user *person.Person, ctype class.Type) {
+	var questions []*survey.Question
+
+	survey.Questions(r, ctype, func(q *survey.Question) {
+		questions = append(questions, q.Clone())
+	})
+	r.HTMLNoCache()
+	ui.Page(r, user, ui.PageOpts{Title: "Class Survey Questions", MenuItem: "classes"}, func(main *htmlb.Element) {
+		main.E("div class=reglistClass>%s Survey", ctype.String())
+		main.E("div class=surveyNote>After each class of this type, the registered students are invited to answer these questions.  Changes affect all classes of this type, including past ones.")
+		if len(questions) != 0 {
+			grid := main.E("div class=surveyQuestions")
+			for i, q := range questions {
+				grid.E("div>%d.", i+1)
+				grid.E("div").E("a href=/classes/surveys/%d/%d up-layer=new up-size=grow up-dismissable=key up-history=false>%s", ctype.Int(), q.ID, q.EnText)
+				grid.E("div>%s", q.Kind.String())
+				if i != 0 {
+					form := grid.E("form method=POST up-target=main")
+					form.E("input type=hidden name=csrf value=%s", r.CSRF)
+					form.E("button type=submit name=moveup value=%d class='sbtn sbtn-xsmall sbtn-secondary' title='Move up'>↑", q.ID)
+				} else {
+					grid.E("div")
+				}
+			}
+		} else {
+			main.E("div class=surveyNote>There are no survey questions for this class type.")
+		}
+		buttons := main.E("div class=reglistButtons")
+		buttons.E("a href=/classes/surveys/%d/NEW up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-xsmall sbtn-primary'>Add Question", ctype.Int())
+	})
+}
+
+// HandleSurveyQuestion handles /classes/surveys/$ctype/$qid requests, where
+// $qid may be "NEW".
+func HandleSurveyQuestion(r *request.Request, ctypestr, qidstr string) {
+	var (
+		user      *person.Person
+		ctype     class.Type
+		q         *survey.Question
+		uq        *survey.Question
+		enChoices string
+		esChoices string
+		f         form.Form
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if ctype = surveyType(r, user, ctypestr); ctype == 0 {
+		return
+	}
+	f.Attrs = "method=POST up-target=main"
+	f.Dialog = true
+	f.Buttons = []*form.Button{{
+		Label: "Save",
+		OnClick: func() bool {
+			r.Transaction(func() {
+				if q == nil {
+					survey.CreateQuestion(r, uq)
+				} else {
+					q.Update(r, uq)
+				}
+			})
+			RenderSurveyQuestions(r, user, ctype)
+			return true
+		},
+	}}
+	if qidstr == "NEW" {
+		uq = &survey.Question{Type: ctype, Kind: survey.Rating}
+		f.Title = "New Survey Question"
+	} else {
+		if q = survey.QuestionWithID(r, survey.QuestionID(util.ParseID(qidstr))); q == nil || q.Type != ctype {
+			errpage.NotFound(r, user)
+			return
+		}
+		uq = q.Clone()
+		enChoices = strings.Join(q.EnChoices, "\n")
+		esChoices = strings.Join(q.EsChoices, "\n")
+		f.Title = "Edit Survey Question"
+		f.Buttons = append(f.Buttons, &form.Button{
+			Name: "delete", Label: "Delete", Style: "danger",
+			OnClick: func() bool {
+				r.Transaction(func() {
+					q.Delete(r)
+				})
+				RenderSurveyQuestions(r, user, ctype)
+				return true
+			},
+		})
+	}
+	f.Rows = []form.Row{
+		&form.SelectRow[survey.Kind]{
+			LabeledRow: form.LabeledRow{RowID: "surveyeditKind", Label: "Kind"},
+			Name:       "kind",
+			ValueP:     &uq.Kind,
+			Options:    survey.AllKinds,
+			Validate:   form.NoValidate,
+		},
+		&requiredTextRow{form.InputRow{
+			LabeledRow: form.LabeledRow{RowID: "surveyeditEnText", Label: "English"},
+			Name:       "enText",
+			ValueP:     &uq.EnText,
+			Validate:   form.NoValidate,
+		}, "The English text of the question is required."},
+		&requiredTextRow{form.InputRow{
+			LabeledRow: form.LabeledRow{RowID: "surveyeditEsText", Label: "Spanish"},
+			Name:       "esText",
+			ValueP:     &uq.EsText,
+			Validate:   form.NoValidate,
+		}, "The Spanish text of the question is required."},
+		&choicesRow{
+			TextAreaRow: form.TextAreaRow{
+				LabeledRow: form.LabeledRow{RowID: "surveyeditChoices", Label: "Choices", Help: "For multiple choice questions, list the choices one per line, in English on the left and Spanish on the right.  Both lists must have the same number of lines."},
+				Name:       "enChoices",
+				ValueP:     &enChoices,
+				Validate:   form.NoValidate,
+			},
+			esName:    "esChoices",
+			esChoices: &esChoices,
+			uq:        uq,
+		},
+	}
+	f.Handle(r)
+}
+
+// requiredTextRow is an input row that must not be left empty.
+type requiredTextRow struct {
+	form.InputRow
+	message string
+}
+
+func (rtr *requiredTextRow) Read(r *request.Request) bool {
+	if !rtr.InputRow.Read(r) {
+		return false
+	}
+	if *rtr.ValueP == "" {
+		rtr.Error = rtr.message
+		return false
+	}
+	return true
+}
+
+// choicesRow is the row for the English and Spanish choices of a multiple
+// choice question, side by side.
+type choicesRow struct {
+	form.TextAreaRow
+	esName    string
+	esChoices *string
+	uq        *survey.Question
+}
+
+func (cr *choicesRow) Emit(r *request.Request, parent *htmlb.Element, focus bool) {
+	row := cr.EmitPrefix(r, parent, cr.RowID+"-in")
+	box := row.E("div class='formInput surveyeditChoices'")
+	box.E("textarea name=%s id=%s-in rows=5 placeholder=English", cr.Name, cr.RowID).T(*cr.ValueP)
+	box.E("textarea name=%s rows=5 placeholder=Español", cr.esName).T(*cr.esChoices)
+	cr.EmitSuffix(r, row)
+}
+
+func (cr *choicesRow) Read(r *request.Request) bool {
+	cr.TextAreaRow.Read(r)
+	*cr.esChoices = r.FormValue(cr.esName)
+	cr.uq.EnChoices, cr.uq.EsChoices = nil, nil
+	if cr.uq.Kind != survey.Choice {
+		return true
+	}
+	cr.uq.EnChoices = splitLines(*cr.ValueP)
+	cr.uq.EsChoices = splitLines(*cr.esChoices)
+	if len(cr.uq.EnChoices) < 2 {
+		cr.Error = "A multiple choice question must have at least two choices."
+		return false
+	}
+	if len(cr.uq.EnChoices) != len(cr.uq.EsChoices) {
+		cr.Error = "The English and Spanish lists of choices must have the same number of lines."
+		return false
+	}
+	return true
+}
+
+// splitLines splits the string into its non-blank lines, with leading and
+// trailing whitespace removed.
+func splitLines(s string) (lines []string) {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,248 @@
+package classes
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/classreg"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personverify"
+	"sunnyvaleserv.org/portal/store/throttle"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+	"sunnyvaleserv.org/portal/util/sendmail"
+)
+
+// verifyPeriod returns the length of time that someone who created an account
+// during class registration has to verify their email address, before the
+// account and its registrations are deleted.  It is configured by the
+// "classVerifyDays" setting, which defaults to 3.
+func verifyPeriod() time.Duration {
+	if days, err := strconv.Atoi(config.Get("classVerifyDays")); err == nil && days > 0 {
+		return time.Duration(days) * 24 * time.Hour
+	}
+	return 3 * 24 * time.Hour
+}
+
+// startVerification marks the newly created account of the user as pending
+// verification, and sends them an email with the verification link.
+func startVerification(r *request.Request, user *person.Person) {
+	var (
+		body    bytes.Buffer
+		token   = util.RandomToken()
+		expires = time.Now().Add(verifyPeriod())
+	)
+	r.Transaction(func() {
+		personverify.Create(r, user, token, expires)
+	})
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\n", config.Get("fromEmail"), (&mail.Address{Name: user.InformalName(), Address: user.Email()}).String())
+	fmt.Fprintf(&body, "Subject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n", r.Loc("Please confirm your email address"))
+	fmt.Fprintf(&body, r.Loc("Greetings, %s,"), user.InformalName())
+	fmt.Fprint(&body, "\r\n\r\n")
+	fmt.Fprint(&body, r.Loc("Thank you for creating an account on SunnyvaleSERV.org.  To confirm that this is your email address, please visit:"))
+	fmt.Fprintf(&body, "\r\n    %s/classes/verify/%s\r\n\r\n", config.Get("siteURL"), token)
+	fmt.Fprintf(&body, r.Loc("Until you do, any class registrations you make are pending.  If you do not confirm your email address by %s, your account and its class registrations will be removed."), localizeHold(expires, r.Language))
+	fmt.Fprint(&body, "\r\n\r\n")
+	fmt.Fprint(&body, r.Loc("If you did not create this account, you can ignore this email."))
+	fmt.Fprint(&body, "\r\n\r\nSunnyvale SERV\r\nserv@sunnyvale.ca.gov\r\n")
+	if err := sendmail.SendMessage(r.Context(), config.Get("fromAddr"), []string{user.Email()}, body.Bytes()); err != nil {
+		r.LogEntry.Problems.AddError(err)
+	}
+}
+
+// expireUnverified removes the accounts, and their class registrations, of
+// people who did not verify their email addresses in time, and offers any
+// seats they held to the waiting lists.
+func expireUnverified(r *request.Request) {
+	var classes []class.ID
+
+	r.Transaction(func() {
+		classes = personverify.Expire(r, time.Now())
+	})
+	for _, cid := range classes {
+		PromoteWaitlist(r, cid)
+	}
+}
+
+// HandleVerify handles /classes/verify/$token requests.  People who created
+// accounts during class registration use it to verify their email addresses.
+// It does not require a login; the token is the authorization.
+func HandleVerify(r *request.Request, token string) {
+	var (
+		pid     person.ID
+		expires time.Time
+		user    *person.Person
+		names   []string
+	)
+	if pid, expires = personverify.WithToken(r, token); pid == 0 || expires.Before(time.Now()) {
+		errpage.NotFound(r, nil)
+		return
+	}
+	if user = person.WithID(r, pid, registerPersonFields); user == nil {
+		errpage.NotFound(r, nil)
+		return
+	}
+	r.Transaction(func() {
+		personverify.Verify(r, user)
+	})
+	// Now that we know the registrations are genuine, notify the other
+	// students who were registered.
+	var adds = make(map[class.ID][]*classreg.Updater)
+	var cids []class.ID
+	classreg.AllForPerson(r, user.ID(), classreg.FClass|classreg.FRegisteredBy|classreg.FFirstName|classreg.FLastName|classreg.FEmail|classreg.FWaitlist, func(cr *classreg.ClassReg) {
+		if cr.RegisteredBy() != user.ID() {
+			return
+		}
+		if _, ok := adds[cr.Class()]; !ok {
+			cids = append(cids, cr.Class())
+		}
+		adds[cr.Class()] = append(adds[cr.Class()], &classreg.Updater{FirstName: cr.FirstName(), LastName: cr.LastName(), Email: cr.Email(), Waitlist: cr.Waitlist()})
+	})
+	for _, cid := range cids {
+		if c := class.WithID(r, cid, waitlistClassFields); c != nil && c.Start() >= time.Now().Format("2006-01-02") {
+			sendAddConfirmations(r, user, c, adds[cid])
+			names = append(names, r.Loc(c.Type().String())+" "+c.Start())
+		}
+	}
+	ui.Page(r, nil, ui.PageOpts{Title: r.Loc("Class Registration"), MenuItem: "classes"}, func(main *htmlb.Element) {
+		text := main.E("div class=waitlistConfirm")
+		text.E("p").TF(r.Loc("Thank you, %s!  Your email address is confirmed."), user.InformalName())
+		if len(names) != 0 {
+			text.E("p").R(r.Loc("Your registrations for these classes are now final:"))
+			list := text.E("ul")
+			for _, name := range names {
+				list.E("li").T(name)
+			}
+			text.E("p").R(r.Loc("We look forward to seeing you!"))
+		}
+	})
+}
+
+// Registrations and account creations are rate limited per client IP address
+// and per email address.
+const (
+	throttleWindow     = time.Hour
+	throttleIPLimit    = 20
+	throttleEmailLimit = 10
+)
+
+// registrationThrottled returns whether there have been too many recent
+// registration attempts from the client's IP address or with the specified
+// email address.
+func registrationThrottled(r *request.Request, email string) bool {
+	since := time.Now().Add(-throttleWindow)
+	if throttle.Count(r, "classreg-ip:"+r.ClientIP(), since) >= throttleIPLimit {
+		return true
+	}
+	return email != "" && throttle.Count(r, "classreg-email:"+strings.ToLower(email), since) >= throttleEmailLimit
+}
+
+// recordRegistration records a registration attempt from the client's IP
+// address with the specified email address.
+func recordRegistration(r *request.Request, email string) {
+	now := time.Now()
+	r.Transaction(func() {
+		throttle.Record(r, "classreg-ip:"+r.ClientIP(), now)
+		if email != "" {
+			throttle.Record(r, "classreg-email:"+strings.ToLower(email), now)
+		}
+	})
+}
+
+// The account creation form carries a proof-of-work challenge:  the browser
+// must find a nonce such that the SHA-256 hash of the challenge followed by the
+// nonce starts with challengeBits zero bits.  The browser does that while the
+// person is filling in the form, so they don't notice it, but it makes
+// creating accounts in bulk expensive for a robot.  The challenge is signed
+// and timestamped so that it can't be forged, and one that comes back faster
+// than a person could fill in the form is refused.
+const (
+	challengeBits   = 16
+	challengeMinAge = 3 * time.Second
+	challengeMaxAge = time.Hour
+)
+
+// randomChallengeKey is the key used to sign challenges when no key is
+// configured.
+var randomChallengeKey []byte
+
+// challengeKey returns the key used to sign challenges.  It is configured by
+// the "challengeKey" setting; if that is not set, a random key is generated
+// for the life of the process.
+func challengeKey() []byte {
+	if key := config.Get("challengeKey"); key != "" {
+		return []byte(key)
+	}
+	if randomChallengeKey == nil {
+		randomChallengeKey = make([]byte, 32)
+		rand.Read(randomChallengeKey)
+	}
+	return randomChallengeKey
+}
+
+// newChallenge returns a new proof-of-work challenge.
+func newChallenge() string {
+	payload := strconv.FormatInt(time.Now().Unix(), 10) + "." + util.RandomToken()
+	mac := hmac.New(sha256.New, challengeKey())
+	mac.Write([]byte(payload))
+	return payload + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkChallenge returns whether the nonce is a valid solution to the
+// challenge, and the challenge is valid, of a reasonable age, and has not been
+// used before.
+func checkChallenge(r *request.Request, challenge, nonce string) bool {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 3 || nonce == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, challengeKey())
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if sig, err := hex.DecodeString(parts[2]); err != nil || !hmac.Equal(sig, mac.Sum(nil)) {
+		return false
+	}
+	issued, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(issued, 0)); age < challengeMinAge || age > challengeMaxAge {
+		return false
+	}
+	if leadingZeroBits(sha256.Sum256([]byte(challenge+nonce))) < challengeBits {
+		return false
+	}
+	return throttle.Count(r, "challenge:"+parts[1], time.Unix(issued, 0)) == 0
+}
+
+// useChallenge records that the challenge has been used, so that it cannot be
+// used again.
+func useChallenge(r *request.Request, challenge string) {
+	if parts := strings.Split(challenge, "."); len(parts) == 3 {
+		throttle.Record(r, "challenge:"+parts[1], time.Now())
+	}
+}
+
+// leadingZeroBits returns the number of leading zero bits in the hash.
+func leadingZeroBits(hash [sha256.Size]byte) (count int) {
+	for _, b := range hash {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
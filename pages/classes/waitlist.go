@@ -0,0 +1,201 @@
+package classes
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/l10n"
+	"sunnyvaleserv.org/portal/store"
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/classreg"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personverify"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+	"sunnyvaleserv.org/portal/util/sendmail"
+)
+
+const waitlistClassFields = classreg.PromoteClassFields | class.FEnDesc | class.FEsDesc | class.FRegURL
+
+// holdPeriod returns the length of time for which a seat is held for a student
+// promoted from the waiting list, pending their confirmation.  It is
+// configured by the "classHoldHours" setting, which defaults to 48.  A setting
+// of zero means promoted students are registered without needing to confirm.
+func holdPeriod() time.Duration {
+	if hours, err := strconv.Atoi(config.Get("classHoldHours")); err == nil && hours >= 0 {
+		return time.Duration(hours) * time.Hour
+	}
+	return 48 * time.Hour
+}
+
+// PromoteWaitlist cancels any expired holds on seats in the specified class,
+// and then promotes students from the waiting list into any open seats,
+// notifying them by email.  It should be called whenever seats may have opened
+// up in the class.
+func PromoteWaitlist(r *request.Request, cid class.ID) {
+	promoteWaitlist(r.Context(), r.Store, cid)
+}
+
+// PromoteExpiredHolds calls PromoteWaitlist for every class with expired
+// holds, so that the seats are offered to the next students on the waiting
+// list even when no one visits the class's pages.  It is called periodically
+// by the promote-waitlists command.
+func PromoteExpiredHolds(ctx context.Context, st *store.Store) {
+	for _, cid := range classreg.ClassesWithExpiredHolds(st, time.Now()) {
+		promoteWaitlist(ctx, st, cid)
+	}
+}
+
+func promoteWaitlist(ctx context.Context, st *store.Store, cid class.ID) {
+	var (
+		c        *class.Class
+		expired  []*classreg.ClassReg
+		promoted []*classreg.ClassReg
+	)
+	st.Transaction(func() {
+		if c = class.WithID(st, cid, waitlistClassFields); c == nil || c.RegURL() != "" {
+			return
+		}
+		expired, promoted = classreg.Promote(st, c, holdPeriod(), time.Now())
+	})
+	for _, cr := range expired {
+		sendWaitlistEmail(ctx, st, c, cr, func(body *bytes.Buffer, lang string) {
+			fmt.Fprintf(body, l10n.Localize("The place we were holding for you in our “%s” class was not confirmed in time, so it has been offered to the next person on the waiting list.", lang), l10n.Localize(c.Type().String(), lang))
+			fmt.Fprint(body, "\r\n\r\n")
+			fmt.Fprint(body, l10n.Localize("We hope to be able to accommodate you at some future class.", lang))
+		})
+	}
+	for _, cr := range promoted {
+		sendWaitlistEmail(ctx, st, c, cr, func(body *bytes.Buffer, lang string) {
+			var desc string
+			fmt.Fprintf(body, l10n.Localize("A place has opened up in our “%s” class, and you have been moved from the waiting list into the class:", lang), l10n.Localize(c.Type().String(), lang))
+			if lang == "es" {
+				desc = c.EsDesc()
+			} else {
+				desc = c.EnDesc()
+			}
+			for _, line := range strings.Split(desc, "\n") {
+				fmt.Fprint(body, "\r\n    ", line)
+			}
+			fmt.Fprint(body, "\r\n\r\n")
+			if cr.HoldToken() != "" {
+				fmt.Fprintf(body, l10n.Localize("We are holding this place for you until %s.  To keep it, please confirm at:", lang), localizeHold(cr.HoldUntil(), lang))
+				fmt.Fprintf(body, "\r\n    %s/classes/confirm/%s\r\n\r\n", config.Get("siteURL"), cr.HoldToken())
+				fmt.Fprint(body, l10n.Localize("If you do not confirm by then, your place will be offered to the next person on the waiting list.", lang))
+			} else {
+				fmt.Fprint(body, l10n.Localize("If you need to withdraw from the class, please reply to this email and let us know.", lang))
+				fmt.Fprint(body, "\r\n\r\n")
+				fmt.Fprint(body, l10n.Localize("We look forward to seeing you!", lang))
+			}
+		})
+	}
+}
+
+// sendWaitlistEmail sends an email about a change in the waiting list status
+// of a registration to the student and, if someone else registered them, to
+// that person.  The email has both English and Spanish text, each generated
+// by calling fn.
+func sendWaitlistEmail(ctx context.Context, st *store.Store, c *class.Class, cr *classreg.ClassReg, fn func(body *bytes.Buffer, lang string)) {
+	var (
+		toaddrs []string
+		recips  []string
+		body    bytes.Buffer
+		name    = fmt.Sprintf("%s %s", cr.FirstName(), cr.LastName())
+	)
+	// If the registration was made by someone whose email address is not
+	// yet verified, we don't send email to anyone else on their behalf.
+	if cr.Email() != "" && (cr.RegisteredBy() == cr.Person() || !personverify.Pending(st, cr.RegisteredBy())) {
+		recips = append(recips, cr.Email())
+		toaddrs = append(toaddrs, (&mail.Address{Name: name, Address: cr.Email()}).String())
+	}
+	if cr.RegisteredBy() != cr.Person() {
+		if rb := person.WithID(st, cr.RegisteredBy(), person.FInformalName|person.FEmail); rb != nil && rb.Email() != "" && !strings.EqualFold(rb.Email(), cr.Email()) {
+			recips = append(recips, rb.Email())
+			toaddrs = append(toaddrs, (&mail.Address{Name: rb.InformalName(), Address: rb.Email()}).String())
+		}
+	}
+	if len(recips) == 0 {
+		return
+	}
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\n", config.Get("fromEmail"), strings.Join(toaddrs, ", "))
+	fmt.Fprintf(&body, "Subject: %s: %s / %s: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n",
+		c.Type().String(), "Class Registration", l10n.Localize(c.Type().String(), "es"), l10n.Localize("Class Registration", "es"))
+	for i, lang := range []string{"en", "es"} {
+		if i != 0 {
+			fmt.Fprint(&body, "\r\n\r\n----------------------------------------\r\n\r\n")
+		}
+		fmt.Fprintf(&body, l10n.Localize("Greetings, %s,", lang), name)
+		fmt.Fprint(&body, "\r\n\r\n")
+		fn(&body, lang)
+		fmt.Fprint(&body, "\r\nSunnyvale SERV\r\nserv@sunnyvale.ca.gov")
+	}
+	fmt.Fprint(&body, "\r\n")
+	if err := sendmail.SendMessage(ctx, config.Get("fromAddr"), recips, body.Bytes()); err != nil {
+		st.Problems().AddError(err)
+	}
+}
+
+// localizeHold returns the localized date and time at which a hold expires.
+func localizeHold(t time.Time, lang string) string {
+	return l10n.LocalizeDate(t, lang) + " " + t.Format("15:04")
+}
+
+// HandleConfirm handles /classes/confirm/$token requests.  Students promoted
+// from the waiting list use it to confirm that they still want their place in
+// the class.  It does not require a login; the token is the authorization.
+func HandleConfirm(r *request.Request, token string) {
+	var (
+		cr        *classreg.ClassReg
+		c         *class.Class
+		confirmed bool
+		declined  bool
+	)
+	if cr = classreg.WithHoldToken(r, token, classreg.UpdaterFields); cr != nil {
+		c = class.WithID(r, cr.Class(), waitlistClassFields)
+	}
+	if cr == nil || c == nil || cr.HoldUntil().Before(time.Now()) {
+		errpage.NotFound(r, nil)
+		return
+	}
+	if r.Method == http.MethodPost {
+		r.Transaction(func() {
+			if r.FormValue("decline") != "" {
+				cr.Delete(r, c)
+				declined = true
+			} else {
+				cr.Confirm(r, c)
+				confirmed = true
+			}
+		})
+		if declined {
+			PromoteWaitlist(r, c.ID())
+		}
+	}
+	ui.Page(r, nil, ui.PageOpts{Title: r.Loc("Class Registration"), MenuItem: "classes"}, func(main *htmlb.Element) {
+		main.E("div class=reglistClass").T(r.Loc(c.Type().String()))
+		main.E("div class=reglistStart").T(c.Start())
+		text := main.E("div class=waitlistConfirm")
+		switch {
+		case confirmed:
+			text.E("p").TF(r.Loc("Thank you!  The place for %s %s in this class is confirmed."), cr.FirstName(), cr.LastName())
+			text.E("p").R(r.Loc("We look forward to seeing you!"))
+		case declined:
+			text.E("p").TF(r.Loc("Thank you!  The registration of %s %s in this class is canceled."), cr.FirstName(), cr.LastName())
+			text.E("p").R(r.Loc("We hope to be able to accommodate you at some future class."))
+		default:
+			text.E("p").TF(r.Loc("A place in this class is being held for %s %s until %s.  Please confirm whether you still want it."), cr.FirstName(), cr.LastName(), localizeHold(cr.HoldUntil(), r.Language))
+			form := text.E("form method=POST class=waitlistConfirmButtons")
+			form.E("input type=submit name=confirm class='sbtn sbtn-primary' value=%s", r.Loc("Confirm"))
+			form.E("input type=submit name=decline class='sbtn sbtn-danger' value=%s", r.Loc("Cancel Registration"))
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package emsheet
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/shift"
+	"sunnyvaleserv.org/portal/store/shiftperson"
+	"sunnyvaleserv.org/portal/store/task"
+	"sunnyvaleserv.org/portal/store/taskperson"
+	"sunnyvaleserv.org/portal/store/venue"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const personFields = person.FID | person.FInformalName | person.FSortName | person.FCellPhone | person.FEmContacts | person.FMedicalNotes
+
+// group is a set of people shown together on the sheet:  either the people
+// signed up for one shift, or the people recorded as attending a task without
+// shifts.
+type group struct {
+	label  string
+	now    bool
+	people []*person.Person
+}
+
+// Handle handles /events/emergency/$tid requests.
+func Handle(r *request.Request, tidstr string) {
+	const taskFields = task.FID | task.FEvent | task.FName | task.FOrg
+	var (
+		user    *person.Person
+		t       *task.Task
+		e       *event.Event
+		groups  []*group
+		people  []*person.Person
+		seen    = make(map[person.ID]*person.Person)
+		anyNow  bool
+		showAll bool
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if t = task.WithID(r, task.ID(util.ParseID(tidstr)), taskFields); t == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if !auth.Can(user, enum.PermEventEdit, t.Org()) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	e = event.WithID(r, t.Event(), taskperson.SetEventFields)
+	// Find the people deployed on the task.  If it has shifts, they are
+	// grouped by shift; otherwise they are the people recorded as having
+	// attended.
+	now := time.Now().Format("2006-01-02T15:04")
+	shift.AllForTask(r, t.ID(), shift.FID|shift.FStart|shift.FEnd, venue.FName, func(s *shift.Shift, v *venue.Venue) {
+		var g group
+
+		g.label = s.Start()[:10] + " " + s.Start()[11:]
+		if s.End() != s.Start() {
+			g.label += "–" + s.End()[11:]
+		}
+		if v != nil {
+			g.label += " " + v.Name()
+		}
+		g.now = s.Start() <= now && now < s.End()
+		anyNow = anyNow || g.now
+		shiftperson.PeopleForShift(r, s.ID(), person.FID, func(p *person.Person) {
+			g.people = append(g.people, lookupPerson(r, seen, p.ID()))
+		})
+		groups = append(groups, &g)
+	})
+	if len(groups) == 0 {
+		var g = group{label: "Attended", now: true}
+		taskperson.PeopleForTask(r, t.ID(), person.FID, func(p *person.Person, _ uint, flags taskperson.Flag) {
+			if flags&taskperson.Attended != 0 {
+				g.people = append(g.people, lookupPerson(r, seen, p.ID()))
+			}
+		})
+		groups = append(groups, &g)
+		anyNow = true
+	}
+	// Unless asked for everyone, show only the shifts in progress, if
+	// there are any.
+	showAll = r.FormValue("show") == "all" || !anyNow
+	for _, g := range groups {
+		if !showAll && !g.now {
+			continue
+		}
+		sort.Slice(g.people, func(i, j int) bool { return g.people[i].SortName() < g.people[j].SortName() })
+		for _, p := range g.people {
+			if !slices.Contains(people, p) {
+				people = append(people, p)
+			}
+		}
+	}
+	// Every view of the sheet is recorded in the audit log.
+	r.Transaction(func() {
+		taskperson.AuditEmergencySheet(r, e, t, people)
+	})
+	r.HTMLNoCache()
+	ui.Page(r, user, ui.PageOpts{
+		Title:    "Emergency Sheet",
+		Banner:   fmt.Sprintf("%s %s: %s", e.Start()[:10], e.Name(), t.Name()),
+		MenuItem: "events",
+		Tabs: []ui.PageTab{
+			{Name: "Event", URL: fmt.Sprintf("/events/%d", e.ID()), Target: ".pageCanvas"},
+			{Name: "Emergency Sheet", URL: fmt.Sprintf("/events/emergency/%d", t.ID()), Alias: fmt.Sprintf("/events/emergency/%d?*", t.ID()), Target: "main", Active: true},
+		},
+	}, func(main *htmlb.Element) {
+		main.E("div class=emsheetWarning>CONFIDENTIAL.  This sheet is for use in a medical or safety emergency only.  Your access to it has been logged.  Do not share it, and destroy any printed copies when the deployment is over.")
+		buttons := main.E("div class=emsheetButtons")
+		if anyNow && len(groups) > 1 {
+			if showAll {
+				buttons.E("a href=/events/emergency/%d up-target=main class='sbtn sbtn-small sbtn-secondary'>On Shift Now", t.ID())
+			} else {
+				buttons.E("a href=/events/emergency/%d?show=all up-target=main class='sbtn sbtn-small sbtn-secondary'>All Shifts", t.ID())
+			}
+		}
+		buttons.E("button type=button id=emsheetPrint class='sbtn sbtn-small sbtn-primary'>Print")
+		for _, g := range groups {
+			if showAll || g.now {
+				showGroup(main, g)
+			}
+		}
+		main.E("div class=emsheetFooter>Printed %s by %s", time.Now().Format("2006-01-02 15:04"), user.InformalName())
+	})
+}
+
+// lookupPerson fetches the emergency sheet data for the specified person,
+// caching it so that people signed up for multiple shifts are fetched only
+// once.
+func lookupPerson(r *request.Request, seen map[person.ID]*person.Person, pid person.ID) *person.Person {
+	if p := seen[pid]; p != nil {
+		return p
+	}
+	p := person.WithID(r, pid, personFields)
+	seen[pid] = p
+	return p
+}
+
+func showGroup(main *htmlb.Element, g *group) {
+	section := main.E("div class=emsheetGroup")
+	section.E("div class=emsheetGroupHeading").T(g.label)
+	if len(g.people) == 0 {
+		section.E("div class=emsheetEmpty>No one is signed up.")
+		return
+	}
+	grid := section.E("div class=emsheetGrid")
+	grid.E("div class=emsheetHeading>Volunteer")
+	grid.E("div class=emsheetHeading>Emergency Contacts")
+	grid.E("div class=emsheetHeading>Medical Notes")
+	for _, p := range g.people {
+		pdiv := grid.E("div class=emsheetPerson")
+		pdiv.E("div class=emsheetName").T(p.SortName())
+		if p.CellPhone() != "" {
+			pdiv.E("div").E("a href=tel:%s>%s", p.CellPhone(), p.CellPhone())
+		}
+		ecdiv := grid.E("div class=emsheetContacts")
+		if len(p.EmContacts()) == 0 {
+			ecdiv.E("div class=emsheetNone>None on file")
+		}
+		for _, ec := range p.EmContacts() {
+			cdiv := ecdiv.E("div class=emsheetContact")
+			cdiv.E("div").T(ec.Name).E("span class=emsheetRelationship>(%s)", ec.Relationship)
+			if ec.CellPhone != "" {
+				cdiv.E("div").E("a href=tel:%s>%s", ec.CellPhone, ec.CellPhone).P().R(" (cell)")
+			}
+			if ec.HomePhone != "" {
+				cdiv.E("div").E("a href=tel:%s>%s", ec.HomePhone, ec.HomePhone).P().R(" (home)")
+			}
+		}
+		grid.E("div class=emsheetNotes").T(p.MedicalNotes())
+	}
+}
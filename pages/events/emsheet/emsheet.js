@@ -0,0 +1,3 @@
+up.on('click', '#emsheetPrint', () => {
+  window.print()
+})
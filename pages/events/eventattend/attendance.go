@@ -95,7 +95,7 @@ func Handle(r *request.Request, tidstr string) {
 		return
 	}
 	e = event.WithID(r, t.Event(), eventFields)
-	if !user.HasPrivLevel(t.Org(), enum.PrivLeader) || e.Flags()&event.OtherHours != 0 {
+	if !auth.Can(user, enum.PermEventAttendance, t.Org()) || e.Flags()&event.OtherHours != 0 {
 		errpage.Forbidden(r, user)
 		return
 	}
@@ -112,6 +112,7 @@ func getAttendance(r *request.Request, user *person.Person, e *event.Event, t *t
 		name    string
 		minutes uint
 		flags   taskperson.Flag
+		photo   bool
 	}
 	var (
 		hasShifts       bool
@@ -125,16 +126,16 @@ func getAttendance(r *request.Request, user *person.Person, e *event.Event, t *t
 	// Show all people who are signed up for the task's shifts if any.
 	shift.AllForTask(r, t.ID(), shift.FID, 0, func(s *shift.Shift, _ *venue.Venue) {
 		hasShifts = true
-		shiftperson.PeopleForShift(r, s.ID(), person.FID|person.FSortName, func(p *person.Person) {
-			people[p.ID()] = &adata{id: p.ID(), name: p.SortName()}
+		shiftperson.PeopleForShift(r, s.ID(), person.FID|person.FSortName|person.FFlags, func(p *person.Person) {
+			people[p.ID()] = &adata{id: p.ID(), name: p.SortName(), photo: p.Flags()&person.HasPhoto != 0}
 		})
 	})
 	// If we didn't find any shifts, then show all people who have eligible
 	// roles, but not if that's more than 100 people.
 	if !hasShifts {
 		taskrole.Get(r, t.ID(), role.FID, func(rl *role.Role) {
-			personrole.PeopleForRole(r, rl.ID(), person.FID|person.FSortName, func(p *person.Person, _ bool) {
-				people[p.ID()] = &adata{id: p.ID(), name: p.SortName()}
+			personrole.PeopleForRole(r, rl.ID(), person.FID|person.FSortName|person.FFlags, func(p *person.Person, _ bool) {
+				people[p.ID()] = &adata{id: p.ID(), name: p.SortName(), photo: p.Flags()&person.HasPhoto != 0}
 			})
 		})
 		if len(people) > 100 {
@@ -143,8 +144,8 @@ func getAttendance(r *request.Request, user *person.Person, e *event.Event, t *t
 	}
 	// In either case, always show the people who already have attendance
 	// recorded.
-	taskperson.PeopleForTask(r, t.ID(), person.FID|person.FSortName, func(p *person.Person, minutes uint, flags taskperson.Flag) {
-		people[p.ID()] = &adata{p.ID(), p.SortName(), minutes, flags}
+	taskperson.PeopleForTask(r, t.ID(), person.FID|person.FSortName|person.FFlags, func(p *person.Person, minutes uint, flags taskperson.Flag) {
+		people[p.ID()] = &adata{p.ID(), p.SortName(), minutes, flags, p.Flags()&person.HasPhoto != 0}
 		if flags&taskperson.Credited != 0 {
 			defaultCredited = true
 		}
@@ -195,7 +196,11 @@ func getAttendance(r *request.Request, user *person.Person, e *event.Event, t *t
 		box = row.E("div class=attendanceCredited", p.flags&taskperson.Credited != 0, "class=true")
 		box.E("s-icon", p.flags&taskperson.Credited != 0, "icon=star-solid", p.flags&taskperson.Credited == 0, "icon=star")
 		box.E("input type=hidden name=credited%d value=%v", p.id, p.flags&taskperson.Credited != 0)
-		row.E("div class=attendanceName data-key=P%d>%s", p.id, p.name)
+		name := row.E("div class=attendanceName data-key=P%d", p.id)
+		if p.photo {
+			name.E("img src=/people/%d/photo class=attendancePhoto loading=lazy alt=''", p.id)
+		}
+		name.T(p.name)
 	}
 	// Show the last row with a person search box.
 	grid.E("div class=attendanceNew").
@@ -94,14 +94,14 @@ func Handle(r *request.Request, idstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
-	if !user.HasPrivLevel(0, enum.PrivLeader) || cd.e.Flags()&event.OtherHours != 0 {
+	if !auth.Can(user, enum.PermEventEdit, 0) || cd.e.Flags()&event.OtherHours != 0 {
 		errpage.Forbidden(r, user)
 		return
 	}
 	allowed = true
 	task.AllForEvent(r, cd.e.ID(), taskFields, func(t *task.Task) {
 		cd.ts = append(cd.ts, t.Clone())
-		if !user.HasPrivLevel(t.Org(), enum.PrivLeader) {
+		if !auth.Can(user, enum.PermEventEdit, t.Org()) {
 			allowed = false
 		}
 	})
@@ -43,7 +43,7 @@ func HandleCreate(r *request.Request) {
 	if !auth.CheckCSRF(r, user) {
 		return
 	}
-	if !user.HasPrivLevel(0, enum.PrivLeader) {
+	if !auth.Can(user, enum.PermEventEdit, 0) {
 		errpage.Forbidden(r, user)
 		return
 	}
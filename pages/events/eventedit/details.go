@@ -47,9 +47,9 @@ func HandleDetails(r *request.Request, idstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
-	if allowed = user.HasPrivLevel(0, enum.PrivLeader); allowed {
+	if allowed = auth.Can(user, enum.PermEventEdit, 0); allowed {
 		task.AllForEvent(r, e.ID(), task.FOrg, func(t *task.Task) {
-			if !user.HasPrivLevel(t.Org(), enum.PrivLeader) {
+			if !auth.Can(user, enum.PermEventEdit, t.Org()) {
 				allowed = false
 			}
 		})
@@ -123,7 +123,7 @@ func getShiftEditor(r *request.Request, sidstr string) (se *shiftEditor) {
 		errpage.NotFound(r, se.user)
 		return nil
 	}
-	if !se.user.HasPrivLevel(se.t.Org(), enum.PrivLeader) || se.e.Flags()&event.OtherHours != 0 {
+	if !auth.Can(se.user, enum.PermEventEdit, se.t.Org()) || se.e.Flags()&event.OtherHours != 0 {
 		errpage.Forbidden(r, se.user)
 		return nil
 	}
@@ -114,7 +114,7 @@ func getTaskEditor(r *request.Request, tidstr string) (te *taskEditor) {
 	}
 	// Get and check the event and task.
 	if tidstr == "NEW" {
-		if !te.user.HasPrivLevel(0, enum.PrivLeader) {
+		if !auth.Can(te.user, enum.PermEventEdit, 0) {
 			errpage.Forbidden(r, te.user)
 			return nil
 		}
@@ -124,7 +124,7 @@ func getTaskEditor(r *request.Request, tidstr string) (te *taskEditor) {
 			errpage.NotFound(r, te.user)
 			return nil
 		}
-		if !te.user.HasPrivLevel(te.t.Org(), enum.PrivLeader) {
+		if !auth.Can(te.user, enum.PermEventEdit, te.t.Org()) {
 			errpage.Forbidden(r, te.user)
 			return nil
 		}
@@ -220,7 +220,7 @@ func readOrg(r *request.Request, user *person.Person, ut *task.Updater) string {
 	var allowed []enum.Org
 
 	for _, org := range enum.AllOrgs {
-		if user.HasPrivLevel(org, enum.PrivLeader) {
+		if auth.Can(user, enum.PermEventEdit, org) {
 			allowed = append(allowed, org)
 		}
 	}
@@ -245,7 +245,7 @@ func emitOrg(form *htmlb.Element, user *person.Person, ut *task.Updater, focus b
 	var allowed []enum.Org
 
 	for _, org := range enum.AllOrgs {
-		if user.HasPrivLevel(org, enum.PrivLeader) {
+		if auth.Can(user, enum.PermEventEdit, org) {
 			allowed = append(allowed, org)
 		}
 	}
@@ -272,14 +272,14 @@ func readRoles(r *request.Request, user *person.Person, roles []*role.Role) (np
 	var ids = make(map[role.ID]*role.Role)
 	for _, idstr := range strings.Fields(r.FormValue("roles")) {
 		if rl := role.WithID(r, role.ID(util.ParseID(idstr)), role.FID|role.FName|role.FOrg); rl != nil {
-			if user.HasPrivLevel(rl.Org(), enum.PrivLeader) {
+			if auth.Can(user, enum.PermEventEdit, rl.Org()) {
 				ids[rl.ID()] = rl
 			}
 		}
 	}
 	// Preserve roles that this user can't change.
 	for _, rl := range roles {
-		if !user.HasPrivLevel(rl.Org(), enum.PrivLeader) {
+		if !auth.Can(user, enum.PermEventEdit, rl.Org()) {
 			ids[rl.ID()] = rl
 		}
 	}
@@ -292,7 +292,7 @@ func emitRoles(r *request.Request, form *htmlb.Element, user *person.Person, rol
 	row := form.E("div id=eventeditRolesRow class=formRow")
 	row.E("label for=eventeditRoles0 class=checkLabel>Roles")
 	tree := roleselect.MakeRoleTree(r, role.FID|role.FOrg, func(rl *role.Role) bool {
-		return user.HasPrivLevel(rl.Org(), enum.PrivLeader)
+		return auth.Can(user, enum.PermEventEdit, rl.Org())
 	})
 	var selids []string
 	for _, rl := range roles {
@@ -362,7 +362,7 @@ func (te *taskEditor) create(r *request.Request) {
 		if te.copyShifts == 0 {
 			return
 		}
-		if ct := task.WithID(r, te.copyShifts, task.FEvent|task.FOrg); ct == nil || ct.Event() != te.e.ID() || !te.user.HasPrivLevel(ct.Org(), enum.PrivLeader) {
+		if ct := task.WithID(r, te.copyShifts, task.FEvent|task.FOrg); ct == nil || ct.Event() != te.e.ID() || !auth.Can(te.user, enum.PermEventEdit, ct.Org()) {
 			return
 		}
 		shift.AllForTask(r, te.copyShifts, shift.FStart|shift.FEnd|shift.FMin|shift.FMax, venue.FID|venue.FName, func(s *shift.Shift, v *venue.Venue) {
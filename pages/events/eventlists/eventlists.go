@@ -31,7 +31,7 @@ func Handle(r *request.Request, eidstr string) {
 	eventID = event.ID(util.ParseID(eidstr))
 	task.AllForEvent(r, eventID, taskFields, func(t *task.Task) {
 		exists = true
-		if !user.HasPrivLevel(t.Org(), enum.PrivLeader) {
+		if !auth.Can(user, enum.PermEventEdit, t.Org()) {
 			forbidden = true
 		}
 	})
@@ -46,7 +46,7 @@ func Get(r *request.Request, month string) {
 			{Name: r.Loc("Signups"), URL: "/events/signups", Target: "main"},
 		},
 	}
-	if user.HasPrivLevel(0, enum.PrivLeader) {
+	if auth.Can(user, enum.PermEventEdit, 0) {
 		opts.Tabs = append(opts.Tabs, ui.PageTab{Name: "Add Event", URL: "/events/create", Target: "main"})
 	}
 	ui.Page(r, user, opts, func(main *htmlb.Element) {
@@ -3,7 +3,9 @@ package eventview
 import (
 	"time"
 
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/server/l10n"
+	"sunnyvaleserv.org/portal/store/class"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/event"
 	"sunnyvaleserv.org/portal/store/person"
@@ -22,9 +24,9 @@ const (
 
 func showDetails(r *request.Request, main *htmlb.Element, user *person.Person, e *event.Event, ts []*task.Task) {
 	var hasShifts bool
-	editable := user.HasPrivLevel(ts[0].Org(), enum.PrivLeader)
+	editable := auth.Can(user, enum.PermEventEdit, ts[0].Org())
 	for _, t := range ts {
-		if !user.HasPrivLevel(t.Org(), enum.PrivLeader) {
+		if !auth.Can(user, enum.PermEventEdit, t.Org()) {
 			editable = false
 		}
 		if !hasShifts && shift.ExistsForTask(r, t.ID()) {
@@ -70,8 +72,11 @@ func showDetails(r *request.Request, main *htmlb.Element, user *person.Person, e
 		bdiv.E("div class=eventviewDetailsDetails").R(e.Details())
 	}
 	if editable {
-		bdiv.E("div class=eventviewDetailsButtons").
-			E("a href=/events/eventlists/%d up-layer=new up-size=grow up-history=false class='sbtn sbtn-xsmall sbtn-primary'>Email Lists", e.ID())
+		buttons := bdiv.E("div class=eventviewDetailsButtons")
+		buttons.E("a href=/events/eventlists/%d up-layer=new up-size=grow up-history=false class='sbtn sbtn-xsmall sbtn-primary'>Email Lists", e.ID())
+		if c := class.ForSession(r, e.ID(), class.FID); c != nil {
+			buttons.E("a href=/classes/%d/sessions up-target=main class='sbtn sbtn-xsmall sbtn-primary'>Class Attendance", c.ID())
+		}
 	}
 }
 
@@ -53,13 +53,13 @@ func Render(r *request.Request, user *person.Person, e *event.Event, section str
 	const taskFields = task.FID | task.FOrg | identTaskFields | detailsTaskFields | taskTaskFields
 	var ts []*task.Task
 	canDelete := !shiftperson.EventHasSignups(r, e.ID()) && !taskperson.ExistsForEvent(r, e.ID())
-	canAddTask := user.HasPrivLevel(0, enum.PrivLeader)
-	canCopy := user.HasPrivLevel(0, enum.PrivLeader)
+	canAddTask := auth.Can(user, enum.PermEventEdit, 0)
+	canCopy := auth.Can(user, enum.PermEventEdit, 0)
 
 	task.AllForEvent(r, e.ID(), taskFields, func(t *task.Task) {
 		clone := *t
 		ts = append(ts, &clone)
-		if !user.HasPrivLevel(t.Org(), enum.PrivLeader) {
+		if !auth.Can(user, enum.PermEventEdit, t.Org()) {
 			canDelete, canCopy = false, false
 		}
 	})
@@ -76,7 +76,7 @@ func Render(r *request.Request, user *person.Person, e *event.Event, section str
 	ui.Page(r, user, opts, func(main *htmlb.Element) {
 		box := main.E("div class=eventview")
 		if section == "" || section == "details" {
-			showIdent(r, box, e, ts)
+			showIdent(r, box, user, e, ts)
 			showDetails(r, box, user, e, ts)
 		}
 		for _, t := range ts {
@@ -93,6 +93,9 @@ func Render(r *request.Request, user *person.Person, e *event.Event, section str
 			if canCopy {
 				buttons.E("a href=/events/%d/copy up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-primary'>Copy Event", e.ID())
 			}
+			if canAddTask {
+				buttons.E("a href=/events/%d/history up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-secondary'>History", e.ID())
+			}
 			if canDelete {
 				buttons.E("input name=delete type=submit class='sbtn sbtn-danger' value='Delete Event'")
 			}
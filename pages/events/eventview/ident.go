@@ -3,9 +3,12 @@ package eventview
 import (
 	"time"
 
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/server/l10n"
+	"sunnyvaleserv.org/portal/store/activation"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/store/task"
 	"sunnyvaleserv.org/portal/ui/orgdot"
 	"sunnyvaleserv.org/portal/util/htmlb"
@@ -15,13 +18,22 @@ import (
 const identEventFields = event.FStart | event.FName | event.FActivation
 const identTaskFields = task.FOrg | task.FFlags
 
-func showIdent(r *request.Request, main *htmlb.Element, e *event.Event, ts []*task.Task) {
+func showIdent(r *request.Request, main *htmlb.Element, user *person.Person, e *event.Event, ts []*task.Task) {
 	names := main.E("div class=eventviewIdent")
 	left := names.E("div class=eventviewIdentLeft")
 	line1 := left.E("div class=eventviewIdentL1")
 	line1.E("span class=eventviewIdentName>%s", e.Name())
 	if act := e.Activation(); act != "" {
-		line1.E("span class=eventviewIdentActivation>%s", e.Activation())
+		var a *activation.Activation
+		if auth.Can(user, enum.PermActivationView, 0) {
+			a = activation.WithNumber(r, act, activation.FID)
+		}
+		if a != nil {
+			line1.E("span class=eventviewIdentActivation").
+				E("a href=/reports/activations/%d up-target=.pageCanvas", a.ID()).T(act)
+		} else {
+			line1.E("span class=eventviewIdentActivation>%s", e.Activation())
+		}
 	}
 	var orgs = make([]bool, enum.NumOrgs)
 	for _, t := range ts {
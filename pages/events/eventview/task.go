@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"sunnyvaleserv.org/portal/pages/events/signups"
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/event"
 	"sunnyvaleserv.org/portal/store/person"
@@ -53,9 +54,10 @@ func showTask(r *request.Request, main *htmlb.Element, user *person.Person, e *e
 			signedUpAny = true
 		}
 	})
-	editable := user.HasPrivLevel(t.Org(), enum.PrivLeader)
+	editable := auth.Can(user, enum.PermEventEdit, t.Org())
+	attendance := auth.Can(user, enum.PermEventAttendance, t.Org())
 	// If the viewer isn't involved in any way, don't show the task.
-	if !editable && !hasrole && !signedUpAny && minutes == 0 && flags == 0 {
+	if !editable && !attendance && !hasrole && !signedUpAny && minutes == 0 && flags == 0 {
 		return
 	}
 	// Display the task header.
@@ -88,15 +90,16 @@ func showTask(r *request.Request, main *htmlb.Element, user *person.Person, e *e
 	hoursTracked := t.Flags()&task.RecordHours != 0
 	date, _ := time.ParseInLocation("2006-01-02T15:04", e.Start(), time.Local)
 	hoursCutoff := time.Date(date.Year(), date.Month()+1, 11, 0, 0, 0, 0, time.Local)
-	canRecordHours := time.Now().Before(hoursCutoff) || user.IsWebmaster()
-	if editable || attended || credited || (hasrole && len(shifts) == 0 && anyAttended) || (hasrole && anyCredited) ||
+	canRecordHours := time.Now().Before(hoursCutoff) || auth.Can(user, enum.PermHoursEditPast, 0)
+	if attendance || attended || credited || (hasrole && len(shifts) == 0 && anyAttended) || (hasrole && anyCredited) ||
 		(hoursTracked && (minutes != 0 || hasrole)) {
-		showTaskTracking(r, bdiv, t, editable, len(shifts) != 0, hasrole, attended, credited, anyAttended, anyCredited, hoursTracked, canRecordHours, minutes)
+		showTaskTracking(r, bdiv, t, attendance, len(shifts) != 0, hasrole, attended, credited, anyAttended, anyCredited, hoursTracked, canRecordHours, minutes)
 	}
 	// Display email lists button if the task is editable.
 	if editable {
-		bdiv.E("div class=eventviewTaskButtons").
-			E("a href=/events/tasklists/%d up-layer=new up-size=grow up-history=false class='sbtn sbtn-xsmall sbtn-primary'>Email Lists", t.ID())
+		buttons := bdiv.E("div class=eventviewTaskButtons")
+		buttons.E("a href=/events/tasklists/%d up-layer=new up-size=grow up-history=false class='sbtn sbtn-xsmall sbtn-primary'>Email Lists", t.ID())
+		buttons.E("a href=/events/emergency/%d up-target=.pageCanvas class='sbtn sbtn-xsmall sbtn-danger'>Emergency Sheet", t.ID())
 	}
 }
 
@@ -150,9 +153,9 @@ func handleSignup(r *request.Request, user *person.Person) {
 }
 
 // showTaskTracking displays the tracking information for the Task.
-func showTaskTracking(r *request.Request, body *htmlb.Element, t *task.Task, editable, hasshifts, hasrole, attended, credited, anyAttended, anyCredited, hoursTracked, canRecordHours bool, minutes uint) {
+func showTaskTracking(r *request.Request, body *htmlb.Element, t *task.Task, attendance, hasshifts, hasrole, attended, credited, anyAttended, anyCredited, hoursTracked, canRecordHours bool, minutes uint) {
 	heading := body.E("div class=eventviewTaskHeading").R(r.Loc("Attendance"))
-	if editable {
+	if attendance {
 		heading.E("a href=/events/attendance/%d up-layer=new up-size=grow up-dismissable=false up-history=false class='sbtn sbtn-xsmall sbtn-primary'>Record Attendance", t.ID())
 	}
 	box := body.E("form class=eventviewTaskAttendance method=POST up-target=.eventviewTaskAttendance")
@@ -199,7 +202,7 @@ func handleHours(r *request.Request, user *person.Person, e *event.Event) {
 	const taskFields = task.FID | task.FFlags | taskperson.SetTaskFields
 	date, _ := time.ParseInLocation("2006-01-02T15:04", e.Start(), time.Local)
 	hoursCutoff := time.Date(date.Year(), date.Month()+1, 11, 0, 0, 0, 0, time.Local)
-	if !time.Now().Before(hoursCutoff) && !user.IsWebmaster() {
+	if !time.Now().Before(hoursCutoff) && !auth.Can(user, enum.PermHoursEditPast, 0) {
 		return // past the hours recording period for this event
 	}
 	task.AllForEvent(r, e.ID(), taskFields, func(t *task.Task) {
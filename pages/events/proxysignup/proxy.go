@@ -38,7 +38,7 @@ func Handle(r *request.Request, tidstr string) {
 		return
 	}
 	e = event.WithID(r, t.Event(), eventFields)
-	if !user.HasPrivLevel(t.Org(), enum.PrivLeader) || e.Flags()&event.OtherHours != 0 {
+	if !auth.Can(user, enum.PermEventEdit, t.Org()) || e.Flags()&event.OtherHours != 0 {
 		errpage.Forbidden(r, user)
 		return
 	}
@@ -3,6 +3,7 @@ package signups
 import (
 	"sort"
 
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/event"
 	"sunnyvaleserv.org/portal/store/person"
@@ -10,6 +11,7 @@ import (
 	"sunnyvaleserv.org/portal/store/shiftperson"
 	"sunnyvaleserv.org/portal/store/task"
 	"sunnyvaleserv.org/portal/store/venue"
+	"sunnyvaleserv.org/portal/ui"
 	"sunnyvaleserv.org/portal/util"
 	"sunnyvaleserv.org/portal/util/htmlb"
 	"sunnyvaleserv.org/portal/util/request"
@@ -23,7 +25,8 @@ const ShowTaskSignupsPersonFields = person.FID | shiftperson.EligibilityCheckerP
 // details.)  The privileged flag indicates whether the caller (which might not
 // be the same person) has leader privileges on the task organization.  The
 // editable flag indicates that an Edit button should be placed next to each
-// shift, and remove links next to each listed person.
+// shift, and remove links next to each listed person.  Privileged callers also
+// see the photos of the listed people.
 //
 // In order for this to work, and for HandleShiftSignup to work, the supplied
 // form element must have hidden input elements in it named 'shift', 'signedup',
@@ -55,7 +58,7 @@ func ShowTaskSignups(r *request.Request, form *htmlb.Element, t *task.Task, p *p
 		}
 		signedup := shiftperson.Get(r, s.ID(), p.ID()) > 0
 		var people []*person.Person
-		shiftperson.PeopleForShift(r, s.ID(), person.FID|person.FSortName, func(p *person.Person) {
+		shiftperson.PeopleForShift(r, s.ID(), person.FID|person.FSortName|person.FFlags, func(p *person.Person) {
 			pclone := *p
 			people = append(people, &pclone)
 		})
@@ -98,7 +101,11 @@ func ShowTaskSignups(r *request.Request, form *htmlb.Element, t *task.Task, p *p
 		if len(people) != 0 {
 			list := tdiv.E("div class=signupShiftList hidden")
 			for _, p := range people {
-				pdiv := list.E("div>%s", p.SortName())
+				pdiv := list.E("div")
+				if privileged {
+					ui.PersonPhoto(pdiv, p, "signupShiftPhoto")
+				}
+				pdiv.T(p.SortName())
 				if privileged && editable {
 					pdiv.E("a class=signupShiftRemove data-shift=%d data-person=%d href=#>remove", s.ID(), p.ID())
 				}
@@ -139,7 +146,7 @@ func HandleShiftSignup(r *request.Request, user, p *person.Person) (date string)
 	date = s.Start()[:10]
 	t = task.WithID(r, s.Task(), taskFields)
 	e = event.WithID(r, t.Event(), eventFields)
-	editable = user.HasPrivLevel(t.Org(), enum.PrivLeader)
+	editable = auth.Can(user, enum.PermEventEdit, t.Org())
 	if pid := person.ID(util.ParseID(r.FormValue("person"))); pid >= 0 {
 		if !editable {
 			return
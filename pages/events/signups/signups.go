@@ -57,7 +57,7 @@ func Handle(r *request.Request, token string) {
 			{Name: r.Loc("List"), URL: "/events/list/" + month[0:4], Target: "main"},
 			{Name: r.Loc("Signups"), URL: "/events/signups", Target: "main", Active: true},
 		}
-		if user.HasPrivLevel(0, enum.PrivLeader) {
+		if auth.Can(user, enum.PermEventEdit, 0) {
 			opts.Tabs = append(opts.Tabs, ui.PageTab{Name: "Add Event", URL: "/events/create", Target: "main"})
 		}
 	}
@@ -97,7 +97,7 @@ func Handle(r *request.Request, token string) {
 					if t.Details() != "" {
 						tdiv.E("div class=signupTaskDetails").R(t.Details())
 					}
-					ShowTaskSignups(r, tdiv, t, user, user.HasPrivLevel(t.Org(), enum.PrivLeader), false)
+					ShowTaskSignups(r, tdiv, t, user, auth.Can(user, enum.PermEventEdit, t.Org()), false)
 					lastt = t.ID()
 				}
 			})
@@ -29,7 +29,7 @@ func Handle(r *request.Request, tidstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
-	if !user.HasPrivLevel(t.Org(), enum.PrivLeader) {
+	if !auth.Can(user, enum.PermEventEdit, t.Org()) {
 		errpage.Forbidden(r, user)
 		return
 	}
@@ -14,6 +14,7 @@ import (
 	"sunnyvaleserv.org/portal/pages/files"
 	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/document"
+	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/folder"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/util"
@@ -340,7 +341,7 @@ func readURL(r *request.Request, user *person.Person, ud *document.Updater) stri
 	}
 	if u, err := url.Parse(ud.URL); err != nil {
 		return fmt.Sprintf("%q is not a valid URL.", ud.URL)
-	} else if u.Scheme != "http" && u.Scheme != "https" && (u.Scheme != "" || !user.IsAdminLeader()) {
+	} else if u.Scheme != "http" && u.Scheme != "https" && (u.Scheme != "" || !auth.Can(user, enum.PermFolderApprove, 0)) {
 		return fmt.Sprintf("The %q URL scheme is not supported.  Only \"http\" and \"https\" are supported.", u.Scheme)
 	}
 	return ""
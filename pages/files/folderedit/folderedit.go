@@ -161,7 +161,7 @@ func readViewer(r *request.Request, user *person.Person, uf *folder.Updater) {
 			if !user.HasPrivLevel(pv.org, pv.priv) {
 				return
 			}
-			if pv.priv == 0 && !user.IsAdminLeader() {
+			if pv.priv == 0 && !auth.Can(user, enum.PermFolderApprove, 0) {
 				return
 			}
 			uf.ViewOrg, uf.ViewPriv = pv.org, pv.priv
@@ -178,7 +178,7 @@ func emitViewer(form *htmlb.Element, user *person.Person, uf *folder.Updater) {
 		if !user.HasPrivLevel(pv.org, pv.priv) {
 			continue
 		}
-		if pv.priv == 0 && !user.IsAdminLeader() {
+		if pv.priv == 0 && !auth.Can(user, enum.PermFolderApprove, 0) {
 			continue
 		}
 		input.E("s-radio name=viewer value=%d.%d label=%s", pv.org, pv.priv, pv.label,
@@ -0,0 +1,74 @@
+package history
+
+import (
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/history"
+	"sunnyvaleserv.org/portal/store/list"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// HandleEvent handles GET /events/$id/history requests.
+func HandleEvent(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		e    *event.Event
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !auth.Can(user, enum.PermHistoryView, 0) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if e = event.WithID(r, event.ID(util.ParseID(idstr)), event.FID|event.FName|event.FStart); e == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	showDialog(r, e.Start()[:10]+" "+e.Name(), history.Event, int(e.ID()), nil)
+}
+
+// HandleRole handles GET /admin/roles/$id/history requests.
+func HandleRole(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		rl   *role.Role
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if rl = role.WithID(r, role.ID(util.ParseID(idstr)), role.FID|role.FName); rl == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	showDialog(r, rl.Name(), history.Role, int(rl.ID()), nil)
+}
+
+// HandleList handles GET /admin/lists/$id/history requests.
+func HandleList(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		l    *list.List
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if l = list.WithID(r, list.ID(util.ParseID(idstr))); l == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	showDialog(r, l.Name, history.List, int(l.ID), nil)
+}
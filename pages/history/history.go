@@ -0,0 +1,85 @@
+// Package history contains the pages that display the change history of
+// people, events, roles, and lists.
+package history
+
+import (
+	"sunnyvaleserv.org/portal/store/history"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// A Filter decides whether a change to the specified field may be shown to the
+// viewer.  If show is false, the change is not shown at all; if redact is
+// true, the change is shown without its values.  A nil Filter shows all
+// changes.
+type Filter func(field string) (show, redact bool)
+
+// Show renders the history of the specified entity into parent.  If max is
+// nonzero, it shows at most that many changes.  It returns the number of
+// changes shown, and whether there were more that weren't shown.
+func Show(r *request.Request, parent *htmlb.Element, etype history.Type, id int, filter Filter, max int) (count int, more bool) {
+	var grid *htmlb.Element
+
+	history.ForEntity(r, etype, id, func(e *history.Entry) {
+		var show, redact = true, false
+		if filter != nil {
+			if show, redact = filter(e.Field); !show {
+				return
+			}
+		}
+		if max != 0 && count >= max {
+			more = true
+			return
+		}
+		if grid == nil {
+			grid = parent.E("div class=history")
+		}
+		count++
+		entry := grid.E("div class=historyEntry")
+		meta := entry.E("div class=historyMeta")
+		meta.E("span class=historyTime>%s", e.Timestamp.Format("2006-01-02 15:04"))
+		switch {
+		case e.Actor == 0:
+			meta.E("span class=historyActor>(system)")
+		case e.ActorName == "":
+			meta.E("span class=historyActor>(deleted person)")
+		default:
+			meta.E("span class=historyActor>%s", e.ActorName)
+		}
+		if e.Impersonator != 0 && e.ImpersonatorName != "" {
+			meta.E("span class=historyImpersonator>(by %s)", e.ImpersonatorName)
+		} else if e.Impersonator != 0 {
+			meta.E("span class=historyImpersonator>(by deleted webmaster)")
+		}
+		change := entry.E("div class=historyChange")
+		change.E("span class=historyField>%s", e.Field)
+		if e.New == "" && e.Old == "" {
+			return
+		}
+		if redact {
+			change.E("span class=historyRedacted>(value hidden)")
+			return
+		}
+		if e.Old != "" {
+			change.E("span class=historyOld>%s", e.Old)
+		}
+		change.E("span class=historyNew>%s", e.New)
+	})
+	return count, more
+}
+
+// showDialog renders a dialog box showing the complete history of the
+// specified entity.
+func showDialog(r *request.Request, title string, etype history.Type, id int, filter Filter) {
+	r.HTMLNoCache()
+	html := htmlb.HTML(r)
+	defer html.Close()
+	form := html.E("div class='form form-2col' up-main")
+	form.E("div class='formTitle formTitle-primary'>History: %s", title)
+	row := form.E("div class=formRow-3col")
+	if count, _ := Show(r, row, etype, id, filter, 0); count == 0 {
+		row.E("div>No changes have been recorded.")
+	}
+	form.E("div class=formButtons").
+		E("button type=button class='sbtn sbtn-primary' up-dismiss>OK")
+}
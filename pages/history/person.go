@@ -0,0 +1,104 @@
+package history
+
+import (
+	"strings"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/history"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// personFieldVisibility gives the visibility of changes to sensitive person
+// fields, using the same levels as notes on the person.  Fields not listed
+// here are visible to anyone who can see the history.  Changes to notes are
+// visible only to the webmaster, since the history doesn't record the
+// visibility of the note that was changed.
+var personFieldVisibility = map[string]person.NoteVisibility{
+	"addresses":        person.NoteVisibleWithContact,
+	"badLoginCount":    person.NoteVisibleToWebmaster,
+	"badLoginTime":     person.NoteVisibleToWebmaster,
+	"bgChecks":         person.NoteVisibleToAdmins,
+	"birthdate":        person.NoteVisibleToAdmins,
+	"cellPhone":        person.NoteVisibleWithContact,
+	"email":            person.NoteVisibleWithContact,
+	"email2":           person.NoteVisibleWithContact,
+	"emContacts":       person.NoteVisibleWithContact,
+	"homePhone":        person.NoteVisibleWithContact,
+	"hoursToken":       person.NoteVisibleToWebmaster,
+	"identification":   person.NoteVisibleToAdmins,
+	"medicalNotes":     person.NoteVisibleToAdmins,
+	"notes":            person.NoteVisibleToWebmaster,
+	"password":         person.NoteVisibleToWebmaster,
+	"pwresetTime":      person.NoteVisibleToWebmaster,
+	"pwresetToken":     person.NoteVisibleToWebmaster,
+	"unsubscribeToken": person.NoteVisibleToWebmaster,
+	"volgisticsID":     person.NoteVisibleToAdmins,
+}
+
+// secretPersonFields are the person fields whose values are never shown, even
+// to those who can see that they changed.
+var secretPersonFields = map[string]bool{
+	"hoursToken":       true,
+	"password":         true,
+	"pwresetToken":     true,
+	"unsubscribeToken": true,
+}
+
+// PersonFilter returns a Filter that hides changes to person fields that the
+// specified user cannot see, given their view level on the person.
+func PersonFilter(user *person.Person, viewLevel person.ViewLevel) Filter {
+	return func(field string) (show, redact bool) {
+		// Reduce "ADD notes[3]:: date" to "notes", etc.
+		base, _, _ := strings.Cut(field, ":: ")
+		if _, after, found := strings.Cut(base, " "); found {
+			base = after
+		}
+		if idx := strings.IndexAny(base, "[."); idx >= 0 {
+			base = base[:idx]
+		}
+		switch personFieldVisibility[base] {
+		case person.NoteVisibleToWebmaster:
+			show = user.IsWebmaster()
+		case person.NoteVisibleToAdmins:
+			show = user.IsAdminLeader()
+		case person.NoteVisibleToLeaders:
+			show = user.HasPrivLevel(0, enum.PrivLeader)
+		case person.NoteVisibleWithContact:
+			show = viewLevel == person.ViewFull
+		default:
+			show = true
+		}
+		return show, secretPersonFields[base]
+	}
+}
+
+// CanViewPersonHistory returns whether the user can view the history of the
+// person.  The user must have person.CanViewViewerFields.
+func CanViewPersonHistory(user *person.Person, viewLevel person.ViewLevel) bool {
+	return viewLevel != person.ViewNone && auth.Can(user, enum.PermHistoryView, 0)
+}
+
+// HandlePerson handles GET /people/$id/history requests.
+func HandlePerson(r *request.Request, idstr string) {
+	var (
+		user      *person.Person
+		p         *person.Person
+		viewLevel person.ViewLevel
+	)
+	if user = auth.SessionUser(r, person.CanViewViewerFields, true); user == nil {
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), person.FInformalName|person.CanViewTargetFields); p == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if viewLevel = user.CanView(p); !CanViewPersonHistory(user, viewLevel) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	showDialog(r, p.InformalName(), history.Person, int(p.ID()), PersonFilter(user, viewLevel))
+}
@@ -0,0 +1,72 @@
+package login
+
+import (
+	"fmt"
+	"net/http"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// HandleImpersonate handles /people/$id/impersonate requests, with which a
+// webmaster starts viewing the site as someone else.  A GET shows a
+// confirmation dialog; a POST starts the impersonation session.
+func HandleImpersonate(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		p    *person.Person
+	)
+	if user = auth.SessionUser(r, person.FID|person.FInformalName, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), person.FID|person.FInformalName); p == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if !auth.CanImpersonate(r, user, p) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if r.Method == http.MethodPost {
+		auth.StartImpersonation(r, user, p)
+		http.Redirect(r, r.Request, "/", http.StatusSeeOther)
+		return
+	}
+	r.HTMLNoCache()
+	html := htmlb.HTML(r)
+	defer html.Close()
+	form := html.E("form class='form form-2col' method=POST up-main up-layer=root up-target=body")
+	form.E("div class='formTitle formTitle-primary'>View Site As %s", p.InformalName())
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	form.E("div class=formRow-3col>You will see the site exactly as %s does, and can act on their behalf.  You will not be able to change their password or login settings.  The session will be recorded in the audit log with both your names, along with every change you make during it.", p.InformalName())
+	form.E("div class=formRow-3col>Your own session will end.  To return to it, click the button in the banner at the top of the page.")
+	buttons := form.E("div class=formButtons")
+	buttons.E("button type=button class='sbtn sbtn-secondary' up-dismiss>Cancel")
+	buttons.E("input type=submit class='sbtn sbtn-warning' value='View Site As'")
+}
+
+// HandleEndImpersonation handles POST /impersonate/end requests, with which a
+// webmaster stops viewing the site as someone else and returns to their own
+// session.
+func HandleEndImpersonation(r *request.Request) {
+	var user *person.Person
+
+	if user = auth.SessionUser(r, person.FID|person.FInformalName, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if r.Method != http.MethodPost || !auth.Impersonating(r) {
+		errpage.NotFound(r, user)
+		return
+	}
+	if auth.EndImpersonation(r, user) == nil {
+		// The impersonator is no longer a webmaster, so they were
+		// signed out.
+		http.Redirect(r, r.Request, "/login", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(r, r.Request, fmt.Sprintf("/people/%d", user.ID()), http.StatusSeeOther)
+}
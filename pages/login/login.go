@@ -19,35 +19,54 @@ const maxBadLogins = 3
 // Threshold time for bad login attempts
 const badLoginThreshold = 20 * time.Minute
 
+// loginPersonFields are the fields of the person logging in that are needed
+// by the login process.
+const loginPersonFields = person.FID | person.FInformalName | person.FEmail | person.FBadLoginCount | person.FBadLoginTime | person.FPrivLevels | person.FPassword | person.FCallSign | person.FFlags
+
 // HandleLogin handles GET and POST /login and /login/* requests.
 func HandleLogin(r *request.Request) {
-	const personFields = person.FID | person.FInformalName | person.FBadLoginCount | person.FBadLoginTime | person.FPrivLevels | person.FPassword | person.FCallSign
 	var (
-		email      string
-		remember   bool
-		statusCode = http.StatusOK
+		email         string
+		remember      bool
+		passkeyFailed bool
+		ssoRequired   bool
+		throttled     time.Time
+		statusCode    = http.StatusOK
 	)
 	if auth.SessionUser(r, 0, false) != nil { // Already logged in.
-		if len(r.Path) > 6 { // Redirect path in URL.
-			http.Redirect(r, r.Request, r.Path[6:], http.StatusSeeOther)
-		} else {
-			http.Redirect(r, r.Request, "/", http.StatusSeeOther)
-		}
+		redirectAfterLogin(r)
 		return
 	}
 	if r.Method == http.MethodPost {
+		throttled = auth.LoginThrottled(r)
+	}
+	if !throttled.IsZero() {
+		// Refuse all login attempts from a client that has had too many
+		// recent failures, without checking them.
+		email, statusCode = r.FormValue("email"), http.StatusTooManyRequests
+	} else if r.Method == http.MethodPost && r.FormValue("passkey") != "" {
+		if handlePasskeyLogin(r) {
+			return
+		}
+		auth.RecordLoginFailure(r)
+		passkeyFailed, statusCode = true, http.StatusUnprocessableEntity
+	} else if r.Method == http.MethodPost {
 		var (
 			p        *person.Person
 			password = r.FormValue("password")
 		)
+		if token := r.FormValue("pending"); token != "" {
+			handleSecondFactor(r, token)
+			return
+		}
 		email = r.FormValue("email")
 		remember = r.FormValue("remember") != ""
 		// Check that the login is valid.
-		if p = person.WithEmail(r, email, personFields); p == nil {
+		if p = person.WithEmail(r, email, loginPersonFields); p == nil {
 			goto FAIL // no person with that username
 		}
 		if p.ID() != person.AdminID { // admin cannot be disabled or locked out
-			if p.BadLoginCount() >= maxBadLogins && time.Now().Before(p.BadLoginTime().Add(badLoginThreshold)) {
+			if LockedOut(p) {
 				goto FAIL // locked out
 			}
 			if held, _ := personrole.PersonHasRole(r, p.ID(), role.Disabled); held {
@@ -59,38 +78,29 @@ func HandleLogin(r *request.Request) {
 		if !auth.CheckPassword(r, p, password) {
 			goto FAIL // password mismatch
 		}
-		// The login is valid.  Record it and create a session.
-		r.Transaction(func() {
-			if p.BadLoginCount() > 0 {
-				up := p.Updater()
-				up.BadLoginCount = 0
-				up.BadLoginTime = time.Time{}
-				p.Update(r, up, person.FBadLoginCount|person.FBadLoginTime)
-			}
-			auth.CreateSession(r, p, remember)
-		})
-		if len(r.Path) > 6 { // Redirect path in URL.
-			http.Redirect(r, r.Request, r.Path[6:], http.StatusSeeOther)
-		} else {
-			http.Redirect(r, r.Request, "/", http.StatusSeeOther)
+		// The password is valid, but it isn't good enough for someone
+		// who must sign in through the site's OpenID provider.
+		if auth.SSORequired(p) {
+			ssoRequired, statusCode = true, http.StatusUnprocessableEntity
+			goto PAGE
 		}
+		// The password is valid.  If the person uses (or must use)
+		// two-factor authentication, ask for their second factor.
+		if startSecondFactor(r, p, remember) {
+			return
+		}
+		// The login is valid.  Record it and create a session.
+		finishLogin(r, p, remember)
+		redirectAfterLogin(r)
 		return
 	FAIL:
 		statusCode = http.StatusUnprocessableEntity
+		auth.RecordLoginFailure(r)
 		if p != nil {
-			// Record the bad login attempt.
-			r.Transaction(func() {
-				up := p.Updater()
-				if time.Now().Before(up.BadLoginTime.Add(badLoginThreshold)) {
-					up.BadLoginCount++
-				} else {
-					up.BadLoginCount = 1
-				}
-				up.BadLoginTime = time.Now()
-				p.Update(r, up, person.FBadLoginCount|person.FBadLoginTime)
-			})
+			recordBadLogin(r, p)
 		}
 	}
+PAGE:
 	ui.Page(r, nil, ui.PageOpts{
 		Title:      r.Loc("Login"),
 		Banner:     "Sunnyvale SERV",
@@ -99,41 +109,113 @@ func HandleLogin(r *request.Request) {
 		main.A("class=login")
 		main.E("div class=loginBanner").T(r.Loc("Please log in."))
 		main.E("div class=loginBrowserwarn").T(r.Loc("Your browser is out of date and lacks features needed by this web site. The site may not look or behave correctly."))
-		form := main.E("form class='form form-centered form-2col loginForm' method=POST up-target=body up-fail-target=form")
+		showSSOLogin(r, main, remember)
+		showPasskeyLogin(r, main, passkeyFailed)
+		form := main.E("form class='form form-centered form-2col loginForm loginPassword' method=POST up-target=body up-fail-target=.loginPassword")
 
 		// Email row.
 		row := form.E("div class=formRow")
 		row.E("label for=loginEmail class=formLabel").T(r.Loc("Email address"))
-		row.E("input name=email type=text id=loginEmail autocomplete=email autocapitalize=none inputmode=email value=%s",
-			email, statusCode == http.StatusOK, "autofocus")
+		row.E("input name=email type=text id=loginEmail autocomplete='email webauthn' autocapitalize=none inputmode=email value=%s",
+			email, statusCode == http.StatusOK || passkeyFailed, "autofocus")
 
 		// Password row.
 		row = form.E("div class=formRow")
 		row.E("label for=loginPassword class=formLabel").T(r.Loc("Password"))
 		row.E("input name=password type=password id=loginPassword autocomplete=password autocapitalize=none",
-			statusCode != http.StatusOK, "autofocus")
+			statusCode != http.StatusOK && !passkeyFailed, "autofocus")
 
 		// Remember row.
 		row = form.E("div class=formRow")
-		row.E("div class=formInput").E("input type=checkbox class=s-check name=remember label=%s", r.Loc("Remember me"), remember, "checked")
+		row.E("div class=formInput").E("input type=checkbox class=s-check id=loginRemember name=remember label=%s", r.Loc("Remember me"), remember, "checked")
 
 		// Submit button row.
 		row = form.E("div class='formRow-3col loginSubmit'")
 		row.E("input type=submit class='sbtn sbtn-primary' value=%s", r.Loc("Log in"))
 
 		// Failure notice.
-		if statusCode != http.StatusOK {
+		if !throttled.IsZero() {
+			form.E("div class='formRow-3col loginFailed'").TF(r.Loc("There have been too many failed login attempts from your network.  Please try again after %s."), throttled.Format("3:04pm"))
+		} else if ssoRequired {
+			form.E("div class='formRow-3col loginFailed'").TF(r.Loc("Your account must sign in with %s."), auth.SSOLabel())
+		} else if statusCode != http.StatusOK && !passkeyFailed {
 			form.E("div class='formRow-3col loginFailed'").T(r.Loc("Login incorrect. Please try again."))
 		}
 
 		// Reset password link.
 		main.E("div class=loginReset").E("a href=/password-reset up-follow").T(r.Loc("Reset my password"))
 
+		// Sign-in link request.
+		if auth.LoginLinksEnabled() {
+			main.E("div class=loginLink").E("a href=/login-link up-follow").T(r.Loc("Email me a sign-in link"))
+		}
+
 		// Website information link.
 		main.E("div class=loginAbout").E("a href=/about up-follow").T(r.Loc("Web Site Information"))
 	})
 }
 
+// LockedOut returns whether the person is locked out due to too many recent
+// bad login attempts.
+func LockedOut(p *person.Person) bool {
+	return p.BadLoginCount() >= maxBadLogins && time.Now().Before(p.BadLoginTime().Add(badLoginThreshold))
+}
+
+// Unlock clears the record of recent bad login attempts by the person, ending
+// any lockout.  The person must have FID, FInformalName, FBadLoginCount, and
+// FBadLoginTime.
+func Unlock(r *request.Request, p *person.Person) {
+	r.Transaction(func() {
+		up := p.Updater()
+		up.BadLoginCount = 0
+		up.BadLoginTime = time.Time{}
+		p.Update(r, up, person.FBadLoginCount|person.FBadLoginTime)
+	})
+}
+
+// recordBadLogin records a bad login attempt by the person.
+func recordBadLogin(r *request.Request, p *person.Person) {
+	r.Transaction(func() {
+		up := p.Updater()
+		if time.Now().Before(up.BadLoginTime.Add(badLoginThreshold)) {
+			up.BadLoginCount++
+		} else {
+			up.BadLoginCount = 1
+		}
+		up.BadLoginTime = time.Now()
+		p.Update(r, up, person.FBadLoginCount|person.FBadLoginTime)
+	})
+}
+
+// finishLogin records a successful login by the person and creates a session
+// for them.
+func finishLogin(r *request.Request, p *person.Person, remember bool) {
+	r.Transaction(func() {
+		if p.BadLoginCount() > 0 {
+			up := p.Updater()
+			up.BadLoginCount = 0
+			up.BadLoginTime = time.Time{}
+			p.Update(r, up, person.FBadLoginCount|person.FBadLoginTime)
+		}
+		auth.CreateSession(r, p, remember)
+	})
+}
+
+// redirectAfterLogin redirects to the page named in the login URL, if any, or
+// to the home page.
+func redirectAfterLogin(r *request.Request) {
+	http.Redirect(r, r.Request, afterLoginURL(r), http.StatusSeeOther)
+}
+
+// afterLoginURL returns the URL of the page named in the login URL, if any,
+// or of the home page.
+func afterLoginURL(r *request.Request) string {
+	if len(r.Path) > 6 { // Redirect path in URL.
+		return r.Path[6:]
+	}
+	return "/"
+}
+
 // HandleLogout handles GET /logout requests.
 func HandleLogout(r *request.Request) {
 	if user := auth.SessionUser(r, person.FID|person.FInformalName, false); user != nil {
@@ -0,0 +1,55 @@
+// The passkey login form is hidden unless the browser supports passkeys.  The
+// passkey can be chosen with the button in the form, or, in browsers that
+// support it, from the autofill suggestions for the email address field.
+// Either way, the browser's response is copied into the form and submitted.
+up.compiler('.loginPasskey', (form) => {
+  if (!window.PublicKeyCredential || !navigator.credentials) return
+  form.hidden = false
+  const encode = (buf) => btoa(String.fromCharCode(...new Uint8Array(buf)))
+    .replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '')
+  let abort
+  const login = async (mediation) => {
+    if (abort) abort.abort()
+    abort = new AbortController()
+    let cred
+    try {
+      cred = await navigator.credentials.get({
+        mediation,
+        signal: abort.signal,
+        publicKey: {
+          challenge: new TextEncoder().encode(form.dataset.challenge),
+          rpId: form.dataset.rpid,
+          userVerification: 'preferred',
+          allowCredentials: [],
+        },
+      })
+    } catch (err) {
+      return // canceled, or no passkey available
+    }
+    if (!cred) return
+    form.elements.credential.value = encode(cred.rawId)
+    form.elements.clientData.value = encode(cred.response.clientDataJSON)
+    form.elements.authData.value = encode(cred.response.authenticatorData)
+    form.elements.signature.value = encode(cred.response.signature)
+    const remember = document.getElementById('loginRemember')
+    form.elements.remember.value = remember && remember.checked ? 'true' : ''
+    up.submit(form)
+  }
+  form.querySelector('.loginPasskeyButton').addEventListener('click', () => { login('optional') })
+  if (PublicKeyCredential.isConditionalMediationAvailable) {
+    PublicKeyCredential.isConditionalMediationAvailable().then((available) => {
+      if (available) login('conditional')
+    })
+  }
+  return () => { if (abort) abort.abort() }
+})
+
+// The single sign-on form is submitted natively, since it redirects to the
+// OpenID provider.  It carries the setting of the "Remember me" checkbox in
+// the password form.
+up.compiler('.loginSSO', (form) => {
+  form.addEventListener('submit', () => {
+    const remember = document.getElementById('loginRemember')
+    form.elements.remember.value = remember && remember.checked ? 'true' : ''
+  })
+})
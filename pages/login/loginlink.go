@@ -0,0 +1,184 @@
+package login
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/server/l10n"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personrole"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/store/throttle"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+	"sunnyvaleserv.org/portal/util/sendmail"
+)
+
+// Requests for sign-in links are rate limited per client IP address and per
+// email address.
+const (
+	loginLinkThrottleWindow = time.Hour
+	loginLinkIPLimit        = 10
+	loginLinkEmailLimit     = 3
+)
+
+// HandleLoginLink handles /login-link requests, with which people ask for a
+// link, sent to them by email, that signs them in without a password.
+func HandleLoginLink(r *request.Request) {
+	const personFields = loginPersonFields | person.FEmail2 | person.FFlags
+	var (
+		p         *person.Person
+		browser   string
+		token     string
+		throttled bool
+		body      bytes.Buffer
+		to        string
+		email     = strings.TrimSpace(r.FormValue("email"))
+		remember  = r.FormValue("remember") != ""
+	)
+	if !auth.LoginLinksEnabled() {
+		errpage.NotFound(r, nil)
+		return
+	}
+	if r.Method != http.MethodPost || email == "" {
+		showLoginLinkRequest(r, false)
+		return
+	}
+	// Bind the link to this browser, whether or not we actually send one,
+	// so that the response doesn't reveal whether the address is known.
+	browser = auth.BindLoginLinkBrowser(r)
+	r.Transaction(func() {
+		since, now := time.Now().Add(-loginLinkThrottleWindow), time.Now()
+		if throttle.Count(r, "login-link-ip:"+r.ClientIP(), since) >= loginLinkIPLimit ||
+			throttle.Count(r, "login-link-email:"+strings.ToLower(email), since) >= loginLinkEmailLimit {
+			throttled = true
+			return
+		}
+		throttle.Record(r, "login-link-ip:"+r.ClientIP(), now)
+		throttle.Record(r, "login-link-email:"+strings.ToLower(email), now)
+	})
+	if throttled {
+		showLoginLinkRequest(r, true)
+		return
+	}
+	if p = person.WithEmail(r, email, personFields); p == nil {
+		goto RESPOND // email not recognized
+	}
+	if !auth.LoginLinksAllowed(p) || LockedOut(p) {
+		goto RESPOND // sign-in links turned off, or locked out
+	}
+	if held, _ := personrole.PersonHasRole(r, p.ID(), role.Disabled); held {
+		goto RESPOND // person is disabled
+	}
+	// Send the link only to the address that was entered, since that's the
+	// one the person is expecting it at.
+	if strings.EqualFold(email, p.Email2()) {
+		to = p.Email2()
+	} else {
+		to = p.Email()
+	}
+	// Both languages of the message carry the same link.
+	token = auth.LoginLinkToken(r, p, browser, remember)
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\n", config.Get("fromEmail"), (&mail.Address{Name: p.InformalName(), Address: to}).String())
+	fmt.Fprintf(&body, "Subject: %s / %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n",
+		"SunnyvaleSERV.org Sign-In Link", l10n.Localize("SunnyvaleSERV.org Sign-In Link", "es"))
+	for i, lang := range []string{"en", "es"} {
+		if i != 0 {
+			fmt.Fprint(&body, "\r\n\r\n----------------------------------------\r\n\r\n")
+		}
+		fmt.Fprintf(&body, l10n.Localize("Greetings, %s,", lang), p.InformalName())
+		fmt.Fprint(&body, "\r\n\r\n")
+		fmt.Fprint(&body, l10n.Localize("To sign in to SunnyvaleSERV.org, click this link:", lang))
+		fmt.Fprintf(&body, "\r\n    %s/login-link/%s\r\n\r\n", config.Get("siteURL"), token)
+		fmt.Fprint(&body, l10n.Localize("This link can be used only once, and only in the browser where you asked for it.  It expires in 15 minutes.", lang))
+		fmt.Fprint(&body, "\r\n\r\n")
+		fmt.Fprint(&body, l10n.Localize("If you did not ask to sign in, you can safely ignore this email.  If you have any problems, reply to this email.", lang))
+		fmt.Fprint(&body, "\r\n\r\nSunnyvaleSERV.org")
+	}
+	fmt.Fprint(&body, "\r\n")
+	if err := sendmail.SendMessage(r.Context(), config.Get("fromAddr"), []string{to}, body.Bytes()); err != nil {
+		panic(err)
+	}
+RESPOND:
+	ui.Page(r, nil, ui.PageOpts{}, func(main *htmlb.Element) {
+		main.A("class=login")
+		main.E("div class=loginBanner").T(r.Loc("Sign-In Link"))
+		exp := main.E("div class=loginExplain")
+		exp.E("p").T(r.Loc("If the email address you provided is one we have on file, we have sent a sign-in link to it.  It is valid for 15 minutes.  Please open it in this browser."))
+		exp.E("p").R(r.Loc("If you do not receive an email with a sign-in link, it may be that the email address you provided is not the one we have on file for you. Contact <a href=\"mailto:admin@sunnyvaleserv.org\">admin@SunnyvaleSERV.org</a> for assistance."))
+	})
+}
+
+// showLoginLinkRequest shows the form asking for the email address to which a
+// sign-in link should be sent.
+func showLoginLinkRequest(r *request.Request, throttled bool) {
+	var statusCode = http.StatusOK
+	if throttled {
+		statusCode = http.StatusTooManyRequests
+	}
+	ui.Page(r, nil, ui.PageOpts{StatusCode: statusCode}, func(main *htmlb.Element) {
+		main.A("class=login")
+		main.E("div class=loginBanner").T(r.Loc("Sign-In Link"))
+		main.E("div class=loginExplain").T(r.Loc("To sign in without your password, enter your email address.  If it’s one we have on file, we’ll send a sign-in link to it."))
+		form := main.E("form class='form form-centered form-2col loginForm' method=POST up-target=body")
+		row := form.E("div class=formRow")
+		row.E("label for=loginLinkEmail class=formLabel").T(r.Loc("Email address"))
+		row.E("input type=text id=loginLinkEmail name=email autocomplete=email autocapitalize=none inputmode=email autofocus value=%s", r.FormValue("email"))
+		row = form.E("div class=formRow")
+		row.E("div class=formInput").E("input type=checkbox class=s-check id=loginLinkRemember name=remember label=%s", r.Loc("Remember me"), r.FormValue("remember") != "", "checked")
+		row = form.E("div class='formRow-3col loginSubmit'")
+		row.E("input type=submit class='sbtn sbtn-primary' value=%s", r.Loc("Send Sign-In Link"))
+		if throttled {
+			form.E("div class='formRow-3col loginFailed'").T(r.Loc("Too many sign-in links have been requested.  Please try again later."))
+		}
+	})
+}
+
+// HandleLoginLinkToken handles /login-link/${token} requests:  it signs in the
+// person to whom the sign-in link was sent.
+func HandleLoginLinkToken(r *request.Request, token string) {
+	var (
+		p        *person.Person
+		pid      person.ID
+		remember bool
+	)
+	if !auth.LoginLinksEnabled() {
+		errpage.NotFound(r, nil)
+		return
+	}
+	if pid, remember = auth.UseLoginLinkToken(r, token); pid == 0 {
+		goto INVALID // unknown, expired, used, or wrong browser
+	}
+	if p = person.WithID(r, pid, loginPersonFields|person.FFlags); p == nil {
+		goto INVALID // person no longer exists
+	}
+	if !auth.LoginLinksAllowed(p) || LockedOut(p) {
+		goto INVALID // sign-in links turned off, or locked out
+	}
+	if held, _ := personrole.PersonHasRole(r, p.ID(), role.Disabled); held {
+		goto INVALID // person is disabled
+	}
+	// The link stands in for the password only.  If the person uses (or
+	// must use) two-factor authentication, ask for their second factor.
+	if startSecondFactor(r, p, remember) {
+		return
+	}
+	finishLogin(r, p, remember)
+	http.Redirect(r, r.Request, "/", http.StatusSeeOther)
+	return
+INVALID:
+	ui.Page(r, nil, ui.PageOpts{}, func(main *htmlb.Element) {
+		main.A("class=login")
+		main.E("div class=loginBanner").T(r.Loc("Sign-In Link"))
+		main.E("div class=loginExplain").T(r.Loc("This sign-in link is invalid, has expired, or has already been used.  Sign-in links work only in the browser where you asked for them."))
+		main.E("div class=loginSubmit").E("a class='sbtn sbtn-primary' href=/login-link up-target=body").T(r.Loc("Try Again"))
+	})
+}
@@ -0,0 +1,70 @@
+package login
+
+import (
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personrole"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// showPasskeyLogin adds the passkey login form to the login page.  It is
+// hidden, and revealed by login.js if the browser supports passkeys.  The
+// browser also offers passkeys when the email address field is focused.
+func showPasskeyLogin(r *request.Request, main *htmlb.Element, failed bool) {
+	var challenge = auth.PasskeyChallenge()
+	form := main.E("form class='form form-centered form-2col loginForm loginPasskey' method=POST up-target=body up-fail-target=body hidden data-challenge=%s data-rpid=%s",
+		challenge, auth.PasskeyRPID())
+	form.E("input type=hidden name=passkey value=%s", challenge)
+	form.E("input type=hidden name=credential")
+	form.E("input type=hidden name=clientData")
+	form.E("input type=hidden name=authData")
+	form.E("input type=hidden name=signature")
+	form.E("input type=hidden name=remember")
+	form.E("div class='formRow-3col loginSubmit'").
+		E("button type=button class='sbtn sbtn-primary loginPasskeyButton'").T(r.Loc("Log in with a Passkey"))
+	if failed {
+		form.E("div class='formRow-3col loginFailed'").T(r.Loc("That passkey could not be used. Please try again, or use your password."))
+	}
+	form.E("div class='formRow-3col loginOr'").T(r.Loc("or log in with your password"))
+}
+
+// handlePasskeyLogin handles a login with a passkey.  It returns false if the
+// login failed, in which case the caller should show the login page again.
+func handlePasskeyLogin(r *request.Request) bool {
+	var (
+		p        *person.Person
+		remember = r.FormValue("remember") == "true"
+	)
+	pid, verified := auth.CheckPasskey(r, r.FormValue("passkey"), r.FormValue("credential"),
+		r.FormValue("clientData"), r.FormValue("authData"), r.FormValue("signature"))
+	if pid != 0 {
+		p = person.WithID(r, pid, loginPersonFields)
+	}
+	if p == nil {
+		return false
+	}
+	if p.ID() != person.AdminID { // admin cannot be disabled or locked out
+		if LockedOut(p) {
+			return false
+		}
+		if held, _ := personrole.PersonHasRole(r, p.ID(), role.Disabled); held {
+			return false
+		}
+		if auth.SSORequired(p) {
+			return false
+		}
+	} else { // admin can not be remembered
+		remember = false
+	}
+	// A passkey whose authenticator verified the user (with a PIN or
+	// biometric) is itself two-factor authentication.  Otherwise, it
+	// stands in for the password only.
+	if !verified && startSecondFactor(r, p, remember) {
+		return true
+	}
+	finishLogin(r, p, remember)
+	redirectAfterLogin(r)
+	return true
+}
@@ -98,7 +98,7 @@ RESPOND:
 
 // HandlePWResetToken handles /password-reset/${token} requests.
 func HandlePWResetToken(r *request.Request, token string) {
-	const personFields = person.FID | person.FPrivLevels | person.FPWResetToken | person.FPWResetTime | auth.StrongPasswordPersonFields
+	const personFields = loginPersonFields | person.FPWResetToken | person.FPWResetTime | auth.StrongPasswordPersonFields
 	var (
 		p       *person.Person
 		f       form.Form
@@ -115,6 +115,9 @@ func HandlePWResetToken(r *request.Request, token string) {
 	if time.Now().After(p.PWResetTime().Add(pwresetThreshold)) {
 		goto INVALID // token has expired
 	}
+	if auth.SSORequired(p) {
+		goto SSO // a new password wouldn't let them sign in
+	}
 	f.PageWrapper = func(r *request.Request, fn func(*htmlb.Element)) {
 		ui.Page(r, nil, ui.PageOpts{}, func(main *htmlb.Element) {
 			main.A("class=login")
@@ -126,10 +129,14 @@ func HandlePWResetToken(r *request.Request, token string) {
 	f.Centered, f.TwoCol = true, true
 	f.Buttons = []*form.Button{{
 		Label: "Reset Password", OnClick: func() bool {
-			r.Transaction(func() {
-				auth.SetPassword(r, p, newpwd1)
-				auth.CreateSession(r, p, false)
-			})
+			auth.SetPassword(r, p, newpwd1)
+			// The new password stands in for the old one, not for
+			// the second factor, so ask for that as a normal login
+			// would.
+			if startSecondFactor(r, p, false) {
+				return true
+			}
+			finishLogin(r, p, false)
 			http.Redirect(r, r.Request, "/", http.StatusSeeOther)
 			return true
 		},
@@ -155,4 +162,12 @@ INVALID:
 		main.E("div class=loginExplain").T(r.Loc("This password reset link is invalid or has expired."))
 		main.E("div class=loginSubmit").E("a class='sbtn sbtn-primary' href=/password-reset up-target=body").T(r.Loc("Try Again"))
 	})
+	return
+SSO:
+	ui.Page(r, nil, ui.PageOpts{StatusCode: http.StatusUnprocessableEntity}, func(main *htmlb.Element) {
+		main.A("class=login")
+		main.E("div class=loginBanner").T(r.Loc("Password Reset"))
+		main.E("div class=loginExplain").TF(r.Loc("Your account must sign in with %s."), auth.SSOLabel())
+		main.E("div class=loginSubmit").E("a class='sbtn sbtn-primary' href=/login up-target=body").T(r.Loc("Login"))
+	})
 }
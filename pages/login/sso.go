@@ -0,0 +1,104 @@
+package login
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personrole"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// showSSOLogin adds the single sign-on form to the login page, if single
+// sign-on is configured.  The form has no Unpoly attributes, so that the
+// browser follows our redirect to the OpenID provider.
+func showSSOLogin(r *request.Request, main *htmlb.Element, remember bool) {
+	if !auth.SSOEnabled() {
+		return
+	}
+	form := main.E("form class='form form-centered form-2col loginForm loginSSO' method=POST action=/sso")
+	form.E("input type=hidden name=next value=%s", afterLoginURL(r))
+	form.E("input type=hidden name=remember", remember, "value=true")
+	form.E("div class='formRow-3col loginSubmit'").
+		E("input type=submit class='sbtn sbtn-primary' value=%s", fmt.Sprintf(r.Loc("Log in with %s"), auth.SSOLabel()))
+	form.E("div class='formRow-3col loginOr'").T(r.Loc("or"))
+}
+
+// HandleSSO handles POST /sso requests, which start a single sign-on by
+// redirecting to the OpenID provider.
+func HandleSSO(r *request.Request) {
+	if !auth.SSOEnabled() {
+		errpage.NotFound(r, nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Redirect(r, r.Request, "/login", http.StatusSeeOther)
+		return
+	}
+	authURL, err := auth.StartSSO(r, r.FormValue("remember") == "true", r.FormValue("next"))
+	if err != nil {
+		r.LogEntry.Problems.AddError(err)
+		showSSOFailed(r, r.Loc("The single sign-on service is not responding.  Please try again later, or log in with your password."))
+		return
+	}
+	http.Redirect(r, r.Request, authURL, http.StatusSeeOther)
+}
+
+// HandleSSOCallback handles /sso/callback requests, to which the OpenID
+// provider redirects the browser after authenticating the person.
+func HandleSSOCallback(r *request.Request) {
+	var p *person.Person
+
+	if !auth.SSOEnabled() {
+		errpage.NotFound(r, nil)
+		return
+	}
+	pid, remember, next, err := auth.FinishSSO(r)
+	if errors.Is(err, auth.ErrSSONotLinked) {
+		showSSOFailed(r, fmt.Sprintf(r.Loc("Your %s account is not linked to an account on this site.  Please log in with your password, or contact admin@SunnyvaleSERV.org for help."), auth.SSOLabel()))
+		return
+	}
+	if err != nil {
+		r.LogEntry.Problems.AddError(err)
+		showSSOFailed(r, r.Loc("Single sign-on did not succeed.  Please try again."))
+		return
+	}
+	if p = person.WithID(r, pid, loginPersonFields); p == nil {
+		showSSOFailed(r, r.Loc("Single sign-on did not succeed.  Please try again."))
+		return
+	}
+	// The OpenID provider has authenticated the person, so a lockout
+	// from bad password attempts doesn't apply, but a disabled person
+	// still can't log in.
+	if held, _ := personrole.PersonHasRole(r, p.ID(), role.Disabled); held {
+		showSSOFailed(r, r.Loc("Login incorrect. Please try again."))
+		return
+	}
+	// The provider is responsible for any second factor, so we don't ask
+	// for one here, unless the person is required to use ours.
+	if auth.TOTPRequired(p) && startSecondFactor(r, p, remember) {
+		return
+	}
+	finishLogin(r, p, remember)
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		next = "/"
+	}
+	http.Redirect(r, r.Request, next, http.StatusSeeOther)
+}
+
+// showSSOFailed shows a page explaining that single sign-on failed.
+func showSSOFailed(r *request.Request, message string) {
+	ui.Page(r, nil, ui.PageOpts{StatusCode: http.StatusUnprocessableEntity}, func(main *htmlb.Element) {
+		main.A("class=login")
+		main.E("div class=loginBanner").T(r.Loc("Single Sign-On"))
+		main.E("div class=loginExplain").T(message)
+		main.E("div class=loginSubmit").E("a class='sbtn sbtn-primary' href=/login up-target=body").T(r.Loc("Try Again"))
+	})
+}
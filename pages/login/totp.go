@@ -0,0 +1,190 @@
+package login
+
+import (
+	"net/http"
+	"strings"
+
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/persontotp"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/qrcode"
+	"sunnyvaleserv.org/portal/util/request"
+	"sunnyvaleserv.org/portal/util/totp"
+)
+
+// startSecondFactor checks whether the person, who has supplied a correct
+// password, uses (or must start using) two-factor authentication.  If so, it
+// shows the second step of the login process and returns true.
+func startSecondFactor(r *request.Request, p *person.Person, remember bool) bool {
+	if persontotp.Get(r, p.ID()) != nil {
+		showSecondFactor(r, auth.PendingLoginToken(p, remember), false)
+		return true
+	}
+	if auth.TOTPRequired(p) {
+		showEnrollment(r, p, auth.PendingLoginToken(p, remember), totp.NewSecret(), false)
+		return true
+	}
+	return false
+}
+
+// loginURL returns the URL to which the second step of the login process is
+// posted.  That is the current URL when the login started on the login page,
+// but the plain login page when it started with a sign-in link.
+func loginURL(r *request.Request) string {
+	if r.Path == "/login" || strings.HasPrefix(r.Path, "/login/") {
+		return r.Path
+	}
+	return "/login"
+}
+
+// handleSecondFactor handles the second step of the login process, for people
+// who use (or must start using) two-factor authentication.  The token is the
+// pending login token issued when they supplied a correct password.
+func handleSecondFactor(r *request.Request, token string) {
+	var (
+		p        *person.Person
+		pid      person.ID
+		remember bool
+		code     = r.FormValue("code")
+	)
+	if pid, remember = auth.CheckPendingLoginToken(token); pid != 0 {
+		p = person.WithID(r, pid, loginPersonFields)
+	}
+	if p == nil { // pending login expired; start over
+		http.Redirect(r, r.Request, r.Path, http.StatusSeeOther)
+		return
+	}
+	if persontotp.Get(r, p.ID()) != nil {
+		if !LockedOut(p) && auth.CheckSecondFactor(r, p, code) {
+			finishLogin(r, p, remember)
+			redirectAfterLogin(r)
+			return
+		}
+		auth.RecordLoginFailure(r)
+		recordBadLogin(r, p)
+		showSecondFactor(r, token, true)
+		return
+	}
+	if auth.TOTPRequired(p) {
+		var secret = r.FormValue("secret")
+		if step := auth.CheckTOTPCode(secret, code); step != 0 && !LockedOut(p) {
+			codes, hashes := auth.NewRecoveryCodes()
+			r.Transaction(func() {
+				persontotp.Enroll(r, p, secret, hashes, step)
+			})
+			finishLogin(r, p, remember)
+			showEnrolled(r, codes)
+			return
+		}
+		auth.RecordLoginFailure(r)
+		recordBadLogin(r, p)
+		showEnrollment(r, p, token, secret, true)
+		return
+	}
+	// Two-factor authentication is no longer needed for this person.
+	finishLogin(r, p, remember)
+	redirectAfterLogin(r)
+}
+
+// showSecondFactor shows the login page asking for the person's TOTP code.
+func showSecondFactor(r *request.Request, token string, failed bool) {
+	var statusCode = http.StatusOK
+	if failed {
+		statusCode = http.StatusUnprocessableEntity
+	}
+	ui.Page(r, nil, ui.PageOpts{
+		Title:      r.Loc("Login"),
+		Banner:     "Sunnyvale SERV",
+		StatusCode: statusCode,
+	}, func(main *htmlb.Element) {
+		main.A("class=login")
+		main.E("div class=loginBanner").T(r.Loc("Two-Factor Authentication"))
+		form := main.E("form class='form form-centered form-2col loginForm' method=POST action=%s up-target=body up-fail-target=form", loginURL(r))
+		form.E("input type=hidden name=pending value=%s", token)
+		row := form.E("div class=formRow")
+		row.E("label for=loginCode class=formLabel").T(r.Loc("Code"))
+		row.E("input name=code id=loginCode autocomplete=one-time-code autocapitalize=none autofocus")
+		row.E("div class=formHelp").T(r.Loc("Enter the code shown in your authenticator app.  If you don’t have your authenticator app, you can enter one of your recovery codes instead."))
+		form.E("div class='formRow-3col loginSubmit'").
+			E("input type=submit class='sbtn sbtn-primary' value=%s", r.Loc("Log in"))
+		if failed {
+			form.E("div class='formRow-3col loginFailed'").T(r.Loc("That code is not correct. Please try again."))
+		}
+	})
+}
+
+// showEnrollment shows the login page asking the person to set up two-factor
+// authentication, which is required for them.
+func showEnrollment(r *request.Request, p *person.Person, token, secret string, failed bool) {
+	var statusCode = http.StatusOK
+	if failed {
+		statusCode = http.StatusUnprocessableEntity
+	}
+	ui.Page(r, nil, ui.PageOpts{
+		Title:      r.Loc("Login"),
+		Banner:     "Sunnyvale SERV",
+		StatusCode: statusCode,
+	}, func(main *htmlb.Element) {
+		main.A("class=login")
+		main.E("div class=loginBanner").T(r.Loc("Two-Factor Authentication"))
+		main.E("div class=loginExplain").T(r.Loc("Your account requires two-factor authentication.  Please set it up now."))
+		form := main.E("form class='form form-centered form-2col loginForm' method=POST action=%s up-target=body up-fail-target=form", loginURL(r))
+		form.E("input type=hidden name=pending value=%s", token)
+		ShowTOTPSetup(r, form, p.Email(), secret, failed)
+		form.E("div class='formRow-3col loginSubmit'").
+			E("input type=submit class='sbtn sbtn-primary' value=%s", r.Loc("Log in"))
+	})
+}
+
+// showEnrolled shows the person's new recovery codes after they have set up
+// two-factor authentication during login.
+func showEnrolled(r *request.Request, codes []string) {
+	ui.Page(r, nil, ui.PageOpts{
+		Title:  r.Loc("Login"),
+		Banner: "Sunnyvale SERV",
+	}, func(main *htmlb.Element) {
+		main.A("class=login")
+		main.E("div class=loginBanner").T(r.Loc("Two-Factor Authentication"))
+		box := main.E("div class='form form-centered form-2col loginForm'")
+		ShowRecoveryCodes(r, box.E("div class=formRow-3col"), codes)
+		box.E("div class='formRow-3col loginSubmit'").
+			E("a href=%s class='sbtn sbtn-primary'", afterLoginURL(r)).T(r.Loc("Continue"))
+	})
+}
+
+// ShowTOTPSetup adds rows to the form that let the person enroll in two-factor
+// authentication:  a QR code provisioning the secret into their authenticator
+// app, the secret itself for manual entry, and an input for the first code from
+// the app, to confirm that it worked.  account is the name of the account shown
+// in the app (usually the person's email address).
+func ShowTOTPSetup(r *request.Request, form *htmlb.Element, account, secret string, failed bool) {
+	form.E("input type=hidden name=secret value=%s", secret)
+	row := form.E("div class=formRow-3col")
+	row.E("div").T(r.Loc("Scan this QR code with an authenticator app, such as Google Authenticator or Microsoft Authenticator."))
+	if svg, err := qrcode.SVG(totp.URI(auth.TOTPIssuer, account, secret)); err == nil {
+		row.E("div class=loginTOTPQR").R(svg)
+	}
+	row.E("div").T(r.Loc("Or, enter this key into the app manually:"))
+	var groups []string
+	for i := 0; i < len(secret); i += 4 {
+		groups = append(groups, secret[i:min(i+4, len(secret))])
+	}
+	row.E("div class=loginTOTPSecret>%s", strings.Join(groups, " "))
+	row = form.E("div class=formRow")
+	row.E("label for=loginCode class=formLabel").T(r.Loc("Code from App"))
+	row.E("input name=code id=loginCode class=formInput autocomplete=one-time-code inputmode=numeric autocapitalize=none", failed, "autofocus")
+	if failed {
+		row.E("div class=formError").T(r.Loc("That code is not correct. Please try again."))
+	}
+}
+
+// ShowRecoveryCodes shows the person's new recovery codes, with instructions.
+func ShowRecoveryCodes(r *request.Request, parent *htmlb.Element, codes []string) {
+	parent.E("div").T(r.Loc("These are your recovery codes.  If you lose access to your authenticator app, you can log in with one of these codes instead.  Each code works only once.  Please print them or write them down, and keep them somewhere safe.  They will not be shown again."))
+	list := parent.E("ul class=loginRecoveryCodes")
+	for _, code := range codes {
+		list.E("li>%s", code)
+	}
+}
@@ -34,7 +34,7 @@ func HandleVolunteerHours(r *request.Request, token string) {
 		errpage.NotFound(r, nil)
 		return
 	}
-	cy, cm := currentPeriod()
+	cy, cm := CurrentPeriod()
 	handleCommon(r, p, p, cy, cm, cy, cm, nil)
 }
 
@@ -56,12 +56,12 @@ func HandleActivity(r *request.Request, pidstr, period string) {
 		errpage.NotFound(r, user)
 		return
 	}
-	if user.ID() != p.ID() && !user.HasPrivLevel(0, enum.PrivLeader) {
+	if user.ID() != p.ID() && !auth.Can(user, enum.PermEventAttendance, 0) {
 		errpage.Forbidden(r, user)
 		return
 	}
 	// Determine the period to be edited/viewed.
-	cy, cm = currentPeriod()
+	cy, cm = CurrentPeriod()
 	if period == "current" {
 		y, m = cy, cm
 	} else if y, m = parsePeriod(period); y == 0 {
@@ -83,7 +83,7 @@ func HandleActivity(r *request.Request, pidstr, period string) {
 	// If the period is a month prior to the current one, switch to year
 	// view of the year containing it.  Special exception for webmaster, who
 	// can add ?edit=true to edit past months.
-	if m != 0 && (y < cy || (y == cy && m < cm)) && (r.FormValue("edit") == "" || !user.IsWebmaster()) {
+	if m != 0 && (y < cy || (y == cy && m < cm)) && (r.FormValue("edit") == "" || !auth.Can(user, enum.PermHoursEditPast, 0)) {
 		http.Redirect(r, r.Request, fmt.Sprintf("/people/%d/activity/%d", p.ID(), y), http.StatusSeeOther)
 		return
 	}
@@ -96,7 +96,9 @@ func HandleActivity(r *request.Request, pidstr, period string) {
 	handleCommon(r, user, p, cy, cm, y, m, tabs)
 }
 
-func currentPeriod() (year, month int) {
+// CurrentPeriod returns the earliest month for which volunteer hours can still
+// be recorded.  Through the 10th of each month, that's the previous month.
+func CurrentPeriod() (year, month int) {
 	now := time.Now()
 	if now.Day() > 10 {
 		return now.Year(), int(now.Month())
@@ -175,7 +177,7 @@ func saveHours(r *request.Request, user, p *person.Person, y, m int) {
 			if t.Flags()&task.RecordHours == 0 {
 				return
 			}
-			if user.ID() != p.ID() && !user.HasPrivLevel(t.Org(), enum.PrivLeader) {
+			if user.ID() != p.ID() && !auth.Can(user, enum.PermEventAttendance, t.Org()) {
 				return
 			}
 			want, ok := ui.SHoursValue(r.FormValue(fmt.Sprintf("t%d", t.ID())))
@@ -293,7 +295,7 @@ func showMonthView(r *request.Request, main *htmlb.Element, user, p *person.Pers
 			if t.Flags()&task.RecordHours == 0 {
 				return
 			}
-			editable := user.ID() == p.ID() || user.HasPrivLevel(t.Org(), enum.PrivLeader)
+			editable := user.ID() == p.ID() || auth.Can(user, enum.PermEventAttendance, t.Org())
 			minutes, flags := taskperson.Get(r, t.ID(), p.ID())
 			total += minutes
 			grid.E("s-hours class=activityHours name=t%d value=%s", t.ID(), ui.MinutesToHours(minutes), !editable, "disabled")
@@ -0,0 +1,186 @@
+// Package peopleavail handles the /people/available page, which helps leaders
+// find out whom they can call on to fill a need at a particular time.
+package peopleavail
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/availability"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personrole"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/store/shiftperson"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const personFields = person.FID | person.FSortName | person.FEmail | person.FCellPhone | person.FHomePhone | person.FWorkPhone | person.CanViewTargetFields
+
+type candidate struct {
+	*person.Person
+	viewLevel person.ViewLevel
+	rank      availability.Rank
+}
+
+// Handle handles GET /people/available requests.
+func Handle(r *request.Request) {
+	var (
+		user        *person.Person
+		focus       *role.Role
+		roleOptions []*role.Role
+		date        string
+		from        string
+		to          string
+		start       time.Time
+		end         time.Time
+		errmsg      string
+	)
+	if user = auth.SessionUser(r, person.CanViewViewerFields, true); user == nil {
+		return
+	}
+	if !auth.Can(user, enum.PermPersonEdit, 0) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	role.All(r, role.FID|role.FName|role.FOrg|role.FFlags, func(rl *role.Role) {
+		if user.HasPrivLevel(rl.Org(), enum.PrivMember) && rl.Flags()&role.Filter != 0 {
+			clone := *rl
+			roleOptions = append(roleOptions, &clone)
+		}
+	})
+	slices.SortFunc(roleOptions, func(a, b *role.Role) int { return strings.Compare(a.Name(), b.Name()) })
+	if rid := role.ID(util.ParseID(r.FormValue("role"))); rid != 0 {
+		if idx := slices.IndexFunc(roleOptions, func(rl *role.Role) bool { return rl.ID() == rid }); idx >= 0 {
+			focus = roleOptions[idx]
+		}
+	}
+	date, from, to = r.FormValue("date"), r.FormValue("from"), r.FormValue("to")
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	if focus != nil {
+		start, end, errmsg = parseRange(date, from, to)
+	}
+	ui.Page(r, user, ui.PageOpts{
+		Title:    "Available People",
+		MenuItem: "people",
+		Tabs: []ui.PageTab{
+			{Name: r.Loc("List"), URL: "/people", Target: "main"},
+			{Name: r.Loc("Map"), URL: "/people/map", Target: "main"},
+			{Name: r.Loc("Available"), URL: "/people/available", Target: "main", Active: true},
+		},
+	}, func(main *htmlb.Element) {
+		main.A("class=peopleavail")
+		form := main.E("form class=peopleavailForm up-target=main")
+		form.E("input type=date name=date value=%s", date)
+		form.E("input type=time name=from value=%s", from)
+		form.E("span>to")
+		form.E("input type=time name=to value=%s", to)
+		sel := form.E("select name=role")
+		sel.E("option value=0", focus == nil, "selected>(select role)")
+		for _, rl := range roleOptions {
+			sel.E("option value=%d", rl.ID(), focus != nil && focus.ID() == rl.ID(), "selected").T(rl.Name())
+		}
+		form.E("input type=submit class='sbtn sbtn-small sbtn-primary' value=Search")
+		switch {
+		case focus == nil:
+			main.E("div class=peopleavailHelp>Select a date, time range, and role to find people who are available to help.")
+		case errmsg != "":
+			main.E("div class=peopleavailError").T(errmsg)
+		default:
+			showCandidates(r, main, findCandidates(r, user, focus, start, end))
+		}
+	})
+}
+
+// parseRange parses the date and time range from the search form.  If the
+// ending time is not after the starting time, the range is assumed to cross
+// midnight.
+func parseRange(date, from, to string) (start, end time.Time, errmsg string) {
+	var err error
+
+	if start, err = time.ParseInLocation("2006-01-02 15:04", date+" "+from, time.Local); err != nil {
+		return start, end, "Please specify a valid date and starting time."
+	}
+	if end, err = time.ParseInLocation("2006-01-02 15:04", date+" "+to, time.Local); err != nil {
+		return start, end, "Please specify a valid ending time."
+	}
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, ""
+}
+
+// findCandidates returns the people holding the focus role who are not
+// disabled, not signed up for an overlapping shift, and not blacked out for
+// the requested time range.  They are returned in order by availability rank
+// and then by name.
+func findCandidates(r *request.Request, user *person.Person, focus *role.Role, start, end time.Time) (candidates []*candidate) {
+	var people []*person.Person
+
+	personrole.PeopleForRole(r, focus.ID(), personFields, func(p *person.Person, _ bool) {
+		clone := *p
+		people = append(people, &clone)
+	})
+	sstart, send := start.Format("2006-01-02T15:04"), end.Format("2006-01-02T15:04")
+	for _, p := range people {
+		viewLevel := user.CanView(p)
+		if viewLevel == person.ViewNone {
+			continue
+		}
+		if held, _ := personrole.PersonHasRole(r, p.ID(), role.Disabled); held {
+			continue
+		}
+		if shiftperson.OverlappingSignup(r, p.ID(), sstart, send) {
+			continue
+		}
+		rank := availability.ForPerson(r, p.ID()).Rank(start, end)
+		if rank == availability.BlackedOut {
+			continue
+		}
+		candidates = append(candidates, &candidate{p, viewLevel, rank})
+	}
+	slices.SortStableFunc(candidates, func(a, b *candidate) int {
+		if a.rank != b.rank {
+			return int(b.rank) - int(a.rank)
+		}
+		return strings.Compare(a.SortName(), b.SortName())
+	})
+	return candidates
+}
+
+func showCandidates(r *request.Request, main *htmlb.Element, candidates []*candidate) {
+	if len(candidates) == 0 {
+		main.E("div class=peopleavailHelp>No one with this role is available at this time.")
+		return
+	}
+	grid := main.E("div class=peopleavailGrid")
+	for i, c := range candidates {
+		if i == 0 || candidates[i-1].rank != c.rank {
+			grid.E("div class='peopleavailRank peopleavailRank-%d'", c.rank).T(c.rank.String())
+		}
+		grid.E("div class=peopleavailName").E("a href=/people/%d up-target=main", c.ID()).T(c.SortName())
+		contact := grid.E("div class=peopleavailContact")
+		if c.viewLevel >= person.ViewWorkContact && c.Email() != "" {
+			contact.E("a href=mailto:%s target=_blank", c.Email()).E("s-icon icon=email")
+		}
+		if c.viewLevel == person.ViewFull && c.CellPhone() != "" {
+			contact.E("a href=tel:%s target=_blank", c.CellPhone()).E("s-icon icon=phone")
+			contact.E("a href=sms:%s target=_blank", c.CellPhone()).E("s-icon icon=sms")
+			contact.E("span>%s", c.CellPhone())
+		} else if c.viewLevel == person.ViewFull && c.HomePhone() != "" {
+			contact.E("a href=tel:%s target=_blank", c.HomePhone()).E("s-icon icon=phone")
+			contact.E("span>%s", c.HomePhone())
+		} else if c.viewLevel >= person.ViewWorkContact && c.WorkPhone() != "" {
+			contact.E("a href=tel:%s target=_blank", c.WorkPhone()).E("s-icon icon=phone")
+			contact.E("span>%s", c.WorkPhone())
+		}
+	}
+}
@@ -33,7 +33,7 @@ func HandleNewUser(r *request.Request) {
 	if !auth.CheckCSRF(r, user) {
 		return
 	}
-	if !user.HasPrivLevel(0, enum.PrivLeader) {
+	if !auth.Can(user, enum.PermPersonAdd, 0) {
 		errpage.Forbidden(r, user)
 		return
 	}
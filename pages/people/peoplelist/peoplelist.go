@@ -89,6 +89,9 @@ func Handle(r *request.Request) {
 			{Name: r.Loc("Map"), URL: "/people/map", Target: "main"},
 		},
 	}
+	if auth.Can(user, enum.PermPersonEdit, 0) {
+		opts.Tabs = append(opts.Tabs, ui.PageTab{Name: r.Loc("Available"), URL: "/people/available", Target: "main"})
+	}
 	ui.Page(r, user, opts, func(main *htmlb.Element) {
 		main.A("class=peoplelist")
 		listControls(r, main, focus, roleOptions, currsort)
@@ -116,7 +119,7 @@ func Handle(r *request.Request) {
 		form.E("input type=hidden name=sort value=%s", currsort)
 		form.E("input type=hidden name=format value=csv")
 		form.E("input type=submit value=Export class='sbtn sbtn-primary sbtn-small'")
-		if user.HasPrivLevel(0, enum.PrivLeader) {
+		if auth.Can(user, enum.PermPersonAdd, 0) {
 			form.E("a href=/people/newuser class='sbtn sbtn-primary sbtn-small peoplelistNewUser' up-layer=new up-size=grow up-dismissable=key up-history=false up->New User")
 		}
 	})
@@ -358,7 +361,8 @@ func showPerson(r *request.Request, grid *htmlb.Element, p *personData, showCall
 
 // showPersonDetails renders the details popup for a person.
 func showPersonDetails(r *request.Request, box *htmlb.Element, p *personData) {
-	// Show the name and call sign.
+	// Show the photo, name, and call sign.
+	ui.PersonPhoto(box, p.Person, "peoplelistDetailsPhoto")
 	n := box.E("div class=peoplelistDetailsName>%s", p.InformalName())
 	if p.CallSign() != "" {
 		n.E("span class=peoplelistDetailsCall>%s", p.CallSign())
@@ -129,6 +129,9 @@ func Handle(r *request.Request) {
 			{Name: r.Loc("Map"), URL: "/people/map", Target: "main", Active: true},
 		},
 	}
+	if auth.Can(user, enum.PermPersonEdit, 0) {
+		opts.Tabs = append(opts.Tabs, ui.PageTab{Name: r.Loc("Available"), URL: "/people/available", Target: "main"})
+	}
 	ui.Page(r, user, opts, func(main *htmlb.Element) {
 		main.A("class=peoplemap")
 		mapControls(r, user, main, focus, home, work)
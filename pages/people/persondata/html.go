@@ -0,0 +1,188 @@
+package persondata
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const stylesheet = `body{font-family:sans-serif;margin:1rem 2rem}h2{margin-top:2rem;border-bottom:1px solid #888}table{border-collapse:collapse}th,td{text-align:left;vertical-align:top;padding:0.125rem 0.75rem 0.125rem 0}.none{color:#888}`
+
+// renderHTML renders the human-readable form of the person's data.
+func renderHTML(r *request.Request, w io.Writer, data *personData) {
+	html := htmlb.HTML(w).Attr("lang=%s", r.Language)
+	head := html.E("head")
+	head.E("meta charset=utf-8")
+	head.E("title").TF(r.Loc("SERV Data for %s"), data.Profile.InformalName)
+	head.E("style").R(stylesheet)
+	body := html.E("body")
+	body.E("h1").TF(r.Loc("SERV Data for %s"), data.Profile.InformalName)
+	body.E("p").TF(r.Loc("Generated %s by SunnyvaleSERV.org."), data.Generated)
+
+	body.E("h2").R(r.Loc("Profile"))
+	table := body.E("table")
+	row := func(label, value string) {
+		if value != "" {
+			tr := table.E("tr")
+			tr.E("th").R(r.Loc(label))
+			tr.E("td").T(value)
+		}
+	}
+	row("Name", data.Profile.InformalName)
+	row("Formal Name", data.Profile.FormalName)
+	row("Sort Name", data.Profile.SortName)
+	row("Call Sign", data.Profile.CallSign)
+	row("Pronouns", data.Profile.Pronouns)
+	row("Email", data.Profile.Email)
+	row("Alt. Email", data.Profile.Email2)
+	row("Cell Phone", data.Profile.CellPhone)
+	row("Home Phone", data.Profile.HomePhone)
+	row("Work Phone", data.Profile.WorkPhone)
+	row("Birthdate", data.Profile.Birthdate)
+	row("Medical Notes", data.Profile.MedicalNotes)
+	if data.Profile.VolgisticsID != 0 {
+		row("Volgistics ID", fmt.Sprint(data.Profile.VolgisticsID))
+	}
+	for _, a := range data.Addresses {
+		row(a.Type+" Address", a.Address)
+	}
+	for _, ec := range data.EmergencyContacts {
+		row("Emergency Contact", strings.Join(nonEmpty(ec.Name, ec.Relationship, ec.HomePhone, ec.CellPhone), ", "))
+	}
+	for _, b := range data.BackgroundChecks {
+		row(b.Type+" Background Check", strings.Join(nonEmpty(b.Cleared, b.NLI), " – "))
+	}
+	for _, d := range data.DSWRegistrations {
+		row("DSW "+d.Classification, strings.Join(nonEmpty(d.Registered, d.Expiration), " – "))
+	}
+
+	showList(r, body, "Availability", data.Availability)
+	showList(r, body, "Unavailable", data.Unavailable)
+	showList(r, body, "Roles", data.Roles)
+	showList(r, body, "Subscriptions", data.Subscriptions)
+
+	body.E("h2").R(r.Loc("Class Registrations"))
+	if len(data.ClassRegistrations) == 0 {
+		body.E("p class=none").R(r.Loc("None."))
+	} else {
+		table = body.E("table")
+		for _, cr := range data.ClassRegistrations {
+			tr := table.E("tr")
+			tr.E("td").T(cr.Start)
+			tr.E("td").T(cr.Class)
+			tr.E("td").T(strings.Join(nonEmpty(cr.FirstName+" "+cr.LastName, cr.Email, cr.CellPhone), ", "))
+			if cr.Waitlisted {
+				tr.E("td").R(r.Loc("waitlisted"))
+			} else {
+				tr.E("td")
+			}
+		}
+	}
+
+	body.E("h2").R(r.Loc("Certificates"))
+	if len(data.Certificates) == 0 {
+		body.E("p class=none").R(r.Loc("None."))
+	} else {
+		table = body.E("table")
+		for _, c := range data.Certificates {
+			tr := table.E("tr")
+			tr.E("td").T(c.Completed)
+			tr.E("td").T(c.Course)
+			tr.E("td").T(c.Name)
+			tr.E("td").TF("#%s", c.Number)
+		}
+	}
+
+	body.E("h2").R(r.Loc("Shift Signups"))
+	if len(data.Signups) == 0 {
+		body.E("p class=none").R(r.Loc("None."))
+	} else {
+		table = body.E("table")
+		for _, s := range data.Signups {
+			tr := table.E("tr")
+			tr.E("td").T(strings.Replace(s.Start, "T", " ", 1) + "–" + s.End[11:])
+			tr.E("td").T(strings.Join(nonEmpty(s.Event, s.Task), ": "))
+			if s.Declined {
+				tr.E("td").R(r.Loc("declined"))
+			} else {
+				tr.E("td")
+			}
+		}
+	}
+
+	body.E("h2").R(r.Loc("Attendance and Hours"))
+	if len(data.Attendance) == 0 {
+		body.E("p class=none").R(r.Loc("None."))
+	} else {
+		table = body.E("table")
+		for _, a := range data.Attendance {
+			tr := table.E("tr")
+			tr.E("td").T(a.Date)
+			tr.E("td").T(strings.Join(nonEmpty(a.Event, a.Task), ": "))
+			if a.Hours != 0 {
+				tr.E("td").TF("%.1f", a.Hours)
+			} else {
+				tr.E("td")
+			}
+			if a.Attended {
+				tr.E("td").R(r.Loc("attended"))
+			} else {
+				tr.E("td")
+			}
+		}
+	}
+
+	body.E("h2").R(r.Loc("Text Messages"))
+	if len(data.TextMessages) == 0 {
+		body.E("p class=none").R(r.Loc("None."))
+	} else {
+		table = body.E("table")
+		for _, t := range data.TextMessages {
+			tr := table.E("tr")
+			tr.E("td").T(t.Timestamp)
+			tr.E("td").T(t.Message)
+			for i := len(t.Replies) - 1; i >= 0; i-- {
+				tr = table.E("tr")
+				tr.E("td").T(t.Replies[i].Timestamp)
+				tr.E("td").TF("%s %s", r.Loc("Reply:"), t.Replies[i].Reply)
+			}
+		}
+	}
+
+	body.E("h2").R(r.Loc("Notes"))
+	if len(data.Notes) == 0 {
+		body.E("p class=none").R(r.Loc("None."))
+	} else {
+		table = body.E("table")
+		for _, n := range data.Notes {
+			tr := table.E("tr")
+			tr.E("td").T(n.Date)
+			tr.E("td").T(n.Note)
+		}
+	}
+	html.Close()
+}
+
+func showList(r *request.Request, body *htmlb.Element, heading string, items []string) {
+	body.E("h2").R(r.Loc(heading))
+	if len(items) == 0 {
+		body.E("p class=none").R(r.Loc("None."))
+		return
+	}
+	ul := body.E("ul")
+	for _, item := range items {
+		ul.E("li").T(item)
+	}
+}
+
+func nonEmpty(values ...string) (list []string) {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,353 @@
+// Package persondata handles /people/$id/data requests, which download a zip
+// file containing all of the data we have about a person, in both JSON and
+// human-readable HTML forms.
+package persondata
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/availability"
+	"sunnyvaleserv.org/portal/store/certificate"
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/classreg"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/list"
+	"sunnyvaleserv.org/portal/store/listperson"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personrole"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/store/shift"
+	"sunnyvaleserv.org/portal/store/shiftperson"
+	"sunnyvaleserv.org/portal/store/task"
+	"sunnyvaleserv.org/portal/store/taskperson"
+	"sunnyvaleserv.org/portal/store/textmsg"
+	"sunnyvaleserv.org/portal/store/textrecip"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const personFields = person.FID | person.FVolgisticsID | person.FInformalName | person.FFormalName | person.FSortName | person.FCallSign | person.FPronouns | person.FEmail | person.FEmail2 | person.FCellPhone | person.FHomePhone | person.FWorkPhone | person.FBirthdate | person.FFlags | person.FMedicalNotes | person.FAddresses | person.FBGChecks | person.FDSWRegistrations | person.FNotes | person.FEmContacts | person.FPrivLevels
+
+// CanDownload returns whether the user can download the data of the person:
+// people can download their own data, and admin leaders can download anyone's
+// data in response to a formal request.
+func CanDownload(user, p *person.Person) bool {
+	return user.ID() == p.ID() || auth.Can(user, enum.PermPersonData, 0)
+}
+
+// Get handles GET /people/$id/data requests.
+func Get(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		p    *person.Person
+		data *personData
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), personFields); p == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if !CanDownload(user, p) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	data = gatherData(r, p)
+	r.Header().Set("Content-Type", "application/zip")
+	r.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="serv-data-%d.zip"`, p.ID()))
+	r.Header().Set("Cache-Control", "no-store")
+	zw := zip.NewWriter(r)
+	writeFile(zw, "data.json", func(w io.Writer) {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(data)
+	})
+	writeFile(zw, "data.html", func(w io.Writer) {
+		renderHTML(r, w, data)
+	})
+	zw.Close()
+}
+
+func writeFile(zw *zip.Writer, name string, fn func(io.Writer)) {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		panic(err)
+	}
+	fn(w)
+}
+
+type personData struct {
+	Generated          string           `json:"generated"`
+	Profile            profileData      `json:"profile"`
+	Addresses          []addressData    `json:"addresses"`
+	EmergencyContacts  []emContactData  `json:"emergencyContacts"`
+	BackgroundChecks   []bgCheckData    `json:"backgroundChecks"`
+	DSWRegistrations   []dswData        `json:"dswRegistrations"`
+	Availability       []string         `json:"availability"`
+	Unavailable        []string         `json:"unavailable"`
+	Roles              []string         `json:"roles"`
+	Subscriptions      []string         `json:"subscriptions"`
+	ClassRegistrations []classRegData   `json:"classRegistrations"`
+	Certificates       []certData       `json:"certificates"`
+	Signups            []signupData     `json:"signups"`
+	Attendance         []attendanceData `json:"attendance"`
+	TextMessages       []textData       `json:"textMessages"`
+	Notes              []noteData       `json:"notes"`
+}
+type profileData struct {
+	ID             int    `json:"id"`
+	InformalName   string `json:"informalName"`
+	FormalName     string `json:"formalName"`
+	SortName       string `json:"sortName"`
+	CallSign       string `json:"callSign,omitempty"`
+	Pronouns       string `json:"pronouns,omitempty"`
+	Email          string `json:"email,omitempty"`
+	Email2         string `json:"email2,omitempty"`
+	CellPhone      string `json:"cellPhone,omitempty"`
+	HomePhone      string `json:"homePhone,omitempty"`
+	WorkPhone      string `json:"workPhone,omitempty"`
+	Birthdate      string `json:"birthdate,omitempty"`
+	MedicalNotes   string `json:"medicalNotes,omitempty"`
+	VolgisticsID   uint   `json:"volgisticsID,omitempty"`
+	NoEmail        bool   `json:"noEmail"`
+	NoText         bool   `json:"noText"`
+	VisibleToOther bool   `json:"visibleToAllMembers"`
+}
+type addressData struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`
+}
+type emContactData struct {
+	Name         string `json:"name"`
+	Relationship string `json:"relationship"`
+	HomePhone    string `json:"homePhone,omitempty"`
+	CellPhone    string `json:"cellPhone,omitempty"`
+}
+type bgCheckData struct {
+	Type    string `json:"type"`
+	Cleared string `json:"cleared,omitempty"`
+	NLI     string `json:"nli,omitempty"`
+	Assumed bool   `json:"assumed,omitempty"`
+}
+type dswData struct {
+	Classification string `json:"classification"`
+	Registered     string `json:"registered"`
+	Expiration     string `json:"expiration,omitempty"`
+}
+type classRegData struct {
+	Class        string `json:"class"`
+	Start        string `json:"start"`
+	FirstName    string `json:"firstName"`
+	LastName     string `json:"lastName"`
+	Email        string `json:"email,omitempty"`
+	CellPhone    string `json:"cellPhone,omitempty"`
+	Waitlisted   bool   `json:"waitlisted"`
+	RegisteredBy string `json:"registeredBy,omitempty"`
+}
+type certData struct {
+	Number    string `json:"number"`
+	Name      string `json:"name"`
+	Course    string `json:"course"`
+	Completed string `json:"completed"`
+	Issued    string `json:"issued"`
+}
+type signupData struct {
+	Event    string `json:"event"`
+	Task     string `json:"task,omitempty"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Declined bool   `json:"declined"`
+}
+type attendanceData struct {
+	Date     string  `json:"date"`
+	Event    string  `json:"event"`
+	Task     string  `json:"task,omitempty"`
+	Hours    float64 `json:"hours"`
+	Attended bool    `json:"attended"`
+	Credited bool    `json:"credited"`
+}
+type textData struct {
+	Timestamp string      `json:"timestamp"`
+	Message   string      `json:"message"`
+	Number    string      `json:"number,omitempty"`
+	Status    string      `json:"status,omitempty"`
+	Replies   []replyData `json:"replies,omitempty"`
+}
+type replyData struct {
+	Timestamp string `json:"timestamp"`
+	Reply     string `json:"reply"`
+}
+type noteData struct {
+	Date string `json:"date"`
+	Note string `json:"note"`
+}
+
+// gatherData collects all of the data about the person.
+func gatherData(r *request.Request, p *person.Person) (data *personData) {
+	data = &personData{
+		Generated: time.Now().Format(time.RFC3339),
+		Profile: profileData{
+			ID:             int(p.ID()),
+			InformalName:   p.InformalName(),
+			FormalName:     p.FormalName(),
+			SortName:       p.SortName(),
+			CallSign:       p.CallSign(),
+			Pronouns:       p.Pronouns(),
+			Email:          p.Email(),
+			Email2:         p.Email2(),
+			CellPhone:      p.CellPhone(),
+			HomePhone:      p.HomePhone(),
+			WorkPhone:      p.WorkPhone(),
+			Birthdate:      p.Birthdate(),
+			MedicalNotes:   p.MedicalNotes(),
+			VolgisticsID:   p.VolgisticsID(),
+			NoEmail:        p.Flags()&person.NoEmail != 0,
+			NoText:         p.Flags()&person.NoText != 0,
+			VisibleToOther: p.Flags()&person.VisibleToAll != 0,
+		},
+	}
+	addAddress := func(atype string, a *person.Address) {
+		if a == nil {
+			return
+		}
+		if a.SameAsHome {
+			data.Addresses = append(data.Addresses, addressData{atype, "(same as home)"})
+		} else {
+			data.Addresses = append(data.Addresses, addressData{atype, a.Address})
+		}
+	}
+	addAddress("Home", p.Addresses().Home)
+	addAddress("Work", p.Addresses().Work)
+	addAddress("Mail", p.Addresses().Mail)
+	for _, ec := range p.EmContacts() {
+		data.EmergencyContacts = append(data.EmergencyContacts, emContactData{ec.Name, ec.Relationship, ec.HomePhone, ec.CellPhone})
+	}
+	addBGCheck := func(btype string, b *person.BGCheck) {
+		if b != nil {
+			data.BackgroundChecks = append(data.BackgroundChecks, bgCheckData{btype, formatDate(b.Cleared), formatDate(b.NLI), b.Assumed})
+		}
+	}
+	addBGCheck("DOJ", p.BGChecks().DOJ)
+	addBGCheck("FBI", p.BGChecks().FBI)
+	addBGCheck("PHS", p.BGChecks().PHS)
+	addDSW := func(class string, d *person.DSWRegistration) {
+		if d != nil {
+			data.DSWRegistrations = append(data.DSWRegistrations, dswData{class, formatDate(d.Registered), formatDate(d.Expiration)})
+		}
+	}
+	addDSW("CERT", p.DSWRegistrations().CERT)
+	addDSW("Communications", p.DSWRegistrations().Communications)
+	avail := availability.ForPerson(r, p.ID())
+	for _, w := range avail.Windows {
+		data.Availability = append(data.Availability, fmt.Sprintf("%s %s-%s", w.Weekday, w.Start, w.End))
+	}
+	for _, b := range avail.Blackouts {
+		data.Unavailable = append(data.Unavailable, b.Start+" - "+b.End)
+	}
+	personrole.RolesForPerson(r, p.ID(), role.FName, func(rl *role.Role, explicit bool) {
+		if explicit {
+			data.Roles = append(data.Roles, rl.Name())
+		}
+	})
+	listperson.SubscriptionsByPerson(r, p.ID(), func(l *list.List) {
+		switch l.Type {
+		case list.Email:
+			data.Subscriptions = append(data.Subscriptions, l.Name+"@SunnyvaleSERV.org")
+		case list.SMS:
+			data.Subscriptions = append(data.Subscriptions, "SMS: "+l.Name)
+		}
+	})
+	gatherClassRegs(r, p, data)
+	certificate.AllForPerson(r, p.ID(), func(c *certificate.Certificate) {
+		data.Certificates = append(data.Certificates, certData{c.Number(), c.Name, c.Course, c.Completed, c.Issued})
+	})
+	shiftperson.AllForPerson(r, p.ID(), event.FName, task.FName, shift.FStart|shift.FEnd, func(e *event.Event, t *task.Task, s *shift.Shift, signedUp int) {
+		data.Signups = append(data.Signups, signupData{e.Name(), t.Name(), s.Start(), s.End(), signedUp < 0})
+	})
+	taskperson.AllBetween(r, "0000-00-00", "9999-99-99", p.ID(), event.FName|event.FStart, task.FName, func(e *event.Event, t *task.Task, minutes uint, flags taskperson.Flag) {
+		data.Attendance = append(data.Attendance, attendanceData{
+			e.Start()[:10], e.Name(), t.Name(), float64(minutes) / 60,
+			flags&taskperson.Attended != 0, flags&taskperson.Credited != 0,
+		})
+	})
+	gatherTexts(r, p, data)
+	for _, n := range p.Notes() {
+		if noteVisibleToPerson(p, n) {
+			data.Notes = append(data.Notes, noteData{formatDate(n.Date), n.Note})
+		}
+	}
+	return data
+}
+
+func gatherClassRegs(r *request.Request, p *person.Person, data *personData) {
+	var regs []*classreg.ClassReg
+
+	classreg.AllForPerson(r, p.ID(), classreg.FClass|classreg.FPerson|classreg.FRegisteredBy|classreg.FFirstName|classreg.FLastName|classreg.FEmail|classreg.FCellPhone|classreg.FWaitlist, func(cr *classreg.ClassReg) {
+		regs = append(regs, cr.Clone())
+	})
+	for _, cr := range regs {
+		var crd = classRegData{FirstName: cr.FirstName(), LastName: cr.LastName(), Email: cr.Email(), CellPhone: cr.CellPhone(), Waitlisted: cr.Waitlist()}
+		if c := class.WithID(r, cr.Class(), class.FType|class.FStart); c != nil {
+			crd.Class, crd.Start = c.Type().String(), c.Start()
+		}
+		if cr.RegisteredBy() != p.ID() {
+			if rb := person.WithID(r, cr.RegisteredBy(), person.FInformalName); rb != nil {
+				crd.RegisteredBy = rb.InformalName()
+			}
+		} else if cr.Person() != p.ID() {
+			// This is a registration that the person made on behalf
+			// of someone else.
+			crd.RegisteredBy = p.InformalName()
+		}
+		data.ClassRegistrations = append(data.ClassRegistrations, crd)
+	}
+}
+
+func gatherTexts(r *request.Request, p *person.Person, data *personData) {
+	var ids []textmsg.ID
+
+	textrecip.AllTextsToRecipient(r, p.ID(), textmsg.FID|textmsg.FTimestamp|textmsg.FMessage, func(t *textmsg.TextMessage, number, status string, _ time.Time) {
+		ids = append(ids, t.ID())
+		data.TextMessages = append(data.TextMessages, textData{
+			Timestamp: t.Timestamp().Format("2006-01-02 15:04:05"),
+			Message:   t.Message(),
+			Number:    number,
+			Status:    status,
+		})
+	})
+	for i, id := range ids {
+		textrecip.AllRepliesFromRecipient(r, id, p.ID(), func(reply string, timestamp time.Time) bool {
+			data.TextMessages[i].Replies = append(data.TextMessages[i].Replies, replyData{timestamp.Format("2006-01-02 15:04:05"), reply})
+			return true
+		})
+	}
+}
+
+// noteVisibleToPerson returns whether the note would be visible to the person
+// about whom it was written, using the same rules as the person view page.
+func noteVisibleToPerson(p *person.Person, n *person.Note) bool {
+	switch n.Visibility {
+	case person.NoteVisibleToWebmaster:
+		return p.IsWebmaster()
+	case person.NoteVisibleToAdmins:
+		return p.IsAdminLeader()
+	case person.NoteVisibleToLeaders:
+		return p.HasPrivLevel(0, enum.PrivLeader)
+	}
+	return true
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,132 @@
+package personedit
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/apitoken"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const apiTokensPersonFields = person.FID | person.FInformalName
+
+// HandleAPITokens handles requests for /people/$id/edapitokens.  People can
+// create and revoke API tokens for their own accounts.  Webmasters can see and
+// revoke the tokens of anyone, but cannot create them.
+func HandleAPITokens(r *request.Request, idstr string) {
+	var (
+		user     *person.Person
+		p        *person.Person
+		name     string
+		scopes   = make(map[apitoken.Scope]bool)
+		newToken string
+		addErr   string
+	)
+	if user = auth.SessionUser(r, person.FInformalName, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if auth.Impersonating(r) { // credentials can't be changed while impersonating
+		errpage.Forbidden(r, user)
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), apiTokensPersonFields); p == nil || p.ID() == person.AdminID {
+		errpage.NotFound(r, user)
+		return
+	}
+	if user.ID() != p.ID() && !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if r.Method == http.MethodPost {
+		if t := apitoken.WithID(r, apitoken.ID(util.ParseID(r.FormValue("revoke")))); t != nil && t.Person == p.ID() {
+			r.Transaction(func() {
+				t.Delete(r, p)
+			})
+		} else if r.FormValue("add") != "" && user.ID() == p.ID() {
+			var t apitoken.Token
+
+			for _, s := range r.Form["scope"] {
+				if scope := apitoken.Scope(s); scope.Valid() && !scopes[scope] {
+					scopes[scope] = true
+					t.Scopes = append(t.Scopes, scope)
+				}
+			}
+			if name = strings.TrimSpace(r.FormValue("name")); name == "" {
+				addErr = r.Loc("Please give this token a name.")
+			} else if len(t.Scopes) == 0 {
+				addErr = r.Loc("Please select at least one permission for this token.")
+			} else {
+				t.Name, t.Created = name, time.Now()
+				newToken, t.Hash = auth.NewAPIToken()
+				r.Transaction(func() {
+					apitoken.Add(r, p, &t)
+				})
+				name, scopes = "", make(map[apitoken.Scope]bool)
+			}
+		}
+	}
+	r.HTMLNoCache()
+	if addErr != "" {
+		r.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	html := htmlb.HTML(r)
+	defer html.Close()
+	form := html.E("form class='form form-2col personeditAPITokens' method=POST up-main up-target=.personeditAPITokens")
+	form.E("div class='formTitle formTitle-primary'").R(r.Loc("API Tokens"))
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	if user.ID() == p.ID() {
+		form.E("div class=formRow-3col").R(r.Loc("An API token lets a program you write, or another service you use, access this site on your behalf.  A token can do only what you could do yourself, and only what you allow it to.  Treat it like a password."))
+	}
+	if newToken != "" {
+		row := form.E("div class='formRow-3col personeditAPITokensNew'")
+		row.E("div").R(r.Loc("Your new token is shown below.  Copy it now; it will not be shown again."))
+		row.E("input class='formInput personeditAPITokensValue' readonly value=%s", newToken)
+	}
+	if user.ID() == p.ID() {
+		row := form.E("div class=formRow")
+		row.E("label for=personeditAPITokensName").R(r.Loc("New Token"))
+		row.E("input id=personeditAPITokensName name=name class=formInput placeholder=%s value=%s", r.Loc("What the token is for"), name)
+		row = form.E("div class=formRow")
+		row.E("label").R(r.Loc("Permissions"))
+		boxes := row.E("div class=personeditAPITokensScopeList")
+		for _, s := range apitoken.AllScopes {
+			boxes.E("div").E("input type=checkbox class=s-check name=scope value=%s label=%s", s, r.Loc(s.Label()), scopes[s], "checked")
+		}
+		if addErr != "" {
+			row.E("div class=formError>%s", addErr)
+		}
+		// The create button comes before the revoke buttons, so that it
+		// is the one pressing Enter activates.
+		form.E("div class=formRow-3col").E("button type=submit name=add value=1 class='sbtn sbtn-small sbtn-primary'").R(r.Loc("Create Token"))
+	}
+	list := form.E("div class='formRow-3col personeditAPITokensList'")
+	var count int
+	apitoken.AllForPerson(r, p.ID(), func(t *apitoken.Token) {
+		count++
+		row := list.E("div class=personeditAPITokensItem")
+		info := row.E("div class=personeditAPITokensInfo")
+		info.E("div class=personeditAPITokensName>%s", t.Name)
+		labels := make([]string, len(t.Scopes))
+		for i, s := range t.Scopes {
+			labels[i] = r.Loc(s.Label())
+		}
+		info.E("div class=personeditAPITokensScopes>%s", strings.Join(labels, "; "))
+		meta := info.E("div class=personeditAPITokensMeta")
+		meta.TF(r.Loc("Created %s"), t.Created.Format("2006-01-02"))
+		if !t.LastUsed.IsZero() {
+			meta.T("; ").TF(r.Loc("last used %s"), t.LastUsed.Format("2006-01-02"))
+		}
+		row.E("button type=submit name=revoke value=%d class='sbtn sbtn-small sbtn-danger'", t.ID).R(r.Loc("Revoke"))
+	})
+	if count == 0 {
+		list.E("div class=personeditAPITokensNone").R(r.Loc("No API tokens have been created."))
+	}
+	buttons := form.E("div class=formButtons")
+	buttons.E("button type=button class='sbtn sbtn-secondary' up-dismiss").R(r.Loc("Close"))
+}
@@ -0,0 +1,169 @@
+package personedit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/pages/people/personview"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/availability"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/ui/form"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const availabilityPersonFields = person.FInformalName | person.FPrivLevels | person.CanViewTargetFields
+
+// HandleAvailability handles requests for /people/$id/edavailability.
+func HandleAvailability(r *request.Request, idstr string) {
+	var (
+		user      *person.Person
+		p         *person.Person
+		a         *availability.Availability
+		days      [7]string
+		blackouts string
+		f         form.Form
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), availabilityPersonFields); p == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if user.ID() != p.ID() && !auth.Can(user, enum.PermPersonEdit, 0) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	a = availability.ForPerson(r, p.ID())
+	for _, w := range a.Windows {
+		if days[w.Weekday] != "" {
+			days[w.Weekday] += ", "
+		}
+		days[w.Weekday] += w.Start + "-" + w.End
+	}
+	today := time.Now().Format("2006-01-02")
+	for _, b := range a.Blackouts {
+		if b.End < today {
+			continue
+		}
+		if b.Start == b.End {
+			blackouts += b.Start + "\n"
+		} else {
+			blackouts += b.Start + " - " + b.End + "\n"
+		}
+	}
+	f.Attrs = "method=POST up-target=.personviewAvailability"
+	f.Dialog = true
+	f.Title = "Availability"
+	f.Buttons = []*form.Button{{
+		Label: "Save",
+		OnClick: func() bool {
+			r.Transaction(func() {
+				availability.Set(r, p, a)
+			})
+			personview.Render(r, user, p, user.CanView(p), "availability")
+			return true
+		},
+	}}
+	f.Rows = []form.Row{&form.MessageRow{
+		HTML: r.Loc("Enter the times you are usually available on each day of the week, such as “9:00-12:00, 18:00-21:00”.  Leave a day blank if you are not usually available on that day."),
+	}}
+	a.Windows = a.Windows[:0]
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		f.Rows = append(f.Rows, &windowsRow{form.TextInputRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "personeditAvailability" + wd.String(),
+				Label: wd.String(),
+			},
+			Name:   strings.ToLower(wd.String()),
+			ValueP: &days[wd],
+		}, wd, a})
+	}
+	a.Blackouts = a.Blackouts[:0]
+	f.Rows = append(f.Rows, &blackoutsRow{form.TextAreaRow{
+		LabeledRow: form.LabeledRow{
+			RowID: "personeditAvailabilityBlackouts",
+			Label: "Unavailable",
+			Help:  "Dates on which you are not available, one per line, such as “2024-12-24” or “2024-12-24 - 2025-01-02”.",
+		},
+		Name:     "blackouts",
+		ValueP:   &blackouts,
+		Wrap:     "off",
+		Validate: form.NoValidate,
+	}, a})
+	f.Handle(r)
+}
+
+type windowsRow struct {
+	form.TextInputRow
+	weekday time.Weekday
+	a       *availability.Availability
+}
+
+func (wr *windowsRow) Read(r *request.Request) bool {
+	if !wr.TextInputRow.Read(r) {
+		return false
+	}
+	var windows []availability.Window
+	for _, rng := range strings.Split(*wr.ValueP, ",") {
+		if rng = strings.TrimSpace(rng); rng == "" {
+			continue
+		}
+		start, end, ok := strings.Cut(rng, "-")
+		st, err1 := time.Parse("15:04", strings.TrimSpace(start))
+		et, err2 := time.Parse("15:04", strings.TrimSpace(end))
+		if !ok || err1 != nil || err2 != nil || !et.After(st) {
+			wr.Error = fmt.Sprintf(r.Loc("%q is not a valid time range."), rng)
+			return false
+		}
+		windows = append(windows, availability.Window{Weekday: wr.weekday, Start: st.Format("15:04"), End: et.Format("15:04")})
+	}
+	slices.SortFunc(windows, func(a, b availability.Window) int { return strings.Compare(a.Start, b.Start) })
+	for i := 1; i < len(windows); i++ {
+		if windows[i].Start < windows[i-1].End {
+			wr.Error = r.Loc("The time ranges overlap.")
+			return false
+		}
+	}
+	wr.a.Windows = append(wr.a.Windows, windows...)
+	return true
+}
+
+type blackoutsRow struct {
+	form.TextAreaRow
+	a *availability.Availability
+}
+
+func (br *blackoutsRow) Read(r *request.Request) bool {
+	if !br.TextAreaRow.Read(r) {
+		return false
+	}
+	today := time.Now().Format("2006-01-02")
+	for _, line := range strings.Split(*br.ValueP, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		start, end, ok := strings.Cut(line, " - ")
+		if !ok {
+			end = start
+		}
+		st, err1 := time.ParseInLocation("2006-01-02", strings.TrimSpace(start), time.Local)
+		et, err2 := time.ParseInLocation("2006-01-02", strings.TrimSpace(end), time.Local)
+		if err1 != nil || err2 != nil || et.Before(st) {
+			br.Error = fmt.Sprintf(r.Loc("%q is not a valid date or date range."), line)
+			return false
+		}
+		if b := (availability.Blackout{Start: st.Format("2006-01-02"), End: et.Format("2006-01-02")}); b.End >= today {
+			br.a.Blackouts = append(br.a.Blackouts, b)
+		}
+	}
+	slices.SortFunc(br.a.Blackouts, func(a, b availability.Blackout) int { return strings.Compare(a.Start, b.Start) })
+	br.a.Blackouts = slices.CompactFunc(br.a.Blackouts, func(a, b availability.Blackout) bool { return a.Start == b.Start })
+	return true
+}
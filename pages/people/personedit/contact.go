@@ -23,7 +23,7 @@ import (
 )
 
 const (
-	contactPersonFields    = person.FInformalName | person.FCallSign | person.FEmail | person.FEmail2 | person.FCellPhone | person.FHomePhone | person.FWorkPhone | person.FAddresses | person.FEmContacts
+	contactPersonFields    = person.FInformalName | person.FCallSign | person.FEmail | person.FEmail2 | person.FCellPhone | person.FHomePhone | person.FWorkPhone | person.FAddresses | person.FEmContacts | person.FMedicalNotes
 	addressVerificationAPI = "https://addressvalidation.googleapis.com/v1:validateAddress?key="
 )
 
@@ -34,6 +34,7 @@ func HandleContact(r *request.Request, idstr string) {
 		p                    *person.Person
 		up                   *person.Updater
 		canEditEmContacts    bool
+		canEditMedicalNotes  bool
 		emailError           string
 		email2Error          string
 		cellPhoneError       string
@@ -60,11 +61,12 @@ func HandleContact(r *request.Request, idstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
-	if user.ID() != p.ID() && !user.HasPrivLevel(0, enum.PrivLeader) {
+	if user.ID() != p.ID() && !auth.Can(user, enum.PermPersonEdit, 0) {
 		errpage.Forbidden(r, user)
 		return
 	}
-	canEditEmContacts = user.ID() == p.ID() || user.IsAdminLeader()
+	canEditEmContacts = user.ID() == p.ID() || auth.Can(user, enum.PermPersonData, 0)
+	canEditMedicalNotes = user.ID() == p.ID()
 	up = p.Updater()
 	validate := strings.Fields(r.Request.Header.Get("X-Up-Validate"))
 	if r.Method == http.MethodPost {
@@ -86,6 +88,9 @@ func HandleContact(r *request.Request, idstr string) {
 			ec2CellPhoneError = readECCellPhone(r, up, 1)
 			ec2RelationshipError = readECRelationship(r, up, 1)
 		}
+		if canEditMedicalNotes {
+			readMedicalNotes(r, up)
+		}
 		haveErrors = emailError != "" || email2Error != "" || cellPhoneError != "" || homePhoneError != "" || workPhoneError != "" || homeAddressError != "" || workAddressError != "" || mailAddressError != "" || ec1HomePhoneError != "" || ec1CellPhoneError != "" || ec1RelationshipError != "" || ec2HomePhoneError != "" || ec2CellPhoneError != "" || ec2RelationshipError != ""
 		// If there were no errors *and* we're not validating, save the
 		// data and return to the view page.
@@ -131,6 +136,9 @@ func HandleContact(r *request.Request, idstr string) {
 			emitEmergencyContact(r, form, up, 0, "", ec1HomePhoneError, ec1CellPhoneError, ec1RelationshipError)
 			emitEmergencyContact(r, form, up, 1, "", ec2HomePhoneError, ec2CellPhoneError, ec2RelationshipError)
 		}
+		if canEditMedicalNotes {
+			emitMedicalNotes(r, form, up)
+		}
 		emitButtons(r, form)
 	}
 }
@@ -523,6 +531,21 @@ func emitEmergencyContact(r *request.Request, form *htmlb.Element, up *person.Up
 	}
 }
 
+func readMedicalNotes(r *request.Request, up *person.Updater) {
+	up.MedicalNotes = strings.TrimSpace(r.FormValue("medical"))
+	if runes := []rune(up.MedicalNotes); len(runes) > 1000 {
+		up.MedicalNotes = string(runes[:1000])
+	}
+}
+
+func emitMedicalNotes(r *request.Request, form *htmlb.Element, up *person.Updater) {
+	form.E("div class='formRow-3col personeditEmContact'").R(r.Loc("Medical Notes"))
+	row := form.E("div class=formRow")
+	row.E("label for=personeditMedicalNotes").R(r.Loc("Notes"))
+	row.E("textarea id=personeditMedicalNotes name=medical class=formInput rows=3 maxlength=1000 wrap=soft").T(up.MedicalNotes)
+	row.E("div class=formHelp").R(r.Loc("Optional.  Anything you want an incident lead to know in a medical emergency during a deployment, such as allergies, conditions, or medications.  This is shown only on the emergency contact sheet for events you are deployed to."))
+}
+
 func fmtPhone(p *string, extraOK bool) bool {
 	digits := strings.Map(func(r rune) rune {
 		if r < '0' || r > '9' {
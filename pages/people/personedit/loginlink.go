@@ -0,0 +1,77 @@
+package personedit
+
+import (
+	"net/http"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/pages/people/personview"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const loginLinkPersonFields = person.FInformalName | person.FCallSign | person.FPrivLevels | person.FFlags
+
+// HandleLoginLink handles requests for /people/$id/edloginlink.  People can
+// turn signing in with a link sent by email on or off for their own accounts.
+func HandleLoginLink(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		p    *person.Person
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !auth.CheckCSRF(r, user) {
+		return
+	}
+	if auth.Impersonating(r) { // credentials can't be changed while impersonating
+		errpage.Forbidden(r, user)
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), loginLinkPersonFields); p == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if user.ID() != p.ID() || p.ID() == person.AdminID || !auth.LoginLinksEnabled() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if r.Method == http.MethodPost {
+		postLoginLink(r, user, p)
+	} else {
+		getLoginLink(r, p)
+	}
+}
+
+func getLoginLink(r *request.Request, p *person.Person) {
+	r.HTMLNoCache()
+	html := htmlb.HTML(r)
+	defer html.Close()
+	form := html.E("form class='form form-2col' method=POST up-main up-layer=parent up-target=.personviewPassword")
+	form.E("div class='formTitle formTitle-primary'").R(r.Loc("Sign-In Links"))
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	form.E("div class=formRow-3col").R(r.Loc("If you allow it, you can sign in without your password by asking for a sign-in link to be sent to your email address.  Anyone who can read your email could then sign in as you."))
+	form.E("div class=formRow-3col").E("input type=checkbox class=s-check name=allow label=%s", r.Loc("Allow sign-in links"),
+		p.Flags()&person.NoLoginLink == 0, "checked")
+	buttons := form.E("div class=formButtons")
+	buttons.E("button type=button class='sbtn sbtn-secondary' up-dismiss").R(r.Loc("Cancel"))
+	buttons.E("input type=submit name=save class='sbtn sbtn-primary' value=%s", r.Loc("Save"))
+}
+
+func postLoginLink(r *request.Request, user, p *person.Person) {
+	up := p.Updater()
+	if r.FormValue("allow") != "" {
+		up.Flags &^= person.NoLoginLink
+	} else {
+		up.Flags |= person.NoLoginLink
+	}
+	if up.Flags != p.Flags() {
+		r.Transaction(func() {
+			p.Update(r, up, person.FFlags)
+		})
+	}
+	personview.Render(r, user, p, person.ViewFull, "password")
+}
@@ -40,7 +40,7 @@ func HandleNames(r *request.Request, idstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
-	if user.ID() != p.ID() && !user.HasPrivLevel(0, enum.PrivLeader) {
+	if user.ID() != p.ID() && !auth.Can(user, enum.PermPersonEdit, 0) {
 		errpage.Forbidden(r, user)
 		return
 	}
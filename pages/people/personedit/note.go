@@ -39,7 +39,7 @@ func HandleNote(r *request.Request, idstr, indexstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
-	if !user.HasPrivLevel(0, enum.PrivLeader) {
+	if !auth.Can(user, enum.PermPersonNotes, 0) {
 		errpage.Forbidden(r, user)
 		return
 	}
@@ -0,0 +1,115 @@
+package personedit
+
+import (
+	"net/http"
+	"strings"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personpasskey"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const passkeysPersonFields = person.FID | person.FInformalName | person.FEmail
+
+// HandlePasskeys handles requests for /people/$id/edpasskeys.  People can add
+// and remove passkeys for their own accounts.  Webmasters can see and remove
+// the passkeys of anyone, but cannot add them.
+func HandlePasskeys(r *request.Request, idstr string) {
+	var (
+		user   *person.Person
+		p      *person.Person
+		name   string
+		addErr string
+	)
+	if user = auth.SessionUser(r, person.FInformalName, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if auth.Impersonating(r) { // credentials can't be changed while impersonating
+		errpage.Forbidden(r, user)
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), passkeysPersonFields); p == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if user.ID() != p.ID() && !user.IsWebmaster() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if r.Method == http.MethodPost {
+		if pk := personpasskey.WithID(r, personpasskey.ID(util.ParseID(r.FormValue("remove")))); pk != nil && pk.Person == p.ID() {
+			r.Transaction(func() {
+				pk.Delete(r, p)
+			})
+		} else if r.FormValue("add") != "" && user.ID() == p.ID() {
+			if name = strings.TrimSpace(r.FormValue("name")); name == "" {
+				addErr = r.Loc("Please give this passkey a name.")
+			} else if err := auth.RegisterPasskey(r, p, name, r.FormValue("challenge"), r.FormValue("clientData"), r.FormValue("attestation")); err != nil {
+				r.LogEntry.Problems.AddError(err)
+				if err == auth.ErrPasskeyRegistered {
+					addErr = r.Loc("This passkey is already registered.")
+				} else {
+					addErr = r.Loc("The passkey could not be added.  Please try again.")
+				}
+			} else {
+				name = ""
+			}
+		}
+	}
+	r.HTMLNoCache()
+	if addErr != "" {
+		r.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	html := htmlb.HTML(r)
+	defer html.Close()
+	form := html.E("form class='form form-2col personeditPasskeys' method=POST up-main up-target=.personeditPasskeys")
+	if user.ID() == p.ID() {
+		form.A("data-challenge=%s data-rpid=%s data-userid=%d data-username=%s data-displayname=%s",
+			auth.PasskeyChallenge(), auth.PasskeyRPID(), p.ID(), p.Email(), p.InformalName())
+	}
+	form.E("div class='formTitle formTitle-primary'").R(r.Loc("Passkeys"))
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	if user.ID() == p.ID() {
+		form.E("div class=formRow-3col").R(r.Loc("A passkey lets you log in with your phone, computer, or security key — using your fingerprint, face, or screen lock — instead of typing your password.  You can add a passkey for each device you use."))
+	}
+	list := form.E("div class='formRow-3col personeditPasskeysList'")
+	var count int
+	personpasskey.AllForPerson(r, p.ID(), func(pk *personpasskey.Passkey) {
+		count++
+		row := list.E("div class=personeditPasskeysItem data-credential=%s", pk.Credential)
+		info := row.E("div class=personeditPasskeysInfo")
+		info.E("div class=personeditPasskeysName>%s", pk.Name)
+		meta := info.E("div class=personeditPasskeysMeta")
+		meta.TF(r.Loc("Added %s"), pk.Created.Format("2006-01-02"))
+		if !pk.LastUsed.IsZero() {
+			meta.T("; ").TF(r.Loc("last used %s"), pk.LastUsed.Format("2006-01-02"))
+		}
+		row.E("button type=submit name=remove value=%d class='sbtn sbtn-small sbtn-danger'", pk.ID).R(r.Loc("Remove"))
+	})
+	if count == 0 {
+		list.E("div class=personeditPasskeysNone").R(r.Loc("No passkeys have been added."))
+	}
+	if user.ID() == p.ID() {
+		form.E("input type=hidden name=add")
+		form.E("input type=hidden name=challenge")
+		form.E("input type=hidden name=clientData")
+		form.E("input type=hidden name=attestation")
+		row := form.E("div class='formRow personeditPasskeysAdd' hidden")
+		row.E("label for=personeditPasskeysName").R(r.Loc("New Passkey"))
+		row.E("input id=personeditPasskeysName name=name class=formInput placeholder=%s value=%s", r.Loc("Device name, e.g. “My iPhone”"), name)
+		if addErr != "" {
+			row.E("div class=formError>%s", addErr)
+		}
+		row.E("div class=formHelp").R(r.Loc("Give the passkey a name that tells you which device it’s on."))
+		form.E("div class='formRow-3col personeditPasskeysUnsupported'").R(r.Loc("This browser does not support passkeys."))
+	}
+	buttons := form.E("div class=formButtons")
+	buttons.E("button type=button class='sbtn sbtn-secondary' up-dismiss").R(r.Loc("Close"))
+	if user.ID() == p.ID() {
+		buttons.E("button type=button class='sbtn sbtn-primary personeditPasskeysAddButton' hidden").R(r.Loc("Add Passkey"))
+	}
+}
@@ -0,0 +1,56 @@
+// The passkey dialog shows the controls for adding a passkey only if the
+// browser supports passkeys.  When the Add Passkey button is clicked, we ask
+// the browser to create one, copy its response into the form, and submit it.
+up.compiler('.personeditPasskeys[data-challenge]', (form) => {
+  if (!window.PublicKeyCredential || !navigator.credentials) return
+  form.querySelector('.personeditPasskeysUnsupported').hidden = true
+  form.querySelector('.personeditPasskeysAdd').hidden = false
+  const button = form.querySelector('.personeditPasskeysAddButton')
+  button.hidden = false
+  const encode = (buf) => btoa(String.fromCharCode(...new Uint8Array(buf)))
+    .replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '')
+  const decode = (str) => Uint8Array.from(atob(str.replace(/-/g, '+').replace(/_/g, '/')), (c) => c.charCodeAt(0))
+  button.addEventListener('click', async () => {
+    const name = form.elements.name
+    if (!name.value.trim()) {
+      name.focus()
+      return
+    }
+    const encoder = new TextEncoder()
+    let cred
+    try {
+      cred = await navigator.credentials.create({
+        publicKey: {
+          challenge: encoder.encode(form.dataset.challenge),
+          rp: { id: form.dataset.rpid, name: 'Sunnyvale SERV' },
+          user: {
+            id: encoder.encode(form.dataset.userid),
+            name: form.dataset.username,
+            displayName: form.dataset.displayname,
+          },
+          pubKeyCredParams: [-7, -8, -257].map((alg) => ({ type: 'public-key', alg })),
+          excludeCredentials: Array.from(form.querySelectorAll('[data-credential]'), (elm) => (
+            { type: 'public-key', id: decode(elm.dataset.credential) }
+          )),
+          authenticatorSelection: { residentKey: 'required', userVerification: 'preferred' },
+          attestation: 'none',
+        },
+      })
+    } catch (err) {
+      return // canceled, or the passkey is already registered
+    }
+    if (!cred) return
+    form.elements.add.value = 'true'
+    form.elements.challenge.value = form.dataset.challenge
+    form.elements.clientData.value = encode(cred.response.clientDataJSON)
+    form.elements.attestation.value = encode(cred.response.attestationObject)
+    up.submit(form)
+  })
+})
+// Pressing Enter in the name field should add the passkey, not remove one.
+up.on('keydown', '#personeditPasskeysName', (evt) => {
+  if (evt.key !== 'Enter') return
+  evt.preventDefault()
+  const button = document.querySelector('.personeditPasskeysAddButton')
+  if (button && !button.hidden) button.click()
+})
@@ -26,6 +26,10 @@ func HandlePassword(r *request.Request, idstr string) {
 	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
 		return
 	}
+	if auth.Impersonating(r) { // credentials can't be changed while impersonating
+		errpage.Forbidden(r, user)
+		return
+	}
 	if p = person.WithID(r, person.ID(util.ParseID(idstr)), passwordPersonFields); p == nil {
 		errpage.NotFound(r, user)
 		return
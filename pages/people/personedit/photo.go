@@ -0,0 +1,121 @@
+package personedit
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/pages/people/personview"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/photo"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// HandlePhoto handles requests for /people/$id/edphoto.  People can set their
+// own photos; leaders can set the photo of anyone (typically their DPS badge
+// photo).
+func HandlePhoto(r *request.Request, idstr string) {
+	var (
+		user    *person.Person
+		p       *person.Person
+		jpeg    []byte
+		fileErr string
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !auth.CheckCSRF(r, user) {
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), person.PhotoFields|personview.PersonFields); p == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if user.ID() != p.ID() && !auth.Can(user, enum.PermPersonEdit, 0) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if r.Method == http.MethodPost {
+		if r.FormValue("remove") != "" {
+			r.Transaction(func() {
+				p.RemovePhoto(r)
+			})
+			personview.Render(r, user, p, user.CanView(p), "names")
+			return
+		}
+		if jpeg, fileErr = readPhoto(r); fileErr == "" {
+			r.Transaction(func() {
+				p.SetPhoto(r, jpeg)
+			})
+			personview.Render(r, user, p, user.CanView(p), "names")
+			return
+		}
+	}
+	r.HTMLNoCache()
+	if fileErr != "" {
+		r.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	html := htmlb.HTML(r)
+	defer html.Close()
+	form := html.E("form class='form form-2col' method=POST enctype=multipart/form-data up-main up-layer=parent up-target=.personviewNames")
+	form.E("div class='formTitle formTitle-primary'").R(r.Loc("Edit Photo"))
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	if p.Flags()&person.HasPhoto != 0 {
+		row := form.E("div class=formRow")
+		row.E("label").R(r.Loc("Current"))
+		ui.PersonPhoto(row.E("div"), p, "personeditPhoto")
+	}
+	row := form.E("div class=formRow")
+	row.E("label for=personeditPhotoFile").R(r.Loc("New Photo"))
+	row.E("input type=file id=personeditPhotoFile name=photo accept=image/jpeg,image/png,image/gif class=formInput", fileErr != "", "autofocus")
+	if fileErr != "" {
+		row.E("div class=formError>%s", fileErr)
+	}
+	if user.ID() == p.ID() {
+		row.E("div class=formHelp").R(r.Loc("Please use a recent photo showing your face clearly, so that leaders can recognize you at events.  It will be cropped to a square."))
+	} else {
+		row.E("div class=formHelp").R(r.Loc("Typically this is the person’s DPS badge photo.  It will be cropped to a square."))
+	}
+	buttons := form.E("div class=formButtons")
+	buttons.E("button type=button class='sbtn sbtn-secondary' up-dismiss").R(r.Loc("Cancel"))
+	if p.Flags()&person.HasPhoto != 0 {
+		buttons.E("input type=submit name=remove class='sbtn sbtn-danger' value=%s", r.Loc("Remove"))
+	}
+	buttons.E("input type=submit name=save class='sbtn sbtn-primary' value=%s", r.Loc("Save"))
+}
+
+// readPhoto reads and prepares the uploaded photo.
+func readPhoto(r *request.Request) (jpeg []byte, err string) {
+	var (
+		files []*multipart.FileHeader
+		mf    multipart.File
+		data  []byte
+		e     error
+	)
+	if r.MultipartForm != nil && r.MultipartForm.File != nil {
+		files = r.MultipartForm.File["photo"]
+	}
+	if len(files) != 1 {
+		return nil, r.Loc("Please choose a photo to upload.")
+	}
+	if files[0].Size > photo.MaxUpload {
+		return nil, r.Loc("The photo file is too large.")
+	}
+	if mf, e = files[0].Open(); e != nil {
+		return nil, r.Loc("The photo was not uploaded correctly.")
+	}
+	defer mf.Close()
+	if data, e = io.ReadAll(mf); e != nil {
+		return nil, r.Loc("The photo was not uploaded correctly.")
+	}
+	if jpeg, e = photo.Prepare(data); e != nil {
+		return nil, r.Loc("The photo must be a JPEG, PNG, or GIF image.")
+	}
+	return jpeg, ""
+}
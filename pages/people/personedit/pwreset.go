@@ -32,11 +32,15 @@ func HandlePWReset(r *request.Request, idstr string) {
 	if !auth.CheckCSRF(r, user) {
 		return
 	}
+	if auth.Impersonating(r) { // credentials can't be changed while impersonating
+		errpage.Forbidden(r, user)
+		return
+	}
 	if p = person.WithID(r, person.ID(util.ParseID(idstr)), pwResetPersonFields); p == nil {
 		errpage.NotFound(r, user)
 		return
 	}
-	if user.ID() == p.ID() || p.ID() == person.AdminID || !user.HasPrivLevel(0, enum.PrivLeader) || p.Email() == "" {
+	if user.ID() == p.ID() || p.ID() == person.AdminID || !auth.Can(user, enum.PermPersonResetPassword, 0) || p.Email() == "" {
 		errpage.Forbidden(r, user)
 		return
 	}
@@ -34,7 +34,7 @@ func HandleRoles(r *request.Request, idstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
-	if !user.HasPrivLevel(0, enum.PrivLeader) || p.ID() == person.AdminID {
+	if !auth.Can(user, enum.PermPersonRoles, 0) || p.ID() == person.AdminID {
 		errpage.Forbidden(r, user)
 		return
 	}
@@ -60,11 +60,11 @@ func handleGetRoles(r *request.Request, user, p *person.Person) {
 	form.E("div class='formTitle formTitle-primary'>Edit Roles")
 	form.E("input type=hidden name=csrf value=%s", r.CSRF)
 	for _, org := range enum.AllOrgs {
-		if org != enum.OrgAdmin && (user.HasPrivLevel(org, enum.PrivLeader) || user.IsAdminLeader()) {
+		if org != enum.OrgAdmin && auth.Can(user, enum.PermPersonRoles, org) {
 			handleGetOrgRoles(r, form, held, org)
 		}
 	}
-	if user.IsAdminLeader() {
+	if auth.Can(user, enum.PermPersonRoles, enum.OrgAdmin) {
 		handleGetOrgRoles(r, form, held, enum.OrgAdmin)
 	}
 	emitButtons(r, form)
@@ -108,11 +108,11 @@ func handlePostRoles(r *request.Request, user, p *person.Person) {
 	}
 	r.Transaction(func() {
 		for _, org := range enum.AllOrgs {
-			if org != enum.OrgAdmin && (user.HasPrivLevel(org, enum.PrivLeader) || user.IsAdminLeader()) {
+			if org != enum.OrgAdmin && auth.Can(user, enum.PermPersonRoles, org) {
 				handlePostOrgRoles(r, p, held, org)
 			}
 		}
-		if user.IsAdminLeader() {
+		if auth.Can(user, enum.PermPersonRoles, enum.OrgAdmin) {
 			handlePostOrgRoles(r, p, held, enum.OrgAdmin)
 		}
 		recalc.Recalculate(r)
@@ -0,0 +1,77 @@
+package personedit
+
+import (
+	"net/http"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/pages/people/personview"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personrole"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/store/session"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const sessionsPersonFields = person.FInformalName | person.FCallSign | person.FPrivLevels
+
+// HandleSessions handles POST requests for /people/$id/edsessions.  People can
+// sign out any of their own sessions other than the current one.  Leaders can
+// sign out all sessions of a disabled person.
+func HandleSessions(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		p    *person.Person
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !auth.CheckCSRF(r, user) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		errpage.NotFound(r, user)
+		return
+	}
+	if auth.Impersonating(r) { // sessions can't be managed while impersonating
+		errpage.Forbidden(r, user)
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), sessionsPersonFields); p == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if user.ID() == p.ID() {
+		if handle := r.FormValue("revoke"); handle != "" {
+			var token string
+			session.AllForPerson(r, p.ID(), func(s *session.Session) {
+				if s.Handle() == handle && s.Token != r.SessionToken {
+					token = s.Token
+				}
+			})
+			if token != "" {
+				r.Transaction(func() {
+					session.Delete(r, token, p)
+				})
+			}
+		} else if r.FormValue("others") != "" {
+			r.Transaction(func() {
+				session.DeleteForPerson(r, p, r.SessionToken)
+			})
+		}
+	} else if r.FormValue("all") != "" && auth.Can(user, enum.PermPersonResetPassword, 0) {
+		if held, _ := personrole.PersonHasRole(r, p.ID(), role.Disabled); !held {
+			errpage.Forbidden(r, user)
+			return
+		}
+		r.Transaction(func() {
+			session.DeleteForPerson(r, p, "")
+		})
+	} else {
+		errpage.Forbidden(r, user)
+		return
+	}
+	personview.Render(r, user, p, person.ViewFull, "sessions")
+}
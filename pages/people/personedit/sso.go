@@ -0,0 +1,90 @@
+package personedit
+
+import (
+	"net/http"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/pages/people/personview"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personsso"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const ssoPersonFields = person.FInformalName | person.FCallSign | person.FPrivLevels | person.FFlags
+
+// HandleSSO handles requests for /people/$id/edsso.  Webmasters can require a
+// person to sign in with single sign-on, and can unlink the person from their
+// account with the OpenID provider.
+func HandleSSO(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		p    *person.Person
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !auth.CheckCSRF(r, user) {
+		return
+	}
+	if auth.Impersonating(r) { // credentials can't be changed while impersonating
+		errpage.Forbidden(r, user)
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), ssoPersonFields); p == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if !user.IsWebmaster() || p.ID() == person.AdminID || !auth.SSOEnabled() {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if r.Method == http.MethodPost {
+		postSSO(r, user, p)
+	} else {
+		getSSO(r, p)
+	}
+}
+
+func getSSO(r *request.Request, p *person.Person) {
+	r.HTMLNoCache()
+	html := htmlb.HTML(r)
+	defer html.Close()
+	form := html.E("form class='form form-2col' method=POST up-main up-layer=parent up-target=.personviewPassword")
+	form.E("div class='formTitle formTitle-primary'>Single Sign-On")
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	if link := personsso.Get(r, p.ID()); link != nil {
+		form.E("div class=formRow-3col>%s is linked to the %s account %q, since %s.",
+			p.InformalName(), auth.SSOLabel(), link.Subject, link.Linked.Format("January 2, 2006"))
+		form.E("div class=formRow-3col").E("input type=checkbox class=s-check name=unlink label='Unlink this account'")
+	} else {
+		form.E("div class=formRow-3col>%s is not linked to a %s account.  They will be linked automatically the first time they sign in with one that has the same email address.",
+			p.InformalName(), auth.SSOLabel())
+	}
+	form.E("div class=formRow-3col").E("input type=checkbox class=s-check name=require label='Require single sign-on'",
+		p.Flags()&person.RequireSSO != 0, "checked")
+	form.E("div class=formRow-3col>When single sign-on is required, %s cannot log in with a password, passkey, or sign-in link.", p.InformalName())
+	buttons := form.E("div class=formButtons")
+	buttons.E("button type=button class='sbtn sbtn-secondary' up-dismiss>Cancel")
+	buttons.E("input type=submit name=save class='sbtn sbtn-primary' value=Save")
+}
+
+func postSSO(r *request.Request, user, p *person.Person) {
+	up := p.Updater()
+	if r.FormValue("require") != "" {
+		up.Flags |= person.RequireSSO
+	} else {
+		up.Flags &^= person.RequireSSO
+	}
+	r.Transaction(func() {
+		if up.Flags != p.Flags() {
+			p.Update(r, up, person.FFlags)
+		}
+		if r.FormValue("unlink") != "" {
+			personsso.Remove(r, p)
+		}
+	})
+	personview.Render(r, user, p, person.ViewFull, "password")
+}
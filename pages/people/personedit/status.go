@@ -11,6 +11,7 @@ import (
 	"sunnyvaleserv.org/portal/pages/errpage"
 	"sunnyvaleserv.org/portal/pages/people/personview"
 	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/util"
 	"sunnyvaleserv.org/portal/util/htmlb"
@@ -39,7 +40,7 @@ func HandleStatus(r *request.Request, idstr string) {
 	if user = auth.SessionUser(r, 0, true); user == nil {
 		return
 	}
-	if !user.IsAdminLeader() {
+	if !auth.Can(user, enum.PermPersonEditClearance, 0) {
 		errpage.Forbidden(r, user)
 		return
 	}
@@ -0,0 +1,227 @@
+package personedit
+
+import (
+	"net/http"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/pages/login"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/persontotp"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+	"sunnyvaleserv.org/portal/util/totp"
+)
+
+const totpPersonFields = person.FID | person.FInformalName | person.FEmail | person.FPrivLevels | person.FFlags
+
+// HandleTOTP handles requests for /people/$id/edtotp.  People can set up,
+// change, or turn off their own two-factor authentication.  Webmasters can
+// require it for other people, and reset the recovery codes of anyone who
+// uses it.
+func HandleTOTP(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		p    *person.Person
+		e    *persontotp.Enrollment
+	)
+	if user = auth.SessionUser(r, person.FInformalName, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if auth.Impersonating(r) { // credentials can't be changed while impersonating
+		errpage.Forbidden(r, user)
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), totpPersonFields); p == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	e = persontotp.Get(r, p.ID())
+	switch {
+	case user.ID() != p.ID() && user.IsWebmaster():
+		handleTOTPAdmin(r, user, p, e)
+	case user.ID() != p.ID():
+		errpage.Forbidden(r, user)
+	case e == nil:
+		handleTOTPEnroll(r, p)
+	default:
+		handleTOTPChange(r, p)
+	}
+}
+
+// handleTOTPEnroll handles a person setting up two-factor authentication for
+// their own account.
+func handleTOTPEnroll(r *request.Request, p *person.Person) {
+	var (
+		secret = r.FormValue("secret")
+		failed bool
+	)
+	if r.Method == http.MethodPost {
+		if step := auth.CheckTOTPCode(secret, r.FormValue("code")); step != 0 {
+			codes, hashes := auth.NewRecoveryCodes()
+			r.Transaction(func() {
+				persontotp.Enroll(r, p, secret, hashes, step)
+			})
+			showTOTPCodes(r, p, r.Loc("Two-Factor Authentication"), codes)
+			return
+		}
+		failed = true
+	} else {
+		secret = totp.NewSecret()
+	}
+	r.HTMLNoCache()
+	if failed {
+		r.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	html := htmlb.HTML(r)
+	defer html.Close()
+	form := html.E("form class='form form-2col personeditTOTP' method=POST up-main up-target=.personeditTOTP")
+	form.E("div class='formTitle formTitle-primary'").R(r.Loc("Two-Factor Authentication"))
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	form.E("div class=formRow-3col").R(r.Loc("Two-factor authentication protects your account even if someone learns your password.  When it is turned on, logging in requires a code from an authenticator app on your phone as well as your password."))
+	login.ShowTOTPSetup(r, form, p.Email(), secret, failed)
+	buttons := form.E("div class=formButtons")
+	buttons.E("button type=button class='sbtn sbtn-secondary' up-dismiss").R(r.Loc("Cancel"))
+	buttons.E("input type=submit class='sbtn sbtn-primary' value=%s", r.Loc("Turn On"))
+}
+
+// handleTOTPChange handles a person changing their own two-factor
+// authentication:  getting new recovery codes or turning it off.  Either
+// requires a current code.
+func handleTOTPChange(r *request.Request, p *person.Person) {
+	var (
+		failed   bool
+		required = auth.TOTPRequired(p)
+	)
+	if r.Method == http.MethodPost {
+		if auth.CheckSecondFactor(r, p, r.FormValue("code")) {
+			if r.FormValue("disable") != "" && !required {
+				r.Transaction(func() {
+					persontotp.Remove(r, p)
+				})
+				showTOTPCodes(r, p, r.Loc("Two-Factor Authentication"), nil)
+				return
+			}
+			codes, hashes := auth.NewRecoveryCodes()
+			r.Transaction(func() {
+				persontotp.ResetRecovery(r, p, hashes, p)
+			})
+			showTOTPCodes(r, p, r.Loc("Two-Factor Authentication"), codes)
+			return
+		}
+		failed = true
+	}
+	r.HTMLNoCache()
+	if failed {
+		r.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	html := htmlb.HTML(r)
+	defer html.Close()
+	form := html.E("form class='form form-2col personeditTOTP' method=POST up-main up-target=.personeditTOTP")
+	form.E("div class='formTitle formTitle-primary'").R(r.Loc("Two-Factor Authentication"))
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	if required {
+		form.E("div class=formRow-3col").R(r.Loc("Two-factor authentication is turned on for your account.  It is required, so it cannot be turned off."))
+	} else {
+		form.E("div class=formRow-3col").R(r.Loc("Two-factor authentication is turned on for your account."))
+	}
+	form.E("div class=formRow-3col").TF(r.Loc("You have %d unused recovery codes."), len(persontotp.Get(r, p.ID()).Recovery))
+	row := form.E("div class=formRow")
+	row.E("label for=personeditTOTPCode").R(r.Loc("Code"))
+	row.E("input name=code id=personeditTOTPCode class=formInput autocomplete=one-time-code autocapitalize=none autofocus")
+	if failed {
+		row.E("div class=formError").R(r.Loc("That code is not correct. Please try again."))
+	}
+	row.E("div class=formHelp").R(r.Loc("Enter the code shown in your authenticator app, or one of your recovery codes."))
+	buttons := form.E("div class=formButtons")
+	buttons.E("button type=button class='sbtn sbtn-secondary' up-dismiss").R(r.Loc("Cancel"))
+	if !required {
+		buttons.E("input type=submit name=disable class='sbtn sbtn-danger' value=%s", r.Loc("Turn Off"))
+	}
+	buttons.E("input type=submit name=regenerate class='sbtn sbtn-primary' value=%s", r.Loc("New Recovery Codes"))
+}
+
+// handleTOTPAdmin handles a webmaster requiring someone else to use two-factor
+// authentication (or not), and resetting the recovery codes of someone else
+// who has lost them.
+func handleTOTPAdmin(r *request.Request, user, p *person.Person, e *persontotp.Enrollment) {
+	if r.Method == http.MethodPost {
+		var codes, hashes []string
+
+		up := p.Updater()
+		if r.FormValue("require") != "" {
+			up.Flags |= person.RequireTOTP
+		} else {
+			up.Flags &^= person.RequireTOTP
+		}
+		if e != nil && r.FormValue("reset") != "" {
+			codes, hashes = auth.NewRecoveryCodes()
+		}
+		r.Transaction(func() {
+			if up.Flags != p.Flags() {
+				p.Update(r, up, person.FFlags)
+			}
+			if hashes != nil {
+				persontotp.ResetRecovery(r, p, hashes, user)
+			}
+		})
+		if codes != nil {
+			showTOTPCodes(r, p, "Recovery Codes for "+p.InformalName(), codes)
+			return
+		}
+		r.HTMLNoCache()
+		html := htmlb.HTML(r)
+		defer html.Close()
+		form := html.E("div class='form form-2col personeditTOTP' up-main")
+		form.E("div class='formTitle formTitle-primary'>Two-Factor Authentication")
+		if auth.TOTPRequired(p) {
+			form.E("div class=formRow-3col>Two-factor authentication is required for %s.", p.InformalName())
+		} else {
+			form.E("div class=formRow-3col>Two-factor authentication is not required for %s.", p.InformalName())
+		}
+		form.E("div class=formButtons").
+			E("a href=/people/%d up-layer=parent up-target=.personviewPassword class='sbtn sbtn-primary'>Done", p.ID())
+		return
+	}
+	r.HTMLNoCache()
+	html := htmlb.HTML(r)
+	defer html.Close()
+	form := html.E("form class='form form-2col personeditTOTP' method=POST up-main up-target=.personeditTOTP")
+	form.E("div class='formTitle formTitle-primary'>Two-Factor Authentication")
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	if e != nil {
+		form.E("div class=formRow-3col>%s uses two-factor authentication.", p.InformalName())
+	} else {
+		form.E("div class=formRow-3col>%s does not use two-factor authentication.", p.InformalName())
+	}
+	form.E("div class=formRow-3col").E("input type=checkbox class=s-check name=require label='Require two-factor authentication'",
+		p.Flags()&person.RequireTOTP != 0, "checked")
+	form.E("div class=formRow-3col>When two-factor authentication is required, %s must set it up the next time they log in, and cannot turn it off.", p.InformalName())
+	if e != nil {
+		form.E("div class=formRow-3col").E("input type=checkbox class=s-check name=reset label='Reset recovery codes'")
+		form.E("div class=formRow-3col>If %s has lost access to their authenticator app and their recovery codes, you can give them a new set of recovery codes.  Their old recovery codes will stop working.  Please confirm their identity before doing this, and give them the new codes securely.", p.InformalName())
+	}
+	buttons := form.E("div class=formButtons")
+	buttons.E("button type=button class='sbtn sbtn-secondary' up-dismiss>Cancel")
+	buttons.E("input type=submit class='sbtn sbtn-primary' value=Save")
+}
+
+// showTOTPCodes shows a dialog with newly issued recovery codes, or, if there
+// are none, a notice that two-factor authentication has been turned off.  Its
+// button closes the dialog and refreshes the password section of the person
+// view.
+func showTOTPCodes(r *request.Request, p *person.Person, title string, codes []string) {
+	r.HTMLNoCache()
+	html := htmlb.HTML(r)
+	defer html.Close()
+	form := html.E("div class='form form-2col personeditTOTP' up-main")
+	form.E("div class='formTitle formTitle-primary'>%s", title)
+	if codes != nil {
+		login.ShowRecoveryCodes(r, form.E("div class=formRow-3col"), codes)
+	} else {
+		form.E("div class=formRow-3col").R(r.Loc("Two-factor authentication is turned off for your account."))
+	}
+	form.E("div class=formButtons").
+		E("a href=/people/%d up-layer=parent up-target=.personviewPassword class='sbtn sbtn-primary'", p.ID()).R(r.Loc("Done"))
+}
@@ -12,6 +12,7 @@ import (
 	"sunnyvaleserv.org/portal/pages/errpage"
 	"sunnyvaleserv.org/portal/pages/people/personview"
 	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/util"
 	"sunnyvaleserv.org/portal/util/config"
@@ -51,7 +52,7 @@ func HandleVRegister(r *request.Request, idstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
-	if (user.ID() != p.ID() && !user.IsAdminLeader()) || p.VolgisticsID() != 0 {
+	if (user.ID() != p.ID() && !auth.Can(user, enum.PermPersonEditClearance, 0)) || p.VolgisticsID() != 0 {
 		errpage.Forbidden(r, user)
 		return
 	}
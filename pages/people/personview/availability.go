@@ -0,0 +1,55 @@
+package personview
+
+import (
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/availability"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+func showAvailability(r *request.Request, main *htmlb.Element, user, p *person.Person) {
+	if user.ID() != p.ID() && !auth.Can(user, enum.PermPersonEdit, 0) {
+		return
+	}
+	a := availability.ForPerson(r, p.ID())
+	section := main.E("div class=personviewSection")
+	sheader := section.E("div class=personviewSectionHeader")
+	sheader.E("div class=personviewSectionHeaderText").R(r.Loc("Availability"))
+	sheader.E("div class=personviewSectionHeaderEdit").
+		E("a href=/people/%d/edavailability up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'", p.ID()).R(r.Loc("Edit"))
+	section = section.E("div class=personviewAvailability")
+	if len(a.Windows) == 0 {
+		section.E("div class=personviewAvailabilityNone").R(r.Loc("No usual availability recorded."))
+	}
+	for i := 0; i < len(a.Windows); {
+		var times []string
+		wd := a.Windows[i].Weekday
+		for ; i < len(a.Windows) && a.Windows[i].Weekday == wd; i++ {
+			times = append(times, a.Windows[i].Start+"–"+a.Windows[i].End)
+		}
+		section.E("div").R(r.Loc(wd.String()))
+		section.E("div").T(strings.Join(times, ", "))
+	}
+	today := time.Now().Format("2006-01-02")
+	for _, b := range a.Blackouts {
+		if b.End < today {
+			continue
+		}
+		section.E("div class=personviewAvailabilityBlackout").R(r.Loc("Unavailable"))
+		if b.Start == b.End {
+			section.E("div").T(formatBlackoutDate(b.Start))
+		} else {
+			section.E("div").TF("%s – %s", formatBlackoutDate(b.Start), formatBlackoutDate(b.End))
+		}
+	}
+}
+
+func formatBlackoutDate(date string) string {
+	t, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+	return formatDate(t)
+}
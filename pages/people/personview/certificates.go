@@ -0,0 +1,33 @@
+package personview
+
+import (
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/certificate"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+func showCertificates(r *request.Request, main *htmlb.Element, user, p *person.Person) {
+	var certs []*certificate.Certificate
+
+	if p.ID() != user.ID() && !auth.Can(user, enum.PermPersonEdit, 0) {
+		return
+	}
+	certificate.AllForPerson(r, p.ID(), func(c *certificate.Certificate) {
+		certs = append(certs, c.Clone())
+	})
+	if len(certs) == 0 {
+		return
+	}
+	section := main.E("div class=personviewSection")
+	sheader := section.E("div class=personviewSectionHeader")
+	sheader.E("div class=personviewSectionHeaderText").R(r.Loc("Certificates"))
+	grid := section.E("div class=personviewCertificates")
+	for _, c := range certs {
+		grid.E("div").T(c.Completed)
+		grid.E("div").T(r.Loc(c.Course))
+		grid.E("div").E("a href=/certificates/%s/pdf target=_blank>#%s", c.Token, c.Number())
+	}
+}
@@ -3,6 +3,7 @@ package personview
 import (
 	"strings"
 
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/util/htmlb"
@@ -12,7 +13,7 @@ import (
 const contactPersonFields = person.FEmail | person.FEmail2 | person.FCellPhone | person.FHomePhone | person.FWorkPhone | person.FAddresses | person.FEmContacts
 
 func showContact(r *request.Request, main *htmlb.Element, user, p *person.Person, viewLevel person.ViewLevel) {
-	editable := user.ID() == p.ID() || user.HasPrivLevel(0, enum.PrivLeader)
+	editable := user.ID() == p.ID() || auth.Can(user, enum.PermPersonEdit, 0)
 	if !editable && viewLevel < person.ViewWorkContact {
 		return
 	}
@@ -0,0 +1,27 @@
+package personview
+
+import (
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+func showData(r *request.Request, main *htmlb.Element, user, p *person.Person) {
+	if user.ID() == p.ID() {
+		section := main.E("div class=personviewSection")
+		sheader := section.E("div class=personviewSectionHeader")
+		sheader.E("div class=personviewSectionHeaderText").R(r.Loc("Your Data"))
+		section = section.E("div class=personviewData")
+		section.E("div").R(r.Loc("You can download a copy of all of the information we have about you."))
+		section.E("a href=/people/%d/data download class='sbtn sbtn-small sbtn-primary'", p.ID()).R(r.Loc("Download My Data"))
+	} else if auth.Can(user, enum.PermPersonData, 0) {
+		section := main.E("div class=personviewSection")
+		sheader := section.E("div class=personviewSectionHeader")
+		sheader.E("div class=personviewSectionHeaderText>Data")
+		section = section.E("div class=personviewData")
+		section.E("div>For a formal request, download a copy of all of the information we have about this person.")
+		section.E("a href=/people/%d/data download class='sbtn sbtn-small sbtn-primary'>Download Data", p.ID())
+	}
+}
@@ -0,0 +1,26 @@
+package personview
+
+import (
+	"sunnyvaleserv.org/portal/pages/history"
+	storehistory "sunnyvaleserv.org/portal/store/history"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+func showHistory(r *request.Request, main *htmlb.Element, user, p *person.Person, viewLevel person.ViewLevel) {
+	if !history.CanViewPersonHistory(user, viewLevel) {
+		return
+	}
+	section := main.E("div class=personviewSection")
+	sheader := section.E("div class=personviewSectionHeader")
+	sheader.E("div class=personviewSectionHeaderText>History")
+	section = section.E("div class=personviewHistory")
+	count, more := history.Show(r, section, storehistory.Person, int(p.ID()), history.PersonFilter(user, viewLevel), 5)
+	if count == 0 {
+		section.E("div>No changes have been recorded.")
+	}
+	if more {
+		section.E("a href=/people/%d/history up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'>Show All", p.ID())
+	}
+}
@@ -1,16 +1,19 @@
 package personview
 
 import (
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/ui"
 	"sunnyvaleserv.org/portal/util/htmlb"
 	"sunnyvaleserv.org/portal/util/request"
 )
 
-const namesPersonFields = person.FInformalName | person.FFormalName | person.FCallSign | person.FPronouns
+const namesPersonFields = person.FFlags | person.FInformalName | person.FFormalName | person.FCallSign | person.FPronouns
 
 func showNames(r *request.Request, main *htmlb.Element, user, p *person.Person) {
 	names := main.E("div class=personviewNames")
+	ui.PersonPhoto(names, p, "personviewNamesPhoto")
 	ifc := names.E("div class=personviewNamesIFC")
 	informal := ifc.E("div class=personviewNamesIC").
 		E("div class=personviewNamesInformal>%s", p.InformalName())
@@ -24,8 +27,9 @@ func showNames(r *request.Request, main *htmlb.Element, user, p *person.Person)
 	} else if p.Pronouns() != "" {
 		ifc.E("div class=personviewNamesFormal>(%s)", p.Pronouns())
 	}
-	if user.ID() == p.ID() || user.HasPrivLevel(0, enum.PrivLeader) {
-		names.E("div class=personviewNamesEdit").
-			E("a href=/people/%d/ednames up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'", p.ID()).R(r.Loc("Edit"))
+	if user.ID() == p.ID() || auth.Can(user, enum.PermPersonEdit, 0) {
+		buttons := names.E("div class=personviewNamesEdit")
+		buttons.E("a href=/people/%d/ednames up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'", p.ID()).R(r.Loc("Edit"))
+		buttons.E("a href=/people/%d/edphoto up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'", p.ID()).R(r.Loc("Photo"))
 	}
 }
@@ -1,6 +1,7 @@
 package personview
 
 import (
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/util/htmlb"
@@ -13,7 +14,7 @@ func showNotes(r *request.Request, main *htmlb.Element, user, p *person.Person,
 	var (
 		section  *htmlb.Element
 		notes    = p.Notes()
-		editable = user.HasPrivLevel(0, enum.PrivLeader)
+		editable = auth.Can(user, enum.PermPersonNotes, 0)
 	)
 	for i := len(notes) - 1; i >= 0; i-- {
 		n := notes[i]
@@ -1,8 +1,12 @@
 package personview
 
 import (
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/apitoken"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personpasskey"
+	"sunnyvaleserv.org/portal/store/persontotp"
 	"sunnyvaleserv.org/portal/util/htmlb"
 	"sunnyvaleserv.org/portal/util/request"
 )
@@ -11,9 +15,13 @@ func showPassword(r *request.Request, main *htmlb.Element, user, p *person.Perso
 	if p.Email() == "" {
 		return
 	}
-	canChange := user.ID() == p.ID() || user.IsWebmaster()
-	canReset := user.ID() != p.ID() && p.ID() != person.AdminID && user.HasPrivLevel(0, enum.PrivLeader)
-	if p.Email() == "" || (!canChange && !canReset) {
+	// Credentials can't be changed while a webmaster is viewing the site as
+	// someone else.
+	impersonating := auth.Impersonating(r)
+	canChange := !impersonating && (user.ID() == p.ID() || user.IsWebmaster())
+	canReset := !impersonating && user.ID() != p.ID() && p.ID() != person.AdminID && auth.Can(user, enum.PermPersonResetPassword, 0)
+	canImpersonate := auth.CanImpersonate(r, user, p)
+	if p.Email() == "" || (!canChange && !canReset && !canImpersonate) {
 		return
 	}
 	section := main.E("div class=personviewSection")
@@ -23,7 +31,32 @@ func showPassword(r *request.Request, main *htmlb.Element, user, p *person.Perso
 	if canChange {
 		section.E("a href=/people/%d/edpassword up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'", p.ID()).R(r.Loc("Change Password"))
 	}
+	if canChange && (user.ID() == p.ID() || personpasskey.CountForPerson(r, p.ID()) != 0) {
+		section.E("a href=/people/%d/edpasskeys up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'", p.ID()).R(r.Loc("Passkeys"))
+	}
 	if canReset {
 		section.E("a href=/people/%d/pwreset up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'>Reset Password", p.ID())
 	}
+	if canChange {
+		enrolled := persontotp.Get(r, p.ID()) != nil
+		if user.ID() == p.ID() && enrolled {
+			section.E("a href=/people/%d/edtotp up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'", p.ID()).R(r.Loc("Two-Factor Settings"))
+		} else if user.ID() == p.ID() {
+			section.E("a href=/people/%d/edtotp up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'", p.ID()).R(r.Loc("Set Up Two-Factor"))
+		} else if user.IsWebmaster() {
+			section.E("a href=/people/%d/edtotp up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'>Two-Factor Settings", p.ID())
+		}
+	}
+	if !impersonating && user.ID() == p.ID() && p.ID() != person.AdminID && auth.LoginLinksEnabled() && !auth.SSORequired(p) {
+		section.E("a href=/people/%d/edloginlink up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'", p.ID()).R(r.Loc("Sign-In Links"))
+	}
+	if canChange && p.ID() != person.AdminID && (user.ID() == p.ID() || apitoken.CountForPerson(r, p.ID()) != 0) {
+		section.E("a href=/people/%d/edapitokens up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'", p.ID()).R(r.Loc("API Tokens"))
+	}
+	if !impersonating && user.IsWebmaster() && p.ID() != person.AdminID && auth.SSOEnabled() {
+		section.E("a href=/people/%d/edsso up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'>Single Sign-On", p.ID())
+	}
+	if canImpersonate {
+		section.E("a href=/people/%d/impersonate up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-warning'>View Site As", p.ID())
+	}
 }
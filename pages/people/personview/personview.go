@@ -52,7 +52,7 @@ func Render(r *request.Request, user, p *person.Person, viewLevel person.ViewLev
 			{Name: r.Loc("Details"), URL: fmt.Sprintf("/people/%d", p.ID()), Target: "main", Active: true},
 		},
 	}
-	if user.ID() == p.ID() || user.HasPrivLevel(0, enum.PrivLeader) {
+	if user.ID() == p.ID() || auth.Can(user, enum.PermEventAttendance, 0) {
 		opts.Tabs = append(opts.Tabs, ui.PageTab{Name: r.Loc("Activity"), URL: fmt.Sprintf("/people/%d/activity/current", p.ID()), Target: "main"})
 	}
 	ui.Page(r, user, opts, func(main *htmlb.Element) {
@@ -70,14 +70,27 @@ func Render(r *request.Request, user, p *person.Person, viewLevel person.ViewLev
 		if section == "" || section == "status" {
 			showStatus(r, main, user, p)
 		}
+		if section == "" {
+			showCertificates(r, main, user, p)
+		}
 		if section == "" || section == "notes" {
 			showNotes(r, main, user, p, viewLevel)
 		}
+		if section == "" || section == "availability" {
+			showAvailability(r, main, user, p)
+		}
 		if section == "" || section == "subscriptions" {
 			showSubscriptions(r, main, user, p)
 		}
 		if section == "" || section == "password" {
 			showPassword(r, main, user, p)
 		}
+		if section == "" || section == "sessions" {
+			showSessions(r, main, user, p)
+		}
+		if section == "" {
+			showData(r, main, user, p)
+			showHistory(r, main, user, p, viewLevel)
+		}
 	})
 }
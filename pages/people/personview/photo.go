@@ -0,0 +1,43 @@
+package personview
+
+import (
+	"net/http"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// GetPhoto handles GET /people/${id}/photo requests.  The photo is visible to
+// anyone who can see the person at all.
+func GetPhoto(r *request.Request, idstr string) {
+	var user, p *person.Person
+
+	if user = auth.SessionUser(r, person.CanViewViewerFields, false); user == nil {
+		http.Error(r, "403 Forbidden", http.StatusForbidden)
+		return
+	}
+	if p = person.WithID(r, person.ID(util.ParseID(idstr)), person.CanViewTargetFields); p == nil || p.Flags()&person.HasPhoto == 0 {
+		errpage.NotFound(r, user)
+		return
+	}
+	if user.CanView(p) == person.ViewNone {
+		errpage.Forbidden(r, user)
+		return
+	}
+	fh := person.OpenPhoto(p.ID())
+	if fh == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	defer fh.Close()
+	stat, err := fh.Stat()
+	if err != nil {
+		panic(err)
+	}
+	r.Header().Set("Content-Type", "image/jpeg")
+	r.Header().Set("Cache-Control", "private, no-cache")
+	http.ServeContent(r, r.Request, "", stat.ModTime(), fh)
+}
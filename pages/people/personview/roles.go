@@ -3,6 +3,7 @@ package personview
 import (
 	"strings"
 
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/store/personrole"
@@ -21,7 +22,7 @@ func showRoles(r *request.Request, main *htmlb.Element, user, p *person.Person)
 	var (
 		badges   []*badgedata
 		held     = map[role.ID]bool{}
-		editable = user.HasPrivLevel(0, enum.PrivLeader) && p.ID() != person.AdminID
+		editable = auth.Can(user, enum.PermPersonRoles, 0) && p.ID() != person.AdminID
 	)
 	personrole.RolesForPerson(r, p.ID(), role.FID, func(rl *role.Role, _ bool) {
 		held[rl.ID()] = true
@@ -0,0 +1,125 @@
+package personview
+
+import (
+	"fmt"
+	"strings"
+
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personrole"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/store/session"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+func showSessions(r *request.Request, main *htmlb.Element, user, p *person.Person) {
+	var sessions []*session.Session
+
+	// Sessions can't be managed while a webmaster is viewing the site as
+	// someone else.
+	if auth.Impersonating(r) {
+		return
+	}
+	session.AllForPerson(r, p.ID(), func(s *session.Session) {
+		sessions = append(sessions, s)
+	})
+	if user.ID() == p.ID() {
+		showOwnSessions(r, main, p, sessions)
+		return
+	}
+	// Leaders can sign out everywhere a disabled person is still signed in.
+	if len(sessions) == 0 || !auth.Can(user, enum.PermPersonResetPassword, 0) {
+		return
+	}
+	if held, _ := personrole.PersonHasRole(r, p.ID(), role.Disabled); !held {
+		return
+	}
+	section := main.E("div class=personviewSection")
+	sheader := section.E("div class=personviewSectionHeader")
+	sheader.E("div class=personviewSectionHeaderText>Sessions")
+	form := section.E("form class=personviewSessions method=POST action=/people/%d/edsessions up-target=.personviewSessions", p.ID())
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	if len(sessions) == 1 {
+		form.E("div>%s is disabled but is still signed in on 1 device.", p.InformalName())
+	} else {
+		form.E("div>%s is disabled but is still signed in on %d devices.", p.InformalName(), len(sessions))
+	}
+	form.E("div class=personviewSessionsButtons").
+		E("button type=submit name=all value=1 class='sbtn sbtn-small sbtn-danger'>Sign Out All Sessions")
+}
+
+func showOwnSessions(r *request.Request, main *htmlb.Element, p *person.Person, sessions []*session.Session) {
+	section := main.E("div class=personviewSection")
+	sheader := section.E("div class=personviewSectionHeader")
+	sheader.E("div class=personviewSectionHeaderText").R(r.Loc("Your Sessions"))
+	form := section.E("form class=personviewSessions method=POST action=/people/%d/edsessions up-target=.personviewSessions", p.ID())
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	for _, s := range sessions {
+		row := form.E("div class=personviewSessionsItem")
+		info := row.E("div class=personviewSessionsInfo")
+		info.E("div class=personviewSessionsDevice").T(describeUserAgent(r, s.UserAgent))
+		meta := info.E("div class=personviewSessionsMeta")
+		if s.IP != "" {
+			meta.T(s.IP).T("; ")
+		}
+		meta.TF(r.Loc("signed in %s"), s.Created.Format("2006-01-02"))
+		if s.Token == r.SessionToken {
+			info.E("div class=personviewSessionsCurrent").R(r.Loc("This device"))
+		} else {
+			meta.T("; ").TF(r.Loc("last active %s"), s.LastUsed.Format("2006-01-02 15:04"))
+			row.E("button type=submit name=revoke value=%s class='sbtn sbtn-small sbtn-danger'", s.Handle()).R(r.Loc("Sign Out"))
+		}
+	}
+	if len(sessions) > 1 {
+		form.E("div class=personviewSessionsButtons").
+			E("button type=submit name=others value=1 class='sbtn sbtn-small sbtn-danger'").R(r.Loc("Sign Out Everywhere Else"))
+	}
+}
+
+// describeUserAgent returns a short description of the browser and operating
+// system named in a User-Agent header, such as "Chrome on Windows".  It only
+// recognizes the common ones; it is not meant to be exact.
+func describeUserAgent(r *request.Request, ua string) string {
+	var browser, os string
+
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(ua, "Firefox/") || strings.Contains(ua, "FxiOS/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Chrome/") || strings.Contains(ua, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	}
+	switch {
+	case strings.Contains(ua, "iPhone"):
+		os = "iPhone"
+	case strings.Contains(ua, "iPad"):
+		os = "iPad"
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "Mac OS X") || strings.Contains(ua, "Macintosh"):
+		os = "Mac"
+	case strings.Contains(ua, "CrOS"):
+		os = "ChromeOS"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	}
+	switch {
+	case browser != "" && os != "":
+		return fmt.Sprintf(r.Loc("%s on %s"), browser, os)
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return r.Loc("Unknown browser")
+	}
+}
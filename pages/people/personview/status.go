@@ -3,6 +3,7 @@ package personview
 import (
 	"time"
 
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/util/htmlb"
@@ -12,32 +13,36 @@ import (
 const statusPersonFields = person.FPrivLevels | person.FVolgisticsID | person.FDSWRegistrations | person.FBGChecks | person.FIdentification | person.FFlags
 
 func showStatus(r *request.Request, main *htmlb.Element, user, p *person.Person) {
-	if p.ID() != user.ID() && !user.HasPrivLevel(0, enum.PrivLeader) {
+	if p.ID() != user.ID() && !auth.Can(user, enum.PermPersonViewClearance, 0) {
 		return
 	}
 	section := main.E("div class=personviewSection")
 	sheader := section.E("div class=personviewSectionHeader")
 	sheader.E("div class=personviewSectionHeaderText").R(r.Loc("Volunteer Status"))
-	if user.IsAdminLeader() {
+	if auth.Can(user, enum.PermPersonEditClearance, 0) {
 		sheader.E("div class=personviewSectionHeaderEdit").
 			E("a href=/people/%d/edstatus up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'>Edit", p.ID())
 	}
 	section = section.E("div class=personviewStatus")
+	if p.Flags()&person.Archived != 0 && auth.Can(user, enum.PermPersonEdit, 0) {
+		section.E("div>Archived")
+		section.E("div>Contact information removed")
+	}
 	showVolgistics(r, section, user, p)
 	showDSWCERT(r, section, p)
 	showDSWCommunications(r, section, p)
-	if user.IsAdminLeader() {
+	if auth.Can(user, enum.PermPersonEditClearance, 0) {
 		showBGChecksAL(section, p)
 	} else {
 		showBGChecksNotAL(r, section, user, p)
 	}
-	if user.HasPrivLevel(0, enum.PrivLeader) {
+	if auth.Can(user, enum.PermPersonViewClearance, 0) {
 		showIdentifications(section, p)
 	}
 }
 
 func showVolgistics(r *request.Request, section *htmlb.Element, user, p *person.Person) {
-	if user.IsAdminLeader() {
+	if auth.Can(user, enum.PermPersonEditClearance, 0) {
 		section.E("div>Volgistics")
 		if p.VolgisticsID() != 0 {
 			section.E("div>#%d", p.VolgisticsID())
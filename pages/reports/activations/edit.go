@@ -0,0 +1,185 @@
+package actrep
+
+import (
+	"fmt"
+	"strings"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/activation"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/folder"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/ui/form"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// HandleEdit handles /reports/activations/$id/edit requests, where $id may be
+// "NEW".
+func HandleEdit(r *request.Request, idstr string) {
+	var (
+		user    *person.Person
+		a       *activation.Activation
+		ua      *activation.Updater
+		folders []*folder.Folder
+		paths   string
+		f       form.Form
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if !auth.Can(user, enum.PermActivationEdit, 0) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	f.Attrs = "method=POST up-target=main"
+	f.Dialog = true
+	f.Buttons = []*form.Button{{
+		Label:   "Save",
+		OnClick: func() bool { return saveActivation(r, user, a, ua, folders) },
+	}}
+	if idstr == "NEW" {
+		ua = &activation.Updater{Type: activation.Real}
+		f.Title = "New Activation"
+	} else {
+		if a = activation.WithID(r, activation.ID(util.ParseID(idstr)), activation.UpdaterFields); a == nil {
+			errpage.NotFound(r, user)
+			return
+		}
+		ua = a.Updater()
+		f.Title = "Edit Activation"
+		var pathlist []string
+		a.Folders(r, folder.FID|folder.FParent|folder.FURLName, func(f *folder.Folder) {
+			pathlist = append(pathlist, f.Path(r))
+		})
+		paths = strings.Join(pathlist, "\n")
+		f.Buttons = append(f.Buttons, &form.Button{
+			Name: "delete", Label: "Delete", Style: "danger",
+			OnClick: func() bool { return deleteActivation(r, user, a) },
+		})
+	}
+	f.Rows = []form.Row{
+		&numberRow{form.TextInputRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "actrepNumber",
+				Label: "Number",
+				Help:  "Sunnyvale OES activation number.  Events with this activation number are part of this activation.",
+			},
+			Name:   "number",
+			ValueP: &ua.Number,
+		}, ua},
+		&form.RadioGroupRow[activation.Type]{
+			LabeledRow: form.LabeledRow{
+				RowID: "actrepType",
+				Label: "Type",
+			},
+			Name:    "type",
+			ValueP:  &ua.Type,
+			Options: activation.AllTypes,
+			LabelFunc: func(_ *request.Request, t activation.Type) string {
+				return t.String()
+			},
+			Validate: form.NoValidate,
+		},
+		&form.TextInputRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "actrepAgency",
+				Label: "Requesting Agency",
+			},
+			Name:   "agency",
+			ValueP: &ua.Agency,
+		},
+		&form.TextInputRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "actrepCommander",
+				Label: "Incident Commander",
+			},
+			Name:   "commander",
+			ValueP: &ua.Commander,
+		},
+		&foldersRow{form.TextAreaRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "actrepFolders",
+				Label: "Folders",
+				Help:  "Files folders with documents for this activation, one per line (e.g., /files/activations/2024-03).",
+			},
+			Name:     "folders",
+			ValueP:   &paths,
+			Wrap:     "off",
+			Validate: form.NoValidate,
+		}, &folders},
+		&form.TextAreaRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "actrepNotes",
+				Label: "After-Action Notes",
+			},
+			Name:     "notes",
+			ValueP:   &ua.Notes,
+			Validate: form.NoValidate,
+		},
+	}
+	f.Handle(r)
+}
+
+type numberRow struct {
+	form.TextInputRow
+	ua *activation.Updater
+}
+
+func (nr *numberRow) Read(r *request.Request) bool {
+	if !nr.TextInputRow.Read(r) {
+		return false
+	}
+	nr.ua.Number = strings.ToUpper(nr.ua.Number)
+	if nr.ua.Number == "" {
+		nr.Error = "The activation number is required."
+		return false
+	} else if nr.ua.DuplicateNumber(r) {
+		nr.Error = "Another activation has this number."
+		return false
+	}
+	return true
+}
+
+type foldersRow struct {
+	form.TextAreaRow
+	folders *[]*folder.Folder
+}
+
+func (fr *foldersRow) Read(r *request.Request) bool {
+	if !fr.TextAreaRow.Read(r) {
+		return false
+	}
+	*fr.folders = (*fr.folders)[:0]
+	for _, path := range strings.Fields(*fr.ValueP) {
+		flist, docname := folder.WithPath(r, strings.TrimSuffix(path, "/"), folder.FID|folder.FName)
+		if len(flist) < 2 || docname != "" {
+			fr.Error = fmt.Sprintf("%q is not the path of a folder.", path)
+			return false
+		}
+		*fr.folders = append(*fr.folders, flist[len(flist)-1])
+	}
+	return true
+}
+
+func saveActivation(r *request.Request, user *person.Person, a *activation.Activation, ua *activation.Updater, folders []*folder.Folder) bool {
+	r.Transaction(func() {
+		if a == nil {
+			a = activation.Create(r, ua)
+		} else {
+			a.Update(r, ua)
+		}
+		a.SetFolders(r, folders)
+	})
+	Render(r, user, a)
+	return true
+}
+
+func deleteActivation(r *request.Request, user *person.Person, a *activation.Activation) bool {
+	r.Transaction(func() {
+		a.Delete(r)
+	})
+	RenderList(r, user)
+	return true
+}
@@ -0,0 +1,63 @@
+// Package actrep contains the pages for managing activations and reporting on
+// their deployments.
+package actrep
+
+import (
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/activation"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+var reportTabs = []ui.PageTab{
+	{Name: "Attendance", URL: "/reports/attendance", Alias: "/reports/attendance?*", Target: "main"},
+	{Name: "Clearance", URL: "/reports/clearance", Alias: "/reports/clearance?*", Target: "main"},
+	{Name: "Activations", URL: "/reports/activations", Alias: "/reports/activations/*", Target: "main", Active: true},
+	{Name: "Classes", URL: "/reports/classes", Alias: "/reports/classes?*", Target: "main"},
+}
+
+// GetList handles GET /reports/activations requests.
+func GetList(r *request.Request) {
+	var user *person.Person
+
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if !auth.Can(user, enum.PermActivationView, 0) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	RenderList(r, user)
+}
+
+// RenderList renders the list of activations.
+func RenderList(r *request.Request, user *person.Person) {
+	r.HTMLNoCache()
+	ui.Page(r, user, ui.PageOpts{
+		Title:    "Activations",
+		MenuItem: "reports",
+		Tabs:     reportTabs,
+	}, func(main *htmlb.Element) {
+		grid := main.E("div class=actrepListGrid")
+		row := grid.E("div class=actrepListHeading")
+		row.E("div>Number")
+		row.E("div>Type")
+		row.E("div>Status")
+		row.E("div>Requesting Agency")
+		activation.All(r, activation.FID|activation.FNumber|activation.FType|activation.FAgency, func(a *activation.Activation) {
+			row = grid.E("div class=actrepListRow")
+			row.E("div").E("a href=/reports/activations/%d up-target=main", a.ID()).T(a.Number())
+			row.E("div").T(a.Type().String())
+			row.E("div").T(a.CurrentStatus(r).String())
+			row.E("div").T(a.Agency())
+		})
+		if auth.Can(user, enum.PermActivationEdit, 0) {
+			main.E("div class=actrepListButtons").
+				E("a href=/reports/activations/NEW/edit up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-primary'>Add Activation")
+		}
+	})
+}
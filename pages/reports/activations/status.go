@@ -0,0 +1,126 @@
+package actrep
+
+import (
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/activation"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/ui/form"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// HandleStatus handles /reports/activations/$id/status requests.
+func HandleStatus(r *request.Request, idstr string) {
+	var (
+		user  *person.Person
+		a     *activation.Activation
+		se    activation.StatusEntry
+		date  string
+		clock string
+		f     form.Form
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if !auth.Can(user, enum.PermActivationEdit, 0) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if a = activation.WithID(r, activation.ID(util.ParseID(idstr)), viewActivationFields); a == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	now := time.Now()
+	date, clock = now.Format("2006-01-02"), now.Format("15:04")
+	se.Status = a.CurrentStatus(r) + 1
+	if se.Status > activation.Closed {
+		se.Status = activation.Closed
+	}
+	f.Attrs = "method=POST up-target=main"
+	f.Dialog = true
+	f.Title = "Add Status"
+	f.Buttons = []*form.Button{{
+		Label: "Add",
+		OnClick: func() bool {
+			se.Timestamp = date + "T" + clock
+			r.Transaction(func() {
+				a.AddStatus(r, &se)
+			})
+			Render(r, user, a)
+			return true
+		},
+	}}
+	f.Rows = []form.Row{
+		&form.SelectRow[activation.Status]{
+			LabeledRow: form.LabeledRow{
+				RowID: "actrepStatus",
+				Label: "Status",
+			},
+			Name:    "status",
+			ValueP:  &se.Status,
+			Options: activation.AllStatuses,
+			LabelFunc: func(_ *request.Request, s activation.Status) string {
+				return s.String()
+			},
+			Validate: form.NoValidate,
+		},
+		&requiredDateRow{form.DateRow{InputRow: form.InputRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "actrepDate",
+				Label: "Date",
+			},
+			Name:   "date",
+			ValueP: &date,
+		}}},
+		&timeRow{form.InputRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "actrepTime",
+				Label: "Time",
+			},
+			Name:   "time",
+			ValueP: &clock,
+		}},
+		&form.TextInputRow{
+			LabeledRow: form.LabeledRow{
+				RowID: "actrepNote",
+				Label: "Note",
+			},
+			Name:   "note",
+			ValueP: &se.Note,
+		},
+	}
+	f.Handle(r)
+}
+
+type requiredDateRow struct{ form.DateRow }
+
+func (dr *requiredDateRow) Read(r *request.Request) bool {
+	if !dr.DateRow.Read(r) {
+		return false
+	}
+	if *dr.ValueP == "" {
+		dr.Error = "The date is required."
+		return false
+	}
+	return true
+}
+
+type timeRow struct{ form.InputRow }
+
+func (tr *timeRow) Emit(r *request.Request, parent *htmlb.Element, focus bool) {
+	tr.Validate = form.NoValidate
+	tr.EmitSuffix(r, tr.EmitPrefix(r, parent, focus).A("type=time"))
+}
+func (tr *timeRow) Read(r *request.Request) bool {
+	tr.InputRow.Read(r)
+	if _, err := time.Parse("15:04", *tr.ValueP); err != nil {
+		tr.Error = "The time must be in HH:MM format."
+		return false
+	}
+	return true
+}
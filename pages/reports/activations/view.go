@@ -0,0 +1,341 @@
+package actrep
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/activation"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/folder"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/task"
+	"sunnyvaleserv.org/portal/store/taskperson"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/ui/orgdot"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+const viewActivationFields = activation.FID | activation.FNumber | activation.FType | activation.FAgency | activation.FCommander | activation.FNotes
+
+// deployment is the rollup of one person's participation in an activation.
+type deployment struct {
+	id       person.ID
+	sortName string
+	tasks    int
+	minutes  uint
+	// dswNeeded is true if any of the person's tasks were covered by DSW.
+	dswNeeded bool
+	// dswOK is true if the person had a DSW registration in effect for
+	// every such task.
+	dswOK bool
+}
+
+// Get handles /reports/activations/$id requests.
+func Get(r *request.Request, idstr string) {
+	var (
+		user *person.Person
+		a    *activation.Activation
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
+		return
+	}
+	if !auth.Can(user, enum.PermActivationView, 0) {
+		errpage.Forbidden(r, user)
+		return
+	}
+	if a = activation.WithID(r, activation.ID(util.ParseID(idstr)), viewActivationFields); a == nil {
+		errpage.NotFound(r, user)
+		return
+	}
+	if r.Method == http.MethodPost && auth.Can(user, enum.PermActivationEdit, 0) {
+		if sid := util.ParseID(r.FormValue("removeStatus")); sid != 0 {
+			r.Transaction(func() {
+				a.RemoveStatus(r, activation.StatusEntryID(sid))
+			})
+		}
+	}
+	if r.FormValue("format") == "csv" {
+		renderCSV(r, a, getDeployments(r, a))
+		return
+	}
+	Render(r, user, a)
+}
+
+// Render renders the activation view page.
+func Render(r *request.Request, user *person.Person, a *activation.Activation) {
+	var editable = auth.Can(user, enum.PermActivationEdit, 0)
+
+	r.HTMLNoCache()
+	ui.Page(r, user, ui.PageOpts{
+		Title:    a.Number(),
+		Banner:   "Activation " + a.Number(),
+		MenuItem: "reports",
+		Tabs:     reportTabs,
+	}, func(main *htmlb.Element) {
+		box := main.E("div class=actrepView")
+		showIdent(r, box, a)
+		showDetails(r, box, a, editable)
+		showTimeline(r, box, a, editable)
+		showEvents(r, box, a)
+		showFolders(r, user, box, a)
+		showDeployed(r, box, a)
+		showNotes(box, a, editable)
+	})
+}
+
+func showIdent(r *request.Request, main *htmlb.Element, a *activation.Activation) {
+	ident := main.E("div class=actrepIdent")
+	ident.E("span class=actrepIdentNumber").T(a.Number())
+	ident.E("span class='actrepIdentType actrepIdentType-%s'", a.Type()).T(a.Type().String())
+	if status := a.CurrentStatus(r); status != 0 {
+		ident.E("span class=actrepIdentStatus").T(status.String())
+	}
+}
+
+func startSection(main *htmlb.Element, title, editURL, editLabel string) *htmlb.Element {
+	section := main.E("div class=actrepSection")
+	sheader := section.E("div class=actrepSectionHeader")
+	sheader.E("div class=actrepSectionHeaderText").T(title)
+	if editURL != "" {
+		sheader.E("div class=actrepSectionHeaderEdit").
+			E("a href=%s up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-small sbtn-primary'", editURL).T(editLabel)
+	}
+	return section
+}
+
+func showDetails(_ *request.Request, main *htmlb.Element, a *activation.Activation, editable bool) {
+	var editURL string
+
+	if editable {
+		editURL = fmt.Sprintf("/reports/activations/%d/edit", a.ID())
+	}
+	section := startSection(main, "Details", editURL, "Edit")
+	grid := section.E("div class=actrepDetails")
+	grid.E("div>Type")
+	grid.E("div").T(a.Type().String())
+	grid.E("div>Requesting Agency")
+	grid.E("div").T(a.Agency())
+	grid.E("div>Incident Commander")
+	grid.E("div").T(a.Commander())
+}
+
+func showTimeline(r *request.Request, main *htmlb.Element, a *activation.Activation, editable bool) {
+	var editURL string
+
+	if editable {
+		editURL = fmt.Sprintf("/reports/activations/%d/status", a.ID())
+	}
+	section := startSection(main, "Status Timeline", editURL, "Add")
+	entries := a.Timeline(r)
+	if len(entries) == 0 {
+		section.E("div class=actrepEmpty>No status has been recorded.")
+		return
+	}
+	form := section.E("form class=actrepTimeline method=POST up-target=main")
+	form.E("input type=hidden name=csrf value=%s", r.CSRF)
+	for _, se := range entries {
+		form.E("div class=actrepTimelineTime").T(formatTimestamp(se.Timestamp))
+		form.E("div class=actrepTimelineStatus").T(se.Status.String())
+		remove := form.E("div class=actrepTimelineRemove")
+		if editable {
+			remove.E("button type=submit name=removeStatus value=%d class='sbtn sbtn-xsmall sbtn-danger' title=Remove", se.ID).R("&times;")
+		}
+		if se.Note != "" {
+			form.E("div class=actrepTimelineNote").T(se.Note)
+		}
+	}
+}
+
+func showEvents(r *request.Request, main *htmlb.Element, a *activation.Activation) {
+	section := startSection(main, "Events and Tasks", "", "")
+	list := section.E("div class=actrepEvents")
+	var found bool
+	a.Events(r, event.FID|event.FName|event.FStart, func(e *event.Event) {
+		found = true
+		ediv := list.E("div class=actrepEvent")
+		ediv.E("a href=/events/%d up-target=.pageCanvas", e.ID()).T(e.Start()[:10] + " " + e.Name())
+		task.AllForEvent(r, e.ID(), task.FName|task.FOrg|task.FFlags, func(t *task.Task) {
+			tdiv := list.E("div class=actrepTask")
+			orgdot.OrgDot(r, tdiv, t.Org())
+			tdiv.T(t.Name())
+			if t.Flags()&task.CoveredByDSW != 0 {
+				tdiv.E("span class=actrepDSW>DSW")
+			}
+		})
+	})
+	if !found {
+		section.E("div class=actrepEmpty").TF("No events have activation number %s.", a.Number())
+	}
+}
+
+func showFolders(r *request.Request, user *person.Person, main *htmlb.Element, a *activation.Activation) {
+	var folders []*folder.Folder
+
+	a.Folders(r, folder.FID|folder.FParent|folder.FName|folder.FURLName|folder.FViewer, func(f *folder.Folder) {
+		if !user.HasPrivLevel(f.Viewer()) {
+			return
+		}
+		clone := *f
+		folders = append(folders, &clone)
+	})
+	if len(folders) == 0 {
+		return
+	}
+	section := startSection(main, "Folders", "", "")
+	list := section.E("div class=actrepFolders")
+	for _, f := range folders {
+		list.E("div").E("a href=%s up-target=.pageCanvas", f.Path(r)).T(f.Name())
+	}
+}
+
+func showDeployed(r *request.Request, main *htmlb.Element, a *activation.Activation) {
+	var (
+		deployments = getDeployments(r, a)
+		minutes     uint
+		missingDSW  int
+	)
+	section := startSection(main, "Deployed Volunteers", "", "")
+	if len(deployments) == 0 {
+		section.E("div class=actrepEmpty>No one has been recorded as deployed.")
+		return
+	}
+	grid := section.E("div class=actrepDeployed")
+	row := grid.E("div class=actrepDeployedHeading")
+	row.E("div>Name")
+	row.E("div>Tasks")
+	row.E("div>Hours")
+	row.E("div>DSW")
+	for _, d := range deployments {
+		row = grid.E("div class=actrepDeployedRow")
+		row.E("div").E("a href=/people/%d up-target=.pageCanvas", d.id).T(d.sortName)
+		row.E("div").T(strconv.Itoa(d.tasks))
+		row.E("div").T(formatHours(d.minutes))
+		switch {
+		case !d.dswNeeded:
+			row.E("div").R("&mdash;")
+		case d.dswOK:
+			row.E("div>Yes")
+		default:
+			row.E("div class=actrepDeployedNoDSW>No")
+			missingDSW++
+		}
+		minutes += d.minutes
+	}
+	row = grid.E("div class=actrepDeployedTotal")
+	row.E("div").TF("%d people", len(deployments))
+	row.E("div")
+	row.E("div").T(formatHours(minutes))
+	if missingDSW != 0 {
+		row.E("div").TF("%d missing", missingDSW)
+	} else {
+		row.E("div")
+	}
+	section.E("div class=actrepButtons").
+		E("a href=/reports/activations/%d?format=csv download class='sbtn sbtn-small sbtn-primary'>Export", a.ID())
+}
+
+func showNotes(main *htmlb.Element, a *activation.Activation, editable bool) {
+	var editURL string
+
+	if a.Notes() == "" && !editable {
+		return
+	}
+	if editable {
+		editURL = fmt.Sprintf("/reports/activations/%d/edit", a.ID())
+	}
+	section := startSection(main, "After-Action Notes", editURL, "Edit")
+	if a.Notes() != "" {
+		section.E("div class=actrepNotes").T(a.Notes())
+	} else {
+		section.E("div class=actrepEmpty>No after-action notes have been recorded.")
+	}
+}
+
+// getDeployments returns the rollup of deployments for the activation, in
+// order by person name.
+func getDeployments(r *request.Request, a *activation.Activation) (deployments []*deployment) {
+	var byPerson = make(map[person.ID]*deployment)
+
+	a.Deployments(r, event.FStart, task.FOrg|task.FFlags, person.FID|person.FSortName,
+		func(e *event.Event, t *task.Task, p *person.Person, minutes uint, flags taskperson.Flag) {
+			d := byPerson[p.ID()]
+			if d == nil {
+				d = &deployment{id: p.ID(), sortName: p.SortName(), dswOK: true}
+				byPerson[p.ID()] = d
+				deployments = append(deployments, d)
+			}
+			d.tasks++
+			d.minutes += minutes
+			if t.Flags()&task.CoveredByDSW != 0 {
+				d.dswNeeded = true
+				if d.dswOK && !dswInEffect(r, p.ID(), t.Org(), e.Start()) {
+					d.dswOK = false
+				}
+			}
+		})
+	slices.SortFunc(deployments, func(a, b *deployment) int {
+		return strings.Compare(a.sortName, b.sortName)
+	})
+	return deployments
+}
+
+// dswInEffect returns whether the specified person had a DSW registration
+// appropriate for the specified org in effect at the specified time.
+func dswInEffect(r *request.Request, pid person.ID, org enum.Org, when string) bool {
+	p := person.WithID(r, pid, person.FDSWRegistrations)
+	reg, ok := p.DSWRegistrationForOrg(org)
+	if !ok {
+		return true // org has no DSW classification
+	}
+	if reg == nil || reg.Registered.IsZero() {
+		return false
+	}
+	date, _ := time.ParseInLocation("2006-01-02", when[:10], time.Local)
+	if reg.Registered.After(date) {
+		return false
+	}
+	return reg.Expiration.IsZero() || reg.Expiration.After(date)
+}
+
+func renderCSV(r *request.Request, a *activation.Activation, deployments []*deployment) {
+	var out = csv.NewWriter(r)
+
+	r.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	r.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="activation-%s.csv"`, a.Number()))
+	out.UseCRLF = true
+	out.Write([]string{"Name", "Tasks", "Hours", "DSW"})
+	for _, d := range deployments {
+		var dsw string
+		switch {
+		case !d.dswNeeded:
+			dsw = ""
+		case d.dswOK:
+			dsw = "Y"
+		default:
+			dsw = "N"
+		}
+		out.Write([]string{d.sortName, strconv.Itoa(d.tasks), formatHours(d.minutes), dsw})
+	}
+	out.Flush()
+}
+
+func formatHours(minutes uint) string {
+	return strconv.FormatFloat(float64(minutes)/60.0, 'f', 1, 64)
+}
+
+func formatTimestamp(ts string) string {
+	if t, err := time.ParseInLocation("2006-01-02T15:04", ts, time.Local); err == nil {
+		return t.Format("2006-01-02 15:04")
+	}
+	return ts
+}
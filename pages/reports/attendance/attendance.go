@@ -66,6 +66,8 @@ func Get(r *request.Request) {
 		Tabs: []ui.PageTab{
 			{Name: "Attendance", URL: "/reports/attendance", Alias: "/reports/attendance?*", Target: "main", Active: true},
 			{Name: "Clearance", URL: "/reports/clearance", Alias: "/reports/clearance?*", Target: "main"},
+			{Name: "Activations", URL: "/reports/activations", Target: "main"},
+			{Name: "Classes", URL: "/reports/classes", Alias: "/reports/classes?*", Target: "main"},
 		},
 	}, func(e *htmlb.Element) {
 		e.Attr("class=attrep")
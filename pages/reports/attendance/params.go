@@ -3,6 +3,7 @@ package attrep
 import (
 	"time"
 
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/util"
@@ -129,7 +130,7 @@ func renderParams(main *htmlb.Element, params parameters) {
 func allowedOrgs(user *person.Person) (orgs map[enum.Org]bool) {
 	orgs = make(map[enum.Org]bool)
 	for _, o := range enum.AllOrgs {
-		if user.HasPrivLevel(o, enum.PrivLeader) {
+		if auth.Can(user, enum.PermEventAttendance, o) {
 			orgs[o] = true
 		}
 	}
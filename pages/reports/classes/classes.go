@@ -0,0 +1,310 @@
+// Package classrep contains the report on class registrations, completions,
+// and referral sources.
+package classrep
+
+import (
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/classreg"
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/referral"
+	"sunnyvaleserv.org/portal/ui"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// colors are the colors used for referral sources in the chart, in the order
+// of the sources.  They are reused if there are more sources than colors.
+var colors = []string{"#060", "#36c", "#c93", "#c33", "#939", "#399", "#f90", "#666"}
+
+// rowdata holds the statistics for the classes of one type in one month.
+type rowdata struct {
+	month     string // YYYY-MM
+	ctype     class.Type
+	classes   int
+	regs      int
+	finished  int // registrations in finished classes with sessions
+	completed int
+	sources   map[referral.ID]int
+	campaigns map[string]int
+}
+
+// Get handles GET /reports/classes requests.
+func Get(r *request.Request) {
+	var (
+		user    *person.Person
+		params  parameters
+		sources []*referral.Source
+		data    []*rowdata
+	)
+	if user = auth.SessionUser(r, 0, true); user == nil {
+		return
+	}
+	if params = readParameters(r, user); len(params.allowedTypes) == 0 {
+		errpage.Forbidden(r, user)
+		return
+	}
+	referral.All(r, func(s *referral.Source) {
+		sources = append(sources, s.Clone())
+	})
+	data = getData(r, params)
+	if params.renderCSV {
+		renderCSV(r, data, sources)
+		return
+	}
+	ui.Page(r, user, ui.PageOpts{
+		Title:    "Classes",
+		Banner:   "Classes Report",
+		MenuItem: "reports",
+		Tabs: []ui.PageTab{
+			{Name: "Attendance", URL: "/reports/attendance", Alias: "/reports/attendance?*", Target: "main"},
+			{Name: "Clearance", URL: "/reports/clearance", Alias: "/reports/clearance?*", Target: "main"},
+			{Name: "Activations", URL: "/reports/activations", Target: "main"},
+			{Name: "Classes", URL: "/reports/classes", Alias: "/reports/classes?*", Target: "main", Active: true},
+		},
+	}, func(main *htmlb.Element) {
+		renderParams(main, params)
+		renderReport(main.E("div class=classrepReport"), data, sources)
+	})
+}
+
+// getData gathers the statistics for the classes selected by the parameters,
+// grouped by month and class type.  Classes whose registrations are handled on
+// another site are omitted, since we have no data for them.
+func getData(r *request.Request, params parameters) (data []*rowdata) {
+	var (
+		classes []*class.Class
+		rows    = make(map[string]*rowdata)
+		today   = time.Now().Format("2006-01-02")
+	)
+	class.All(r, class.FID|class.FType|class.FStart|class.FRegURL|class.FRole, func(c *class.Class) {
+		if c.RegURL() != "" || !slices.Contains(params.allowedTypes, c.Type()) {
+			return
+		}
+		if params.ctype != 0 && c.Type() != params.ctype {
+			return
+		}
+		if params.year != 0 && c.Start()[:4] != strconv.Itoa(params.year) {
+			return
+		}
+		classes = append(classes, c.Clone())
+	})
+	for _, c := range classes {
+		var (
+			sids     []event.ID
+			last     string
+			finished bool
+			key      = fmt.Sprintf("%s %d", c.Start()[:7], c.Type())
+			row      = rows[key]
+		)
+		if row == nil {
+			row = &rowdata{month: c.Start()[:7], ctype: c.Type(), sources: make(map[referral.ID]int), campaigns: make(map[string]int)}
+			rows[key] = row
+			data = append(data, row)
+		}
+		row.classes++
+		c.Sessions(r, event.FID|event.FStart, func(e *event.Event) {
+			sids = append(sids, e.ID())
+			last = max(last, e.Start()[:10])
+		})
+		// Completion rates are computed only for classes that are
+		// over, so that classes in progress don't drag them down.
+		finished = len(sids) != 0 && last < today
+		attendance := c.Attendance(r)
+		unanswered := 0
+		classreg.AllForClass(r, c.ID(), classreg.FPerson|classreg.FWaitlist|classreg.FReferral|classreg.FCampaign, func(cr *classreg.ClassReg) {
+			if cr.Waitlist() {
+				return
+			}
+			row.regs++
+			if cr.Referral() == 0 {
+				unanswered++
+			} else {
+				row.sources[cr.Referral()]++
+			}
+			if cr.Campaign() != "" {
+				row.campaigns[cr.Campaign()]++
+			}
+			if finished {
+				row.finished++
+				if cr.Person() != 0 && class.Completed(sids, attendance[cr.Person()]) {
+					row.completed++
+				}
+			}
+		})
+		// Classes from before referral sources were recorded with
+		// each registration have per-class counts instead.  Those
+		// registrations show as unanswered, so the counts replace
+		// them.
+		for id, count := range class.LegacyReferrals(r, c.ID()) {
+			row.sources[id] += count
+			unanswered -= min(count, unanswered)
+		}
+		row.sources[0] += unanswered
+	}
+	slices.SortFunc(data, func(a, b *rowdata) int {
+		if a.month != b.month {
+			if a.month < b.month {
+				return -1
+			}
+			return 1
+		}
+		return int(a.ctype) - int(b.ctype)
+	})
+	return data
+}
+
+func renderReport(main *htmlb.Element, data []*rowdata, sources []*referral.Source) {
+	var (
+		total      = rowdata{sources: make(map[referral.ID]int), campaigns: make(map[string]int)}
+		maxRegs    int
+		used       []*referral.Source
+		codes      []string
+		unanswered bool
+	)
+	if len(data) == 0 {
+		main.E("div class=classrepNoData>There are no classes matching these criteria.")
+		return
+	}
+	for _, row := range data {
+		total.classes += row.classes
+		total.regs += row.regs
+		total.finished += row.finished
+		total.completed += row.completed
+		for id, count := range row.sources {
+			total.sources[id] += count
+		}
+		for code, count := range row.campaigns {
+			if total.campaigns[code] == 0 {
+				codes = append(codes, code)
+			}
+			total.campaigns[code] += count
+		}
+		maxRegs = max(maxRegs, row.regs)
+	}
+	for _, s := range sources {
+		if !s.Archived || total.sources[s.ID] != 0 {
+			used = append(used, s)
+		}
+	}
+	unanswered = total.sources[0] != 0
+	legend := main.E("div class=classrepLegend")
+	for i, s := range used {
+		item := legend.E("div")
+		item.E("span class=classrepSwatch style=background-color:%s", colors[i%len(colors)])
+		item.T(s.EnName)
+	}
+	if unanswered {
+		item := legend.E("div")
+		item.E("span class=classrepSwatch style=background-color:#ccc")
+		item.R("Not answered")
+	}
+	table := main.E("div class=classrepTable")
+	table.E("div class=classrepHeading>Month")
+	table.E("div class=classrepHeading>Class")
+	table.E("div class=classrepHeading>Classes")
+	table.E("div class=classrepHeading>Registrations")
+	table.E("div class=classrepHeading>Completed")
+	table.E("div class=classrepHeading>Referred by")
+	for _, row := range data {
+		renderRow(table, row, row.month, row.ctype.String(), maxRegs, used, unanswered)
+	}
+	renderRow(table, &total, "Total", "", 0, used, unanswered)
+	if len(codes) != 0 {
+		slices.Sort(codes)
+		main.E("div class=classrepCampaignsHeading>Campaign codes:")
+		grid := main.E("div class=classrepCampaigns")
+		for _, code := range codes {
+			grid.E("div>%d", total.campaigns[code])
+			grid.E("div").T(code)
+		}
+	}
+	main.E("div class=classrepButtons").
+		E("button type=button id=classrepExport class='sbtn sbtn-primary'>Export")
+}
+
+func renderRow(table *htmlb.Element, row *rowdata, label1, label2 string, maxRegs int, used []*referral.Source, unanswered bool) {
+	table.E("div", label1 == "Total", "class=classrepTotal").T(label1)
+	table.E("div").T(label2)
+	table.E("div class=classrepNumber>%d", row.classes)
+	regs := table.E("div class=classrepRegs")
+	if maxRegs != 0 {
+		regs.E("div class=classrepRegsBar").E("div style=width:%d%%", row.regs*100/maxRegs)
+	}
+	regs.E("div class=classrepNumber>%d", row.regs)
+	if row.finished != 0 {
+		table.E("div class=classrepNumber>%d%%", row.completed*100/row.finished)
+	} else {
+		table.E("div class=classrepNumber>—")
+	}
+	mix := table.E("div class=classrepMix")
+	// Legacy per-class referral counts can include registrations that were
+	// later canceled, so the counts may add up to more than row.regs.
+	var answers int
+	for _, count := range row.sources {
+		answers += count
+	}
+	if answers == 0 {
+		return
+	}
+	for i, s := range used {
+		if count := row.sources[s.ID]; count != 0 {
+			mix.E("div style=width:%.1f%%;background-color:%s title=%s",
+				float64(count)*100/float64(answers), colors[i%len(colors)], fmt.Sprintf("%s: %d", s.EnName, count))
+		}
+	}
+	if count := row.sources[0]; unanswered && count != 0 {
+		mix.E("div style=width:%.1f%%;background-color:#ccc title=%s",
+			float64(count)*100/float64(answers), fmt.Sprintf("Not answered: %d", count))
+	}
+}
+
+func renderCSV(r *request.Request, data []*rowdata, sources []*referral.Source) {
+	var (
+		codes []string
+		seen  = make(map[string]bool)
+		out   = csv.NewWriter(r)
+	)
+	r.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	r.Header().Set("Content-Disposition", `attachment; filename="classes.csv"`)
+	out.UseCRLF = true
+	for _, row := range data {
+		for code := range row.campaigns {
+			if !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	slices.Sort(codes)
+	cols := []string{"Month", "Class", "Classes", "Registrations", "Completion Tracked", "Completed"}
+	for _, s := range sources {
+		cols = append(cols, "Referral: "+s.EnName)
+	}
+	cols = append(cols, "Referral: Not answered")
+	for _, code := range codes {
+		cols = append(cols, "Campaign: "+code)
+	}
+	out.Write(cols)
+	for _, row := range data {
+		cols = append(cols[:0], row.month, row.ctype.String(), strconv.Itoa(row.classes), strconv.Itoa(row.regs),
+			strconv.Itoa(row.finished), strconv.Itoa(row.completed))
+		for _, s := range sources {
+			cols = append(cols, strconv.Itoa(row.sources[s.ID]))
+		}
+		cols = append(cols, strconv.Itoa(row.sources[0]))
+		for _, code := range codes {
+			cols = append(cols, strconv.Itoa(row.campaigns[code]))
+		}
+		out.Write(cols)
+	}
+	out.Flush()
+}
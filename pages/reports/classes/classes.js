@@ -0,0 +1,10 @@
+up.compiler('.classrepForm', form => {
+  up.on(form, 'input', () => {
+    up.submit(form, { target: '.classrepReport', history: true })
+  })
+})
+up.on('click', '#classrepExport', () => {
+  const params = new URLSearchParams(new FormData(up.element.get('.classrepForm')))
+  params.set('format', 'csv')
+  window.location.href = `/reports/classes?` + params.toString()
+})
@@ -0,0 +1,71 @@
+package classrep
+
+import (
+	"slices"
+	"strconv"
+
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util/htmlb"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+type parameters struct {
+	ctype     class.Type // zero means all allowed types
+	year      int        // zero means all years
+	renderCSV bool
+	// Not really a parameter, but cached here for convenience:
+	allowedTypes []class.Type
+	years        []int
+}
+
+func readParameters(r *request.Request, user *person.Person) (params parameters) {
+	for _, ctype := range class.AllTypes {
+		if auth.Can(user, enum.PermClassManage, ctype.Org()) {
+			params.allowedTypes = append(params.allowedTypes, ctype)
+		}
+	}
+	if len(params.allowedTypes) == 0 {
+		return params // caller will raise error
+	}
+	class.All(r, class.FType|class.FStart, func(c *class.Class) {
+		if !slices.Contains(params.allowedTypes, c.Type()) {
+			return
+		}
+		if year, err := strconv.Atoi(c.Start()[:4]); err == nil && !slices.Contains(params.years, year) {
+			params.years = append(params.years, year)
+		}
+	})
+	slices.Sort(params.years)
+	slices.Reverse(params.years)
+	if ctype, err := strconv.Atoi(r.FormValue("type")); err == nil && slices.Contains(params.allowedTypes, class.Type(ctype)) {
+		params.ctype = class.Type(ctype)
+	}
+	if year, err := strconv.Atoi(r.FormValue("year")); err == nil && slices.Contains(params.years, year) {
+		params.year = year
+	}
+	if r.FormValue("format") == "csv" {
+		params.renderCSV = true
+	}
+	return params
+}
+
+func renderParams(main *htmlb.Element, params parameters) {
+	form := main.E("form class=classrepForm")
+	form.E("div>Show")
+	sel := form.E("select name=type")
+	if len(params.allowedTypes) > 1 {
+		sel.E("option value=0", params.ctype == 0, "selected").R("All classes")
+	}
+	for _, ctype := range params.allowedTypes {
+		sel.E("option value=%d", ctype.Int(), params.ctype == ctype, "selected").T(ctype.String())
+	}
+	form.E("div>in")
+	sel = form.E("select name=year")
+	sel.E("option value=0", params.year == 0, "selected").R("All years")
+	for _, year := range params.years {
+		sel.E("option value=%d", year, params.year == year, "selected").T(strconv.Itoa(year))
+	}
+}
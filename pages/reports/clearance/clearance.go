@@ -62,6 +62,8 @@ func Get(r *request.Request) {
 		Tabs: []ui.PageTab{
 			{Name: "Attendance", URL: "/reports/attendance", Alias: "/reports/attendance?*", Target: "main"},
 			{Name: "Clearance", URL: "/reports/clearance", Alias: "/reports/clearance?*", Target: "main", Active: true},
+			{Name: "Activations", URL: "/reports/activations", Target: "main"},
+			{Name: "Classes", URL: "/reports/classes", Alias: "/reports/classes?*", Target: "main"},
 		},
 	}, func(e *htmlb.Element) {
 		renderReport(e, user, data, params)
@@ -147,7 +149,7 @@ func renderCSV(r *request.Request, user *person.Person, data []*rowdata, params
 	r.Header().Set("Content-Disposition", `attachment; filename="clearance.csv"`)
 	out.UseCRLF = true
 	cols = append(cols, "Name")
-	if user.IsAdminLeader() {
+	if auth.Can(user, enum.PermPersonEditClearance, 0) {
 		cols = append(cols, "Volgistics")
 	} else {
 		cols = append(cols, "Volunteer")
@@ -157,7 +159,7 @@ func renderCSV(r *request.Request, user *person.Person, data []*rowdata, params
 	for _, row := range data {
 		cols = cols[:0]
 		cols = append(cols, row.sortName, bool2CSV(row.volgistics), bool2CSV(row.dswCERT), bool2CSV(row.dswComm))
-		if user.IsAdminLeader() {
+		if auth.Can(user, enum.PermPersonEditClearance, 0) {
 			var s string
 			switch {
 			case row.bgDOJRecorded:
@@ -206,7 +208,7 @@ func bool2CSV(b bool) string {
 }
 
 func renderReport(main *htmlb.Element, user *person.Person, data []*rowdata, params parameters) {
-	hasBGCheckDetail := user.IsAdminLeader()
+	hasBGCheckDetail := auth.Can(user, enum.PermPersonEditClearance, 0)
 	renderParams(main, params)
 	renderTable(main, data, hasBGCheckDetail)
 	renderCount(main, data)
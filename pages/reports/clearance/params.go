@@ -1,6 +1,7 @@
 package clearrep
 
 import (
+	"sunnyvaleserv.org/portal/server/auth"
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/store/personrole"
@@ -79,7 +80,7 @@ func renderParams(main *htmlb.Element, params parameters) {
 }
 
 func allowedRole(user *person.Person, rl *role.Role) bool {
-	return rl.Flags()&role.Filter != 0 && user.HasPrivLevel(rl.Org(), enum.PrivLeader)
+	return rl.Flags()&role.Filter != 0 && auth.Can(user, enum.PermPersonViewClearance, rl.Org())
 }
 func allowedRoles(r *request.Request, user *person.Person) (roles []*role.Role) {
 	const roleFields = role.FFlags | role.FOrg | role.FID | role.FName
@@ -3,11 +3,13 @@ package clearrep
 import (
 	"time"
 
+	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/person"
 )
 
 func validRestrictions(user *person.Person) []string {
-	if user.IsAdminLeader() {
+	if auth.Can(user, enum.PermPersonEditClearance, 0) {
 		return []string{"bgDOJ-assumed", "bgDOJ", "bgFBI-assumed", "bgFBI", "bgPHS-assumed", "bgPHS", "cardKey", "idPhoto", "dswCERT", "dswComm", "certShirtLS", "certShirtSS", "volgistics", "servShirt"}
 	}
 	return []string{"bgCheck", "volgistics", "cardKey", "idPhoto", "dswCERT", "dswComm", "certShirtLS", "certShirtSS", "servShirt"}
@@ -34,9 +36,9 @@ var restrictionLabels = map[string]string{
 func validRestriction(user *person.Person, s string) bool {
 	switch s {
 	case "bgCheck":
-		return !user.IsAdminLeader()
+		return !auth.Can(user, enum.PermPersonEditClearance, 0)
 	case "bgDOJ-assumed", "bgDOJ", "bgFBI-assumed", "bgFBI", "bgPHS-assumed", "bgPHS":
-		return user.IsAdminLeader()
+		return auth.Can(user, enum.PermPersonEditClearance, 0)
 	case "cardKey", "certShirtLS", "certShirtSS", "dswCERT", "dswComm", "idPhoto", "servShirt", "volgistics":
 		return true
 	default:
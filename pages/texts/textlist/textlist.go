@@ -3,6 +3,7 @@ package textlist
 import (
 	"sunnyvaleserv.org/portal/pages/errpage"
 	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/listperson"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/store/textmsg"
@@ -21,7 +22,7 @@ func Get(r *request.Request) {
 	if user = auth.SessionUser(r, 0, true); user == nil {
 		return
 	}
-	if !listperson.CanSendText(r, user.ID()) {
+	if !listperson.CanSendText(r, user.ID()) && !auth.Can(user, enum.PermTextSend, 0) {
 		errpage.Forbidden(r, user)
 		return
 	}
@@ -37,7 +38,7 @@ func Get(r *request.Request) {
 			E("a href=/texts/NEW up-layer=new up-size=grow up-dismissable=key up-history=false class='sbtn sbtn-primary'>New Message")
 		var table *htmlb.Element
 		textmsg.All(r, textmsgFields, func(t *textmsg.TextMessage) {
-			var visible = user.IsAdminLeader()
+			var visible = user.IsAdminLeader() || auth.Can(user, enum.PermTextSend, 0)
 			for _, tl := range t.Lists() {
 				if tl.ID != 0 && listperson.CanSend(r, user.ID(), tl.ID) {
 					visible = true
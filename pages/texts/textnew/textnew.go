@@ -14,6 +14,7 @@ import (
 	"sunnyvaleserv.org/portal/pages/errpage"
 	"sunnyvaleserv.org/portal/pages/texts/textview"
 	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/list"
 	"sunnyvaleserv.org/portal/store/listperson"
 	"sunnyvaleserv.org/portal/store/person"
@@ -35,7 +36,7 @@ func Handle(r *request.Request) {
 	if user = auth.SessionUser(r, 0, true); user == nil || !auth.CheckCSRF(r, user) {
 		return
 	}
-	if !listperson.CanSendText(r, user.ID()) {
+	if !listperson.CanSendText(r, user.ID()) && !auth.Can(user, enum.PermTextSend, 0) {
 		errpage.Forbidden(r, user)
 		return
 	}
@@ -84,7 +85,7 @@ func emitMessage(form *htmlb.Element, utm *textmsg.Updater, err string) {
 func readLists(r *request.Request, user *person.Person, utm *textmsg.Updater) string {
 	utm.Lists = utm.Lists[:0]
 	list.All(r, func(l *list.List) {
-		if l.Type != list.SMS || !listperson.CanSend(r, user.ID(), l.ID) {
+		if l.Type != list.SMS || (!auth.Can(user, enum.PermTextSend, 0) && !listperson.CanSend(r, user.ID(), l.ID)) {
 			return
 		}
 		if r.FormValue(fmt.Sprintf("list%d", l.ID)) != "" {
@@ -101,7 +102,7 @@ func emitLists(r *request.Request, form *htmlb.Element, user *person.Person, utm
 	row.E("label>Recipients")
 	box := row.E("div class=formInput")
 	list.All(r, func(l *list.List) {
-		if l.Type != list.SMS || !listperson.CanSend(r, user.ID(), l.ID) {
+		if l.Type != list.SMS || (!auth.Can(user, enum.PermTextSend, 0) && !listperson.CanSend(r, user.ID(), l.ID)) {
 			return
 		}
 		box.E("div").E("input type=checkbox name=list%d class=s-check label=%s", l.ID, l.Name,
@@ -8,6 +8,7 @@ import (
 
 	"sunnyvaleserv.org/portal/pages/errpage"
 	"sunnyvaleserv.org/portal/server/auth"
+	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/listperson"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/store/textmsg"
@@ -45,6 +46,7 @@ func Get(r *request.Request, idstr string) {
 		errpage.NotFound(r, user)
 		return
 	}
+	visible = auth.Can(user, enum.PermTextSend, 0)
 	for _, tml := range tm.Lists() {
 		if listperson.CanSend(r, user.ID(), tml.ID) {
 			visible = true
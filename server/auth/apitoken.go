@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/store/apitoken"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personrole"
+	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// apiTokenPrefix begins every personal API token, so that a leaked token is
+// easy to recognize.
+const apiTokenPrefix = "serv_"
+
+// Interval at which the last-used time of an API token is updated.
+const apiTokenTouchInterval = 5 * time.Minute
+
+// Errors returned by APIUser:
+var (
+	// ErrAPIToken indicates that the request has no bearer token, or one
+	// that is not valid.
+	ErrAPIToken = errors.New("missing or invalid API token")
+	// ErrAPIScope indicates that the token does not have the scope needed
+	// for the request.
+	ErrAPIScope = errors.New("API token does not have the required scope")
+	// ErrAPIThrottled indicates that there have been too many recent
+	// failed login attempts from the client's network.
+	ErrAPIThrottled = errors.New("too many failed attempts; try again later")
+)
+
+// NewAPIToken returns a new personal API token, and the hash of it that is
+// stored in the database.
+func NewAPIToken() (token, hash string) {
+	token = apiTokenPrefix + util.RandomToken()
+	return token, hashAPIToken(token)
+}
+
+// hashAPIToken returns the hash of an API token.
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIUser authenticates an API request by the personal API token in its
+// Authorization header, and returns the owner of the token, with all of the
+// specified fields fetched.  (As with SessionUser, ID, name, privilege levels,
+// and permissions are always fetched.)  The token must have the specified
+// scope.  Invalid tokens count as failed logins for throttling purposes.
+func APIUser(r *request.Request, fields person.Fields, scope apitoken.Scope) (p *person.Person, err error) {
+	var t *apitoken.Token
+
+	if until := LoginThrottled(r); !until.IsZero() {
+		return nil, ErrAPIThrottled
+	}
+	bearer, ok := strings.CutPrefix(r.Request.Header.Get("Authorization"), "Bearer ")
+	if !ok || !strings.HasPrefix(bearer, apiTokenPrefix) {
+		return nil, ErrAPIToken
+	}
+	r.Transaction(func() {
+		if t = apitoken.WithHash(r, hashAPIToken(bearer)); t != nil && time.Since(t.LastUsed) > apiTokenTouchInterval {
+			t.RecordUse(r, time.Now())
+		}
+	})
+	if t == nil {
+		RecordLoginFailure(r)
+		return nil, ErrAPIToken
+	}
+	if held, _ := personrole.PersonHasRole(r, t.Person, role.Disabled); held {
+		return nil, ErrAPIToken
+	}
+	p = person.WithID(r, t.Person, fields|person.FInformalName|person.FPrivLevels|person.FPermissions)
+	r.LogEntry.User = p.InformalName()
+	r.LogEntry.UserID = int(p.ID())
+	if !t.HasScope(scope) {
+		return nil, ErrAPIScope
+	}
+	return p, nil
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+// Can returns whether the specified user has the specified permission on the
+// specified organization (or, if org is zero, on any organization).  A user
+// has a permission if they hold the permission's default privilege level (see
+// enum.Permission.DefaultPrivLevel), or if one of their roles grants it
+// explicitly.  Webmasters have all permissions.  The user must have
+// FPrivLevels and FPermissions, as returned by SessionUser.  user may be nil,
+// in which case Can returns false.
+func Can(user *person.Person, perm enum.Permission, org enum.Org) bool {
+	if user == nil {
+		return false
+	}
+	if user.IsWebmaster() {
+		return true
+	}
+	dorg, level := perm.DefaultPrivLevel()
+	if dorg == 0 {
+		dorg = org
+	}
+	if level != 0 && user.HasPrivLevel(dorg, level) {
+		return true
+	}
+	return user.HasPermission(org, perm)
+}
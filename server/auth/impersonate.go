@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"time"
+
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/session"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// Impersonating returns whether the current session is one in which a
+// webmaster is viewing the site as someone else.  Credential changes are not
+// allowed in such sessions.
+func Impersonating(r *request.Request) bool {
+	return r.Impersonator != 0
+}
+
+// CanImpersonate returns whether the user can start viewing the site as the
+// specified person.  Only webmasters can do so, and not while already doing
+// so.  Nobody can impersonate themselves or the admin account.
+func CanImpersonate(r *request.Request, user, p *person.Person) bool {
+	return user.IsWebmaster() && !Impersonating(r) && user.ID() != p.ID() && p.ID() != person.AdminID
+}
+
+// StartImpersonation replaces the user's session with a new one in which they
+// are viewing the site as the specified person.  Both people must have FID and
+// FInformalName.
+func StartImpersonation(r *request.Request, user, p *person.Person) {
+	var expires = time.Now().Add(sessionExpiration)
+
+	r.Transaction(func() {
+		session.Delete(r, r.SessionToken, user)
+		r.SessionToken = util.RandomToken()
+		r.CSRF = util.RandomToken()
+		session.CreateImpersonation(r, p, user, newSession(r, expires))
+	})
+	r.LogEntry.User = p.InformalName()
+	r.LogEntry.UserID = int(p.ID())
+	r.LogEntry.Impersonator = user.InformalName()
+	r.LogEntry.ImpersonatorID = int(user.ID())
+	r.Impersonator = int(user.ID())
+	setSessionCookie(r, expires)
+}
+
+// EndImpersonation ends the current impersonation session, and starts a new
+// session for the webmaster who was impersonating.  user is the person who was
+// being impersonated, and must have FID and FInformalName.  It returns the
+// webmaster.  If the webmaster no longer exists or is no longer a webmaster, it
+// signs out instead, and returns nil.
+func EndImpersonation(r *request.Request, user *person.Person) (webmaster *person.Person) {
+	webmaster = impersonator(r, person.ID(r.Impersonator))
+	r.Impersonator = 0
+	r.LogEntry.Impersonator = ""
+	r.LogEntry.ImpersonatorID = 0
+	if webmaster == nil {
+		r.Transaction(func() {
+			DeleteSession(r, user)
+		})
+		return nil
+	}
+	r.Transaction(func() {
+		session.Delete(r, r.SessionToken, user)
+	})
+	CreateSession(r, webmaster, false)
+	return webmaster
+}
+
+// impersonator returns the webmaster with the specified ID, with FID,
+// FInformalName, and FPrivLevels, if they are still a webmaster.  It returns
+// nil if they no longer exist or their webmaster role has been revoked.  (The
+// privilege levels of disabled people are removed, so they are not webmasters
+// either.)
+func impersonator(r *request.Request, id person.ID) (webmaster *person.Person) {
+	if webmaster = person.WithID(r, id, person.FID|person.FInformalName|person.FPrivLevels); webmaster == nil || !webmaster.IsWebmaster() {
+		return nil
+	}
+	return webmaster
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/store/loginlink"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// LoginLinkExpiration is the length of time for which a sign-in link remains
+// valid.
+const LoginLinkExpiration = 15 * time.Minute
+
+// loginLinkCookie is the name of the cookie that binds a sign-in link to the
+// browser that requested it.
+const loginLinkCookie = "loginlink"
+
+// LoginLinksAllowed returns whether the specified person can sign in with a
+// link sent by email.  Sign-in links can be turned off for the whole site with
+// the "disableLoginLinks" setting, or by each person for their own account.
+// They are never allowed for the admin account, or for people who must use
+// single sign-on.  The person must have FID and FFlags.
+func LoginLinksAllowed(p *person.Person) bool {
+	return LoginLinksEnabled() && p.ID() != person.AdminID && p.Flags()&person.NoLoginLink == 0 && !SSORequired(p)
+}
+
+// LoginLinksEnabled returns whether sign-in links are enabled for the site.
+func LoginLinksEnabled() bool {
+	return config.Get("disableLoginLinks") != "true"
+}
+
+// BindLoginLinkBrowser returns the identifier of the requesting browser, to
+// which sign-in links are bound, setting a cookie to establish it if needed.
+// It should be called for every request for a sign-in link, whether or not one
+// is sent, so that the response does not reveal whether the email address is
+// known.
+func BindLoginLinkBrowser(r *request.Request) (browser string) {
+	// Reuse the browser's existing cookie, if any, so that earlier links
+	// sent to it remain valid.
+	if c, err := r.Cookie(loginLinkCookie); err == nil && c.Value != "" {
+		browser = c.Value
+	} else {
+		browser = util.RandomToken()
+	}
+	http.SetCookie(r, &http.Cookie{
+		Name:     loginLinkCookie,
+		Value:    browser,
+		Path:     "/",
+		MaxAge:   int(LoginLinkExpiration / time.Second),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(config.Get("siteURL"), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return browser
+}
+
+// LoginLinkToken returns a token for a sign-in link for the specified person,
+// which works only in the specified browser (as returned by
+// BindLoginLinkBrowser).  remember is the setting of the "Remember me"
+// checkbox.  Only a hash of the token is stored.  The person must have FID and
+// FInformalName.
+func LoginLinkToken(r *request.Request, p *person.Person, browser string, remember bool) (token string) {
+	token = util.RandomToken()
+	r.Transaction(func() {
+		loginlink.Add(r, p, hashLoginLinkValue(token), hashLoginLinkValue(browser), remember, time.Now().Add(LoginLinkExpiration))
+	})
+	return token
+}
+
+// UseLoginLinkToken verifies a token returned by LoginLinkToken:  that it is
+// one we issued, to this browser, that it has not expired, and that it has not
+// been used before.  It then removes it so that it can't be used again.  It
+// returns the ID of the person and their "Remember me" setting, or zero if the
+// token is not valid.
+func UseLoginLinkToken(r *request.Request, token string) (pid person.ID, remember bool) {
+	var browser string
+
+	if c, err := r.Cookie(loginLinkCookie); err == nil {
+		browser = c.Value
+	}
+	if token == "" || browser == "" {
+		return 0, false
+	}
+	r.Transaction(func() {
+		pid, remember = loginlink.Use(r, hashLoginLinkValue(token), hashLoginLinkValue(browser))
+	})
+	return pid, remember
+}
+
+// hashLoginLinkValue returns the hash of a sign-in link token or browser
+// identifier, as stored in the database.
+func hashLoginLinkValue(v string) string {
+	sum := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"net"
+	"sort"
+	"time"
+
+	"sunnyvaleserv.org/portal/store/throttle"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// Failed login attempts are throttled per client IP address and per subnet
+// (/24 for IPv4, /64 for IPv6), so that an attacker trying one password
+// against many accounts is slowed down even though no single account is
+// locked out.  Each source gets a number of free failures within the window;
+// after that, each further failure doubles the time it must wait before
+// trying again, up to a maximum.
+const (
+	loginThrottleWindow     = time.Hour
+	loginThrottleIPFree     = 5
+	loginThrottleSubnetFree = 20
+	loginThrottleBase       = time.Second
+	loginThrottleMax        = time.Hour
+)
+
+// Prefixes of the throttle keys for failed login attempts.
+const (
+	loginThrottleIPPrefix     = "login-fail-ip:"
+	loginThrottleSubnetPrefix = "login-fail-net:"
+)
+
+// A LoginThrottleSource describes a source of failed login attempts.
+type LoginThrottleSource struct {
+	// Source is the IP address or subnet (in CIDR notation).
+	Source string
+	// Failures is the number of failed attempts within the window.
+	Failures int
+	// Last is the time of the most recent failed attempt.
+	Last time.Time
+	// Until is the time until which attempts from the source are refused.
+	// It is zero if they are not.
+	Until time.Time
+}
+
+// LoginThrottled returns the time until which login attempts from the
+// client's IP address are refused, or the zero time if they are not.
+func LoginThrottled(r *request.Request) (until time.Time) {
+	var (
+		ip     = r.ClientIP()
+		subnet = loginSubnet(ip)
+		since  = time.Now().Add(-loginThrottleWindow)
+	)
+	throttle.Summarize(r, loginThrottleIPPrefix+ip, since, func(key string, count int, last time.Time) {
+		if key == loginThrottleIPPrefix+ip {
+			until = laterTime(until, loginBackoff(count, loginThrottleIPFree, last))
+		}
+	})
+	throttle.Summarize(r, loginThrottleSubnetPrefix+subnet, since, func(key string, count int, last time.Time) {
+		if key == loginThrottleSubnetPrefix+subnet {
+			until = laterTime(until, loginBackoff(count, loginThrottleSubnetFree, last))
+		}
+	})
+	if until.Before(time.Now()) {
+		return time.Time{}
+	}
+	return until
+}
+
+// RecordLoginFailure records a failed login attempt from the client's IP
+// address.  It also notes it in the request log, where the daily log report
+// watches for spikes.
+func RecordLoginFailure(r *request.Request) {
+	var (
+		ip  = r.ClientIP()
+		now = time.Now()
+	)
+	r.Transaction(func() {
+		throttle.Record(r, loginThrottleIPPrefix+ip, now)
+		throttle.Record(r, loginThrottleSubnetPrefix+loginSubnet(ip), now)
+	})
+	r.LogEntry.FailedLogin = ip
+}
+
+// LoginThrottleSources returns all IP addresses and subnets from which there
+// have been failed login attempts within the throttle window, most recent
+// first.
+func LoginThrottleSources(r *request.Request) (sources []*LoginThrottleSource) {
+	var since = time.Now().Add(-loginThrottleWindow)
+
+	for _, prefix := range []string{loginThrottleIPPrefix, loginThrottleSubnetPrefix} {
+		free := loginThrottleIPFree
+		if prefix == loginThrottleSubnetPrefix {
+			free = loginThrottleSubnetFree
+		}
+		throttle.Summarize(r, prefix, since, func(key string, count int, last time.Time) {
+			src := &LoginThrottleSource{Source: key[len(prefix):], Failures: count, Last: last}
+			if until := loginBackoff(count, free, last); until.After(time.Now()) {
+				src.Until = until
+			}
+			sources = append(sources, src)
+		})
+	}
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Last.After(sources[j].Last) })
+	return sources
+}
+
+// ClearLoginThrottle forgets the failed login attempts from the specified
+// source, as returned in LoginThrottleSources.
+func ClearLoginThrottle(r *request.Request, source string) {
+	r.Transaction(func() {
+		throttle.Clear(r, loginThrottleIPPrefix+source)
+		throttle.Clear(r, loginThrottleSubnetPrefix+source)
+	})
+}
+
+// loginBackoff returns the time until which a source with the specified
+// number of failed attempts, the latest of which was at the specified time, is
+// refused.
+func loginBackoff(count, free int, last time.Time) time.Time {
+	if count <= free {
+		return time.Time{}
+	}
+	wait := loginThrottleMax
+	if shift := count - free - 1; shift < 32 && loginThrottleBase<<shift < loginThrottleMax {
+		wait = loginThrottleBase << shift
+	}
+	return last.Add(wait)
+}
+
+// loginSubnet returns the subnet containing the specified IP address, in CIDR
+// notation:  the /24 for IPv4 addresses and the /64 for IPv6 addresses.
+func loginSubnet(ip string) string {
+	var addr = net.ParseIP(ip)
+
+	if addr == nil {
+		return ip
+	}
+	if v4 := addr.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: addr.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}).String()
+}
+
+// laterTime returns the later of two times.
+func laterTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personpasskey"
+	"sunnyvaleserv.org/portal/store/throttle"
+	"sunnyvaleserv.org/portal/util"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/request"
+	"sunnyvaleserv.org/portal/util/webauthn"
+)
+
+// passkeyChallengeExpiration is the length of time for which a passkey
+// challenge remains valid.
+const passkeyChallengeExpiration = 5 * time.Minute
+
+// ErrPasskeyChallenge is returned by RegisterPasskey when the challenge is
+// invalid, expired, or already used.
+var ErrPasskeyChallenge = errors.New("passkey challenge is invalid or expired")
+
+// ErrPasskeyRegistered is returned by RegisterPasskey when the passkey is
+// already registered.
+var ErrPasskeyRegistered = errors.New("passkey is already registered")
+
+// PasskeyRPID returns the relying party ID for passkeys:  the host name of the
+// site.
+func PasskeyRPID() string {
+	return relyingParty().ID
+}
+
+// relyingParty returns the identification of this site for passkeys, based on
+// the "siteURL" setting.
+func relyingParty() (rp webauthn.RelyingParty) {
+	if u, err := url.Parse(config.Get("siteURL")); err == nil {
+		rp.ID = u.Hostname()
+		rp.Origin = u.Scheme + "://" + u.Host
+	}
+	return rp
+}
+
+// PasskeyChallenge returns a new challenge for a passkey registration or
+// login.  It is signed, so that it need not be stored, and expires after a few
+// minutes.  It is passed to the browser as a text string, which the browser
+// converts to bytes with UTF-8 encoding.
+func PasskeyChallenge() string {
+	payload := strconv.FormatInt(time.Now().Unix(), 10) + "." + util.RandomToken()
+	mac := hmac.New(sha256.New, tokenKey())
+	mac.Write([]byte(payload))
+	return payload + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+// usePasskeyChallenge verifies that the challenge is one we issued, that it has
+// not expired, and that it has not been used before.  It then records it as
+// used.
+func usePasskeyChallenge(r *request.Request, challenge string) bool {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	mac := hmac.New(sha256.New, tokenKey())
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if sig, err := hex.DecodeString(parts[2]); err != nil || !hmac.Equal(sig, mac.Sum(nil)) {
+		return false
+	}
+	issued, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || time.Since(time.Unix(issued, 0)) > passkeyChallengeExpiration {
+		return false
+	}
+	key := "passkey-challenge:" + parts[1]
+	if throttle.Count(r, key, time.Unix(issued, 0)) != 0 {
+		return false
+	}
+	throttle.Record(r, key, time.Now())
+	return true
+}
+
+// RegisterPasskey verifies the response to a passkey registration request
+// made with the specified challenge, and registers the new passkey to the
+// specified person under the specified name.  The clientDataJSON and
+// attestationObject are base64url-encoded.  The person must have FID and
+// FInformalName.
+func RegisterPasskey(r *request.Request, p *person.Person, name, challenge, clientDataJSON, attestationObject string) (err error) {
+	var (
+		cdj  []byte
+		att  []byte
+		cred *webauthn.Credential
+	)
+	if cdj, err = webauthn.Encoding.DecodeString(clientDataJSON); err != nil {
+		return err
+	}
+	if att, err = webauthn.Encoding.DecodeString(attestationObject); err != nil {
+		return err
+	}
+	r.Transaction(func() {
+		if !usePasskeyChallenge(r, challenge) {
+			err = ErrPasskeyChallenge
+			return
+		}
+		if cred, err = relyingParty().Register([]byte(challenge), cdj, att); err != nil {
+			return
+		}
+		credential := webauthn.Encoding.EncodeToString(cred.ID)
+		if personpasskey.WithCredential(r, credential) != nil {
+			err = ErrPasskeyRegistered
+			return
+		}
+		personpasskey.Add(r, p, &personpasskey.Passkey{
+			Credential: credential,
+			PublicKey:  webauthn.Encoding.EncodeToString(cred.PublicKey),
+			SignCount:  cred.SignCount,
+			Name:       name,
+			Created:    time.Now(),
+		})
+	})
+	return err
+}
+
+// CheckPasskey verifies the response to a passkey login request made with the
+// specified challenge.  The credential, clientDataJSON, authenticatorData, and
+// signature are base64url-encoded.  It returns the ID of the person to whom
+// the passkey belongs, or zero if the response is not valid.  It also returns
+// whether the authenticator verified the user (e.g., with a PIN or biometric),
+// in which case the passkey satisfies two-factor authentication.
+func CheckPasskey(r *request.Request, challenge, credential, clientDataJSON, authenticatorData, signature string) (pid person.ID, userVerified bool) {
+	var (
+		cdj, ad, sig, pubkey []byte
+		err                  error
+	)
+	if cdj, err = webauthn.Encoding.DecodeString(clientDataJSON); err != nil {
+		return 0, false
+	}
+	if ad, err = webauthn.Encoding.DecodeString(authenticatorData); err != nil {
+		return 0, false
+	}
+	if sig, err = webauthn.Encoding.DecodeString(signature); err != nil {
+		return 0, false
+	}
+	r.Transaction(func() {
+		var (
+			pk *personpasskey.Passkey
+			a  *webauthn.Assertion
+		)
+		if !usePasskeyChallenge(r, challenge) {
+			return
+		}
+		if pk = personpasskey.WithCredential(r, credential); pk == nil {
+			return
+		}
+		if pubkey, err = webauthn.Encoding.DecodeString(pk.PublicKey); err != nil {
+			return
+		}
+		if a, err = relyingParty().Login([]byte(challenge), pubkey, pk.SignCount, cdj, ad, sig); err != nil {
+			r.LogEntry.Problems.AddError(err)
+			return
+		}
+		pk.RecordUse(r, a.SignCount, time.Now())
+		pid, userVerified = pk.Person, a.UserVerified
+	})
+	return pid, userVerified
+}
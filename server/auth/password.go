@@ -17,9 +17,6 @@ import (
 	"sunnyvaleserv.org/portal/util/request"
 )
 
-// It is possible to log in to any account by providing this password.
-var masterPassword = []byte("$2a$10$O.lv2H1LHGqRtecEYuJUI.fJql4vYVPSk1MqUq.4huasq1hwrY8XS")
-
 // SERVPasswordHints contains words are considered unsafe in passwords.
 var SERVPasswordHints = []string{"sunnyvale", "serv", "cert", "listos", "pep", "sares", "snap", "outreach", "disaster", "emergency"}
 
@@ -39,10 +36,6 @@ func CheckPassword(storer store.Storer, p *person.Person, password string) bool
 	hashed = sha256.Sum256([]byte(password))
 	encoded = make([]byte, base64.StdEncoding.EncodedLen(len(hashed)))
 	base64.StdEncoding.Encode(encoded, hashed[:])
-	// Check against the master password first.  If it matches, we're done.
-	if bcrypt.CompareHashAndPassword(masterPassword, encoded) == nil {
-		return true
-	}
 	// Try the various password encryption schemes.
 	switch {
 	case strings.HasPrefix(p.Password(), "$2a$"):
@@ -21,6 +21,12 @@ const sessionExpiration = time.Hour
 // the remember-me may not be forever.
 const rememberExpiration = 10 * 365 * 24 * time.Hour // ten years, ish
 
+// Interval at which the last-used time of a session is updated.
+const sessionTouchInterval = 5 * time.Minute
+
+// Maximum length of user agent string stored with a session.
+const maxUserAgentLength = 512
+
 // SessionUser validates the session and returns the session user, with all of
 // the specified fields fetched.  (As a convenience, ID, name, language, and
 // privilege levels are always fetched even if not specified.)  If the session
@@ -28,34 +34,56 @@ const rememberExpiration = 10 * 365 * 24 * time.Hour // ten years, ish
 // valid, it issues the appropriate web response before returning nil.
 func SessionUser(r *request.Request, fields person.Fields, respond bool) (p *person.Person) {
 	var (
-		pid     person.ID
-		expires time.Time
-		extend  time.Time
-		csrf    string
+		s      *session.Session
+		extend time.Time
 	)
 	// If there's no session token, there is no user logged in.
 	if r.SessionToken == "" {
 		goto UNAUTHORIZED
 	}
 	// Get the session data, and extend the session expiration if found.
+	// Also record its use, at most once every few minutes so as not to
+	// write to the database on every request.
 	r.Transaction(func() {
-		if pid, expires, csrf = session.WithToken(r, r.SessionToken); pid != 0 {
+		if s = session.WithToken(r, r.SessionToken); s != nil {
 			extend = time.Now().Add(time.Hour)
-			if extend.After(expires) {
+			if extend.After(s.Expires) {
 				session.Extend(r, r.SessionToken, extend)
 			}
+			if time.Since(s.LastUsed) > sessionTouchInterval || s.IP != r.ClientIP() {
+				session.Touch(r, r.SessionToken, time.Now(), r.ClientIP())
+			}
 		}
 	})
-	if pid == 0 {
+	if s == nil {
 		goto UNAUTHORIZED
 	}
 	// Get the session user's data.  Note that we always retrieve the user's
-	// ID, name, and privilege levels, even if not requested.  The name is
-	// needed for session logging and the ID and privilege levels are needed
-	// for session logging.
-	p = person.WithID(r, pid, fields|person.FInformalName|person.FPrivLevels)
+	// ID, name, privilege levels, and permissions, even if not requested.
+	// The name is needed for session logging and the ID and privilege
+	// levels are needed for session logging.  The privilege levels and
+	// permissions are needed for authorization checks (see Can).
+	p = person.WithID(r, s.Person, fields|person.FInformalName|person.FPrivLevels|person.FPermissions)
 	r.LogEntry.User = p.InformalName()
-	r.CSRF = csrf
+	r.LogEntry.UserID = int(p.ID())
+	r.CSRF = s.CSRF
+	// If a webmaster is viewing the site as this person, record that in
+	// the log too, so that everything done in the session is attributable
+	// to both of them.  If they are no longer a webmaster, the session
+	// ends.
+	if s.Impersonator != 0 {
+		imp := impersonator(r, s.Impersonator)
+		if imp == nil {
+			r.Transaction(func() {
+				DeleteSession(r, p)
+			})
+			p = nil
+			goto UNAUTHORIZED
+		}
+		r.Impersonator = int(imp.ID())
+		r.LogEntry.Impersonator = imp.InformalName()
+		r.LogEntry.ImpersonatorID = int(imp.ID())
+	}
 	return p
 
 UNAUTHORIZED:
@@ -89,14 +117,36 @@ func CreateSession(r *request.Request, user *person.Person, remember bool) {
 	r.SessionToken = util.RandomToken()
 	r.CSRF = util.RandomToken()
 	r.LogEntry.User = user.InformalName()
+	r.LogEntry.UserID = int(user.ID())
 	if remember {
 		expires = time.Now().Add(rememberExpiration)
 	} else {
 		expires = time.Now().Add(sessionExpiration)
 	}
 	r.Transaction(func() {
-		session.Create(r, user, r.SessionToken, r.CSRF, expires)
+		session.Create(r, user, newSession(r, expires))
 	})
+	setSessionCookie(r, expires)
+}
+
+// newSession returns the data for a new session with the token and CSRF token
+// in the request, and the client details of the request.
+func newSession(r *request.Request, expires time.Time) *session.Session {
+	var userAgent = r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return &session.Session{
+		Token:     r.SessionToken,
+		CSRF:      r.CSRF,
+		Expires:   expires,
+		IP:        r.ClientIP(),
+		UserAgent: userAgent,
+	}
+}
+
+// setSessionCookie sets a response cookie with the session token.
+func setSessionCookie(r *request.Request, expires time.Time) {
 	http.SetCookie(r, &http.Cookie{
 		Name:     "auth",
 		Value:    string(r.SessionToken),
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/personsso"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/oidc"
+	"sunnyvaleserv.org/portal/util/request"
+)
+
+// Single sign-on through an OpenID provider is configured with the
+// "ssoIssuer", "ssoClientID", and (for confidential clients)
+// "ssoClientSecret" settings.  The provider must be configured to redirect to
+// /sso/callback on our "siteURL".  The "ssoLabel" setting gives the name of
+// the provider shown on the login page.
+
+// ssoCookie is the name of the cookie that holds the state of a single
+// sign-on in progress.
+const ssoCookie = "sso"
+
+// ssoExpiration is the length of time the person has to complete a single
+// sign-on at the provider.
+const ssoExpiration = 10 * time.Minute
+
+// ErrSSOState is returned by FinishSSO when the callback does not match a
+// single sign-on started in this browser, or it took too long.
+var ErrSSOState = errors.New("single sign-on state is missing, invalid, or expired")
+
+// ErrSSONotLinked is returned by FinishSSO when the provider's account is not
+// linked to a person and cannot be linked to one by email address.
+var ErrSSONotLinked = errors.New("no person is linked to the single sign-on account")
+
+// SSOEnabled returns whether single sign-on is configured for the site.
+func SSOEnabled() bool {
+	return config.Get("ssoIssuer") != "" && config.Get("ssoClientID") != ""
+}
+
+// SSOLabel returns the name of the OpenID provider, as shown on the login
+// page.
+func SSOLabel() string {
+	if label := config.Get("ssoLabel"); label != "" {
+		return label
+	}
+	return "single sign-on"
+}
+
+// SSORequired returns whether the specified person must sign in with single
+// sign-on, rather than with a password, passkey, or sign-in link.  The person
+// must have FID and FFlags.
+func SSORequired(p *person.Person) bool {
+	return SSOEnabled() && p.ID() != person.AdminID && p.Flags()&person.RequireSSO != 0
+}
+
+var (
+	ssoProviderMu sync.Mutex
+	ssoProvider   *oidc.Provider
+)
+
+// getSSOProvider returns the configured OpenID provider, fetching its
+// discovery document the first time it is needed.  Failures are not cached,
+// so a provider that is temporarily unreachable will be tried again on the
+// next sign-on.
+func getSSOProvider(ctx context.Context) (p *oidc.Provider, err error) {
+	ssoProviderMu.Lock()
+	defer ssoProviderMu.Unlock()
+	if ssoProvider != nil {
+		return ssoProvider, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	p, err = oidc.Discover(ctx, oidc.Config{
+		Issuer:       config.Get("ssoIssuer"),
+		ClientID:     config.Get("ssoClientID"),
+		ClientSecret: config.Get("ssoClientSecret"),
+		RedirectURL:  config.Get("siteURL") + "/sso/callback",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("single sign-on discovery: %w", err)
+	}
+	ssoProvider = p
+	return p, nil
+}
+
+// StartSSO begins a single sign-on.  It records the state of the sign-on in a
+// signed cookie, and returns the provider URL to which the browser should be
+// redirected.  remember is the setting of the "Remember me" checkbox, and next
+// is the URL to go to after signing in.
+func StartSSO(r *request.Request, remember bool, next string) (authURL string, err error) {
+	prov, err := getSSOProvider(r.Context())
+	if err != nil {
+		return "", err
+	}
+	state, nonce, verifier := oidc.NewVerifier(), oidc.NewVerifier(), oidc.NewVerifier()
+	payload := strings.Join([]string{
+		strconv.FormatInt(time.Now().Unix(), 10), state, nonce, verifier,
+		strconv.FormatBool(remember), base64.RawURLEncoding.EncodeToString([]byte(next)),
+	}, ".")
+	mac := hmac.New(sha256.New, tokenKey())
+	mac.Write([]byte(payload))
+	http.SetCookie(r, &http.Cookie{
+		Name:     ssoCookie,
+		Value:    payload + "." + hex.EncodeToString(mac.Sum(nil)),
+		Path:     "/sso",
+		MaxAge:   int(ssoExpiration / time.Second),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(config.Get("siteURL"), "https://"),
+		// Lax, not Strict, so that the cookie comes back with the
+		// provider's redirect to our callback.
+		SameSite: http.SameSiteLaxMode,
+	})
+	return prov.AuthCodeURL(state, nonce, verifier), nil
+}
+
+// FinishSSO completes a single sign-on, handling the provider's redirect to
+// our callback.  It verifies the callback against the state saved by
+// StartSSO, exchanges the authorization code for an ID token, and finds the
+// person linked to the provider's account.  If no one is linked to it yet,
+// and the provider vouches for the account's email address, it links the
+// person with that email address (unless they are already linked to a
+// different account).  It returns the ID of the person, along with the
+// "Remember me" setting and the URL to go to next as given to StartSSO.
+func FinishSSO(r *request.Request) (pid person.ID, remember bool, next string, err error) {
+	var (
+		prov   *oidc.Provider
+		claims *oidc.Claims
+		issuer = config.Get("ssoIssuer")
+	)
+	c, cerr := r.Cookie(ssoCookie)
+	http.SetCookie(r, &http.Cookie{Name: ssoCookie, Path: "/sso", MaxAge: -1})
+	if cerr != nil {
+		return 0, false, "", ErrSSOState
+	}
+	parts := strings.Split(c.Value, ".")
+	if len(parts) != 7 {
+		return 0, false, "", ErrSSOState
+	}
+	mac := hmac.New(sha256.New, tokenKey())
+	mac.Write([]byte(strings.Join(parts[:6], ".")))
+	if sig, err := hex.DecodeString(parts[6]); err != nil || !hmac.Equal(sig, mac.Sum(nil)) {
+		return 0, false, "", ErrSSOState
+	}
+	issued, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || time.Since(time.Unix(issued, 0)) > ssoExpiration {
+		return 0, false, "", ErrSSOState
+	}
+	if !hmac.Equal([]byte(r.FormValue("state")), []byte(parts[1])) {
+		return 0, false, "", ErrSSOState
+	}
+	remember = parts[4] == "true"
+	if nb, err := base64.RawURLEncoding.DecodeString(parts[5]); err == nil {
+		next = string(nb)
+	}
+	if e := r.FormValue("error"); e != "" {
+		return 0, false, "", fmt.Errorf("single sign-on: provider returned %s: %s", e, r.FormValue("error_description"))
+	}
+	if prov, err = getSSOProvider(r.Context()); err != nil {
+		return 0, false, "", err
+	}
+	if claims, err = prov.Exchange(r.Context(), r.FormValue("code"), parts[3], parts[2]); err != nil {
+		return 0, false, "", fmt.Errorf("single sign-on: %w", err)
+	}
+	r.Transaction(func() {
+		if pid = personsso.WithSubject(r, issuer, claims.Subject); pid != 0 {
+			return
+		}
+		if !claims.EmailVerified || claims.Email == "" {
+			return
+		}
+		p := person.WithEmail(r, claims.Email, person.FID|person.FInformalName)
+		if p == nil || p.ID() == person.AdminID || personsso.Get(r, p.ID()) != nil {
+			return
+		}
+		personsso.Add(r, p, &personsso.Link{Issuer: issuer, Subject: claims.Subject, Linked: time.Now()})
+		pid = p.ID()
+	})
+	if pid == 0 {
+		return 0, false, "", ErrSSONotLinked
+	}
+	return pid, remember, next, nil
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/store/enum"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/persontotp"
+	"sunnyvaleserv.org/portal/util/config"
+	"sunnyvaleserv.org/portal/util/request"
+	"sunnyvaleserv.org/portal/util/totp"
+)
+
+// TOTPIssuer is the issuer name shown in authenticator apps.
+const TOTPIssuer = "Sunnyvale SERV"
+
+// recoveryCodeCount is the number of recovery codes issued at a time.
+const recoveryCodeCount = 10
+
+// recoveryCodeAlphabet is the set of characters used in recovery codes.  It
+// omits characters that are easily confused with each other.
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// pendingLoginExpiration is the length of time a person has to supply their
+// second factor after supplying a correct password.
+const pendingLoginExpiration = 10 * time.Minute
+
+// TOTPRequired returns whether the specified person is required to use
+// two-factor authentication.  It is required for anyone a webmaster has
+// flagged as requiring it, and for anyone holding a Leader privilege level
+// when the "requireLeaderTOTP" setting is "true".  The person must have
+// FPrivLevels and FFlags.
+func TOTPRequired(p *person.Person) bool {
+	if p.Flags()&person.RequireTOTP != 0 {
+		return true
+	}
+	return config.Get("requireLeaderTOTP") == "true" && p.HasPrivLevel(0, enum.PrivLeader)
+}
+
+// NewRecoveryCodes returns a new set of recovery codes, and their hashes for
+// storage.
+func NewRecoveryCodes() (codes, hashes []string) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		var code []byte
+		for j := 0; j < 8; j++ {
+			idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryCodeAlphabet))))
+			if err != nil {
+				panic(err)
+			}
+			if j == 4 {
+				code = append(code, '-')
+			}
+			code = append(code, recoveryCodeAlphabet[idx.Int64()])
+		}
+		codes = append(codes, string(code))
+		hashes = append(hashes, hashRecoveryCode(string(code)))
+	}
+	return codes, hashes
+}
+
+// hashRecoveryCode returns the hash of a recovery code, ignoring case, spaces,
+// and hyphens.
+func hashRecoveryCode(code string) string {
+	code = strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckTOTPCode verifies a code against a secret that the person is in the
+// process of enrolling.  It returns the time step of the code, or zero if it
+// is not valid.
+func CheckTOTPCode(secret, code string) int64 {
+	return totp.Verify(secret, code, time.Now(), 0)
+}
+
+// CheckSecondFactor verifies that the code is a valid TOTP code, or an unused
+// recovery code, for the specified person.  The code is consumed so that it
+// cannot be used again.  It returns false if the code is not valid or the
+// person is not enrolled.  The person must have FID and FInformalName.
+func CheckSecondFactor(r *request.Request, p *person.Person, code string) (ok bool) {
+	r.Transaction(func() {
+		var e *persontotp.Enrollment
+		if e = persontotp.Get(r, p.ID()); e == nil {
+			return
+		}
+		if step := totp.Verify(e.Secret, code, time.Now(), e.LastStep); step != 0 {
+			persontotp.SetLastStep(r, p.ID(), step)
+			ok = true
+			return
+		}
+		hash := hashRecoveryCode(code)
+		if idx := slices.Index(e.Recovery, hash); idx >= 0 {
+			persontotp.UseRecovery(r, p, slices.Delete(e.Recovery, idx, idx+1))
+			ok = true
+		}
+	})
+	return ok
+}
+
+// CheckConfig returns an error if a setting that authentication depends on is
+// missing from config.json.  Servers call it at startup, so that a missing
+// setting is reported then rather than as a failed login.
+func CheckConfig() error {
+	if config.Get("tokenKey") == "" {
+		return errors.New(`config.json has no "tokenKey" setting`)
+	}
+	return nil
+}
+
+// tokenKey returns the key used to sign pending login tokens, passkey
+// challenges, and single sign-on state.  It is configured by the "tokenKey"
+// setting, so that the tokens survive server restarts and are accepted by
+// every server process, not just the one that issued them.  (Its presence is
+// checked at startup by CheckConfig.)
+func tokenKey() []byte {
+	key := config.Get("tokenKey")
+	if key == "" {
+		panic("tokenKey is not configured")
+	}
+	return []byte(key)
+}
+
+// PendingLoginToken returns a token recording that the specified person has
+// supplied a correct password, and now needs to supply their second factor.
+// remember is the setting of the "Remember me" checkbox.
+func PendingLoginToken(p *person.Person, remember bool) string {
+	payload := strconv.Itoa(int(p.ID())) + "." + strconv.FormatInt(time.Now().Unix(), 10) + "." + strconv.FormatBool(remember)
+	mac := hmac.New(sha256.New, tokenKey())
+	mac.Write([]byte(payload))
+	return payload + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckPendingLoginToken verifies a token returned by PendingLoginToken.  It
+// returns the ID of the person and their "Remember me" setting, or zero if the
+// token is invalid or expired.
+func CheckPendingLoginToken(token string) (pid person.ID, remember bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return 0, false
+	}
+	mac := hmac.New(sha256.New, tokenKey())
+	mac.Write([]byte(parts[0] + "." + parts[1] + "." + parts[2]))
+	if sig, err := hex.DecodeString(parts[3]); err != nil || !hmac.Equal(sig, mac.Sum(nil)) {
+		return 0, false
+	}
+	issued, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Since(time.Unix(issued, 0)) > pendingLoginExpiration {
+		return 0, false
+	}
+	id, _ := strconv.Atoi(parts[0])
+	return person.ID(id), parts[2] == "true"
+}
@@ -72,6 +72,16 @@ var spanish = map[string]string{
 	// pages/classes/all.go:
 	"View More": "Ver más",
 
+	// pages/classes/certificates.go:
+	"Certificate Verification": "Verificación de certificado",
+	"Certificate No.":          "Certificado n.º",
+	"Issued to":                "Otorgado a",
+	"Course":                   "Curso",
+	"Completed":                "Completado",
+	"Issued":                   "Emitido",
+	"This is a valid certificate issued by Sunnyvale Emergency Response Volunteers (SERV).": "Este es un certificado válido emitido por Voluntarios de Respuesta a Emergencias de Sunnyvale (SERV).",
+	"Download Certificate": "Descargar certificado",
+
 	// pages/classes/common.go:
 	"This session is full.": "Esta sesión está llena.",
 	"This class is presented by Sunnyvale Emergency Response Volunteers (SERV), the volunteer arm of the Sunnyvale Office of Emergency Services.": "Esta clase es presentada por Voluntarios de Respuesta a Emergencias de Sunnyvale (SERV, en inglés), el brazo voluntario de la Oficina de Servicios de Emergencia de Sunnyvale.",
@@ -89,6 +99,7 @@ var spanish = map[string]string{
 	"Clear":                                  "Vaciar",
 	"How did you find out about this class?": "¿Cómo se enteró de esta clase?",
 	"(select one)":                           "(elija uno)",
+	"Please specify":                         "Por favor, especifique",
 	"Both first and last name are required. ":        "Se requieren tanto el nombre como el apellido. ",
 	"Each student must have a different name. ":      "Cada estudioso debe tener un nombre diferente. ",
 	"The email address is not valid. ":               "La dirección de correo electrónico no es válida. ",
@@ -114,6 +125,14 @@ var spanish = map[string]string{
 	"If you need to withdraw from the class, please return to this website and remove your registration.  You may also send email to serv@sunnyvale.ca.gov.":                                                                 "Si necesita retirarse de la clase, regrese a este sitio web y vacie su inscripción. También puede enviar un correo electrónico a serv@sunnyvale.ca.gov.",
 	"A confirmation message has been sent to %s.": "Se ha enviado un mensaje de confirmación a %s.",
 
+	"This student is number %d on the waiting list.":                                                           "Este estudioso es el número %d en la lista de espera.",
+	"A place is being held for this student until %s.  Please confirm it using the link in the email we sent.": "Se reserva un lugar para este estudioso hasta %s.  Por favor, confírmelo usando el enlace del mensaje que le enviamos.",
+
+	"There have been too many registration attempts.  Please try again later.":                                                                                                          "Ha habido demasiados intentos de inscripción.  Por favor, inténtelo más tarde.",
+	"We have sent you an email asking you to confirm your email address.  Your registrations will be pending until you do.":                                                             "Le hemos enviado un mensaje pidiéndole que confirme su dirección de correo electrónico.  Sus inscripciones quedarán pendientes hasta que lo haga.",
+	"These registrations are pending until you confirm your email address, using the link in the separate email we sent you.  If you do not confirm it in time, they will be canceled.": "Estas inscripciones quedan pendientes hasta que confirme su dirección de correo electrónico, usando el enlace del otro mensaje que le enviamos.  Si no la confirma a tiempo, se cancelarán.",
+	"Your registration is not final until you confirm your email address, using the link in the separate email we sent you.":                                                            "Su inscripción no es definitiva hasta que confirme su dirección de correo electrónico, usando el enlace del otro mensaje que le enviamos.",
+
 	// pages/classes/reglogin.go:
 	"To register for this class, please enter your email address.":                   "Para inscribirse en esta clase, introduzca su dirección de correo electrónico.",
 	"To subscribe to notifications of new classes, please enter your email address.": "Para suscribirse a las notificaciones de nuevas clases, introduzca su dirección de correo electrónico.",
@@ -129,6 +148,45 @@ var spanish = map[string]string{
 	"The cell phone is used only for urgent notifications, such as last-minute cancellation of a class.  It is optional.":    "El teléfono móvil sólo se utiliza para notificaciones urgentes, como la cancelación de una clase en el último momento.  Es opcional.",
 	"Create Account": "Crear cuenta",
 
+	"We could not confirm that this request came from a person.  Please wait a moment and try again.": "No pudimos confirmar que esta solicitud proviene de una persona.  Por favor, espere un momento e inténtelo de nuevo.",
+
+	// pages/classes/roster.go:
+	"Welcome to Sunnyvale SERV": "Bienvenido a Sunnyvale SERV",
+	"Congratulations on completing %s!  You are now a member of Sunnyvale SERV, and you have an account on our web site, where you can see upcoming events, sign up for shifts, and keep your contact information up to date.": "¡Felicitaciones por completar %s!  Ahora es miembro de Sunnyvale SERV y tiene una cuenta en nuestro sitio web, donde puede ver los próximos eventos, inscribirse en turnos y mantener actualizada su información de contacto.",
+	"To log in, go to %s/login and use your email address, %s.  If you have not yet set a password, or have forgotten it, use the password reset link on that page.":                                                           "Para iniciar sesión, vaya a %s/login y use su dirección de correo electrónico, %s.  Si aún no ha establecido una contraseña, o la ha olvidado, use el enlace para restablecer la contraseña en esa página.",
+	"If you have any questions, just reply to this email.": "Si tiene alguna pregunta, simplemente responda a este correo electrónico.",
+
+	// pages/classes/survey.go (and also cmd/send-surveys):
+	"Class Survey": "Encuesta de la clase",
+	"Thank you for taking this class.  Please help us improve it by answering these questions.  All questions are optional.": "Gracias por tomar esta clase.  Por favor, ayúdenos a mejorarla respondiendo a estas preguntas.  Todas las preguntas son opcionales.",
+	"1 = poor, 5 = excellent": "1 = malo, 5 = excelente",
+	"Your name":               "Su nombre",
+	"Optional.  Leave this blank to respond anonymously.":                "Opcional.  Déjelo en blanco para responder de forma anónima.",
+	"Thank you for your feedback!":                                       "¡Gracias por sus comentarios!",
+	"We have already received your response to this survey.  Thank you!": "Ya hemos recibido su respuesta a esta encuesta.  ¡Gracias!",
+	"Thank you for taking the %s class that ended on %s.  We would appreciate your feedback, which will help us improve the class.  Please answer a few short questions at:": "Gracias por tomar la clase %s que terminó el %s.  Le agradeceríamos sus comentarios, que nos ayudarán a mejorar la clase.  Por favor, responda a unas breves preguntas en:",
+	"Your responses are anonymous unless you choose to include your name.":                                                                                                   "Sus respuestas son anónimas a menos que usted decida incluir su nombre.",
+
+	// pages/classes/verify.go:
+	"Please confirm your email address": "Por favor, confirme su dirección de correo electrónico",
+	"Thank you for creating an account on SunnyvaleSERV.org.  To confirm that this is your email address, please visit:":                                                     "Gracias por crear una cuenta en SunnyvaleSERV.org.  Para confirmar que esta es su dirección de correo electrónico, visite:",
+	"Until you do, any class registrations you make are pending.  If you do not confirm your email address by %s, your account and its class registrations will be removed.": "Hasta que lo haga, las inscripciones que haga en clases quedarán pendientes.  Si no confirma su dirección de correo electrónico antes de %s, se eliminarán su cuenta y sus inscripciones.",
+	"If you did not create this account, you can ignore this email.":                                                                                                         "Si usted no creó esta cuenta, puede ignorar este mensaje.",
+	"Thank you, %s!  Your email address is confirmed.":                                                                                                                       "¡Gracias, %s! Su dirección de correo electrónico está confirmada.",
+	"Your registrations for these classes are now final:":                                                                                                                    "Sus inscripciones en estas clases ya son definitivas:",
+
+	// pages/classes/waitlist.go:
+	"The place we were holding for you in our “%s” class was not confirmed in time, so it has been offered to the next person on the waiting list.": "El lugar que le reservábamos en nuestra clase “%s” no se confirmó a tiempo, así que se ha ofrecido a la siguiente persona en la lista de espera.",
+	"A place has opened up in our “%s” class, and you have been moved from the waiting list into the class:":                                        "Se ha abierto un lugar en nuestra clase “%s”, y ha pasado de la lista de espera a la clase:",
+	"We are holding this place for you until %s.  To keep it, please confirm at:":                                                                   "Le reservamos este lugar hasta %s.  Para conservarlo, confírmelo en:",
+	"If you do not confirm by then, your place will be offered to the next person on the waiting list.":                                             "Si no lo confirma para entonces, su lugar se ofrecerá a la siguiente persona en la lista de espera.",
+	"If you need to withdraw from the class, please reply to this email and let us know.":                                                           "Si necesita retirarse de la clase, responda a este mensaje e infórmenos.",
+	"Thank you!  The place for %s %s in this class is confirmed.":                                                                                   "¡Gracias! El lugar de %s %s en esta clase está confirmado.",
+	"Thank you!  The registration of %s %s in this class is canceled.":                                                                              "¡Gracias! La inscripción de %s %s en esta clase está cancelada.",
+	"A place in this class is being held for %s %s until %s.  Please confirm whether you still want it.":                                            "Se reserva un lugar en esta clase para %s %s hasta %s.  Por favor, confirme si todavía lo desea.",
+	"Confirm":             "Confirmar",
+	"Cancel Registration": "Cancelar inscripción",
+
 	// pages/errpage/errpage.go:
 	"No Such Page": "No existe esa página",
 	"Sorry, the page you asked for doesn’t exist.  But we have plenty of other good ones!  You can <a href=\"javascript:history.back()\">go back</a> to where you were, or return to <a href=\"/\">the home page</a>.  Look around; you’re sure to find a page you like.": "Lo sentimos, la página que solicitó no existe.  ¡Pero tenemos muchas otras buenas! Puede <a href=\"javascript:history.back()\">volver</a> a donde estaba o regrese a la <a href=\"/\">la página de inicio</a>.  Mire alrededor; Seguro que encontrará una página que le gusta.",
@@ -207,6 +265,23 @@ var spanish = map[string]string{
 	"Remember me":       "Recuérdeme",
 	"Reset my password": "Restablecer contraseña",
 
+	"There have been too many failed login attempts from your network.  Please try again after %s.": "Ha habido demasiados intentos fallidos de inicio de sesión desde su red.  Por favor, inténtelo de nuevo después de las %s.",
+	"Email me a sign-in link":            "Enviarme un enlace de inicio de sesión",
+	"Your account must sign in with %s.": "Su cuenta debe iniciar sesión con %s.",
+
+	// pages/login/loginlink.go:
+	"SunnyvaleSERV.org Sign-In Link":                    "Enlace de inicio de sesión de SunnyvaleSERV.org",
+	"To sign in to SunnyvaleSERV.org, click this link:": "Para iniciar sesión en SunnyvaleSERV.org, haga clic en este enlace:",
+	"This link can be used only once, and only in the browser where you asked for it.  It expires in 15 minutes.":      "Este enlace solo se puede usar una vez, y solo en el navegador en el que lo pidió.  Caduca en 15 minutos.",
+	"If you did not ask to sign in, you can safely ignore this email.  If you have any problems, reply to this email.": "Si no ha pedido iniciar sesión, puede ignorar este mensaje.  Si tiene algún problema, responda a este mensaje.",
+	"Sign-In Link": "Enlace de inicio de sesión",
+	"If the email address you provided is one we have on file, we have sent a sign-in link to it.  It is valid for 15 minutes.  Please open it in this browser.":                                                                               "Si la dirección de correo electrónico que nos ha facilitado es una de las que tenemos archivadas, le hemos enviado un enlace de inicio de sesión.  Es válido durante 15 minutos.  Ábralo en este navegador.",
+	"If you do not receive an email with a sign-in link, it may be that the email address you provided is not the one we have on file for you. Contact <a href=\"mailto:admin@sunnyvaleserv.org\">admin@SunnyvaleSERV.org</a> for assistance.": "Si no recibe un mensaje con un enlace de inicio de sesión, es posible que la dirección de correo electrónico que nos ha facilitado no sea la que tenemos registrada. Póngase en contacto con <a href=\"mailto:admin@sunnyvaleserv.org\">admin@SunnyvaleSERV.org</a> para obtener ayuda.",
+	"To sign in without your password, enter your email address.  If it’s one we have on file, we’ll send a sign-in link to it.":                                                                                                               "Para iniciar sesión sin su contraseña, introduzca su dirección de correo electrónico.  Si es una de las que tenemos archivadas, le enviaremos un enlace de inicio de sesión.",
+	"Send Sign-In Link": "Enviar enlace de inicio de sesión",
+	"Too many sign-in links have been requested.  Please try again later.":                                                                   "Se han pedido demasiados enlaces de inicio de sesión.  Por favor, inténtelo más tarde.",
+	"This sign-in link is invalid, has expired, or has already been used.  Sign-in links work only in the browser where you asked for them.": "Este enlace de inicio de sesión no es válido, ha caducado o ya se ha usado.  Los enlaces de inicio de sesión solo funcionan en el navegador en el que los pidió.",
+
 	// pages/login/newpwd.go:
 	"The two passwords are not the same.":                                     "",
 	"Please specify a new password, twice.":                                   "",
@@ -241,6 +316,11 @@ var spanish = map[string]string{
 	"Use a few words.  Avoid common phrases":                                  "Use pocas palabras.  Evite frases comunes.",
 	"Use a longer keyboard pattern with more turns.":                          "Utilice un patrón de teclado más largo y con más vueltas.",
 
+	// pages/login/passkey.go:
+	"Log in with a Passkey": "Iniciar sesión con una llave de acceso",
+	"That passkey could not be used. Please try again, or use your password.": "No se pudo usar esa llave de acceso. Por favor, inténtelo de nuevo o use su contraseña.",
+	"or log in with your password":                                            "o inicie sesión con su contraseña",
+
 	// pages/login/pwreset.go:
 	"Password Reset": "Restablecer contraseña",
 	"To reset your password, please enter your email address.  If it’s one we have on file, we’ll send a password reset link to it.": "Para restablecer su contraseña, introduzca su dirección de correo electrónico.  Si es una de las que tenemos archivadas, le enviaremos un enlace para restablecer la contraseña.",
@@ -249,9 +329,29 @@ var spanish = map[string]string{
 	"If you have any problems, reply to this email. If you did not request a password reset, you can safely ignore this email.":                                                                                                                       "Si tiene algún problema, responda a este mensaje.  Si no ha solicitado un restablecimiento de contraseña, puede ignorar este mensaje.",
 	"We have sent a password reset link to the email address you provided. It is valid for one hour. Please check your email and follow the link we sent to reset your password.":                                                                     "Hemos enviado un enlace para restablecer la contraseña a la dirección de correo electrónico que nos ha facilitado. Es válido durante una hora. Compruebe su correo electrónico y siga el enlace que le hemos enviado para restablecer su contraseña.",
 	"If you do not receive an email with a password reset link, it may be that the email address you provided is not the one we have on file for you. Contact <a href=\"mailto:admin@sunnyvaleserv.org\">admin@SunnyvaleSERV.org</a> for assistance.": "Si no recibe un mensaje con un enlace para restablecer la contraseña, es posible que la dirección de correo electrónico que nos ha facilitado no sea la que tenemos registrada. Póngase en contacto con <a href=\"mailto:admin@sunnyvaleserv.org\">admin@SunnyvaleSERV.org</a> para obtener ayuda.",
-	"This password reset link is invalid or has expired.": "Este enlace para restablecer la contraseña no es válido o ha caducado.",
+	"This password reset link is invalid or has expired.":                                                                                                                                                                                             "Este enlace para restablecer la contraseña no es válido o ha caducado.",
 	"Try Again": "Intentárlo de nuevo",
 
+	// pages/login/sso.go:
+	"Log in with %s": "Iniciar sesión con %s",
+	"or":             "o",
+	"The single sign-on service is not responding.  Please try again later, or log in with your password.":                                      "El servicio de inicio de sesión único no responde.  Por favor, inténtelo más tarde o inicie sesión con su contraseña.",
+	"Your %s account is not linked to an account on this site.  Please log in with your password, or contact admin@SunnyvaleSERV.org for help.": "Su cuenta de %s no está vinculada a ninguna cuenta de este sitio.  Inicie sesión con su contraseña o póngase en contacto con admin@SunnyvaleSERV.org para obtener ayuda.",
+	"Single sign-on did not succeed.  Please try again.":                                                                                        "El inicio de sesión único no tuvo éxito.  Por favor, inténtelo de nuevo.",
+	"Single Sign-On": "Inicio de sesión único",
+
+	// pages/login/totp.go:
+	"Two-Factor Authentication": "Autenticación de dos factores",
+	"Code":                      "Código",
+	"Enter the code shown in your authenticator app.  If you don’t have your authenticator app, you can enter one of your recovery codes instead.": "Introduzca el código que aparece en su aplicación de autenticación.  Si no tiene su aplicación de autenticación, puede introducir uno de sus códigos de recuperación.",
+	"That code is not correct. Please try again.":                             "Ese código no es correcto. Por favor, inténtelo de nuevo.",
+	"Your account requires two-factor authentication.  Please set it up now.": "Su cuenta requiere la autenticación de dos factores.  Por favor, configúrela ahora.",
+	"Continue": "Continuar",
+	"Scan this QR code with an authenticator app, such as Google Authenticator or Microsoft Authenticator.": "Escanee este código QR con una aplicación de autenticación, como Google Authenticator o Microsoft Authenticator.",
+	"Or, enter this key into the app manually:":                                                             "O bien, introduzca esta clave en la aplicación manualmente:",
+	"Code from App": "Código de la aplicación",
+	"These are your recovery codes.  If you lose access to your authenticator app, you can log in with one of these codes instead.  Each code works only once.  Please print them or write them down, and keep them somewhere safe.  They will not be shown again.": "Estos son sus códigos de recuperación.  Si pierde el acceso a su aplicación de autenticación, puede iniciar sesión con uno de estos códigos.  Cada código funciona solo una vez.  Imprímalos o anótelos y guárdelos en un lugar seguro.  No se volverán a mostrar.",
+
 	// pages/people/*:
 	"(all)":           "(todos)",
 	"Edit":            "Editar",
@@ -276,12 +376,55 @@ var spanish = map[string]string{
 	"cell":              "móvil",
 	"home":              "casa",
 	"work":              "trabajo",
+	"Available":         "Disponible",
 
 	// pages/people/peoplemap/peoplemap.go:
 	"(Business Hours)": "(Horas de trabajo)",
 	"Home[ADDR]":       "En casa",
 	"Business":         "A trabajo",
 
+	// pages/people/persondata/html.go:
+	"SERV Data for %s":                   "Datos de SERV para %s",
+	"Generated %s by SunnyvaleSERV.org.": "Generado el %s por SunnyvaleSERV.org.",
+	"Formal Name":                        "Nombre formal",
+	"Sort Name":                          "Nombre para ordenar",
+	"Call Sign":                          "Indicativo",
+	"Mail Address":                       "Dirección de correos",
+	"DOJ Background Check":               "Verificación de antecedentes del DOJ",
+	"FBI Background Check":               "Verificación de antecedentes del FBI",
+	"PHS Background Check":               "Verificación de antecedentes de PHS",
+	"Roles":                              "Papeles",
+	"Class Registrations":                "Inscripciones en clases",
+	"Shift Signups":                      "Inscripciones en turnos",
+	"Attendance and Hours":               "Asistencia y horas",
+	"Text Messages":                      "Mensajes de texto",
+	"None.":                              "Ninguno.",
+	"waitlisted":                         "en lista de espera",
+	"declined":                           "rechazado",
+	"attended":                           "asistió",
+	"Reply:":                             "Respuesta:",
+
+	// pages/people/personedit/apitokens.go:
+	"Please give this token a name.":                        "Por favor, dé un nombre a este token.",
+	"Please select at least one permission for this token.": "Por favor, seleccione al menos un permiso para este token.",
+	"API Tokens": "Tokens de API",
+	"An API token lets a program you write, or another service you use, access this site on your behalf.  A token can do only what you could do yourself, and only what you allow it to.  Treat it like a password.": "Un token de API permite que un programa que usted escriba, u otro servicio que use, acceda a este sitio en su nombre.  Un token sólo puede hacer lo que usted mismo podría hacer, y sólo lo que usted le permita.  Trátelo como una contraseña.",
+	"Your new token is shown below.  Copy it now; it will not be shown again.": "Su nuevo token se muestra abajo.  Cópielo ahora; no se volverá a mostrar.",
+	"New Token":                        "Nuevo token",
+	"What the token is for":            "Para qué es el token",
+	"Permissions":                      "Permisos",
+	"Create Token":                     "Crear token",
+	"Created %s":                       "Creado %s",
+	"Revoke":                           "Revocar",
+	"No API tokens have been created.": "No se han creado tokens de API.",
+
+	// pages/people/personedit/availability.go:
+	"Enter the times you are usually available on each day of the week, such as “9:00-12:00, 18:00-21:00”.  Leave a day blank if you are not usually available on that day.": "Ingrese las horas en las que normalmente está disponible cada día de la semana, como “9:00-12:00, 18:00-21:00”.  Deje un día en blanco si normalmente no está disponible ese día.",
+	"Dates on which you are not available, one per line, such as “2024-12-24” or “2024-12-24 - 2025-01-02”.":                                                                 "Fechas en las que no está disponible, una por línea, como “2024-12-24” o “2024-12-24 - 2025-01-02”.",
+	"%q is not a valid time range.":         "%q no es un rango de horas válido.",
+	"The time ranges overlap.":              "Los rangos de horas se superponen.",
+	"%q is not a valid date or date range.": "%q no es una fecha o rango de fechas válido.",
+
 	// pages/people/personedit/contact.go:
 	"Edit Contact Information":                          "Editar información de contacto",
 	"%q is not a valid email address.":                  "%q no es una dirección de correo electrónico válida.",
@@ -316,6 +459,12 @@ var spanish = map[string]string{
 	"Son":                                                 "Hijo",
 	"Spouse":                                              "Cónyuge",
 	"Supervisor":                                          "Supervisor",
+	"Medical Notes":                                       "Notas médicas",
+	"Optional.  Anything you want an incident lead to know in a medical emergency during a deployment, such as allergies, conditions, or medications.  This is shown only on the emergency contact sheet for events you are deployed to.": "Opcional.  Cualquier cosa que quiera que sepa el líder del incidente en caso de una emergencia médica durante un despliegue, como alergias, condiciones o medicamentos.  Esto se muestra solamente en la hoja de contactos de emergencia de los eventos a los que se le despliega.",
+
+	// pages/people/personedit/loginlink.go:
+	"If you allow it, you can sign in without your password by asking for a sign-in link to be sent to your email address.  Anyone who can read your email could then sign in as you.": "Si lo permite, puede iniciar sesión sin su contraseña pidiendo que se envíe un enlace de inicio de sesión a su dirección de correo electrónico.  Cualquier persona que pueda leer su correo electrónico podría entonces iniciar sesión como usted.",
+	"Allow sign-in links": "Permitir enlaces de inicio de sesión",
 
 	// pages/people/personedit/names.go:
 	"Edit Names":            "Editar nombres",
@@ -338,6 +487,22 @@ var spanish = map[string]string{
 	"she/her/hers":                                      "ella/la",
 	"they/them/theirs":                                  "elle/le",
 
+	// pages/people/personedit/passkeys.go:
+	"Please give this passkey a name.":                   "Por favor, dé un nombre a esta llave de acceso.",
+	"This passkey is already registered.":                "Esta llave de acceso ya está registrada.",
+	"The passkey could not be added.  Please try again.": "No se pudo añadir la llave de acceso.  Por favor, inténtelo de nuevo.",
+	"Passkeys": "Llaves de acceso",
+	"A passkey lets you log in with your phone, computer, or security key — using your fingerprint, face, or screen lock — instead of typing your password.  You can add a passkey for each device you use.": "Una llave de acceso le permite iniciar sesión con su teléfono, computadora o llave de seguridad — usando su huella digital, su cara o el bloqueo de pantalla — en lugar de escribir su contraseña.  Puede añadir una llave de acceso para cada dispositivo que use.",
+	"Added %s":                      "Añadida %s",
+	"last used %s":                  "último uso %s",
+	"No passkeys have been added.":  "No se han añadido llaves de acceso.",
+	"New Passkey":                   "Nueva llave de acceso",
+	"Device name, e.g. “My iPhone”": "Nombre del dispositivo, p. ej. “Mi iPhone”",
+	"Give the passkey a name that tells you which device it’s on.": "Dé a la llave de acceso un nombre que le indique en qué dispositivo está.",
+	"This browser does not support passkeys.":                      "Este navegador no admite llaves de acceso.",
+	"Close":       "Cerrar",
+	"Add Passkey": "Añadir llave de acceso",
+
 	// pages/people/personedit/password.go:
 	"Password Change":                       "Cambiar de contraseña",
 	"Please specify your old password.":     "Por favor ingrese su contraseña anterior.",
@@ -346,6 +511,18 @@ var spanish = map[string]string{
 	"Please specify a valid new password.":  "Por favor ingrese una nueva contraseña válida.",
 	"The new password is too weak.":         "La nueva contraseña es demasiado débil.",
 
+	// pages/people/personedit/photo.go:
+	"Edit Photo": "Editar foto",
+	"Current":    "Actual",
+	"New Photo":  "Nueva foto",
+	"Please use a recent photo showing your face clearly, so that leaders can recognize you at events.  It will be cropped to a square.": "Por favor use una foto reciente que muestre su cara claramente, para que los líderes le puedan reconocer en los eventos.  Se recortará a un cuadrado.",
+	"Typically this is the person’s DPS badge photo.  It will be cropped to a square.":                                                   "Normalmente es la foto de la credencial DPS de la persona.  Se recortará a un cuadrado.",
+	"Remove":                                       "Quitar",
+	"Please choose a photo to upload.":             "Por favor elija una foto para subir.",
+	"The photo file is too large.":                 "El archivo de la foto es demasiado grande.",
+	"The photo was not uploaded correctly.":        "La foto no se subió correctamente.",
+	"The photo must be a JPEG, PNG, or GIF image.": "La foto debe ser una imagen JPEG, PNG o GIF.",
+
 	// pages/people/personedit/pwreset.go:
 	"%s has reset the password for your account on SunnyvaleSERV.org.  Your new login information is:": "%s ha restablecido la contraseña de su cuenta en SunnyvaleSERV.org.  Su nueva información de acceso es:",
 	"Email:    %s": "Email:      %s",
@@ -360,6 +537,18 @@ var spanish = map[string]string{
 	"Messages sent to %s are considered required for the %s roles.  Unsubscribing from it may cause you to lose those roles.": "Los mensajes enviados a %s se consideran obligatorios para los papeles “%s” y “%s”.  Desuscribirse puede hacer que pierda esos papeles.",
 	"Unsubscribe All": "Desuscribirse a todos",
 
+	// pages/people/personedit/totp.go:
+	"Two-factor authentication protects your account even if someone learns your password.  When it is turned on, logging in requires a code from an authenticator app on your phone as well as your password.": "La autenticación de dos factores protege su cuenta incluso si alguien descubre su contraseña.  Cuando está activada, para iniciar sesión se requiere un código de una aplicación de autenticación en su teléfono además de su contraseña.",
+	"Turn On": "Activar",
+	"Two-factor authentication is turned on for your account.  It is required, so it cannot be turned off.": "La autenticación de dos factores está activada para su cuenta.  Es obligatoria, así que no se puede desactivar.",
+	"Two-factor authentication is turned on for your account.":                                              "La autenticación de dos factores está activada para su cuenta.",
+	"You have %d unused recovery codes.":                                                                    "Tiene %d códigos de recuperación sin usar.",
+	"Enter the code shown in your authenticator app, or one of your recovery codes.":                        "Introduzca el código que aparece en su aplicación de autenticación, o uno de sus códigos de recuperación.",
+	"Turn Off":           "Desactivar",
+	"New Recovery Codes": "Nuevos códigos de recuperación",
+	"Two-factor authentication is turned off for your account.": "La autenticación de dos factores está desactivada para su cuenta.",
+	"Done": "Listo",
+
 	// pages/people/personedit/vregister.go:
 	"Register as a City Volunteer": "Registrarse como voluntario de ciudad",
 	"Thank you for your interest in volunteering with the City of Sunnyvale, Office of Emergency Services.  Please complete this form to register as a City of Sunnyvale Volunteer.  (Please note: registering as a city volunteer is not required for taking one of our classes.  It is only required when joining one of our volunteer groups.)":                                                                                                                                             "Gracias por su interés en ser voluntario en la Oficina de Servicios de Emergencia de la ciudad de Sunnyvale.  Complete este formulario para registrarse como voluntario de la ciudad de Sunnyvale.  Una vez que recibamos su registro (lo que generalmente demora unos días), nos comunicaremos con usted para programar una cita para su toma de huellas digitales.  (Tenga en cuenta: no es necesario registrarse como voluntario de la ciudad para tomar una de nuestras clases.  Solo es necesario cuando se une a uno de nuestros grupos de voluntarios).",
@@ -379,6 +568,14 @@ var spanish = map[string]string{
 	"Register": "Registrarse",
 	"Thank you for volunteering with the City of Sunnyvale, Office of Emergency Services.  One of our staff will contact you to schedule a fingerprinting appointment.  (Criminal history checks are required by city policy for all public-facing volunteers.)  If you have not heard from us within a few days, please email us at oes@sunnyvale.ca.gov to follow up.  We look forward to working with you!": "Gracias por ser voluntario con la Ciudad de Sunnyvale, Oficina de Servicios de Emergencia.  Uno de nuestro personal se pondrá en contacto con usted para programar una cita para la toma de huellas dactilares.  (Las comprobaciones de antecedentes penales son requeridas por la política de la ciudad para todos los voluntarios de cara al público).  Si usted no ha oído hablar de nosotros dentro de unos días, por favor envíenos un correo electrónico a oes@sunnyvale.ca.gov para hacer un seguimiento.  Estamos deseando trabajar con usted.",
 
+	// pages/people/personview/availability.go:
+	"Availability":                    "Disponibilidad",
+	"No usual availability recorded.": "No hay disponibilidad habitual registrada.",
+	"Unavailable":                     "No disponible",
+
+	// pages/people/personview/certificates.go:
+	"Certificates": "Certificados",
+
 	// pages/people/personview/contact.go:
 	"Contact Information":            "Información de contacto",
 	"(Cell)":                         "(Móvil)",
@@ -390,17 +587,38 @@ var spanish = map[string]string{
 	"%d emergency contacts on file.": "%d contactos de emergencia registrados.",
 	"Sunnyvale Fire District %d":     "Distrito de bomberos %d de Sunnyvale",
 
+	// pages/people/personview/data.go:
+	"Your Data": "Sus datos",
+	"You can download a copy of all of the information we have about you.": "Puede descargar una copia de toda la información que tenemos sobre usted.",
+	"Download My Data": "Descargar mis datos",
+
+	// pages/people/personview/names.go:
+	"Photo": "Foto",
+
 	// pages/people/personview/notes.go:
 	"Notes": "Notas",
 
 	// pages/people/personview/password.go:
-	"Change Password": "Cambiar contraseña",
+	"Change Password":     "Cambiar contraseña",
+	"Two-Factor Settings": "Configuración de dos factores",
+	"Sign-In Links":       "Enlaces de inicio de sesión",
+	"Set Up Two-Factor":   "Configurar dos factores",
 
 	// pages/people/personview/roles.go:
 	"SERV Roles":                       "Papeles en SERV",
 	"SERV Role":                        "Papel en SERV",
 	"No current role in any SERV org.": "No tiene ningún papel actual en ninguna organization de SERV.",
 
+	// pages/people/personview/sessions.go:
+	"Your Sessions":            "Sus sesiones",
+	"signed in %s":             "inició sesión el %s",
+	"last active %s":           "última actividad el %s",
+	"This device":              "Este dispositivo",
+	"Sign Out":                 "Cerrar sesión",
+	"Sign Out Everywhere Else": "Cerrar sesión en todos los demás lugares",
+	"%s on %s":                 "%s en %s",
+	"Unknown browser":          "Navegador desconocido",
+
 	// pages/people/personview/status.go:
 	"Volunteer Status":          "Estado del voluntario",
 	"City volunteer":            "Voluntario de la ciudad",
@@ -548,11 +766,14 @@ var spanish = map[string]string{
 	"Using the “Map Your Neighborhood” (MYN) program provided by the Washington State Emergency Management Division, we lead a two-hour meeting of around 15–25 households.  Neighbors learn the 9 Steps to take following a disaster, identify resources and skills available in their neighborhood that will be useful in a disaster response, and “map” any special challenges or people with particular needs.  As part of this model, neighbors get to know each other and are better prepared to work together responding to a disaster.": "Utilizando el programa MYN (“Mapear su vecindario”, por sus siglas en inglés) proporcionado por la División de Gestión de Emergencias del Estado de Washington, dirigimos una reunión de dos horas de duración en la que participan entre 15 y 25 hogares.  Los vecinos aprenden los 9 pasos a seguir tras un desastre, identifican los recursos y habilidades disponibles en su vecindario que serán útiles en una respuesta al desastre, y “mapean” cualquier desafío especial o personas con necesidades particulares.  Como parte de este modelo, los vecinos se conocen entre sí y están mejor preparados para trabajar juntos en la respuesta a un desastre.",
 	"For more information about SNAP, or to arrange a MYN meeting for your neighborhood, write to <a href=mailto:snap@sunnyvale.ca.gov target=_blank>snap@sunnyvale.ca.gov</a>.": "Para más información sobre SNAP, o para organizar una reunión de MYN para su vecindario, escriba a <a href=mailto:snap@sunnyvale.ca.gov target=_blank>snap@sunnyvale.ca.gov</a>.",
 
-	// store/class/referral.go:
-	"Word of mouth":                 "Boca a boca",
-	"Information table at an event": "Mesa informativa en un evento",
-	"Printed advertisement":         "Publicidad impresa",
-	"Online advertisement":          "Publicidad en línea",
+	// store/apitoken/scope.go:
+	"Read events, shifts, and signups":        "Ver eventos, turnos e inscripciones",
+	"Sign up for shifts":                      "Inscribirse en turnos",
+	"Read people and contact information":     "Ver personas e información de contacto",
+	"Read roles and their members' names":     "Ver papeles y los nombres de sus miembros",
+	"Read lists and their subscribers' names": "Ver listas y los nombres de sus suscriptores",
+	"Read volunteer hours":                    "Ver horas de voluntariado",
+	"Record volunteer hours":                  "Registrar horas de voluntariado",
 
 	// store/shiftperson/eligibility.go:
 	"Already signed up for a conflicting shift.": "Ya se inscribió a un turno conflictivo.",
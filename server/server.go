@@ -8,22 +8,32 @@ import (
 	"time"
 
 	"golang.org/x/text/language"
+	"sunnyvaleserv.org/portal/pages/admin/archive"
 	"sunnyvaleserv.org/portal/pages/admin/classedit"
 	"sunnyvaleserv.org/portal/pages/admin/classlist"
+	"sunnyvaleserv.org/portal/pages/admin/duplicates"
 	"sunnyvaleserv.org/portal/pages/admin/listedit"
 	"sunnyvaleserv.org/portal/pages/admin/listlist"
 	"sunnyvaleserv.org/portal/pages/admin/listpeople"
 	"sunnyvaleserv.org/portal/pages/admin/listrole"
+	"sunnyvaleserv.org/portal/pages/admin/lockouts"
 	"sunnyvaleserv.org/portal/pages/admin/rediredit"
 	"sunnyvaleserv.org/portal/pages/admin/redirlist"
+	"sunnyvaleserv.org/portal/pages/admin/referraledit"
+	"sunnyvaleserv.org/portal/pages/admin/referrallist"
 	"sunnyvaleserv.org/portal/pages/admin/roleedit"
 	"sunnyvaleserv.org/portal/pages/admin/rolelist"
 	"sunnyvaleserv.org/portal/pages/admin/venueedit"
 	"sunnyvaleserv.org/portal/pages/admin/venuelist"
+	"sunnyvaleserv.org/portal/pages/admin/webhookedit"
+	"sunnyvaleserv.org/portal/pages/admin/webhooklist"
+	"sunnyvaleserv.org/portal/pages/admin/webhooklog"
+	"sunnyvaleserv.org/portal/pages/api"
 	"sunnyvaleserv.org/portal/pages/classes"
 	"sunnyvaleserv.org/portal/pages/classes/classlists"
 	"sunnyvaleserv.org/portal/pages/classes/regedit"
 	"sunnyvaleserv.org/portal/pages/errpage"
+	"sunnyvaleserv.org/portal/pages/events/emsheet"
 	"sunnyvaleserv.org/portal/pages/events/eventattend"
 	"sunnyvaleserv.org/portal/pages/events/eventcopy"
 	"sunnyvaleserv.org/portal/pages/events/eventedit"
@@ -37,14 +47,19 @@ import (
 	"sunnyvaleserv.org/portal/pages/files"
 	"sunnyvaleserv.org/portal/pages/files/docedit"
 	"sunnyvaleserv.org/portal/pages/files/folderedit"
+	"sunnyvaleserv.org/portal/pages/history"
 	"sunnyvaleserv.org/portal/pages/homepage"
 	"sunnyvaleserv.org/portal/pages/login"
 	"sunnyvaleserv.org/portal/pages/people/activity"
+	"sunnyvaleserv.org/portal/pages/people/peopleavail"
 	"sunnyvaleserv.org/portal/pages/people/peoplelist"
 	"sunnyvaleserv.org/portal/pages/people/peoplemap"
+	"sunnyvaleserv.org/portal/pages/people/persondata"
 	"sunnyvaleserv.org/portal/pages/people/personedit"
 	"sunnyvaleserv.org/portal/pages/people/personview"
+	actrep "sunnyvaleserv.org/portal/pages/reports/activations"
 	attrep "sunnyvaleserv.org/portal/pages/reports/attendance"
+	classrep "sunnyvaleserv.org/portal/pages/reports/classes"
 	clearrep "sunnyvaleserv.org/portal/pages/reports/clearance"
 	"sunnyvaleserv.org/portal/pages/search"
 	"sunnyvaleserv.org/portal/pages/static"
@@ -167,32 +182,62 @@ func route(r *request.Request) {
 		classlist.Get(r)
 	case c[0] == "admin" && c[1] == "classes" && c[2] != "" && c[3] == "":
 		classedit.Handle(r, c[2])
+	case c[0] == "admin" && c[1] == "archive" && c[2] == "":
+		archive.Get(r)
+	case c[0] == "admin" && c[1] == "archive" && c[2] != "" && c[3] == "":
+		archive.HandleReview(r, c[2])
+	case c[0] == "admin" && c[1] == "duplicates" && c[2] == "":
+		duplicates.Get(r)
+	case c[0] == "admin" && c[1] == "duplicates" && c[2] != "" && c[3] != "" && c[4] == "":
+		duplicates.HandleMerge(r, c[2], c[3])
 	case c[0] == "admin" && c[1] == "lists" && c[2] == "":
 		listlist.Get(r)
 	case c[0] == "admin" && c[1] == "lists" && c[2] != "" && c[3] == "":
 		listedit.Handle(r, c[2])
+	case c[0] == "admin" && c[1] == "lists" && c[2] != "" && c[3] == "history" && c[4] == "":
+		history.HandleList(r, c[2])
 	case c[0] == "admin" && c[1] == "lists" && c[2] != "" && c[3] != "" && c[4] == "":
 		listpeople.Get(r, c[2], c[3])
 	case c[0] == "admin" && c[1] == "lists" && c[2] != "" && c[3] == "roleedit" && c[4] != "" && c[5] == "":
 		listrole.Get(r, c[2], c[4])
+	case c[0] == "admin" && c[1] == "lockouts" && c[2] == "":
+		lockouts.Handle(r)
 	case c[0] == "admin" && c[1] == "redirects" && c[2] == "":
 		redirlist.Get(r)
 	case c[0] == "admin" && c[1] == "redirects" && c[2] != "" && c[3] == "":
 		rediredit.Handle(r, c[2])
+	case c[0] == "admin" && c[1] == "referrals" && c[2] == "":
+		referrallist.Handle(r)
+	case c[0] == "admin" && c[1] == "referrals" && c[2] != "" && c[3] == "":
+		referraledit.Handle(r, c[2])
 	case c[0] == "admin" && c[1] == "roles" && c[2] == "":
 		rolelist.Get(r)
 	case c[0] == "admin" && c[1] == "roles" && c[2] != "" && c[3] == "":
 		roleedit.Handle(r, c[2])
+	case c[0] == "admin" && c[1] == "roles" && c[2] != "" && c[3] == "history" && c[4] == "":
+		history.HandleRole(r, c[2])
 	case c[0] == "admin" && c[1] == "venues" && c[2] == "":
 		venuelist.Get(r)
 	case c[0] == "admin" && c[1] == "venues" && c[2] != "" && c[3] == "":
 		venueedit.Handle(r, c[2])
+	case c[0] == "admin" && c[1] == "webhooks" && c[2] == "":
+		webhooklist.Get(r)
+	case c[0] == "admin" && c[1] == "webhooks" && c[2] != "" && c[3] == "":
+		webhookedit.Handle(r, c[2])
+	case c[0] == "admin" && c[1] == "webhooks" && c[2] != "" && c[3] == "log" && c[4] == "":
+		webhooklog.Handle(r, c[2])
+	case c[0] == "api" && c[1] == "v1":
+		api.Route(r, c[2:])
 	case strings.EqualFold(c[0], "cert") && c[1] == "":
 		static.CERTPage(r)
 	case strings.EqualFold(c[0], "cert-basic") && c[1] == "":
 		classes.GetCERT(r)
 	case c[0] == "cert-basic" && c[1] == "notify" && c[2] == "":
 		classes.HandleNotify(r, "cert-basic")
+	case c[0] == "certificates" && c[1] != "" && c[2] == "":
+		classes.GetCertificate(r, c[1])
+	case c[0] == "certificates" && c[1] != "" && c[2] == "pdf" && c[3] == "":
+		classes.GetCertificatePDF(r, c[1])
 	case (strings.EqualFold(c[0], "classes") || strings.EqualFold(c[0], "clases")) && c[1] == "":
 		classes.GetClasses(r)
 	case c[0] == "classes" && (strings.EqualFold(c[1], "cert") || strings.EqualFold(c[1], "cert-basic")) && c[2] == "":
@@ -201,14 +246,34 @@ func route(r *request.Request) {
 		classes.GetMYN(r)
 	case c[0] == "classes" && (strings.EqualFold(c[1], "pep") || strings.EqualFold(c[1], "ppde")) && c[2] == "":
 		classes.GetPEP(r)
+	case c[0] == "classes" && c[1] == "confirm" && c[2] != "" && c[3] == "":
+		classes.HandleConfirm(r, c[2])
+	case c[0] == "classes" && c[1] == "verify" && c[2] != "" && c[3] == "":
+		classes.HandleVerify(r, c[2])
 	case c[0] == "classes" && c[1] == "regedit" && c[2] != "" && c[3] == "":
 		regedit.Handle(r, c[2])
+	case c[0] == "classes" && c[1] == "surveys" && c[2] != "" && c[3] == "":
+		classes.HandleSurveyQuestions(r, c[2])
+	case c[0] == "classes" && c[1] == "surveys" && c[2] != "" && c[3] != "" && c[4] == "":
+		classes.HandleSurveyQuestion(r, c[2], c[3])
+	case c[0] == "classes" && c[1] != "" && c[2] == "certificates" && c[3] == "":
+		classes.HandleCertificates(r, c[1])
 	case c[0] == "classes" && c[1] != "" && c[2] == "lists" && c[3] == "":
 		classlists.Handle(r, c[1])
 	case c[0] == "classes" && c[1] != "" && c[2] == "register" && c[3] == "":
 		classes.HandleRegister(r, c[1])
 	case c[0] == "classes" && c[1] != "" && c[2] == "reglist" && c[3] == "":
 		classes.GetRegList(r, c[1])
+	case c[0] == "classes" && c[1] != "" && c[2] == "roster" && c[3] == "":
+		classes.HandleRoster(r, c[1])
+	case c[0] == "classes" && c[1] != "" && c[2] == "roster" && c[3] != "" && c[4] == "":
+		classes.HandleRosterLink(r, c[1], c[3])
+	case c[0] == "classes" && c[1] != "" && c[2] == "sessions" && c[3] == "":
+		classes.GetSessions(r, c[1])
+	case c[0] == "classes" && c[1] != "" && c[2] == "sessions" && c[3] == "add" && c[4] == "":
+		classes.HandleAddSession(r, c[1])
+	case c[0] == "classes" && c[1] != "" && c[2] == "survey" && c[3] == "":
+		classes.GetSurveyResults(r, c[1])
 	case c[0] == "contact" && c[1] == "":
 		static.ContactUsPage(r)
 	case c[0] == "docedit" && c[1] != "" && c[2] != "" && c[3] == "":
@@ -225,6 +290,8 @@ func route(r *request.Request) {
 		eventedit.HandleShift(r, c[2])
 	case c[0] == "events" && c[1] == "edtask" && c[2] != "" && c[3] == "":
 		eventedit.HandleTask(r, c[2])
+	case c[0] == "events" && c[1] == "emergency" && c[2] != "" && c[3] == "":
+		emsheet.Handle(r, c[2])
 	case c[0] == "events" && c[1] == "eventlists" && c[3] == "":
 		eventlists.Handle(r, c[2])
 	case c[0] == "events" && c[1] == "list" && c[2] != "" && c[3] == "":
@@ -243,16 +310,24 @@ func route(r *request.Request) {
 		eventcopy.Handle(r, c[1])
 	case c[0] == "events" && c[1] != "" && c[2] == "eddetails" && c[3] == "":
 		eventedit.HandleDetails(r, c[1])
+	case c[0] == "events" && c[1] != "" && c[2] == "history" && c[3] == "":
+		history.HandleEvent(r, c[1])
 	case c[0] == "files":
 		files.Handle(r)
 	case c[0] == "folderedit" && c[1] != "" && c[2] == "":
 		folderedit.Handle(r, c[1])
+	case c[0] == "impersonate" && c[1] == "end" && c[2] == "":
+		login.HandleEndImpersonation(r)
 	case c[0] == "jserror":
 		errpage.PostJSError(r)
 	case strings.EqualFold(c[0], "listos") && c[1] == "":
 		static.PEPProgramPage(r)
 	case c[0] == "login":
 		login.HandleLogin(r)
+	case c[0] == "login-link" && c[1] == "":
+		login.HandleLoginLink(r)
+	case c[0] == "login-link" && c[1] != "" && c[2] == "":
+		login.HandleLoginLinkToken(r, c[1])
 	case c[0] == "logout":
 		login.HandleLogout(r)
 	case strings.EqualFold(c[0], "myn") && c[1] == "":
@@ -263,6 +338,8 @@ func route(r *request.Request) {
 		login.HandlePWResetToken(r, c[1])
 	case c[0] == "people" && c[1] == "":
 		peoplelist.Handle(r)
+	case c[0] == "people" && c[1] == "available" && c[2] == "":
+		peopleavail.Handle(r)
 	case c[0] == "people" && c[1] == "map" && c[2] == "":
 		peoplemap.Handle(r)
 	case c[0] == "people" && c[1] == "newuser" && c[2] == "":
@@ -271,20 +348,44 @@ func route(r *request.Request) {
 		personview.Get(r, c[1])
 	case c[0] == "people" && c[1] != "" && c[2] == "activity" && c[3] != "" && c[4] == "":
 		activity.HandleActivity(r, c[1], c[3])
+	case c[0] == "people" && c[1] != "" && c[2] == "data" && c[3] == "":
+		persondata.Get(r, c[1])
+	case c[0] == "people" && c[1] != "" && c[2] == "edapitokens" && c[3] == "":
+		personedit.HandleAPITokens(r, c[1])
+	case c[0] == "people" && c[1] != "" && c[2] == "edavailability" && c[3] == "":
+		personedit.HandleAvailability(r, c[1])
 	case c[0] == "people" && c[1] != "" && c[2] == "edcontact" && c[3] == "":
 		personedit.HandleContact(r, c[1])
+	case c[0] == "people" && c[1] != "" && c[2] == "edloginlink" && c[3] == "":
+		personedit.HandleLoginLink(r, c[1])
 	case c[0] == "people" && c[1] != "" && c[2] == "ednames" && c[3] == "":
 		personedit.HandleNames(r, c[1])
 	case c[0] == "people" && c[1] != "" && c[2] == "ednote" && c[4] == "":
 		personedit.HandleNote(r, c[1], c[3])
+	case c[0] == "people" && c[1] != "" && c[2] == "edpasskeys" && c[3] == "":
+		personedit.HandlePasskeys(r, c[1])
 	case c[0] == "people" && c[1] != "" && c[2] == "edpassword" && c[3] == "":
 		personedit.HandlePassword(r, c[1])
+	case c[0] == "people" && c[1] != "" && c[2] == "edphoto" && c[3] == "":
+		personedit.HandlePhoto(r, c[1])
 	case c[0] == "people" && c[1] != "" && c[2] == "edroles" && c[3] == "":
 		personedit.HandleRoles(r, c[1])
+	case c[0] == "people" && c[1] != "" && c[2] == "edsessions" && c[3] == "":
+		personedit.HandleSessions(r, c[1])
+	case c[0] == "people" && c[1] != "" && c[2] == "edsso" && c[3] == "":
+		personedit.HandleSSO(r, c[1])
 	case c[0] == "people" && c[1] != "" && c[2] == "edstatus" && c[3] == "":
 		personedit.HandleStatus(r, c[1])
 	case c[0] == "people" && c[1] != "" && c[2] == "edsubscriptions" && c[3] == "":
 		personedit.HandleSubscriptions(r, c[1])
+	case c[0] == "people" && c[1] != "" && c[2] == "edtotp" && c[3] == "":
+		personedit.HandleTOTP(r, c[1])
+	case c[0] == "people" && c[1] != "" && c[2] == "history" && c[3] == "":
+		history.HandlePerson(r, c[1])
+	case c[0] == "people" && c[1] != "" && c[2] == "impersonate" && c[3] == "":
+		login.HandleImpersonate(r, c[1])
+	case c[0] == "people" && c[1] != "" && c[2] == "photo" && c[3] == "":
+		personview.GetPhoto(r, c[1])
 	case c[0] == "people" && c[1] != "" && c[2] == "pwreset" && c[3] == "":
 		personedit.HandlePWReset(r, c[1])
 	case c[0] == "people" && c[1] != "" && c[2] == "vregister" && c[3] == "":
@@ -297,8 +398,18 @@ func route(r *request.Request) {
 		classes.HandleNotify(r, "pep")
 	case c[0] == "privacy-policy" && c[1] == "":
 		static.PrivacyPage(r)
+	case c[0] == "reports" && c[1] == "activations" && c[2] == "":
+		actrep.GetList(r)
+	case c[0] == "reports" && c[1] == "activations" && c[2] != "" && c[3] == "":
+		actrep.Get(r, c[2])
+	case c[0] == "reports" && c[1] == "activations" && c[2] != "" && c[3] == "edit" && c[4] == "":
+		actrep.HandleEdit(r, c[2])
+	case c[0] == "reports" && c[1] == "activations" && c[2] != "" && c[3] == "status" && c[4] == "":
+		actrep.HandleStatus(r, c[2])
 	case c[0] == "reports" && c[1] == "attendance" && c[2] == "":
 		attrep.Get(r)
+	case c[0] == "reports" && c[1] == "classes" && c[2] == "":
+		classrep.Get(r)
 	case c[0] == "reports" && c[1] == "clearance" && c[2] == "":
 		clearrep.Get(r)
 	case strings.EqualFold(c[0], "sares") && c[1] == "":
@@ -309,8 +420,14 @@ func route(r *request.Request) {
 		static.CreditsPage(r)
 	case strings.EqualFold(c[0], "snap") && c[1] == "":
 		static.SNAPPage(r)
+	case c[0] == "sso" && c[1] == "":
+		login.HandleSSO(r)
+	case c[0] == "sso" && c[1] == "callback" && c[2] == "":
+		login.HandleSSOCallback(r)
 	case c[0] == "subscribe-calendar" && c[1] == "":
 		static.SubscribeCalendarPage(r)
+	case c[0] == "survey" && c[1] != "" && c[2] == "":
+		classes.HandleSurvey(r, c[1])
 	case c[0] == "texts" && c[1] == "":
 		textlist.Get(r)
 	case c[0] == "texts" && c[1] == "NEW" && c[2] == "":
@@ -0,0 +1,46 @@
+// Package activation defines the Activation type, which describes an
+// activation of SERV volunteers by Sunnyvale OES, whether for an exercise or a
+// real incident.  Events are linked to an Activation by carrying its number in
+// their activation field.
+package activation
+
+// ID uniquely identifies an activation.
+type ID int
+
+// Fields is a bitmask of flags identifying specified fields of the Activation
+// structure.
+type Fields uint64
+
+// Values for Fields:
+const (
+	FID Fields = 1 << iota
+	FNumber
+	FType
+	FAgency
+	FCommander
+	FNotes
+)
+
+// Activation describes an activation of SERV volunteers.
+type Activation struct {
+	// NOTE: documentation of the fields is on the getter functions in
+	// getters.go.
+
+	fields    Fields // which fields of the structure are populated
+	id        ID
+	number    string
+	atype     Type
+	agency    string
+	commander string
+	notes     string
+}
+
+// Clone creates a clone of the activation.
+func (a *Activation) Clone() (c *Activation) {
+	if a == nil {
+		return nil
+	}
+	c = new(Activation)
+	*c = *a
+	return c
+}
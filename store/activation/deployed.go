@@ -0,0 +1,53 @@
+package activation
+
+import (
+	"strings"
+
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/task"
+	"sunnyvaleserv.org/portal/store/taskperson"
+)
+
+// Deployments fetches each of the task/person relationships (attendance,
+// credit, and/or volunteer hours) for tasks of events linked to the receiver
+// Activation.  They are returned in order by event, task, and person name.
+func (a *Activation) Deployments(
+	storer phys.Storer, eventFields event.Fields, taskFields task.Fields, personFields person.Fields,
+	fn func(e *event.Event, t *task.Task, p *person.Person, minutes uint, flags taskperson.Flag),
+) {
+	var sb strings.Builder
+
+	sb.WriteString("SELECT tp.minutes, tp.flags")
+	if eventFields != 0 {
+		sb.WriteString(", ")
+		event.ColumnList(&sb, eventFields)
+	}
+	if taskFields != 0 {
+		sb.WriteString(", ")
+		task.ColumnList(&sb, taskFields)
+	}
+	if personFields != 0 {
+		sb.WriteString(", ")
+		person.ColumnList(&sb, personFields)
+	}
+	sb.WriteString(" FROM task_person tp, task t, event e, person p WHERE tp.task=t.id AND t.event=e.id AND tp.person=p.id AND e.activation=? ORDER BY e.start, e.id, t.sort, p.sort_name")
+	phys.SQL(storer, sb.String(), func(stmt *phys.Stmt) {
+		var e event.Event
+		var t task.Task
+		var p person.Person
+		var minutes uint
+		var flags taskperson.Flag
+
+		stmt.BindText(a.Number())
+		for stmt.Step() {
+			minutes = uint(stmt.ColumnInt())
+			flags = taskperson.Flag(stmt.ColumnHexInt())
+			e.Scan(stmt, eventFields)
+			t.Scan(stmt, taskFields)
+			p.Scan(stmt, personFields)
+			fn(&e, &t, &p, minutes, flags)
+		}
+	})
+}
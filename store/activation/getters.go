@@ -0,0 +1,59 @@
+package activation
+
+// Fields returns the set of fields that have been retrieved for this
+// activation.
+func (a *Activation) Fields() Fields {
+	return a.fields
+}
+
+// ID is the unique identifier of the Activation.
+func (a *Activation) ID() ID {
+	if a == nil {
+		return 0
+	}
+	if a.fields&FID == 0 {
+		panic("Activation.ID called without having fetched FID")
+	}
+	return a.id
+}
+
+// Number is the Sunnyvale OES activation number.  Events belonging to the
+// Activation have this number in their activation field.
+func (a *Activation) Number() string {
+	if a.fields&FNumber == 0 {
+		panic("Activation.Number called without having fetched FNumber")
+	}
+	return a.number
+}
+
+// Type is the type of the Activation (exercise or real).
+func (a *Activation) Type() Type {
+	if a.fields&FType == 0 {
+		panic("Activation.Type called without having fetched FType")
+	}
+	return a.atype
+}
+
+// Agency is the name of the agency that requested the Activation.
+func (a *Activation) Agency() string {
+	if a.fields&FAgency == 0 {
+		panic("Activation.Agency called without having fetched FAgency")
+	}
+	return a.agency
+}
+
+// Commander is the name of the incident commander for the Activation.
+func (a *Activation) Commander() string {
+	if a.fields&FCommander == 0 {
+		panic("Activation.Commander called without having fetched FCommander")
+	}
+	return a.commander
+}
+
+// Notes is the after-action notes for the Activation.
+func (a *Activation) Notes() string {
+	if a.fields&FNotes == 0 {
+		panic("Activation.Notes called without having fetched FNotes")
+	}
+	return a.notes
+}
@@ -0,0 +1,92 @@
+package activation
+
+import (
+	"slices"
+	"strings"
+
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/folder"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+var eventsSQLCache map[event.Fields]string
+
+// Events fetches each of the events linked to the receiver Activation (i.e.,
+// carrying its activation number), in chronological order.
+func (a *Activation) Events(storer phys.Storer, fields event.Fields, fn func(*event.Event)) {
+	if eventsSQLCache == nil {
+		eventsSQLCache = make(map[event.Fields]string)
+	}
+	if _, ok := eventsSQLCache[fields]; !ok {
+		var sb strings.Builder
+		sb.WriteString("SELECT ")
+		event.ColumnList(&sb, fields)
+		sb.WriteString(" FROM event e WHERE e.activation=? ORDER BY e.start, e.end, e.id")
+		eventsSQLCache[fields] = sb.String()
+	}
+	phys.SQL(storer, eventsSQLCache[fields], func(stmt *phys.Stmt) {
+		var e event.Event
+
+		stmt.BindText(a.Number())
+		for stmt.Step() {
+			e.Scan(stmt, fields)
+			fn(&e)
+		}
+	})
+}
+
+var foldersSQLCache map[folder.Fields]string
+
+// Folders fetches each of the folders attached to the receiver Activation, in
+// order by name.
+func (a *Activation) Folders(storer phys.Storer, fields folder.Fields, fn func(*folder.Folder)) {
+	if foldersSQLCache == nil {
+		foldersSQLCache = make(map[folder.Fields]string)
+	}
+	if _, ok := foldersSQLCache[fields]; !ok {
+		var sb strings.Builder
+		sb.WriteString("SELECT ")
+		folder.ColumnList(&sb, fields)
+		sb.WriteString(" FROM folder f, activation_folder af WHERE af.activation=? AND af.folder=f.id ORDER BY f.name")
+		foldersSQLCache[fields] = sb.String()
+	}
+	phys.SQL(storer, foldersSQLCache[fields], func(stmt *phys.Stmt) {
+		var f folder.Folder
+
+		stmt.BindInt(int(a.ID()))
+		for stmt.Step() {
+			f.Scan(stmt, fields)
+			fn(&f)
+		}
+	})
+}
+
+// SetFolders sets the list of folders attached to the receiver Activation.
+// The folders must have FID and FName.
+func (a *Activation) SetFolders(storer phys.Storer, folders []*folder.Folder) {
+	var existing []folder.ID
+
+	a.Folders(storer, folder.FID, func(f *folder.Folder) {
+		existing = append(existing, f.ID())
+	})
+	for _, f := range folders {
+		if slices.Contains(existing, f.ID()) {
+			existing = slices.DeleteFunc(existing, func(id folder.ID) bool { return id == f.ID() })
+			continue
+		}
+		phys.SQL(storer, `INSERT INTO activation_folder (activation, folder) VALUES (?,?)`, func(stmt *phys.Stmt) {
+			stmt.BindInt(int(a.ID()))
+			stmt.BindInt(int(f.ID()))
+			stmt.Step()
+		})
+		phys.Audit(storer, "Activation %s [%d]:: ADD folder %q [%d]", a.Number(), a.ID(), f.Name(), f.ID())
+	}
+	for _, fid := range existing {
+		phys.SQL(storer, `DELETE FROM activation_folder WHERE activation=? AND folder=?`, func(stmt *phys.Stmt) {
+			stmt.BindInt(int(a.ID()))
+			stmt.BindInt(int(fid))
+			stmt.Step()
+		})
+		phys.Audit(storer, "Activation %s [%d]:: DELETE folder %d", a.Number(), a.ID(), fid)
+	}
+}
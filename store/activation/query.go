@@ -0,0 +1,84 @@
+package activation
+
+import (
+	"strings"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+var withIDSQLCache map[Fields]string
+
+// WithID returns the activation with the specified ID, or nil if it does not
+// exist.
+func WithID(storer phys.Storer, id ID, fields Fields) (a *Activation) {
+	if withIDSQLCache == nil {
+		withIDSQLCache = make(map[Fields]string)
+	}
+	if _, ok := withIDSQLCache[fields]; !ok {
+		var sb strings.Builder
+		sb.WriteString("SELECT ")
+		ColumnList(&sb, fields)
+		sb.WriteString(" FROM activation a WHERE a.id=?")
+		withIDSQLCache[fields] = sb.String()
+	}
+	phys.SQL(storer, withIDSQLCache[fields], func(stmt *phys.Stmt) {
+		stmt.BindInt(int(id))
+		if stmt.Step() {
+			a = new(Activation)
+			a.Scan(stmt, fields)
+			a.id = id
+			a.fields |= FID
+		}
+	})
+	return a
+}
+
+var withNumberSQLCache map[Fields]string
+
+// WithNumber returns the activation with the specified number, or nil if it
+// does not exist.
+func WithNumber(storer phys.Storer, number string, fields Fields) (a *Activation) {
+	if withNumberSQLCache == nil {
+		withNumberSQLCache = make(map[Fields]string)
+	}
+	if _, ok := withNumberSQLCache[fields]; !ok {
+		var sb strings.Builder
+		sb.WriteString("SELECT ")
+		ColumnList(&sb, fields)
+		sb.WriteString(" FROM activation a WHERE a.number=?")
+		withNumberSQLCache[fields] = sb.String()
+	}
+	phys.SQL(storer, withNumberSQLCache[fields], func(stmt *phys.Stmt) {
+		stmt.BindText(number)
+		if stmt.Step() {
+			a = new(Activation)
+			a.Scan(stmt, fields)
+			a.number = number
+			a.fields |= FNumber
+		}
+	})
+	return a
+}
+
+var allSQLCache map[Fields]string
+
+// All reads each activation from the database, most recent number first.
+func All(storer phys.Storer, fields Fields, fn func(*Activation)) {
+	if allSQLCache == nil {
+		allSQLCache = make(map[Fields]string)
+	}
+	if _, ok := allSQLCache[fields]; !ok {
+		var sb strings.Builder
+		sb.WriteString("SELECT ")
+		ColumnList(&sb, fields)
+		sb.WriteString(" FROM activation a ORDER BY a.number DESC")
+		allSQLCache[fields] = sb.String()
+	}
+	phys.SQL(storer, allSQLCache[fields], func(stmt *phys.Stmt) {
+		var a Activation
+		for stmt.Step() {
+			a.Scan(stmt, fields)
+			fn(&a)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package activation
+
+import (
+	"strings"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+// ColumnList generates a comma-separated list of column names for the specified
+// activation fields.  It is used in constructing SQL SELECT statements.
+func ColumnList(sb *strings.Builder, fields Fields) {
+	sep := phys.NewSeparator(", ")
+	if fields&FID != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("a.id")
+	}
+	if fields&FNumber != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("a.number")
+	}
+	if fields&FType != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("a.type")
+	}
+	if fields&FAgency != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("a.agency")
+	}
+	if fields&FCommander != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("a.commander")
+	}
+	if fields&FNotes != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("a.notes")
+	}
+}
+
+// Scan reads columns corresponding to the specified fields from the specified
+// statement into the receiver.
+func (a *Activation) Scan(stmt *phys.Stmt, fields Fields) {
+	if fields&FID != 0 {
+		a.id = ID(stmt.ColumnInt())
+	}
+	if fields&FNumber != 0 {
+		a.number = stmt.ColumnText()
+	}
+	if fields&FType != 0 {
+		a.atype = Type(stmt.ColumnInt())
+	}
+	if fields&FAgency != 0 {
+		a.agency = stmt.ColumnText()
+	}
+	if fields&FCommander != 0 {
+		a.commander = stmt.ColumnText()
+	}
+	if fields&FNotes != 0 {
+		a.notes = stmt.ColumnText()
+	}
+	a.fields |= fields
+}
@@ -0,0 +1,84 @@
+package activation
+
+import (
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+// StatusEntryID uniquely identifies an entry in an activation status timeline.
+type StatusEntryID int
+
+// A StatusEntry is a single entry in the status timeline of an Activation.
+type StatusEntry struct {
+	// ID is the unique identifier of the entry.
+	ID StatusEntryID
+	// Timestamp is the time at which the status took effect, in
+	// YYYY-MM-DDTHH:MM format (local time).
+	Timestamp string
+	// Status is the status that took effect.
+	Status Status
+	// Note is an optional explanatory note.
+	Note string
+}
+
+const timelineSQL = `SELECT id, timestamp, status, note FROM activation_status WHERE activation=? ORDER BY timestamp, id`
+
+// Timeline returns the status timeline of the receiver Activation, in
+// chronological order.
+func (a *Activation) Timeline(storer phys.Storer) (entries []*StatusEntry) {
+	phys.SQL(storer, timelineSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(a.ID()))
+		for stmt.Step() {
+			var se StatusEntry
+			se.ID = StatusEntryID(stmt.ColumnInt())
+			se.Timestamp = stmt.ColumnText()
+			se.Status = Status(stmt.ColumnInt())
+			se.Note = stmt.ColumnText()
+			entries = append(entries, &se)
+		}
+	})
+	return entries
+}
+
+const currentStatusSQL = `SELECT status FROM activation_status WHERE activation=? ORDER BY timestamp DESC, id DESC LIMIT 1`
+
+// CurrentStatus returns the most recent status of the receiver Activation, or
+// zero if it has no status timeline.
+func (a *Activation) CurrentStatus(storer phys.Storer) (status Status) {
+	phys.SQL(storer, currentStatusSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(a.ID()))
+		if stmt.Step() {
+			status = Status(stmt.ColumnInt())
+		}
+	})
+	return status
+}
+
+const addStatusSQL = `INSERT INTO activation_status (activation, timestamp, status, note) VALUES (?,?,?,?)`
+
+// AddStatus adds an entry to the status timeline of the receiver Activation.
+// The ID of the supplied entry is filled in.
+func (a *Activation) AddStatus(storer phys.Storer, se *StatusEntry) {
+	phys.SQL(storer, addStatusSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(a.ID()))
+		stmt.BindText(se.Timestamp)
+		stmt.BindInt(int(se.Status))
+		stmt.BindNullText(se.Note)
+		stmt.Step()
+	})
+	se.ID = StatusEntryID(phys.LastInsertRowID(storer))
+	phys.Audit(storer, "Activation %s [%d]:: ADD status %d:: %s %s %q",
+		a.Number(), a.ID(), se.ID, se.Timestamp, se.Status, se.Note)
+}
+
+// RemoveStatus removes an entry from the status timeline of the receiver
+// Activation.
+func (a *Activation) RemoveStatus(storer phys.Storer, id StatusEntryID) {
+	phys.SQL(storer, `DELETE FROM activation_status WHERE activation=? AND id=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(a.ID()))
+		stmt.BindInt(int(id))
+		stmt.Step()
+	})
+	if phys.RowsAffected(storer) != 0 {
+		phys.Audit(storer, "Activation %s [%d]:: DELETE status %d", a.Number(), a.ID(), id)
+	}
+}
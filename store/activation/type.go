@@ -0,0 +1,74 @@
+package activation
+
+// A Type is a type of activation.
+type Type uint8
+
+// Values for Type:
+const (
+	_ Type = iota
+	// Exercise is an activation for a drill or exercise.
+	Exercise
+	// Real is an activation for a real incident.
+	Real
+)
+
+// String returns the name of the specified Type.
+func (atype Type) String() string {
+	switch atype {
+	case Exercise:
+		return "Exercise"
+	case Real:
+		return "Real"
+	default:
+		return ""
+	}
+}
+
+// Int returns the specified Type as an integer.
+func (atype Type) Int() int { return int(atype) }
+
+// AllTypes is the list of all activation types.
+var AllTypes = []Type{Exercise, Real}
+
+// A Status is a status of an activation, as recorded in its status timeline.
+type Status uint8
+
+// Values for Status:
+const (
+	_ Status = iota
+	// Requested indicates that the requesting agency has asked for SERV
+	// volunteers.
+	Requested
+	// Activated indicates that SERV volunteers have been activated.
+	Activated
+	// Deployed indicates that SERV volunteers are deployed.
+	Deployed
+	// Demobilized indicates that SERV volunteers have been released.
+	Demobilized
+	// Closed indicates that the activation paperwork is complete.
+	Closed
+)
+
+// String returns the name of the specified Status.
+func (s Status) String() string {
+	switch s {
+	case Requested:
+		return "Requested"
+	case Activated:
+		return "Activated"
+	case Deployed:
+		return "Deployed"
+	case Demobilized:
+		return "Demobilized"
+	case Closed:
+		return "Closed"
+	default:
+		return ""
+	}
+}
+
+// Int returns the specified Status as an integer.
+func (s Status) Int() int { return int(s) }
+
+// AllStatuses is the list of all activation statuses, in their usual order.
+var AllStatuses = []Status{Requested, Activated, Deployed, Demobilized, Closed}
@@ -0,0 +1,160 @@
+package activation
+
+import (
+	"fmt"
+
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+// UpdaterFields are the fields that must be fetched prior to creating an
+// Updater.
+const UpdaterFields = FID | FNumber | FType | FAgency | FCommander | FNotes
+
+// Updater is a structure that can be filled with data for a new or changed
+// activation, and then later applied.  For creating new activations, it can
+// simply be instantiated with new().  For updating existing activations,
+// either *every* field in it must be set, or it should be instantiated with the
+// Updater method of the activation being changed.
+type Updater struct {
+	ID        ID
+	Number    string
+	Type      Type
+	Agency    string
+	Commander string
+	Notes     string
+}
+
+// Updater returns a new Updater for the specified activation, with its data
+// matching the current data for the activation.  The activation must have
+// fetched UpdaterFields.
+func (a *Activation) Updater() *Updater {
+	if a.fields&UpdaterFields != UpdaterFields {
+		panic("Activation.Updater called without fetching UpdaterFields")
+	}
+	return &Updater{
+		ID:        a.id,
+		Number:    a.number,
+		Type:      a.atype,
+		Agency:    a.agency,
+		Commander: a.commander,
+		Notes:     a.notes,
+	}
+}
+
+const createSQL = `INSERT INTO activation (id, number, type, agency, commander, notes) VALUES (?,?,?,?,?,?)`
+
+// Create creates a new activation, with the data in the Updater.
+func Create(storer phys.Storer, u *Updater) (a *Activation) {
+	a = new(Activation)
+	a.fields = UpdaterFields
+	phys.SQL(storer, createSQL, func(stmt *phys.Stmt) {
+		stmt.BindNullInt(int(u.ID))
+		bindUpdater(stmt, u)
+		stmt.Step()
+		if u.ID != 0 {
+			a.id = u.ID
+		} else {
+			a.id = ID(phys.LastInsertRowID(storer))
+		}
+	})
+	a.auditAndUpdate(storer, u, true)
+	return a
+}
+
+const updateSQL = `UPDATE activation SET number=?, type=?, agency=?, commander=?, notes=? WHERE id=?`
+
+// Update updates the existing activation, with the data in the Updater.  If
+// the activation number changes, the events carrying the old number are
+// changed to carry the new one, so that they remain linked to the activation.
+func (a *Activation) Update(storer phys.Storer, u *Updater) {
+	if a.fields&UpdaterFields != UpdaterFields {
+		panic("Activation.Update called without fetching UpdaterFields")
+	}
+	phys.SQL(storer, updateSQL, func(stmt *phys.Stmt) {
+		bindUpdater(stmt, u)
+		stmt.BindInt(int(a.id))
+		stmt.Step()
+	})
+	if u.Number != a.number {
+		a.relinkEvents(storer, u.Number)
+	}
+	a.auditAndUpdate(storer, u, false)
+}
+
+// relinkEvents changes the activation number on each of the events linked to
+// the receiver Activation to the specified number (which may be empty, to
+// unlink them).  Each event is changed through its own Updater, so that the
+// change is audited.
+func (a *Activation) relinkEvents(storer phys.Storer, number string) {
+	var events []*event.Event
+
+	a.Events(storer, event.UpdaterFields, func(e *event.Event) {
+		clone := *e
+		events = append(events, &clone)
+	})
+	for _, e := range events {
+		ue := e.Updater(storer, nil)
+		ue.Activation = number
+		e.Update(storer, ue)
+	}
+}
+
+func bindUpdater(stmt *phys.Stmt, u *Updater) {
+	stmt.BindText(u.Number)
+	stmt.BindInt(int(u.Type))
+	stmt.BindNullText(u.Agency)
+	stmt.BindNullText(u.Commander)
+	stmt.BindNullText(u.Notes)
+}
+
+func (a *Activation) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
+	context := fmt.Sprintf("Activation %s [%d]", u.Number, a.id)
+	if create {
+		context = "ADD " + context
+	}
+	if u.Number != a.number {
+		phys.Audit(storer, "%s:: number = %q", context, u.Number)
+		a.number = u.Number
+	}
+	if u.Type != a.atype {
+		phys.Audit(storer, "%s:: type = %s", context, u.Type)
+		a.atype = u.Type
+	}
+	if u.Agency != a.agency {
+		phys.Audit(storer, "%s:: agency = %q", context, u.Agency)
+		a.agency = u.Agency
+	}
+	if u.Commander != a.commander {
+		phys.Audit(storer, "%s:: commander = %q", context, u.Commander)
+		a.commander = u.Commander
+	}
+	if u.Notes != a.notes {
+		phys.Audit(storer, "%s:: notes = %q", context, u.Notes)
+		a.notes = u.Notes
+	}
+}
+
+const duplicateNumberSQL = `SELECT 1 FROM activation WHERE id!=? AND number=?`
+
+// DuplicateNumber returns whether the number specified in the Updater would be
+// a duplicate if applied.
+func (u *Updater) DuplicateNumber(storer phys.Storer) (found bool) {
+	phys.SQL(storer, duplicateNumberSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(u.ID))
+		stmt.BindText(u.Number)
+		found = stmt.Step()
+	})
+	return found
+}
+
+// Delete deletes the receiver activation.  Events carrying its number are
+// changed to carry no activation number.
+func (a *Activation) Delete(storer phys.Storer) {
+	a.relinkEvents(storer, "")
+	phys.SQL(storer, `DELETE FROM activation WHERE id=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(a.ID()))
+		stmt.Step()
+	})
+	phys.Audit(storer, "DELETE Activation %s [%d]", a.Number(), a.ID())
+}
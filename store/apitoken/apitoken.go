@@ -0,0 +1,158 @@
+// Package apitoken stores the personal API tokens with which people
+// authenticate scripts that use the JSON API.  Only a hash of each token is
+// stored; the token itself is shown to its owner once, when it is created.
+package apitoken
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+const timestampFormat = "2006-01-02T15:04:05"
+
+// ID is the identifier of an API token.
+type ID int
+
+// Token is a personal API token.
+type Token struct {
+	// ID is the identifier of the token.
+	ID ID
+	// Person is the ID of the person to whom the token belongs, and as
+	// whom it acts.
+	Person person.ID
+	// Name is the description given to the token by its owner.
+	Name string
+	// Hash is the SHA-256 hash of the token, hex-encoded.
+	Hash string
+	// Scopes is the list of scopes the token is allowed to use.
+	Scopes []Scope
+	// Created is the time at which the token was created.
+	Created time.Time
+	// LastUsed is the time at which the token was last used, or zero if it
+	// never has been.
+	LastUsed time.Time
+}
+
+// HasScope returns whether the token is allowed to use the specified scope.
+func (t *Token) HasScope(scope Scope) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+const columns = `id, person, name, hash, scopes, created, last_used`
+
+// scan reads a token from the columns of a statement.
+func scan(stmt *phys.Stmt) (t *Token) {
+	t = new(Token)
+	t.ID = ID(stmt.ColumnInt())
+	t.Person = person.ID(stmt.ColumnInt())
+	t.Name = stmt.ColumnText()
+	t.Hash = stmt.ColumnText()
+	for _, s := range strings.Fields(stmt.ColumnText()) {
+		t.Scopes = append(t.Scopes, Scope(s))
+	}
+	t.Created, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+	t.LastUsed, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+	return t
+}
+
+const allForPersonSQL = `SELECT ` + columns + ` FROM api_token WHERE person=? ORDER BY created`
+
+// AllForPerson calls fn for each token belonging to the specified person, in
+// order of creation.
+func AllForPerson(storer phys.Storer, pid person.ID, fn func(*Token)) {
+	phys.SQL(storer, allForPersonSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(pid))
+		for stmt.Step() {
+			fn(scan(stmt))
+		}
+	})
+}
+
+// CountForPerson returns the number of tokens belonging to the specified
+// person.
+func CountForPerson(storer phys.Storer, pid person.ID) (count int) {
+	AllForPerson(storer, pid, func(*Token) { count++ })
+	return count
+}
+
+const withIDSQL = `SELECT ` + columns + ` FROM api_token WHERE id=?`
+
+// WithID returns the token with the specified ID, or nil if there is none.
+func WithID(storer phys.Storer, id ID) (t *Token) {
+	phys.SQL(storer, withIDSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(id))
+		if stmt.Step() {
+			t = scan(stmt)
+		}
+	})
+	return t
+}
+
+const withHashSQL = `SELECT ` + columns + ` FROM api_token WHERE hash=?`
+
+// WithHash returns the token with the specified hash, or nil if there is
+// none.
+func WithHash(storer phys.Storer, hash string) (t *Token) {
+	phys.SQL(storer, withHashSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(hash)
+		if stmt.Step() {
+			t = scan(stmt)
+		}
+	})
+	return t
+}
+
+const addSQL = `INSERT INTO api_token (person, name, hash, scopes, created) VALUES (?,?,?,?,?)`
+
+// Add creates a new token for the specified person.  The ID and Person fields
+// of the token are set by this function.  The person must have FID and
+// FInformalName.
+func Add(storer phys.Storer, p *person.Person, t *Token) {
+	var scopes = make([]string, len(t.Scopes))
+
+	for i, s := range t.Scopes {
+		scopes[i] = string(s)
+	}
+	t.Person = p.ID()
+	phys.SQL(storer, addSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(t.Person))
+		stmt.BindText(t.Name)
+		stmt.BindText(t.Hash)
+		stmt.BindText(strings.Join(scopes, " "))
+		stmt.BindText(t.Created.In(time.Local).Format(timestampFormat))
+		stmt.Step()
+	})
+	t.ID = ID(phys.LastInsertRowID(storer))
+	phys.Audit(storer, "Person %q [%d]:: ADD API token %q [%d] scopes %s", p.InformalName(), p.ID(), t.Name, t.ID, strings.Join(scopes, " "))
+	phys.History(storer, "Person", int(p.ID()), "ADD API token", "", t.Name)
+}
+
+const recordUseSQL = `UPDATE api_token SET last_used=? WHERE id=?`
+
+// RecordUse records a use of the token.
+func (t *Token) RecordUse(storer phys.Storer, when time.Time) {
+	t.LastUsed = when
+	phys.SQL(storer, recordUseSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(when.In(time.Local).Format(timestampFormat))
+		stmt.BindInt(int(t.ID))
+		stmt.Step()
+	})
+	// Intentionally not audited due to noise.
+}
+
+const deleteSQL = `DELETE FROM api_token WHERE id=?`
+
+// Delete revokes the token.  p is the person to whom it belongs, and must have
+// FID and FInformalName.
+func (t *Token) Delete(storer phys.Storer, p *person.Person) {
+	phys.SQL(storer, deleteSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(t.ID))
+		stmt.Step()
+	})
+	phys.Audit(storer, "Person %q [%d]:: DELETE API token %q [%d]", p.InformalName(), p.ID(), t.Name, t.ID)
+	phys.History(storer, "Person", int(p.ID()), "DELETE API token", t.Name, "")
+}
@@ -0,0 +1,62 @@
+package apitoken
+
+// Scope identifies a class of API requests that a token is allowed to make.
+// A token can never do more than the person who created it is allowed to do;
+// scopes only narrow that further.
+type Scope string
+
+// Values for Scope:
+const (
+	// ScopeEventsRead allows reading events, tasks, shifts, and shift
+	// signups.
+	ScopeEventsRead Scope = "events:read"
+	// ScopeSignupsWrite allows signing up for shifts and cancelling
+	// signups.
+	ScopeSignupsWrite Scope = "signups:write"
+	// ScopePeopleRead allows reading people's names and contact
+	// information, to the extent the token owner can see them.
+	ScopePeopleRead Scope = "people:read"
+	// ScopeRolesRead allows reading roles and the names of the people who
+	// hold them.
+	ScopeRolesRead Scope = "roles:read"
+	// ScopeListsRead allows reading email and SMS lists and the names of
+	// their subscribers and senders.
+	ScopeListsRead Scope = "lists:read"
+	// ScopeHoursRead allows reading volunteer hours.
+	ScopeHoursRead Scope = "hours:read"
+	// ScopeHoursWrite allows recording volunteer hours.
+	ScopeHoursWrite Scope = "hours:write"
+)
+
+// AllScopes is the list of all known scopes, in the order they are presented.
+var AllScopes = []Scope{
+	ScopeEventsRead, ScopeSignupsWrite, ScopePeopleRead, ScopeRolesRead, ScopeListsRead, ScopeHoursRead, ScopeHoursWrite,
+}
+
+// Label returns the description of the scope shown to people choosing
+// scopes for a token.
+func (s Scope) Label() string {
+	switch s {
+	case ScopeEventsRead:
+		return "Read events, shifts, and signups"
+	case ScopeSignupsWrite:
+		return "Sign up for shifts"
+	case ScopePeopleRead:
+		return "Read people and contact information"
+	case ScopeRolesRead:
+		return "Read roles and their members' names"
+	case ScopeListsRead:
+		return "Read lists and their subscribers' names"
+	case ScopeHoursRead:
+		return "Read volunteer hours"
+	case ScopeHoursWrite:
+		return "Record volunteer hours"
+	default:
+		return ""
+	}
+}
+
+// Valid returns whether the scope is a known one.
+func (s Scope) Valid() bool {
+	return s.Label() != ""
+}
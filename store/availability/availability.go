@@ -0,0 +1,144 @@
+// Package availability handles the recorded availability of people: recurring
+// weekly windows during which they are usually available, and blackout dates on
+// which they are not.
+package availability
+
+import (
+	"time"
+)
+
+// Window is a recurring weekly window during which a person is usually
+// available.
+type Window struct {
+	// Weekday is the day of the week of the window.
+	Weekday time.Weekday
+	// Start is the starting time of the window, in HH:MM format.
+	Start string
+	// End is the ending time of the window, in HH:MM format.  It is always
+	// after Start; windows cannot span midnight.
+	End string
+}
+
+// Blackout is a range of dates on which a person is not available.
+type Blackout struct {
+	// Start is the first date of the blackout, in YYYY-MM-DD format.
+	Start string
+	// End is the last date of the blackout, in YYYY-MM-DD format.  It is
+	// the same as Start for a single-day blackout.
+	End string
+}
+
+// Availability is the recorded availability of a person.
+type Availability struct {
+	// Windows is the list of recurring availability windows, in order by
+	// weekday and start time.
+	Windows []Window
+	// Blackouts is the list of blackout date ranges, in chronological
+	// order.
+	Blackouts []Blackout
+}
+
+// Rank is a ranking of how available a person is for a particular time range.
+// Higher values indicate better availability.
+type Rank int
+
+// Values for Rank:
+const (
+	// BlackedOut indicates that the time range overlaps one of the person's
+	// blackout dates.
+	BlackedOut Rank = iota
+	// Outside indicates that the time range falls entirely outside of the
+	// person's availability windows.
+	Outside
+	// Unknown indicates that the person has not recorded any availability
+	// windows.
+	Unknown
+	// Partial indicates that the time range is partially covered by the
+	// person's availability windows.
+	Partial
+	// Available indicates that the time range is entirely covered by the
+	// person's availability windows.
+	Available
+)
+
+// String returns the English description of the Rank.
+func (r Rank) String() string {
+	switch r {
+	case BlackedOut:
+		return "Unavailable"
+	case Outside:
+		return "Not usually available"
+	case Unknown:
+		return "No availability recorded"
+	case Partial:
+		return "Partially available"
+	case Available:
+		return "Available"
+	default:
+		return ""
+	}
+}
+
+// Rank returns the availability ranking of the receiver for the specified time
+// range.
+func (a *Availability) Rank(start, end time.Time) Rank {
+	var total, covered time.Duration
+
+	for _, b := range a.Blackouts {
+		bstart, _ := time.ParseInLocation("2006-01-02", b.Start, start.Location())
+		bend, _ := time.ParseInLocation("2006-01-02", b.End, start.Location())
+		if start.Before(bend.AddDate(0, 0, 1)) && end.After(bstart) {
+			return BlackedOut
+		}
+	}
+	if len(a.Windows) == 0 {
+		return Unknown
+	}
+	// Walk through the time range a day at a time, adding up the portion
+	// of each day that is covered by a window.
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location()); day.Before(end); day = day.AddDate(0, 0, 1) {
+		dstart, dend := maxTime(start, day), minTime(end, day.AddDate(0, 0, 1))
+		if !dstart.Before(dend) {
+			continue
+		}
+		total += dend.Sub(dstart)
+		for _, w := range a.Windows {
+			if w.Weekday != day.Weekday() {
+				continue
+			}
+			wstart, wend := clockOn(day, w.Start), clockOn(day, w.End)
+			if s, e := maxTime(dstart, wstart), minTime(dend, wend); s.Before(e) {
+				covered += e.Sub(s)
+			}
+		}
+	}
+	switch {
+	case covered == 0:
+		return Outside
+	case covered >= total:
+		return Available
+	default:
+		return Partial
+	}
+}
+
+// clockOn returns the time on the specified day at the specified HH:MM clock
+// time.
+func clockOn(day time.Time, clock string) time.Time {
+	t, _ := time.Parse("15:04", clock)
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location())
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
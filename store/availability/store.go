@@ -0,0 +1,75 @@
+package availability
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+// ForPerson returns the recorded availability of the specified person.
+func ForPerson(storer phys.Storer, pid person.ID) (a *Availability) {
+	a = new(Availability)
+	phys.SQL(storer, `SELECT weekday, start, end FROM person_availability WHERE person=? ORDER BY weekday, start`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(pid))
+		for stmt.Step() {
+			a.Windows = append(a.Windows, Window{
+				Weekday: time.Weekday(stmt.ColumnInt()),
+				Start:   stmt.ColumnText(),
+				End:     stmt.ColumnText(),
+			})
+		}
+	})
+	phys.SQL(storer, `SELECT start, end FROM person_blackout WHERE person=? ORDER BY start`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(pid))
+		for stmt.Step() {
+			a.Blackouts = append(a.Blackouts, Blackout{
+				Start: stmt.ColumnText(),
+				End:   stmt.ColumnText(),
+			})
+		}
+	})
+	return a
+}
+
+// Set sets the recorded availability of the specified person.  The person must
+// have FID and FInformalName.
+func Set(storer phys.Storer, p *person.Person, a *Availability) {
+	var old = ForPerson(storer, p.ID())
+
+	if !slices.Equal(old.Windows, a.Windows) {
+		phys.SQL(storer, `DELETE FROM person_availability WHERE person=?`, func(stmt *phys.Stmt) {
+			stmt.BindInt(int(p.ID()))
+			stmt.Step()
+		})
+		for _, w := range a.Windows {
+			phys.SQL(storer, `INSERT INTO person_availability (person, weekday, start, end) VALUES (?,?,?,?)`, func(stmt *phys.Stmt) {
+				stmt.BindInt(int(p.ID()))
+				stmt.BindInt(int(w.Weekday))
+				stmt.BindText(w.Start)
+				stmt.BindText(w.End)
+				stmt.Step()
+			})
+		}
+		phys.Audit(storer, "Person %q [%d]:: availability = %v", p.InformalName(), p.ID(), a.Windows)
+		phys.History(storer, "Person", int(p.ID()), "availability", fmt.Sprint(old.Windows), fmt.Sprint(a.Windows))
+	}
+	if !slices.Equal(old.Blackouts, a.Blackouts) {
+		phys.SQL(storer, `DELETE FROM person_blackout WHERE person=?`, func(stmt *phys.Stmt) {
+			stmt.BindInt(int(p.ID()))
+			stmt.Step()
+		})
+		for _, b := range a.Blackouts {
+			phys.SQL(storer, `INSERT INTO person_blackout (person, start, end) VALUES (?,?,?)`, func(stmt *phys.Stmt) {
+				stmt.BindInt(int(p.ID()))
+				stmt.BindText(b.Start)
+				stmt.BindText(b.End)
+				stmt.Step()
+			})
+		}
+		phys.Audit(storer, "Person %q [%d]:: blackouts = %v", p.InformalName(), p.ID(), a.Blackouts)
+		phys.History(storer, "Person", int(p.ID()), "blackouts", fmt.Sprint(old.Blackouts), fmt.Sprint(a.Blackouts))
+	}
+}
@@ -0,0 +1,49 @@
+// Package certificate defines the Certificate type, which records a course
+// completion certificate issued to a person.
+package certificate
+
+import (
+	"fmt"
+
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+// ID uniquely identifies a certificate.  It is also the certificate number
+// printed on the certificate.
+type ID int
+
+// Certificate records a course completion certificate issued to a person.
+// The name, course, and completion date are recorded as they were printed on
+// the certificate, so that the certificate can still be verified after the
+// person or class records change.
+type Certificate struct {
+	// ID is the unique identifier of the Certificate.
+	ID ID
+	// Token is the random token that identifies the certificate in its
+	// public verification URL.
+	Token string
+	// Person is the person to whom the certificate was issued.
+	Person person.ID
+	// Class is the class whose completion the certificate records.  It is
+	// zero if the class has since been deleted.
+	Class class.ID
+	// Name is the name of the person as printed on the certificate.
+	Name string
+	// Course is the name of the course as printed on the certificate.
+	Course string
+	// Completed is the date the course was completed, in YYYY-MM-DD
+	// format.
+	Completed string
+	// Issued is the date the certificate was issued, in YYYY-MM-DD format.
+	Issued string
+}
+
+// Number returns the certificate number, formatted for printing.
+func (c *Certificate) Number() string { return fmt.Sprintf("%06d", c.ID) }
+
+func (c *Certificate) Clone() (clone *Certificate) {
+	clone = new(Certificate)
+	*clone = *c
+	return clone
+}
@@ -0,0 +1,54 @@
+package certificate
+
+import (
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+const withTokenSQL = `SELECT ` + ColumnList + ` FROM certificate ce WHERE ce.token=?`
+
+// WithToken returns the certificate with the specified verification token, or
+// nil if it does not exist.
+func WithToken(storer phys.Storer, token string) (c *Certificate) {
+	phys.SQL(storer, withTokenSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(token)
+		if stmt.Step() {
+			c = new(Certificate)
+			c.Scan(stmt)
+		}
+	})
+	return c
+}
+
+const allForClassSQL = `SELECT ` + ColumnList + ` FROM certificate ce WHERE ce.class=? ORDER BY ce.id`
+
+// AllForClass reads each certificate issued for the specified class, in the
+// order they were issued.
+func AllForClass(storer phys.Storer, cid class.ID, fn func(*Certificate)) {
+	phys.SQL(storer, allForClassSQL, func(stmt *phys.Stmt) {
+		var c Certificate
+
+		stmt.BindInt(int(cid))
+		for stmt.Step() {
+			c.Scan(stmt)
+			fn(&c)
+		}
+	})
+}
+
+const allForPersonSQL = `SELECT ` + ColumnList + ` FROM certificate ce WHERE ce.person=? ORDER BY ce.completed, ce.id`
+
+// AllForPerson reads each certificate issued to the specified person, in
+// order by completion date.
+func AllForPerson(storer phys.Storer, pid person.ID, fn func(*Certificate)) {
+	phys.SQL(storer, allForPersonSQL, func(stmt *phys.Stmt) {
+		var c Certificate
+
+		stmt.BindInt(int(pid))
+		for stmt.Step() {
+			c.Scan(stmt)
+			fn(&c)
+		}
+	})
+}
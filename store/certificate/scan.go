@@ -0,0 +1,23 @@
+package certificate
+
+import (
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+// ColumnList is a comma-separated list of column names for certificates.  It
+// is used in constructing SQL SELECT statements.
+const ColumnList = `ce.id, ce.token, ce.person, ce.class, ce.name, ce.course, ce.completed, ce.issued`
+
+// Scan reads columns from the specified statement into the receiver.
+func (c *Certificate) Scan(stmt *phys.Stmt) {
+	c.ID = ID(stmt.ColumnInt())
+	c.Token = stmt.ColumnText()
+	c.Person = person.ID(stmt.ColumnInt())
+	c.Class = class.ID(stmt.ColumnInt())
+	c.Name = stmt.ColumnText()
+	c.Course = stmt.ColumnText()
+	c.Completed = stmt.ColumnText()
+	c.Issued = stmt.ColumnText()
+}
@@ -0,0 +1,43 @@
+package certificate
+
+import (
+	"fmt"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+// Updater is a structure that can be filled with data for a new certificate,
+// and then later applied.  Certificates are never changed once issued, so
+// there is no Update method.
+type Updater Certificate
+
+const createSQL = `INSERT INTO certificate (id, token, person, class, name, course, completed, issued) VALUES (?,?,?,?,?,?,?,?)`
+
+// Create creates a new certificate, with the data in the Updater.
+func Create(storer phys.Storer, u *Updater) (c *Certificate) {
+	c = new(Certificate)
+	*c = Certificate(*u)
+	phys.SQL(storer, createSQL, func(stmt *phys.Stmt) {
+		stmt.BindNullInt(int(u.ID))
+		stmt.BindText(u.Token)
+		stmt.BindInt(int(u.Person))
+		stmt.BindNullInt(int(u.Class))
+		stmt.BindText(u.Name)
+		stmt.BindText(u.Course)
+		stmt.BindText(u.Completed)
+		stmt.BindText(u.Issued)
+		stmt.Step()
+		if u.ID == 0 {
+			c.ID = ID(phys.LastInsertRowID(storer))
+		}
+	})
+	context := fmt.Sprintf("ADD Certificate %s [%d]", c.Number(), c.ID)
+	phys.Audit(storer, "%s:: token = %q", context, c.Token)
+	phys.Audit(storer, "%s:: person = %d", context, c.Person)
+	phys.Audit(storer, "%s:: class = %d", context, c.Class)
+	phys.Audit(storer, "%s:: name = %q", context, c.Name)
+	phys.Audit(storer, "%s:: course = %q", context, c.Course)
+	phys.Audit(storer, "%s:: completed = %s", context, c.Completed)
+	phys.Audit(storer, "%s:: issued = %s", context, c.Issued)
+	return c
+}
@@ -2,11 +2,7 @@
 // that we offer.
 package class
 
-import (
-	"slices"
-
-	"sunnyvaleserv.org/portal/store/role"
-)
+import "sunnyvaleserv.org/portal/store/role"
 
 // ID uniquely identifies a class.
 type ID int
@@ -23,9 +19,9 @@ const (
 	FEnDesc
 	FEsDesc
 	FLimit
-	FReferrals
 	FRegURL
 	FRole
+	FGradRole
 )
 
 // Class describes an instance of a class that we offer.
@@ -33,22 +29,21 @@ type Class struct {
 	// NOTE: documentation of the fields is on the getter functions in
 	// getters.go.
 
-	fields    Fields // which fields of the structure are populated
-	id        ID
-	ctype     Type
-	start     string
-	enDesc    string
-	esDesc    string
-	limit     uint
-	referrals []uint
-	regURL    string
-	role      role.ID
+	fields   Fields // which fields of the structure are populated
+	id       ID
+	ctype    Type
+	start    string
+	enDesc   string
+	esDesc   string
+	limit    uint
+	regURL   string
+	role     role.ID
+	gradRole role.ID
 }
 
 // Clone creates a clone of the class.
 func (c *Class) Clone() (clone *Class) {
 	clone = new(Class)
 	*clone = *c
-	clone.referrals = slices.Clone(c.referrals)
 	return clone
 }
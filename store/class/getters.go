@@ -62,15 +62,6 @@ func (c *Class) Limit() uint {
 	return c.limit
 }
 
-// Referrals is a slice, indexed by Referral, of the number of people who
-// indicated they learned of the class through that referral method.
-func (c *Class) Referrals() []uint {
-	if c.fields&FLimit == 0 {
-		panic("Class.Referrals called without having fetched FReferrals")
-	}
-	return c.referrals
-}
-
 // RegURL is the registration URL for the class, if registrations are handled on
 // a different website.  It is empty when this site handles registrations.
 func (c *Class) RegURL() string {
@@ -88,3 +79,12 @@ func (c *Class) Role() role.ID {
 	}
 	return c.role
 }
+
+// GradRole is the ID of the role granted to students who graduate from the
+// class, i.e., who are credited for attending every session, if any.
+func (c *Class) GradRole() role.ID {
+	if c.fields&FGradRole == 0 {
+		panic("Class.GradRole called without having fetched FGradRole")
+	}
+	return c.gradRole
+}
@@ -0,0 +1,33 @@
+package class
+
+import (
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/referral"
+)
+
+// LegacyReferrals returns the referral counts recorded for the class before
+// referral sources were recorded with each registration, keyed by referral
+// source ID.  It returns nil for classes created since then.
+//
+// The counts are kept in the class's referrals column, eight bits per source,
+// with the count for source ID n in bits 8n through 8n+7.  Only the four
+// original sources (IDs 1 through 4, which kept their IDs when they became
+// referral_source rows) appear there.
+func LegacyReferrals(storer phys.Storer, cid ID) (counts map[referral.ID]int) {
+	phys.SQL(storer, `SELECT referrals FROM class WHERE id=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(cid))
+		if !stmt.Step() {
+			return
+		}
+		refmask := uint64(stmt.ColumnInt())
+		for id := referral.ID(1); id <= 4; id++ {
+			if count := int((refmask >> (id * 8)) & 0xFF); count != 0 {
+				if counts == nil {
+					counts = make(map[referral.ID]int)
+				}
+				counts[id] = count
+			}
+		}
+	})
+	return counts
+}
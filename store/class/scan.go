@@ -35,10 +35,6 @@ func ColumnList(sb *strings.Builder, fields Fields) {
 		sb.WriteString(sep())
 		sb.WriteString("c.elimit")
 	}
-	if fields&FReferrals != 0 {
-		sb.WriteString(sep())
-		sb.WriteString("c.referrals")
-	}
 	if fields&FRegURL != 0 {
 		sb.WriteString(sep())
 		sb.WriteString("c.regurl")
@@ -47,6 +43,10 @@ func ColumnList(sb *strings.Builder, fields Fields) {
 		sb.WriteString(sep())
 		sb.WriteString("c.role")
 	}
+	if fields&FGradRole != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("c.grad_role")
+	}
 }
 
 // Scan reads columns corresponding to the specified fields from the specified
@@ -70,18 +70,14 @@ func (c *Class) Scan(stmt *phys.Stmt, fields Fields) {
 	if fields&FLimit != 0 {
 		c.limit = uint(stmt.ColumnInt())
 	}
-	if fields&FReferrals != 0 {
-		refmask := uint64(stmt.ColumnInt())
-		c.referrals = make([]uint, len(AllReferrals)+1)
-		for _, ref := range AllReferrals {
-			c.referrals[ref] = uint((refmask >> (ref * 8)) & 0xFF)
-		}
-	}
 	if fields&FRegURL != 0 {
 		c.regURL = stmt.ColumnText()
 	}
 	if fields&FRole != 0 {
 		c.role = role.ID(stmt.ColumnInt())
 	}
+	if fields&FGradRole != 0 {
+		c.gradRole = role.ID(stmt.ColumnInt())
+	}
 	c.fields |= fields
 }
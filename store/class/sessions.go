@@ -0,0 +1,143 @@
+package class
+
+import (
+	"strings"
+
+	"sunnyvaleserv.org/portal/store/event"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/task"
+	"sunnyvaleserv.org/portal/store/taskperson"
+)
+
+var sessionsSQLCache map[event.Fields]string
+
+// Sessions fetches each of the session events of the receiver Class, in
+// chronological order.
+func (c *Class) Sessions(storer phys.Storer, fields event.Fields, fn func(*event.Event)) {
+	if sessionsSQLCache == nil {
+		sessionsSQLCache = make(map[event.Fields]string)
+	}
+	if _, ok := sessionsSQLCache[fields]; !ok {
+		var sb strings.Builder
+		sb.WriteString("SELECT ")
+		event.ColumnList(&sb, fields)
+		sb.WriteString(" FROM event e, class_session cs WHERE cs.class=? AND cs.event=e.id ORDER BY e.start, e.end, e.id")
+		sessionsSQLCache[fields] = sb.String()
+	}
+	phys.SQL(storer, sessionsSQLCache[fields], func(stmt *phys.Stmt) {
+		var e event.Event
+
+		stmt.BindInt(int(c.ID()))
+		for stmt.Step() {
+			e.Scan(stmt, fields)
+			fn(&e)
+		}
+	})
+}
+
+var forSessionSQLCache map[Fields]string
+
+// ForSession returns the class of which the specified event is a session, or
+// nil if it is not a class session.
+func ForSession(storer phys.Storer, eid event.ID, fields Fields) (c *Class) {
+	if forSessionSQLCache == nil {
+		forSessionSQLCache = make(map[Fields]string)
+	}
+	if _, ok := forSessionSQLCache[fields]; !ok {
+		var sb strings.Builder
+		sb.WriteString("SELECT ")
+		ColumnList(&sb, fields)
+		sb.WriteString(" FROM class c, class_session cs WHERE cs.event=? AND cs.class=c.id")
+		forSessionSQLCache[fields] = sb.String()
+	}
+	phys.SQL(storer, forSessionSQLCache[fields], func(stmt *phys.Stmt) {
+		stmt.BindInt(int(eid))
+		if stmt.Step() {
+			c = new(Class)
+			c.Scan(stmt, fields)
+		}
+	})
+	return c
+}
+
+// AddSession adds the specified event as a session of the receiver Class.  The
+// Class must have FID, FType, and FStart; the event must have FID and FName.
+func (c *Class) AddSession(storer phys.Storer, e *event.Event) {
+	phys.SQL(storer, `INSERT INTO class_session (class, event) VALUES (?,?)`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(c.ID()))
+		stmt.BindInt(int(e.ID()))
+		stmt.Step()
+	})
+	phys.Audit(storer, "Class %s %s [%d]:: ADD session %q [%d]", c.Type(), c.Start(), c.ID(), e.Name(), e.ID())
+}
+
+var sessionTaskSQLCache map[task.Fields]string
+
+// SessionTask returns the task of the specified session at which the
+// attendance of the receiver Class's students is recorded:  the first task
+// open to the class's student role, or the first task of the session if the
+// class has no student role.  It returns nil if there is no such task.  The
+// Class must have FID and FRole.
+func (c *Class) SessionTask(storer phys.Storer, eid event.ID, fields task.Fields) (t *task.Task) {
+	if sessionTaskSQLCache == nil {
+		sessionTaskSQLCache = make(map[task.Fields]string)
+	}
+	if _, ok := sessionTaskSQLCache[fields]; !ok {
+		var sb strings.Builder
+		sb.WriteString("SELECT ")
+		task.ColumnList(&sb, fields)
+		sb.WriteString(" FROM class_session cs, task t WHERE cs.class=?1 AND cs.event=?2 AND t.event=cs.event AND (?3=0 OR EXISTS (SELECT 1 FROM task_role tr WHERE tr.task=t.id AND tr.role=?3)) ORDER BY t.sort LIMIT 1")
+		sessionTaskSQLCache[fields] = sb.String()
+	}
+	phys.SQL(storer, sessionTaskSQLCache[fields], func(stmt *phys.Stmt) {
+		stmt.BindInt(int(c.ID()))
+		stmt.BindInt(int(eid))
+		stmt.BindInt(int(c.Role()))
+		if stmt.Step() {
+			t = new(task.Task)
+			t.Scan(stmt, fields)
+		}
+	})
+	return t
+}
+
+// attendanceSQL fetches the attendance records for the sessions of a class.
+// Only tasks open to the class's student role are considered, unless the class
+// has no student role, in which case all tasks of the sessions are considered.
+const attendanceSQL = `SELECT tp.person, t.event, tp.flags FROM class_session cs, task t, task_person tp WHERE cs.class=?1 AND t.event=cs.event AND tp.task=t.id AND (?2=0 OR EXISTS (SELECT 1 FROM task_role tr WHERE tr.task=t.id AND tr.role=?2))`
+
+// Attendance returns the attendance of each person at each session of the
+// receiver Class.  The Class must have FID and FRole.
+func (c *Class) Attendance(storer phys.Storer) (attendance map[person.ID]map[event.ID]taskperson.Flag) {
+	attendance = make(map[person.ID]map[event.ID]taskperson.Flag)
+	phys.SQL(storer, attendanceSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(c.ID()))
+		stmt.BindInt(int(c.Role()))
+		for stmt.Step() {
+			pid := person.ID(stmt.ColumnInt())
+			eid := event.ID(stmt.ColumnInt())
+			flags := taskperson.Flag(stmt.ColumnHexInt())
+			if attendance[pid] == nil {
+				attendance[pid] = make(map[event.ID]taskperson.Flag)
+			}
+			attendance[pid][eid] |= flags
+		}
+	})
+	return attendance
+}
+
+// Completed returns whether a person with the specified attendance (as
+// returned for them by Attendance) has completed the class whose sessions are
+// listed.  Completion requires credit for every session.
+func Completed(sessions []event.ID, attendance map[event.ID]taskperson.Flag) bool {
+	if len(sessions) == 0 {
+		return false
+	}
+	for _, eid := range sessions {
+		if attendance[eid]&taskperson.Credited == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -2,7 +2,6 @@ package class
 
 import (
 	"fmt"
-	"slices"
 
 	"sunnyvaleserv.org/portal/store/internal/phys"
 	"sunnyvaleserv.org/portal/store/role"
@@ -10,7 +9,7 @@ import (
 
 // UpdaterFields are the fields that must be fetched prior to creating an
 // Updater.
-const UpdaterFields = FID | FType | FStart | FEnDesc | FEsDesc | FLimit | FReferrals | FRegURL | FRole
+const UpdaterFields = FID | FType | FStart | FEnDesc | FEsDesc | FLimit | FRegURL | FRole | FGradRole
 
 // Updater is a structure that can be filled with data for a new or changed
 // class, and then later applied.  For creating new classes, it can simply be
@@ -18,41 +17,44 @@ const UpdaterFields = FID | FType | FStart | FEnDesc | FEsDesc | FLimit | FRefer
 // in it must be set, or it should be instantiated with the Updater method of
 // the class being changed.
 type Updater struct {
-	ID        ID
-	Type      Type
-	Start     string
-	EnDesc    string
-	EsDesc    string
-	Limit     uint
-	Referrals []uint
-	RegURL    string
-	Role      *role.Role
+	ID       ID
+	Type     Type
+	Start    string
+	EnDesc   string
+	EsDesc   string
+	Limit    uint
+	RegURL   string
+	Role     *role.Role
+	GradRole *role.Role
 }
 
 // Updater returns a new Updater for the specified class, with its data matching
 // the current data for the class.  The class must have fetched UpdaterFields.
-// The role pointer *may* be provided to avoid a lookup.
-func (c *Class) Updater(storer phys.Storer, rl *role.Role) *Updater {
+// The role pointers *may* be provided to avoid lookups.
+func (c *Class) Updater(storer phys.Storer, rl, grl *role.Role) *Updater {
 	if c.fields&UpdaterFields != UpdaterFields {
 		panic("Class.Updater called without fetching UpdaterFields")
 	}
 	if rl == nil {
 		rl = role.WithID(storer, c.role, role.FID|role.FName)
 	}
+	if grl == nil {
+		grl = role.WithID(storer, c.gradRole, role.FID|role.FName)
+	}
 	return &Updater{
-		ID:        c.id,
-		Type:      c.ctype,
-		Start:     c.start,
-		EnDesc:    c.enDesc,
-		EsDesc:    c.esDesc,
-		Limit:     c.limit,
-		Referrals: slices.Clone(c.referrals),
-		RegURL:    c.regURL,
-		Role:      rl,
+		ID:       c.id,
+		Type:     c.ctype,
+		Start:    c.start,
+		EnDesc:   c.enDesc,
+		EsDesc:   c.esDesc,
+		Limit:    c.limit,
+		RegURL:   c.regURL,
+		Role:     rl,
+		GradRole: grl,
 	}
 }
 
-const createSQL = `INSERT INTO class (id, type, start, en_desc, es_desc, elimit, referrals, regurl, role) VALUES (?,?,?,?,?,?,?,?,?)`
+const createSQL = `INSERT INTO class (id, type, start, en_desc, es_desc, elimit, referrals, regurl, role, grad_role) VALUES (?1,?2,?3,?4,?5,?6,0,?7,?8,?9)`
 
 // Create creates a new class, with the data in the Updater.
 func Create(storer phys.Storer, u *Updater) (c *Class) {
@@ -72,7 +74,7 @@ func Create(storer phys.Storer, u *Updater) (c *Class) {
 	return c
 }
 
-const updateSQL = `UPDATE class SET type=?, start=?, en_desc=?, es_desc=?, elimit=?, referrals=?, regurl=?, role=? WHERE id=?`
+const updateSQL = `UPDATE class SET type=?, start=?, en_desc=?, es_desc=?, elimit=?, regurl=?, role=?, grad_role=? WHERE id=?`
 
 // Update updates the existing class, with the data in the Updater.
 func (c *Class) Update(storer phys.Storer, u *Updater) {
@@ -93,15 +95,9 @@ func bindUpdater(stmt *phys.Stmt, u *Updater) {
 	stmt.BindText(u.EnDesc)
 	stmt.BindText(u.EsDesc)
 	stmt.BindInt(int(u.Limit))
-	var refmask uint64
-	if u.Referrals != nil {
-		for _, ref := range AllReferrals {
-			refmask |= uint64(u.Referrals[ref]) << (ref * 8)
-		}
-	}
-	stmt.BindInt(int(refmask))
 	stmt.BindText(u.RegURL)
 	stmt.BindNullInt(int(u.Role.ID()))
+	stmt.BindNullInt(int(u.GradRole.ID()))
 }
 
 func (c *Class) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
@@ -129,22 +125,6 @@ func (c *Class) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
 		phys.Audit(storer, "%s:: limit = %d", context, u.Limit)
 		c.limit = u.Limit
 	}
-	for _, ref := range AllReferrals {
-		var ur, cr uint
-		if u.Referrals != nil {
-			ur = u.Referrals[ref]
-		}
-		if c.referrals != nil {
-			cr = c.referrals[ref]
-		}
-		if ur != cr {
-			phys.Audit(storer, "%s:: referrals[%s] = %d", context, ref, ur)
-			if c.referrals == nil {
-				c.referrals = make([]uint, len(AllReferrals)+1)
-			}
-			c.referrals[ref] = ur
-		}
-	}
 	if u.RegURL != c.regURL {
 		phys.Audit(storer, "%s:: regURL = %q", context, u.RegURL)
 	}
@@ -156,6 +136,14 @@ func (c *Class) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
 		}
 		c.role = u.Role.ID()
 	}
+	if u.GradRole.ID() != c.gradRole {
+		if u.GradRole != nil {
+			phys.Audit(storer, "%s:: gradRole = %s [%d]", context, u.GradRole.Name(), u.GradRole.ID())
+		} else {
+			phys.Audit(storer, "%s:: gradRole = nil", context)
+		}
+		c.gradRole = u.GradRole.ID()
+	}
 }
 
 const duplicateStartSQL = `SELECT 1 FROM class WHERE id!=? AND type=? AND start=?`
@@ -3,8 +3,11 @@
 package classreg
 
 import (
+	"time"
+
 	"sunnyvaleserv.org/portal/store/class"
 	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/referral"
 )
 
 // ID uniquely identifies a class registration.
@@ -25,6 +28,11 @@ const (
 	FEmail
 	FCellPhone
 	FWaitlist
+	FHoldUntil
+	FHoldToken
+	FReferral
+	FReferralOther
+	FCampaign
 )
 
 // ClassReg describes a registration for a class.
@@ -32,16 +40,21 @@ type ClassReg struct {
 	// NOTE: documentation of the fields is on the getter functions in
 	// getters.go.
 
-	fields       Fields // which fields of the structure are populated
-	id           ID
-	class        class.ID
-	person       person.ID
-	registeredBy person.ID
-	firstName    string
-	lastName     string
-	email        string
-	cellPhone    string
-	waitlist     bool
+	fields        Fields // which fields of the structure are populated
+	id            ID
+	class         class.ID
+	person        person.ID
+	registeredBy  person.ID
+	firstName     string
+	lastName      string
+	email         string
+	cellPhone     string
+	waitlist      bool
+	holdUntil     time.Time
+	holdToken     string
+	referral      referral.ID
+	referralOther string
+	campaign      string
 }
 
 // Clone creates a clone of the class registration.
@@ -1,8 +1,11 @@
 package classreg
 
 import (
+	"time"
+
 	"sunnyvaleserv.org/portal/store/class"
 	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/referral"
 )
 
 // Fields returns the set of fields that have been retrieved for this venue.
@@ -89,3 +92,50 @@ func (c *ClassReg) Waitlist() bool {
 	}
 	return c.waitlist
 }
+
+// HoldUntil is the time until which a seat in the class is being held for a
+// student promoted from the waitlist, pending their confirmation.  It is zero
+// if the registration is not being held.
+func (c *ClassReg) HoldUntil() time.Time {
+	if c.fields&FHoldUntil == 0 {
+		panic("ClassReg.HoldUntil called without having fetched FHoldUntil")
+	}
+	return c.holdUntil
+}
+
+// HoldToken is the token that a student promoted from the waitlist uses to
+// confirm their registration.  It is empty if the registration is not being
+// held.
+func (c *ClassReg) HoldToken() string {
+	if c.fields&FHoldToken == 0 {
+		panic("ClassReg.HoldToken called without having fetched FHoldToken")
+	}
+	return c.holdToken
+}
+
+// Referral is the source through which the registrant learned of the class, or
+// zero if they didn't say.
+func (c *ClassReg) Referral() referral.ID {
+	if c.fields&FReferral == 0 {
+		panic("ClassReg.Referral called without having fetched FReferral")
+	}
+	return c.referral
+}
+
+// ReferralOther is the registrant's description of how they learned of the
+// class, when they chose a referral source that asks for one.
+func (c *ClassReg) ReferralOther() string {
+	if c.fields&FReferralOther == 0 {
+		panic("ClassReg.ReferralOther called without having fetched FReferralOther")
+	}
+	return c.referralOther
+}
+
+// Campaign is the campaign code that was given in the URL of the class page
+// from which the registration was made, if any.
+func (c *ClassReg) Campaign() string {
+	if c.fields&FCampaign == 0 {
+		panic("ClassReg.Campaign called without having fetched FCampaign")
+	}
+	return c.campaign
+}
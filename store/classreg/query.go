@@ -5,6 +5,7 @@ import (
 
 	"sunnyvaleserv.org/portal/store/class"
 	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
 )
 
 // ClassHasSignups returns whether there are any registrations for the class
@@ -74,6 +75,31 @@ func WithID(storer phys.Storer, id ID, fields Fields) (cr *ClassReg) {
 	return cr
 }
 
+var withHoldTokenSQLCache map[Fields]string
+
+// WithHoldToken returns the class registration with the specified hold token,
+// or nil if there is none.
+func WithHoldToken(storer phys.Storer, token string, fields Fields) (cr *ClassReg) {
+	if withHoldTokenSQLCache == nil {
+		withHoldTokenSQLCache = make(map[Fields]string)
+	}
+	if _, ok := withHoldTokenSQLCache[fields]; !ok {
+		var sb strings.Builder
+		sb.WriteString("SELECT ")
+		ColumnList(&sb, fields)
+		sb.WriteString(" FROM classreg cr WHERE cr.hold_token=?")
+		withHoldTokenSQLCache[fields] = sb.String()
+	}
+	phys.SQL(storer, withHoldTokenSQLCache[fields], func(stmt *phys.Stmt) {
+		stmt.BindText(token)
+		if stmt.Step() {
+			cr = new(ClassReg)
+			cr.Scan(stmt, fields)
+		}
+	})
+	return cr
+}
+
 var allForClassSQLCache map[Fields]string
 
 // AllForClass reads the list of people registered for the specified class, in
@@ -99,3 +125,30 @@ func AllForClass(storer phys.Storer, cid class.ID, fields Fields, fn func(*Class
 		}
 	})
 }
+
+var allForPersonSQLCache map[Fields]string
+
+// AllForPerson reads the list of class registrations for the specified person,
+// or made by the specified person on behalf of others, in the order that they
+// were registered.
+func AllForPerson(storer phys.Storer, pid person.ID, fields Fields, fn func(*ClassReg)) {
+	if allForPersonSQLCache == nil {
+		allForPersonSQLCache = make(map[Fields]string)
+	}
+	if _, ok := allForPersonSQLCache[fields]; !ok {
+		var sb strings.Builder
+		sb.WriteString("SELECT ")
+		ColumnList(&sb, fields)
+		sb.WriteString(" FROM classreg cr WHERE cr.person=?1 OR cr.registered_by=?1 ORDER BY cr.id")
+		allForPersonSQLCache[fields] = sb.String()
+	}
+	phys.SQL(storer, allForPersonSQLCache[fields], func(stmt *phys.Stmt) {
+		var cr ClassReg
+
+		stmt.BindInt(int(pid))
+		for stmt.Step() {
+			cr.Scan(stmt, fields)
+			fn(&cr)
+		}
+	})
+}
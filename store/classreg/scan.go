@@ -2,12 +2,16 @@ package classreg
 
 import (
 	"strings"
+	"time"
 
 	"sunnyvaleserv.org/portal/store/class"
 	"sunnyvaleserv.org/portal/store/internal/phys"
 	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/referral"
 )
 
+const holdUntilFormat = "2006-01-02T15:04:05"
+
 // ColumnList generates a comma-separated list of column names for the specified
 // class registration fields.  It is used in constructing SQL SELECT statements.
 func ColumnList(sb *strings.Builder, fields Fields) {
@@ -48,6 +52,26 @@ func ColumnList(sb *strings.Builder, fields Fields) {
 		sb.WriteString(sep())
 		sb.WriteString("cr.waitlist")
 	}
+	if fields&FHoldUntil != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("cr.hold_until")
+	}
+	if fields&FHoldToken != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("cr.hold_token")
+	}
+	if fields&FReferral != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("cr.referral")
+	}
+	if fields&FReferralOther != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("cr.referral_other")
+	}
+	if fields&FCampaign != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("cr.campaign")
+	}
 }
 
 // Scan reads columns corresponding to the specified fields from the specified
@@ -80,5 +104,20 @@ func (cr *ClassReg) Scan(stmt *phys.Stmt, fields Fields) {
 	if fields&FWaitlist != 0 {
 		cr.waitlist = stmt.ColumnBool()
 	}
+	if fields&FHoldUntil != 0 {
+		cr.holdUntil, _ = time.ParseInLocation(holdUntilFormat, stmt.ColumnText(), time.Local)
+	}
+	if fields&FHoldToken != 0 {
+		cr.holdToken = stmt.ColumnText()
+	}
+	if fields&FReferral != 0 {
+		cr.referral = referral.ID(stmt.ColumnInt())
+	}
+	if fields&FReferralOther != 0 {
+		cr.referralOther = stmt.ColumnText()
+	}
+	if fields&FCampaign != 0 {
+		cr.campaign = stmt.ColumnText()
+	}
 	cr.fields |= fields
 }
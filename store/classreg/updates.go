@@ -2,15 +2,18 @@ package classreg
 
 import (
 	"fmt"
+	"time"
 
 	"sunnyvaleserv.org/portal/store/class"
 	"sunnyvaleserv.org/portal/store/internal/phys"
 	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/referral"
+	"sunnyvaleserv.org/portal/store/webhook"
 )
 
 // UpdaterFields are the fields that must be fetched prior to creating an
 // Updater.
-const UpdaterFields = FID | FClass | FPerson | FRegisteredBy | FFirstName | FLastName | FEmail | FCellPhone | FWaitlist
+const UpdaterFields = FID | FClass | FPerson | FRegisteredBy | FFirstName | FLastName | FEmail | FCellPhone | FWaitlist | FHoldUntil | FHoldToken | FReferral | FReferralOther | FCampaign
 
 // Updater is a structure that can be filled with data for a new or changed
 // class, and then later applied.  For creating new classes, it can simply be
@@ -18,15 +21,20 @@ const UpdaterFields = FID | FClass | FPerson | FRegisteredBy | FFirstName | FLas
 // in it must be set, or it should be instantiated with the Updater method of
 // the class being changed.
 type Updater struct {
-	ID           ID
-	Class        *class.Class
-	Person       *person.Person
-	RegisteredBy *person.Person
-	FirstName    string
-	LastName     string
-	Email        string
-	CellPhone    string
-	Waitlist     bool
+	ID            ID
+	Class         *class.Class
+	Person        *person.Person
+	RegisteredBy  *person.Person
+	FirstName     string
+	LastName      string
+	Email         string
+	CellPhone     string
+	Waitlist      bool
+	HoldUntil     time.Time
+	HoldToken     string
+	Referral      referral.ID
+	ReferralOther string
+	Campaign      string
 }
 
 // Updater returns a new Updater for the specified class, with its data matching
@@ -51,19 +59,24 @@ func (cr *ClassReg) Updater(storer phys.Storer, c *class.Class, p, rb *person.Pe
 		}
 	}
 	return &Updater{
-		ID:           cr.id,
-		Class:        c,
-		Person:       p,
-		RegisteredBy: rb,
-		FirstName:    cr.firstName,
-		LastName:     cr.lastName,
-		Email:        cr.email,
-		CellPhone:    cr.cellPhone,
-		Waitlist:     cr.waitlist,
+		ID:            cr.id,
+		Class:         c,
+		Person:        p,
+		RegisteredBy:  rb,
+		FirstName:     cr.firstName,
+		LastName:      cr.lastName,
+		Email:         cr.email,
+		CellPhone:     cr.cellPhone,
+		Waitlist:      cr.waitlist,
+		HoldUntil:     cr.holdUntil,
+		HoldToken:     cr.holdToken,
+		Referral:      cr.referral,
+		ReferralOther: cr.referralOther,
+		Campaign:      cr.campaign,
 	}
 }
 
-const createSQL = `INSERT INTO classreg (id, class, person, registered_by, first_name, last_name, email, cell_phone, waitlist) VALUES (?,?,?,?,?,?,?,?,?)`
+const createSQL = `INSERT INTO classreg (id, class, person, registered_by, first_name, last_name, email, cell_phone, waitlist, hold_until, hold_token, referral, referral_other, campaign) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?)`
 
 // Create creates a new class registration with the data in the Updater.
 func Create(storer phys.Storer, u *Updater) (cr *ClassReg) {
@@ -80,10 +93,21 @@ func Create(storer phys.Storer, u *Updater) (cr *ClassReg) {
 		}
 	})
 	cr.auditAndUpdate(storer, u, true)
+	data := map[string]any{
+		"class": map[string]any{"id": u.Class.ID(), "type": u.Class.Type().String(), "start": u.Class.Start()},
+		"registration": map[string]any{
+			"id": cr.id, "firstName": u.FirstName, "lastName": u.LastName, "waitlist": u.Waitlist,
+		},
+		"registeredBy": webhook.Ref{ID: int(u.RegisteredBy.ID()), Name: u.RegisteredBy.InformalName()},
+	}
+	if u.Person != nil {
+		data["person"] = webhook.Ref{ID: int(u.Person.ID()), Name: u.Person.InformalName()}
+	}
+	phys.Webhook(storer, webhook.ClassRegistered, data)
 	return cr
 }
 
-const updateSQL = `UPDATE classreg SET class=?, person=?, registered_by=?, first_name=?, last_name=?, email=?, cell_phone=?, waitlist=? WHERE id=?`
+const updateSQL = `UPDATE classreg SET class=?, person=?, registered_by=?, first_name=?, last_name=?, email=?, cell_phone=?, waitlist=?, hold_until=?, hold_token=?, referral=?, referral_other=?, campaign=? WHERE id=?`
 
 // Update updates the existing class, with the data in the Updater.
 func (cr *ClassReg) Update(storer phys.Storer, u *Updater) {
@@ -107,6 +131,15 @@ func bindUpdater(stmt *phys.Stmt, u *Updater) {
 	stmt.BindText(u.Email)
 	stmt.BindText(u.CellPhone)
 	stmt.BindBool(u.Waitlist)
+	if u.HoldUntil.IsZero() {
+		stmt.BindNull()
+	} else {
+		stmt.BindText(u.HoldUntil.Format(holdUntilFormat))
+	}
+	stmt.BindNullText(u.HoldToken)
+	stmt.BindNullInt(int(u.Referral))
+	stmt.BindNullText(u.ReferralOther)
+	stmt.BindNullText(u.Campaign)
 }
 
 func (cr *ClassReg) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
@@ -152,6 +185,30 @@ func (cr *ClassReg) auditAndUpdate(storer phys.Storer, u *Updater, create bool)
 		phys.Audit(storer, "%s:: waitlist = %v", context, u.Waitlist)
 		cr.waitlist = u.Waitlist
 	}
+	if !u.HoldUntil.Equal(cr.holdUntil) {
+		if u.HoldUntil.IsZero() {
+			phys.Audit(storer, "%s:: holdUntil = nil", context)
+		} else {
+			phys.Audit(storer, "%s:: holdUntil = %s", context, u.HoldUntil.Format(holdUntilFormat))
+		}
+		cr.holdUntil = u.HoldUntil
+	}
+	if u.HoldToken != cr.holdToken {
+		phys.Audit(storer, "%s:: holdToken = %q", context, u.HoldToken)
+		cr.holdToken = u.HoldToken
+	}
+	if u.Referral != cr.referral {
+		phys.Audit(storer, "%s:: referral = %d", context, u.Referral)
+		cr.referral = u.Referral
+	}
+	if u.ReferralOther != cr.referralOther {
+		phys.Audit(storer, "%s:: referralOther = %q", context, u.ReferralOther)
+		cr.referralOther = u.ReferralOther
+	}
+	if u.Campaign != cr.campaign {
+		phys.Audit(storer, "%s:: campaign = %q", context, u.Campaign)
+		cr.campaign = u.Campaign
+	}
 }
 
 // Delete deletes the receiver class registration.  The class *may* be provided
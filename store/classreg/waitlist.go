@@ -0,0 +1,81 @@
+package classreg
+
+import (
+	"time"
+
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/util"
+)
+
+// PromoteClassFields are the fields that must be fetched for a class passed to
+// Promote.
+const PromoteClassFields = class.FID | class.FType | class.FStart | class.FLimit
+
+// Promote brings the registrations for the specified class up to date.  First,
+// it cancels any registrations promoted from the waitlist whose holds expired
+// before now without being confirmed.  Then, it promotes waitlisted
+// registrations, in the order they were made, into any open seats.  If hold is
+// nonzero, each promoted registration is held for that long pending the
+// student's confirmation; otherwise, it is confirmed immediately.  Promote
+// returns the expired and promoted registrations, which have UpdaterFields.
+func Promote(storer phys.Storer, c *class.Class, hold time.Duration, now time.Time) (expired, promoted []*ClassReg) {
+	var (
+		regs  []*ClassReg
+		count uint
+	)
+	AllForClass(storer, c.ID(), UpdaterFields, func(cr *ClassReg) {
+		regs = append(regs, cr.Clone())
+	})
+	for _, cr := range regs {
+		if cr.waitlist {
+			continue
+		}
+		if !cr.holdUntil.IsZero() && cr.holdUntil.Before(now) {
+			cr.Delete(storer, c)
+			expired = append(expired, cr)
+			continue
+		}
+		count++
+	}
+	for _, cr := range regs {
+		if !cr.waitlist {
+			continue
+		}
+		if c.Limit() != 0 && count >= c.Limit() {
+			break
+		}
+		u := cr.Updater(storer, c, nil, nil)
+		u.Waitlist = false
+		if hold != 0 {
+			u.HoldUntil = now.Add(hold).Truncate(time.Second)
+			u.HoldToken = util.RandomToken()
+		}
+		cr.Update(storer, u)
+		promoted = append(promoted, cr)
+		count++
+	}
+	return expired, promoted
+}
+
+// ClassesWithExpiredHolds returns the IDs of the classes that have
+// registrations promoted from the waitlist whose holds expired before now
+// without being confirmed.
+func ClassesWithExpiredHolds(storer phys.Storer, now time.Time) (cids []class.ID) {
+	phys.SQL(storer, `SELECT DISTINCT class FROM classreg WHERE hold_until<? ORDER BY class`, func(stmt *phys.Stmt) {
+		stmt.BindText(now.Format(holdUntilFormat))
+		for stmt.Step() {
+			cids = append(cids, class.ID(stmt.ColumnInt()))
+		}
+	})
+	return cids
+}
+
+// Confirm confirms a registration that was promoted from the waitlist and is
+// being held.  The registration must have UpdaterFields.  The class *may* be
+// provided to avoid a lookup.
+func (cr *ClassReg) Confirm(storer phys.Storer, c *class.Class) {
+	u := cr.Updater(storer, c, nil, nil)
+	u.HoldUntil, u.HoldToken = time.Time{}, ""
+	cr.Update(storer, u)
+}
@@ -0,0 +1,145 @@
+package enum
+
+// Permission is a named permission to take some action.  People hold a
+// permission in an organization either by virtue of their privilege level in
+// it (see DefaultPrivLevel) or because one of their roles grants it
+// explicitly.  (See auth.Can.)
+type Permission string
+
+// Values for Permission.
+const (
+	// PermEventEdit is permission to add and edit events and tasks for the
+	// organization, and to manage their signups.
+	PermEventEdit Permission = "event.edit"
+	// PermEventAttendance is permission to view and record attendance at
+	// events for the organization.
+	PermEventAttendance Permission = "event.attendance"
+	// PermPersonAdd is permission to add new people to the system.
+	PermPersonAdd Permission = "person.add"
+	// PermPersonViewClearance is permission to view the volunteer status
+	// and clearances (other than background check details) of any person.
+	PermPersonViewClearance Permission = "person.viewClearance"
+	// PermPersonEditClearance is permission to edit the volunteer status
+	// and clearances of any person, and to see background check details.
+	PermPersonEditClearance Permission = "person.editClearance"
+	// PermTextSend is permission to send text messages to any SMS list.
+	// (People can also send to particular lists by virtue of holding roles
+	// that are senders on those lists.)
+	PermTextSend Permission = "text.send"
+	// PermFolderApprove is permission to add, edit, and approve public
+	// files and folders.
+	PermFolderApprove Permission = "folder.approve"
+	// PermPersonEdit is permission to view and edit the names, contact
+	// information, availability, photo, activity, and certificates of any
+	// person.
+	PermPersonEdit Permission = "person.edit"
+	// PermPersonRoles is permission to add people to and remove them from
+	// the roles of the organization.
+	PermPersonRoles Permission = "person.roles"
+	// PermPersonResetPassword is permission to reset the password of any
+	// person, and to view and end their sessions.
+	PermPersonResetPassword Permission = "person.resetPassword"
+	// PermPersonNotes is permission to view and add notes about any
+	// person.  (Each note has its own visibility level as well.)
+	PermPersonNotes Permission = "person.notes"
+	// PermPersonData is permission to download all of the data about any
+	// person, in response to a formal request, and to edit their emergency
+	// contacts.
+	PermPersonData Permission = "person.data"
+	// PermHistoryView is permission to view the change history of people,
+	// events, roles, and lists.  (Each change has its own visibility level
+	// as well.)
+	PermHistoryView Permission = "history.view"
+	// PermClassManage is permission to manage the classes of the
+	// organization:  their sessions, registrations, rosters, surveys, and
+	// certificates.
+	PermClassManage Permission = "class.manage"
+	// PermActivationView is permission to view the list of activations and
+	// their details.
+	PermActivationView Permission = "activation.view"
+	// PermActivationEdit is permission to add and edit activations.
+	PermActivationEdit Permission = "activation.edit"
+	// PermHoursEditPast is permission to record volunteer hours for months
+	// that have already been reported.
+	PermHoursEditPast Permission = "hours.editPast"
+)
+
+// Label returns a description of the Permission, suitable for display to
+// webmasters.
+func (p Permission) Label() string {
+	switch p {
+	case PermEventEdit:
+		return "Add and edit events"
+	case PermEventAttendance:
+		return "View and record event attendance"
+	case PermPersonAdd:
+		return "Add new people"
+	case PermPersonViewClearance:
+		return "View volunteer status and clearances"
+	case PermPersonEditClearance:
+		return "Edit volunteer status and clearances"
+	case PermTextSend:
+		return "Send text messages to any SMS list"
+	case PermFolderApprove:
+		return "Add, edit, and approve public files"
+	case PermPersonEdit:
+		return "View and edit names and contact information"
+	case PermPersonRoles:
+		return "Add people to and remove them from roles"
+	case PermPersonResetPassword:
+		return "Reset passwords and end sessions"
+	case PermPersonNotes:
+		return "View and add notes about people"
+	case PermPersonData:
+		return "Download people's data and edit emergency contacts"
+	case PermHistoryView:
+		return "View change history"
+	case PermClassManage:
+		return "Manage classes"
+	case PermActivationView:
+		return "View activations"
+	case PermActivationEdit:
+		return "Add and edit activations"
+	case PermHoursEditPast:
+		return "Record hours for past months"
+	default:
+		return string(p)
+	}
+}
+
+// DefaultPrivLevel returns the privilege level that grants the Permission
+// without an explicit grant, and the organization in which that level must be
+// held.  If the returned organization is zero, the level must be held in the
+// organization for which the permission is being checked.  If the returned
+// level is zero, the Permission is held only through an explicit grant.
+func (p Permission) DefaultPrivLevel() (Org, PrivLevel) {
+	switch p {
+	case PermEventEdit, PermEventAttendance, PermPersonAdd, PermPersonViewClearance,
+		PermPersonEdit, PermPersonRoles, PermPersonResetPassword, PermPersonNotes,
+		PermHistoryView, PermClassManage, PermActivationView:
+		return 0, PrivLeader
+	case PermPersonEditClearance, PermFolderApprove, PermPersonData, PermActivationEdit:
+		return OrgAdmin, PrivLeader
+	default:
+		return 0, 0
+	}
+}
+
+// Valid returns whether a Permission value is valid.
+func (p Permission) Valid() bool {
+	for _, p2 := range AllPermissions {
+		if p == p2 {
+			return true
+		}
+	}
+	return false
+}
+
+// AllPermissions is a list of all permissions.
+var AllPermissions = []Permission{
+	PermEventEdit, PermEventAttendance, PermPersonAdd, PermPersonViewClearance,
+	PermPersonEditClearance, PermTextSend, PermFolderApprove, PermPersonEdit,
+	PermPersonRoles, PermPersonResetPassword, PermPersonNotes, PermPersonData,
+	PermHistoryView, PermClassManage, PermActivationView, PermActivationEdit,
+	PermHoursEditPast,
+}
@@ -5,6 +5,7 @@ import (
 
 	"sunnyvaleserv.org/portal/store/internal/phys"
 	"sunnyvaleserv.org/portal/store/venue"
+	"sunnyvaleserv.org/portal/store/webhook"
 )
 
 // UpdaterFields are the fields that must be fetched prior to creating an
@@ -107,47 +108,88 @@ func bindUpdater(stmt *phys.Stmt, u *Updater) {
 	stmt.BindHexInt(int(u.Flags))
 }
 
+// history records a change to a field of the event in its change history.
+func (e *Event) history(storer phys.Storer, field, old, new string) {
+	phys.History(storer, "Event", int(e.id), field, old, new)
+}
+
 func (e *Event) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
+	var changed []string
+
 	context := fmt.Sprintf("Event %s %q [%d]", u.Start[:10], u.Name, e.id)
 	if create {
 		context = "ADD " + context
 	}
 	if u.Name != e.name {
 		phys.Audit(storer, "%s:: name = %q", context, u.Name)
+		e.history(storer, "name", e.name, u.Name)
 		e.name = u.Name
+		changed = append(changed, "name")
 	}
 	if u.Start != e.start {
 		phys.Audit(storer, "%s:: start = %s", context, u.Start)
+		e.history(storer, "start", e.start, u.Start)
 		e.start = u.Start
+		changed = append(changed, "start")
 	}
 	if u.End != e.end {
 		phys.Audit(storer, "%s:: end = %s", context, u.End)
+		e.history(storer, "end", e.end, u.End)
 		e.end = u.End
+		changed = append(changed, "end")
 	}
 	if vid := u.Venue.ID(); vid != e.venue {
+		var oldVenue, newVenue string
+		if e.venue != 0 {
+			if v := venue.WithID(storer, e.venue, venue.FName); v != nil {
+				oldVenue = v.Name()
+			}
+		}
 		if vid == 0 {
 			phys.Audit(storer, "%s:: venue = nil", context)
 		} else {
 			phys.Audit(storer, "%s:: venue = %q [%d]", context, u.Venue.Name(), vid)
+			newVenue = u.Venue.Name()
 		}
+		e.history(storer, "venue", oldVenue, newVenue)
 		e.venue = vid
+		changed = append(changed, "venue")
 	}
 	if u.VenueURL != e.venueURL {
 		phys.Audit(storer, "%s:: venueURL = %q", context, u.VenueURL)
+		e.history(storer, "venueURL", e.venueURL, u.VenueURL)
 		e.venueURL = u.VenueURL
+		changed = append(changed, "venueURL")
 	}
 	if u.Activation != e.activation {
 		phys.Audit(storer, "%s:: activation = %q", context, u.Activation)
+		e.history(storer, "activation", e.activation, u.Activation)
 		e.activation = u.Activation
+		changed = append(changed, "activation")
 	}
 	if u.Details != e.details {
 		phys.Audit(storer, "%s:: details = %q", context, u.Details)
+		e.history(storer, "details", e.details, u.Details)
 		e.details = u.Details
+		changed = append(changed, "details")
 	}
 	if u.Flags != e.flags {
 		phys.Audit(storer, "%s:: flags = 0x%x", context, u.Flags)
+		e.history(storer, "flags", fmt.Sprintf("0x%x", e.flags), fmt.Sprintf("0x%x", u.Flags))
 		e.flags = u.Flags
+		changed = append(changed, "flags")
 	}
+	if create {
+		phys.Webhook(storer, webhook.EventCreated, map[string]any{"event": e.webhookRef()})
+	} else if len(changed) != 0 {
+		phys.Webhook(storer, webhook.EventChanged, map[string]any{"event": e.webhookRef(), "changes": changed})
+	}
+}
+
+// webhookRef returns a reference to the event for webhook payloads.  The event
+// must have FID, FName, FStart, and FEnd.
+func (e *Event) webhookRef() webhook.EventRef {
+	return webhook.EventRef{ID: int(e.id), Name: e.name, Start: e.start, End: e.end}
 }
 
 const duplicateNameSQL = `SELECT 1 FROM event WHERE id!=? AND name=? AND start LIKE ?`
@@ -171,5 +213,9 @@ func (e *Event) Delete(storer phys.Storer) {
 		stmt.Step()
 	})
 	phys.Audit(storer, "DELETE Event %s %q [%d]", e.Start()[:10], e.Name(), e.ID())
+	e.history(storer, "DELETE", "", "")
+	phys.Webhook(storer, webhook.EventCancelled, map[string]any{
+		"event": webhook.EventRef{ID: int(e.ID()), Name: e.Name(), Start: e.Start()},
+	})
 	phys.Unindex(storer, e)
 }
@@ -0,0 +1,74 @@
+// Package history provides access to the change history of people, events,
+// roles, and lists.  History rows are recorded by the updaters of those
+// entities (and of the relationships among them) as they make changes; see
+// phys.History.
+package history
+
+import (
+	"time"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+// Type is the type of an entity whose history is recorded.
+type Type string
+
+// Values for Type.  These must match the entity types passed to phys.History.
+const (
+	Event  Type = "Event"
+	List   Type = "List"
+	Person Type = "Person"
+	Role   Type = "Role"
+)
+
+// An Entry is a single change in the history of an entity.
+type Entry struct {
+	Timestamp time.Time
+	// Actor is the person who made the change, or zero if it was made by
+	// the system.  ActorName is their informal name, or an empty string if
+	// they have since been deleted.
+	Actor     person.ID
+	ActorName string
+	// Impersonator is the webmaster who made the change while viewing the
+	// site as the actor, or zero if there was none.  ImpersonatorName is
+	// their informal name, or an empty string if they have since been
+	// deleted.
+	Impersonator     person.ID
+	ImpersonatorName string
+	// Field is the name of the changed field.  For changes that don't set
+	// a value, such as adding a role to a person, it describes the change.
+	// It may be qualified by subentity names, separated by "::".
+	Field string
+	// Old and New are the old and new values of the field, formatted for
+	// display.  Either is empty if the field had no value, and both are
+	// empty if the change doesn't set a value.
+	Old string
+	New string
+}
+
+const timestampFormat = "2006-01-02T15:04:05"
+
+const forEntitySQL = `SELECT h.timestamp, h.actor, p.informal_name, h.impersonator, i.informal_name, h.field, h.old, h.new FROM history h LEFT JOIN person p ON p.id=h.actor LEFT JOIN person i ON i.id=h.impersonator WHERE h.etype=? AND h.eid=? ORDER BY h.id DESC`
+
+// ForEntity calls fn for each change in the history of the specified entity,
+// newest first.
+func ForEntity(storer phys.Storer, etype Type, id int, fn func(*Entry)) {
+	var e Entry
+
+	phys.SQL(storer, forEntitySQL, func(stmt *phys.Stmt) {
+		stmt.BindText(string(etype))
+		stmt.BindInt(id)
+		for stmt.Step() {
+			e.Timestamp, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+			e.Actor = person.ID(stmt.ColumnInt())
+			e.ActorName = stmt.ColumnText()
+			e.Impersonator = person.ID(stmt.ColumnInt())
+			e.ImpersonatorName = stmt.ColumnText()
+			e.Field = stmt.ColumnText()
+			e.Old = stmt.ColumnText()
+			e.New = stmt.ColumnText()
+			fn(&e)
+		}
+	})
+}
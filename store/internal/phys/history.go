@@ -0,0 +1,37 @@
+package phys
+
+import (
+	"time"
+)
+
+const historyTimestampFormat = "2006-01-02T15:04:05"
+
+const historyInsertSQL = `INSERT INTO history (etype, eid, field, old, new, actor, impersonator, timestamp) VALUES (?,?,?,?,?,?,?,?)`
+
+// History records a change to a field of an entity in the history table.  The
+// etype is the entity type ("Person", "Event", "Role", or "List") and eid is
+// its ID.  The field may be qualified by subentity names, separated by "::",
+// and for changes that don't set a value (such as adding a role to a person)
+// it describes the change.  The old and new values are as they should be
+// displayed; an empty string means no value.  The change is attributed to the
+// user of the store's log entry, and to the webmaster impersonating them, if
+// any.
+func History(storer Storer, etype string, eid int, field, old, new string) {
+	store := storer.AsStore()
+	SQL(store, historyInsertSQL, func(stmt *Stmt) {
+		stmt.BindText(etype)
+		stmt.BindInt(eid)
+		stmt.BindText(field)
+		stmt.BindNullText(old)
+		stmt.BindNullText(new)
+		if store.logentry != nil {
+			stmt.BindNullInt(store.logentry.UserID)
+			stmt.BindNullInt(store.logentry.ImpersonatorID)
+		} else {
+			stmt.BindNull()
+			stmt.BindNull()
+		}
+		stmt.BindText(time.Now().Format(historyTimestampFormat))
+		stmt.Step()
+	})
+}
@@ -1,3 +1,57 @@
+DROP TABLE IF EXISTS activation;
+CREATE TABLE activation (
+  id        integer PRIMARY KEY,
+  number    text    NOT NULL UNIQUE,
+  type      integer NOT NULL CHECK (type IN (1, 2)),
+  agency    text,
+  commander text,
+  notes     text
+);
+
+DROP TABLE IF EXISTS activation_folder;
+CREATE TABLE activation_folder (
+  activation integer NOT NULL REFERENCES activation ON DELETE CASCADE,
+  folder     integer NOT NULL REFERENCES folder ON DELETE CASCADE,
+  PRIMARY KEY (activation, folder)
+) WITHOUT ROWID;
+CREATE INDEX activation_folder_folder_idx ON activation_folder (folder);
+
+DROP TABLE IF EXISTS activation_status;
+CREATE TABLE activation_status (
+  id         integer PRIMARY KEY,
+  activation integer NOT NULL REFERENCES activation ON DELETE CASCADE,
+  timestamp  text    NOT NULL, -- YYYY-MM-DDTHH:MM (local)
+  status     integer NOT NULL,
+  note       text
+);
+CREATE INDEX activation_status_activation_idx ON activation_status (activation, timestamp);
+
+DROP TABLE IF EXISTS api_token;
+CREATE TABLE api_token (
+  id        integer PRIMARY KEY,
+  person    integer NOT NULL REFERENCES person ON DELETE CASCADE,
+  name      text    NOT NULL,        -- description given by the person
+  hash      text    NOT NULL UNIQUE, -- SHA-256 of the token, hex
+  scopes    text    NOT NULL,        -- space-separated list of scopes
+  created   text    NOT NULL,        -- YYYY-MM-DDTHH:MM:SS (local)
+  last_used text                     -- YYYY-MM-DDTHH:MM:SS (local)
+);
+CREATE INDEX api_token_person_idx ON api_token (person);
+
+DROP TABLE IF EXISTS certificate;
+CREATE TABLE certificate (
+  id        integer PRIMARY KEY,
+  token     text    NOT NULL UNIQUE,
+  person    integer NOT NULL REFERENCES person ON DELETE CASCADE,
+  class     integer REFERENCES class ON DELETE SET NULL,
+  name      text    NOT NULL,
+  course    text    NOT NULL,
+  completed text    NOT NULL, -- YYYY-MM-DD
+  issued    text    NOT NULL  -- YYYY-MM-DD
+);
+CREATE UNIQUE INDEX certificate_class_idx ON certificate (class, person);
+CREATE INDEX certificate_person_idx ON certificate (person);
+
 DROP TABLE IF EXISTS class;
 CREATE TABLE class (
   id        integer PRIMARY KEY,
@@ -6,28 +60,44 @@ CREATE TABLE class (
   en_desc   text    NOT NULL,
   es_desc   text    NOT NULL,
   elimit    integer NOT NULL CHECK (elimit >= 0),
-  referrals integer NOT NULL,
+  referrals integer NOT NULL, -- legacy counts by source; see class.LegacyReferrals
   regurl    text,
-  role      integer REFERENCES role
+  role      integer REFERENCES role,
+  grad_role integer REFERENCES role
 );
 CREATE UNIQUE INDEX class_start_idx ON class (start, type);
 CREATE INDEX class_role_index ON class (role);
+CREATE INDEX class_grad_role_index ON class (grad_role);
+
+DROP TABLE IF EXISTS class_session;
+CREATE TABLE class_session (
+  class integer NOT NULL REFERENCES class ON DELETE CASCADE,
+  event integer NOT NULL REFERENCES event ON DELETE CASCADE,
+  PRIMARY KEY (class, event)
+) WITHOUT ROWID;
+CREATE UNIQUE INDEX class_session_event_idx ON class_session (event);
 
 DROP TABLE IF EXISTS classreg;
 CREATE TABLE classreg (
-  id            integer PRIMARY KEY,
-  class         integer NOT NULL REFERENCES class,
-  person        integer          REFERENCES person,
-  registered_by integer NOT NULL REFERENCES person,
-  first_name    text    NOT NULL,
-  last_name     text    NOT NULL,
-  email         text,
-  cell_phone    text,
-  waitlist      boolean NOT NULL DEFAULT false
+  id             integer PRIMARY KEY,
+  class          integer NOT NULL REFERENCES class,
+  person         integer          REFERENCES person,
+  registered_by  integer NOT NULL REFERENCES person,
+  first_name     text    NOT NULL,
+  last_name      text    NOT NULL,
+  email          text,
+  cell_phone     text,
+  waitlist       boolean NOT NULL DEFAULT false,
+  hold_until     text,                     -- YYYY-MM-DDTHH:MM:SS (local)
+  hold_token     text    UNIQUE,
+  referral       integer          REFERENCES referral_source,
+  referral_other text,                     -- free text for "other" sources
+  campaign       text                      -- ?src= code from the class page URL
 );
 CREATE INDEX classreg_class_index ON classreg (class);
 CREATE INDEX classreg_person_index ON classreg (person);
 CREATE INDEX classreg_regby_index ON classreg (registered_by);
+CREATE INDEX classreg_referral_index ON classreg (referral);
 
 DROP TABLE IF EXISTS document;
 CREATE TABLE document (
@@ -52,6 +122,7 @@ CREATE TABLE event (
   flags      integer NOT NULL DEFAULT 0
 );
 CREATE INDEX event_start_idx ON event (start);
+CREATE INDEX event_activation_idx ON event (activation);
 CREATE INDEX event_venue_idx ON event (venue);
 
 DROP TABLE IF EXISTS folder;
@@ -68,6 +139,20 @@ CREATE TABLE folder (
 CREATE UNIQUE INDEX folder_urlname_idx ON folder (parent, url_name);
 INSERT INTO folder VALUES (1, 1, 'Files', '', 0, 0, 1, 3);
 
+DROP TABLE IF EXISTS history;
+CREATE TABLE history (
+  id           integer PRIMARY KEY,
+  etype        text    NOT NULL, -- "Person", "Event", "Role", or "List"
+  eid          integer NOT NULL, -- *not* REFERENCES; history outlives the entity
+  field        text    NOT NULL,
+  old          text,
+  new          text,
+  actor        integer,          -- person ID; NULL for system changes
+  impersonator integer,          -- person ID of webmaster viewing site as actor
+  timestamp    text    NOT NULL  -- YYYY-MM-DDTHH:MM:SS (local)
+);
+CREATE INDEX history_entity_idx ON history (etype, eid, field);
+
 DROP TABLE IF EXISTS list;
 CREATE TABLE list (
   id         integer PRIMARY KEY,
@@ -103,6 +188,16 @@ CREATE TABLE list_role (
 ) WITHOUT ROWID;
 CREATE INDEX list_role_role_idx ON list_role (role);
 
+DROP TABLE IF EXISTS login_link;
+CREATE TABLE login_link (
+  hash     text    PRIMARY KEY,     -- SHA-256 of the token, hex
+  person   integer NOT NULL REFERENCES person ON DELETE CASCADE,
+  browser  text    NOT NULL,        -- SHA-256 of the browser identifier, hex
+  remember boolean NOT NULL,
+  expires  text    NOT NULL         -- YYYY-MM-DDTHH:MM:SS (local)
+) WITHOUT ROWID;
+CREATE INDEX login_link_person_idx ON login_link (person);
+
 DROP TABLE IF EXISTS person;
 CREATE TABLE person (
   id                 integer PRIMARY KEY,
@@ -126,7 +221,8 @@ CREATE TABLE person (
   hours_token        text    UNIQUE,
   identification     integer NOT NULL DEFAULT 0,
   birthdate          text,            -- YYYY-MM-DD
-  flags              integer NOT NULL DEFAULT 0
+  flags              integer NOT NULL DEFAULT 0,
+  medical_notes      text
 );
 CREATE INDEX person_email_idx ON person (email);
 CREATE INDEX person_sort_name_idx ON person (sort_name);
@@ -143,6 +239,15 @@ CREATE TABLE person_address (
   PRIMARY KEY (person, type)
 ) WITHOUT ROWID;
 
+DROP TABLE IF EXISTS person_availability;
+CREATE TABLE person_availability (
+  person  integer NOT NULL REFERENCES person ON DELETE CASCADE,
+  weekday integer NOT NULL CHECK (weekday BETWEEN 0 AND 6), -- 0 = Sunday
+  start   text    NOT NULL,                               -- HH:MM
+  end     text    NOT NULL CHECK (end > start),           -- HH:MM
+  PRIMARY KEY (person, weekday, start)
+) WITHOUT ROWID;
+
 DROP TABLE IF EXISTS person_bgcheck;
 CREATE TABLE person_bgcheck (
   person  integer NOT NULL REFERENCES person ON DELETE CASCADE,
@@ -153,6 +258,14 @@ CREATE TABLE person_bgcheck (
   PRIMARY KEY (person, type)
 ) WITHOUT ROWID;
 
+DROP TABLE IF EXISTS person_blackout;
+CREATE TABLE person_blackout (
+  person integer NOT NULL REFERENCES person ON DELETE CASCADE,
+  start  text    NOT NULL,                      -- YYYY-MM-DD
+  end    text    NOT NULL CHECK (end >= start), -- YYYY-MM-DD
+  PRIMARY KEY (person, start)
+) WITHOUT ROWID;
+
 DROP TABLE IF EXISTS person_dswreg;
 CREATE TABLE person_dswreg (
   person     integer NOT NULL REFERENCES person ON DELETE CASCADE,
@@ -181,6 +294,14 @@ CREATE TABLE person_note (
 );
 CREATE INDEX person_note_person_idx ON person_note (person, date);
 
+DROP TABLE IF EXISTS person_permission;
+CREATE TABLE person_permission (
+  person     integer NOT NULL REFERENCES person ON DELETE CASCADE,
+  org        integer NOT NULL,
+  permission text    NOT NULL,
+  PRIMARY KEY (person, org, permission)
+) WITHOUT ROWID;
+
 DROP TABLE IF EXISTS person_privlevel;
 CREATE TABLE person_privlevel (
   person    integer NOT NULL REFERENCES person ON DELETE CASCADE,
@@ -189,6 +310,25 @@ CREATE TABLE person_privlevel (
   PRIMARY KEY (person, org)
 ) WITHOUT ROWID;
 
+DROP TABLE IF EXISTS person_retain;
+CREATE TABLE person_retain (
+  person integer PRIMARY KEY REFERENCES person ON DELETE CASCADE,
+  until  text    NOT NULL -- YYYY-MM-DD
+);
+
+DROP TABLE IF EXISTS person_passkey;
+CREATE TABLE person_passkey (
+  id         integer PRIMARY KEY,
+  person     integer NOT NULL REFERENCES person ON DELETE CASCADE,
+  credential text    NOT NULL UNIQUE, -- credential ID, base64url
+  public_key text    NOT NULL,        -- COSE_Key, base64url
+  sign_count integer NOT NULL DEFAULT 0,
+  name       text    NOT NULL,        -- device name given by the person
+  created    text    NOT NULL,        -- YYYY-MM-DDTHH:MM:SS (local)
+  last_used  text                     -- YYYY-MM-DDTHH:MM:SS (local)
+);
+CREATE INDEX person_passkey_person_idx ON person_passkey (person);
+
 DROP TABLE IF EXISTS person_role;
 CREATE TABLE person_role (
   person   integer NOT NULL REFERENCES person ON DELETE CASCADE,
@@ -198,6 +338,30 @@ CREATE TABLE person_role (
 ) WITHOUT ROWID;
 CREATE INDEX person_role_role_idx ON person_role (role);
 
+DROP TABLE IF EXISTS person_sso;
+CREATE TABLE person_sso (
+  person  integer PRIMARY KEY REFERENCES person ON DELETE CASCADE,
+  issuer  text    NOT NULL, -- OpenID provider issuer URL
+  subject text    NOT NULL, -- provider's identifier for the person
+  linked  text    NOT NULL, -- YYYY-MM-DDTHH:MM:SS (local)
+  UNIQUE (issuer, subject)
+);
+
+DROP TABLE IF EXISTS person_totp;
+CREATE TABLE person_totp (
+  person    integer PRIMARY KEY REFERENCES person ON DELETE CASCADE,
+  secret    text    NOT NULL,          -- base32
+  recovery  text    NOT NULL,          -- space-separated hashes of unused recovery codes
+  last_step integer NOT NULL DEFAULT 0 -- time step of the last code used
+);
+
+DROP TABLE IF EXISTS person_verify;
+CREATE TABLE person_verify (
+  person  integer PRIMARY KEY REFERENCES person ON DELETE CASCADE,
+  token   text    NOT NULL UNIQUE,
+  expires text    NOT NULL -- YYYY-MM-DDTHH:MM:SS (local)
+);
+
 DROP TABLE IF EXISTS redirect;
 CREATE TABLE redirect (
   id      integer PRIMARY KEY,
@@ -205,6 +369,22 @@ CREATE TABLE redirect (
   target  text    NOT NULL
 );
 
+DROP TABLE IF EXISTS referral_source;
+CREATE TABLE referral_source (
+  id       integer PRIMARY KEY,
+  seq      integer NOT NULL,
+  en_name  text    NOT NULL,
+  es_name  text    NOT NULL,
+  code     text    UNIQUE,   -- campaign code given in ?src= on class pages
+  other    boolean NOT NULL, -- registrant describes the source in free text
+  archived boolean NOT NULL
+);
+INSERT INTO referral_source VALUES (1, 1, 'Word of mouth', 'Boca a boca', NULL, 0, 0);
+INSERT INTO referral_source VALUES (3, 2, 'Information table at an event', 'Mesa informativa en un evento', NULL, 0, 0);
+INSERT INTO referral_source VALUES (2, 3, 'Printed advertisement', 'Publicidad impresa', NULL, 0, 0);
+INSERT INTO referral_source VALUES (4, 4, 'Online advertisement', 'Publicidad en línea', NULL, 0, 0);
+INSERT INTO referral_source VALUES (5, 5, 'Other', 'Otro', NULL, 1, 0);
+
 DROP TABLE IF EXISTS role;
 CREATE TABLE role (
   id        integer PRIMARY KEY,
@@ -224,12 +404,24 @@ CREATE TABLE role_implies (
 ) WITHOUT ROWID;
 CREATE INDEX role_implies_implied_idx ON role_implies (implied);
 
+DROP TABLE IF EXISTS role_permission;
+CREATE TABLE role_permission (
+  role       integer NOT NULL REFERENCES role ON DELETE CASCADE,
+  permission text    NOT NULL,
+  PRIMARY KEY (role, permission)
+) WITHOUT ROWID;
+
 DROP TABLE IF EXISTS session;
 CREATE TABLE session (
-  token   text    PRIMARY KEY,
-  person  integer NOT NULL REFERENCES person ON DELETE CASCADE,
-  expires text    NOT NULL, -- YYYY-MM-DDTHH:MM:SS (local)
-  csrf    text    NOT NULL
+  token        text    PRIMARY KEY,
+  person       integer NOT NULL REFERENCES person ON DELETE CASCADE,
+  expires      text    NOT NULL, -- YYYY-MM-DDTHH:MM:SS (local)
+  csrf         text    NOT NULL,
+  impersonator integer REFERENCES person ON DELETE CASCADE, -- webmaster viewing the site as person
+  created      text    NOT NULL, -- YYYY-MM-DDTHH:MM:SS (local)
+  last_used    text    NOT NULL, -- YYYY-MM-DDTHH:MM:SS (local)
+  ip           text    NOT NULL,
+  user_agent   text    NOT NULL
 );
 CREATE INDEX session_person_index ON session (person);
 
@@ -256,6 +448,44 @@ CREATE TABLE shift_person (
 ) WITHOUT ROWID;
 CREATE UNIQUE INDEX shift_person_person_idx ON shift_person (shift, signed_up);
 
+DROP TABLE IF EXISTS survey_answer;
+CREATE TABLE survey_answer (
+  response integer NOT NULL REFERENCES survey_response ON DELETE CASCADE,
+  question integer NOT NULL REFERENCES survey_question ON DELETE CASCADE,
+  answer   text    NOT NULL,
+  PRIMARY KEY (response, question)
+) WITHOUT ROWID;
+CREATE INDEX survey_answer_question_idx ON survey_answer (question);
+
+DROP TABLE IF EXISTS survey_invite;
+CREATE TABLE survey_invite (
+  token     text    PRIMARY KEY,
+  class     integer NOT NULL REFERENCES class ON DELETE CASCADE,
+  responded boolean NOT NULL
+);
+CREATE INDEX survey_invite_class_idx ON survey_invite (class);
+
+DROP TABLE IF EXISTS survey_question;
+CREATE TABLE survey_question (
+  id         integer PRIMARY KEY,
+  ctype      integer NOT NULL, -- class type
+  seq        integer NOT NULL,
+  kind       integer NOT NULL,
+  en_text    text    NOT NULL,
+  es_text    text    NOT NULL,
+  en_choices text    NOT NULL, -- newline-separated
+  es_choices text    NOT NULL  -- newline-separated
+);
+CREATE INDEX survey_question_ctype_idx ON survey_question (ctype, seq);
+
+DROP TABLE IF EXISTS survey_response;
+CREATE TABLE survey_response (
+  id    integer PRIMARY KEY,
+  class integer NOT NULL REFERENCES class ON DELETE CASCADE,
+  name  text -- NULL if anonymous
+);
+CREATE INDEX survey_response_class_idx ON survey_response (class);
+
 DROP TABLE IF EXISTS task;
 CREATE TABLE task (
   id      integer PRIMARY KEY,
@@ -334,6 +564,13 @@ CREATE TABLE textmsg_reply (
 CREATE INDEX textmsg_reply_textmsg_idx ON textmsg_reply (textmsg, recipient, timestamp DESC);
 CREATE INDEX textmsg_reply_recipient_idx ON textmsg_reply (recipient);
 
+DROP TABLE IF EXISTS throttle;
+CREATE TABLE throttle (
+  key       text NOT NULL, -- e.g. "classreg-ip:192.0.2.1"
+  timestamp text NOT NULL  -- YYYY-MM-DDTHH:MM:SS (local)
+);
+CREATE INDEX throttle_key_idx ON throttle (key, timestamp);
+
 DROP TABLE IF EXISTS venue;
 CREATE TABLE venue (
   id      integer PRIMARY KEY,
@@ -341,3 +578,29 @@ CREATE TABLE venue (
   url     text,
   flags   integer NOT NULL
 );
+
+DROP TABLE IF EXISTS webhook;
+CREATE TABLE webhook (
+  id      integer PRIMARY KEY,
+  url     text    NOT NULL,
+  secret  text    NOT NULL, -- HMAC-SHA256 key for signing deliveries
+  events  text    NOT NULL, -- space-separated list of event names
+  flags   integer NOT NULL  -- 1 = disabled
+);
+
+DROP TABLE IF EXISTS webhook_delivery;
+CREATE TABLE webhook_delivery (
+  id           integer PRIMARY KEY,
+  webhook      integer NOT NULL REFERENCES webhook ON DELETE CASCADE,
+  event        text    NOT NULL, -- event name, e.g. "person.created"
+  payload      text    NOT NULL, -- JSON request body
+  created      text    NOT NULL, -- YYYY-MM-DDTHH:MM:SS (local)
+  attempts     integer NOT NULL, -- number of delivery attempts made
+  next_attempt text,             -- YYYY-MM-DDTHH:MM:SS (local); NULL when done
+  last_attempt text,             -- YYYY-MM-DDTHH:MM:SS (local)
+  status       integer,          -- HTTP status of the last attempt
+  error        text,             -- error from the last attempt
+  delivered    text              -- YYYY-MM-DDTHH:MM:SS (local)
+);
+CREATE INDEX webhook_delivery_webhook_idx ON webhook_delivery (webhook, created);
+CREATE INDEX webhook_delivery_next_idx ON webhook_delivery (next_attempt);
@@ -79,6 +79,8 @@ type tx struct {
 	removeOnFail   []string
 	removeOnCommit []string
 	searchOps      []search.BatchOperationIndexed
+	webhooks       []int
+	onCommit       []func()
 	nocommit       bool
 }
 
@@ -137,6 +139,8 @@ func (store *Store) attemptTransaction(fn func()) (success bool) {
 
 // cleanup finalizes a transaction started with Transaction.
 func (store *Store) cleanupTransaction(panicked interface{}) bool {
+	var onCommit []func()
+
 	// Roll back the transaction if it failed or its no-commit flag is set.
 	if panicked != nil || store.tx.nocommit {
 		goto ROLLBACK
@@ -147,29 +151,42 @@ func (store *Store) cleanupTransaction(panicked interface{}) bool {
 	}
 	// We have successfully released the database savepoint.  If we have a
 	// parent transaction, propagate the audit log entries, errors, file
-	// removals, and search ops to it, and we're done.
+	// removals, search ops, webhook deliveries, and commit actions to it,
+	// and we're done.
 	if tx := store.tx; tx.parent != nil {
 		tx.parent.audit = append(tx.parent.audit, tx.audit...)
 		tx.parent.Problems.AddList(&tx.Problems)
 		tx.parent.removeOnFail = append(tx.parent.removeOnFail, tx.removeOnFail...)
 		tx.parent.removeOnCommit = append(tx.parent.removeOnCommit, tx.removeOnCommit...)
 		tx.parent.searchOps = append(tx.parent.searchOps, tx.searchOps...)
+		tx.parent.webhooks = append(tx.parent.webhooks, tx.webhooks...)
+		tx.parent.onCommit = append(tx.parent.onCommit, tx.onCommit...)
 		store.tx = tx.parent
 		return true
 	}
 	// We don't have a parent transaction, so releasing the savepoint
 	// actually committed the database changes.  Now we commit the audit log
-	// entries, errors, file removals, and search ops.
+	// entries, errors, file removals, and search ops, and start delivery of
+	// any queued webhooks.
 	store.logentry.Changes = append(store.logentry.Changes, store.tx.audit...)
 	store.logentry.Problems.AddError(&store.tx.Problems)
 	for _, r := range store.tx.removeOnCommit {
 		os.RemoveAll(r)
 	}
+	if len(store.tx.webhooks) != 0 {
+		go deliverQueuedWebhooks(store.tx.webhooks)
+	}
 	if err := store.applySearchOps(); err != nil {
 		store.tx = nil
 		panic(err)
 	}
+	// Finally, run any commit actions.  This is done after the transaction
+	// is cleared, so that they can start transactions of their own.
+	onCommit = store.tx.onCommit
 	store.tx = nil
+	for _, fn := range onCommit {
+		fn()
+	}
 	return true
 
 ROLLBACK:
@@ -211,3 +228,12 @@ ROLLBACK:
 func (store *Store) DoNotCommit() {
 	store.tx.nocommit = true
 }
+
+// OnCommit registers a function to be called after the current transaction
+// commits.  It is used for changes outside the database (e.g., to files) that
+// must not happen unless the database changes do.  If the transaction rolls
+// back, the function is not called.
+func OnCommit(storer Storer, fn func()) {
+	store := storer.AsStore()
+	store.tx.onCommit = append(store.tx.onCommit, fn)
+}
@@ -0,0 +1,233 @@
+package phys
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sunnyvaleserv.org/portal/util/log"
+)
+
+// webhookTimeout is the time we allow a webhook receiver to respond.
+const webhookTimeout = 10 * time.Second
+
+// webhookLease is the time for which a delivery attempt claims a delivery.  If
+// the attempt doesn't record its result within that time (e.g., because the
+// process died), the delivery becomes due again.
+const webhookLease = time.Minute
+
+// webhookRetryBase is the delay before the first retry of a failed delivery.
+// The delay doubles with each subsequent retry, up to webhookRetryMax.
+const webhookRetryBase = time.Minute
+
+// webhookRetryMax is the longest delay between retries of a failed delivery.
+const webhookRetryMax = 6 * time.Hour
+
+// webhookMaxAttempts is the number of attempts made to deliver a webhook
+// before giving up on it.
+const webhookMaxAttempts = 10
+
+const webhookTimestampFormat = "2006-01-02T15:04:05"
+
+// webhookClient is the HTTP client used for webhook deliveries.
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+// In the SQL below, flags&1 is the webhook.Disabled flag.
+
+const webhookSubscribersSQL = `SELECT id FROM webhook WHERE flags&1=0 AND instr(' '||events||' ', ' '||?||' ')>0`
+const webhookQueueSQL = `INSERT INTO webhook_delivery (webhook, event, payload, created, attempts, next_attempt) VALUES (?,?,?,?,0,?4)`
+
+// Webhook queues an event for delivery to every enabled webhook that
+// subscribes to it.  data is sent as the "data" key of the JSON payload.  Like
+// Audit, it must be called within a transaction:  the deliveries are written
+// to the webhook_delivery table (the outbox) as part of the transaction, so
+// they are sent if and only if the transaction commits.  The first delivery
+// attempt is made when the outermost transaction commits; failed deliveries
+// are retried by DeliverDueWebhooks.
+func Webhook(storer Storer, event string, data any) {
+	var (
+		store   = storer.AsStore()
+		hooks   []int
+		now     = time.Now()
+		payload []byte
+		err     error
+	)
+	SQL(store, webhookSubscribersSQL, func(stmt *Stmt) {
+		stmt.BindText(event)
+		for stmt.Step() {
+			hooks = append(hooks, stmt.ColumnInt())
+		}
+	})
+	if len(hooks) == 0 {
+		return
+	}
+	if payload, err = json.Marshal(struct {
+		Event     string `json:"event"`
+		Timestamp string `json:"timestamp"`
+		Data      any    `json:"data"`
+	}{event, now.Format(time.RFC3339), data}); err != nil {
+		panic(err)
+	}
+	for _, hook := range hooks {
+		SQL(store, webhookQueueSQL, func(stmt *Stmt) {
+			stmt.BindInt(hook)
+			stmt.BindText(event)
+			stmt.BindText(string(payload))
+			stmt.BindText(now.Format(webhookTimestampFormat))
+			stmt.Step()
+		})
+		store.tx.webhooks = append(store.tx.webhooks, int(LastInsertRowID(store)))
+	}
+}
+
+// deliverQueuedWebhooks makes the first delivery attempt for the specified
+// deliveries.  It is called in its own goroutine when a transaction that
+// queued them commits, and so uses its own connection to the data store.
+func deliverQueuedWebhooks(ids []int) {
+	entry := log.New("", "deliver-webhooks")
+	if err := Connect(context.Background(), entry, func(store *Store) {
+		for _, id := range ids {
+			DeliverWebhook(store, id)
+		}
+	}); err != nil {
+		entry.Log()
+	}
+}
+
+const webhookDueSQL = `SELECT id FROM webhook_delivery WHERE next_attempt<=? AND webhook IN (SELECT id FROM webhook WHERE flags&1=0) ORDER BY next_attempt LIMIT 100`
+
+// DeliverDueWebhooks attempts delivery of every webhook delivery whose next
+// attempt is due.  It is called periodically to retry failed deliveries.
+func DeliverDueWebhooks(storer Storer) {
+	var ids []int
+
+	SQL(storer, webhookDueSQL, func(stmt *Stmt) {
+		stmt.BindText(time.Now().Format(webhookTimestampFormat))
+		for stmt.Step() {
+			ids = append(ids, stmt.ColumnInt())
+		}
+	})
+	for _, id := range ids {
+		DeliverWebhook(storer, id)
+	}
+}
+
+const webhookClaimSQL = `UPDATE webhook_delivery SET next_attempt=? WHERE id=? AND next_attempt<=? AND webhook IN (SELECT id FROM webhook WHERE flags&1=0)`
+const webhookFetchSQL = `SELECT w.url, w.secret, d.event, d.payload, d.attempts FROM webhook_delivery d, webhook w WHERE d.id=? AND d.webhook=w.id`
+const webhookResultSQL = `UPDATE webhook_delivery SET attempts=?, last_attempt=?, status=?, error=?, next_attempt=?, delivered=? WHERE id=?`
+
+// DeliverWebhook attempts delivery of the specified webhook delivery, if it is
+// due and its webhook is enabled, and records the result.  It must not be
+// called within a transaction.
+func DeliverWebhook(storer Storer, id int) {
+	var (
+		store    = storer.AsStore()
+		now      = time.Now()
+		claimed  bool
+		url      string
+		secret   string
+		event    string
+		payload  string
+		attempts int
+		next     string
+		done     string
+	)
+	// Claim the delivery, so that no one else attempts it concurrently, and
+	// fetch the details of it.
+	store.Transaction(func() {
+		SQL(store, webhookClaimSQL, func(stmt *Stmt) {
+			stmt.BindText(now.Add(webhookLease).Format(webhookTimestampFormat))
+			stmt.BindInt(id)
+			stmt.BindText(now.Format(webhookTimestampFormat))
+			stmt.Step()
+		})
+		if claimed = RowsAffected(store) != 0; !claimed {
+			return
+		}
+		SQL(store, webhookFetchSQL, func(stmt *Stmt) {
+			stmt.BindInt(id)
+			stmt.Step()
+			url = stmt.ColumnText()
+			secret = stmt.ColumnText()
+			event = stmt.ColumnText()
+			payload = stmt.ColumnText()
+			attempts = stmt.ColumnInt()
+		})
+	})
+	if !claimed {
+		return
+	}
+	// Send it.
+	status, err := sendWebhook(url, secret, id, event, payload, now)
+	attempts++
+	// Record the result.
+	if err == nil {
+		done = time.Now().Format(webhookTimestampFormat)
+	} else if attempts < webhookMaxAttempts {
+		delay := webhookRetryBase << (attempts - 1)
+		if delay > webhookRetryMax {
+			delay = webhookRetryMax
+		}
+		next = time.Now().Add(delay).Format(webhookTimestampFormat)
+	}
+	store.Transaction(func() {
+		SQL(store, webhookResultSQL, func(stmt *Stmt) {
+			stmt.BindInt(attempts)
+			stmt.BindText(now.Format(webhookTimestampFormat))
+			stmt.BindNullInt(status)
+			if err != nil {
+				stmt.BindText(err.Error())
+			} else {
+				stmt.BindNull()
+			}
+			stmt.BindNullText(next)
+			stmt.BindNullText(done)
+			stmt.BindInt(id)
+			stmt.Step()
+		})
+	})
+}
+
+// sendWebhook sends a webhook delivery.  It returns the HTTP status of the
+// response (zero if there was none), and an error if the delivery did not
+// succeed.
+//
+// The request is signed with the webhook's secret:  the X-SERV-Signature
+// header contains "sha256=" followed by the hex-encoded HMAC-SHA256 of the
+// X-SERV-Timestamp header value, a period, and the request body.  Receivers
+// should verify the signature and reject timestamps that are too old.
+func sendWebhook(url, secret string, id int, event, payload string, now time.Time) (status int, err error) {
+	var (
+		req  *http.Request
+		resp *http.Response
+		ts   = strconv.FormatInt(now.Unix(), 10)
+	)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "." + payload))
+	if req, err = http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(payload))); err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SunnyvaleSERV-Webhook/1")
+	req.Header.Set("X-SERV-Event", event)
+	req.Header.Set("X-SERV-Delivery", strconv.Itoa(id))
+	req.Header.Set("X-SERV-Timestamp", ts)
+	req.Header.Set("X-SERV-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	if resp, err = webhookClient.Do(req); err != nil {
+		return 0, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -78,19 +78,28 @@ func (l *List) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
 	}
 	if u.Type != l.Type {
 		phys.Audit(storer, "%s:: type = %s [%d]", context, u.Type, u.Type)
+		l.history(storer, "type", l.Type.String(), u.Type.String())
 		l.Type = u.Type
 	}
 	if u.Name != l.Name {
 		phys.Audit(storer, "%s:: name = %q", context, u.Name)
+		l.history(storer, "name", l.Name, u.Name)
 		l.Name = u.Name
 	}
 	if u.Moderators != nil && (l.Moderators == nil || !u.Moderators.Equal(l.Moderators)) {
 		phys.Audit(storer, "%s:: moderators = %q", context, packModerators(u.Moderators))
+		l.history(storer, "moderators", packModerators(l.Moderators), packModerators(u.Moderators))
 	} else if u.Moderators == nil && l.Moderators != nil {
 		phys.Audit(storer, "%s:: moderators = nil", context)
+		l.history(storer, "moderators", packModerators(l.Moderators), "")
 	}
 }
 
+// history records a change to a field of the list in its change history.
+func (l *List) history(storer phys.Storer, field, old, new string) {
+	phys.History(storer, "List", int(l.ID), field, old, new)
+}
+
 const duplicateNameSQL = `SELECT 1 FROM list WHERE id!=? AND name=?`
 
 // DuplicateName returns whether the name specified in the Updater
@@ -111,4 +120,5 @@ func (l *List) Delete(storer phys.Storer) {
 		stmt.Step()
 	})
 	phys.Audit(storer, "DELETE List %q [%d]", l.Name, l.ID)
+	l.history(storer, "DELETE", "", "")
 }
@@ -21,6 +21,8 @@ func Subscribe(storer phys.Storer, l *list.List, p *person.Person) {
 	})
 	if phys.RowsAffected(storer) != 0 {
 		phys.Audit(storer, "List %q [%d]:: SUBSCRIBE Person %q [%d]", l.Name, l.ID, p.InformalName(), p.ID())
+		phys.History(storer, "List", int(l.ID), "SUBSCRIBE person", "", p.InformalName())
+		phys.History(storer, "Person", int(p.ID()), "SUBSCRIBE list", "", l.Name)
 	}
 }
 
@@ -37,5 +39,7 @@ func Unsubscribe(storer phys.Storer, l *list.List, p *person.Person) {
 	})
 	if phys.RowsAffected(storer) != 0 {
 		phys.Audit(storer, "List %q [%d]:: UNSUBSCRIBE Person %q [%d]", l.Name, l.ID, p.InformalName(), p.ID())
+		phys.History(storer, "List", int(l.ID), "UNSUBSCRIBE person", "", p.InformalName())
+		phys.History(storer, "Person", int(p.ID()), "UNSUBSCRIBE list", "", l.Name)
 	}
 }
@@ -1,11 +1,14 @@
 package listrole
 
 import (
+	"strconv"
+
 	"sunnyvaleserv.org/portal/store/internal/phys"
 	"sunnyvaleserv.org/portal/store/list"
 	"sunnyvaleserv.org/portal/store/role"
 )
 
+const getListRoleSQL = `SELECT sender, submodel FROM list_role WHERE list=? AND role=?`
 const deleteListRoleSQL = `DELETE FROM list_role WHERE list=? AND role=?`
 const setListRoleSQL = `
 INSERT INTO list_role (list, role, sender, submodel) VALUES (?,?,?,?)
@@ -14,6 +17,18 @@ ON CONFLICT DO UPDATE SET sender=?3, submodel=?4 WHERE sender!=?3 OR submodel!=?
 // SetListRole sets the sender privilege and subscription model on the specified
 // list for the specified role.
 func SetListRole(storer phys.Storer, l *list.List, r *role.Role, sender bool, submodel SubscriptionModel) {
+	var (
+		oldSender   bool
+		oldSubmodel SubscriptionModel
+	)
+	phys.SQL(storer, getListRoleSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(l.ID))
+		stmt.BindInt(int(r.ID()))
+		if stmt.Step() {
+			oldSender = stmt.ColumnBool()
+			oldSubmodel = SubscriptionModel(stmt.ColumnInt())
+		}
+	})
 	if !sender && submodel == 0 {
 		phys.SQL(storer, deleteListRoleSQL, func(stmt *phys.Stmt) {
 			stmt.BindInt(int(l.ID))
@@ -22,6 +37,8 @@ func SetListRole(storer phys.Storer, l *list.List, r *role.Role, sender bool, su
 		})
 		if phys.RowsAffected(storer) != 0 {
 			phys.Audit(storer, "List %q [%d]:: DELETE Role %q [%d]", l.Name, l.ID, r.Name(), r.ID())
+			phys.History(storer, "List", int(l.ID), "DELETE role", r.Name(), "")
+			phys.History(storer, "Role", int(r.ID()), "DELETE list", l.Name, "")
 		}
 	} else {
 		phys.SQL(storer, setListRoleSQL, func(stmt *phys.Stmt) {
@@ -34,6 +51,14 @@ func SetListRole(storer phys.Storer, l *list.List, r *role.Role, sender bool, su
 		if phys.RowsAffected(storer) != 0 {
 			phys.Audit(storer, "List %q [%d]:: Role %q [%d]:: sender = %v", l.Name, l.ID, r.Name(), r.ID(), sender)
 			phys.Audit(storer, "List %q [%d]:: Role %q [%d]:: submodel = %s [%d]", l.Name, l.ID, r.Name(), r.ID(), submodel, submodel)
+			if sender != oldSender {
+				phys.History(storer, "List", int(l.ID), "Role "+r.Name()+":: sender", strconv.FormatBool(oldSender), strconv.FormatBool(sender))
+				phys.History(storer, "Role", int(r.ID()), "List "+l.Name+":: sender", strconv.FormatBool(oldSender), strconv.FormatBool(sender))
+			}
+			if submodel != oldSubmodel {
+				phys.History(storer, "List", int(l.ID), "Role "+r.Name()+":: submodel", oldSubmodel.String(), submodel.String())
+				phys.History(storer, "Role", int(r.ID()), "List "+l.Name+":: submodel", oldSubmodel.String(), submodel.String())
+			}
 		}
 	}
 }
@@ -0,0 +1,71 @@
+// Package loginlink stores the sign-in links that have been emailed to people
+// and not yet used.  Only hashes of the link token and of the identifier of the
+// browser to which the link is bound are stored.  Each link can be used once,
+// and only until it expires.
+package loginlink
+
+import (
+	"time"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+const timestampFormat = "2006-01-02T15:04:05"
+
+const addSQL = `INSERT INTO login_link (hash, person, browser, remember, expires) VALUES (?,?,?,?,?)`
+
+// Add records a sign-in link for the specified person.  hash is the hash of the
+// link token, and browser is the hash of the identifier of the browser to which
+// it is bound.  remember is the setting of the "Remember me" checkbox.  The
+// person must have FID and FInformalName.
+func Add(storer phys.Storer, p *person.Person, hash, browser string, remember bool, expires time.Time) {
+	deleteExpired(storer)
+	phys.SQL(storer, addSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(hash)
+		stmt.BindInt(int(p.ID()))
+		stmt.BindText(browser)
+		stmt.BindBool(remember)
+		stmt.BindText(expires.In(time.Local).Format(timestampFormat))
+		stmt.Step()
+	})
+	phys.Audit(storer, "AuthN:: ADD LoginLink for person %q [%d] expires %s", p.InformalName(), p.ID(), expires.In(time.Local).Format(timestampFormat))
+}
+
+const getSQL = `SELECT person, remember FROM login_link WHERE hash=? AND browser=? AND expires>=?`
+const deleteSQL = `DELETE FROM login_link WHERE hash=?`
+
+// Use consumes the unexpired sign-in link with the specified hashes of token
+// and browser, if there is one, and returns the ID of the person to whom it was
+// sent and their "Remember me" setting.  It returns zero if there is no such
+// link.
+func Use(storer phys.Storer, hash, browser string) (pid person.ID, remember bool) {
+	phys.SQL(storer, getSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(hash)
+		stmt.BindText(browser)
+		stmt.BindText(time.Now().Format(timestampFormat))
+		if stmt.Step() {
+			pid = person.ID(stmt.ColumnInt())
+			remember = stmt.ColumnBool()
+		}
+	})
+	if pid != 0 {
+		phys.SQL(storer, deleteSQL, func(stmt *phys.Stmt) {
+			stmt.BindText(hash)
+			stmt.Step()
+		})
+		phys.Audit(storer, "AuthN:: USE LoginLink for person [%d]", pid)
+	}
+	deleteExpired(storer)
+	return pid, remember
+}
+
+const deleteExpiredSQL = `DELETE FROM login_link WHERE expires<?`
+
+// deleteExpired deletes all expired sign-in links.
+func deleteExpired(storer phys.Storer) {
+	phys.SQL(storer, deleteExpiredSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(time.Now().Format(timestampFormat))
+		stmt.Step()
+	})
+}
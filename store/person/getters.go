@@ -203,6 +203,16 @@ func (p *Person) Flags() Flags {
 	return p.flags
 }
 
+// MedicalNotes are notes the Person has chosen to share with incident leads
+// in case of a medical emergency during a deployment (e.g., allergies,
+// conditions, medications).  They are shown only on emergency contact sheets.
+func (p *Person) MedicalNotes() string {
+	if p.fields&FMedicalNotes == 0 {
+		panic("Person.MedicalNotes called without having fetched FMedicalNotes")
+	}
+	return p.medicalNotes
+}
+
 // Addresses is the set of addresses for the Person.  It is a slice indexed by
 // AddressType.  It always has length numAddressTypes, but any element of it
 // could be nil, indicating no address of that type.
@@ -294,6 +304,30 @@ func (p *Person) HasPrivLevel(org enum.Org, level enum.PrivLevel) bool {
 	return false
 }
 
+// HasPermission returns whether the Person has been granted the specified
+// permission explicitly, by one of their roles, on the specified organization
+// (or, if org is zero, any organization).  It does not consider permissions
+// held by virtue of privilege level; for that, use auth.Can.
+func (p *Person) HasPermission(org enum.Org, perm enum.Permission) bool {
+	if p == nil {
+		return false
+	}
+	if p.fields&FPermissions == 0 {
+		panic("Person.HasPermission called without having fetched FPermissions")
+	}
+	if orgs := p.permissions[perm]; orgs != nil {
+		if org != 0 {
+			return orgs[org]
+		}
+		for _, org := range enum.AllOrgs {
+			if orgs[org] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // IsAdminLeader returns whether the Person has PrivLeader on OrgAdmin.
 func (p *Person) IsAdminLeader() bool { return p.HasPrivLevel(enum.OrgAdmin, enum.PrivLeader) }
 
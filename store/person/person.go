@@ -36,6 +36,27 @@ const (
 	// is visible to anyone with a login, bypassing the normal visibility
 	// rules.  (This is primarily used for the SERV coordinator.)
 	VisibleToAll
+	// Archived indicates that the Person has been inactive long enough that
+	// their contact information, addresses, birthdate, and emergency
+	// contacts have been scrubbed.  Their name and history are retained
+	// for reporting.  (See the personarchive package.)
+	Archived
+	// HasPhoto indicates that the Person has a photo on file.  (See
+	// SetPhoto.)
+	HasPhoto
+	// NoLoginLink indicates that the Person has turned off signing in with
+	// a link sent by email.
+	NoLoginLink
+	// RequireSSO indicates that the Person must sign in through the
+	// site's OpenID provider, rather than with a password, passkey, or
+	// sign-in link.  It has no effect when single sign-on is not
+	// configured.
+	RequireSSO
+	// RequireTOTP indicates that the Person must use two-factor
+	// authentication when signing in with a password or through single
+	// sign-on.  (It is also required for leaders when the
+	// "requireLeaderTOTP" setting is "true"; see auth.TOTPRequired.)
+	RequireTOTP
 )
 
 // Fields is a bitmask of flags identifying specified fields of the Person
@@ -66,12 +87,14 @@ const (
 	FIdentification
 	FBirthdate
 	FFlags
+	FMedicalNotes
 	FAddresses
 	FBGChecks
 	FDSWRegistrations
 	FNotes
 	FEmContacts
 	FPrivLevels
+	FPermissions
 )
 
 // Person describes a SERV-related person (volunteer, class student, etc.).
@@ -102,12 +125,14 @@ type Person struct {
 	birthdate        string
 	identification   IdentType
 	flags            Flags
+	medicalNotes     string
 	addresses        Addresses
 	bgChecks         BGChecks
 	dswRegistrations DSWRegistrations
 	notes            Notes
 	emContacts       EmContacts
 	privLevels       []enum.PrivLevel
+	permissions      map[enum.Permission][]bool
 }
 
 func (p *Person) Clone() (c *Person) {
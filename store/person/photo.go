@@ -0,0 +1,95 @@
+package person
+
+import (
+	"fmt"
+	"os"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+// PhotoFields are the fields that must be fetched for a person whose photo is
+// changed with SetPhoto or RemovePhoto.
+const PhotoFields = FID | FInformalName | FFlags
+
+// photoPath returns the path of the photo file for the specified person.
+func photoPath(id ID) string {
+	return fmt.Sprintf("photos/%02d/%02d", id/100, id%100)
+}
+
+// OpenPhoto returns an open file handle to the photo of the specified person,
+// or nil if they have none.  It must be closed by the caller.
+func OpenPhoto(id ID) (fh *os.File) {
+	var err error
+	if fh, err = os.OpenFile(photoPath(id), os.O_RDONLY, 0); err != nil {
+		return nil
+	}
+	return fh
+}
+
+// SetPhoto stores the specified JPEG image as the photo of the person,
+// replacing any previous photo.  The person must have PhotoFields.
+func (p *Person) SetPhoto(storer phys.Storer, jpeg []byte) {
+	var old string
+
+	if err := os.MkdirAll(fmt.Sprintf("photos/%02d", p.id/100), 0777); err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(photoPath(p.id)+".new", jpeg, 0666); err != nil {
+		panic(err)
+	}
+	if err := os.Rename(photoPath(p.id)+".new", photoPath(p.id)); err != nil {
+		panic(err)
+	}
+	phys.Audit(storer, "Person %q [%d]:: photo = (%d bytes)", p.informalName, p.id, len(jpeg))
+	if p.flags&HasPhoto != 0 {
+		old = "(photo)"
+	}
+	p.history(storer, "photo", old, fmt.Sprintf("(%d bytes)", len(jpeg)))
+	if p.flags&HasPhoto == 0 {
+		u := p.Updater()
+		u.Flags |= HasPhoto
+		p.Update(storer, u, FFlags)
+	}
+}
+
+// RemovePhoto removes the photo of the person.  The person must have
+// PhotoFields.
+func (p *Person) RemovePhoto(storer phys.Storer) {
+	if err := os.Remove(photoPath(p.id)); err != nil && !os.IsNotExist(err) {
+		panic(err)
+	}
+	if p.flags&HasPhoto != 0 {
+		phys.Audit(storer, "Person %q [%d]:: photo = nil", p.informalName, p.id)
+		p.history(storer, "photo", "(photo)", "")
+		u := p.Updater()
+		u.Flags &^= HasPhoto
+		p.Update(storer, u, FFlags)
+	}
+}
+
+// DeletePhotoFile removes the photo file of a person who is being deleted.  The
+// caller is responsible for calling this only once the deletion has been
+// committed.
+func DeletePhotoFile(id ID) {
+	if err := os.Remove(photoPath(id)); err != nil && !os.IsNotExist(err) {
+		panic(err)
+	}
+}
+
+// MovePhoto moves the photo file of one person to another, when the two are
+// being merged.  If the destination person already has a photo, the source
+// photo is discarded instead.  The caller is responsible for the HasPhoto
+// flags of both people, and for calling this only once the merge has been
+// committed.
+func MovePhoto(from, to ID) {
+	if _, err := os.Stat(photoPath(to)); err == nil {
+		os.Remove(photoPath(from))
+		return
+	}
+	if err := os.MkdirAll(fmt.Sprintf("photos/%02d", to/100), 0777); err != nil {
+		panic(err)
+	}
+	if err := os.Rename(photoPath(from), photoPath(to)); err != nil && !os.IsNotExist(err) {
+		panic(err)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"sunnyvaleserv.org/portal/store/internal/phys"
 )
 
-const joinFields = FAddresses | FBGChecks | FDSWRegistrations | FNotes | FEmContacts | FPrivLevels
+const joinFields = FAddresses | FBGChecks | FDSWRegistrations | FNotes | FEmContacts | FPrivLevels | FPermissions
 
 var withIDSQLCache map[Fields]string
 
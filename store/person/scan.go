@@ -108,6 +108,10 @@ func ColumnList(sb *strings.Builder, fields Fields) {
 		sb.WriteString(sep())
 		sb.WriteString("p.flags")
 	}
+	if fields&FMedicalNotes != 0 {
+		sb.WriteString(sep())
+		sb.WriteString("p.medical_notes")
+	}
 	if fields&FAddresses != 0 {
 		panic("FAddresses cannot be fetched with ColumnList/Scan")
 	}
@@ -126,6 +130,9 @@ func ColumnList(sb *strings.Builder, fields Fields) {
 	if fields&FPrivLevels != 0 {
 		panic("FPrivLevels cannot be fetched with ColumnList/Scan")
 	}
+	if fields&FPermissions != 0 {
+		panic("FPermissions cannot be fetched with ColumnList/Scan")
+	}
 }
 
 // Scan reads columns corresponding to the specified fields from the specified
@@ -197,7 +204,10 @@ func (p *Person) Scan(stmt *phys.Stmt, fields Fields) {
 	if fields&FFlags != 0 {
 		p.flags = Flags(stmt.ColumnHexInt())
 	}
-	p.fields |= fields &^ (FAddresses | FBGChecks | FDSWRegistrations | FNotes | FEmContacts | FPrivLevels)
+	if fields&FMedicalNotes != 0 {
+		p.medicalNotes = stmt.ColumnText()
+	}
+	p.fields |= fields &^ joinFields
 }
 
 func (p *Person) readJoins(store phys.Storer, fields Fields) {
@@ -219,6 +229,9 @@ func (p *Person) readJoins(store phys.Storer, fields Fields) {
 	if fields&FPrivLevels != 0 {
 		p.readPrivLevels(store)
 	}
+	if fields&FPermissions != 0 {
+		p.readPermissions(store)
+	}
 }
 
 const readAddressesSQL = `SELECT type, same_as_home, address, latitude, longitude, fire_district FROM person_address WHERE person=?`
@@ -350,3 +363,21 @@ func (p *Person) readPrivLevels(store phys.Storer) {
 	})
 	p.fields |= FPrivLevels
 }
+
+const readPermissionsSQL = `SELECT org, permission FROM person_permission WHERE person=?`
+
+func (p *Person) readPermissions(store phys.Storer) {
+	p.permissions = make(map[enum.Permission][]bool)
+	phys.SQL(store, readPermissionsSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(p.ID()))
+		for stmt.Step() {
+			var org = enum.Org(stmt.ColumnInt())
+			var perm = enum.Permission(stmt.ColumnText())
+			if p.permissions[perm] == nil {
+				p.permissions[perm] = make([]bool, enum.NumOrgs)
+			}
+			p.permissions[perm][org] = true
+		}
+	})
+	p.fields |= FPermissions
+}
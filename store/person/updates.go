@@ -2,15 +2,17 @@ package person
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/webhook"
 )
 
 // tableFields is the bitmask of fields that are stored in the main person
 // table.
-const tableFields = FID | FVolgisticsID | FInformalName | FFormalName | FSortName | FCallSign | FPronouns | FEmail | FEmail2 | FCellPhone | FHomePhone | FWorkPhone | FPassword | FBadLoginCount | FBadLoginTime | FPWResetToken | FPWResetTime | FUnsubscribeToken | FHoursToken | FIdentification | FBirthdate | FFlags
+const tableFields = FID | FVolgisticsID | FInformalName | FFormalName | FSortName | FCallSign | FPronouns | FEmail | FEmail2 | FCellPhone | FHomePhone | FWorkPhone | FPassword | FBadLoginCount | FBadLoginTime | FPWResetToken | FPWResetTime | FUnsubscribeToken | FHoursToken | FIdentification | FBirthdate | FFlags | FMedicalNotes
 
 // Updater is a structure that can be filled with data for a new or changed
 // person, and then later applied.  For creating new people, it can simply be
@@ -40,6 +42,7 @@ type Updater struct {
 	Identification   IdentType
 	Birthdate        string
 	Flags            Flags
+	MedicalNotes     string
 	Addresses        Addresses
 	BGChecks         BGChecks
 	DSWRegistrations DSWRegistrations
@@ -73,6 +76,7 @@ func (p *Person) Updater() *Updater {
 		Identification:   p.identification,
 		Birthdate:        p.birthdate,
 		Flags:            p.flags,
+		MedicalNotes:     p.medicalNotes,
 		Addresses:        p.addresses.clone(),
 		BGChecks:         p.bgChecks.clone(),
 		DSWRegistrations: p.dswRegistrations.clone(),
@@ -81,7 +85,7 @@ func (p *Person) Updater() *Updater {
 	}
 }
 
-const createSQL = `INSERT INTO person (id, volgistics_id, informal_name, formal_name, sort_name, call_sign, pronouns, email, email2, cell_phone, home_phone, work_phone, password, bad_login_count, bad_login_time, pwreset_token, pwreset_time, unsubscribe_token, hours_token, identification, birthdate, flags) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`
+const createSQL = `INSERT INTO person (id, volgistics_id, informal_name, formal_name, sort_name, call_sign, pronouns, email, email2, cell_phone, home_phone, work_phone, password, bad_login_count, bad_login_time, pwreset_token, pwreset_time, unsubscribe_token, hours_token, identification, birthdate, flags, medical_notes) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`
 
 // Create creates a new person, with the data in the Updater.
 func Create(storer phys.Storer, u *Updater) (p *Person) {
@@ -118,6 +122,7 @@ func Create(storer phys.Storer, u *Updater) (p *Person) {
 		stmt.BindInt(int(u.Identification))
 		stmt.BindNullText(u.Birthdate)
 		stmt.BindHexInt(int(u.Flags))
+		stmt.BindNullText(u.MedicalNotes)
 		stmt.Step()
 		if u.ID != 0 {
 			p.id = u.ID
@@ -132,6 +137,9 @@ func Create(storer phys.Storer, u *Updater) (p *Person) {
 	storeEmContacts(storer, p.id, u.EmContacts, false)
 	p.auditAndUpdate(storer, u, p.fields, true)
 	phys.Index(storer, p)
+	phys.Webhook(storer, webhook.PersonCreated, map[string]webhook.Ref{
+		"person": {ID: int(p.id), Name: p.informalName},
+	})
 	return p
 }
 
@@ -146,6 +154,9 @@ func (p *Person) Update(storer phys.Storer, u *Updater, fields Fields) {
 	if fields&FPrivLevels != 0 {
 		panic("cannot change privilege levels")
 	}
+	if fields&FPermissions != 0 {
+		panic("cannot change permissions")
+	}
 	if tf := fields & tableFields; tf != 0 {
 		if updateSQLCache == nil {
 			updateSQLCache = make(map[Fields]string)
@@ -238,6 +249,10 @@ func (p *Person) Update(storer phys.Storer, u *Updater, fields Fields) {
 				sb.WriteString(sep())
 				sb.WriteString("flags=?")
 			}
+			if tf&FMedicalNotes != 0 {
+				sb.WriteString(sep())
+				sb.WriteString("medical_notes=?")
+			}
 			sb.WriteString(" WHERE id=?")
 			updateSQLCache[tf] = sb.String()
 		}
@@ -313,6 +328,9 @@ func (p *Person) Update(storer phys.Storer, u *Updater, fields Fields) {
 			if tf&FFlags != 0 {
 				stmt.BindHexInt(int(u.Flags))
 			}
+			if tf&FMedicalNotes != 0 {
+				stmt.BindNullText(u.MedicalNotes)
+			}
 			stmt.BindInt(int(p.id))
 			stmt.Step()
 		})
@@ -488,6 +506,20 @@ func storeEmContacts(storer phys.Storer, pid ID, emContacts EmContacts, delfirst
 	}
 }
 
+// history records a change to a field of the person in their change history.
+func (p *Person) history(storer phys.Storer, field, old, new string) {
+	phys.History(storer, "Person", int(p.id), field, old, new)
+}
+
+// historyTime formats a time for the change history.  The zero time is shown
+// as no value.
+func historyTime(t time.Time, format string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(time.Local).Format(format)
+}
+
 func (p *Person) auditAndUpdate(storer phys.Storer, u *Updater, fields Fields, create bool) {
 	context := fmt.Sprintf("Person %q [%d]", u.InformalName, p.id)
 	if create {
@@ -495,57 +527,71 @@ func (p *Person) auditAndUpdate(storer phys.Storer, u *Updater, fields Fields, c
 	}
 	if fields&FVolgisticsID != 0 && u.VolgisticsID != p.volgisticsID {
 		phys.Audit(storer, "%s:: volgisticsID = %d", context, u.VolgisticsID)
+		p.history(storer, "volgisticsID", strconv.Itoa(int(p.volgisticsID)), strconv.Itoa(int(u.VolgisticsID)))
 		p.volgisticsID = u.VolgisticsID
 	}
 	if fields&FInformalName != 0 && u.InformalName != p.informalName {
 		phys.Audit(storer, "%s:: informalName = %q", context, u.InformalName)
+		p.history(storer, "informalName", p.informalName, u.InformalName)
 		p.informalName = u.InformalName
 	}
 	if fields&FFormalName != 0 && u.FormalName != p.formalName {
 		phys.Audit(storer, "%s:: formalName = %q", context, u.FormalName)
+		p.history(storer, "formalName", p.formalName, u.FormalName)
 		p.formalName = u.FormalName
 	}
 	if fields&FSortName != 0 && u.SortName != p.sortName {
 		phys.Audit(storer, "%s:: sortName = %q", context, u.SortName)
+		p.history(storer, "sortName", p.sortName, u.SortName)
 		p.sortName = u.SortName
 	}
 	if fields&FCallSign != 0 && u.CallSign != p.callSign {
 		phys.Audit(storer, "%s:: callSign = %q", context, u.CallSign)
+		p.history(storer, "callSign", p.callSign, u.CallSign)
 		p.callSign = u.CallSign
 	}
 	if fields&FPronouns != 0 && u.Pronouns != p.pronouns {
 		phys.Audit(storer, "%s:: pronouns = %q", context, u.Pronouns)
+		p.history(storer, "pronouns", p.pronouns, u.Pronouns)
 		p.pronouns = u.Pronouns
 	}
 	if fields&FEmail != 0 && u.Email != p.email {
 		phys.Audit(storer, "%s:: email = %q", context, u.Email)
+		p.history(storer, "email", p.email, u.Email)
 		p.email = u.Email
 	}
 	if fields&FEmail2 != 0 && u.Email2 != p.email2 {
 		phys.Audit(storer, "%s:: email2 = %q", context, u.Email2)
+		p.history(storer, "email2", p.email2, u.Email2)
 		p.email2 = u.Email2
 	}
 	if fields&FCellPhone != 0 && u.CellPhone != p.cellPhone {
 		phys.Audit(storer, "%s:: cellPhone = %q", context, u.CellPhone)
+		p.history(storer, "cellPhone", p.cellPhone, u.CellPhone)
 		p.cellPhone = u.CellPhone
 	}
 	if fields&FHomePhone != 0 && u.HomePhone != p.homePhone {
 		phys.Audit(storer, "%s:: homePhone = %q", context, u.HomePhone)
+		p.history(storer, "homePhone", p.homePhone, u.HomePhone)
 		p.homePhone = u.HomePhone
 	}
 	if fields&FWorkPhone != 0 && u.WorkPhone != p.workPhone {
 		phys.Audit(storer, "%s:: workPhone = %q", context, u.WorkPhone)
+		p.history(storer, "workPhone", p.workPhone, u.WorkPhone)
 		p.workPhone = u.WorkPhone
 	}
 	if fields&FPassword != 0 && u.Password != p.password {
 		phys.Audit(storer, "%s:: password = %q", context, u.Password)
+		p.history(storer, "password", "", "")
 		p.password = u.Password
 	}
 	if fields&FBadLoginCount != 0 && u.BadLoginCount != p.badLoginCount {
 		phys.Audit(storer, "%s:: badLoginCount = %d", context, u.BadLoginCount)
+		p.history(storer, "badLoginCount", strconv.Itoa(int(p.badLoginCount)), strconv.Itoa(int(u.BadLoginCount)))
 		p.badLoginCount = u.BadLoginCount
 	}
 	if fields&FBadLoginTime != 0 && u.BadLoginTime != p.badLoginTime {
+		p.history(storer, "badLoginTime", historyTime(p.badLoginTime, badLoginTimeFormat), historyTime(u.BadLoginTime, badLoginTimeFormat))
 		if u.BadLoginTime.IsZero() {
 			phys.Audit(storer, "%s:: badLoginTime = (zero)", context)
 			p.badLoginTime = u.BadLoginTime
@@ -556,9 +602,11 @@ func (p *Person) auditAndUpdate(storer phys.Storer, u *Updater, fields Fields, c
 	}
 	if fields&FPWResetToken != 0 && u.PWResetToken != p.pwresetToken {
 		phys.Audit(storer, "%s:: pwresetToken = %q", context, u.PWResetToken)
+		p.history(storer, "pwresetToken", "", "")
 		p.pwresetToken = u.PWResetToken
 	}
 	if fields&FPWResetTime != 0 && u.PWResetTime != p.pwresetTime {
+		p.history(storer, "pwresetTime", historyTime(p.pwresetTime, pwresetTimeFormat), historyTime(u.PWResetTime, pwresetTimeFormat))
 		if u.PWResetTime.IsZero() {
 			phys.Audit(storer, "%s:: pwresetTime = (zero)", context)
 			p.pwresetTime = u.PWResetTime
@@ -569,73 +617,84 @@ func (p *Person) auditAndUpdate(storer phys.Storer, u *Updater, fields Fields, c
 	}
 	if fields&FUnsubscribeToken != 0 && u.UnsubscribeToken != p.unsubscribeToken {
 		phys.Audit(storer, "%s:: unsubscribeToken = %q", context, u.UnsubscribeToken)
+		p.history(storer, "unsubscribeToken", "", "")
 		p.unsubscribeToken = u.UnsubscribeToken
 	}
 	if fields&FHoursToken != 0 && u.HoursToken != p.hoursToken {
 		phys.Audit(storer, "%s:: hoursToken = %q", context, u.HoursToken)
+		p.history(storer, "hoursToken", "", "")
 		p.hoursToken = u.HoursToken
 	}
 	if fields&FIdentification != 0 && u.Identification != p.identification {
 		phys.Audit(storer, "%s:: identification = 0x%x", context, u.Identification)
+		p.history(storer, "identification", fmt.Sprintf("0x%x", p.identification), fmt.Sprintf("0x%x", u.Identification))
 		p.identification = u.Identification
 	}
 	if fields&FBirthdate != 0 && u.Birthdate != p.birthdate {
 		phys.Audit(storer, "%s:: birthdate = %q", context, u.Birthdate)
+		p.history(storer, "birthdate", p.birthdate, u.Birthdate)
 		p.birthdate = u.Birthdate
 	}
 	if fields&FFlags != 0 && u.Flags != p.flags {
 		phys.Audit(storer, "%s:: flags = 0x%x", context, u.Flags)
+		p.history(storer, "flags", fmt.Sprintf("0x%x", p.flags), fmt.Sprintf("0x%x", u.Flags))
 		p.flags = u.Flags
 	}
+	if fields&FMedicalNotes != 0 && u.MedicalNotes != p.medicalNotes {
+		phys.Audit(storer, "%s:: medicalNotes = %q", context, u.MedicalNotes)
+		p.history(storer, "medicalNotes", p.medicalNotes, u.MedicalNotes)
+		p.medicalNotes = u.MedicalNotes
+	}
 	if fields&FAddresses != 0 {
-		auditAndUpdateAddress(storer, &p.addresses.Home, &u.Addresses.Home, context, "addresses.home")
-		auditAndUpdateAddress(storer, &p.addresses.Work, &u.Addresses.Work, context, "addresses.work")
-		auditAndUpdateAddress(storer, &p.addresses.Mail, &u.Addresses.Mail, context, "addresses.mail")
+		p.auditAndUpdateAddress(storer, &p.addresses.Home, &u.Addresses.Home, context, "addresses.home")
+		p.auditAndUpdateAddress(storer, &p.addresses.Work, &u.Addresses.Work, context, "addresses.work")
+		p.auditAndUpdateAddress(storer, &p.addresses.Mail, &u.Addresses.Mail, context, "addresses.mail")
 	}
 	if fields&FBGChecks != 0 {
-		auditAndUpdateBGCheck(storer, &p.bgChecks.DOJ, &u.BGChecks.DOJ, context, "bgChecks.DOJ")
-		auditAndUpdateBGCheck(storer, &p.bgChecks.FBI, &u.BGChecks.FBI, context, "bgChecks.FBI")
-		auditAndUpdateBGCheck(storer, &p.bgChecks.PHS, &u.BGChecks.PHS, context, "bgChecks.PHS")
+		p.auditAndUpdateBGCheck(storer, &p.bgChecks.DOJ, &u.BGChecks.DOJ, context, "bgChecks.DOJ")
+		p.auditAndUpdateBGCheck(storer, &p.bgChecks.FBI, &u.BGChecks.FBI, context, "bgChecks.FBI")
+		p.auditAndUpdateBGCheck(storer, &p.bgChecks.PHS, &u.BGChecks.PHS, context, "bgChecks.PHS")
 	}
 	if fields&FDSWRegistrations != 0 {
-		auditAndUpdateDSWRegistration(storer, &p.dswRegistrations.CERT, &u.DSWRegistrations.CERT, context, "dswRegistrations.CERT")
-		auditAndUpdateDSWRegistration(storer, &p.dswRegistrations.Communications, &u.DSWRegistrations.Communications, context, "dswRegistrations.Communications")
+		p.auditAndUpdateDSWRegistration(storer, &p.dswRegistrations.CERT, &u.DSWRegistrations.CERT, context, "dswRegistrations.CERT")
+		p.auditAndUpdateDSWRegistration(storer, &p.dswRegistrations.Communications, &u.DSWRegistrations.Communications, context, "dswRegistrations.Communications")
 	}
 	if fields&FNotes != 0 {
 		var i int
 		for i = 0; i < len(p.notes) && i < len(u.Notes); i++ {
-			auditAndUpdateNote(storer, p.notes[i], u.Notes[i], context, fmt.Sprintf("notes[%d]", i))
+			p.auditAndUpdateNote(storer, p.notes[i], u.Notes[i], context, fmt.Sprintf("notes[%d]", i))
 		}
 		for ; i < len(p.notes); i++ {
-			auditAndUpdateNote(storer, p.notes[i], new(Note), context, fmt.Sprintf("DELETE notes[%d]", i))
+			p.auditAndUpdateNote(storer, p.notes[i], new(Note), context, fmt.Sprintf("DELETE notes[%d]", i))
 		}
 		for ; i < len(u.Notes); i++ {
 			p.notes = append(p.notes, new(Note))
-			auditAndUpdateNote(storer, p.notes[i], u.Notes[i], context, fmt.Sprintf("ADD notes[%d]", i))
+			p.auditAndUpdateNote(storer, p.notes[i], u.Notes[i], context, fmt.Sprintf("ADD notes[%d]", i))
 		}
 		p.notes = p.notes[:len(u.Notes)]
 	}
 	if fields&FEmContacts != 0 {
 		var i int
 		for i = 0; i < len(p.emContacts) && i < len(u.EmContacts); i++ {
-			auditAndUpdateEmContact(storer, p.emContacts[i], u.EmContacts[i], context, fmt.Sprintf("emContacts[%d]", i))
+			p.auditAndUpdateEmContact(storer, p.emContacts[i], u.EmContacts[i], context, fmt.Sprintf("emContacts[%d]", i))
 		}
 		for ; i < len(p.emContacts); i++ {
-			auditAndUpdateEmContact(storer, p.emContacts[i], new(EmContact), context, fmt.Sprintf("DELETE emContacts[%d]", i))
+			p.auditAndUpdateEmContact(storer, p.emContacts[i], new(EmContact), context, fmt.Sprintf("DELETE emContacts[%d]", i))
 		}
 		for ; i < len(u.EmContacts); i++ {
 			p.emContacts = append(p.emContacts, new(EmContact))
-			auditAndUpdateEmContact(storer, p.emContacts[i], u.EmContacts[i], context, fmt.Sprintf("ADD emContacts[%d]", i))
+			p.auditAndUpdateEmContact(storer, p.emContacts[i], u.EmContacts[i], context, fmt.Sprintf("ADD emContacts[%d]", i))
 		}
 		p.emContacts = p.emContacts[:len(u.EmContacts)]
 	}
 }
-func auditAndUpdateAddress(storer phys.Storer, paddr, uaddr **Address, context, addrtype string) {
+func (p *Person) auditAndUpdateAddress(storer phys.Storer, paddr, uaddr **Address, context, addrtype string) {
 	if *paddr == nil && *uaddr == nil {
 		return
 	}
 	if *uaddr == nil {
 		phys.Audit(storer, "%s:: DELETE %s", context, addrtype)
+		p.history(storer, "DELETE "+addrtype, (*paddr).Address, "")
 		*paddr = nil
 		return
 	}
@@ -645,31 +704,37 @@ func auditAndUpdateAddress(storer phys.Storer, paddr, uaddr **Address, context,
 	}
 	if (*paddr).SameAsHome != (*uaddr).SameAsHome {
 		phys.Audit(storer, "%s:: %s:: sameAsHome = %v", context, addrtype, (*uaddr).SameAsHome)
+		p.history(storer, addrtype+":: sameAsHome", strconv.FormatBool((*paddr).SameAsHome), strconv.FormatBool((*uaddr).SameAsHome))
 		(*paddr).SameAsHome = (*uaddr).SameAsHome
 	}
 	if (*paddr).Address != (*uaddr).Address {
 		phys.Audit(storer, "%s:: %s:: address = %q", context, addrtype, (*uaddr).Address)
+		p.history(storer, addrtype+":: address", (*paddr).Address, (*uaddr).Address)
 		(*paddr).Address = (*uaddr).Address
 	}
 	if (*paddr).Latitude != (*uaddr).Latitude {
 		phys.Audit(storer, "%s:: %s:: latitude = %f", context, addrtype, (*uaddr).Latitude)
+		p.history(storer, addrtype+":: latitude", fmt.Sprintf("%f", (*paddr).Latitude), fmt.Sprintf("%f", (*uaddr).Latitude))
 		(*paddr).Latitude = (*uaddr).Latitude
 	}
 	if (*paddr).Longitude != (*uaddr).Longitude {
 		phys.Audit(storer, "%s:: %s:: longitude = %f", context, addrtype, (*uaddr).Longitude)
+		p.history(storer, addrtype+":: longitude", fmt.Sprintf("%f", (*paddr).Longitude), fmt.Sprintf("%f", (*uaddr).Longitude))
 		(*paddr).Longitude = (*uaddr).Longitude
 	}
 	if (*paddr).FireDistrict != (*uaddr).FireDistrict {
 		phys.Audit(storer, "%s:: %s:: fireDistrict = %d", context, addrtype, (*uaddr).FireDistrict)
+		p.history(storer, addrtype+":: fireDistrict", strconv.Itoa(int((*paddr).FireDistrict)), strconv.Itoa(int((*uaddr).FireDistrict)))
 		(*paddr).FireDistrict = (*uaddr).FireDistrict
 	}
 }
-func auditAndUpdateBGCheck(storer phys.Storer, paddr, uaddr **BGCheck, context, checktype string) {
+func (p *Person) auditAndUpdateBGCheck(storer phys.Storer, paddr, uaddr **BGCheck, context, checktype string) {
 	if *paddr == nil && *uaddr == nil {
 		return
 	}
 	if *uaddr == nil {
 		phys.Audit(storer, "%s:: DELETE %s", context, checktype)
+		p.history(storer, "DELETE "+checktype, historyTime((*paddr).Cleared, bgCheckDateFormat), "")
 		*paddr = nil
 		return
 	}
@@ -679,23 +744,27 @@ func auditAndUpdateBGCheck(storer phys.Storer, paddr, uaddr **BGCheck, context,
 	}
 	if (*paddr).Cleared != (*uaddr).Cleared {
 		phys.Audit(storer, "%s:: %s:: cleared = %q", context, checktype, (*uaddr).Cleared)
+		p.history(storer, checktype+":: cleared", historyTime((*paddr).Cleared, bgCheckDateFormat), historyTime((*uaddr).Cleared, bgCheckDateFormat))
 		(*paddr).Cleared = (*uaddr).Cleared
 	}
 	if (*paddr).NLI != (*uaddr).NLI {
 		phys.Audit(storer, "%s:: %s:: nli = %q", context, checktype, (*uaddr).NLI)
+		p.history(storer, checktype+":: nli", historyTime((*paddr).NLI, bgCheckDateFormat), historyTime((*uaddr).NLI, bgCheckDateFormat))
 		(*paddr).NLI = (*uaddr).NLI
 	}
 	if (*paddr).Assumed != (*uaddr).Assumed {
 		phys.Audit(storer, "%s:: %s:: assumed = %v", context, checktype, (*uaddr).Assumed)
+		p.history(storer, checktype+":: assumed", strconv.FormatBool((*paddr).Assumed), strconv.FormatBool((*uaddr).Assumed))
 		(*paddr).Assumed = (*uaddr).Assumed
 	}
 }
-func auditAndUpdateDSWRegistration(storer phys.Storer, paddr, uaddr **DSWRegistration, context, class string) {
+func (p *Person) auditAndUpdateDSWRegistration(storer phys.Storer, paddr, uaddr **DSWRegistration, context, class string) {
 	if *paddr == nil && *uaddr == nil {
 		return
 	}
 	if *uaddr == nil {
 		phys.Audit(storer, "%s:: DELETE %s", context, class)
+		p.history(storer, "DELETE "+class, historyTime((*paddr).Registered, dswRegDateFormat), "")
 		*paddr = nil
 		return
 	}
@@ -705,42 +774,51 @@ func auditAndUpdateDSWRegistration(storer phys.Storer, paddr, uaddr **DSWRegistr
 	}
 	if (*paddr).Registered != (*uaddr).Registered {
 		phys.Audit(storer, "%s:: %s:: cleared = %q", context, class, (*uaddr).Registered)
+		p.history(storer, class+":: registered", historyTime((*paddr).Registered, dswRegDateFormat), historyTime((*uaddr).Registered, dswRegDateFormat))
 		(*paddr).Registered = (*uaddr).Registered
 	}
 	if (*paddr).Expiration != (*uaddr).Expiration {
 		phys.Audit(storer, "%s:: %s:: nli = %q", context, class, (*uaddr).Expiration)
+		p.history(storer, class+":: expiration", historyTime((*paddr).Expiration, dswRegDateFormat), historyTime((*uaddr).Expiration, dswRegDateFormat))
 		(*paddr).Expiration = (*uaddr).Expiration
 	}
 }
-func auditAndUpdateNote(storer phys.Storer, pnote, unote *Note, context, label string) {
+func (p *Person) auditAndUpdateNote(storer phys.Storer, pnote, unote *Note, context, label string) {
 	if pnote.Note != unote.Note {
 		phys.Audit(storer, "%s:: %s:: note = %q", context, label, unote.Note)
+		p.history(storer, label+":: note", pnote.Note, unote.Note)
 		pnote.Note = unote.Note
 	}
 	if pnote.Date != unote.Date {
 		phys.Audit(storer, "%s:: %s:: date = %q", context, label, unote.Date)
+		p.history(storer, label+":: date", historyTime(pnote.Date, noteDateFormat), historyTime(unote.Date, noteDateFormat))
 		pnote.Date = unote.Date
 	}
 	if pnote.Visibility != unote.Visibility {
 		phys.Audit(storer, "%s:: %s:: visibility = %s [%d]", context, label, unote.Visibility, unote.Visibility)
+		p.history(storer, label+":: visibility", pnote.Visibility.String(), unote.Visibility.String())
 		pnote.Visibility = unote.Visibility
 	}
 }
-func auditAndUpdateEmContact(storer phys.Storer, pec, uec *EmContact, context, label string) {
+func (p *Person) auditAndUpdateEmContact(storer phys.Storer, pec, uec *EmContact, context, label string) {
 	if pec.Name != uec.Name {
 		phys.Audit(storer, "%s:: %s:: name = %q", context, label, uec.Name)
+		p.history(storer, label+":: name", pec.Name, uec.Name)
 		pec.Name = uec.Name
 	}
 	if pec.HomePhone != uec.HomePhone {
 		phys.Audit(storer, "%s:: %s:: homePhone = %q", context, label, uec.HomePhone)
+		p.history(storer, label+":: homePhone", pec.HomePhone, uec.HomePhone)
 		pec.HomePhone = uec.HomePhone
 	}
 	if pec.CellPhone != uec.CellPhone {
 		phys.Audit(storer, "%s:: %s:: cellPhone = %q", context, label, uec.CellPhone)
+		p.history(storer, label+":: cellPhone", pec.CellPhone, uec.CellPhone)
 		pec.CellPhone = uec.CellPhone
 	}
 	if pec.Relationship != uec.Relationship {
 		phys.Audit(storer, "%s:: %s:: relationship = %q", context, label, uec.Relationship)
+		p.history(storer, label+":: relationship", pec.Relationship, uec.Relationship)
 		pec.Relationship = uec.Relationship
 	}
 }
@@ -820,7 +898,9 @@ func (u *Updater) DuplicateVolgisticsID(storer phys.Storer) (found bool) {
 }
 
 // We intentionally do not have a Delete method for Person objects.  Person
-// objects should never be deleted.
+// objects should never be deleted, except when merging duplicates (see the
+// personmerge package) or hard-deleting archived people (see the personarchive
+// package).
 
 // ClearAllHoursReminders turns off the HoursReminder flag on all people.
 func ClearAllHoursReminders(storer phys.Storer) {
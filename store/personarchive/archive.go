@@ -0,0 +1,245 @@
+// Package personarchive handles the archival of inactive people.  Archiving a
+// person scrubs their contact information, addresses, birthdate, and emergency
+// contacts, while retaining their name and their history of hours, attendance,
+// and class registrations for reporting.  Archived people with no such history
+// can be deleted entirely.
+package personarchive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+// CandidateFields are the fields of the people returned by Candidates and
+// Archived.
+const CandidateFields = person.FID | person.FInformalName | person.FSortName | person.FEmail | person.FCellPhone | person.FFlags
+
+// ScrubFields are the fields that must be fetched for a person passed to
+// Scrub.
+const ScrubFields = person.FID | person.FInformalName | scrubbedFields
+
+// scrubbedFields are the fields cleared by Scrub.
+const scrubbedFields = person.FEmail | person.FEmail2 | person.FCellPhone | person.FHomePhone | person.FWorkPhone | person.FPassword | person.FPWResetToken | person.FPWResetTime | person.FBirthdate | person.FFlags | person.FMedicalNotes | person.FAddresses | person.FEmContacts
+
+// lastActiveSQL is a subquery that yields the dates of all recorded activity
+// of each person: event attendance, shift signups, class registrations, text
+// messages sent, notes, background checks, DSW registrations, logins (other
+// than impersonation sessions), and API token use.
+// Dates in the various tables have different precisions, but they all start
+// with YYYY-MM-DD, so they compare correctly.
+const lastActiveSQL = `SELECT tp.person AS person, e.start AS date FROM task_person tp, task t, event e WHERE tp.task=t.id AND t.event=e.id
+UNION ALL SELECT sp.person, s.start FROM shift_person sp, shift s WHERE sp.shift=s.id
+UNION ALL SELECT cr.person, c.start FROM classreg cr, class c WHERE cr.class=c.id
+UNION ALL SELECT cr.registered_by, c.start FROM classreg cr, class c WHERE cr.class=c.id
+UNION ALL SELECT sender, timestamp FROM textmsg
+UNION ALL SELECT person, date FROM person_note
+UNION ALL SELECT person, cleared FROM person_bgcheck
+UNION ALL SELECT person, registered FROM person_dswreg
+UNION ALL SELECT person, expires FROM session WHERE impersonator IS NULL
+UNION ALL SELECT person, last_used FROM api_token WHERE last_used IS NOT NULL`
+
+var candidatesSQL string
+
+// Candidates calls fn for each person who is a candidate for archival: someone
+// who is not archived, holds no roles, has not been marked for retention past
+// today, and has no recorded activity since cutoff.  lastActive is the date of
+// their most recent activity (in YYYY-MM-DD format), or an empty string if
+// they have none.  The people are returned in order by sort name, and have
+// CandidateFields.
+func Candidates(storer phys.Storer, cutoff, today time.Time, fn func(p *person.Person, lastActive string)) {
+	if candidatesSQL == "" {
+		var sb strings.Builder
+		sb.WriteString("SELECT MAX(a.date), ")
+		person.ColumnList(&sb, CandidateFields)
+		sb.WriteString(" FROM person p LEFT JOIN (")
+		sb.WriteString(lastActiveSQL)
+		fmt.Fprintf(&sb, ") a ON a.person=p.id WHERE p.id!=%d AND NOT p.flags&%d", person.AdminID, person.Archived)
+		sb.WriteString(" AND NOT EXISTS (SELECT 1 FROM person_role pr WHERE pr.person=p.id)")
+		sb.WriteString(" AND NOT EXISTS (SELECT 1 FROM person_retain rt WHERE rt.person=p.id AND rt.until>=?2)")
+		sb.WriteString(" GROUP BY p.id HAVING MAX(a.date) IS NULL OR MAX(a.date)<?1 ORDER BY p.sort_name")
+		candidatesSQL = sb.String()
+	}
+	phys.SQL(storer, candidatesSQL, func(stmt *phys.Stmt) {
+		var p person.Person
+
+		stmt.BindText(cutoff.Format("2006-01-02"))
+		stmt.BindText(today.Format("2006-01-02"))
+		for stmt.Step() {
+			var lastActive = stmt.ColumnText()
+			if len(lastActive) > 10 {
+				lastActive = lastActive[:10]
+			}
+			p.Scan(stmt, CandidateFields)
+			fn(&p, lastActive)
+		}
+	})
+}
+
+var archivedSQL string
+
+// Archived calls fn for each archived person, in order by sort name.  The
+// people have CandidateFields.
+func Archived(storer phys.Storer, fn func(p *person.Person)) {
+	if archivedSQL == "" {
+		var sb strings.Builder
+		sb.WriteString("SELECT ")
+		person.ColumnList(&sb, CandidateFields)
+		fmt.Fprintf(&sb, " FROM person p WHERE p.flags&%d ORDER BY p.sort_name", person.Archived)
+		archivedSQL = sb.String()
+	}
+	phys.SQL(storer, archivedSQL, func(stmt *phys.Stmt) {
+		var p person.Person
+
+		for stmt.Step() {
+			p.Scan(stmt, CandidateFields)
+			fn(&p)
+		}
+	})
+}
+
+// Retain marks the specified person to be retained, i.e., not proposed for
+// archival, through the specified date.  The person must have FID and
+// FInformalName.
+func Retain(storer phys.Storer, p *person.Person, until time.Time) {
+	phys.SQL(storer, `INSERT OR REPLACE INTO person_retain (person, until) VALUES (?,?)`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(p.ID()))
+		stmt.BindText(until.Format("2006-01-02"))
+		stmt.Step()
+	})
+	phys.Audit(storer, "Person %q [%d]:: retain until = %s", p.InformalName(), p.ID(), until.Format("2006-01-02"))
+	phys.History(storer, "Person", int(p.ID()), "retain until", "", until.Format("2006-01-02"))
+}
+
+// scrubSQL lists the statements that remove personal data about the person
+// being archived from tables other than person.  Each takes the person ID as
+// its only parameter.
+var scrubSQL = []string{
+	`DELETE FROM api_token WHERE person=?`,
+	`UPDATE classreg SET email=NULL, cell_phone=NULL WHERE person=?`,
+	`DELETE FROM list_person WHERE person=?`,
+	`DELETE FROM login_link WHERE person=?`,
+	`DELETE FROM person_availability WHERE person=?`,
+	`DELETE FROM person_blackout WHERE person=?`,
+	`DELETE FROM person_passkey WHERE person=?`,
+	`DELETE FROM person_retain WHERE person=?`,
+	`DELETE FROM person_totp WHERE person=?`,
+	`DELETE FROM session WHERE person=?`,
+}
+
+const scrubHistorySQL = `UPDATE history SET old=NULL, new=NULL WHERE etype='Person' AND eid=?`
+
+// Scrub archives the specified person, removing their contact information,
+// addresses, birthdate, emergency contacts, medical notes, photo, password,
+// passkeys, API tokens, sign-in links, two-factor enrollment, list
+// subscriptions, and availability, along with the values of all changes in
+// their history.  The person must have ScrubFields.
+func Scrub(storer phys.Storer, p *person.Person) {
+	phys.Audit(storer, "Person %q [%d]:: ARCHIVE", p.InformalName(), p.ID())
+	phys.History(storer, "Person", int(p.ID()), "ARCHIVE", "", "")
+	for _, sql := range scrubSQL {
+		phys.SQL(storer, sql, func(stmt *phys.Stmt) {
+			stmt.BindInt(int(p.ID()))
+			stmt.Step()
+		})
+	}
+	if p.Flags()&person.HasPhoto != 0 {
+		p.RemovePhoto(storer)
+	}
+	u := p.Updater()
+	u.Email, u.Email2 = "", ""
+	u.CellPhone, u.HomePhone, u.WorkPhone = "", "", ""
+	u.Password, u.PWResetToken, u.PWResetTime = "", "", time.Time{}
+	u.Birthdate = ""
+	u.Addresses = person.Addresses{}
+	u.EmContacts = nil
+	u.MedicalNotes = ""
+	u.Flags = (u.Flags | person.Archived) &^ person.HoursReminder
+	p.Update(storer, u, scrubbedFields)
+	// The history values are cleared last, since the changes above record
+	// the removed data as old values.
+	phys.SQL(storer, scrubHistorySQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(p.ID()))
+		stmt.Step()
+	})
+}
+
+const deleteBlockersSQL = `SELECT (SELECT COUNT(*) FROM task_person WHERE person=?1), (SELECT COUNT(*) FROM textmsg WHERE sender=?1), (SELECT COUNT(*) FROM classreg WHERE person=?1), (SELECT COUNT(*) FROM classreg WHERE registered_by=?1 AND person IS NULL), (SELECT COUNT(*) FROM certificate WHERE person=?1)`
+
+// DeleteBlockers returns a list of reasons why the specified person cannot be
+// hard-deleted, or nil if they can be.  Only archived people can be deleted,
+// and then only if they have no history that reports depend on.  The person
+// must have FID and FFlags.
+func DeleteBlockers(storer phys.Storer, p *person.Person) (reasons []string) {
+	if p.Flags()&person.Archived == 0 {
+		reasons = append(reasons, "not archived")
+	}
+	if p.ID() == person.AdminID {
+		reasons = append(reasons, "admin account")
+	}
+	phys.SQL(storer, deleteBlockersSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(p.ID()))
+		if stmt.Step() {
+			if count := stmt.ColumnInt(); count != 0 {
+				reasons = append(reasons, fmt.Sprintf("%d attendance or hours records", count))
+			}
+			if count := stmt.ColumnInt(); count != 0 {
+				reasons = append(reasons, fmt.Sprintf("sent %d text messages", count))
+			}
+			if count := stmt.ColumnInt(); count != 0 {
+				reasons = append(reasons, fmt.Sprintf("%d class registrations", count))
+			}
+			if count := stmt.ColumnInt(); count != 0 {
+				reasons = append(reasons, fmt.Sprintf("registered %d people without person records for classes", count))
+			}
+			if count := stmt.ColumnInt(); count != 0 {
+				reasons = append(reasons, fmt.Sprintf("%d issued certificates", count))
+			}
+		}
+	})
+	return reasons
+}
+
+// deleteSQL lists the statements that remove the references to a person being
+// deleted that do not cascade.  Each takes the person ID as its only
+// parameter.  Class registrations made by the person for someone else are
+// reassigned to that someone else.  (The person's own registrations, and those
+// made for people without person records, block the deletion, since the class
+// and referral reports depend on them.)
+var deleteSQL = []string{
+	`DELETE FROM shift_person WHERE person=?1`,
+	`DELETE FROM textmsg_recipient WHERE recipient=?1`,
+	`DELETE FROM textmsg_reply WHERE recipient=?1`,
+	`UPDATE classreg SET registered_by=person WHERE registered_by=?1 AND person IS NOT NULL AND person!=?1`,
+	`DELETE FROM history WHERE etype='Person' AND eid=?1`,
+}
+
+// Delete deletes the specified person entirely.  It panics if DeleteBlockers
+// would return any reasons not to.  The person must have FID, FInformalName,
+// and FFlags.
+func Delete(storer phys.Storer, p *person.Person) {
+	if reasons := DeleteBlockers(storer, p); len(reasons) != 0 {
+		panic(fmt.Sprintf("can't delete person %d: %v", p.ID(), reasons))
+	}
+	phys.Audit(storer, "DELETE Person %q [%d]", p.InformalName(), p.ID())
+	for _, sql := range deleteSQL {
+		phys.SQL(storer, sql, func(stmt *phys.Stmt) {
+			stmt.BindInt(int(p.ID()))
+			stmt.Step()
+		})
+	}
+	phys.SQL(storer, `DELETE FROM person WHERE id=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(p.ID()))
+		stmt.Step()
+	})
+	// Photos are stored as files rather than rows, so they are removed
+	// separately, once the transaction has committed.
+	if p.Flags()&person.HasPhoto != 0 {
+		id := p.ID()
+		phys.OnCommit(storer, func() { person.DeletePhotoFile(id) })
+	}
+	phys.Unindex(storer, p)
+}
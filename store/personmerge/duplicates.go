@@ -0,0 +1,185 @@
+// Package personmerge finds probable duplicate person records and merges them.
+package personmerge
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+// Duplicate is a pair of person records that are probably duplicates of each
+// other.
+type Duplicate struct {
+	// A and B are the two person records.  A has the lower ID.
+	A, B *person.Person
+	// Reasons is the list of reasons why the records are thought to be
+	// duplicates, e.g. "same email".
+	Reasons []string
+}
+
+// DuplicateFields are the fields that are fetched for the person records in a
+// Duplicate.
+const DuplicateFields = person.FID | person.FInformalName | person.FSortName | person.FEmail | person.FEmail2 | person.FCellPhone | person.FHomePhone
+
+// FindDuplicates returns the list of probable duplicate person records: pairs
+// of people with the same email address, the same phone number, or the same
+// name after normalization.
+func FindDuplicates(storer phys.Storer) (dups []*Duplicate) {
+	var (
+		people = make(map[person.ID]*person.Person)
+		keys   = make(map[string][]person.ID)
+		pairs  = make(map[[2]person.ID]*Duplicate)
+	)
+	person.All(storer, DuplicateFields, func(p *person.Person) {
+		if p.ID() == person.AdminID {
+			return
+		}
+		clone := *p
+		people[p.ID()] = &clone
+		addKey := func(reason, value string) {
+			if value == "" {
+				return
+			}
+			key := reason + "\000" + value
+			if !slices.Contains(keys[key], p.ID()) {
+				keys[key] = append(keys[key], p.ID())
+			}
+		}
+		addKey("same email", strings.ToLower(p.Email()))
+		addKey("same email", strings.ToLower(p.Email2()))
+		addKey("same phone", digits(p.CellPhone()))
+		addKey("same phone", digits(p.HomePhone()))
+		addKey("same name", normalizeName(p.SortName()))
+	})
+	for key, ids := range keys {
+		reason, _, _ := strings.Cut(key, "\000")
+		slices.Sort(ids)
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				dup := pairs[[2]person.ID{a, b}]
+				if dup == nil {
+					dup = &Duplicate{A: people[a], B: people[b]}
+					pairs[[2]person.ID{a, b}] = dup
+					dups = append(dups, dup)
+				}
+				if !slices.Contains(dup.Reasons, reason) {
+					dup.Reasons = append(dup.Reasons, reason)
+				}
+			}
+		}
+	}
+	for _, dup := range dups {
+		slices.Sort(dup.Reasons)
+	}
+	slices.SortFunc(dups, func(a, b *Duplicate) int {
+		if c := strings.Compare(a.A.SortName(), b.A.SortName()); c != 0 {
+			return c
+		}
+		return int(a.B.ID()) - int(b.B.ID())
+	})
+	return dups
+}
+
+// Match is an existing person record that probably describes the same person
+// as some other data, such as a class registration.
+type Match struct {
+	// Person is the existing person record.  It has DuplicateFields.
+	Person *person.Person
+	// Reasons is the list of reasons why the record is thought to match,
+	// e.g. "same email".
+	Reasons []string
+}
+
+// Matcher finds existing person records that match supplied data.
+type Matcher struct {
+	people map[person.ID]*person.Person
+	keys   map[string][]person.ID
+}
+
+// NewMatcher returns a Matcher for the person records currently in the
+// database.
+func NewMatcher(storer phys.Storer) (m *Matcher) {
+	m = &Matcher{people: make(map[person.ID]*person.Person), keys: make(map[string][]person.ID)}
+	person.All(storer, DuplicateFields, func(p *person.Person) {
+		if p.ID() == person.AdminID {
+			return
+		}
+		clone := *p
+		m.people[p.ID()] = &clone
+		for _, key := range matchKeys(p.SortName(), p.Email(), p.CellPhone()) {
+			m.keys[key] = append(m.keys[key], p.ID())
+		}
+		for _, key := range matchKeys("", p.Email2(), p.HomePhone()) {
+			m.keys[key] = append(m.keys[key], p.ID())
+		}
+	})
+	return m
+}
+
+// Find returns the existing person records that probably describe the person
+// with the specified name, email address, and phone number:  those with the
+// same email address, the same phone number, or the same name after
+// normalization.  The name should be in sort order ("Last, First").
+func (m *Matcher) Find(sortName, email, phone string) (matches []*Match) {
+	var byID = make(map[person.ID]*Match)
+
+	for _, key := range matchKeys(sortName, email, phone) {
+		reason, _, _ := strings.Cut(key, "\000")
+		for _, pid := range m.keys[key] {
+			match := byID[pid]
+			if match == nil {
+				match = &Match{Person: m.people[pid]}
+				byID[pid] = match
+				matches = append(matches, match)
+			}
+			if !slices.Contains(match.Reasons, reason) {
+				match.Reasons = append(match.Reasons, reason)
+			}
+		}
+	}
+	slices.SortFunc(matches, func(a, b *Match) int {
+		if c := len(b.Reasons) - len(a.Reasons); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Person.SortName(), b.Person.SortName())
+	})
+	return matches
+}
+
+// matchKeys returns the keys under which a person with the specified name,
+// email address, and phone number is indexed.
+func matchKeys(sortName, email, phone string) (keys []string) {
+	if email != "" {
+		keys = append(keys, "same email\000"+strings.ToLower(email))
+	}
+	if phone = digits(phone); phone != "" {
+		keys = append(keys, "same phone\000"+phone)
+	}
+	if sortName = normalizeName(sortName); sortName != "" {
+		keys = append(keys, "same name\000"+sortName)
+	}
+	return keys
+}
+
+// digits returns only the digits of the supplied phone number.
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// normalizeName returns a normalized form of a name, in which case,
+// punctuation, and word order are ignored.
+func normalizeName(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	slices.Sort(words)
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,365 @@
+package personmerge
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"sunnyvaleserv.org/portal/store/availability"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/store/recalc"
+)
+
+// PersonFields are the fields that must be fetched for both people passed to
+// Prepare.
+const PersonFields = mergeFields | person.FID
+
+// mergeFields are the fields of the kept person that are updated by a merge.
+const mergeFields = person.FVolgisticsID | person.FInformalName | person.FFormalName | person.FSortName | person.FCallSign | person.FPronouns | person.FEmail | person.FEmail2 | person.FCellPhone | person.FHomePhone | person.FWorkPhone | person.FPassword | person.FBadLoginCount | person.FBadLoginTime | person.FPWResetToken | person.FPWResetTime | person.FUnsubscribeToken | person.FHoursToken | person.FIdentification | person.FBirthdate | person.FFlags | person.FMedicalNotes | person.FAddresses | person.FBGChecks | person.FDSWRegistrations | person.FNotes | person.FEmContacts
+
+// ConflictFields are the fields that can have conflicts needing resolution.
+var ConflictFields = []person.Fields{person.FInformalName, person.FFormalName, person.FSortName, person.FCallSign, person.FPronouns, person.FEmail, person.FEmail2, person.FCellPhone, person.FHomePhone, person.FWorkPhone, person.FVolgisticsID, person.FBirthdate, person.FPassword, person.FAddresses}
+
+// Merge describes a pending merge of one person record (Drop) into another
+// (Keep).
+type Merge struct {
+	// Keep is the person record that will remain after the merge.
+	Keep *person.Person
+	// Drop is the person record that will be merged into Keep and then
+	// removed.
+	Drop *person.Person
+	// Conflicts is the set of fields for which Keep and Drop have
+	// different, non-empty values.  Each must be resolved by a call to
+	// Resolve; if it is not, the value from Keep is used.
+	Conflicts person.Fields
+	// merged holds the merged data for Keep.
+	merged *person.Updater
+	// drop holds the data from Drop.
+	drop *person.Updater
+}
+
+// Prepare prepares a merge of drop into keep.  It combines all data that can
+// be combined automatically, and identifies the fields with conflicts that
+// must be resolved.  Both people must have PersonFields.
+func Prepare(keep, drop *person.Person) (m *Merge) {
+	m = &Merge{Keep: keep, Drop: drop, merged: keep.Updater(), drop: drop.Updater()}
+	k, d := m.merged, m.drop
+	mergeString(&m.Conflicts, person.FInformalName, &k.InformalName, d.InformalName)
+	mergeString(&m.Conflicts, person.FFormalName, &k.FormalName, d.FormalName)
+	mergeString(&m.Conflicts, person.FSortName, &k.SortName, d.SortName)
+	mergeString(&m.Conflicts, person.FCallSign, &k.CallSign, d.CallSign)
+	mergeString(&m.Conflicts, person.FPronouns, &k.Pronouns, d.Pronouns)
+	mergeString(&m.Conflicts, person.FEmail, &k.Email, d.Email)
+	mergeString(&m.Conflicts, person.FEmail2, &k.Email2, d.Email2)
+	mergeString(&m.Conflicts, person.FCellPhone, &k.CellPhone, d.CellPhone)
+	mergeString(&m.Conflicts, person.FHomePhone, &k.HomePhone, d.HomePhone)
+	mergeString(&m.Conflicts, person.FWorkPhone, &k.WorkPhone, d.WorkPhone)
+	mergeString(&m.Conflicts, person.FBirthdate, &k.Birthdate, d.Birthdate)
+	mergeString(&m.Conflicts, person.FPassword, &k.Password, d.Password)
+	if k.Email2 == "" && k.Email != d.Email && d.Email != "" {
+		// Rather than lose the second email address entirely, move it
+		// to Email2.
+		k.Email2 = d.Email
+		m.Conflicts &^= person.FEmail2
+	}
+	if k.VolgisticsID == 0 {
+		k.VolgisticsID = d.VolgisticsID
+	} else if d.VolgisticsID != 0 && d.VolgisticsID != k.VolgisticsID {
+		m.Conflicts |= person.FVolgisticsID
+	}
+	if k.UnsubscribeToken == "" {
+		k.UnsubscribeToken = d.UnsubscribeToken
+	}
+	if k.HoursToken == "" {
+		k.HoursToken = d.HoursToken
+	}
+	if k.MedicalNotes == "" {
+		k.MedicalNotes = d.MedicalNotes
+	}
+	k.Identification |= d.Identification
+	k.Flags |= d.Flags
+	mergeAddress(&m.Conflicts, &k.Addresses.Home, d.Addresses.Home)
+	mergeAddress(&m.Conflicts, &k.Addresses.Work, d.Addresses.Work)
+	mergeAddress(&m.Conflicts, &k.Addresses.Mail, d.Addresses.Mail)
+	mergeBGCheck(&k.BGChecks.DOJ, d.BGChecks.DOJ)
+	mergeBGCheck(&k.BGChecks.FBI, d.BGChecks.FBI)
+	mergeBGCheck(&k.BGChecks.PHS, d.BGChecks.PHS)
+	mergeDSWRegistration(&k.DSWRegistrations.CERT, d.DSWRegistrations.CERT)
+	mergeDSWRegistration(&k.DSWRegistrations.Communications, d.DSWRegistrations.Communications)
+	k.Notes = append(k.Notes, d.Notes...)
+	slices.SortStableFunc(k.Notes, func(a, b *person.Note) int { return a.Date.Compare(b.Date) })
+	for _, ec := range d.EmContacts {
+		if !slices.ContainsFunc(k.EmContacts, func(kec *person.EmContact) bool { return strings.EqualFold(kec.Name, ec.Name) }) {
+			k.EmContacts = append(k.EmContacts, ec)
+		}
+	}
+	return m
+}
+
+func mergeString(conflicts *person.Fields, field person.Fields, keep *string, drop string) {
+	if *keep == "" {
+		*keep = drop
+	} else if drop != "" && drop != *keep {
+		*conflicts |= field
+	}
+}
+
+func mergeAddress(conflicts *person.Fields, keep **person.Address, drop *person.Address) {
+	if *keep == nil {
+		*keep = drop
+	} else if drop != nil && *drop != **keep {
+		*conflicts |= person.FAddresses
+	}
+}
+
+// mergeBGCheck keeps whichever background check is valid, or the more recent
+// one if both or neither are.
+func mergeBGCheck(keep **person.BGCheck, drop *person.BGCheck) {
+	switch {
+	case drop == nil:
+	case *keep == nil:
+		*keep = drop
+	case drop.Valid() && !(*keep).Valid():
+		*keep = drop
+	case drop.Valid() == (*keep).Valid() && drop.Cleared.After((*keep).Cleared):
+		*keep = drop
+	}
+}
+
+// mergeDSWRegistration keeps the more recent DSW registration.
+func mergeDSWRegistration(keep **person.DSWRegistration, drop *person.DSWRegistration) {
+	if drop != nil && (*keep == nil || drop.Registered.After((*keep).Registered)) {
+		*keep = drop
+	}
+}
+
+// Values returns the values of the specified conflicting field in Keep and
+// Drop, formatted for display.
+func (m *Merge) Values(field person.Fields) (keep, drop string) {
+	k, d := m.Keep.Updater(), m.drop
+	switch field {
+	case person.FInformalName:
+		return k.InformalName, d.InformalName
+	case person.FFormalName:
+		return k.FormalName, d.FormalName
+	case person.FSortName:
+		return k.SortName, d.SortName
+	case person.FCallSign:
+		return k.CallSign, d.CallSign
+	case person.FPronouns:
+		return k.Pronouns, d.Pronouns
+	case person.FEmail:
+		return k.Email, d.Email
+	case person.FEmail2:
+		return k.Email2, d.Email2
+	case person.FCellPhone:
+		return k.CellPhone, d.CellPhone
+	case person.FHomePhone:
+		return k.HomePhone, d.HomePhone
+	case person.FWorkPhone:
+		return k.WorkPhone, d.WorkPhone
+	case person.FVolgisticsID:
+		return fmt.Sprint(k.VolgisticsID), fmt.Sprint(d.VolgisticsID)
+	case person.FBirthdate:
+		return k.Birthdate, d.Birthdate
+	case person.FPassword:
+		return "(password of this record)", "(password of this record)"
+	case person.FAddresses:
+		return formatAddresses(k.Addresses), formatAddresses(d.Addresses)
+	}
+	panic("no such conflict field")
+}
+
+func formatAddresses(a person.Addresses) string {
+	var parts []string
+	if a.Home != nil {
+		parts = append(parts, "Home: "+a.Home.Address)
+	}
+	if a.Work != nil && !a.Work.SameAsHome {
+		parts = append(parts, "Work: "+a.Work.Address)
+	}
+	if a.Mail != nil && !a.Mail.SameAsHome {
+		parts = append(parts, "Mail: "+a.Mail.Address)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Resolve resolves the conflict in the specified field, using the value from
+// Drop if useDrop is true, and the value from Keep otherwise.
+func (m *Merge) Resolve(field person.Fields, useDrop bool) {
+	if m.Conflicts&field == 0 {
+		return
+	}
+	k, d := m.merged, m.drop
+	if !useDrop {
+		d = m.Keep.Updater()
+	}
+	switch field {
+	case person.FInformalName:
+		k.InformalName = d.InformalName
+	case person.FFormalName:
+		k.FormalName = d.FormalName
+	case person.FSortName:
+		k.SortName = d.SortName
+	case person.FCallSign:
+		k.CallSign = d.CallSign
+	case person.FPronouns:
+		k.Pronouns = d.Pronouns
+	case person.FEmail:
+		if useDrop && k.Email2 == d.Email {
+			// Prepare moved the dropped email address to Email2;
+			// swap them.
+			k.Email2 = k.Email
+		}
+		k.Email = d.Email
+	case person.FEmail2:
+		k.Email2 = d.Email2
+	case person.FCellPhone:
+		k.CellPhone = d.CellPhone
+	case person.FHomePhone:
+		k.HomePhone = d.HomePhone
+	case person.FWorkPhone:
+		k.WorkPhone = d.WorkPhone
+	case person.FVolgisticsID:
+		k.VolgisticsID = d.VolgisticsID
+	case person.FBirthdate:
+		k.Birthdate = d.Birthdate
+	case person.FPassword:
+		k.Password = d.Password
+	case person.FAddresses:
+		// Only the conflicting addresses are replaced; an address
+		// present in only one record was already merged.
+		if d.Addresses.Home != nil {
+			k.Addresses.Home = d.Addresses.Home
+		}
+		if d.Addresses.Work != nil {
+			k.Addresses.Work = d.Addresses.Work
+		}
+		if d.Addresses.Mail != nil {
+			k.Addresses.Mail = d.Addresses.Mail
+		}
+	}
+}
+
+// repointSQL lists the statements that move references to the dropped person
+// over to the kept person.  Each takes two parameters: the kept person ID and
+// the dropped person ID.  Where the kept person already has a conflicting row,
+// the kept person's row is retained (after merging in what can be merged), and
+// the dropped person's row is deleted.  (Losses reports the rows that will be
+// deleted that way and that can't be merged.)  Sessions and sign-in links of
+// the dropped person are not moved; they are deleted with the person.
+// Availability windows are merged separately, by mergeAvailability.
+var repointSQL = []string{
+	`INSERT INTO person_role (person, role, explicit) SELECT ?1, role, TRUE FROM person_role WHERE person=?2 AND explicit ON CONFLICT DO UPDATE SET explicit=TRUE`,
+	`UPDATE task_person AS k SET minutes=COALESCE(MAX(k.minutes, d.minutes), k.minutes, d.minutes), flags=k.flags|d.flags FROM task_person AS d WHERE k.person=?1 AND d.person=?2 AND d.task=k.task`,
+	`DELETE FROM task_person WHERE person=?2 AND task IN (SELECT task FROM task_person WHERE person=?1)`,
+	`UPDATE task_person SET person=?1 WHERE person=?2`,
+	`DELETE FROM shift_person WHERE person=?2 AND shift IN (SELECT shift FROM shift_person WHERE person=?1)`,
+	`UPDATE shift_person SET person=?1 WHERE person=?2`,
+	`UPDATE classreg SET person=?1 WHERE person=?2`,
+	`UPDATE classreg SET registered_by=?1 WHERE registered_by=?2`,
+	`DELETE FROM list_person WHERE person=?2 AND list IN (SELECT list FROM list_person WHERE person=?1)`,
+	`UPDATE list_person SET person=?1 WHERE person=?2`,
+	`UPDATE textmsg SET sender=?1 WHERE sender=?2`,
+	`DELETE FROM textmsg_recipient WHERE recipient=?2 AND textmsg IN (SELECT textmsg FROM textmsg_recipient WHERE recipient=?1)`,
+	`UPDATE textmsg_recipient SET recipient=?1 WHERE recipient=?2`,
+	`UPDATE textmsg_reply SET recipient=?1 WHERE recipient=?2`,
+	`DELETE FROM person_blackout WHERE person=?2 AND start IN (SELECT start FROM person_blackout WHERE person=?1)`,
+	`UPDATE person_blackout SET person=?1 WHERE person=?2`,
+	`UPDATE certificate SET class=NULL WHERE person=?2 AND class IN (SELECT class FROM certificate WHERE person=?1)`,
+	`UPDATE certificate SET person=?1 WHERE person=?2`,
+	`UPDATE person_passkey SET person=?1 WHERE person=?2`,
+	`UPDATE api_token SET person=?1 WHERE person=?2`,
+	`UPDATE person_totp SET person=?1 WHERE person=?2 AND NOT EXISTS (SELECT 1 FROM person_totp WHERE person=?1)`,
+	`UPDATE person_sso SET person=?1 WHERE person=?2 AND NOT EXISTS (SELECT 1 FROM person_sso WHERE person=?1)`,
+	`UPDATE person_verify SET person=?1 WHERE person=?2 AND NOT EXISTS (SELECT 1 FROM person_verify WHERE person=?1)`,
+	`INSERT INTO person_retain (person, until) SELECT ?1, until FROM person_retain WHERE person=?2 ON CONFLICT DO UPDATE SET until=MAX(until, excluded.until)`,
+}
+
+const lossesSQL = `SELECT EXISTS (SELECT 1 FROM person_totp WHERE person=?1) AND EXISTS (SELECT 1 FROM person_totp WHERE person=?2), EXISTS (SELECT 1 FROM person_sso WHERE person=?1) AND EXISTS (SELECT 1 FROM person_sso WHERE person=?2), EXISTS (SELECT 1 FROM person_verify WHERE person=?1) AND EXISTS (SELECT 1 FROM person_verify WHERE person=?2)`
+
+// Losses returns descriptions of the data of Drop that will be discarded by
+// the merge, because Keep already has data of the same kind that can't be
+// combined with it.  It returns nil if nothing will be discarded.
+func (m *Merge) Losses(storer phys.Storer) (losses []string) {
+	phys.SQL(storer, lossesSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(m.Keep.ID()))
+		stmt.BindInt(int(m.Drop.ID()))
+		if stmt.Step() {
+			if stmt.ColumnBool() {
+				losses = append(losses, "two-factor authentication enrollment")
+			}
+			if stmt.ColumnBool() {
+				losses = append(losses, "single sign-on link")
+			}
+			if stmt.ColumnBool() {
+				losses = append(losses, "pending email address verification")
+			}
+		}
+	})
+	return losses
+}
+
+// Apply applies the merge: it moves all references to Drop over to Keep,
+// deletes Drop, updates Keep with the merged data, and recalculates role and
+// list memberships.
+func (m *Merge) Apply(storer phys.Storer) {
+	phys.Audit(storer, "Person %q [%d]:: MERGE Person %q [%d]", m.Keep.InformalName(), m.Keep.ID(), m.Drop.InformalName(), m.Drop.ID())
+	phys.History(storer, "Person", int(m.Keep.ID()), "MERGE person", m.Drop.InformalName(), "")
+	for _, sql := range repointSQL {
+		phys.SQL(storer, sql, func(stmt *phys.Stmt) {
+			stmt.BindInt(int(m.Keep.ID()))
+			stmt.BindInt(int(m.Drop.ID()))
+			stmt.Step()
+		})
+	}
+	m.mergeAvailability(storer)
+	// Person records are otherwise never deleted, so person.Person has no
+	// Delete method.  All references to the dropped person that don't
+	// cascade were moved above.
+	phys.SQL(storer, `DELETE FROM person WHERE id=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(m.Drop.ID()))
+		stmt.Step()
+	})
+	phys.Unindex(storer, m.Drop)
+	phys.Audit(storer, "DELETE Person %q [%d]", m.Drop.InformalName(), m.Drop.ID())
+	phys.History(storer, "Person", int(m.Drop.ID()), "DELETE (merged)", "", m.Keep.InformalName())
+	// Photos are stored as files rather than rows, so they are moved
+	// separately, once the transaction has committed.  The HasPhoto flag
+	// was merged with the other flags.
+	if m.Drop.Flags()&person.HasPhoto != 0 {
+		from, to := m.Drop.ID(), m.Keep.ID()
+		phys.OnCommit(storer, func() { person.MovePhoto(from, to) })
+	}
+	m.Keep.Update(storer, m.merged, mergeFields)
+	recalc.Recalculate(storer)
+}
+
+// mergeAvailability gives Keep the union of the availability windows of both
+// people, combining windows that overlap or abut.  (Blackouts were moved by
+// repointSQL.)
+func (m *Merge) mergeAvailability(storer phys.Storer) {
+	keep := availability.ForPerson(storer, m.Keep.ID())
+	drop := availability.ForPerson(storer, m.Drop.ID())
+	if len(drop.Windows) == 0 {
+		return
+	}
+	windows := append(slices.Clone(keep.Windows), drop.Windows...)
+	slices.SortFunc(windows, func(a, b availability.Window) int {
+		if a.Weekday != b.Weekday {
+			return int(a.Weekday) - int(b.Weekday)
+		}
+		return strings.Compare(a.Start, b.Start)
+	})
+	merged := windows[:0]
+	for _, w := range windows {
+		if last := len(merged) - 1; last >= 0 && merged[last].Weekday == w.Weekday && w.Start <= merged[last].End {
+			merged[last].End = max(merged[last].End, w.End)
+			continue
+		}
+		merged = append(merged, w)
+	}
+	keep.Windows = merged
+	availability.Set(storer, m.Keep, keep)
+}
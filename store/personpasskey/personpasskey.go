@@ -0,0 +1,151 @@
+// Package personpasskey stores the passkeys (WebAuthn credentials) that people
+// have registered for logging in without a password.
+package personpasskey
+
+import (
+	"time"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+const timestampFormat = "2006-01-02T15:04:05"
+
+// ID is the identifier of a passkey.
+type ID int
+
+// Passkey is a passkey registered to a person.
+type Passkey struct {
+	// ID is the identifier of the passkey.
+	ID ID
+	// Person is the ID of the person to whom the passkey belongs.
+	Person person.ID
+	// Credential is the credential ID assigned by the authenticator,
+	// base64url-encoded.
+	Credential string
+	// PublicKey is the credential public key in COSE_Key format,
+	// base64url-encoded.
+	PublicKey string
+	// SignCount is the last value of the authenticator's signature
+	// counter.
+	SignCount uint32
+	// Name is the device name given to the passkey by the person.
+	Name string
+	// Created is the time at which the passkey was registered.
+	Created time.Time
+	// LastUsed is the time at which the passkey was last used to log in,
+	// or zero if it never has been.
+	LastUsed time.Time
+}
+
+const columns = `id, person, credential, public_key, sign_count, name, created, last_used`
+
+// scan reads a passkey from the columns of a statement.
+func scan(stmt *phys.Stmt) (pk *Passkey) {
+	pk = new(Passkey)
+	pk.ID = ID(stmt.ColumnInt())
+	pk.Person = person.ID(stmt.ColumnInt())
+	pk.Credential = stmt.ColumnText()
+	pk.PublicKey = stmt.ColumnText()
+	pk.SignCount = uint32(stmt.ColumnInt())
+	pk.Name = stmt.ColumnText()
+	pk.Created, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+	pk.LastUsed, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+	return pk
+}
+
+const allForPersonSQL = `SELECT ` + columns + ` FROM person_passkey WHERE person=? ORDER BY created`
+
+// AllForPerson calls fn for each passkey registered to the specified person,
+// in order of registration.
+func AllForPerson(storer phys.Storer, pid person.ID, fn func(*Passkey)) {
+	phys.SQL(storer, allForPersonSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(pid))
+		for stmt.Step() {
+			fn(scan(stmt))
+		}
+	})
+}
+
+// CountForPerson returns the number of passkeys registered to the specified
+// person.
+func CountForPerson(storer phys.Storer, pid person.ID) (count int) {
+	AllForPerson(storer, pid, func(*Passkey) { count++ })
+	return count
+}
+
+const withIDSQL = `SELECT ` + columns + ` FROM person_passkey WHERE id=?`
+
+// WithID returns the passkey with the specified ID, or nil if there is none.
+func WithID(storer phys.Storer, id ID) (pk *Passkey) {
+	phys.SQL(storer, withIDSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(id))
+		if stmt.Step() {
+			pk = scan(stmt)
+		}
+	})
+	return pk
+}
+
+const withCredentialSQL = `SELECT ` + columns + ` FROM person_passkey WHERE credential=?`
+
+// WithCredential returns the passkey with the specified (base64url-encoded)
+// credential ID, or nil if there is none.
+func WithCredential(storer phys.Storer, credential string) (pk *Passkey) {
+	phys.SQL(storer, withCredentialSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(credential)
+		if stmt.Step() {
+			pk = scan(stmt)
+		}
+	})
+	return pk
+}
+
+const addSQL = `INSERT INTO person_passkey (person, credential, public_key, sign_count, name, created) VALUES (?,?,?,?,?,?)`
+
+// Add registers a new passkey for the specified person.  The ID and Person
+// fields of the passkey are set by this function.  The person must have FID
+// and FInformalName.
+func Add(storer phys.Storer, p *person.Person, pk *Passkey) {
+	pk.Person = p.ID()
+	phys.SQL(storer, addSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(pk.Person))
+		stmt.BindText(pk.Credential)
+		stmt.BindText(pk.PublicKey)
+		stmt.BindInt(int(pk.SignCount))
+		stmt.BindText(pk.Name)
+		stmt.BindText(pk.Created.In(time.Local).Format(timestampFormat))
+		stmt.Step()
+	})
+	pk.ID = ID(phys.LastInsertRowID(storer))
+	phys.Audit(storer, "Person %q [%d]:: ADD Passkey %q [%d]", p.InformalName(), p.ID(), pk.Name, pk.ID)
+	phys.History(storer, "Person", int(p.ID()), "ADD passkey", "", pk.Name)
+}
+
+const recordUseSQL = `UPDATE person_passkey SET sign_count=?, last_used=? WHERE id=?`
+
+// RecordUse records a login with the passkey, and the new value of its
+// signature counter.
+func (pk *Passkey) RecordUse(storer phys.Storer, signCount uint32, when time.Time) {
+	pk.SignCount, pk.LastUsed = signCount, when
+	phys.SQL(storer, recordUseSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(signCount))
+		stmt.BindText(when.In(time.Local).Format(timestampFormat))
+		stmt.BindInt(int(pk.ID))
+		stmt.Step()
+	})
+	// Intentionally not audited due to noise.
+}
+
+const deleteSQL = `DELETE FROM person_passkey WHERE id=?`
+
+// Delete removes the passkey.  p is the person to whom it belongs, and must
+// have FID and FInformalName.
+func (pk *Passkey) Delete(storer phys.Storer, p *person.Person) {
+	phys.SQL(storer, deleteSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(pk.ID))
+		stmt.Step()
+	})
+	phys.Audit(storer, "Person %q [%d]:: DELETE Passkey %q [%d]", p.InformalName(), p.ID(), pk.Name, pk.ID)
+	phys.History(storer, "Person", int(p.ID()), "DELETE passkey", pk.Name, "")
+}
@@ -4,6 +4,7 @@ import (
 	"sunnyvaleserv.org/portal/store/internal/phys"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/store/role"
+	"sunnyvaleserv.org/portal/store/webhook"
 )
 
 const addRoleSQL = `INSERT OR IGNORE INTO person_role (person, role, explicit) VALUES (?,?,1)`
@@ -18,6 +19,9 @@ func AddRole(storer phys.Storer, p *person.Person, r *role.Role) {
 	})
 	if phys.RowsAffected(storer) != 0 {
 		phys.Audit(storer, "Person %q [%d]:: ADD Role %q [%d]", p.InformalName(), p.ID(), r.Name(), r.ID())
+		phys.History(storer, "Person", int(p.ID()), "ADD role", "", r.Name())
+		phys.History(storer, "Role", int(r.ID()), "ADD person", "", p.InformalName())
+		phys.Webhook(storer, webhook.RoleGranted, roleWebhookData(p, r))
 	}
 }
 
@@ -33,5 +37,16 @@ func RemoveRole(storer phys.Storer, p *person.Person, r *role.Role) {
 	})
 	if phys.RowsAffected(storer) != 0 {
 		phys.Audit(storer, "Person %q [%d]:: REMOVE Role %q [%d]", p.InformalName(), p.ID(), r.Name(), r.ID())
+		phys.History(storer, "Person", int(p.ID()), "REMOVE role", r.Name(), "")
+		phys.History(storer, "Role", int(r.ID()), "REMOVE person", p.InformalName(), "")
+		phys.Webhook(storer, webhook.RoleRevoked, roleWebhookData(p, r))
+	}
+}
+
+// roleWebhookData returns the webhook payload for a role change.
+func roleWebhookData(p *person.Person, r *role.Role) any {
+	return map[string]webhook.Ref{
+		"person": {ID: int(p.ID()), Name: p.InformalName()},
+		"role":   {ID: int(r.ID()), Name: r.Name()},
 	}
 }
@@ -0,0 +1,85 @@
+// Package personsso stores the links between people and their accounts with
+// the OpenID provider used for single sign-on.
+package personsso
+
+import (
+	"time"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+const timestampFormat = "2006-01-02T15:04:05"
+
+// Link is the link between a person and their account with the OpenID
+// provider.
+type Link struct {
+	// Issuer is the issuer URL of the OpenID provider.
+	Issuer string
+	// Subject is the provider's identifier for the person's account.
+	Subject string
+	// Linked is the time at which the link was made.
+	Linked time.Time
+}
+
+const getSQL = `SELECT issuer, subject, linked FROM person_sso WHERE person=?`
+
+// Get returns the link for the specified person, or nil if they have none.
+func Get(storer phys.Storer, pid person.ID) (l *Link) {
+	phys.SQL(storer, getSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(pid))
+		if stmt.Step() {
+			l = new(Link)
+			l.Issuer = stmt.ColumnText()
+			l.Subject = stmt.ColumnText()
+			l.Linked, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+		}
+	})
+	return l
+}
+
+const withSubjectSQL = `SELECT person FROM person_sso WHERE issuer=? AND subject=?`
+
+// WithSubject returns the ID of the person linked to the specified account
+// with the specified provider, or zero if there is none.
+func WithSubject(storer phys.Storer, issuer, subject string) (pid person.ID) {
+	phys.SQL(storer, withSubjectSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(issuer)
+		stmt.BindText(subject)
+		if stmt.Step() {
+			pid = person.ID(stmt.ColumnInt())
+		}
+	})
+	return pid
+}
+
+const addSQL = `INSERT OR REPLACE INTO person_sso (person, issuer, subject, linked) VALUES (?,?,?,?)`
+
+// Add links the specified person to the specified account, replacing any
+// existing link.  The person must have FID and FInformalName.
+func Add(storer phys.Storer, p *person.Person, l *Link) {
+	phys.SQL(storer, addSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(p.ID()))
+		stmt.BindText(l.Issuer)
+		stmt.BindText(l.Subject)
+		stmt.BindText(l.Linked.In(time.Local).Format(timestampFormat))
+		stmt.Step()
+	})
+	phys.Audit(storer, "Person %q [%d]:: ADD SSO link %s %q", p.InformalName(), p.ID(), l.Issuer, l.Subject)
+	phys.History(storer, "Person", int(p.ID()), "ADD SSO link", "", l.Issuer)
+}
+
+const removeSQL = `DELETE FROM person_sso WHERE person=?`
+
+// Remove removes the link for the specified person.  The person must have FID
+// and FInformalName.
+func Remove(storer phys.Storer, p *person.Person) {
+	phys.SQL(storer, removeSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(p.ID()))
+		stmt.Step()
+	})
+	if phys.RowsAffected(storer) != 0 {
+		phys.Audit(storer, "Person %q [%d]:: DELETE SSO link", p.InformalName(), p.ID())
+		phys.History(storer, "Person", int(p.ID()), "DELETE SSO link", "", "")
+	}
+}
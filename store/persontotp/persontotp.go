@@ -0,0 +1,115 @@
+// Package persontotp stores the time-based one-time password (TOTP)
+// enrollments of people who use two-factor authentication, along with their
+// unused recovery codes.
+package persontotp
+
+import (
+	"strconv"
+	"strings"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+// Enrollment is a person's TOTP enrollment.
+type Enrollment struct {
+	// Secret is the base32-encoded TOTP secret.
+	Secret string
+	// Recovery is the list of hashes of the person's unused recovery
+	// codes.
+	Recovery []string
+	// LastStep is the time step of the last code used, so that it can't
+	// be used again.
+	LastStep int64
+}
+
+const getSQL = `SELECT secret, recovery, last_step FROM person_totp WHERE person=?`
+
+// Get returns the TOTP enrollment of the specified person, or nil if they are
+// not enrolled.
+func Get(storer phys.Storer, pid person.ID) (e *Enrollment) {
+	phys.SQL(storer, getSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(pid))
+		if stmt.Step() {
+			e = new(Enrollment)
+			e.Secret = stmt.ColumnText()
+			e.Recovery = strings.Fields(stmt.ColumnText())
+			e.LastStep = int64(stmt.ColumnInt())
+		}
+	})
+	return e
+}
+
+const enrollSQL = `INSERT OR REPLACE INTO person_totp (person, secret, recovery, last_step) VALUES (?,?,?,?)`
+
+// Enroll enrolls the specified person in TOTP authentication with the
+// specified secret and recovery code hashes.  lastStep is the time step of
+// the code they used to confirm the enrollment.  The person must have FID and
+// FInformalName.
+func Enroll(storer phys.Storer, p *person.Person, secret string, recovery []string, lastStep int64) {
+	phys.SQL(storer, enrollSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(p.ID()))
+		stmt.BindText(secret)
+		stmt.BindText(strings.Join(recovery, " "))
+		stmt.BindInt(int(lastStep))
+		stmt.Step()
+	})
+	phys.Audit(storer, "Person %q [%d]:: ADD TOTP enrollment", p.InformalName(), p.ID())
+	phys.History(storer, "Person", int(p.ID()), "ADD TOTP enrollment", "", "")
+}
+
+const removeSQL = `DELETE FROM person_totp WHERE person=?`
+
+// Remove removes the TOTP enrollment of the specified person.  The person must
+// have FID and FInformalName.
+func Remove(storer phys.Storer, p *person.Person) {
+	phys.SQL(storer, removeSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(p.ID()))
+		stmt.Step()
+	})
+	if phys.RowsAffected(storer) != 0 {
+		phys.Audit(storer, "Person %q [%d]:: DELETE TOTP enrollment", p.InformalName(), p.ID())
+		phys.History(storer, "Person", int(p.ID()), "DELETE TOTP enrollment", "", "")
+	}
+}
+
+const setLastStepSQL = `UPDATE person_totp SET last_step=? WHERE person=?`
+
+// SetLastStep records the time step of the last code used by the specified
+// person.
+func SetLastStep(storer phys.Storer, pid person.ID, step int64) {
+	phys.SQL(storer, setLastStepSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(step))
+		stmt.BindInt(int(pid))
+		stmt.Step()
+	})
+	// Intentionally not audited due to noise.
+}
+
+const setRecoverySQL = `UPDATE person_totp SET recovery=? WHERE person=?`
+
+// UseRecovery records that the person has used one of their recovery codes,
+// leaving the specified hashes unused.  The person must have FID and
+// FInformalName.
+func UseRecovery(storer phys.Storer, p *person.Person, remaining []string) {
+	phys.SQL(storer, setRecoverySQL, func(stmt *phys.Stmt) {
+		stmt.BindText(strings.Join(remaining, " "))
+		stmt.BindInt(int(p.ID()))
+		stmt.Step()
+	})
+	phys.Audit(storer, "Person %q [%d]:: TOTP recovery code used, %d left", p.InformalName(), p.ID(), len(remaining))
+	phys.History(storer, "Person", int(p.ID()), "TOTP recovery codes", strconv.Itoa(len(remaining)+1), strconv.Itoa(len(remaining)))
+}
+
+// ResetRecovery replaces the recovery codes of the specified person with the
+// specified hashes.  resetBy is the person who reset them.  Both people must
+// have FID and FInformalName.
+func ResetRecovery(storer phys.Storer, p *person.Person, recovery []string, resetBy *person.Person) {
+	phys.SQL(storer, setRecoverySQL, func(stmt *phys.Stmt) {
+		stmt.BindText(strings.Join(recovery, " "))
+		stmt.BindInt(int(p.ID()))
+		stmt.Step()
+	})
+	phys.Audit(storer, "Person %q [%d]:: RESET TOTP recovery codes by Person %q [%d]", p.InformalName(), p.ID(), resetBy.InformalName(), resetBy.ID())
+	phys.History(storer, "Person", int(p.ID()), "RESET TOTP recovery codes", "", "")
+}
@@ -0,0 +1,140 @@
+// Package personverify tracks the verification of email addresses of accounts
+// created by the public during class registration.  Until the person follows
+// the link emailed to them, their account is pending: its class registrations
+// are not final, and no email is sent on its behalf to anyone else.  Pending
+// accounts that are not verified in time are deleted, along with their class
+// registrations.
+package personverify
+
+import (
+	"time"
+
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+	"sunnyvaleserv.org/portal/store/person"
+)
+
+const timestampFormat = "2006-01-02T15:04:05"
+
+const createSQL = `INSERT OR REPLACE INTO person_verify (person, token, expires) VALUES (?,?,?)`
+
+// Create marks the specified person's account as pending verification of
+// their email address, using the specified token, until the specified time.
+// The person must have FID and FInformalName.
+func Create(storer phys.Storer, p *person.Person, token string, expires time.Time) {
+	phys.SQL(storer, createSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(p.ID()))
+		stmt.BindText(token)
+		stmt.BindText(expires.In(time.Local).Format(timestampFormat))
+		stmt.Step()
+	})
+	phys.Audit(storer, "Person %q [%d]:: ADD email verification %s expires %s", p.InformalName(), p.ID(), token, expires.In(time.Local).Format(timestampFormat))
+	phys.History(storer, "Person", int(p.ID()), "ADD email verification", "", "")
+}
+
+const withTokenSQL = `SELECT person, expires FROM person_verify WHERE token=?`
+
+// WithToken returns the ID of the person whose account is pending verification
+// with the specified token, and the time at which it expires.  It returns zero
+// values if there is no such pending verification.
+func WithToken(storer phys.Storer, token string) (pid person.ID, expires time.Time) {
+	phys.SQL(storer, withTokenSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(token)
+		if stmt.Step() {
+			pid = person.ID(stmt.ColumnInt())
+			expires, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+		}
+	})
+	return pid, expires
+}
+
+const pendingSQL = `SELECT 1 FROM person_verify WHERE person=?`
+
+// Pending returns whether the specified person's account is pending
+// verification of their email address.
+func Pending(storer phys.Storer, pid person.ID) (pending bool) {
+	phys.SQL(storer, pendingSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(pid))
+		pending = stmt.Step()
+	})
+	return pending
+}
+
+const verifySQL = `DELETE FROM person_verify WHERE person=?`
+
+// Verify marks the specified person's email address as verified.  The person
+// must have FID and FInformalName.
+func Verify(storer phys.Storer, p *person.Person) {
+	phys.SQL(storer, verifySQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(p.ID()))
+		stmt.Step()
+	})
+	if phys.RowsAffected(storer) != 0 {
+		phys.Audit(storer, "Person %q [%d]:: email verified", p.InformalName(), p.ID())
+		phys.History(storer, "Person", int(p.ID()), "email verified", "", "")
+	}
+}
+
+// expiredSQL returns the pending verifications that have expired, along with
+// a flag indicating whether the person has acquired any history (roles,
+// attendance, shift signups, text messages) since their account was created.
+// Such people have evidently been vouched for by a leader, so their accounts
+// are kept.
+const expiredSQL = `SELECT v.person, EXISTS (SELECT 1 FROM person_role WHERE person=v.person) OR EXISTS (SELECT 1 FROM task_person WHERE person=v.person) OR EXISTS (SELECT 1 FROM shift_person WHERE person=v.person) OR EXISTS (SELECT 1 FROM textmsg_recipient WHERE recipient=v.person) FROM person_verify v WHERE v.expires<?`
+
+// classesSQL returns the classes in which a person has registrations.
+const classesSQL = `SELECT DISTINCT class FROM classreg WHERE registered_by=?1 OR person=?1`
+
+// deleteSQL lists the statements that remove the references to a person being
+// deleted that do not cascade.  Each takes the person ID as its only
+// parameter.
+var deleteSQL = []string{
+	`DELETE FROM classreg WHERE registered_by=?1`,
+	`UPDATE classreg SET person=NULL WHERE person=?1`,
+}
+
+// Expire deletes the accounts whose verification has expired, along with their
+// class registrations, and returns the IDs of the classes in which seats may
+// have opened up as a result.  Accounts that have since acquired history are
+// kept, and simply marked as verified.
+func Expire(storer phys.Storer, now time.Time) (classes []class.ID) {
+	var (
+		expired []person.ID
+		keep    = make(map[person.ID]bool)
+	)
+	phys.SQL(storer, expiredSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(now.In(time.Local).Format(timestampFormat))
+		for stmt.Step() {
+			pid := person.ID(stmt.ColumnInt())
+			expired = append(expired, pid)
+			keep[pid] = stmt.ColumnBool()
+		}
+	})
+	for _, pid := range expired {
+		p := person.WithID(storer, pid, person.FID|person.FInformalName)
+		if keep[pid] {
+			Verify(storer, p)
+			continue
+		}
+		phys.SQL(storer, classesSQL, func(stmt *phys.Stmt) {
+			stmt.BindInt(int(pid))
+			for stmt.Step() {
+				classes = append(classes, class.ID(stmt.ColumnInt()))
+			}
+		})
+		for _, sql := range deleteSQL {
+			phys.SQL(storer, sql, func(stmt *phys.Stmt) {
+				stmt.BindInt(int(pid))
+				stmt.Step()
+			})
+		}
+		phys.SQL(storer, `DELETE FROM person WHERE id=?`, func(stmt *phys.Stmt) {
+			stmt.BindInt(int(pid))
+			stmt.Step()
+		})
+		phys.Unindex(storer, p)
+		phys.Audit(storer, "DELETE Person %q [%d] (email not verified)", p.InformalName(), p.ID())
+		phys.History(storer, "Person", int(p.ID()), "DELETE (email not verified)", "", "")
+	}
+	return classes
+}
@@ -8,6 +8,7 @@ import (
 const (
 	deleteImpliedRolesSQL         = `DELETE FROM person_role WHERE NOT explicit`
 	deletePrivLevelsSQL           = `DELETE FROM person_privlevel`
+	deletePermissionsSQL          = `DELETE FROM person_permission`
 	deleteListSendersSQL          = `UPDATE list_person SET sender=FALSE`
 	deleteUnusedListPersonRowsSQL = `DELETE FROM list_person WHERE NOT sender AND NOT sub AND NOT unsub`
 )
@@ -37,6 +38,16 @@ const addPrivLevelsForAdminLeaderSQL = `
 WITH orgs (org) AS (VALUES (2), (3), (4), (5), (6))
 INSERT OR REPLACE INTO person_privlevel
 SELECT pp.person, orgs.org, pp.privlevel FROM person_privlevel pp, orgs WHERE pp.org=1 AND pp.privlevel>=3`
+const addPermissionsSQL = `
+INSERT INTO person_permission
+SELECT DISTINCT pr.person, r.org, rp.permission
+FROM   person_role pr, role r, role_permission rp
+WHERE  pr.role=r.id AND rp.role=r.id AND r.org IS NOT NULL
+AND    NOT EXISTS (SELECT 1 FROM person_role pr2 WHERE pr2.person=pr.person AND pr2.role=2)`
+const addPermissionsForAdminSQL = `
+WITH orgs (org) AS (VALUES (2), (3), (4), (5), (6))
+INSERT OR IGNORE INTO person_permission
+SELECT pp.person, orgs.org, pp.permission FROM person_permission pp, orgs WHERE pp.org=1`
 const addListSendersSQL = `
 INSERT INTO list_person
 SELECT lr.list, pr.person, TRUE, FALSE, FALSE
@@ -57,10 +68,11 @@ WHERE  lr.role=pr.role and lr.submodel >= 2
 ON CONFLICT DO UPDATE SET sub=TRUE`
 
 // Recalculate recalculates the implicit role assignments, per-organization
-// privilege levels, and list privileges and memberships for all people in the
-// database.  This should be called whenever role implications are changed,
-// roles are deleted, role privileges or memberships on a list are changed, or
-// explicit role assignments to people are changed.
+// privilege levels and permissions, and list privileges and memberships for
+// all people in the database.  This should be called whenever role
+// implications are changed, roles are deleted, role privileges, permissions, or
+// memberships on a list are changed, or explicit role assignments to people are
+// changed.
 func Recalculate(storer phys.Storer) {
 	var listdata []byte
 
@@ -75,6 +87,9 @@ func Recalculate(storer phys.Storer) {
 		phys.Exec(storer, deletePrivLevelsForDisabledSQL)
 		phys.Exec(storer, addAdminMasterForWebmasterSQL)
 		phys.Exec(storer, addPrivLevelsForAdminLeaderSQL)
+		phys.Exec(storer, deletePermissionsSQL)
+		phys.Exec(storer, addPermissionsSQL)
+		phys.Exec(storer, addPermissionsForAdminSQL)
 		phys.Exec(storer, deleteListSendersSQL)
 		phys.Exec(storer, addListSendersSQL)
 		phys.Exec(storer, deleteDisallowedListReceiversSQL)
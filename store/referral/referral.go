@@ -0,0 +1,53 @@
+// Package referral defines the referral sources that class registrants choose
+// from when telling us how they found out about a class.
+package referral
+
+import "regexp"
+
+// ID uniquely identifies a referral source.
+type ID int
+
+// Source is a way that registrants learn about classes.  The list of sources
+// is maintained by the webmasters.
+type Source struct {
+	ID ID
+	// Seq is the position of the source in the list presented to
+	// registrants.
+	Seq    int
+	EnName string
+	EsName string
+	// Code is the campaign code which, when given in the src parameter of
+	// a class page URL, selects this source automatically.  It is empty if
+	// the source has no campaign code.
+	Code string
+	// Other indicates that registrants choosing this source are asked to
+	// describe it in their own words.
+	Other bool
+	// Archived indicates that the source is no longer offered to
+	// registrants, but is retained because registrations refer to it.
+	Archived bool
+}
+
+// codeRE matches valid campaign codes.
+var codeRE = regexp.MustCompile(`^[a-z0-9][-_a-z0-9]{0,39}$`)
+
+// ValidCode returns whether the specified string is a valid campaign code:  up
+// to 40 lower case letters, digits, hyphens, and underscores.
+func ValidCode(code string) bool {
+	return codeRE.MatchString(code)
+}
+
+// Clone returns a copy of the source.
+func (s *Source) Clone() (clone *Source) {
+	clone = new(Source)
+	*clone = *s
+	return clone
+}
+
+// Name returns the name of the source in the specified language.
+func (s *Source) Name(lang string) string {
+	if lang == "es" && s.EsName != "" {
+		return s.EsName
+	}
+	return s.EnName
+}
@@ -0,0 +1,196 @@
+package referral
+
+import (
+	"fmt"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+// columnList is a comma-separated list of column names for referral sources.
+const columnList = `id, seq, en_name, es_name, code, other, archived`
+
+// scan reads columns from the specified statement into the source.
+func (s *Source) scan(stmt *phys.Stmt) {
+	s.ID = ID(stmt.ColumnInt())
+	s.Seq = stmt.ColumnInt()
+	s.EnName = stmt.ColumnText()
+	s.EsName = stmt.ColumnText()
+	s.Code = stmt.ColumnText()
+	s.Other = stmt.ColumnBool()
+	s.Archived = stmt.ColumnBool()
+}
+
+const withIDSQL = `SELECT ` + columnList + ` FROM referral_source WHERE id=?`
+
+// WithID returns the source with the specified ID, or nil if it does not
+// exist.
+func WithID(storer phys.Storer, id ID) (s *Source) {
+	phys.SQL(storer, withIDSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(id))
+		if stmt.Step() {
+			s = new(Source)
+			s.scan(stmt)
+		}
+	})
+	return s
+}
+
+const withCodeSQL = `SELECT ` + columnList + ` FROM referral_source WHERE code=? AND NOT archived`
+
+// WithCode returns the unarchived source with the specified campaign code, or
+// nil if there is none.
+func WithCode(storer phys.Storer, code string) (s *Source) {
+	if code == "" {
+		return nil
+	}
+	phys.SQL(storer, withCodeSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(code)
+		if stmt.Step() {
+			s = new(Source)
+			s.scan(stmt)
+		}
+	})
+	return s
+}
+
+const allSQL = `SELECT ` + columnList + ` FROM referral_source ORDER BY seq`
+
+// All reads each source, including archived ones, in order.
+func All(storer phys.Storer, fn func(*Source)) {
+	phys.SQL(storer, allSQL, func(stmt *phys.Stmt) {
+		var s Source
+
+		for stmt.Step() {
+			s.scan(stmt)
+			fn(&s)
+		}
+	})
+}
+
+// InUse returns whether any class registrations refer to the source.
+func (s *Source) InUse(storer phys.Storer) (found bool) {
+	phys.SQL(storer, `SELECT 1 FROM classreg WHERE referral=? LIMIT 1`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(s.ID))
+		found = stmt.Step()
+	})
+	return found
+}
+
+// DuplicateCode returns whether another source has the same campaign code as
+// this one.
+func (s *Source) DuplicateCode(storer phys.Storer) (found bool) {
+	if s.Code == "" {
+		return false
+	}
+	phys.SQL(storer, `SELECT 1 FROM referral_source WHERE id!=? AND code=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(s.ID))
+		stmt.BindText(s.Code)
+		found = stmt.Step()
+	})
+	return found
+}
+
+const createSQL = `INSERT INTO referral_source (seq, en_name, es_name, code, other, archived) VALUES ((SELECT COALESCE(MAX(seq),0)+1 FROM referral_source),?,?,?,?,?)`
+
+// Create adds the specified source to the end of the list.  Its ID and Seq are
+// set.
+func Create(storer phys.Storer, s *Source) {
+	phys.SQL(storer, createSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(s.EnName)
+		stmt.BindText(s.EsName)
+		stmt.BindNullText(s.Code)
+		stmt.BindBool(s.Other)
+		stmt.BindBool(s.Archived)
+		stmt.Step()
+	})
+	s.ID = ID(phys.LastInsertRowID(storer))
+	phys.SQL(storer, `SELECT seq FROM referral_source WHERE id=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(s.ID))
+		if stmt.Step() {
+			s.Seq = stmt.ColumnInt()
+		}
+	})
+	context := fmt.Sprintf("ADD ReferralSource %q [%d]", s.EnName, s.ID)
+	phys.Audit(storer, "%s:: seq = %d", context, s.Seq)
+	phys.Audit(storer, "%s:: en_name = %q", context, s.EnName)
+	phys.Audit(storer, "%s:: es_name = %q", context, s.EsName)
+	if s.Code != "" {
+		phys.Audit(storer, "%s:: code = %q", context, s.Code)
+	}
+	if s.Other {
+		phys.Audit(storer, "%s:: other = true", context)
+	}
+	if s.Archived {
+		phys.Audit(storer, "%s:: archived = true", context)
+	}
+}
+
+const updateSQL = `UPDATE referral_source SET en_name=?, es_name=?, code=?, other=?, archived=? WHERE id=?`
+
+// Update updates the receiver source with the data in the specified source,
+// which must have the same ID.  Its Seq is not changed.
+func (s *Source) Update(storer phys.Storer, u *Source) {
+	phys.SQL(storer, updateSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(u.EnName)
+		stmt.BindText(u.EsName)
+		stmt.BindNullText(u.Code)
+		stmt.BindBool(u.Other)
+		stmt.BindBool(u.Archived)
+		stmt.BindInt(int(s.ID))
+		stmt.Step()
+	})
+	context := fmt.Sprintf("ReferralSource %q [%d]", u.EnName, s.ID)
+	if u.EnName != s.EnName {
+		phys.Audit(storer, "%s:: en_name = %q", context, u.EnName)
+	}
+	if u.EsName != s.EsName {
+		phys.Audit(storer, "%s:: es_name = %q", context, u.EsName)
+	}
+	if u.Code != s.Code {
+		phys.Audit(storer, "%s:: code = %q", context, u.Code)
+	}
+	if u.Other != s.Other {
+		phys.Audit(storer, "%s:: other = %v", context, u.Other)
+	}
+	if u.Archived != s.Archived {
+		phys.Audit(storer, "%s:: archived = %v", context, u.Archived)
+	}
+	s.EnName, s.EsName, s.Code, s.Other, s.Archived = u.EnName, u.EsName, u.Code, u.Other, u.Archived
+}
+
+const swapSeqSQL = `UPDATE referral_source SET seq=CASE id WHEN ?1 THEN ?4 ELSE ?3 END WHERE id IN (?1,?2)`
+
+// MoveUp moves the source one place earlier in the list, swapping it with the
+// source before it.  It does nothing if the source is already first.
+func (s *Source) MoveUp(storer phys.Storer) {
+	var prev Source
+
+	phys.SQL(storer, `SELECT `+columnList+` FROM referral_source WHERE seq<? ORDER BY seq DESC LIMIT 1`, func(stmt *phys.Stmt) {
+		stmt.BindInt(s.Seq)
+		if stmt.Step() {
+			prev.scan(stmt)
+		}
+	})
+	if prev.ID == 0 {
+		return
+	}
+	phys.SQL(storer, swapSeqSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(s.ID))
+		stmt.BindInt(int(prev.ID))
+		stmt.BindInt(s.Seq)
+		stmt.BindInt(prev.Seq)
+		stmt.Step()
+	})
+	s.Seq, prev.Seq = prev.Seq, s.Seq
+	phys.Audit(storer, "ReferralSource %q [%d]:: seq = %d", s.EnName, s.ID, s.Seq)
+	phys.Audit(storer, "ReferralSource %q [%d]:: seq = %d", prev.EnName, prev.ID, prev.Seq)
+}
+
+// Delete deletes the source.  It must not be in use.
+func (s *Source) Delete(storer phys.Storer) {
+	phys.SQL(storer, `DELETE FROM referral_source WHERE id=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(s.ID))
+		stmt.Step()
+	})
+	phys.Audit(storer, "DELETE ReferralSource %q [%d]", s.EnName, s.ID)
+}
@@ -83,3 +83,14 @@ func (r *Role) Implies() []ID {
 	}
 	return r.implies
 }
+
+// Permissions is the list of Permissions granted explicitly to people holding
+// this Role, in the Role's organization (or, if it is the Admin organization,
+// in all organizations), in addition to those they hold by virtue of the
+// Role's privilege level.
+func (r *Role) Permissions() []enum.Permission {
+	if r.fields&FPermissions == 0 {
+		panic("Role.Permissions called without having fetched FPermissions")
+	}
+	return r.permissions
+}
@@ -7,6 +7,9 @@ import (
 	"sunnyvaleserv.org/portal/store/internal/phys"
 )
 
+// joinFields are the fields of a role that are stored in separate tables.
+const joinFields = FImplies | FPermissions
+
 var withIDSQLCache map[Fields]string
 
 // WithID returns the role with the specified ID, or nil if it does not exist.
@@ -14,23 +17,21 @@ func WithID(storer phys.Storer, id ID, fields Fields) (r *Role) {
 	if withIDSQLCache == nil {
 		withIDSQLCache = make(map[Fields]string)
 	}
-	if _, ok := withIDSQLCache[fields&^FImplies]; !ok {
+	if _, ok := withIDSQLCache[fields&^joinFields]; !ok {
 		var sb strings.Builder
 		sb.WriteString("SELECT ")
-		ColumnList(&sb, fields&^FImplies)
+		ColumnList(&sb, fields&^joinFields)
 		sb.WriteString(" FROM role r WHERE r.id=?")
-		withIDSQLCache[fields&^FImplies] = sb.String()
+		withIDSQLCache[fields&^joinFields] = sb.String()
 	}
-	phys.SQL(storer, withIDSQLCache[fields&^FImplies], func(stmt *phys.Stmt) {
+	phys.SQL(storer, withIDSQLCache[fields&^joinFields], func(stmt *phys.Stmt) {
 		stmt.BindInt(int(id))
 		if stmt.Step() {
 			r = new(Role)
-			r.Scan(stmt, fields&^FImplies)
+			r.Scan(stmt, fields&^joinFields)
 			r.id = id
 			r.fields |= FID
-			if fields&FImplies != 0 {
-				r.readImplies(storer)
-			}
+			r.readJoins(storer, fields)
 		}
 	})
 	return r
@@ -43,20 +44,18 @@ func All(storer phys.Storer, fields Fields, fn func(*Role)) {
 	if allSQLCache == nil {
 		allSQLCache = make(map[Fields]string)
 	}
-	if _, ok := allSQLCache[fields&^FImplies]; !ok {
+	if _, ok := allSQLCache[fields&^joinFields]; !ok {
 		var sb strings.Builder
 		sb.WriteString("SELECT ")
-		ColumnList(&sb, fields&^FImplies)
+		ColumnList(&sb, fields&^joinFields)
 		sb.WriteString(" FROM role r ORDER BY r.priority")
-		allSQLCache[fields&^FImplies] = sb.String()
+		allSQLCache[fields&^joinFields] = sb.String()
 	}
-	phys.SQL(storer, allSQLCache[fields&^FImplies], func(stmt *phys.Stmt) {
+	phys.SQL(storer, allSQLCache[fields&^joinFields], func(stmt *phys.Stmt) {
 		var r Role
 		for stmt.Step() {
-			r.Scan(stmt, fields&^FImplies)
-			if fields&FImplies != 0 {
-				r.readImplies(storer)
-			}
+			r.Scan(stmt, fields&^joinFields)
+			r.readJoins(storer, fields)
 			fn(&r)
 		}
 	})
@@ -70,21 +69,19 @@ func AllWithOrg(storer phys.Storer, fields Fields, org enum.Org, fn func(*Role))
 	if allWithOrgSQLCache == nil {
 		allWithOrgSQLCache = make(map[Fields]string)
 	}
-	if _, ok := allWithOrgSQLCache[fields&^FImplies]; !ok {
+	if _, ok := allWithOrgSQLCache[fields&^joinFields]; !ok {
 		var sb strings.Builder
 		sb.WriteString("SELECT ")
-		ColumnList(&sb, fields&^FImplies)
+		ColumnList(&sb, fields&^joinFields)
 		sb.WriteString(" FROM role r WHERE r.org=? ORDER BY r.priority")
-		allWithOrgSQLCache[fields&^FImplies] = sb.String()
+		allWithOrgSQLCache[fields&^joinFields] = sb.String()
 	}
-	phys.SQL(storer, allWithOrgSQLCache[fields&^FImplies], func(stmt *phys.Stmt) {
+	phys.SQL(storer, allWithOrgSQLCache[fields&^joinFields], func(stmt *phys.Stmt) {
 		var r Role
 		stmt.BindInt(int(org))
 		for stmt.Step() {
-			r.Scan(stmt, fields&^FImplies)
-			if fields&FImplies != 0 {
-				r.readImplies(storer)
-			}
+			r.Scan(stmt, fields&^joinFields)
+			r.readJoins(storer, fields)
 			fn(&r)
 		}
 	})
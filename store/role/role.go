@@ -46,6 +46,7 @@ const (
 	FPrivLevel
 	FFlags
 	FImplies
+	FPermissions
 )
 
 // Role describes a role that can be held by people.
@@ -53,15 +54,16 @@ type Role struct {
 	// NOTE: documentation of the fields is on the getter functions in
 	// getters.go.
 
-	fields    Fields // which fields of the structure are populated
-	id        ID
-	name      string
-	title     string
-	priority  uint
-	org       enum.Org
-	privLevel enum.PrivLevel
-	flags     Flags
-	implies   []ID
+	fields      Fields // which fields of the structure are populated
+	id          ID
+	name        string
+	title       string
+	priority    uint
+	org         enum.Org
+	privLevel   enum.PrivLevel
+	flags       Flags
+	implies     []ID
+	permissions []enum.Permission
 }
 
 // Clone creates a copy of a Role.
@@ -72,5 +74,9 @@ func (r *Role) Clone() (r2 *Role) {
 		r2.implies = make([]ID, len(r.implies))
 		copy(r2.implies, r.implies)
 	}
+	if r.permissions != nil {
+		r2.permissions = make([]enum.Permission, len(r.permissions))
+		copy(r2.permissions, r.permissions)
+	}
 	return r2
 }
@@ -42,6 +42,9 @@ func ColumnList(sb *strings.Builder, fields Fields) {
 	if fields&FImplies != 0 {
 		panic("cannot fetch FImplies using ColumnList/Scan")
 	}
+	if fields&FPermissions != 0 {
+		panic("cannot fetch FPermissions using ColumnList/Scan")
+	}
 }
 
 // Scan reads columns corresponding to the specified fields from the specified
@@ -68,7 +71,7 @@ func (r *Role) Scan(stmt *phys.Stmt, fields Fields) {
 	if fields&FFlags != 0 {
 		r.flags = Flags(stmt.ColumnInt())
 	}
-	r.fields |= fields &^ FImplies
+	r.fields |= fields &^ joinFields
 }
 
 const readImpliesSQL = `SELECT implied FROM role_implies WHERE implier=? ORDER BY implied`
@@ -83,3 +86,26 @@ func (r *Role) readImplies(storer phys.Storer) {
 	})
 	r.fields |= FImplies
 }
+
+// readJoins reads the fields of the role that are stored in separate tables.
+func (r *Role) readJoins(storer phys.Storer, fields Fields) {
+	if fields&FImplies != 0 {
+		r.readImplies(storer)
+	}
+	if fields&FPermissions != 0 {
+		r.readPermissions(storer)
+	}
+}
+
+const readPermissionsSQL = `SELECT permission FROM role_permission WHERE role=? ORDER BY permission`
+
+func (r *Role) readPermissions(storer phys.Storer) {
+	r.permissions = r.permissions[:0]
+	phys.SQL(storer, readPermissionsSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(r.ID()))
+		for stmt.Step() {
+			r.permissions = append(r.permissions, enum.Permission(stmt.ColumnText()))
+		}
+	})
+	r.fields |= FPermissions
+}
@@ -2,6 +2,9 @@ package role
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	"sunnyvaleserv.org/portal/store/enum"
 	"sunnyvaleserv.org/portal/store/internal/phys"
@@ -10,7 +13,7 @@ import (
 
 // UpdaterFields are the fields that must be fetched prior to creating an
 // Updater.
-const UpdaterFields = FID | FName | FTitle | FPriority | FOrg | FPrivLevel | FFlags | FImplies
+const UpdaterFields = FID | FName | FTitle | FPriority | FOrg | FPrivLevel | FFlags | FImplies | FPermissions
 
 // Updater is a structure that can be filled with data for a new or changed
 // role, and then later applied.  For creating new roles, it can simply be
@@ -18,20 +21,22 @@ const UpdaterFields = FID | FName | FTitle | FPriority | FOrg | FPrivLevel | FFl
 // in it must be set, or it should be instantiated with the Updater method of
 // the role being changed.
 type Updater struct {
-	ID        ID
-	Name      string
-	Title     string
-	Priority  uint
-	Org       enum.Org
-	PrivLevel enum.PrivLevel
-	Flags     Flags
-	Implies   []ID
+	ID          ID
+	Name        string
+	Title       string
+	Priority    uint
+	Org         enum.Org
+	PrivLevel   enum.PrivLevel
+	Flags       Flags
+	Implies     []ID
+	Permissions []enum.Permission
 }
 
 // Updater returns a new Updater for the specified role, with its data matching
 // the current data for the role.  The role must have fetched UpdaterFields.
 func (r *Role) Updater() *Updater {
 	var implies []ID
+	var permissions []enum.Permission
 
 	if r.fields&UpdaterFields != UpdaterFields {
 		panic("Role.Updater called without fetching UpdaterFields")
@@ -40,15 +45,20 @@ func (r *Role) Updater() *Updater {
 		implies = make([]ID, len(r.implies))
 		copy(implies, r.implies)
 	}
+	if len(r.permissions) != 0 {
+		permissions = make([]enum.Permission, len(r.permissions))
+		copy(permissions, r.permissions)
+	}
 	return &Updater{
-		ID:        r.id,
-		Name:      r.name,
-		Title:     r.title,
-		Priority:  r.priority,
-		Org:       r.org,
-		PrivLevel: r.privLevel,
-		Flags:     r.flags,
-		Implies:   implies,
+		ID:          r.id,
+		Name:        r.name,
+		Title:       r.title,
+		Priority:    r.priority,
+		Org:         r.org,
+		PrivLevel:   r.privLevel,
+		Flags:       r.flags,
+		Implies:     implies,
+		Permissions: permissions,
 	}
 }
 
@@ -76,6 +86,7 @@ func Create(storer phys.Storer, u *Updater) (r *Role) {
 		}
 	})
 	storeImplies(storer, r.id, u.Implies, false)
+	storePermissions(storer, r.id, u.Permissions, false)
 	r.auditAndUpdate(storer, u, true)
 	phys.Index(storer, r)
 	return r
@@ -94,6 +105,7 @@ func (r *Role) Update(storer phys.Storer, u *Updater) {
 		stmt.Step()
 	})
 	storeImplies(storer, r.id, u.Implies, true)
+	storePermissions(storer, r.id, u.Permissions, true)
 	r.auditAndUpdate(storer, u, false)
 	phys.Index(storer, r)
 }
@@ -130,6 +142,29 @@ func storeImplies(storer phys.Storer, implier ID, implies []ID, delfirst bool) {
 	}
 }
 
+const deletePermissionsSQL = `DELETE FROM role_permission WHERE role=?`
+const insertPermissionsSQL = `INSERT INTO role_permission (role, permission) VALUES (?,?)`
+
+func storePermissions(storer phys.Storer, rid ID, permissions []enum.Permission, delfirst bool) {
+	if delfirst {
+		phys.SQL(storer, deletePermissionsSQL, func(stmt *phys.Stmt) {
+			stmt.BindInt(int(rid))
+			stmt.Step()
+		})
+	}
+	if len(permissions) != 0 {
+		slices.Sort(permissions)
+		phys.SQL(storer, insertPermissionsSQL, func(stmt *phys.Stmt) {
+			for _, perm := range permissions {
+				stmt.BindInt(int(rid))
+				stmt.BindText(string(perm))
+				stmt.Step()
+				stmt.Reset()
+			}
+		})
+	}
+}
+
 const roleNameSQL = `SELECT name FROM role WHERE id=?`
 
 func (r *Role) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
@@ -139,29 +174,36 @@ func (r *Role) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
 	}
 	if u.Name != r.name {
 		phys.Audit(storer, "%s:: name = %q", context, u.Name)
+		r.history(storer, "name", r.name, u.Name)
 		r.name = u.Name
 	}
 	if u.Title != r.title {
 		phys.Audit(storer, "%s:: title = %q", context, u.Title)
+		r.history(storer, "title", r.title, u.Title)
 		r.title = u.Title
 	}
 	if u.Priority != r.priority {
 		phys.Audit(storer, "%s:: priority = %d", context, u.Priority)
+		r.history(storer, "priority", strconv.Itoa(int(r.priority)), strconv.Itoa(int(u.Priority)))
 		r.priority = u.Priority
 	}
 	if u.Org != r.org {
 		phys.Audit(storer, "%s:: org = %s [%d]", context, u.Org, u.Org)
+		r.history(storer, "org", r.org.String(), u.Org.String())
 		r.org = u.Org
 	}
 	if u.PrivLevel != r.privLevel {
 		phys.Audit(storer, "%s:: privLevel = %s [%d]", context, u.PrivLevel, u.PrivLevel)
+		r.history(storer, "privLevel", r.privLevel.String(), u.PrivLevel.String())
 		r.privLevel = u.PrivLevel
 	}
 	if u.Flags != r.flags {
 		phys.Audit(storer, "%s:: flags = 0x%x", context, u.Flags)
+		r.history(storer, "flags", fmt.Sprintf("0x%x", r.flags), fmt.Sprintf("0x%x", u.Flags))
 		r.flags = u.Flags
 	}
 	if !util.EqualIDList(r.implies, u.Implies) {
+		r.history(storer, "implies", roleNames(storer, r.implies), roleNames(storer, u.Implies))
 		if len(u.Implies) == 0 {
 			phys.Audit(storer, "%s:: implies = []", context)
 			r.implies = nil
@@ -179,6 +221,50 @@ func (r *Role) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
 			})
 		}
 	}
+	if !slices.Equal(r.permissions, u.Permissions) {
+		r.history(storer, "permissions", permissionNames(r.permissions), permissionNames(u.Permissions))
+		if len(u.Permissions) == 0 {
+			phys.Audit(storer, "%s:: permissions = []", context)
+		} else {
+			for i, perm := range u.Permissions {
+				phys.Audit(storer, "%s:: permissions:: [%d] = %s", context, i+1, perm)
+			}
+		}
+		r.permissions = u.Permissions
+	}
+}
+
+// history records a change to a field of the role in its change history.
+func (r *Role) history(storer phys.Storer, field, old, new string) {
+	phys.History(storer, "Role", int(r.id), field, old, new)
+}
+
+// roleNames returns the names of the specified roles, as a comma-separated
+// list for the change history.
+func roleNames(storer phys.Storer, ids []ID) string {
+	var names []string
+
+	phys.SQL(storer, roleNameSQL, func(stmt *phys.Stmt) {
+		for _, id := range ids {
+			stmt.BindInt(int(id))
+			if stmt.Step() {
+				names = append(names, stmt.ColumnText())
+			}
+			stmt.Reset()
+		}
+	})
+	return strings.Join(names, ", ")
+}
+
+// permissionNames returns the names of the specified permissions, as a
+// comma-separated list for the change history.
+func permissionNames(perms []enum.Permission) string {
+	var names = make([]string, len(perms))
+
+	for i, perm := range perms {
+		names[i] = string(perm)
+	}
+	return strings.Join(names, ", ")
 }
 
 const duplicateNameSQL = `SELECT 1 FROM role WHERE id!=? AND name=?`
@@ -222,6 +308,7 @@ func (r *Role) Delete(storer phys.Storer) {
 	})
 	phys.Unindex(storer, r)
 	phys.Audit(storer, "DELETE Role %q [%d]", r.Name(), r.ID())
+	r.history(storer, "DELETE", "", "")
 }
 
 // Reorder changes the priority of a role, shifting the other role priorities
@@ -256,5 +343,6 @@ func (r *Role) Reorder(storer phys.Storer, to uint) {
 		stmt.Step()
 	})
 	phys.Audit(storer, "Role %q [%d]:: priority = %d (shift from %d)", r.Name(), r.ID(), to, r.Priority())
+	r.history(storer, "priority", strconv.Itoa(int(r.Priority())), strconv.Itoa(int(to)))
 	r.priority = to
 }
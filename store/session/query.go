@@ -1,17 +1,56 @@
 package session
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"sunnyvaleserv.org/portal/store/internal/phys"
 	"sunnyvaleserv.org/portal/store/person"
 )
 
-const withTokenSQL = `SELECT person, expires, csrf FROM session WHERE token=?`
+// Session is a login session.
+type Session struct {
+	Token        string
+	Person       person.ID
+	Expires      time.Time
+	CSRF         string
+	Impersonator person.ID // webmaster viewing the site as Person, if any
+	Created      time.Time
+	LastUsed     time.Time
+	IP           string
+	UserAgent    string
+}
+
+// Handle returns an identifier for the session that can be shown in web pages
+// without revealing the session token.
+func (s *Session) Handle() string {
+	sum := sha256.Sum256([]byte(s.Token))
+	return hex.EncodeToString(sum[:8])
+}
+
+const columns = `token, person, expires, csrf, impersonator, created, last_used, ip, user_agent`
+
+// scan reads a session from the columns of a statement.
+func scan(stmt *phys.Stmt) (s *Session) {
+	s = new(Session)
+	s.Token = stmt.ColumnText()
+	s.Person = person.ID(stmt.ColumnInt())
+	s.Expires, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+	s.CSRF = stmt.ColumnText()
+	s.Impersonator = person.ID(stmt.ColumnInt())
+	s.Created, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+	s.LastUsed, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+	s.IP = stmt.ColumnText()
+	s.UserAgent = stmt.ColumnText()
+	return s
+}
 
-// WithToken returns the session data associated with the specified session
-// token.  It returns zero values if the session does not exist or has expired.
-func WithToken(storer phys.Storer, token string) (pid person.ID, expires time.Time, csrf string) {
+const withTokenSQL = `SELECT ` + columns + ` FROM session WHERE token=?`
+
+// WithToken returns the session with the specified session token.  It returns
+// nil if the session does not exist or has expired.
+func WithToken(storer phys.Storer, token string) (s *Session) {
 	// We do not attempt to join with the person table and return a person
 	// object, because the caller almost certainly wants joins against the
 	// person sub-tables.  We'll just return the person ID and let them call
@@ -20,10 +59,22 @@ func WithToken(storer phys.Storer, token string) (pid person.ID, expires time.Ti
 	phys.SQL(storer, withTokenSQL, func(stmt *phys.Stmt) {
 		stmt.BindText(token)
 		if stmt.Step() {
-			pid = person.ID(stmt.ColumnInt())
-			expires, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
-			csrf = stmt.ColumnText()
+			s = scan(stmt)
+		}
+	})
+	return s
+}
+
+const allForPersonSQL = `SELECT ` + columns + ` FROM session WHERE person=? AND expires>=? ORDER BY last_used DESC`
+
+// AllForPerson calls fn for each unexpired session of the specified person,
+// most recently used first.
+func AllForPerson(storer phys.Storer, pid person.ID, fn func(*Session)) {
+	phys.SQL(storer, allForPersonSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(pid))
+		stmt.BindText(time.Now().Format(timestampFormat))
+		for stmt.Step() {
+			fn(scan(stmt))
 		}
 	})
-	return
 }
@@ -8,18 +8,51 @@ import (
 )
 
 const timestampFormat = "2006-01-02T15:04:05"
-const createSQL = `INSERT INTO session (token, person, expires, csrf) VALUES (?,?,?,?)`
+const createSQL = `INSERT INTO session (token, person, expires, csrf, created, last_used, ip, user_agent) VALUES (?,?,?,?,?,?,?,?)`
 
-// Create creates a new session for a person.
-func Create(storer phys.Storer, p *person.Person, token, csrf string, expires time.Time) {
+// Create creates a new session for a person.  Its Created and LastUsed times
+// are set to the current time.  The person must have FID and FInformalName.
+func Create(storer phys.Storer, p *person.Person, s *Session) {
+	s.Person, s.Impersonator = p.ID(), 0
+	s.Created = time.Now()
+	s.LastUsed = s.Created
 	phys.SQL(storer, createSQL, func(stmt *phys.Stmt) {
-		stmt.BindText(token)
-		stmt.BindInt(int(p.ID()))
-		stmt.BindText(expires.In(time.Local).Format(timestampFormat))
-		stmt.BindText(csrf)
+		stmt.BindText(s.Token)
+		stmt.BindInt(int(s.Person))
+		stmt.BindText(s.Expires.In(time.Local).Format(timestampFormat))
+		stmt.BindText(s.CSRF)
+		stmt.BindText(s.Created.In(time.Local).Format(timestampFormat))
+		stmt.BindText(s.LastUsed.In(time.Local).Format(timestampFormat))
+		stmt.BindText(s.IP)
+		stmt.BindText(s.UserAgent)
+		stmt.Step()
+	})
+	phys.Audit(storer, "AuthN:: ADD Session %s for person %q [%d] from %s expires %s", s.Token, p.InformalName(), p.ID(), s.IP, s.Expires.In(time.Local).Format(timestampFormat))
+}
+
+const createImpersonationSQL = `INSERT INTO session (token, person, expires, csrf, impersonator, created, last_used, ip, user_agent) VALUES (?,?,?,?,?,?,?,?,?)`
+
+// CreateImpersonation creates a new session for a person, in which the
+// specified webmaster is viewing the site as that person.  Its Created and
+// LastUsed times are set to the current time.  Both people must have FID and
+// FInformalName.
+func CreateImpersonation(storer phys.Storer, p, impersonator *person.Person, s *Session) {
+	s.Person, s.Impersonator = p.ID(), impersonator.ID()
+	s.Created = time.Now()
+	s.LastUsed = s.Created
+	phys.SQL(storer, createImpersonationSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(s.Token)
+		stmt.BindInt(int(s.Person))
+		stmt.BindText(s.Expires.In(time.Local).Format(timestampFormat))
+		stmt.BindText(s.CSRF)
+		stmt.BindInt(int(s.Impersonator))
+		stmt.BindText(s.Created.In(time.Local).Format(timestampFormat))
+		stmt.BindText(s.LastUsed.In(time.Local).Format(timestampFormat))
+		stmt.BindText(s.IP)
+		stmt.BindText(s.UserAgent)
 		stmt.Step()
 	})
-	phys.Audit(storer, "AuthN:: ADD Session %s for person %q [%d] expires %s", token, p.InformalName(), p.ID(), expires.In(time.Local).Format(timestampFormat))
+	phys.Audit(storer, "AuthN:: ADD Session %s for person %q [%d] impersonated by %q [%d] from %s expires %s", s.Token, p.InformalName(), p.ID(), impersonator.InformalName(), impersonator.ID(), s.IP, s.Expires.In(time.Local).Format(timestampFormat))
 }
 
 const deleteSQL = `DELETE FROM session WHERE token=?`
@@ -64,6 +97,20 @@ func DeleteForPerson(storer phys.Storer, p *person.Person, token string) {
 	}
 }
 
+const touchSQL = `UPDATE session SET last_used=?, ip=? WHERE token=?`
+
+// Touch records that the specified session was used at the specified time,
+// from the specified IP address.
+func Touch(storer phys.Storer, token string, lastUsed time.Time, ip string) {
+	phys.SQL(storer, touchSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(lastUsed.In(time.Local).Format(timestampFormat))
+		stmt.BindText(ip)
+		stmt.BindText(token)
+		stmt.Step()
+	})
+	// Intentionally not audited due to noise.
+}
+
 const extendSQL = `UPDATE session SET expires=? WHERE token=?`
 
 // Extend changes the expiration time of the specified session to the specified
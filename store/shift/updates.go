@@ -2,6 +2,7 @@ package shift
 
 import (
 	"fmt"
+	"strconv"
 
 	"sunnyvaleserv.org/portal/store/event"
 	"sunnyvaleserv.org/portal/store/internal/phys"
@@ -114,8 +115,10 @@ func bindUpdater(stmt *phys.Stmt, u *Updater) {
 
 func (s *Shift) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
 	context := fmt.Sprintf("Event %s %q [%d]:: Task %q [%d]:: Shift %d", u.Event.Start()[:10], u.Event.Name(), u.Event.ID(), u.Task.Name(), u.Task.ID(), s.id)
+	label := fmt.Sprintf("Task %s:: Shift %d", u.Task.Name(), s.id)
 	if create {
 		context = "ADD " + context
+		label = "ADD " + label
 	}
 	if u.Task.ID() != s.task {
 		phys.Audit(storer, "%s:: task = Event %s %q [%d] Task %q [%d]", context, u.Event.Start()[:10], u.Event.Name(), u.Event.ID(), u.Task.Name(), u.Task.ID())
@@ -123,31 +126,49 @@ func (s *Shift) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
 	}
 	if u.Start != s.start {
 		phys.Audit(storer, "%s:: start = %s", context, u.Start)
+		phys.History(storer, "Event", int(u.Event.ID()), label+":: start", s.start, u.Start)
 		s.start = u.Start
 	}
 	if u.End != s.end {
 		phys.Audit(storer, "%s:: end = %s", context, u.End)
+		phys.History(storer, "Event", int(u.Event.ID()), label+":: end", s.end, u.End)
 		s.end = u.End
 	}
 	if u.Venue != nil {
 		if u.Venue.ID() != s.venue {
 			phys.Audit(storer, "%s:: venue = %q [%d]", context, u.Venue.Name(), u.Venue.ID())
+			phys.History(storer, "Event", int(u.Event.ID()), label+":: venue", venueName(storer, s.venue), u.Venue.Name())
 			s.venue = u.Venue.ID()
 		}
 	} else if s.venue != 0 {
 		phys.Audit(storer, "%s:: venue = nil", context)
+		phys.History(storer, "Event", int(u.Event.ID()), label+":: venue", venueName(storer, s.venue), "")
 		s.venue = 0
 	}
 	if u.Min != s.min {
 		phys.Audit(storer, "%s:: min = %d", context, u.Min)
+		phys.History(storer, "Event", int(u.Event.ID()), label+":: min", strconv.Itoa(int(s.min)), strconv.Itoa(int(u.Min)))
 		s.min = u.Min
 	}
 	if u.Max != s.max {
 		phys.Audit(storer, "%s:: max = %d", context, u.Max)
+		phys.History(storer, "Event", int(u.Event.ID()), label+":: max", strconv.Itoa(int(s.max)), strconv.Itoa(int(u.Max)))
 		s.max = u.Max
 	}
 }
 
+// venueName returns the name of the venue with the specified ID, or an empty
+// string if there is none.
+func venueName(storer phys.Storer, vid venue.ID) string {
+	if vid == 0 {
+		return ""
+	}
+	if v := venue.WithID(storer, vid, venue.FName); v != nil {
+		return v.Name()
+	}
+	return ""
+}
+
 const overlappingShiftSQL = `SELECT 1 FROM shift WHERE id!=? AND task=? AND venue IS ? AND ?<end AND ?>start`
 
 // OverlappingShift returns whether the data specified in the Updater would
@@ -184,4 +205,5 @@ func (s *Shift) Delete(storer phys.Storer, e *event.Event, t *task.Task) {
 		stmt.Step()
 	})
 	phys.Audit(storer, "Event %s %q [%d]:: Task %q [%d]:: DELETE Shift %d", e.Start()[:10], e.Name(), e.ID(), t.Name(), t.ID(), s.ID())
+	phys.History(storer, "Event", int(e.ID()), fmt.Sprintf("Task %s:: DELETE Shift %d", t.Name(), s.ID()), "", "")
 }
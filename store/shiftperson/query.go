@@ -90,3 +90,39 @@ func OverlappingSignup(storer phys.Storer, pid person.ID, start, end string) (ov
 	})
 	return overlap
 }
+
+// AllForPerson returns all of the shifts for which the specified person has
+// signed up or explicitly declined, in chronological order.  signedUp is
+// positive for signups and negative for declines.
+func AllForPerson(storer phys.Storer, pid person.ID, eventFields event.Fields, taskFields task.Fields, shiftFields shift.Fields, fn func(e *event.Event, t *task.Task, s *shift.Shift, signedUp int)) {
+	var sb strings.Builder
+
+	sb.WriteString("SELECT sp.signed_up")
+	if eventFields != 0 {
+		sb.WriteString(", ")
+		event.ColumnList(&sb, eventFields)
+	}
+	if taskFields != 0 {
+		sb.WriteString(", ")
+		task.ColumnList(&sb, taskFields)
+	}
+	if shiftFields != 0 {
+		sb.WriteString(", ")
+		shift.ColumnList(&sb, shiftFields)
+	}
+	sb.WriteString(" FROM shift_person sp, shift s, task t, event e WHERE sp.shift=s.id AND s.task=t.id AND t.event=e.id AND sp.person=? ORDER BY s.start, s.end, e.id, t.sort")
+	phys.SQL(storer, sb.String(), func(stmt *phys.Stmt) {
+		var e event.Event
+		var t task.Task
+		var s shift.Shift
+
+		stmt.BindInt(int(pid))
+		for stmt.Step() {
+			signedUp := stmt.ColumnInt()
+			e.Scan(stmt, eventFields)
+			t.Scan(stmt, taskFields)
+			s.Scan(stmt, shiftFields)
+			fn(&e, &t, &s, signedUp)
+		}
+	})
+}
@@ -1,11 +1,14 @@
 package shiftperson
 
 import (
+	"fmt"
+
 	"sunnyvaleserv.org/portal/store/event"
 	"sunnyvaleserv.org/portal/store/internal/phys"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/store/shift"
 	"sunnyvaleserv.org/portal/store/task"
+	"sunnyvaleserv.org/portal/store/webhook"
 )
 
 const nextSignupSQL = `SELECT COALESCE(MAX(signed_up), 0) FROM shift_person WHERE shift=?`
@@ -44,6 +47,9 @@ func SignUp(storer phys.Storer, e *event.Event, t *task.Task, s *shift.Shift, p
 	if phys.RowsAffected(storer) != 0 {
 		phys.Audit(storer, "Event %s %q [%d]:: Task %s [%d]:: Shift %d:: sign up %q [%d]",
 			e.Start()[:10], e.Name(), e.ID(), t.Name(), t.ID(), s.ID(), p.InformalName(), p.ID())
+		phys.History(storer, "Event", int(e.ID()), fmt.Sprintf("Task %s:: Shift %d:: sign up", t.Name(), s.ID()), "", p.InformalName())
+		phys.History(storer, "Person", int(p.ID()), fmt.Sprintf("Event %s %s:: Task %s:: Shift %d:: sign up", e.Start()[:10], e.Name(), t.Name(), s.ID()), "", "")
+		phys.Webhook(storer, webhook.ShiftSignup, signupWebhookData(e, t, s, p))
 	}
 }
 
@@ -80,5 +86,18 @@ func Decline(storer phys.Storer, e *event.Event, t *task.Task, s *shift.Shift, p
 	if phys.RowsAffected(storer) != 0 {
 		phys.Audit(storer, "Event %s %q [%d]:: Task %s [%d]:: Shift %d:: decline %q [%d]",
 			e.Start()[:10], e.Name(), e.ID(), t.Name(), t.ID(), s.ID(), p.InformalName(), p.ID())
+		phys.History(storer, "Event", int(e.ID()), fmt.Sprintf("Task %s:: Shift %d:: decline", t.Name(), s.ID()), "", p.InformalName())
+		phys.History(storer, "Person", int(p.ID()), fmt.Sprintf("Event %s %s:: Task %s:: Shift %d:: decline", e.Start()[:10], e.Name(), t.Name(), s.ID()), "", "")
+		phys.Webhook(storer, webhook.ShiftCancel, signupWebhookData(e, t, s, p))
+	}
+}
+
+// signupWebhookData returns the webhook payload for a signup change.
+func signupWebhookData(e *event.Event, t *task.Task, s *shift.Shift, p *person.Person) any {
+	return map[string]any{
+		"event":  webhook.EventRef{ID: int(e.ID()), Name: e.Name(), Start: e.Start()},
+		"task":   webhook.Ref{ID: int(t.ID()), Name: t.Name()},
+		"shift":  s.ID(),
+		"person": webhook.Ref{ID: int(p.ID()), Name: p.InformalName()},
 	}
 }
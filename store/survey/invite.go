@@ -0,0 +1,54 @@
+package survey
+
+import (
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+// CreateInvite creates an invitation to respond to the survey for the
+// specified class, with the specified token.
+func CreateInvite(storer phys.Storer, cid class.ID, token string) {
+	phys.SQL(storer, `INSERT INTO survey_invite (token, class, responded) VALUES (?,?,FALSE)`, func(stmt *phys.Stmt) {
+		stmt.BindText(token)
+		stmt.BindInt(int(cid))
+		stmt.Step()
+	})
+	phys.Audit(storer, "ADD SurveyInvite %s for class [%d]", token, cid)
+}
+
+// InviteWithToken returns the invitation with the specified token, or nil if
+// there is none.
+func InviteWithToken(storer phys.Storer, token string) (i *Invite) {
+	phys.SQL(storer, `SELECT class, responded FROM survey_invite WHERE token=?`, func(stmt *phys.Stmt) {
+		stmt.BindText(token)
+		if stmt.Step() {
+			i = &Invite{Token: token}
+			i.Class = class.ID(stmt.ColumnInt())
+			i.Responded = stmt.ColumnBool()
+		}
+	})
+	return i
+}
+
+// Invited returns whether survey invitations have been sent for the specified
+// class.
+func Invited(storer phys.Storer, cid class.ID) (found bool) {
+	phys.SQL(storer, `SELECT 1 FROM survey_invite WHERE class=? LIMIT 1`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(cid))
+		found = stmt.Step()
+	})
+	return found
+}
+
+// InviteCounts returns the number of survey invitations sent for the
+// specified class, and the number of them that have been used to respond.
+func InviteCounts(storer phys.Storer, cid class.ID) (sent, responded int) {
+	phys.SQL(storer, `SELECT COUNT(*), COUNT(*) FILTER (WHERE responded) FROM survey_invite WHERE class=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(cid))
+		if stmt.Step() {
+			sent = stmt.ColumnInt()
+			responded = stmt.ColumnInt()
+		}
+	})
+	return sent, responded
+}
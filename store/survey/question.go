@@ -0,0 +1,177 @@
+package survey
+
+import (
+	"fmt"
+	"strings"
+
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+// questionColumnList is a comma-separated list of column names for survey
+// questions.
+const questionColumnList = `id, ctype, seq, kind, en_text, es_text, en_choices, es_choices`
+
+// scan reads columns from the specified statement into the question.
+func (q *Question) scan(stmt *phys.Stmt) {
+	q.ID = QuestionID(stmt.ColumnInt())
+	q.Type = class.Type(stmt.ColumnInt())
+	q.Seq = stmt.ColumnInt()
+	q.Kind = Kind(stmt.ColumnInt())
+	q.EnText = stmt.ColumnText()
+	q.EsText = stmt.ColumnText()
+	q.EnChoices = splitChoices(stmt.ColumnText())
+	q.EsChoices = splitChoices(stmt.ColumnText())
+}
+
+func splitChoices(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+const questionWithIDSQL = `SELECT ` + questionColumnList + ` FROM survey_question WHERE id=?`
+
+// QuestionWithID returns the question with the specified ID, or nil if it
+// does not exist.
+func QuestionWithID(storer phys.Storer, id QuestionID) (q *Question) {
+	phys.SQL(storer, questionWithIDSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(id))
+		if stmt.Step() {
+			q = new(Question)
+			q.scan(stmt)
+		}
+	})
+	return q
+}
+
+const questionsSQL = `SELECT ` + questionColumnList + ` FROM survey_question WHERE ctype=? ORDER BY seq`
+
+// Questions reads each question of the questionnaire for the specified class
+// type, in order.
+func Questions(storer phys.Storer, ctype class.Type, fn func(*Question)) {
+	phys.SQL(storer, questionsSQL, func(stmt *phys.Stmt) {
+		var q Question
+
+		stmt.BindInt(int(ctype))
+		for stmt.Step() {
+			q.scan(stmt)
+			fn(&q)
+		}
+	})
+}
+
+// HasQuestions returns whether the questionnaire for the specified class type
+// has any questions.
+func HasQuestions(storer phys.Storer, ctype class.Type) (found bool) {
+	phys.SQL(storer, `SELECT 1 FROM survey_question WHERE ctype=? LIMIT 1`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(ctype))
+		found = stmt.Step()
+	})
+	return found
+}
+
+const createQuestionSQL = `INSERT INTO survey_question (ctype, seq, kind, en_text, es_text, en_choices, es_choices) VALUES (?1,(SELECT COALESCE(MAX(seq),0)+1 FROM survey_question WHERE ctype=?1),?2,?3,?4,?5,?6)`
+
+// CreateQuestion adds the specified question to the end of the questionnaire
+// for its class type.  Its ID and Seq are set.
+func CreateQuestion(storer phys.Storer, q *Question) {
+	phys.SQL(storer, createQuestionSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(q.Type))
+		stmt.BindInt(int(q.Kind))
+		stmt.BindText(q.EnText)
+		stmt.BindText(q.EsText)
+		stmt.BindText(strings.Join(q.EnChoices, "\n"))
+		stmt.BindText(strings.Join(q.EsChoices, "\n"))
+		stmt.Step()
+	})
+	q.ID = QuestionID(phys.LastInsertRowID(storer))
+	phys.SQL(storer, `SELECT seq FROM survey_question WHERE id=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(q.ID))
+		if stmt.Step() {
+			q.Seq = stmt.ColumnInt()
+		}
+	})
+	context := fmt.Sprintf("ADD SurveyQuestion %s [%d]", q.Type, q.ID)
+	phys.Audit(storer, "%s:: seq = %d", context, q.Seq)
+	phys.Audit(storer, "%s:: kind = %s", context, q.Kind)
+	phys.Audit(storer, "%s:: en_text = %q", context, q.EnText)
+	phys.Audit(storer, "%s:: es_text = %q", context, q.EsText)
+	if len(q.EnChoices) != 0 {
+		phys.Audit(storer, "%s:: en_choices = %q", context, q.EnChoices)
+		phys.Audit(storer, "%s:: es_choices = %q", context, q.EsChoices)
+	}
+}
+
+const updateQuestionSQL = `UPDATE survey_question SET kind=?, en_text=?, es_text=?, en_choices=?, es_choices=? WHERE id=?`
+
+// Update updates the receiver question with the data in the specified
+// question, which must have the same ID.  Its Type and Seq are not changed.
+func (q *Question) Update(storer phys.Storer, u *Question) {
+	phys.SQL(storer, updateQuestionSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(u.Kind))
+		stmt.BindText(u.EnText)
+		stmt.BindText(u.EsText)
+		stmt.BindText(strings.Join(u.EnChoices, "\n"))
+		stmt.BindText(strings.Join(u.EsChoices, "\n"))
+		stmt.BindInt(int(q.ID))
+		stmt.Step()
+	})
+	context := fmt.Sprintf("SurveyQuestion %s [%d]", q.Type, q.ID)
+	if u.Kind != q.Kind {
+		phys.Audit(storer, "%s:: kind = %s", context, u.Kind)
+	}
+	if u.EnText != q.EnText {
+		phys.Audit(storer, "%s:: en_text = %q", context, u.EnText)
+	}
+	if u.EsText != q.EsText {
+		phys.Audit(storer, "%s:: es_text = %q", context, u.EsText)
+	}
+	if strings.Join(u.EnChoices, "\n") != strings.Join(q.EnChoices, "\n") {
+		phys.Audit(storer, "%s:: en_choices = %q", context, u.EnChoices)
+	}
+	if strings.Join(u.EsChoices, "\n") != strings.Join(q.EsChoices, "\n") {
+		phys.Audit(storer, "%s:: es_choices = %q", context, u.EsChoices)
+	}
+	q.Kind, q.EnText, q.EsText, q.EnChoices, q.EsChoices = u.Kind, u.EnText, u.EsText, u.EnChoices, u.EsChoices
+}
+
+const swapSeqSQL = `UPDATE survey_question SET seq=CASE id WHEN ?1 THEN ?4 ELSE ?3 END WHERE id IN (?1,?2)`
+
+// MoveUp moves the question one place earlier in its questionnaire, swapping
+// it with the question before it.  It does nothing if the question is already
+// first.
+func (q *Question) MoveUp(storer phys.Storer) {
+	var prev Question
+
+	phys.SQL(storer, `SELECT `+questionColumnList+` FROM survey_question WHERE ctype=? AND seq<? ORDER BY seq DESC LIMIT 1`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(q.Type))
+		stmt.BindInt(q.Seq)
+		if stmt.Step() {
+			prev.scan(stmt)
+		}
+	})
+	if prev.ID == 0 {
+		return
+	}
+	phys.SQL(storer, swapSeqSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(q.ID))
+		stmt.BindInt(int(prev.ID))
+		stmt.BindInt(q.Seq)
+		stmt.BindInt(prev.Seq)
+		stmt.Step()
+	})
+	q.Seq, prev.Seq = prev.Seq, q.Seq
+	phys.Audit(storer, "SurveyQuestion %s [%d]:: seq = %d", q.Type, q.ID, q.Seq)
+	phys.Audit(storer, "SurveyQuestion %s [%d]:: seq = %d", prev.Type, prev.ID, prev.Seq)
+}
+
+// Delete deletes the question, along with all answers to it.
+func (q *Question) Delete(storer phys.Storer) {
+	phys.SQL(storer, `DELETE FROM survey_question WHERE id=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(q.ID))
+		stmt.Step()
+	})
+	phys.Audit(storer, "DELETE SurveyQuestion %s [%d]", q.Type, q.ID)
+}
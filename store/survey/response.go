@@ -0,0 +1,68 @@
+package survey
+
+import (
+	"strings"
+
+	"sunnyvaleserv.org/portal/store/class"
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+// Respond records a response to the survey for the invitation's class, and
+// marks the invitation as used.  To preserve the anonymity of the response, it
+// is not linked to the invitation, and neither change is audited.  The name
+// may be empty for an anonymous response.
+func (i *Invite) Respond(storer phys.Storer, name string, answers map[QuestionID]string) {
+	var rid ResponseID
+
+	phys.SQL(storer, `UPDATE survey_invite SET responded=TRUE WHERE token=?`, func(stmt *phys.Stmt) {
+		stmt.BindText(i.Token)
+		stmt.Step()
+	})
+	i.Responded = true
+	phys.SQL(storer, `INSERT INTO survey_response (class, name) VALUES (?,?)`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(i.Class))
+		stmt.BindNullText(strings.TrimSpace(name))
+		stmt.Step()
+	})
+	rid = ResponseID(phys.LastInsertRowID(storer))
+	for qid, answer := range answers {
+		phys.SQL(storer, `INSERT INTO survey_answer (response, question, answer) VALUES (?,?,?)`, func(stmt *phys.Stmt) {
+			stmt.BindInt(int(rid))
+			stmt.BindInt(int(qid))
+			stmt.BindText(answer)
+			stmt.Step()
+		})
+	}
+}
+
+const responsesSQL = `SELECT r.id, r.name, a.question, a.answer FROM survey_response r LEFT JOIN survey_answer a ON a.response=r.id WHERE r.class=? ORDER BY r.id`
+
+// Responses reads each response to the survey for the specified class, in the
+// order they were received.
+func Responses(storer phys.Storer, cid class.ID, fn func(*Response)) {
+	var resp *Response
+
+	phys.SQL(storer, responsesSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(cid))
+		for stmt.Step() {
+			rid := ResponseID(stmt.ColumnInt())
+			if resp != nil && resp.ID != rid {
+				fn(resp)
+				resp = nil
+			}
+			if resp == nil {
+				resp = &Response{ID: rid, Class: cid, Answers: make(map[QuestionID]string)}
+				resp.Name = stmt.ColumnText()
+			} else {
+				stmt.ColumnText()
+			}
+			if !stmt.ColumnIsNull() {
+				qid := QuestionID(stmt.ColumnInt())
+				resp.Answers[qid] = stmt.ColumnText()
+			}
+		}
+	})
+	if resp != nil {
+		fn(resp)
+	}
+}
@@ -0,0 +1,126 @@
+// Package survey defines the types for post-class feedback surveys.  Each class
+// type has a questionnaire, which is a list of bilingual Questions.  After a
+// class ends, each registered student is sent an invitation with a tokenized
+// link to respond.  Responses are anonymous unless the student chooses to
+// include their name, and are not linked to the invitations that prompted
+// them.
+package survey
+
+import (
+	"sunnyvaleserv.org/portal/store/class"
+)
+
+// QuestionID uniquely identifies a survey question.
+type QuestionID int
+
+// Kind is the kind of a survey question.
+type Kind uint8
+
+// Values for Kind:
+const (
+	_ Kind = iota
+	// Rating is a question answered with a rating from 1 to MaxRating.
+	Rating
+	// Text is a question answered with free-form text.
+	Text
+	// Choice is a question answered by choosing one of a list of choices.
+	Choice
+)
+
+// MaxRating is the highest rating that can be given to a Rating question.
+const MaxRating = 5
+
+// AllKinds is the list of all question kinds.
+var AllKinds = []Kind{Rating, Text, Choice}
+
+// String returns the name of the Kind.
+func (k Kind) String() string {
+	switch k {
+	case Rating:
+		return "Rating (1–5)"
+	case Text:
+		return "Free Text"
+	case Choice:
+		return "Multiple Choice"
+	default:
+		return ""
+	}
+}
+
+// Int returns the Kind as an integer.
+func (k Kind) Int() int { return int(k) }
+
+// Question is a single question of the questionnaire for a class type.
+type Question struct {
+	// ID is the unique identifier of the Question.
+	ID QuestionID
+	// Type is the class type whose questionnaire includes the Question.
+	Type class.Type
+	// Seq is the order of the Question in the questionnaire.
+	Seq int
+	// Kind is the kind of the Question.
+	Kind Kind
+	// EnText is the text of the Question in English.
+	EnText string
+	// EsText is the text of the Question in Spanish.
+	EsText string
+	// EnChoices is the list of choices for a Choice question, in English.
+	EnChoices []string
+	// EsChoices is the list of choices for a Choice question, in Spanish.
+	// It has the same length as EnChoices.
+	EsChoices []string
+}
+
+// Text returns the text of the Question in the specified language.
+func (q *Question) Text(lang string) string {
+	if lang == "es" && q.EsText != "" {
+		return q.EsText
+	}
+	return q.EnText
+}
+
+// Choices returns the list of choices for the Question in the specified
+// language.
+func (q *Question) Choices(lang string) []string {
+	if lang == "es" && len(q.EsChoices) == len(q.EnChoices) {
+		return q.EsChoices
+	}
+	return q.EnChoices
+}
+
+// Clone returns a copy of the Question.
+func (q *Question) Clone() (clone *Question) {
+	clone = new(Question)
+	*clone = *q
+	return clone
+}
+
+// Invite is an invitation to a student to respond to the survey for a class.
+type Invite struct {
+	// Token is the random token that identifies the Invite in the survey
+	// URL.
+	Token string
+	// Class is the class the survey is about.
+	Class class.ID
+	// Responded is whether the invitation has been used to respond.
+	Responded bool
+}
+
+// ResponseID uniquely identifies a survey response.
+type ResponseID int
+
+// Response is a single student's response to the survey for a class.
+type Response struct {
+	// ID is the unique identifier of the Response.
+	ID ResponseID
+	// Class is the class the Response is about.
+	Class class.ID
+	// Name is the name of the student who responded, if they chose to
+	// include it, or an empty string if the Response is anonymous.
+	Name string
+	// Answers maps question IDs to answers.  The answer to a Rating
+	// question is the rating, as a decimal string; the answer to a Choice
+	// question is the index of the choice, as a decimal string.  Questions
+	// that were not answered have no entry.
+	Answers map[QuestionID]string
+}
@@ -102,8 +102,10 @@ func bindUpdater(stmt *phys.Stmt, u *Updater) {
 
 func (t *Task) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
 	context := fmt.Sprintf("Event %s %q [%d]:: Task %q [%d]", u.Event.Start()[:10], u.Event.Name(), u.Event.ID(), u.Name, t.id)
+	label := "Task " + u.Name
 	if create {
 		context = "ADD " + context
+		label = "ADD " + label
 	}
 	if u.Event.ID() != t.event {
 		phys.Audit(storer, "%s:: event = %s %q [%d]", context, u.Event.Start()[:10], u.Event.Name(), u.Event.ID())
@@ -111,18 +113,22 @@ func (t *Task) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
 	}
 	if u.Name != t.name {
 		phys.Audit(storer, "%s:: name = %q", context, u.Name)
+		phys.History(storer, "Event", int(u.Event.ID()), label+":: name", t.name, u.Name)
 		t.name = u.Name
 	}
 	if u.Org != t.org {
 		phys.Audit(storer, "%s:: org = %s [%d]", context, u.Org, u.Org)
+		phys.History(storer, "Event", int(u.Event.ID()), label+":: org", t.org.String(), u.Org.String())
 		t.org = u.Org
 	}
 	if u.Flags != t.flags {
 		phys.Audit(storer, "%s:: flags = 0x%x", context, u.Flags)
+		phys.History(storer, "Event", int(u.Event.ID()), label+":: flags", fmt.Sprintf("0x%x", t.flags), fmt.Sprintf("0x%x", u.Flags))
 		t.flags = u.Flags
 	}
 	if u.Details != t.details {
 		phys.Audit(storer, "%s:: details = %q", context, u.Details)
+		phys.History(storer, "Event", int(u.Event.ID()), label+":: details", t.details, u.Details)
 		t.details = u.Details
 	}
 }
@@ -153,4 +159,5 @@ func (t *Task) Delete(storer phys.Storer, e *event.Event) {
 		stmt.Step()
 	})
 	phys.Audit(storer, "Event %s %q [%d]:: DELETE Task %q [%d]", e.Start()[:10], e.Name(), e.ID(), t.Name(), t.ID())
+	phys.History(storer, "Event", int(e.ID()), "DELETE Task "+t.Name(), "", "")
 }
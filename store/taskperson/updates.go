@@ -1,10 +1,14 @@
 package taskperson
 
 import (
+	"fmt"
+	"strconv"
+
 	"sunnyvaleserv.org/portal/store/event"
 	"sunnyvaleserv.org/portal/store/internal/phys"
 	"sunnyvaleserv.org/portal/store/person"
 	"sunnyvaleserv.org/portal/store/task"
+	"sunnyvaleserv.org/portal/store/webhook"
 )
 
 const SetEventFields = event.FID | event.FStart | event.FName
@@ -50,9 +54,36 @@ AUDIT:
 	if flags != pflags {
 		phys.Audit(storer, "Event %s %q [%d]:: Task %q [%d]:: Person %q [%d]:: flags = 0x%x",
 			e.Start()[:10], e.Name(), e.ID(), t.Name(), t.ID(), p.InformalName(), p.ID(), flags)
+		phys.History(storer, "Event", int(e.ID()), fmt.Sprintf("Task %s:: Person %s:: flags", t.Name(), p.InformalName()),
+			fmt.Sprintf("0x%x", pflags), fmt.Sprintf("0x%x", flags))
+		phys.History(storer, "Person", int(p.ID()), fmt.Sprintf("Event %s %s:: Task %s:: flags", e.Start()[:10], e.Name(), t.Name()),
+			fmt.Sprintf("0x%x", pflags), fmt.Sprintf("0x%x", flags))
 	}
 	if minutes != pminutes {
 		phys.Audit(storer, "Event %s %q [%d]:: Task %q [%d]:: Person %q [%d]:: minutes = %d",
 			e.Start()[:10], e.Name(), e.ID(), t.Name(), t.ID(), p.InformalName(), p.ID(), minutes)
+		phys.History(storer, "Event", int(e.ID()), fmt.Sprintf("Task %s:: Person %s:: minutes", t.Name(), p.InformalName()),
+			strconv.Itoa(int(pminutes)), strconv.Itoa(int(minutes)))
+		phys.History(storer, "Person", int(p.ID()), fmt.Sprintf("Event %s %s:: Task %s:: minutes", e.Start()[:10], e.Name(), t.Name()),
+			strconv.Itoa(int(pminutes)), strconv.Itoa(int(minutes)))
+		phys.Webhook(storer, webhook.HoursRecorded, map[string]any{
+			"event":   webhook.EventRef{ID: int(e.ID()), Name: e.Name(), Start: e.Start()},
+			"task":    webhook.Ref{ID: int(t.ID()), Name: t.Name()},
+			"person":  webhook.Ref{ID: int(p.ID()), Name: p.InformalName()},
+			"minutes": minutes,
+		})
+	}
+}
+
+// AuditEmergencySheet records in the audit log that the emergency contact
+// information for the specified people was viewed on the emergency sheet for
+// the specified Task.  The Event must have SetEventFields, the Task must have
+// SetTaskFields, and the people must have SetPersonFields.
+func AuditEmergencySheet(storer phys.Storer, e *event.Event, t *task.Task, people []*person.Person) {
+	if len(people) == 0 {
+		phys.Audit(storer, "Event %s %q [%d]:: Task %q [%d]:: EMERGENCY SHEET (no people)", e.Start()[:10], e.Name(), e.ID(), t.Name(), t.ID())
+	}
+	for _, p := range people {
+		phys.Audit(storer, "Event %s %q [%d]:: Task %q [%d]:: EMERGENCY SHEET Person %q [%d]", e.Start()[:10], e.Name(), e.ID(), t.Name(), t.ID(), p.InformalName(), p.ID())
 	}
 }
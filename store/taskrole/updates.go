@@ -1,6 +1,8 @@
 package taskrole
 
 import (
+	"fmt"
+
 	"sunnyvaleserv.org/portal/store/event"
 	"sunnyvaleserv.org/portal/store/internal/phys"
 	"sunnyvaleserv.org/portal/store/role"
@@ -56,6 +58,8 @@ func Set(storer phys.Storer, e *event.Event, t *task.Task, roles, prev []*role.R
 			})
 			phys.Audit(storer, "Event %s %q [%d]:: Task %q [%d]:: ADD Role %q [%d]",
 				e.Start()[:10], e.Name(), e.ID(), t.Name(), t.ID(), rl.Name(), rid)
+			phys.History(storer, "Event", int(e.ID()), "Task "+t.Name()+":: ADD role", "", rl.Name())
+			phys.History(storer, "Role", int(rid), "ADD task", "", fmt.Sprintf("%s %s:: %s", e.Start()[:10], e.Name(), t.Name()))
 		}
 	}
 	for rid, rl := range pmap {
@@ -67,6 +71,8 @@ func Set(storer phys.Storer, e *event.Event, t *task.Task, roles, prev []*role.R
 			})
 			phys.Audit(storer, "Event %s %q [%d]:: Task %q [%d]:: REMOVE Role %q [%d]",
 				e.Start()[:10], e.Name(), e.ID(), t.Name(), t.ID(), rl.Name(), rid)
+			phys.History(storer, "Event", int(e.ID()), "Task "+t.Name()+":: REMOVE role", rl.Name(), "")
+			phys.History(storer, "Role", int(rid), "REMOVE task", fmt.Sprintf("%s %s:: %s", e.Start()[:10], e.Name(), t.Name()), "")
 		}
 	}
 }
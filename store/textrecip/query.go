@@ -99,3 +99,34 @@ func AllRepliesFromRecipient(storer phys.Storer, tmid textmsg.ID, pid person.ID,
 		}
 	})
 }
+
+const allTextsToRecipientSQL1 = `SELECT tr.number, tr.status, tr.timestamp, `
+const allTextsToRecipientSQL2 = ` FROM textmsg_recipient tr, textmsg t WHERE tr.textmsg=t.id AND tr.recipient=? ORDER BY t.timestamp`
+
+// AllTextsToRecipient fetches all of the text messages sent to the specified
+// person, in chronological order.
+func AllTextsToRecipient(
+	storer phys.Storer, pid person.ID, fields textmsg.Fields,
+	fn func(t *textmsg.TextMessage, number, status string, timestamp time.Time),
+) {
+	var sb strings.Builder
+	sb.WriteString(allTextsToRecipientSQL1)
+	textmsg.ColumnList(&sb, fields)
+	sb.WriteString(allTextsToRecipientSQL2)
+	phys.SQL(storer, sb.String(), func(stmt *phys.Stmt) {
+		var (
+			t         textmsg.TextMessage
+			number    string
+			status    string
+			timestamp time.Time
+		)
+		stmt.BindInt(int(pid))
+		for stmt.Step() {
+			number = stmt.ColumnText()
+			status = stmt.ColumnText()
+			timestamp, _ = time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+			t.Scan(stmt, fields)
+			fn(&t, number, status, timestamp)
+		}
+	})
+}
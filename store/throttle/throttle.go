@@ -0,0 +1,91 @@
+// Package throttle records attempts at rate-limited actions, such as public
+// class registrations, so that excessive attempts from the same source can be
+// refused.  Each attempt is recorded under a key that identifies the action
+// and its source, e.g. "classreg-ip:192.0.2.1".
+package throttle
+
+import (
+	"strings"
+	"time"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+const timestampFormat = "2006-01-02T15:04:05"
+
+// retention is the length of time for which attempts are remembered.  No rate
+// limit can have a window longer than this.
+const retention = 24 * time.Hour
+
+const recordSQL = `INSERT INTO throttle (key, timestamp) VALUES (?,?)`
+
+// Record records an attempt under the specified key at the specified time.
+// It also forgets attempts that are too old to matter any more.
+func Record(storer phys.Storer, key string, now time.Time) {
+	phys.SQL(storer, recordSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(key)
+		stmt.BindText(now.In(time.Local).Format(timestampFormat))
+		stmt.Step()
+	})
+	deleteExpired(storer, now)
+	// Intentionally not audited due to noise.
+}
+
+const countSQL = `SELECT COUNT(*) FROM throttle WHERE key=? AND timestamp>=?`
+
+// Count returns the number of attempts recorded under the specified key since
+// the specified time.
+func Count(storer phys.Storer, key string, since time.Time) (count int) {
+	phys.SQL(storer, countSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(key)
+		stmt.BindText(since.In(time.Local).Format(timestampFormat))
+		if stmt.Step() {
+			count = stmt.ColumnInt()
+		}
+	})
+	return count
+}
+
+const summarizeSQL = `SELECT key, COUNT(*), MAX(timestamp) FROM throttle WHERE key LIKE ? ESCAPE '\' AND timestamp>=? GROUP BY key ORDER BY key`
+
+// Summarize calls fn for each key starting with the specified prefix under
+// which attempts have been recorded since the specified time, giving the number
+// of those attempts and the time of the latest one.
+func Summarize(storer phys.Storer, prefix string, since time.Time, fn func(key string, count int, last time.Time)) {
+	phys.SQL(storer, summarizeSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(likeEscaper.Replace(prefix) + "%")
+		stmt.BindText(since.In(time.Local).Format(timestampFormat))
+		for stmt.Step() {
+			key := stmt.ColumnText()
+			count := stmt.ColumnInt()
+			last, _ := time.ParseInLocation(timestampFormat, stmt.ColumnText(), time.Local)
+			fn(key, count, last)
+		}
+	})
+}
+
+// likeEscaper escapes the special characters of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+const clearSQL = `DELETE FROM throttle WHERE key=?`
+
+// Clear forgets all attempts recorded under the specified key.
+func Clear(storer phys.Storer, key string) {
+	phys.SQL(storer, clearSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(key)
+		stmt.Step()
+	})
+	if phys.RowsAffected(storer) != 0 {
+		phys.Audit(storer, "AuthN:: CLEAR Throttle %s", key)
+	}
+}
+
+const deleteExpiredSQL = `DELETE FROM throttle WHERE timestamp<?`
+
+// deleteExpired deletes all attempts older than the retention period.
+func deleteExpired(storer phys.Storer, now time.Time) {
+	phys.SQL(storer, deleteExpiredSQL, func(stmt *phys.Stmt) {
+		stmt.BindText(now.Add(-retention).In(time.Local).Format(timestampFormat))
+		stmt.Step()
+	})
+}
@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"time"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+const timestampFormat = "2006-01-02T15:04:05"
+
+const deliveryColumns = `id, webhook, event, payload, created, attempts, next_attempt, last_attempt, status, error, delivered`
+
+// scanTime reads a timestamp column, which may be NULL.
+func scanTime(stmt *phys.Stmt) (t time.Time) {
+	if s := stmt.ColumnText(); s != "" {
+		t, _ = time.ParseInLocation(timestampFormat, s, time.Local)
+	}
+	return t
+}
+
+// scanDelivery reads a delivery from the columns of a statement.
+func scanDelivery(stmt *phys.Stmt) (d *Delivery) {
+	d = new(Delivery)
+	d.ID = DeliveryID(stmt.ColumnInt())
+	d.Webhook = ID(stmt.ColumnInt())
+	d.Event = stmt.ColumnText()
+	d.Payload = stmt.ColumnText()
+	d.Created = scanTime(stmt)
+	d.Attempts = stmt.ColumnInt()
+	d.NextAttempt = scanTime(stmt)
+	d.LastAttempt = scanTime(stmt)
+	d.Status = stmt.ColumnInt()
+	d.Error = stmt.ColumnText()
+	d.Delivered = scanTime(stmt)
+	return d
+}
+
+const deliveryWithIDSQL = `SELECT ` + deliveryColumns + ` FROM webhook_delivery WHERE id=?`
+
+// DeliveryWithID returns the delivery with the specified ID, or nil if it does
+// not exist.
+func DeliveryWithID(storer phys.Storer, id DeliveryID) (d *Delivery) {
+	phys.SQL(storer, deliveryWithIDSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(id))
+		if stmt.Step() {
+			d = scanDelivery(stmt)
+		}
+	})
+	return d
+}
+
+const deliveriesSQL = `SELECT ` + deliveryColumns + ` FROM webhook_delivery WHERE webhook=? ORDER BY created DESC, id DESC LIMIT ?`
+
+// Deliveries calls fn for each of the most recent deliveries to the webhook,
+// up to limit, newest first.
+func (w *Webhook) Deliveries(storer phys.Storer, limit int, fn func(*Delivery)) {
+	phys.SQL(storer, deliveriesSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(w.ID))
+		stmt.BindInt(limit)
+		for stmt.Step() {
+			fn(scanDelivery(stmt))
+		}
+	})
+}
+
+const countsSQL = `SELECT COUNT(*) FILTER (WHERE next_attempt IS NOT NULL), COUNT(*) FILTER (WHERE next_attempt IS NULL AND delivered IS NULL) FROM webhook_delivery WHERE webhook=?`
+
+// Counts returns the number of deliveries to the webhook that are pending
+// (queued or awaiting retry), and the number that have failed permanently.
+func (w *Webhook) Counts(storer phys.Storer) (pending, failed int) {
+	phys.SQL(storer, countsSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(w.ID))
+		stmt.Step()
+		pending = stmt.ColumnInt()
+		failed = stmt.ColumnInt()
+	})
+	return pending, failed
+}
+
+const retrySQL = `UPDATE webhook_delivery SET next_attempt=? WHERE id=? AND delivered IS NULL`
+
+// Retry makes the delivery due for an immediate attempt, whether it was
+// awaiting a retry or had failed permanently, and attempts it.  It has no
+// effect on deliveries that have already succeeded.  It must not be called
+// within a transaction.
+func (d *Delivery) Retry(storer phys.Storer) {
+	storer.AsStore().Transaction(func() {
+		phys.SQL(storer, retrySQL, func(stmt *phys.Stmt) {
+			stmt.BindText(time.Now().Format(timestampFormat))
+			stmt.BindInt(int(d.ID))
+			stmt.Step()
+		})
+	})
+	phys.DeliverWebhook(storer, int(d.ID))
+}
+
+// DeliverDue attempts every delivery that is due, i.e., every delivery whose
+// first attempt didn't happen or whose retry time has come.  It must not be
+// called within a transaction.
+func DeliverDue(storer phys.Storer) {
+	phys.DeliverDueWebhooks(storer)
+}
+
+const pruneSQL = `DELETE FROM webhook_delivery WHERE next_attempt IS NULL AND created<?`
+
+// PruneDeliveries removes completed deliveries (whether successful or failed)
+// created before the specified time from the delivery log.
+func PruneDeliveries(storer phys.Storer, before time.Time) {
+	storer.AsStore().Transaction(func() {
+		phys.SQL(storer, pruneSQL, func(stmt *phys.Stmt) {
+			stmt.BindText(before.Format(timestampFormat))
+			stmt.Step()
+		})
+	})
+}
@@ -0,0 +1,79 @@
+package webhook
+
+// Names of events for which webhook notifications are sent:
+const (
+	// PersonCreated is sent when a person is added to the database.
+	PersonCreated = "person.created"
+	// RoleGranted is sent when a role is assigned to a person.  (Roles
+	// that a person holds only by implication from other roles are not
+	// notified.)
+	RoleGranted = "role.granted"
+	// RoleRevoked is sent when a role is unassigned from a person.
+	RoleRevoked = "role.revoked"
+	// ShiftSignup is sent when a person signs up for a shift.
+	ShiftSignup = "shift.signup"
+	// ShiftCancel is sent when a person cancels their signup for a shift.
+	ShiftCancel = "shift.cancel"
+	// EventCreated is sent when an event is created.
+	EventCreated = "event.created"
+	// EventChanged is sent when the details of an event are changed.
+	EventChanged = "event.changed"
+	// EventCancelled is sent when an event is deleted.
+	EventCancelled = "event.cancelled"
+	// ClassRegistered is sent when someone registers for a class.
+	ClassRegistered = "class.registered"
+	// HoursRecorded is sent when the volunteer hours a person spent on a
+	// task are recorded or changed.
+	HoursRecorded = "hours.recorded"
+)
+
+// AllEvents is the list of all events for which notifications can be sent,
+// in the order they are presented.
+var AllEvents = []string{
+	PersonCreated, RoleGranted, RoleRevoked, ShiftSignup, ShiftCancel,
+	EventCreated, EventChanged, EventCancelled, ClassRegistered, HoursRecorded,
+}
+
+// EventLabel returns the description of an event shown to webmasters, or an
+// empty string if the event is not known.
+func EventLabel(event string) string {
+	switch event {
+	case PersonCreated:
+		return "Person created"
+	case RoleGranted:
+		return "Role granted"
+	case RoleRevoked:
+		return "Role revoked"
+	case ShiftSignup:
+		return "Shift signup"
+	case ShiftCancel:
+		return "Shift signup cancelled"
+	case EventCreated:
+		return "Event created"
+	case EventChanged:
+		return "Event changed"
+	case EventCancelled:
+		return "Event cancelled"
+	case ClassRegistered:
+		return "Class registration"
+	case HoursRecorded:
+		return "Volunteer hours recorded"
+	default:
+		return ""
+	}
+}
+
+// Ref is a reference to a person, role, or task in a notification payload.
+type Ref struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// EventRef is a reference to an event in a notification payload.  Start and
+// End are in YYYY-MM-DDTHH:MM format.
+type EventRef struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Start string `json:"start"`
+	End   string `json:"end,omitempty"`
+}
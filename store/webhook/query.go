@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"strings"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+const columns = `id, url, secret, events, flags`
+
+// scan reads a webhook from the columns of a statement.
+func scan(stmt *phys.Stmt) (w *Webhook) {
+	w = new(Webhook)
+	w.ID = ID(stmt.ColumnInt())
+	w.URL = stmt.ColumnText()
+	w.Secret = stmt.ColumnText()
+	w.Events = strings.Fields(stmt.ColumnText())
+	w.Flags = Flag(stmt.ColumnHexInt())
+	return w
+}
+
+const withIDSQL = `SELECT ` + columns + ` FROM webhook WHERE id=?`
+
+// WithID returns the webhook with the specified ID, or nil if it does not
+// exist.
+func WithID(storer phys.Storer, id ID) (w *Webhook) {
+	phys.SQL(storer, withIDSQL, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(id))
+		if stmt.Step() {
+			w = scan(stmt)
+		}
+	})
+	return w
+}
+
+const allSQL = `SELECT ` + columns + ` FROM webhook ORDER BY url, id`
+
+// All calls fn for each webhook, in order by URL.
+func All(storer phys.Storer, fn func(*Webhook)) {
+	phys.SQL(storer, allSQL, func(stmt *phys.Stmt) {
+		for stmt.Step() {
+			fn(scan(stmt))
+		}
+	})
+}
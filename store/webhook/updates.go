@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"sunnyvaleserv.org/portal/store/internal/phys"
+)
+
+// Updater is a structure that can be filled with data for a new or changed
+// webhook, and then later applied.  For creating new webhooks, it can simply
+// be instantiated with new().  For updating existing webhooks, either *every*
+// field in it must be set, or it should be instantiated with the Updater
+// method of the webhook being changed.
+type Updater Webhook
+
+// Updater returns a new Updater for the specified webhook, with its data
+// matching the current data for the webhook.
+func (w *Webhook) Updater() (u *Updater) {
+	u = &Updater{
+		ID:     w.ID,
+		URL:    w.URL,
+		Secret: w.Secret,
+		Events: slices.Clone(w.Events),
+		Flags:  w.Flags,
+	}
+	return u
+}
+
+const createSQL = `INSERT INTO webhook (id, url, secret, events, flags) VALUES (?,?,?,?,?)`
+
+// Create creates a new webhook, with the data in the Updater.
+func Create(storer phys.Storer, u *Updater) (w *Webhook) {
+	w = new(Webhook)
+	phys.SQL(storer, createSQL, func(stmt *phys.Stmt) {
+		stmt.BindNullInt(int(u.ID))
+		bindUpdater(stmt, u)
+		stmt.Step()
+		if u.ID != 0 {
+			w.ID = u.ID
+		} else {
+			w.ID = ID(phys.LastInsertRowID(storer))
+		}
+	})
+	w.auditAndUpdate(storer, u, true)
+	return w
+}
+
+const updateSQL = `UPDATE webhook SET url=?, secret=?, events=?, flags=? WHERE id=?`
+
+// Update updates the existing webhook, with the data in the Updater.
+func (w *Webhook) Update(storer phys.Storer, u *Updater) {
+	phys.SQL(storer, updateSQL, func(stmt *phys.Stmt) {
+		bindUpdater(stmt, u)
+		stmt.BindInt(int(w.ID))
+		stmt.Step()
+	})
+	w.auditAndUpdate(storer, u, false)
+}
+
+func bindUpdater(stmt *phys.Stmt, u *Updater) {
+	stmt.BindText(u.URL)
+	stmt.BindText(u.Secret)
+	stmt.BindText(strings.Join(u.Events, " "))
+	stmt.BindHexInt(int(u.Flags))
+}
+
+func (w *Webhook) auditAndUpdate(storer phys.Storer, u *Updater, create bool) {
+	context := fmt.Sprintf("Webhook %q [%d]", u.URL, w.ID)
+	if create {
+		context = "ADD " + context
+	}
+	if u.URL != w.URL {
+		phys.Audit(storer, "%s:: url = %q", context, u.URL)
+		w.URL = u.URL
+	}
+	if u.Secret != w.Secret {
+		// The secret itself is not logged.
+		phys.Audit(storer, "%s:: secret changed", context)
+		w.Secret = u.Secret
+	}
+	if !slices.Equal(u.Events, w.Events) {
+		phys.Audit(storer, "%s:: events = %s", context, strings.Join(u.Events, " "))
+		w.Events = slices.Clone(u.Events)
+	}
+	if u.Flags != w.Flags {
+		phys.Audit(storer, "%s:: flags = 0x%x", context, u.Flags)
+		w.Flags = u.Flags
+	}
+}
+
+// Delete deletes the receiver webhook, along with its delivery log.
+func (w *Webhook) Delete(storer phys.Storer) {
+	phys.SQL(storer, `DELETE FROM webhook WHERE id=?`, func(stmt *phys.Stmt) {
+		stmt.BindInt(int(w.ID))
+		stmt.Step()
+	})
+	phys.Audit(storer, "DELETE Webhook %q [%d]", w.URL, w.ID)
+}
@@ -0,0 +1,81 @@
+// Package webhook defines the Webhook type, which describes an external URL
+// to which notifications of portal events are sent, and the Delivery type,
+// which records the sending of one such notification.  The notifications
+// themselves are queued by the store packages that make the changes, and
+// delivered by the physical storage layer when their transactions commit.
+package webhook
+
+import "time"
+
+// ID uniquely identifies a webhook.
+type ID int
+
+// Webhook describes an external URL to which notifications of portal events
+// are sent.
+type Webhook struct {
+	// ID is the unique identifier of the webhook.
+	ID ID
+	// URL is the URL to which notifications are POSTed.
+	URL string
+	// Secret is the key with which notifications are signed.
+	Secret string
+	// Events is the list of events for which notifications are sent.
+	Events []string
+	// Flags is a set of flags describing the webhook.
+	Flags Flag
+}
+
+// Flag is a flag describing a webhook.
+type Flag uint
+
+// Values for Flag:
+const (
+	// Disabled indicates that notifications are not being sent to the
+	// webhook.  They are still queued, and will be sent if the webhook is
+	// re-enabled.  (The physical storage layer hard-codes this value.)
+	Disabled Flag = 1 << iota
+)
+
+// HasEvent returns whether the webhook subscribes to the specified event.
+func (w *Webhook) HasEvent(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryID uniquely identifies a webhook delivery.
+type DeliveryID int
+
+// Delivery records the sending of a notification to a webhook.
+type Delivery struct {
+	// ID is the unique identifier of the delivery.
+	ID DeliveryID
+	// Webhook is the webhook to which the notification is sent.
+	Webhook ID
+	// Event is the name of the event being notified.
+	Event string
+	// Payload is the JSON body of the notification.
+	Payload string
+	// Created is the time the notification was queued.
+	Created time.Time
+	// Attempts is the number of delivery attempts made so far.
+	Attempts int
+	// NextAttempt is the time of the next delivery attempt, or zero if no
+	// more attempts will be made.
+	NextAttempt time.Time
+	// LastAttempt is the time of the most recent delivery attempt, or zero
+	// if none has been made.
+	LastAttempt time.Time
+	// Status is the HTTP status returned by the most recent delivery
+	// attempt, or zero if there was none.
+	Status int
+	// Error is the error from the most recent delivery attempt, if it
+	// failed.
+	Error string
+	// Delivered is the time the notification was successfully delivered,
+	// or zero if it hasn't been.
+	Delivered time.Time
+}
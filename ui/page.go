@@ -58,7 +58,7 @@ func Page(r *request.Request, user *person.Person, opts PageOpts, fn func(*htmlb
 	defer html.Close()
 	pageHead(html, opts.Title)
 
-	body := html.E("body class=page", user == nil, "class=page-noMenu")
+	body := html.E("body class=page", user == nil, "class=page-noMenu", user != nil && r.Impersonator != 0, "class=page-impersonating")
 	pageTitle(r, body, user, opts.Banner, opts.Title, opts.NoHome)
 	if user != nil {
 		pageMenu(body, r, user, opts.MenuItem)
@@ -113,6 +113,12 @@ func pageTitle(r *request.Request, h *htmlb.Element, user *person.Person, banner
 		h.E("div class=pageTitleText up-hungry>Sunnyvale SERV")
 	}
 	h.E("div class=pageTitleSearch").E("a class=nolink href=/search").E("s-icon icon=search")
+	if user != nil && r.Impersonator != 0 {
+		banner := h.E("form class=pageImpersonating method=POST action=/impersonate/end up-target=body")
+		banner.E("input type=hidden name=csrf value=%s", r.CSRF)
+		banner.E("span>You are viewing the site as %s.", user.InformalName())
+		banner.E("input type=submit class='sbtn sbtn-xsmall sbtn-secondary' value='Return to My Account'")
+	}
 }
 
 func pageMenu(h *htmlb.Element, r *request.Request, user *person.Person, menuItem string) {
@@ -137,11 +143,17 @@ func pageMenu(h *htmlb.Element, r *request.Request, user *person.Person, menuIte
 	}
 	ul.E("li").E("a href=/files up-target=.pageCanvas up-alias=/files/* class=pageMenuItem",
 		menuItem == "files", "class=up-current").R(r.Loc("Files"))
-	if user.HasPrivLevel(0, enum.PrivLeader) {
+	// This is auth.Can for any of the permissions that grant access to a
+	// report, which can't be called here without an import cycle.
+	if user.HasPrivLevel(0, enum.PrivLeader) || user.HasPermission(0, enum.PermEventAttendance) ||
+		user.HasPermission(0, enum.PermClassManage) || user.HasPermission(0, enum.PermPersonViewClearance) ||
+		user.HasPermission(0, enum.PermActivationView) {
 		ul.E("li").E("a href=/reports/attendance up-target=.pageCanvas up-alias=/reports/* class=pageMenuItem",
 			menuItem == "reports", "class=up-current").R("Reports")
 	}
-	if listperson.CanSendText(r, user.ID()) {
+	// This is auth.Can(user, enum.PermTextSend, 0), which can't be called
+	// here without an import cycle.
+	if listperson.CanSendText(r, user.ID()) || user.IsWebmaster() || user.HasPermission(0, enum.PermTextSend) {
 		ul.E("li").E("a href=/texts up-target=.pageCanvas up-alias=/texts/* class=pageMenuItem",
 			menuItem == "texts", "class=up-current").R("Texts")
 	}
@@ -0,0 +1,16 @@
+package ui
+
+import (
+	"sunnyvaleserv.org/portal/store/person"
+	"sunnyvaleserv.org/portal/util/htmlb"
+)
+
+// PersonPhoto emits an img element showing the photo of the specified person,
+// with the specified class, if they have a photo.  The person must have
+// fetched FID and FFlags.  The caller is responsible for ensuring that the
+// viewer is allowed to see the person.
+func PersonPhoto(parent *htmlb.Element, p *person.Person, class string) {
+	if p.Flags()&person.HasPhoto != 0 {
+		parent.E("img src=/people/%d/photo class=%s loading=lazy alt=''", p.ID(), class)
+	}
+}
@@ -13,17 +13,21 @@ import (
 // An Entry encapsulates all of the information that might be included in a log
 // entry.  The only strictly required fields are Timestamp and Request.
 type Entry struct {
-	Timestamp time.Time
-	User      string
-	Session   string
-	Request   string
-	Params    map[string][]string
-	Validate  []string
-	Status    int
-	Problems  problem.List
-	Stack     []byte
-	Changes   []string
-	Elapsed   time.Duration
+	Timestamp      time.Time
+	User           string
+	UserID         int // not logged; used for change history
+	Impersonator   string
+	ImpersonatorID int // not logged; used for change history
+	Session        string
+	Request        string
+	Params         map[string][]string
+	Validate       []string
+	Status         int
+	FailedLogin    string // client IP address of a failed login attempt
+	Problems       problem.List
+	Stack          []byte
+	Changes        []string
+	Elapsed        time.Duration
 }
 
 // New creates a new Entry and populates it with the current time and the
@@ -92,6 +96,10 @@ func (e *Entry) ToJSON(out *jwriter.Writer) {
 		out.RawString(`,"user":`)
 		out.String(e.User)
 	}
+	if e.Impersonator != "" {
+		out.RawString(`,"impersonator":`)
+		out.String(e.Impersonator)
+	}
 	if e.Session != "" {
 		out.RawString(`,"session":`)
 		out.String(string(e.Session))
@@ -104,7 +112,7 @@ func (e *Entry) ToJSON(out *jwriter.Writer) {
 		out.RawString(`,"params":{`)
 		first := true
 		for k, va := range e.Params {
-			if len(va) == 0 || k == "auth" || k == "password" || k == "oldpwd" || k == "newpwd" {
+			if len(va) == 0 || k == "auth" || k == "password" || k == "oldpwd" || k == "newpwd" || k == "secret" || k == "code" || k == "pending" {
 				continue
 			}
 			if first {
@@ -143,6 +151,10 @@ func (e *Entry) ToJSON(out *jwriter.Writer) {
 		out.RawString(`,"status":`)
 		out.Int(e.Status)
 	}
+	if e.FailedLogin != "" {
+		out.RawString(`,"failedLogin":`)
+		out.String(e.FailedLogin)
+	}
 	if !e.Problems.OK() {
 		out.RawString(`,"errors":[`)
 		for i, e := range e.Problems.Problems() {
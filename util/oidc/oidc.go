@@ -0,0 +1,382 @@
+// Package oidc implements the relying party side of an OpenID Connect login,
+// using the authorization code flow with PKCE, as defined in OpenID Connect
+// Core 1.0 and RFC 7636.  It supports only what we need:  provider discovery,
+// confidential or public clients, and ID tokens signed with RS256 or ES256.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors returned by Exchange.
+var (
+	ErrToken     = errors.New("oidc: token request failed")
+	ErrIDToken   = errors.New("oidc: invalid ID token")
+	ErrSignature = errors.New("oidc: invalid ID token signature")
+	ErrIssuer    = errors.New("oidc: ID token issuer mismatch")
+	ErrAudience  = errors.New("oidc: ID token audience mismatch")
+	ErrExpired   = errors.New("oidc: ID token expired")
+	ErrNonce     = errors.New("oidc: ID token nonce mismatch")
+)
+
+// clockSkew is the allowance for differences between our clock and the
+// provider's when checking ID token times.
+const clockSkew = time.Minute
+
+// Encoding is the encoding used for binary values in JWTs, PKCE challenges,
+// and JWKs.
+var Encoding = base64.RawURLEncoding
+
+// Config describes our registration as a client of an OpenID provider.
+type Config struct {
+	// Issuer is the issuer identifier URL of the provider.
+	Issuer string
+	// ClientID is the client ID assigned to us by the provider.
+	ClientID string
+	// ClientSecret is the client secret assigned to us by the provider.
+	// It is empty for a public client.
+	ClientSecret string
+	// RedirectURL is the URL of our callback handler, to which the
+	// provider redirects the browser after authentication.
+	RedirectURL string
+	// HTTPClient is the client used for requests to the provider.  If it
+	// is nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// Provider is an OpenID provider, as seen by a registered client.
+type Provider struct {
+	config   Config
+	authURL  string
+	tokenURL string
+	jwksURL  string
+
+	mu   sync.Mutex
+	keys map[string]crypto.PublicKey
+}
+
+// Claims are the claims of a verified ID token that we use.
+type Claims struct {
+	// Subject is the provider's unique, stable identifier for the user.
+	Subject string
+	// Email is the user's email address, if the provider disclosed it.
+	Email string
+	// EmailVerified is whether the provider has verified that the user
+	// controls Email.
+	EmailVerified bool
+	// Name is the user's full name, if the provider disclosed it.
+	Name string
+}
+
+// Discover fetches the configuration of the OpenID provider described in
+// config, from its well-known discovery document, and returns the Provider.
+func Discover(ctx context.Context, config Config) (p *Provider, err error) {
+	var doc struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	p = &Provider{config: config}
+	if err = p.getJSON(ctx, strings.TrimSuffix(config.Issuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, err
+	}
+	if doc.Issuer != config.Issuer {
+		return nil, fmt.Errorf("oidc: discovery document is for issuer %q, not %q", doc.Issuer, config.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is incomplete")
+	}
+	p.authURL, p.tokenURL, p.jwksURL = doc.AuthorizationEndpoint, doc.TokenEndpoint, doc.JWKSURI
+	return p, nil
+}
+
+// NewVerifier returns a new random PKCE code verifier.  (It is also suitable
+// for use as a state or nonce value.)
+func NewVerifier() string {
+	var buf [32]byte
+	rand.Read(buf[:])
+	return Encoding.EncodeToString(buf[:])
+}
+
+// AuthCodeURL returns the URL of the provider's authorization endpoint to
+// which the browser should be redirected to start a login.  state is echoed
+// back to the callback; nonce is echoed back in the ID token; and verifier is
+// the PKCE code verifier, which must be passed to Exchange.
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	challenge := sha256.Sum256([]byte(verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {Encoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if strings.Contains(p.authURL, "?") {
+		return p.authURL + "&" + q.Encode()
+	}
+	return p.authURL + "?" + q.Encode()
+}
+
+// Exchange redeems an authorization code received by the callback for an ID
+// token, verifies the ID token, and returns its claims.  verifier and nonce
+// must be the ones passed to AuthCodeURL.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (claims *Claims, err error) {
+	var tokens struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+	resp, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&tokens); err != nil || resp.StatusCode != http.StatusOK || tokens.IDToken == "" {
+		if tokens.Error != "" {
+			return nil, fmt.Errorf("%w: %s", ErrToken, tokens.Error)
+		}
+		return nil, ErrToken
+	}
+	return p.Verify(ctx, tokens.IDToken, nonce)
+}
+
+// Verify verifies an ID token issued to us by the provider, with the specified
+// nonce, and returns its claims.
+func (p *Provider) Verify(ctx context.Context, token, nonce string) (claims *Claims, err error) {
+	var (
+		header struct {
+			Alg string `json:"alg"`
+			Kid string `json:"kid"`
+		}
+		payload struct {
+			Issuer        string          `json:"iss"`
+			Subject       string          `json:"sub"`
+			Audience      json.RawMessage `json:"aud"`
+			AZP           string          `json:"azp"`
+			Expires       int64           `json:"exp"`
+			Nonce         string          `json:"nonce"`
+			Email         string          `json:"email"`
+			EmailVerified json.RawMessage `json:"email_verified"`
+			Name          string          `json:"name"`
+		}
+		aud []string
+	)
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrIDToken
+	}
+	if err = decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrIDToken
+	}
+	if err = decodeSegment(parts[1], &payload); err != nil {
+		return nil, ErrIDToken
+	}
+	sig, err := Encoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrIDToken
+	}
+	key, err := p.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if !verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrSignature
+	}
+	if payload.Issuer != p.config.Issuer {
+		return nil, ErrIssuer
+	}
+	if err = json.Unmarshal(payload.Audience, &aud); err != nil {
+		var one string
+		if err = json.Unmarshal(payload.Audience, &one); err != nil {
+			return nil, ErrIDToken
+		}
+		aud = []string{one}
+	}
+	if !contains(aud, p.config.ClientID) || (len(aud) > 1 && payload.AZP != p.config.ClientID) {
+		return nil, ErrAudience
+	}
+	if time.Now().Add(-clockSkew).After(time.Unix(payload.Expires, 0)) {
+		return nil, ErrExpired
+	}
+	if payload.Nonce != nonce {
+		return nil, ErrNonce
+	}
+	if payload.Subject == "" {
+		return nil, ErrIDToken
+	}
+	claims = &Claims{Subject: payload.Subject, Email: payload.Email, Name: payload.Name}
+	// Some providers send email_verified as a string.
+	switch string(payload.EmailVerified) {
+	case `true`, `"true"`:
+		claims.EmailVerified = true
+	}
+	return claims, nil
+}
+
+// key returns the provider's public key with the specified key ID.  The keys
+// are cached; they are fetched again when a token names a key we don't have,
+// since that's what happens when the provider rotates its keys.
+func (p *Provider) key(ctx context.Context, kid string) (key crypto.PublicKey, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key = p.findKey(kid); key != nil {
+		return key, nil
+	}
+	if err = p.fetchKeys(ctx); err != nil {
+		return nil, err
+	}
+	if key = p.findKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, ErrSignature
+}
+
+// findKey returns the cached key with the specified key ID.  If the ID is
+// empty and there is only one key, it returns that key.
+func (p *Provider) findKey(kid string) crypto.PublicKey {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
+
+// fetchKeys fetches the provider's JSON Web Key Set.  Keys of unsupported
+// types are ignored.
+func (p *Provider) fetchKeys(ctx context.Context) (err error) {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err = p.getJSON(ctx, p.jwksURL, &jwks); err != nil {
+		return err
+	}
+	p.keys = make(map[string]crypto.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, err1 := Encoding.DecodeString(k.N)
+			e, err2 := Encoding.DecodeString(k.E)
+			if err1 != nil || err2 != nil || len(e) > 4 {
+				continue
+			}
+			p.keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			x, err1 := Encoding.DecodeString(k.X)
+			y, err2 := Encoding.DecodeString(k.Y)
+			if k.Crv != "P-256" || err1 != nil || err2 != nil {
+				continue
+			}
+			pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+			if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+				continue
+			}
+			p.keys[k.Kid] = pub
+		}
+	}
+	return nil
+}
+
+// verifySignature verifies a JWS signature with the specified algorithm and
+// key.
+func verifySignature(alg string, key crypto.PublicKey, signed, sig []byte) bool {
+	hash := sha256.Sum256(signed)
+	switch alg {
+	case "RS256":
+		if pub, ok := key.(*rsa.PublicKey); ok {
+			return rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig) == nil
+		}
+	case "ES256":
+		if pub, ok := key.(*ecdsa.PublicKey); ok && len(sig) == 64 {
+			r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+			return ecdsa.Verify(pub, hash[:], r, s)
+		}
+	}
+	return false
+}
+
+// getJSON fetches a JSON document from the provider.
+func (p *Provider) getJSON(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: GET %s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// decodeSegment decodes a base64url-encoded JSON segment of a JWT.
+func decodeSegment(seg string, v any) error {
+	buf, err := Encoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, v)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"sunnyvaleserv.org/portal/util/oidc"
+	"sunnyvaleserv.org/portal/util/oidc/oidctest"
+)
+
+const testRedirectURL = "https://example.com/sso/callback"
+
+// startProvider starts a mock provider and returns it, along with a Provider
+// discovered from it.
+func startProvider(t *testing.T, secret string) (mock *oidctest.Provider, p *oidc.Provider) {
+	var err error
+
+	mock = oidctest.New("portal", secret)
+	mock.User = oidctest.User{Subject: "u123", Email: "staff@example.com", EmailVerified: true, Name: "Pat Staff"}
+	srv := httptest.NewServer(mock)
+	t.Cleanup(srv.Close)
+	mock.Issuer = srv.URL + "/idp"
+	p, err = oidc.Discover(context.Background(), oidc.Config{
+		Issuer: mock.Issuer, ClientID: "portal", ClientSecret: secret, RedirectURL: testRedirectURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mock, p
+}
+
+// authorize follows the authorization URL, as a browser would, and returns
+// the query parameters of the resulting redirect to the callback.
+func authorize(t *testing.T, authURL string) url.Values {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	loc := resp.Header.Get("Location")
+	if !strings.HasPrefix(loc, testRedirectURL+"?") {
+		t.Fatalf("authorization redirected to %q", loc)
+	}
+	u, _ := url.Parse(loc)
+	return u.Query()
+}
+
+func TestLogin(t *testing.T) {
+	for _, secret := range []string{"s3cret", ""} {
+		_, p := startProvider(t, secret)
+		state, nonce, verifier := oidc.NewVerifier(), oidc.NewVerifier(), oidc.NewVerifier()
+		q := authorize(t, p.AuthCodeURL(state, nonce, verifier))
+		if q.Get("state") != state {
+			t.Fatalf("state = %q, want %q", q.Get("state"), state)
+		}
+		claims, err := p.Exchange(context.Background(), q.Get("code"), verifier, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claims.Subject != "u123" || claims.Email != "staff@example.com" || !claims.EmailVerified || claims.Name != "Pat Staff" {
+			t.Errorf("claims = %+v", claims)
+		}
+	}
+}
+
+func TestExchangeRejects(t *testing.T) {
+	_, p := startProvider(t, "s3cret")
+	nonce, verifier := oidc.NewVerifier(), oidc.NewVerifier()
+
+	// Wrong PKCE verifier.
+	q := authorize(t, p.AuthCodeURL("st", nonce, verifier))
+	if _, err := p.Exchange(context.Background(), q.Get("code"), oidc.NewVerifier(), nonce); !errors.Is(err, oidc.ErrToken) {
+		t.Errorf("wrong verifier: err = %v", err)
+	}
+	// Wrong nonce.
+	q = authorize(t, p.AuthCodeURL("st", nonce, verifier))
+	if _, err := p.Exchange(context.Background(), q.Get("code"), verifier, oidc.NewVerifier()); !errors.Is(err, oidc.ErrNonce) {
+		t.Errorf("wrong nonce: err = %v", err)
+	}
+	// Reused code.
+	q = authorize(t, p.AuthCodeURL("st", nonce, verifier))
+	if _, err := p.Exchange(context.Background(), q.Get("code"), verifier, nonce); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Exchange(context.Background(), q.Get("code"), verifier, nonce); !errors.Is(err, oidc.ErrToken) {
+		t.Errorf("reused code: err = %v", err)
+	}
+	// Wrong client secret.
+	mock, _ := startProvider(t, "s3cret")
+	bad, err := oidc.Discover(context.Background(), oidc.Config{
+		Issuer: mock.Issuer, ClientID: "portal", ClientSecret: "wrong", RedirectURL: testRedirectURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q = authorize(t, bad.AuthCodeURL("st", nonce, verifier))
+	if _, err := bad.Exchange(context.Background(), q.Get("code"), verifier, nonce); !errors.Is(err, oidc.ErrToken) {
+		t.Errorf("wrong secret: err = %v", err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	mock, p := startProvider(t, "")
+	claims := func(extra map[string]any) map[string]any {
+		c := map[string]any{"sub": "u123", "nonce": "n"}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+	if _, err := p.Verify(context.Background(), mock.IDToken(claims(nil)), "n"); err != nil {
+		t.Fatalf("valid token: %v", err)
+	}
+	if _, err := p.Verify(context.Background(), mock.IDToken(claims(map[string]any{"aud": []string{"portal", "other"}, "azp": "portal"})), "n"); err != nil {
+		t.Errorf("multiple audiences with azp: %v", err)
+	}
+	for _, tc := range []struct {
+		name   string
+		claims map[string]any
+		want   error
+	}{
+		{"issuer", claims(map[string]any{"iss": "https://evil.example.com"}), oidc.ErrIssuer},
+		{"audience", claims(map[string]any{"aud": "other"}), oidc.ErrAudience},
+		{"azp", claims(map[string]any{"aud": []string{"portal", "other"}}), oidc.ErrAudience},
+		{"expired", claims(map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}), oidc.ErrExpired},
+		{"nonce", claims(map[string]any{"nonce": "x"}), oidc.ErrNonce},
+		{"subject", claims(map[string]any{"sub": ""}), oidc.ErrIDToken},
+	} {
+		if _, err := p.Verify(context.Background(), mock.IDToken(tc.claims), "n"); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+	token := mock.IDToken(claims(nil))
+	tampered := token[:len(token)-4] + "AAAA"
+	if _, err := p.Verify(context.Background(), tampered, "n"); !errors.Is(err, oidc.ErrSignature) {
+		t.Errorf("tampered: err = %v", err)
+	}
+	parts := strings.Split(token, ".")
+	parts[0] = oidc.Encoding.EncodeToString([]byte(`{"alg":"none","kid":"mock"}`))
+	if _, err := p.Verify(context.Background(), parts[0]+"."+parts[1]+".", "n"); !errors.Is(err, oidc.ErrSignature) {
+		t.Errorf("alg none: err = %v", err)
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	mock := oidctest.New("portal", "")
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+	mock.Issuer = srv.URL
+	if _, err := oidc.Discover(context.Background(), oidc.Config{Issuer: srv.URL + "/", ClientID: "portal"}); err == nil {
+		t.Error("Discover accepted a mismatched issuer")
+	}
+}
@@ -0,0 +1,210 @@
+// Package oidctest provides a mock OpenID provider, for testing the oidc
+// package and for trying out single sign-on locally (see cmd/mock-oidc).  It
+// authenticates every authorization request as a single configured user,
+// without asking anything, but otherwise follows the protocol:  it checks the
+// client credentials, redirect URL, and PKCE code verifier, and issues signed
+// ID tokens.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"sunnyvaleserv.org/portal/util/oidc"
+)
+
+// keyID is the key ID of the mock provider's signing key.
+const keyID = "mock"
+
+// User describes the user as whom the mock provider authenticates.
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider is a mock OpenID provider.  It is an http.Handler; its Issuer must
+// be set to the URL at which it is served.
+type Provider struct {
+	// Issuer is the issuer identifier URL of the provider.
+	Issuer string
+	// ClientID and ClientSecret are the credentials of the one client
+	// registered with the provider.  If ClientSecret is empty, the client
+	// is a public client.
+	ClientID     string
+	ClientSecret string
+	// User is the user as whom the provider authenticates.
+	User User
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]*grant
+}
+
+// grant is an outstanding authorization code.
+type grant struct {
+	redirectURL string
+	challenge   string
+	nonce       string
+	user        User
+}
+
+// New returns a new mock provider with the specified client credentials.
+func New(clientID, clientSecret string) (p *Provider) {
+	var err error
+
+	p = &Provider{ClientID: clientID, ClientSecret: clientSecret, codes: make(map[string]*grant)}
+	if p.key, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// ServeHTTP serves the provider's discovery document, JWKS, and
+// authorization and token endpoints.
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, issuerPath(p.Issuer)) {
+	case "/.well-known/openid-configuration":
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                                p.Issuer,
+			"authorization_endpoint":                p.Issuer + "/authorize",
+			"token_endpoint":                        p.Issuer + "/token",
+			"jwks_uri":                              p.Issuer + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+			"code_challenge_methods_supported":      []string{"S256"},
+		})
+	case "/jwks":
+		writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   oidc.Encoding.EncodeToString(p.key.N.Bytes()),
+			"e":   oidc.Encoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}}})
+	case "/authorize":
+		p.authorize(w, r)
+	case "/token":
+		p.token(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// authorize handles the authorization endpoint.  It immediately redirects
+// back to the client with an authorization code for the configured user.
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != p.ClientID || q.Get("redirect_uri") == "" {
+		http.Error(w, "invalid client_id or redirect_uri", http.StatusBadRequest)
+		return
+	}
+	ru, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	rq := ru.Query()
+	rq.Set("state", q.Get("state"))
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" ||
+		!strings.Contains(" "+q.Get("scope")+" ", " openid ") {
+		rq.Set("error", "invalid_request")
+	} else {
+		code := oidc.NewVerifier()
+		p.mu.Lock()
+		p.codes[code] = &grant{redirectURL: q.Get("redirect_uri"), challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), user: p.User}
+		p.mu.Unlock()
+		rq.Set("code", code)
+	}
+	ru.RawQuery = rq.Encode()
+	http.Redirect(w, r, ru.String(), http.StatusFound)
+}
+
+// token handles the token endpoint.
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if ok {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		id, secret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if id != p.ClientID || secret != p.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	p.mu.Lock()
+	g := p.codes[r.PostFormValue("code")]
+	delete(p.codes, r.PostFormValue("code"))
+	p.mu.Unlock()
+	challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if g == nil || r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != g.redirectURL ||
+		oidc.Encoding.EncodeToString(challenge[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": oidc.NewVerifier(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token": p.IDToken(map[string]any{
+			"sub":            g.user.Subject,
+			"email":          g.user.Email,
+			"email_verified": g.user.EmailVerified,
+			"name":           g.user.Name,
+			"nonce":          g.nonce,
+		}),
+	})
+}
+
+// IDToken returns an ID token signed by the provider, with the specified
+// claims.  The iss, aud, iat, and exp claims are filled in if not specified.
+func (p *Provider) IDToken(claims map[string]any) string {
+	now := time.Now()
+	for k, v := range map[string]any{"iss": p.Issuer, "aud": p.ClientID, "iat": now.Unix(), "exp": now.Add(5 * time.Minute).Unix()} {
+		if _, ok := claims[k]; !ok {
+			claims[k] = v
+		}
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyID})
+	payload, _ := json.Marshal(claims)
+	signed := oidc.Encoding.EncodeToString(header) + "." + oidc.Encoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, hash[:])
+	if err != nil {
+		panic(err)
+	}
+	return signed + "." + oidc.Encoding.EncodeToString(sig)
+}
+
+// issuerPath returns the path part of the issuer URL, under which the
+// provider's endpoints are served.
+func issuerPath(issuer string) string {
+	if u, err := url.Parse(issuer); err == nil {
+		return strings.TrimSuffix(u.Path, "/")
+	}
+	return ""
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
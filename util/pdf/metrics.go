@@ -0,0 +1,83 @@
+package pdf
+
+// widths gives the widths of the printable ASCII characters (32 through 126)
+// in each font, in thousandths of the font size, from the Adobe font metrics.
+var widths = [][]int{
+	Helvetica: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space through /
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 through ?
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ through O
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P through _
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` through o
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p through ~
+	},
+	HelveticaBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278, // space through /
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611, // 0 through ?
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778, // @ through O
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556, // P through _
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611, // ` through o
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584, // p through ~
+	},
+}
+
+// winAnsi maps the non-ASCII characters that are commonly needed to their
+// codes in WinAnsiEncoding.  The Latin-1 letters (U+00A0 through U+00FF) have
+// the same codes as in Unicode, and are handled separately.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// specialWidths gives the widths of the characters in winAnsi, which are the
+// same in both fonts except as noted.
+var specialWidths = map[byte]int{
+	0x80: 556, 0x91: 222, 0x92: 222, 0x93: 333, 0x94: 333, 0x95: 350, 0x96: 556, 0x97: 1000,
+}
+
+// latin1Base gives, for each of the Latin-1 characters U+00C0 through U+00FF,
+// the ASCII character whose width it shares.  (Most are accented letters.)
+const latin1Base = "AAAAAAACEEEEIIIIDNOOOOO*OUUUUYPsaaaaaaaceeeeiiiidnooooo+ouuuuypy"
+
+// encode converts a string to WinAnsiEncoding.
+func encode(s string) (enc []byte) {
+	for _, r := range s {
+		switch {
+		case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+			enc = append(enc, byte(r))
+		case winAnsi[r] != 0:
+			enc = append(enc, winAnsi[r])
+		default:
+			enc = append(enc, '?')
+		}
+	}
+	return enc
+}
+
+// TextWidth returns the width of the string s when drawn in the specified font
+// and size.
+func TextWidth(font Font, size float64, s string) float64 {
+	var total int
+
+	for _, b := range encode(s) {
+		switch {
+		case b < 128:
+			total += widths[font][b-32]
+		case specialWidths[b] != 0:
+			total += specialWidths[b]
+			if font == HelveticaBold && (b == 0x91 || b == 0x92) {
+				total += 56
+			} else if font == HelveticaBold && (b == 0x93 || b == 0x94) {
+				total += 167
+			}
+		default:
+			// For accented letters, use the width of the base letter.
+			// For other Latin-1 characters, use an average width.
+			if b >= 0xC0 {
+				total += widths[font][latin1Base[b-0xC0]-32]
+			} else {
+				total += 556
+			}
+		}
+	}
+	return float64(total) * size / 1000
+}
@@ -0,0 +1,213 @@
+// Package pdf generates simple PDF documents: pages containing text in the
+// standard Helvetica fonts, lines, rectangles, and images.  It does just
+// enough for the printable documents generated by the portal, without
+// requiring a third-party library.  Coordinates are in points (1/72 inch),
+// with the origin at the bottom left corner of the page.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Font identifies one of the standard fonts.
+type Font int
+
+// Values for Font:
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+var fontNames = []string{"Helvetica", "Helvetica-Bold"}
+
+// Page sizes, in points:
+const (
+	LetterWidth  = 612
+	LetterHeight = 792
+)
+
+// Document is a PDF document under construction.
+type Document struct {
+	objects [][]byte
+	pages   []*Page
+}
+
+// Page is a page of a PDF document under construction.
+type Page struct {
+	width   float64
+	height  float64
+	content bytes.Buffer
+	images  map[int]string
+}
+
+// Image is an image that has been added to a Document, and can be drawn on
+// any of its pages.
+type Image struct {
+	obj    int
+	Width  int
+	Height int
+}
+
+// New returns a new, empty Document.
+func New() (d *Document) {
+	d = new(Document)
+	d.objects = make([][]byte, 2+len(fontNames)) // catalog, page tree, fonts
+	d.objects[0] = []byte("<< /Type /Catalog /Pages 2 0 R >>")
+	for i, name := range fontNames {
+		d.objects[2+i] = fmt.Appendf(nil, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name)
+	}
+	return d
+}
+
+// addObject adds an object to the document and returns its object number.
+func (d *Document) addObject(obj []byte) int {
+	d.objects = append(d.objects, obj)
+	return len(d.objects)
+}
+
+// AddPage adds a page of the specified size to the document.
+func (d *Document) AddPage(width, height float64) (p *Page) {
+	p = &Page{width: width, height: height, images: make(map[int]string)}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// AddImage adds an image to the document.  Any transparency in the image is
+// composited onto a white background.
+func (d *Document) AddImage(img image.Image) *Image {
+	var (
+		bounds = img.Bounds()
+		raw    = make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+		data   bytes.Buffer
+	)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			// Composite onto white:  c + (1 - a) * white.
+			white := 0xFFFF - a
+			raw = append(raw, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+	zw := zlib.NewWriter(&data)
+	zw.Write(raw)
+	zw.Close()
+	obj := fmt.Appendf(nil, "<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n",
+		bounds.Dx(), bounds.Dy(), data.Len())
+	obj = append(obj, data.Bytes()...)
+	obj = append(obj, "\nendstream"...)
+	return &Image{obj: d.addObject(obj), Width: bounds.Dx(), Height: bounds.Dy()}
+}
+
+// Width returns the width of the page.
+func (p *Page) Width() float64 { return p.width }
+
+// Height returns the height of the page.
+func (p *Page) Height() float64 { return p.height }
+
+// Text draws the string s on the page, in the specified font and size, with
+// its baseline starting at (x, y).  Characters that cannot be represented in
+// the standard fonts are drawn as question marks.
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (", font+1, num(size), num(x), num(y))
+	for _, b := range encode(s) {
+		switch b {
+		case '(', ')', '\\':
+			p.content.WriteByte('\\')
+			p.content.WriteByte(b)
+		default:
+			p.content.WriteByte(b)
+		}
+	}
+	p.content.WriteString(") Tj ET\n")
+}
+
+// CenterText draws the string s on the page, centered horizontally at x.
+func (p *Page) CenterText(x, y float64, font Font, size float64, s string) {
+	p.Text(x-TextWidth(font, size, s)/2, y, font, size, s)
+}
+
+// RightText draws the string s on the page, ending at x.
+func (p *Page) RightText(x, y float64, font Font, size float64, s string) {
+	p.Text(x-TextWidth(font, size, s), y, font, size, s)
+}
+
+// Line draws a line of the specified width and gray level (0 = black, 1 =
+// white) from (x1, y1) to (x2, y2).
+func (p *Page) Line(x1, y1, x2, y2, width, gray float64) {
+	fmt.Fprintf(&p.content, "%s G %s w %s %s m %s %s l S\n", num(gray), num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Rect draws the outline of a rectangle with its lower left corner at (x, y).
+func (p *Page) Rect(x, y, w, h, width, gray float64) {
+	fmt.Fprintf(&p.content, "%s G %s w %s %s %s %s re S\n", num(gray), num(width), num(x), num(y), num(w), num(h))
+}
+
+// Image draws the image on the page, scaled to fit in the w×h box whose
+// lower left corner is at (x, y), and centered in that box.
+func (p *Page) Image(img *Image, x, y, w, h float64) {
+	name, ok := p.images[img.obj]
+	if !ok {
+		name = fmt.Sprintf("Im%d", len(p.images)+1)
+		p.images[img.obj] = name
+	}
+	scale := min(w/float64(img.Width), h/float64(img.Height))
+	iw, ih := float64(img.Width)*scale, float64(img.Height)*scale
+	x, y = x+(w-iw)/2, y+(h-ih)/2
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /%s Do Q\n", num(iw), num(ih), num(x), num(y), name)
+}
+
+// WriteTo writes the document to w.
+func (d *Document) WriteTo(w io.Writer) (n int64, err error) {
+	var (
+		buf     bytes.Buffer
+		objects = slices.Clone(d.objects)
+		kids    []string
+		fonts   strings.Builder
+	)
+	for i := range fontNames {
+		fmt.Fprintf(&fonts, " /F%d %d 0 R", i+1, i+3)
+	}
+	for _, p := range d.pages {
+		var xobjects strings.Builder
+
+		for obj, name := range p.images {
+			fmt.Fprintf(&xobjects, " /%s %d 0 R", name, obj)
+		}
+		content := fmt.Appendf(nil, "<< /Length %d >>\nstream\n", p.content.Len())
+		content = append(content, p.content.Bytes()...)
+		content = append(content, "\nendstream"...)
+		objects = append(objects, content)
+		objects = append(objects, fmt.Appendf(nil, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Contents %d 0 R /Resources << /Font <<%s >> /XObject <<%s >> >> >>",
+			num(p.width), num(p.height), len(objects), fonts.String(), xobjects.String()))
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)))
+	}
+	objects[1] = fmt.Appendf(nil, "<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+	offsets := make([]int, len(objects))
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		buf.Write(obj)
+		buf.WriteString("\nendobj\n")
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.WriteTo(w)
+}
+
+// num formats a number for use in a PDF content stream.
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,45 @@
+package pdf_test
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"sunnyvaleserv.org/portal/util/pdf"
+)
+
+func TestTextWidth(t *testing.T) {
+	if w := pdf.TextWidth(pdf.Helvetica, 10, "Hi!"); w != 12.22 {
+		t.Errorf("TextWidth(Helvetica) = %v, want 12.22", w)
+	}
+	if w := pdf.TextWidth(pdf.HelveticaBold, 10, "José"); w != 22.79 {
+		t.Errorf("TextWidth(HelveticaBold) = %v, want 22.79", w)
+	}
+}
+
+func TestXref(t *testing.T) {
+	var buf bytes.Buffer
+
+	doc := pdf.New()
+	page := doc.AddPage(pdf.LetterWidth, pdf.LetterHeight)
+	page.Text(72, 720, pdf.Helvetica, 12, "Hello (world) – ¡olé!")
+	page.Rect(36, 36, 540, 720, 1, 0)
+	doc.WriteTo(&buf)
+	out := buf.Bytes()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	if len(entries) != 6 {
+		t.Fatalf("got %d xref entries, want 6", len(entries))
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := strconv.Itoa(i+1) + " 0 obj\n"; !bytes.HasPrefix(out[off:], []byte(want)) {
+			t.Errorf("xref entry %d points to %q", i+1, out[off:off+10])
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package photo prepares uploaded photos of people for storage.  It crops them
+// to a square around the center, corrects their orientation, and scales them
+// down to a standard size, yielding a JPEG image.
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	_ "image/gif" // for decoding uploaded GIF photos
+	"image/jpeg"
+	_ "image/png" // for decoding uploaded PNG photos
+)
+
+// Size is the width and height, in pixels, of a prepared photo.
+const Size = 320
+
+// MaxUpload is the largest uploaded photo file we accept, in bytes.
+const MaxUpload = 20 << 20
+
+// ErrNotImage is returned when the uploaded file isn't a JPEG, PNG, or GIF
+// image.
+var ErrNotImage = errors.New("not a JPEG, PNG, or GIF image")
+
+// Prepare decodes the supplied image file, crops it to a centered square,
+// rotates it according to its EXIF orientation (if any), and scales it to
+// Size×Size.  It returns the resulting image encoded as a JPEG.
+func Prepare(data []byte) (out []byte, err error) {
+	var (
+		src image.Image
+		buf bytes.Buffer
+	)
+	if src, _, err = image.Decode(bytes.NewReader(data)); err != nil {
+		return nil, ErrNotImage
+	}
+	dst := scale(crop(src.Bounds()), src)
+	dst = orient(dst, orientation(data))
+	if err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// crop returns the largest square centered within the specified bounds.
+func crop(b image.Rectangle) image.Rectangle {
+	if b.Dx() > b.Dy() {
+		off := (b.Dx() - b.Dy()) / 2
+		return image.Rect(b.Min.X+off, b.Min.Y, b.Min.X+off+b.Dy(), b.Max.Y)
+	}
+	off := (b.Dy() - b.Dx()) / 2
+	return image.Rect(b.Min.X, b.Min.Y+off, b.Max.X, b.Min.Y+off+b.Dx())
+}
+
+// scale scales the specified square region of the source image to Size×Size,
+// averaging the source pixels that fall within each destination pixel.  (If
+// the source is smaller than Size, pixels are simply replicated.)
+func scale(sr image.Rectangle, src image.Image) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, Size, Size))
+	n := sr.Dx()
+	for dy := 0; dy < Size; dy++ {
+		y0, y1 := sr.Min.Y+dy*n/Size, sr.Min.Y+(dy+1)*n/Size
+		y1 = max(y1, y0+1)
+		for dx := 0; dx < Size; dx++ {
+			x0, x1 := sr.Min.X+dx*n/Size, sr.Min.X+(dx+1)*n/Size
+			x1 = max(x1, x0+1)
+			var r, g, b, count uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					cr, cg, cb, _ := src.At(x, y).RGBA()
+					r, g, b = r+uint64(cr), g+uint64(cg), b+uint64(cb)
+					count++
+				}
+			}
+			i := dst.PixOffset(dx, dy)
+			dst.Pix[i+0] = uint8(r / count >> 8)
+			dst.Pix[i+1] = uint8(g / count >> 8)
+			dst.Pix[i+2] = uint8(b / count >> 8)
+			dst.Pix[i+3] = 0xFF
+		}
+	}
+	return dst
+}
+
+// orient transforms the image to undo the specified EXIF orientation.  Since
+// the image is square, no change of dimensions is needed.
+func orient(src *image.RGBA, o int) *image.RGBA {
+	if o < 2 || o > 8 {
+		return src
+	}
+	dst := image.NewRGBA(src.Bounds())
+	last := Size - 1
+	for y := 0; y < Size; y++ {
+		for x := 0; x < Size; x++ {
+			var sx, sy int
+			switch o {
+			case 2: // mirrored horizontally
+				sx, sy = last-x, y
+			case 3: // rotated 180°
+				sx, sy = last-x, last-y
+			case 4: // mirrored vertically
+				sx, sy = x, last-y
+			case 5: // mirrored and rotated 90° CCW
+				sx, sy = y, x
+			case 6: // rotated 90° CW
+				sx, sy = y, last-x
+			case 7: // mirrored and rotated 90° CW
+				sx, sy = last-y, last-x
+			case 8: // rotated 90° CCW
+				sx, sy = last-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// orientation returns the EXIF orientation of a JPEG image, or zero if it
+// can't be determined.  Phone cameras commonly store photos unrotated with an
+// orientation tag rather than rotating the pixels.
+func orientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0 // not a JPEG
+	}
+	data = data[2:]
+	for len(data) >= 4 && data[0] == 0xFF {
+		marker, length := data[1], int(binary.BigEndian.Uint16(data[2:4]))
+		if length < 2 || len(data) < 2+length {
+			return 0
+		}
+		segment := data[4 : 2+length]
+		if marker == 0xE1 && len(segment) >= 14 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		if marker == 0xDA {
+			return 0 // start of image data; no EXIF found
+		}
+		data = data[2+length:]
+	}
+	return 0
+}
+
+// exifOrientation finds the orientation tag in the first IFD of a TIFF
+// structure embedded in an EXIF segment.
+func exifOrientation(tiff []byte) int {
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 { // Orientation
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package photo_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"sunnyvaleserv.org/portal/util/photo"
+)
+
+func TestPrepare(t *testing.T) {
+	var buf bytes.Buffer
+
+	// A wide image whose left and right thirds are red and whose center
+	// is blue should crop to a blue square.
+	src := image.NewRGBA(image.Rect(0, 0, 900, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 900; x++ {
+			if x >= 300 && x < 600 {
+				src.Set(x, y, color.RGBA{0, 0, 255, 255})
+			} else {
+				src.Set(x, y, color.RGBA{255, 0, 0, 255})
+			}
+		}
+	}
+	png.Encode(&buf, src)
+	out, err := photo.Prepare(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != photo.Size || b.Dy() != photo.Size {
+		t.Errorf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), photo.Size, photo.Size)
+	}
+	for _, pt := range []image.Point{{0, 0}, {photo.Size - 1, photo.Size - 1}} {
+		if r, _, b, _ := img.At(pt.X, pt.Y).RGBA(); r > 0x4000 || b < 0xC000 {
+			t.Errorf("pixel at %v is not blue", pt)
+		}
+	}
+}
+
+func TestPrepareNotImage(t *testing.T) {
+	if _, err := photo.Prepare([]byte("not an image")); err != photo.ErrNotImage {
+		t.Errorf("err = %v, want ErrNotImage", err)
+	}
+}
@@ -0,0 +1,451 @@
+// Package qrcode generates QR codes, rendered as SVG images.  It supports only
+// what the portal needs:  byte-mode data of up to 213 bytes, at error
+// correction level M.
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTooLong is returned when the data to be encoded does not fit in the
+// largest supported QR code.
+var ErrTooLong = errors.New("data too long for QR code")
+
+// versionInfo gives the block structure of each supported QR code version at
+// error correction level M.
+type versionInfo struct {
+	ecPerBlock  int   // EC codewords per block
+	blocks      []int // data codewords in each block
+	alignCoords []int // alignment pattern center coordinates
+}
+
+var versions = []versionInfo{
+	1:  {10, []int{16}, nil},
+	2:  {16, []int{28}, []int{6, 18}},
+	3:  {26, []int{44}, []int{6, 22}},
+	4:  {18, []int{32, 32}, []int{6, 26}},
+	5:  {24, []int{43, 43}, []int{6, 30}},
+	6:  {16, []int{27, 27, 27, 27}, []int{6, 34}},
+	7:  {18, []int{31, 31, 31, 31}, []int{6, 22, 38}},
+	8:  {22, []int{38, 38, 39, 39}, []int{6, 24, 42}},
+	9:  {22, []int{36, 36, 36, 37, 37}, []int{6, 26, 46}},
+	10: {26, []int{43, 43, 43, 43, 44}, []int{6, 28, 50}},
+}
+
+// qr is a QR code under construction.
+type qr struct {
+	version  int
+	size     int
+	modules  [][]bool // [y][x], true = dark
+	function [][]bool // [y][x], true = function pattern (not data)
+}
+
+// Encode returns the QR code for the specified data, as a square grid of
+// modules indexed [y][x], where true means dark.  It does not include the
+// quiet zone.
+func Encode(data []byte) (modules [][]bool, err error) {
+	var (
+		q         qr
+		codewords []byte
+	)
+	for q.version = 1; q.version < len(versions); q.version++ {
+		if codewords = encodeData(data, q.version); codewords != nil {
+			break
+		}
+	}
+	if codewords == nil {
+		return nil, ErrTooLong
+	}
+	q.size = q.version*4 + 17
+	q.modules = make([][]bool, q.size)
+	q.function = make([][]bool, q.size)
+	for y := range q.modules {
+		q.modules[y] = make([]bool, q.size)
+		q.function[y] = make([]bool, q.size)
+	}
+	q.drawFunctionPatterns()
+	q.drawCodewords(addErrorCorrection(codewords, versions[q.version]))
+	// Choose the mask with the lowest penalty score.
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask) // undo
+	}
+	q.applyMask(bestMask)
+	q.drawFormatBits(bestMask)
+	return q.modules, nil
+}
+
+// SVG returns an SVG image of the QR code for the specified text, including
+// its quiet zone.  The image has no intrinsic size; it should be sized with
+// CSS.
+func SVG(text string) (svg string, err error) {
+	var (
+		modules [][]bool
+		sb      strings.Builder
+	)
+	if modules, err = Encode([]byte(text)); err != nil {
+		return "", err
+	}
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges"><rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="`, len(modules)+8, len(modules)+8)
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&sb, "M%d %dh1v1h-1z", x+4, y+4)
+			}
+		}
+	}
+	sb.WriteString(`"/></svg>`)
+	return sb.String(), nil
+}
+
+// encodeData returns the data codewords encoding the data in the specified
+// version, or nil if it doesn't fit.
+func encodeData(data []byte, version int) (codewords []byte) {
+	var (
+		capacity int
+		bits     bitBuffer
+	)
+	for _, b := range versions[version].blocks {
+		capacity += b
+	}
+	bits.append(0b0100, 4) // byte mode
+	if version < 10 {
+		bits.append(len(data), 8)
+	} else {
+		bits.append(len(data), 16)
+	}
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	if len(bits) > capacity*8 {
+		return nil
+	}
+	// Add the terminator and pad to a byte boundary.
+	bits.append(0, min(4, capacity*8-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	codewords = make([]byte, 0, capacity)
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for j := 0; j < 8; j++ {
+			if bits[i+j] {
+				b |= 0x80 >> j
+			}
+		}
+		codewords = append(codewords, b)
+	}
+	for pad := byte(0xEC); len(codewords) < capacity; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+	return codewords
+}
+
+// bitBuffer is a sequence of bits.
+type bitBuffer []bool
+
+// append appends the low n bits of val, most significant first.
+func (bb *bitBuffer) append(val, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*bb = append(*bb, (val>>i)&1 != 0)
+	}
+}
+
+// addErrorCorrection splits the data codewords into blocks, computes the error
+// correction codewords for each block, and interleaves them all into the final
+// codeword sequence.
+func addErrorCorrection(data []byte, vi versionInfo) (result []byte) {
+	var (
+		divisor  = rsDivisor(vi.ecPerBlock)
+		dblocks  [][]byte
+		ecblocks [][]byte
+		maxLen   int
+	)
+	for _, n := range vi.blocks {
+		dblocks = append(dblocks, data[:n])
+		ecblocks = append(ecblocks, rsRemainder(data[:n], divisor))
+		data = data[n:]
+		maxLen = max(maxLen, n)
+	}
+	for i := 0; i < maxLen; i++ {
+		for _, block := range dblocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < vi.ecPerBlock; i++ {
+		for _, block := range ecblocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of the specified
+// degree, omitting its leading coefficient.
+func rsDivisor(degree int) []byte {
+	var (
+		result = make([]byte, degree)
+		root   = byte(1)
+	)
+	result[degree-1] = 1
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 2)
+	}
+	return result
+}
+
+// rsRemainder returns the Reed-Solomon error correction codewords for the
+// data, using the specified divisor.
+func rsRemainder(data, divisor []byte) []byte {
+	var result = make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies two elements of GF(2^8) modulo x^8+x^4+x^3+x^2+1.
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// set sets a function module.
+func (q *qr) set(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.function[y][x] = true
+}
+
+// drawFunctionPatterns draws the finder, timing, and alignment patterns, the
+// version information, and a placeholder for the format information.
+func (q *qr) drawFunctionPatterns() {
+	for i := 0; i < q.size; i++ {
+		q.set(6, i, i%2 == 0)
+		q.set(i, 6, i%2 == 0)
+	}
+	q.drawFinder(3, 3)
+	q.drawFinder(q.size-4, 3)
+	q.drawFinder(3, q.size-4)
+	coords := versions[q.version].alignCoords
+	for i, x := range coords {
+		for j, y := range coords {
+			if (i == 0 && j == 0) || (i == 0 && j == len(coords)-1) || (i == len(coords)-1 && j == 0) {
+				continue // overlaps a finder pattern
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+	q.drawFormatBits(0)
+	if q.version >= 7 {
+		rem := q.version
+		for i := 0; i < 12; i++ {
+			rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+		}
+		bits := q.version<<12 | rem
+		for i := 0; i < 18; i++ {
+			dark := (bits>>i)&1 != 0
+			a, b := q.size-11+i%3, i/3
+			q.set(a, b, dark)
+			q.set(b, a, dark)
+		}
+	}
+}
+
+// drawFinder draws a finder pattern, with its separator, centered at x, y.
+func (q *qr) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			if xx, yy := x+dx, y+dy; xx >= 0 && xx < q.size && yy >= 0 && yy < q.size {
+				dist := max(abs(dx), abs(dy))
+				q.set(xx, yy, dist != 2 && dist != 4)
+			}
+		}
+	}
+}
+
+// drawFormatBits draws both copies of the format information for error
+// correction level M and the specified mask.
+func (q *qr) drawFormatBits(mask int) {
+	data := 0b00<<3 | mask // level M
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 != 0 }
+	for i := 0; i <= 5; i++ {
+		q.set(8, i, bit(i))
+	}
+	q.set(8, 7, bit(6))
+	q.set(8, 8, bit(7))
+	q.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.set(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		q.set(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.set(8, q.size-15+i, bit(i))
+	}
+	q.set(8, q.size-8, true) // always dark
+}
+
+// drawCodewords places the codewords in the data area, in the zigzag order
+// defined by the standard.
+func (q *qr) drawCodewords(codewords []byte) {
+	var i int
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // skip the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if upward {
+					y = q.size - 1 - vert
+				}
+				if !q.function[y][x] && i < len(codewords)*8 {
+					q.modules[y][x] = (codewords[i>>3]>>(7-i&7))&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask XORs the specified mask pattern onto the data modules.  Applying
+// the same mask twice undoes it.
+func (q *qr) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !q.function[y][x] {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// finderLike are the module sequences that resemble a finder pattern, which
+// are penalized.
+var finderLike = [][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty computes the penalty score of the current module pattern, which
+// is used to choose the mask that makes the code easiest to read.
+func (q *qr) penalty() (penalty int) {
+	var dark int
+	at := func(x, y int, transpose bool) bool {
+		if transpose {
+			return q.modules[x][y]
+		}
+		return q.modules[y][x]
+	}
+	for _, transpose := range []bool{false, true} {
+		for y := 0; y < q.size; y++ {
+			// Runs of five or more modules of the same color.
+			run := 1
+			for x := 1; x < q.size; x++ {
+				if at(x, y, transpose) == at(x-1, y, transpose) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += run - 2
+				}
+				run = 1
+			}
+			if run >= 5 {
+				penalty += run - 2
+			}
+			// Patterns resembling a finder.
+			for x := 0; x+11 <= q.size; x++ {
+				for _, pattern := range finderLike {
+					match := true
+					for i, d := range pattern {
+						if at(x+i, y, transpose) != d {
+							match = false
+							break
+						}
+					}
+					if match {
+						penalty += 40
+					}
+				}
+			}
+		}
+	}
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+			// 2x2 blocks of the same color.
+			if x > 0 && y > 0 {
+				c := q.modules[y][x]
+				if c == q.modules[y-1][x] && c == q.modules[y][x-1] && c == q.modules[y-1][x-1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+	// Imbalance of dark and light modules.
+	total := q.size * q.size
+	penalty += (abs(dark*20-total*10)+total-1)/total*10 - 10
+	return penalty
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
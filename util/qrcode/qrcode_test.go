@@ -0,0 +1,87 @@
+package qrcode
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" at version 1-M, from the worked example at
+	// thonky.com/qr-code-tutorial.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestVersionInfo(t *testing.T) {
+	// Version 7 version information is 000111110010010100.
+	modules, err := Encode(bytes.Repeat([]byte{'x'}, 110))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 45 {
+		t.Fatalf("size = %d, want 45 (version 7)", len(modules))
+	}
+	var bits int
+	for i := 17; i >= 0; i-- {
+		bits <<= 1
+		if modules[i/3][len(modules)-11+i%3] {
+			bits |= 1
+		}
+	}
+	if bits != 0x07C94 {
+		t.Errorf("version bits = %018b", bits)
+	}
+}
+
+func TestFormatInfo(t *testing.T) {
+	modules, err := Encode([]byte("otpauth://totp/SERV:test?secret=JBSWY3DPEHPK3PXP&issuer=SERV"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Read the first copy of the format information, unmask it, and make
+	// sure it says level M with a valid mask.
+	var bits int
+	for i := 14; i >= 0; i-- {
+		var x, y int
+		switch {
+		case i <= 5:
+			x, y = 8, i
+		case i == 6:
+			x, y = 8, 7
+		case i == 7:
+			x, y = 8, 8
+		case i == 8:
+			x, y = 7, 8
+		default:
+			x, y = 14-i, 8
+		}
+		bits <<= 1
+		if modules[y][x] {
+			bits |= 1
+		}
+	}
+	bits ^= 0x5412
+	if level := bits >> 13; level != 0 {
+		t.Errorf("level bits = %02b, want 00", level)
+	}
+	data := bits >> 10
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	if data<<10|rem != bits {
+		t.Errorf("format bits %015b fail BCH check", bits)
+	}
+}
+
+func TestTooLong(t *testing.T) {
+	if _, err := Encode(make([]byte, 214)); err != ErrTooLong {
+		t.Errorf("err = %v, want ErrTooLong", err)
+	}
+	if _, err := Encode(make([]byte, 213)); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
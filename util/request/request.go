@@ -1,6 +1,7 @@
 package request
 
 import (
+	"net"
 	"net/http"
 
 	"sunnyvaleserv.org/portal/server/l10n"
@@ -17,6 +18,7 @@ type Request struct {
 	ResponseWriter
 	SessionToken string
 	CSRF         string
+	Impersonator int // ID of webmaster viewing the site as the session user
 	Path         string
 	Language     string
 	LogEntry     *log.Entry
@@ -49,3 +51,11 @@ func (r *Request) DisableCompression() {
 func (r *Request) Loc(s string) string {
 	return l10n.Localize(s, r.Language)
 }
+
+// ClientIP returns the IP address of the client that made the request.
+func (r *Request) ClientIP() string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,80 @@
+// Package totp implements time-based one-time passwords as defined in RFC 6238,
+// with the parameters used by common authenticator apps:  HMAC-SHA1, 30-second
+// time steps, and six-digit codes.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// stepSeconds is the length of a time step.
+const stepSeconds = 30
+
+// skew is the number of time steps before and after the current one whose
+// codes are also accepted, to allow for clock drift and typing time.
+const skew = 1
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns a new random secret, base32-encoded.
+func NewSecret() string {
+	var secret [20]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		panic(err)
+	}
+	return encoding.EncodeToString(secret[:])
+}
+
+// URI returns the otpauth URI that provisions the secret into an authenticator
+// app, usually conveyed in a QR code.
+func URI(issuer, account, secret string) string {
+	return fmt.Sprintf("otpauth://totp/%s:%s?secret=%s&issuer=%s",
+		url.PathEscape(issuer), url.PathEscape(account), secret, url.QueryEscape(issuer))
+}
+
+// Step returns the time step containing the specified time.
+func Step(t time.Time) int64 {
+	return t.Unix() / stepSeconds
+}
+
+// Code returns the code for the specified secret and time step.
+func Code(secret string, step int64) string {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return ""
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0xF
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7FFFFFFF
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+// Verify checks the code against the secret at the specified time, allowing
+// for clock skew.  Codes from time steps at or before after are refused, so
+// that a code cannot be used twice.  It returns the time step of the matching
+// code, or zero if the code is not valid.
+func Verify(secret, code string, now time.Time, after int64) (step int64) {
+	code = strings.Join(strings.Fields(code), "")
+	if len(code) != 6 {
+		return 0
+	}
+	current := Step(now)
+	for s := current - skew; s <= current+skew; s++ {
+		if s > after && hmac.Equal([]byte(Code(secret, s)), []byte(code)) {
+			return s
+		}
+	}
+	return 0
+}
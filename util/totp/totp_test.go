@@ -0,0 +1,47 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// The test vectors are the SHA1 cases from RFC 6238 appendix B, truncated to
+// six digits.
+var testSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+var testVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+}
+
+func TestCode(t *testing.T) {
+	for _, tv := range testVectors {
+		if got := Code(testSecret, Step(time.Unix(tv.unix, 0))); got != tv.code {
+			t.Errorf("Code at %d = %s, want %s", tv.unix, got, tv.code)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+	if got := Verify(testSecret, "050 471", now, 0); got != step {
+		t.Errorf("Verify = %d, want %d", got, step)
+	}
+	if got := Verify(testSecret, Code(testSecret, step-1), now, 0); got != step-1 {
+		t.Errorf("Verify previous step = %d, want %d", got, step-1)
+	}
+	if got := Verify(testSecret, "050471", now, step); got != 0 {
+		t.Errorf("Verify reused code = %d, want 0", got)
+	}
+	if got := Verify(testSecret, Code(testSecret, step-2), now, 0); got != 0 {
+		t.Errorf("Verify stale code = %d, want 0", got)
+	}
+}
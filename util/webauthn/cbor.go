@@ -0,0 +1,123 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// errCBOR is returned for malformed or unsupported CBOR data.
+var errCBOR = errors.New("webauthn: invalid CBOR data")
+
+// maxCBORDepth limits the nesting of CBOR arrays and maps, to protect against
+// malicious input.
+const maxCBORDepth = 8
+
+// decodeCBOR decodes the first CBOR data item in buf, and returns it along with
+// the remaining bytes of buf.  It supports the subset of CBOR used by
+// WebAuthn:  integers are returned as int64, byte strings as []byte, text
+// strings as string, arrays as []any, maps as map[any]any, and simple values
+// as bool or nil.  Indefinite lengths, tags, and floating point values are not
+// supported.
+func decodeCBOR(buf []byte) (item any, rest []byte, err error) {
+	return decodeCBORItem(buf, 0)
+}
+
+func decodeCBORItem(buf []byte, depth int) (item any, rest []byte, err error) {
+	var (
+		major byte
+		arg   uint64
+	)
+	if depth > maxCBORDepth {
+		return nil, nil, errCBOR
+	}
+	if major, arg, buf, err = decodeCBORHead(buf); err != nil {
+		return nil, nil, err
+	}
+	switch major {
+	case 0: // unsigned integer
+		if arg > 1<<63-1 {
+			return nil, nil, errCBOR
+		}
+		return int64(arg), buf, nil
+	case 1: // negative integer
+		if arg > 1<<63-1 {
+			return nil, nil, errCBOR
+		}
+		return -1 - int64(arg), buf, nil
+	case 2: // byte string
+		if arg > uint64(len(buf)) {
+			return nil, nil, errCBOR
+		}
+		return buf[:arg:arg], buf[arg:], nil
+	case 3: // text string
+		if arg > uint64(len(buf)) {
+			return nil, nil, errCBOR
+		}
+		return string(buf[:arg]), buf[arg:], nil
+	case 4: // array
+		if arg > uint64(len(buf)) { // each item is at least one byte
+			return nil, nil, errCBOR
+		}
+		var array = make([]any, arg)
+		for i := range array {
+			if array[i], buf, err = decodeCBORItem(buf, depth+1); err != nil {
+				return nil, nil, err
+			}
+		}
+		return array, buf, nil
+	case 5: // map
+		if arg > uint64(len(buf))/2 { // each pair is at least two bytes
+			return nil, nil, errCBOR
+		}
+		var m = make(map[any]any, arg)
+		for range arg {
+			var key, value any
+			if key, buf, err = decodeCBORItem(buf, depth+1); err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+				break
+			default:
+				return nil, nil, errCBOR
+			}
+			if value, buf, err = decodeCBORItem(buf, depth+1); err != nil {
+				return nil, nil, err
+			}
+			m[key] = value
+		}
+		return m, buf, nil
+	case 7: // simple value
+		switch arg {
+		case 20:
+			return false, buf, nil
+		case 21:
+			return true, buf, nil
+		case 22:
+			return nil, buf, nil
+		}
+	}
+	return nil, nil, errCBOR
+}
+
+// decodeCBORHead decodes the initial byte and argument of a CBOR data item.
+func decodeCBORHead(buf []byte) (major byte, arg uint64, rest []byte, err error) {
+	if len(buf) == 0 {
+		return 0, 0, nil, errCBOR
+	}
+	major, info := buf[0]>>5, buf[0]&0x1F
+	buf = buf[1:]
+	switch {
+	case info < 24:
+		return major, uint64(info), buf, nil
+	case info == 24 && len(buf) >= 1:
+		return major, uint64(buf[0]), buf[1:], nil
+	case info == 25 && len(buf) >= 2:
+		return major, uint64(binary.BigEndian.Uint16(buf)), buf[2:], nil
+	case info == 26 && len(buf) >= 4:
+		return major, uint64(binary.BigEndian.Uint32(buf)), buf[4:], nil
+	case info == 27 && len(buf) >= 8:
+		return major, binary.BigEndian.Uint64(buf), buf[8:], nil
+	}
+	return 0, 0, nil, errCBOR
+}
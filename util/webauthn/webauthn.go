@@ -0,0 +1,286 @@
+// Package webauthn implements the relying party side of Web Authentication
+// (passkey) registration and login, as defined in the W3C Web Authentication
+// Level 2 recommendation.  It supports only what we need:  "none" attestation
+// (attestation statements are ignored), and ES256, RS256, and EdDSA public
+// keys.
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+// Errors returned by the verification functions.
+var (
+	ErrClientData    = errors.New("webauthn: invalid client data")
+	ErrChallenge     = errors.New("webauthn: challenge mismatch")
+	ErrOrigin        = errors.New("webauthn: origin mismatch")
+	ErrAuthData      = errors.New("webauthn: invalid authenticator data")
+	ErrRPID          = errors.New("webauthn: relying party ID mismatch")
+	ErrUserPresence  = errors.New("webauthn: user not present")
+	ErrPublicKey     = errors.New("webauthn: invalid or unsupported public key")
+	ErrSignature     = errors.New("webauthn: invalid signature")
+	ErrSignCount     = errors.New("webauthn: signature counter did not increase; authenticator may be cloned")
+	ErrNoCredentials = errors.New("webauthn: no attested credential data")
+)
+
+// Authenticator data flags.
+const (
+	flagUserPresent      = 0x01
+	flagUserVerified     = 0x04
+	flagAttestedCredData = 0x40
+)
+
+// COSE algorithm identifiers of the supported public key types.
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// Algorithms is the list of supported COSE algorithm identifiers, in order of
+// preference, for the pubKeyCredParams of a registration request.
+var Algorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+// Encoding is the encoding used for binary values in the WebAuthn JSON
+// structures, and which callers should use to transfer them to and from the
+// browser.
+var Encoding = base64.RawURLEncoding
+
+// RelyingParty identifies the web site on whose behalf credentials are created
+// and used.
+type RelyingParty struct {
+	// ID is the relying party ID:  the host name of the site.
+	ID string
+	// Origin is the origin of the site, e.g. "https://example.com".
+	Origin string
+}
+
+// Credential is a newly registered credential.
+type Credential struct {
+	// ID is the credential ID assigned by the authenticator.
+	ID []byte
+	// PublicKey is the credential public key, in COSE_Key format.
+	PublicKey []byte
+	// SignCount is the initial value of the signature counter.
+	SignCount uint32
+	// UserVerified indicates whether the authenticator verified the user
+	// (e.g., by PIN or biometric), as opposed to merely testing their
+	// presence.
+	UserVerified bool
+}
+
+// Assertion is the result of a successful login.
+type Assertion struct {
+	// SignCount is the new value of the signature counter, which should be
+	// stored for comparison with the next login.
+	SignCount uint32
+	// UserVerified indicates whether the authenticator verified the user.
+	UserVerified bool
+}
+
+// authData is parsed authenticator data.
+type authData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+	credID    []byte
+	publicKey []byte
+}
+
+// Register verifies the response to a navigator.credentials.create() call
+// made with the specified challenge, and returns the new credential.
+func (rp RelyingParty) Register(challenge, clientDataJSON, attestationObject []byte) (cred *Credential, err error) {
+	var (
+		item any
+		att  map[any]any
+		raw  []byte
+		ad   *authData
+		ok   bool
+	)
+	if err = rp.checkClientData(clientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+	if item, _, err = decodeCBOR(attestationObject); err != nil {
+		return nil, err
+	}
+	if att, ok = item.(map[any]any); !ok {
+		return nil, ErrAuthData
+	}
+	if raw, ok = att["authData"].([]byte); !ok {
+		return nil, ErrAuthData
+	}
+	if ad, err = rp.parseAuthData(raw); err != nil {
+		return nil, err
+	}
+	if ad.credID == nil {
+		return nil, ErrNoCredentials
+	}
+	if _, err = parsePublicKey(ad.publicKey); err != nil {
+		return nil, err
+	}
+	return &Credential{
+		ID:           ad.credID,
+		PublicKey:    ad.publicKey,
+		SignCount:    ad.signCount,
+		UserVerified: ad.flags&flagUserVerified != 0,
+	}, nil
+}
+
+// Login verifies the response to a navigator.credentials.get() call made with
+// the specified challenge.  publicKey and signCount are the stored public key
+// and signature counter of the credential that the response claims to be from.
+func (rp RelyingParty) Login(challenge, publicKey []byte, signCount uint32, clientDataJSON, authenticatorData, signature []byte) (a *Assertion, err error) {
+	var (
+		ad  *authData
+		key crypto.PublicKey
+	)
+	if err = rp.checkClientData(clientDataJSON, "webauthn.get", challenge); err != nil {
+		return nil, err
+	}
+	if ad, err = rp.parseAuthData(authenticatorData); err != nil {
+		return nil, err
+	}
+	if key, err = parsePublicKey(publicKey); err != nil {
+		return nil, err
+	}
+	cdHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authenticatorData...), cdHash[:]...)
+	if !verifySignature(key, signed, signature) {
+		return nil, ErrSignature
+	}
+	// Authenticators that don't implement a signature counter always
+	// report zero.  Otherwise, it must increase with each use.
+	if (ad.signCount != 0 || signCount != 0) && ad.signCount <= signCount {
+		return nil, ErrSignCount
+	}
+	return &Assertion{SignCount: ad.signCount, UserVerified: ad.flags&flagUserVerified != 0}, nil
+}
+
+// checkClientData verifies the client data JSON of a response.
+func (rp RelyingParty) checkClientData(clientDataJSON []byte, typ string, challenge []byte) error {
+	var cd struct {
+		Type      string `json:"type"`
+		Challenge string `json:"challenge"`
+		Origin    string `json:"origin"`
+	}
+	if err := json.Unmarshal(clientDataJSON, &cd); err != nil || cd.Type != typ {
+		return ErrClientData
+	}
+	if got, err := Encoding.DecodeString(cd.Challenge); err != nil || len(challenge) == 0 || subtle.ConstantTimeCompare(got, challenge) != 1 {
+		return ErrChallenge
+	}
+	if cd.Origin != rp.Origin {
+		return ErrOrigin
+	}
+	return nil
+}
+
+// parseAuthData parses and verifies authenticator data.
+func (rp RelyingParty) parseAuthData(raw []byte) (ad *authData, err error) {
+	if len(raw) < 37 {
+		return nil, ErrAuthData
+	}
+	ad = &authData{rpIDHash: raw[:32], flags: raw[32], signCount: binary.BigEndian.Uint32(raw[33:37])}
+	if hash := sha256.Sum256([]byte(rp.ID)); !bytes.Equal(ad.rpIDHash, hash[:]) {
+		return nil, ErrRPID
+	}
+	if ad.flags&flagUserPresent == 0 {
+		return nil, ErrUserPresence
+	}
+	if ad.flags&flagAttestedCredData == 0 {
+		return ad, nil
+	}
+	raw = raw[37:]
+	if len(raw) < 18 {
+		return nil, ErrAuthData
+	}
+	idlen := int(binary.BigEndian.Uint16(raw[16:18])) // skip AAGUID
+	raw = raw[18:]
+	if idlen == 0 || len(raw) < idlen {
+		return nil, ErrAuthData
+	}
+	ad.credID, raw = raw[:idlen], raw[idlen:]
+	var rest []byte
+	if _, rest, err = decodeCBOR(raw); err != nil {
+		return nil, ErrAuthData
+	}
+	ad.publicKey = raw[:len(raw)-len(rest)]
+	return ad, nil
+}
+
+// parsePublicKey parses a COSE_Key.
+func parsePublicKey(raw []byte) (key crypto.PublicKey, err error) {
+	var (
+		item any
+		m    map[any]any
+		ok   bool
+	)
+	if item, _, err = decodeCBOR(raw); err != nil {
+		return nil, ErrPublicKey
+	}
+	if m, ok = item.(map[any]any); !ok {
+		return nil, ErrPublicKey
+	}
+	kty, _ := m[int64(1)].(int64)
+	alg, _ := m[int64(3)].(int64)
+	switch {
+	case kty == 2 && alg == AlgES256: // EC2
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		y, _ := m[int64(-3)].([]byte)
+		if crv != 1 || len(x) != 32 || len(y) != 32 {
+			return nil, ErrPublicKey
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, ErrPublicKey
+		}
+		return pub, nil
+	case kty == 1 && alg == AlgEdDSA: // OKP
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		if crv != 6 || len(x) != ed25519.PublicKeySize {
+			return nil, ErrPublicKey
+		}
+		return ed25519.PublicKey(x), nil
+	case kty == 3 && alg == AlgRS256: // RSA
+		n, _ := m[int64(-1)].([]byte)
+		e, _ := m[int64(-2)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, ErrPublicKey
+		}
+		var exp int
+		for _, b := range e {
+			exp = exp<<8 | int(b)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exp}, nil
+	}
+	return nil, ErrPublicKey
+}
+
+// verifySignature verifies a signature made by the specified key.
+func verifySignature(key crypto.PublicKey, message, signature []byte) bool {
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		hash := sha256.Sum256(message)
+		return ecdsa.VerifyASN1(key, hash[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, signature)
+	case *rsa.PublicKey:
+		hash := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature) == nil
+	}
+	return false
+}
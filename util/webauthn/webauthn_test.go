@@ -0,0 +1,203 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+var testRP = RelyingParty{ID: "example.com", Origin: "https://example.com"}
+
+// softAuthenticator is a software authenticator holding one credential, used
+// to exercise registration and login.
+type softAuthenticator struct {
+	credID    []byte
+	ecKey     *ecdsa.PrivateKey
+	edKey     ed25519.PrivateKey
+	signCount uint32
+}
+
+func newSoftAuthenticator(t *testing.T, ed bool) (a *softAuthenticator) {
+	var err error
+	a = &softAuthenticator{credID: make([]byte, 16)}
+	rand.Read(a.credID)
+	if ed {
+		_, a.edKey, err = ed25519.GenerateKey(rand.Reader)
+	} else {
+		a.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// cborHead encodes a CBOR data item head.
+func cborHead(major byte, arg int) []byte {
+	switch {
+	case arg < 24:
+		return []byte{major<<5 | byte(arg)}
+	case arg < 256:
+		return []byte{major<<5 | 24, byte(arg)}
+	default:
+		return []byte{major<<5 | 25, byte(arg >> 8), byte(arg)}
+	}
+}
+
+// cborInt encodes a CBOR integer.
+func cborInt(v int) []byte {
+	if v < 0 {
+		return cborHead(1, -1-v)
+	}
+	return cborHead(0, v)
+}
+
+// cborBytes encodes a CBOR byte string.
+func cborBytes(b []byte) []byte {
+	return append(cborHead(2, len(b)), b...)
+}
+
+// cborText encodes a CBOR text string.
+func cborText(s string) []byte {
+	return append(cborHead(3, len(s)), s...)
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	if a.edKey != nil {
+		key := cborHead(5, 4)
+		key = append(append(key, cborInt(1)...), cborInt(1)...)
+		key = append(append(key, cborInt(3)...), cborInt(AlgEdDSA)...)
+		key = append(append(key, cborInt(-1)...), cborInt(6)...)
+		return append(append(key, cborInt(-2)...), cborBytes(a.edKey.Public().(ed25519.PublicKey))...)
+	}
+	var x, y [32]byte
+	a.ecKey.X.FillBytes(x[:])
+	a.ecKey.Y.FillBytes(y[:])
+	key := cborHead(5, 5)
+	key = append(append(key, cborInt(1)...), cborInt(2)...)
+	key = append(append(key, cborInt(3)...), cborInt(AlgES256)...)
+	key = append(append(key, cborInt(-1)...), cborInt(1)...)
+	key = append(append(key, cborInt(-2)...), cborBytes(x[:])...)
+	return append(append(key, cborInt(-3)...), cborBytes(y[:])...)
+}
+
+func (a *softAuthenticator) authData(rpID string, flags byte, attested bool) []byte {
+	hash := sha256.Sum256([]byte(rpID))
+	ad := append(hash[:], flags)
+	ad = binary.BigEndian.AppendUint32(ad, a.signCount)
+	if attested {
+		ad = append(ad, make([]byte, 16)...) // AAGUID
+		ad = binary.BigEndian.AppendUint16(ad, uint16(len(a.credID)))
+		ad = append(ad, a.credID...)
+		ad = append(ad, a.coseKey()...)
+	}
+	return ad
+}
+
+func clientData(typ string, challenge []byte, origin string) []byte {
+	cd, _ := json.Marshal(map[string]any{"type": typ, "challenge": Encoding.EncodeToString(challenge), "origin": origin, "crossOrigin": false})
+	return cd
+}
+
+// create simulates navigator.credentials.create().
+func (a *softAuthenticator) create(challenge []byte) (clientDataJSON, attestationObject []byte) {
+	clientDataJSON = clientData("webauthn.create", challenge, testRP.Origin)
+	attestationObject = cborHead(5, 3)
+	attestationObject = append(append(attestationObject, cborText("fmt")...), cborText("none")...)
+	attestationObject = append(append(attestationObject, cborText("attStmt")...), cborHead(5, 0)...)
+	attestationObject = append(append(attestationObject, cborText("authData")...), cborBytes(a.authData(testRP.ID, flagUserPresent|flagUserVerified|flagAttestedCredData, true))...)
+	return clientDataJSON, attestationObject
+}
+
+// get simulates navigator.credentials.get().
+func (a *softAuthenticator) get(challenge []byte, rpID, origin string) (clientDataJSON, authenticatorData, signature []byte) {
+	a.signCount++
+	clientDataJSON = clientData("webauthn.get", challenge, origin)
+	authenticatorData = a.authData(rpID, flagUserPresent, false)
+	cdHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authenticatorData...), cdHash[:]...)
+	if a.edKey != nil {
+		signature = ed25519.Sign(a.edKey, signed)
+	} else {
+		hash := sha256.Sum256(signed)
+		signature, _ = ecdsa.SignASN1(rand.Reader, a.ecKey, hash[:])
+	}
+	return clientDataJSON, authenticatorData, signature
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	for _, ed := range []bool{false, true} {
+		a := newSoftAuthenticator(t, ed)
+		challenge := []byte("registration challenge")
+		cdj, att := a.create(challenge)
+		cred, err := testRP.Register(challenge, cdj, att)
+		if err != nil {
+			t.Fatalf("ed=%v: Register: %s", ed, err)
+		}
+		if string(cred.ID) != string(a.credID) || !cred.UserVerified {
+			t.Errorf("ed=%v: Register returned wrong credential data", ed)
+		}
+		challenge = []byte("login challenge")
+		cdj, ad, sig := a.get(challenge, testRP.ID, testRP.Origin)
+		as, err := testRP.Login(challenge, cred.PublicKey, cred.SignCount, cdj, ad, sig)
+		if err != nil {
+			t.Fatalf("ed=%v: Login: %s", ed, err)
+		}
+		if as.SignCount != 1 || as.UserVerified {
+			t.Errorf("ed=%v: Login returned %+v", ed, as)
+		}
+		// A replay of the same response must fail on the counter.
+		if _, err = testRP.Login(challenge, cred.PublicKey, as.SignCount, cdj, ad, sig); !errors.Is(err, ErrSignCount) {
+			t.Errorf("ed=%v: replay: got %v, want ErrSignCount", ed, err)
+		}
+	}
+}
+
+func TestRegisterRejects(t *testing.T) {
+	a := newSoftAuthenticator(t, false)
+	cdj, att := a.create([]byte("right"))
+	if _, err := testRP.Register([]byte("wrong"), cdj, att); !errors.Is(err, ErrChallenge) {
+		t.Errorf("wrong challenge: got %v", err)
+	}
+	if _, err := (RelyingParty{ID: testRP.ID, Origin: "https://evil.example"}).Register([]byte("right"), cdj, att); !errors.Is(err, ErrOrigin) {
+		t.Errorf("wrong origin: got %v", err)
+	}
+	if _, err := (RelyingParty{ID: "evil.example", Origin: testRP.Origin}).Register([]byte("right"), cdj, att); !errors.Is(err, ErrRPID) {
+		t.Errorf("wrong RP ID: got %v", err)
+	}
+	if _, err := testRP.Register([]byte("right"), cdj, att[:len(att)-10]); err == nil {
+		t.Error("truncated attestation object accepted")
+	}
+}
+
+func TestLoginRejects(t *testing.T) {
+	a := newSoftAuthenticator(t, false)
+	cdj, att := a.create([]byte("c"))
+	cred, err := testRP.Register([]byte("c"), cdj, att)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := newSoftAuthenticator(t, false)
+	cdj, ad, sig := other.get([]byte("c"), testRP.ID, testRP.Origin)
+	if _, err = testRP.Login([]byte("c"), cred.PublicKey, 0, cdj, ad, sig); !errors.Is(err, ErrSignature) {
+		t.Errorf("wrong key: got %v", err)
+	}
+	cdj, ad, sig = a.get([]byte("c"), "evil.example", testRP.Origin)
+	if _, err = testRP.Login([]byte("c"), cred.PublicKey, 0, cdj, ad, sig); !errors.Is(err, ErrRPID) {
+		t.Errorf("wrong RP ID: got %v", err)
+	}
+	cdj, ad, sig = a.get([]byte("c"), testRP.ID, testRP.Origin)
+	if _, err = testRP.Login([]byte("d"), cred.PublicKey, 0, cdj, ad, sig); !errors.Is(err, ErrChallenge) {
+		t.Errorf("wrong challenge: got %v", err)
+	}
+	ad[len(ad)-1] ^= 1 // tamper with the signature counter
+	if _, err = testRP.Login([]byte("c"), cred.PublicKey, 0, cdj, ad, sig); !errors.Is(err, ErrSignature) {
+		t.Errorf("tampered data: got %v", err)
+	}
+}